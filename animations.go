@@ -0,0 +1,54 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"sync"
+)
+
+// AnimationsEnabled returns whether the system wants things on screen to move: it's false if the user turned animations off (Show animations in Windows in Ease of Access on Windows, gtk-enable-animations on GTK+) or asked for less motion (Reduce motion on Mac OS X 10.12 and newer).
+// When it's false, what package ui animates itself changes state at once instead: a banner (see Window.ShowBanner) appears without sliding in, the bars of a LevelMeter drop straight to the new level, and the busy indicator of a Window (see Window.SetBusy) stands still where the system has a still one.
+// If you animate things yourself, such as in an Area, do the same.
+// AnimationsEnabled can be called from any goroutine, including from the methods of an AreaHandler, which run on the UI thread.
+//
+// As with HighContrast, on Windows package ui hears about the change through its Windows, so AnimationsEnabled only follows it while at least one Window exists.
+func AnimationsEnabled() bool {
+	animationsLock.Lock()
+	known := animations.known
+	enabled := animations.enabled
+	animationsLock.Unlock()
+	if !known { // package ui isn't set up yet; this sets it up
+		ret := make(chan struct{})
+		defer close(ret)
+		uitask(func() {
+			ret <- struct{}{}
+		})
+		<-ret
+		return AnimationsEnabled()
+	}
+	return enabled
+}
+
+var (
+	animationsLock sync.Mutex
+	animations     struct {
+		known   bool // set by the first animationsUpdate(), which uiinit() calls
+		enabled bool
+	}
+)
+
+// called on the UI thread by uiinit() and whenever the system says the setting may have changed
+func animationsUpdate() {
+	enabled := sysAnimationsEnabled()
+	animationsLock.Lock()
+	animations.known = true
+	animations.enabled = enabled
+	animationsLock.Unlock()
+}
+
+// for package ui's own animations; unlike AnimationsEnabled(), this can't set up package ui, so it assumes animations until it knows better
+func currentAnimationsEnabled() bool {
+	animationsLock.Lock()
+	defer animationsLock.Unlock()
+	return !animations.known || animations.enabled
+}
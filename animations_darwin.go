@@ -0,0 +1,10 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+func sysAnimationsEnabled() bool {
+	return C.animationsEnabled() != C.NO
+}
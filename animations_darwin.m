@@ -0,0 +1,21 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSWorkspace.h>
+
+/*
+Reduce motion is new in 10.12, so, as with Increase Contrast (see highcontrast_darwin.m), we ask if the workspace has -[NSWorkspace accessibilityDisplayShouldReduceMotion] before calling it.
+It changes with the same notification as Increase Contrast, so highContrastChanged: updates both.
+*/
+
+BOOL animationsEnabled(void)
+{
+	NSWorkspace *ws;
+	SEL sel;
+
+	ws = [NSWorkspace sharedWorkspace];
+	sel = @selector(accessibilityDisplayShouldReduceMotion);
+	if (![ws respondsToSelector:sel])		// before 10.12
+		return YES;
+	return !((BOOL (*)(id, SEL)) objc_msgSend)(ws, sel);
+}
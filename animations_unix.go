@@ -0,0 +1,42 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern void our_animations_setting_callback(GObject *, GParamSpec *, gpointer);
+// /* g_object_get() is variadic, which cgo can't call */
+// static inline gboolean gtkEnableAnimations(void)
+// {
+// 	gboolean enabled = TRUE;
+//
+// 	g_object_get(gtk_settings_get_default(), "gtk-enable-animations", &enabled, NULL);
+// 	return enabled;
+// }
+import "C"
+
+/*
+GTK+'s setting is gtk-enable-animations of GtkSettings, which GNOME sets from its own Enable Animations; GTK+ follows it by itself for its widgets (including the GtkSpinner of a busy Window), so we only need it for what we animate ourselves.
+*/
+
+func sysAnimationsEnabled() bool {
+	return C.gtkEnableAnimations() != C.FALSE
+}
+
+// called by uiinit()
+func animationsWatch() {
+	g_signal_connect_pointer((*C.GtkWidget)(unsafe.Pointer(C.gtk_settings_get_default())), "notify::gtk-enable-animations", animations_setting_callback, nil)
+}
+
+//export our_animations_setting_callback
+func our_animations_setting_callback(object *C.GObject, pspec *C.GParamSpec, what C.gpointer) {
+	defer recoverUIPanic()
+	animationsUpdate()
+}
+
+var animations_setting_callback = C.GCallback(C.our_animations_setting_callback)
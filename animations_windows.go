@@ -0,0 +1,26 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+/*
+Show animations in Windows (in Ease of Access) is SPI_GETCLIENTAREAANIMATION, which is new in Windows Vista; SystemParametersInfo() fails for it on older versions, which animate.
+As with high contrast, Windows says it changed with WM_SETTINGCHANGE to top-level windows only, so our Windows pass it on (see stdWndProc()).
+*/
+
+func sysAnimationsEnabled() bool {
+	var enabled uint32 // originally BOOL
+
+	r1, _, _ := _systemParametersInfo.Call(
+		uintptr(_SPI_GETCLIENTAREAANIMATION),
+		uintptr(0),
+		uintptr(unsafe.Pointer(&enabled)),
+		0)
+	if r1 == 0 { // before Windows Vista
+		return true
+	}
+	return enabled != 0
+}
@@ -0,0 +1,66 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NewAboutMenuItem creates a new MenuItem for showing the About box of the program.
+// Its text is "About" and the name of the program.
+//
+// The MenuItems made by NewAboutMenuItem, NewPreferencesMenuItem, and NewQuitMenuItem go wherever each system expects them, with the text each system uses for them.
+// Put each of them where Windows and GTK+ programs have them (About at the end of the Help menu, Preferences at the end of the Edit menu, and Quit at the end of the File menu, with a separator before each); they stay there on Windows and GTK+.
+// On Mac OS X, About and Preferences go in the application menu instead, the one named after the program, and aren't shown in the Menu they're in.
+// (Mac OS X always shows the menu bar at the top of the screen, and so does Ubuntu's Unity, on its own, for GTK+ programs; nothing else changes there.)
+func NewAboutMenuItem() *MenuItem {
+	return newAppMenuItem(appMenuAbout)
+}
+
+// NewPreferencesMenuItem creates a new MenuItem for showing the preferences of the program.
+// Its text is "Preferences" ("Options" on Windows); on Mac OS X, it has the usual keyboard shortcut, Command-comma.
+// See NewAboutMenuItem for where it goes.
+func NewPreferencesMenuItem() *MenuItem {
+	return newAppMenuItem(appMenuPreferences)
+}
+
+// NewQuitMenuItem creates a new MenuItem for quitting the program.
+// Picking it pulses AppQuit, just as quitting the program any other way does, so your program should watch AppQuit rather than Clicked, which is never signaled.
+// Its text is "Quit" ("Exit" on Windows).
+// On Mac OS X, the application menu always has Quit, which already pulses AppQuit, so this MenuItem is not shown at all.
+func NewQuitMenuItem() *MenuItem {
+	return newAppMenuItem(appMenuQuit)
+}
+
+type appMenuRole int
+
+const (
+	appMenuNone appMenuRole = iota // for every other MenuItem
+	appMenuAbout
+	appMenuPreferences
+	appMenuQuit
+)
+
+func newAppMenuItem(role appMenuRole) *MenuItem {
+	i := newMenuItem(menuItemNormal, appMenuTexts[role]) // see appmenu_*.go
+	i.role = role
+	i.shortcut = appMenuShortcuts[role]
+	return i
+}
+
+// the name of the program, for About; Mac OS X uses its own (the process name) for the whole application menu
+func appName() string {
+	name := filepath.Base(os.Args[0])
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// runs on the UI thread
+func appQuit() {
+	// asynchronous, as AppQuit might not be waited on yet (or at all); see also appDelegate_applicationShouldTerminate()
+	go func() {
+		saveAllSettings() // see BindSetting()
+		AppQuit <- struct{}{}
+	}()
+}
@@ -0,0 +1,14 @@
+// 9 july 2014
+
+package ui
+
+// the application menu is named after the process, so About uses its name too; Quit is never shown (see NewQuitMenuItem())
+var appMenuTexts = map[appMenuRole]string{
+	appMenuAbout:       "About " + appName(),
+	appMenuPreferences: "Preferences…",
+	appMenuQuit:        "Quit",
+}
+
+var appMenuShortcuts = map[appMenuRole]menuShortcut{
+	appMenuPreferences: {',', false},
+}
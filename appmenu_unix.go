@@ -0,0 +1,14 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+// the GNOME HIG would give Quit Ctrl+Q, but only Mac OS X acts on menu shortcuts for now (see menuShortcut), so it would just be shown
+var appMenuTexts = map[appMenuRole]string{
+	appMenuAbout:       "About " + appName(),
+	appMenuPreferences: "Preferences",
+	appMenuQuit:        "Quit",
+}
+
+var appMenuShortcuts = map[appMenuRole]menuShortcut{}
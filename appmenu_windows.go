@@ -0,0 +1,12 @@
+// 9 july 2014
+
+package ui
+
+// Windows programs say Exit and Options; neither has a shortcut (Alt+F4 already closes the Window)
+var appMenuTexts = map[appMenuRole]string{
+	appMenuAbout:       "About " + appName(),
+	appMenuPreferences: "Options...",
+	appMenuQuit:        "Exit",
+}
+
+var appMenuShortcuts = map[appMenuRole]menuShortcut{}
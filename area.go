@@ -7,6 +7,7 @@ import (
 	"image"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -38,6 +39,7 @@ type Area struct {
 
 // AreaHandler represents the events that an Area should respond to.
 // These methods are all executed on the main goroutine, not necessarily the same one that you created the AreaHandler in; you are responsible for the thread safety of any members of the actual type that implements ths interface.
+// Because they run on the main goroutine, these methods cannot call any other package ui function or method; doing so panics.
 // (Having to use this interface does not strike me as being particularly Go-like, but the nature of Paint makes channel-based event handling a non-option; in practice, deadlocks occur.)
 type AreaHandler interface {
 	// Paint is called when the Area needs to be redrawn.
@@ -71,6 +73,32 @@ type AreaHandler interface {
 	Key(e KeyEvent) (repaint bool)
 }
 
+// An AreaCaretHandler is an AreaHandler that also says where its text cursor (caret) is, such as that of a text editor drawn on an Area.
+// Screen magnifiers and other assistive tools follow the keyboard focus and the caret of the system's own controls (so LineEdits need nothing of the sort), but they can't see into an Area; if its AreaHandler is an AreaCaretHandler, they follow Caret while the Area has the keyboard focus.
+// This is done with a hidden system caret on Windows, which Magnifier and Narrator follow, and with Zoom's own function for it on Mac OS X; on GTK+ it would take implementing AtkText for the Area, which package ui doesn't do yet, so Caret is not used there.
+type AreaCaretHandler interface {
+	AreaHandler
+
+	// Caret returns where the caret is, in the coordinates of the Area, or image.ZR if there is none at the moment; a caret can be zero pixels wide, like a line between two characters.
+	// It is called after each call to Paint, Mouse, and Key, and when the Area gets the keyboard focus; so if the caret moves for any other reason, call RepaintAll, which you need to do to draw it in its new place anyway.
+	// Like the other methods, it runs on the main goroutine.
+	Caret() image.Rectangle
+}
+
+// called by the system code on the UI thread after each call to the AreaHandler, and with force when the Area gets the keyboard focus (or is scrolled, on Windows), when the system needs to hear about the caret again; see AreaCaretHandler
+func (s *sysData) areaCaretUpdate(force bool) {
+	h, ok := s.handler.(AreaCaretHandler)
+	if !ok {
+		return
+	}
+	r := h.Caret().Canon()
+	if r == s.areaCaret && !force {
+		return
+	}
+	s.areaCaret = r
+	s.setAreaCaret(r)
+}
+
 // MouseEvent contains all the information for a mous event sent by Area.Mouse.
 // Mouse button IDs start at 1, with 1 being the left mouse button, 2 being the middle mouse button, and 3 being the right mouse button.
 // If additional buttons are supported, they will be returned with 4 being the first additional button.
@@ -311,6 +339,8 @@ func (a *Area) SetSize(width int, height int) {
 }
 
 // RepaintAll signals the entirety of the Area for redraw.
+// It does not wait for the Area to be redrawn.
+// Redraws are combined: calling RepaintAll many times before the Area gets around to redrawing results in only one call to Paint, and Paint is not called more often than the system redraws windows.
 // If called before the Window containing the Area is created, RepaintAll does nothing.
 func (a *Area) RepaintAll() {
 	a.lock.Lock()
@@ -319,6 +349,10 @@ func (a *Area) RepaintAll() {
 	if !a.created {
 		return
 	}
+	// if there's already a request on its way to the UI thread, that one will do
+	if !atomic.CompareAndSwapInt32(&a.sysData.repaintPending, 0, 1) {
+		return
+	}
 	a.sysData.repaintAll()
 }
 
@@ -327,6 +361,7 @@ func (a *Area) make(window *sysData) error {
 	defer a.lock.Unlock()
 
 	a.sysData.handler = a.handler
+	a.sysData.control = a
 	err := a.sysData.make(window)
 	if err != nil {
 		return err
@@ -337,13 +372,7 @@ func (a *Area) make(window *sysData) error {
 }
 
 func (a *Area) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
-	return []*allocation{&allocation{
-		x:       x,
-		y:       y,
-		width:   width,
-		height:  height,
-		this:		a,
-	}}
+	return a.sysData.singleAllocation(x, y, width, height, a)
 }
 
 func (a *Area) preferredSize(d *sysSizeData) (width int, height int) {
@@ -25,6 +25,7 @@ func areaInScrollView(scrollview C.id) C.id {
 
 //export areaView_drawRect
 func areaView_drawRect(self C.id, rect C.struct_xrect) {
+	defer recoverUIPanic()
 	s := getSysData(self)
 	// no need to clear the clip rect; the NSScrollView does that for us (see the setDrawsBackground: call in objc_darwin.m)
 	// rectangles in Cocoa are origin/size, not point0/point1; if we don't watch for this, weird things will happen when scrolling
@@ -38,6 +39,7 @@ func areaView_drawRect(self C.id, rect C.struct_xrect) {
 	C.drawImage(
 		unsafe.Pointer(pixelData(i)), C.intptr_t(i.Rect.Dx()), C.intptr_t(i.Rect.Dy()), C.intptr_t(i.Stride),
 		C.intptr_t(cliprect.Min.X), C.intptr_t(cliprect.Min.Y))
+	s.areaCaretUpdate(false)
 }
 
 func parseModifiers(e C.id) (m Modifiers) {
@@ -110,13 +112,15 @@ func areaMouseEvent(self C.id, e C.id, click bool, up bool) {
 		held >>= 1
 	}
 	repaint := s.handler.Mouse(me)
+	s.areaCaretUpdate(false)
 	if repaint {
-		C.display(self)
+		C.setNeedsDisplay(self)
 	}
 }
 
 //export areaView_mouseMoved_mouseDragged
 func areaView_mouseMoved_mouseDragged(self C.id, e C.id) {
+	defer recoverUIPanic()
 	// for moving, this is handled by the tracking rect stuff above
 	// for dragging, if multiple buttons are held, only one of their xxxMouseDragged: messages will be sent, so this is OK to do
 	areaMouseEvent(self, e, false, false)
@@ -124,20 +128,23 @@ func areaView_mouseMoved_mouseDragged(self C.id, e C.id) {
 
 //export areaView_mouseDown
 func areaView_mouseDown(self C.id, e C.id) {
+	defer recoverUIPanic()
 	// no need to manually set focus; Mac OS X has already done that for us by this point since we set our view to be a first responder
 	areaMouseEvent(self, e, true, false)
 }
 
 //export areaView_mouseUp
 func areaView_mouseUp(self C.id, e C.id) {
+	defer recoverUIPanic()
 	areaMouseEvent(self, e, true, true)
 }
 
 func sendKeyEvent(self C.id, ke KeyEvent) {
 	s := getSysData(self)
 	repaint := s.handler.Key(ke)
+	s.areaCaretUpdate(false)
 	if repaint {
-		C.display(self)
+		C.setNeedsDisplay(self)
 	}
 }
 
@@ -158,16 +165,19 @@ func areaKeyEvent(self C.id, e C.id, up bool) {
 
 //export areaView_keyDown
 func areaView_keyDown(self C.id, e C.id) {
+	defer recoverUIPanic()
 	areaKeyEvent(self, e, false)
 }
 
 //export areaView_keyUp
 func areaView_keyUp(self C.id, e C.id) {
+	defer recoverUIPanic()
 	areaKeyEvent(self, e, true)
 }
 
 //export areaView_flagsChanged
 func areaView_flagsChanged(self C.id, e C.id) {
+	defer recoverUIPanic()
 	var ke KeyEvent
 
 	// Mac OS X sends this event on both key up and key down.
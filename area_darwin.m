@@ -56,6 +56,15 @@ extern NSRect dummyRect;
 	return YES;
 }
 
+// for AreaCaretHandler; see areacaret_darwin.go
+- (BOOL)becomeFirstResponder
+{
+	if ([super becomeFirstResponder] != YES)
+		return NO;
+	areaView_becomeFirstResponder(self);
+	return YES;
+}
+
 // this will have the Area receive a click that switches to the Window it is in from another one
 - (BOOL)acceptsFirstMouse:(NSEvent *)e
 {
@@ -5,6 +5,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"unsafe"
@@ -50,6 +51,7 @@ func gtkAreaGetControl(scrollarea *C.GtkWidget) *C.GtkWidget {
 
 //export our_area_draw_callback
 func our_area_draw_callback(widget *C.GtkWidget, cr *C.cairo_t, data C.gpointer) C.gboolean {
+	defer recoverUIPanic()
 	var x0, y0, x1, y1 C.double
 	var maxwid, maxht C.gint
 
@@ -66,13 +68,19 @@ func our_area_draw_callback(widget *C.GtkWidget, cr *C.cairo_t, data C.gpointer)
 		return C.FALSE // signals handled without stopping the event chain (thanks to desrt again)
 	}
 	i := s.handler.Paint(cliprect)
+	s.areaCaretUpdate(false)
 	surface := C.cairo_image_surface_create(
 		C.CAIRO_FORMAT_ARGB32, // alpha-premultiplied; native byte order
 		C.int(i.Rect.Dx()),
 		C.int(i.Rect.Dy()))
 	if status := C.cairo_surface_status(surface); status != C.CAIRO_STATUS_SUCCESS {
-		panic(fmt.Errorf("cairo_create_image_surface() failed: %s\n",
-			C.GoString(C.cairo_status_to_string(status))))
+		panic(&Error{
+			Op:      "creating image surface for image returned by AreaHandler.Paint()",
+			Call:    "cairo_image_surface_create()",
+			Code:    uintptr(status),
+			Control: s.control,
+			Err:     errors.New(C.GoString(C.cairo_status_to_string(status))),
+		})
 	}
 	// the flush and mark_dirty calls are required; see the cairo docs and https://git.gnome.org/browse/gtk+/tree/gdk/gdkcairo.c#n232 (thanks desrt in irc.gimp.net/#gtk+)
 	C.cairo_surface_flush(surface)
@@ -151,6 +159,7 @@ func finishMouseEvent(widget *C.GtkWidget, data C.gpointer, me MouseEvent, mb ui
 		me.Up -= 4
 	}
 	repaint := s.handler.Mouse(me)
+	s.areaCaretUpdate(false)
 	if repaint {
 		C.gtk_widget_queue_draw(widget)
 	}
@@ -166,6 +175,7 @@ const continueEventChain C.gboolean = C.FALSE
 
 //export our_area_button_press_event_callback
 func our_area_button_press_event_callback(widget *C.GtkWidget, event *C.GdkEvent, data C.gpointer) C.gboolean {
+	defer recoverUIPanic()
 	// clicking doesn't automatically transfer keyboard focus; we must do so manually (thanks tristan in irc.gimp.net/#gtk+)
 	C.gtk_widget_grab_focus(widget)
 	e := (*C.GdkEventButton)(unsafe.Pointer(event))
@@ -199,6 +209,7 @@ var area_button_press_event_callback = C.GCallback(C.our_area_button_press_event
 
 //export our_area_button_release_event_callback
 func our_area_button_release_event_callback(widget *C.GtkWidget, event *C.GdkEvent, data C.gpointer) C.gboolean {
+	defer recoverUIPanic()
 	e := (*C.GdkEventButton)(unsafe.Pointer(event))
 	me := MouseEvent{
 		// GDK button ID == our button ID with some exceptions taken care of by finishMouseEvent()
@@ -212,6 +223,7 @@ var area_button_release_event_callback = C.GCallback(C.our_area_button_release_e
 
 //export our_area_motion_notify_event_callback
 func our_area_motion_notify_event_callback(widget *C.GtkWidget, event *C.GdkEvent, data C.gpointer) C.gboolean {
+	defer recoverUIPanic()
 	e := (*C.GdkEventMotion)(unsafe.Pointer(event))
 	me := MouseEvent{}
 	finishMouseEvent(widget, data, me, 0, e.x, e.y, e.state, e.window)
@@ -226,6 +238,7 @@ var area_motion_notify_event_callback = C.GCallback(C.our_area_motion_notify_eve
 
 //export our_area_enterleave_notify_event_callback
 func our_area_enterleave_notify_event_callback(widget *C.GtkWidget, event *C.GdkEvent, data C.gpointer) C.gboolean {
+	defer recoverUIPanic()
 	s := (*sysData)(unsafe.Pointer(data))
 	s.clickCounter.reset()
 	return continueEventChain
@@ -259,6 +272,7 @@ func doKeyEvent(widget *C.GtkWidget, event *C.GdkEvent, data C.gpointer, up bool
 	}
 	ke.Up = up
 	repaint := s.handler.Key(ke)
+	s.areaCaretUpdate(false)
 	if repaint {
 		C.gtk_widget_queue_draw(widget)
 	}
@@ -266,6 +280,7 @@ func doKeyEvent(widget *C.GtkWidget, event *C.GdkEvent, data C.gpointer, up bool
 
 //export our_area_key_press_event_callback
 func our_area_key_press_event_callback(widget *C.GtkWidget, event *C.GdkEvent, data C.gpointer) C.gboolean {
+	defer recoverUIPanic()
 	doKeyEvent(widget, event, data, false)
 	return continueEventChain
 }
@@ -274,6 +289,7 @@ var area_key_press_event_callback = C.GCallback(C.our_area_key_press_event_callb
 
 //export our_area_key_release_event_callback
 func our_area_key_release_event_callback(widget *C.GtkWidget, event *C.GdkEvent, data C.gpointer) C.gboolean {
+	defer recoverUIPanic()
 	doKeyEvent(widget, event, data, true)
 	return continueEventChain
 }
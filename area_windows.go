@@ -28,14 +28,14 @@ func getScrollPos(hwnd _HWND) (xpos int32, ypos int32) {
 	si.fMask = _SIF_POS | _SIF_TRACKPOS
 	try.N("GetScrollInfo", hwnd, _SB_HORZ, &si)
 	if try.Err != nil {
-		panic(fmt.Errorf("error getting horizontal scroll position for Area: %v", try.Err))
+		panic(newError(nil, "getting horizontal scroll position for Area", "GetScrollInfo()", try.Err))
 	}
 	xpos = si.nPos
 	si.cbSize = uint32(unsafe.Sizeof(si)) // MSDN example code reinitializes this each time, so we'll do it too just to be safe
 	si.fMask = _SIF_POS | _SIF_TRACKPOS
 	try.N("GetScrollInfo", hwnd, _SB_VERT, &si)
 	if try.Err != nil {
-		panic(fmt.Errorf("error getting vertical scroll position for Area: %v", try.Err))
+		panic(newError(nil, "getting vertical scroll position for Area", "GetScrollInfo()", try.Err))
 	}
 	ypos = si.nPos
 	return xpos, ypos
@@ -70,7 +70,7 @@ func paintArea(s *sysData) {
 
 	hdc := _HANDLE(try.N("BeginPaint", s.hwnd, &ps))
 	if try.Err != nil {
-		panic(fmt.Errorf("error beginning Area repaint: %v", try.Err))
+		panic(s.newError("beginning Area repaint", "BeginPaint()", try.Err))
 	}
 	defer try.Detach().A("EndPaint", s.hwnd, &ps) // return value always nonzero according to MSDN
 
@@ -80,7 +80,7 @@ func paintArea(s *sysData) {
 	// this is how we fake drawing the background; see also http://msdn.microsoft.com/en-us/library/ms969905.aspx
 	rdc := _HANDLE(try.N("CreateCompatibleDC", hdc))
 	if try.Err != nil {
-		panic(fmt.Errorf("error creating off-screen rendering DC: %v", try.Err))
+		panic(s.newError("creating off-screen rendering DC", "CreateCompatibleDC()", try.Err))
 	}
 	defer try.Detach().N("DeleteDC", rdc)
 	// the bitmap has to be compatible with the window
@@ -88,12 +88,12 @@ func paintArea(s *sysData) {
 	// thanks to David Heffernan in http://stackoverflow.com/questions/23033636/winapi-gdi-fillrectcolor-btnface-fills-with-strange-grid-like-brush-on-window
 	rbitmap := _HANDLE(try.N("CreateCompatibleBitmap", hdc, xrect.right-xrect.left, xrect.bottom-xrect.top))
 	if try.Err != nil {
-		panic(fmt.Errorf("error creating off-screen rendering bitmap: %v", try.Err))
+		panic(s.newError("creating off-screen rendering bitmap", "CreateCompatibleBitmap()", try.Err))
 	}
 	defer try.Detach().N("DeleteObject", rbitmap)
 	prevrbitmap := _HANDLE(try.N("SelectObject", rdc, rbitmap))
 	if try.Err != nil {
-		panic(fmt.Errorf("error connecting off-screen rendering bitmap to off-screen rendering DC: %v", try.Err))
+		panic(s.newError("connecting off-screen rendering bitmap to off-screen rendering DC", "SelectObject()", try.Err))
 	}
 	defer try.Detach().N("SelectObject", rdc, prevrbitmap)
 	rrect := _RECT{
@@ -104,10 +104,11 @@ func paintArea(s *sysData) {
 	}
 	try.N("FillRect", rdc, &rrect, areaBackgroundBrush)
 	if try.Err != nil { // failure
-		panic(fmt.Errorf("error filling off-screen rendering bitmap with the system background color: %v", try.Err))
+		panic(s.newError("filling off-screen rendering bitmap with the system background color", "FillRect()", try.Err))
 	}
 
 	i := s.handler.Paint(cliprect)
+	s.areaCaretUpdate(false)
 	// don't convert to BRGA just yet; see below
 
 	// now we need to shove realbits into a bitmap
@@ -131,7 +132,7 @@ func paintArea(s *sysData) {
 		0, // we're not dealing with hSection or dwOffset
 		0))
 	if try.Err != nil {
-		panic(fmt.Errorf("error creating HBITMAP for image returned by AreaHandler.Paint(): %v", try.Err))
+		panic(s.newError("creating HBITMAP for image returned by AreaHandler.Paint()", "CreateDIBSection()", try.Err))
 	}
 	defer try.Detach().N("DeleteObject", ibitmap)
 
@@ -146,12 +147,12 @@ func paintArea(s *sysData) {
 	// Ninjifox just makes another compatible DC; we'll do the same
 	idc := _HANDLE(try.N("CreateCompatibleDC", hdc))
 	if try.Err != nil {
-		panic(fmt.Errorf("error creating HDC for image returned by AreaHandler.Paint(): %v", try.Err))
+		panic(s.newError("creating HDC for image returned by AreaHandler.Paint()", "CreateCompatibleDC()", try.Err))
 	}
 	defer try.Detach().N("DeleteDC", idc)
 	previbitmap := _HANDLE(try.N("SelectObject", idc, ibitmap))
 	if try.Err != nil {
-		panic(fmt.Errorf("error connecting HBITMAP for image returned by AreaHandler.Paint() to its HDC: %v", try.Err))
+		panic(s.newError("connecting HBITMAP for image returned by AreaHandler.Paint() to its HDC", "SelectObject()", try.Err))
 	}
 	defer try.Detach().N("SelectObject", idc, previbitmap)
 
@@ -175,7 +176,7 @@ func paintArea(s *sysData) {
 		i.Rect.Dy(),
 		blendfunc.arg())
 	if try.Err != nil {
-		panic(fmt.Errorf("error alpha-blending image returned by AreaHandler.Paint() onto background: %v", try.Err))
+		panic(s.newError("alpha-blending image returned by AreaHandler.Paint() onto background", "AlphaBlend()", try.Err))
 	}
 
 	// and finally we can just blit that into the window
@@ -190,7 +191,7 @@ func paintArea(s *sysData) {
 		0,
 		_SRCCOPY)
 	if try.Err != nil {
-		panic(fmt.Errorf("error blitting Area image to Area: %v", try.Err))
+		panic(s.newError("blitting Area image to Area", "BitBlt()", try.Err))
 	}
 }
 
@@ -200,7 +201,7 @@ func getAreaControlSize(hwnd _HWND) (width int, height int) {
 
 	try.N("GetClientRect", hwnd, &rect)
 	if try.Err != nil {
-		panic(fmt.Errorf("error getting size of actual Area control: %v", try.Err))
+		panic(newError(nil, "getting size of actual Area control", "GetClientRect()", try.Err))
 	}
 	return int(rect.right - rect.left),
 		int(rect.bottom - rect.top)
@@ -222,7 +223,7 @@ func scrollArea(s *sysData, wparam _WPARAM, which uintptr) {
 	si.fMask = _SIF_POS | _SIF_TRACKPOS
 	try.N("GetScrollInfo", s.hwnd, which, &si)
 	if try.Err != nil {
-		panic(fmt.Errorf("error getting current scroll position for scrolling: %v", try.Err))
+		panic(s.newError("getting current scroll position for scrolling", "GetScrollInfo()", try.Err))
 	}
 
 	newpos := si.nPos
@@ -274,7 +275,7 @@ func scrollArea(s *sysData, wparam _WPARAM, which uintptr) {
 		0,
 		_SW_INVALIDATE|_SW_ERASE) // mark the remaining rect as needing redraw and erase...
 	if try.Err != nil {
-		panic(fmt.Errorf("error scrolling Area: %v", try.Err))
+		panic(s.newError("scrolling Area", "ScrollWindowEx()", try.Err))
 	}
 	// ...but don't redraw the window yet; we need to apply our scroll changes
 
@@ -287,7 +288,7 @@ func scrollArea(s *sysData, wparam _WPARAM, which uintptr) {
 	// NOW redraw it
 	try.N("UpdateWindow", s.hwnd)
 	if try.Err != nil {
-		panic(fmt.Errorf("error updating Area after scrolling: %v", try.Err))
+		panic(s.newError("updating Area after scrolling", "UpdateWindow()", try.Err))
 	}
 }
 
@@ -325,12 +326,9 @@ func repaintArea(s *sysData) {
 		0,     // the whole area
 		_TRUE) // have Windows erase if possible
 	if try.Err != nil {
-		panic(fmt.Errorf("error flagging Area as needing repainting after event (last error: %v)", try.Err))
-	}
-	try.N("UpdateWindow", s.hwnd)
-	if try.Err != nil {
-		panic(fmt.Errorf("error repainting Area after event: %v", try.Err))
+		panic(s.newError("flagging Area as needing repainting after event", "InvalidateRect()", try.Err))
 	}
+	// don't UpdateWindow() here; Windows sends WM_PAINT once there are no other messages waiting, so several repaints in a row only result in one WM_PAINT
 }
 
 func getModifiers() (m Modifiers) {
@@ -397,6 +395,7 @@ func areaMouseEvent(s *sysData, button uint, up bool, wparam _WPARAM, lparam _LP
 		me.Held = append(me.Held, 5)
 	}
 	repaint := s.handler.Mouse(me)
+	s.areaCaretUpdate(false)
 	if repaint {
 		repaintArea(s)
 	}
@@ -432,6 +431,7 @@ func areaKeyEvent(s *sysData, up bool, wparam _WPARAM, lparam _LPARAM) {
 	}
 	ke.Up = up
 	repaint := s.handler.Key(ke)
+	s.areaCaretUpdate(false)
 	if repaint {
 		repaintArea(s)
 	}
@@ -520,6 +520,7 @@ var modonlykeys = map[_WPARAM]Modifiers{
 }
 
 func areaWndProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM) _LRESULT {
+	defer recoverUIPanic()
 	s := getSysData(hwnd)
 	if s == nil { // not yet saved
 		return storeSysData(hwnd, uMsg, wParam, lParam)
@@ -534,9 +535,18 @@ func areaWndProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM) _LRESU
 		return 1
 	case _WM_HSCROLL:
 		scrollArea(s, wParam, _SB_HORZ)
+		s.areaCaretUpdate(true) // the caret moved with the rest of the Area
 		return 0
 	case _WM_VSCROLL:
 		scrollArea(s, wParam, _SB_VERT)
+		s.areaCaretUpdate(true)
+		return 0
+	case _WM_SETFOCUS:
+		// see areacaret_windows.go
+		s.areaCaretUpdate(true)
+		return 0
+	case _WM_KILLFOCUS:
+		s.areaCaretKillFocus()
 		return 0
 	case _WM_SIZE:
 		adjustAreaScrollbars(s)
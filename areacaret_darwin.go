@@ -0,0 +1,30 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image"
+)
+
+// #include "objc_darwin.h"
+import "C"
+
+// runs on the UI thread; see sysData.areaCaretUpdate() and areacaret_darwin.m
+func (s *sysData) setAreaCaret(r image.Rectangle) {
+	if r == image.ZR { // nothing to follow; Zoom stays where it is
+		return
+	}
+	C.areaSetCaret(areaInScrollView(s.id), C.struct_xrect{
+		x:      C.intptr_t(r.Min.X),
+		y:      C.intptr_t(r.Min.Y),
+		width:  C.intptr_t(r.Dx()),
+		height: C.intptr_t(r.Dy()),
+	})
+}
+
+//export areaView_becomeFirstResponder
+func areaView_becomeFirstResponder(self C.id) {
+	defer recoverUIPanic()
+	s := getSysData(self)
+	s.areaCaretUpdate(true)
+}
@@ -0,0 +1,39 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#include <ApplicationServices/ApplicationServices.h>
+#import <AppKit/NSView.h>
+#import <AppKit/NSWindow.h>
+#import <AppKit/NSScreen.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSView(x) to(NSView, (x))
+
+/*
+Zoom has a function for programs that draw their own text to tell it where the insertion point is, UAZoomChangeFocus(); it only needs to be called while Zoom is on, and only makes sense while the Area is the first responder of its window.
+It wants the rectangle in global coordinates: (0,0) is the top-left corner of the main screen (the first one, with the menu bar), and y grows downward; Cocoa's screen coordinates grow upward from the bottom-left corner of the same screen.
+*/
+
+// see areacaret_darwin.go
+void areaSetCaret(id area, struct xrect r)
+{
+	NSView *v;
+	NSWindow *w;
+	NSRect nr;
+	CGRect cr;
+
+	if (!UAZoomEnabled())
+		return;
+	v = toNSView(area);
+	w = [v window];
+	if (w == nil || [w firstResponder] != v)
+		return;
+	nr = NSMakeRect((CGFloat) r.x, (CGFloat) r.y, (CGFloat) r.width, (CGFloat) r.height);
+	nr = [v convertRect:nr toView:nil];
+	nr = [w convertRectToScreen:nr];
+	cr.origin.x = nr.origin.x;
+	cr.origin.y = NSMaxY([[[NSScreen screens] objectAtIndex:0] frame]) - NSMaxY(nr);
+	cr.size.width = nr.size.width;
+	cr.size.height = nr.size.height;
+	UAZoomChangeFocus(&cr, &cr, kUAZoomFocusTypeInsertionPoint);
+}
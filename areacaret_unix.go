@@ -0,0 +1,19 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"image"
+)
+
+/*
+GNOME's magnifier follows the caret through AT-SPI, which asks the accessible object of the focused widget for it through AtkText (the caret offset and the extents of the character there); GtkDrawingArea's accessible object has no AtkText, and giving it one means a GObject subclass of GtkWidgetAccessible in C, set with gtk_widget_class_set_accessible_type() on a GtkDrawingArea subclass of our own.
+Until then, there's nothing to tell.
+*/
+
+// see sysData.areaCaretUpdate()
+func (s *sysData) setAreaCaret(r image.Rectangle) {
+	// do nothing; see above
+}
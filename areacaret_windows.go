@@ -0,0 +1,55 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image"
+)
+
+/*
+Magnifier and Narrator follow the system caret (they hear about it as OBJID_CARET), which each thread has at most one of, belonging to the window with the keyboard focus.
+So an Area with an AreaCaretHandler makes one when it gets the focus (WM_SETFOCUS) and moves it along with Caret(), and destroys it when it loses the focus (WM_KILLFOCUS).
+The caret is never shown (no ShowCaret()): the AreaHandler draws its own, and a hidden caret is still followed.
+Its position is in client coordinates, so scrolling the Area moves it too; see areaWndProc().
+*/
+
+var (
+	_createCaret  = user32.NewProc("CreateCaret")
+	_destroyCaret = user32.NewProc("DestroyCaret")
+	_setCaretPos  = user32.NewProc("SetCaretPos")
+)
+
+// runs on the UI thread; see sysData.areaCaretUpdate()
+// if any of these fail there's nothing we can do about it; the Area still works, it's just not followed
+func (s *sysData) setAreaCaret(r image.Rectangle) {
+	focus, _, _ := _getFocus.Call()
+	if _HWND(focus) != s.hwnd { // not ours to move; we'll be asked again on WM_SETFOCUS
+		return
+	}
+	if r == image.ZR {
+		_destroyCaret.Call()
+		return
+	}
+	xpos, ypos := getScrollPos(s.hwnd)
+	r = r.Sub(image.Pt(int(xpos), int(ypos)))
+	// this replaces the caret we made before, if any; a width of 0 would be the system's caret width
+	width := r.Dx()
+	if width == 0 {
+		width = 1
+	}
+	_createCaret.Call(
+		uintptr(s.hwnd),
+		uintptr(0), // a solid caret, if it were shown
+		uintptr(width),
+		uintptr(r.Dy()))
+	_setCaretPos.Call(
+		uintptr(r.Min.X),
+		uintptr(r.Min.Y))
+}
+
+// runs on the UI thread
+func (s *sysData) areaCaretKillFocus() {
+	if _, ok := s.handler.(AreaCaretHandler); ok {
+		_destroyCaret.Call()
+	}
+}
@@ -0,0 +1,19 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// see badge_darwin.m
+
+func (s *sysData) setBadge(badge string) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.badge = badge
+		s.badgeView = C.setButtonBadge(s.id, s.badgeView, toNSString(badge))
+		ret <- struct{}{}
+	})
+	<-ret
+}
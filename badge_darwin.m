@@ -0,0 +1,118 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSView.h>
+#import <AppKit/NSButton.h>
+#import <AppKit/NSColor.h>
+#import <AppKit/NSBezierPath.h>
+#import <AppKit/NSFont.h>
+#import <AppKit/NSStringDrawing.h>
+#import <AppKit/NSAttributedString.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSView(x) to(NSView, (x))
+#define toNSString(x) to(NSString, (x))
+
+extern NSRect dummyRect;
+
+/*
+NSButtons have no badges, so a badge is a small view of our own, a subview of the button in its top-right corner.
+Autoresizing keeps it in that corner if the button is resized.
+*/
+
+#define badgeInset 2		/* between the badge and the edges of the button */
+
+@interface badgeView : NSView {
+	NSString *text;
+}
+- (void)setText:(NSString *)t;
+- (NSDictionary *)textAttributes;
+@end
+
+@implementation badgeView
+
+- (void)dealloc
+{
+	[text release];
+	[super dealloc];
+}
+
+- (NSDictionary *)textAttributes
+{
+	return [NSDictionary dictionaryWithObjectsAndKeys:
+		[NSFont boldSystemFontOfSize:[NSFont smallSystemFontSize]], NSFontAttributeName,
+		highContrast() ? [NSColor selectedTextColor] : [NSColor whiteColor], NSForegroundColorAttributeName,
+		nil];
+}
+
+// also sizes the view to fit; a pill as tall as the text, at least as wide as it is tall
+- (void)setText:(NSString *)t
+{
+	NSSize size;
+	NSRect r;
+
+	[t retain];
+	[text release];
+	text = t;
+	size = [text sizeWithAttributes:[self textAttributes]];
+	r = [self frame];
+	r.size.height = ceil(size.height);
+	r.size.width = ceil(size.width + r.size.height / 2);
+	if (r.size.width < r.size.height)
+		r.size.width = r.size.height;
+	[self setFrameSize:r.size];
+	[self setNeedsDisplay:YES];
+}
+
+- (void)drawRect:(NSRect)r
+{
+	NSRect b;
+	NSSize size;
+	NSPoint p;
+
+	b = [self bounds];
+	if (highContrast())		// see highcontrast_darwin.m
+		[[NSColor selectedTextBackgroundColor] set];
+	else
+		[[NSColor colorWithCalibratedRed:0.85 green:0.11 blue:0.11 alpha:1] set];
+	[[NSBezierPath bezierPathWithRoundedRect:b xRadius:(NSHeight(b) / 2) yRadius:(NSHeight(b) / 2)] fill];
+	size = [text sizeWithAttributes:[self textAttributes]];
+	p.x = (NSWidth(b) - size.width) / 2;
+	p.y = (NSHeight(b) - size.height) / 2;
+	[text drawAtPoint:p withAttributes:[self textAttributes]];
+}
+
+@end
+
+// returns the badge view, or nil if there isn't one anymore; view is the one returned last time, if any
+id setButtonBadge(id button, id view, id text)
+{
+	badgeView *v;
+	NSRect r, br;
+
+	v = to(badgeView, view);
+	if ([toNSString(text) length] == 0) {
+		[v removeFromSuperview];		// does nothing if v is nil
+		return nil;
+	}
+	if (v == nil) {
+		v = [[badgeView alloc]
+			initWithFrame:dummyRect];
+		[toNSView(button) addSubview:v];
+		[v release];		// the button has it now
+	}
+	[v setText:toNSString(text)];
+	// and move it to the top-right corner
+	br = [toNSView(button) bounds];
+	r = [v frame];
+	r.origin.x = NSMaxX(br) - NSWidth(r) - badgeInset;
+	if ([toNSView(button) isFlipped]) {
+		r.origin.y = NSMinY(br) + badgeInset;
+		[v setAutoresizingMask:(NSViewMinXMargin | NSViewMaxYMargin)];
+	} else {
+		r.origin.y = NSMaxY(br) - NSHeight(r) - badgeInset;
+		[v setAutoresizingMask:(NSViewMinXMargin | NSViewMinYMargin)];
+	}
+	[v setFrame:r];
+	return v;
+}
@@ -0,0 +1,94 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"image/color"
+	"unsafe"
+)
+
+/*
+GTK+ buttons have no badges, so we draw them ourselves: we connect to the button's draw signal after GTK+'s own handler, so the badge goes on top of the button.
+The handler is only connected the first time a button gets a badge; after that, it just doesn't draw anything if the badge is empty.
+*/
+
+// #include "gtk_unix.h"
+// extern gboolean our_badge_draw_callback(GtkWidget *, cairo_t *, gpointer);
+// /* because cgo is flaky with macros; static inline because we have //exports */
+// static inline void gSignalConnectAfter(GtkWidget *widget, char *signal, GCallback callback, void *data) { g_signal_connect_after(widget, signal, callback, data); }
+import "C"
+
+const (
+	badgeInset = 2 // pixels between the badge and the edges of the button
+)
+
+//export our_badge_draw_callback
+func our_badge_draw_callback(widget *C.GtkWidget, cr *C.cairo_t, data C.gpointer) C.gboolean {
+	defer recoverUIPanic()
+	var tw, th C.int
+
+	s := (*sysData)(unsafe.Pointer(data))
+	if s.badge == "" {
+		return C.FALSE // continue the event chain
+	}
+	cbadge := C.CString(s.badge)
+	defer C.free(unsafe.Pointer(cbadge))
+	layout := C.gtk_widget_create_pango_layout(widget, togstr(cbadge))
+	defer C.g_object_unref(C.gpointer(unsafe.Pointer(layout)))
+	C.pango_layout_get_pixel_size(layout, &tw, &th)
+
+	// a pill as tall as the text, at least as wide as it is tall
+	height := C.double(th)
+	width := C.double(tw) + height/2
+	if width < height {
+		width = height
+	}
+	right := C.double(C.gtk_widget_get_allocated_width(widget) - badgeInset)
+	top := C.double(badgeInset)
+	radius := height / 2
+	C.cairo_new_path(cr)
+	C.cairo_arc(cr, right-width+radius, top+radius, radius, C.G_PI/2, 3*C.G_PI/2)
+	C.cairo_arc(cr, right-radius, top+radius, radius, -C.G_PI/2, C.G_PI/2)
+	C.cairo_close_path(cr)
+	on, colors := currentHighContrast() // see highcontrast.go
+	if on {
+		cairoSetSourceRGBA(cr, colors.highlight)
+	} else {
+		C.cairo_set_source_rgb(cr, 0.85, 0.11, 0.11)
+	}
+	C.cairo_fill(cr)
+
+	if on {
+		cairoSetSourceRGBA(cr, colors.highlightText)
+	} else {
+		C.cairo_set_source_rgb(cr, 1, 1, 1)
+	}
+	C.cairo_move_to(cr, right-width+(width-C.double(tw))/2, top)
+	C.pango_cairo_show_layout(cr, layout)
+	return C.FALSE // and let anything else draw too
+}
+
+var badge_draw_callback = C.GCallback(C.our_badge_draw_callback)
+
+func cairoSetSourceRGBA(cr *C.cairo_t, c color.RGBA) {
+	C.cairo_set_source_rgb(cr, C.double(c.R)/0xFF, C.double(c.G)/0xFF, C.double(c.B)/0xFF)
+}
+
+func (s *sysData) setBadge(badge string) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.badge = badge
+		if !s.badgeConnected && badge != "" {
+			csig := C.CString("draw")
+			defer C.free(unsafe.Pointer(csig))
+			C.gSignalConnectAfter(s.widget, csig, badge_draw_callback, unsafe.Pointer(s))
+			s.badgeConnected = true
+		}
+		C.gtk_widget_queue_draw(s.widget)
+		ret <- struct{}{}
+	})
+	<-ret
+}
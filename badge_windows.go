@@ -0,0 +1,155 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+/*
+Windows buttons have no badges, so we draw them ourselves after the button has drawn itself.
+Common Controls 6 buttons (see comctl_windows.go) send NM_CUSTOMDRAW to their parent (the Window, so stdWndProc()) when they paint; if a button has a badge, we ask for a notification after painting (CDRF_NOTIFYPOSTPAINT), and draw the badge then.
+*/
+
+type _NMHDR struct {
+	hwndFrom _HWND
+	idFrom   uintptr
+	code     uint32
+}
+
+func (l _LPARAM) NMHDR() *_NMHDR {
+	return (*_NMHDR)(unsafe.Pointer(l))
+}
+
+type _NMCUSTOMDRAW struct {
+	hdr         _NMHDR
+	dwDrawStage uint32
+	hdc         _HANDLE
+	rc          _RECT
+	dwItemSpec  uintptr
+	uItemState  uint32
+	lItemlParam _LPARAM
+}
+
+func (l _LPARAM) NMCUSTOMDRAW() *_NMCUSTOMDRAW {
+	return (*_NMCUSTOMDRAW)(unsafe.Pointer(l))
+}
+
+const (
+	badgeColor     = 0x1C1CD9 // COLORREF (0x00BBGGRR) of the background
+	badgeTextColor = 0xFFFFFF
+	badgeInset     = 2 // pixels between the badge and the edges of the button
+)
+
+var (
+	_getStockObject = gdi32.NewProc("GetStockObject")
+	_roundRect      = gdi32.NewProc("RoundRect")
+	_setTextColor   = gdi32.NewProc("SetTextColor")
+)
+
+func (s *sysData) setBadge(badge string) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.badge = badge
+		// this sends NM_CUSTOMDRAW again
+		r1, _, err := _redrawWindow.Call(
+			uintptr(s.hwnd),
+			uintptr(0),
+			uintptr(0),
+			uintptr(_RDW_ERASE|_RDW_INVALIDATE))
+		if r1 == 0 {
+			panic(s.newError("redrawing button after changing badge", "RedrawWindow()", err))
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// runs on the UI thread; the return value is what stdWndProc() should return for the NM_CUSTOMDRAW
+func (s *sysData) badgeCustomDraw(nm *_NMCUSTOMDRAW) _LRESULT {
+	if s.badge == "" {
+		return _CDRF_DODEFAULT
+	}
+	switch nm.dwDrawStage {
+	case _CDDS_PREPAINT:
+		return _CDRF_NOTIFYPOSTPAINT
+	case _CDDS_POSTPAINT:
+		drawBadge(nm.hdc, nm.rc, s.badge)
+	}
+	return _CDRF_DODEFAULT
+}
+
+// if any of this fails there's nothing we can do about it, so just draw as much as we can; the button is still usable
+func drawBadge(dc _HANDLE, rc _RECT, badge string) {
+	var size _SIZE
+
+	text := syscall.StringToUTF16(badge)
+	oldfont, _, _ := _selectObject.Call(
+		uintptr(dc),
+		uintptr(controlFont))
+	defer _selectObject.Call(
+		uintptr(dc),
+		oldfont)
+	_getTextExtentPoint32.Call(
+		uintptr(dc),
+		uintptr(unsafe.Pointer(&text[0])),
+		uintptr(len(text)-1), // without the terminating NUL
+		uintptr(unsafe.Pointer(&size)))
+
+	// a pill as tall as the text, at least as wide as it is tall
+	height := size.cy
+	width := size.cx + height/2
+	if width < height {
+		width = height
+	}
+	var r _RECT
+	r.right = rc.right - badgeInset
+	r.left = r.right - width
+	r.top = rc.top + badgeInset
+	r.bottom = r.top + height
+
+	background, foreground := uintptr(badgeColor), uintptr(badgeTextColor)
+	if on, _ := currentHighContrast(); on { // see highcontrast.go
+		background, foreground = sysColor(_COLOR_HIGHLIGHT), sysColor(_COLOR_HIGHLIGHTTEXT)
+	}
+	brush, _, _ := _createSolidBrush.Call(background)
+	if brush != 0 {
+		defer _deleteObject.Call(brush)
+		oldbrush, _, _ := _selectObject.Call(
+			uintptr(dc),
+			brush)
+		defer _selectObject.Call(
+			uintptr(dc),
+			oldbrush)
+	}
+	pen, _, _ := _getStockObject.Call(uintptr(_NULL_PEN)) // stock objects are never deleted
+	oldpen, _, _ := _selectObject.Call(
+		uintptr(dc),
+		pen)
+	defer _selectObject.Call(
+		uintptr(dc),
+		oldpen)
+	_roundRect.Call(
+		uintptr(dc),
+		uintptr(r.left),
+		uintptr(r.top),
+		uintptr(r.right+1), // RoundRect() leaves out the right and bottom edges when there's no pen
+		uintptr(r.bottom+1),
+		uintptr(height),
+		uintptr(height))
+
+	_setTextColor.Call(
+		uintptr(dc),
+		foreground)
+	_setBkMode.Call(
+		uintptr(dc),
+		uintptr(_TRANSPARENT))
+	_drawText.Call(
+		uintptr(dc),
+		uintptr(unsafe.Pointer(&text[0])),
+		negConst(-1), // NUL-terminated
+		uintptr(unsafe.Pointer(&r)),
+		uintptr(_DT_CENTER|_DT_SINGLELINE|_DT_VCENTER|_DT_NOPREFIX))
+}
@@ -0,0 +1,72 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+)
+
+// BannerKind says what a banner shown with Window.ShowBanner is about, which decides how it looks (usually its color).
+type BannerKind uintptr
+
+const (
+	BannerInfo    BannerKind = iota // something the user should know about, such as "A new version is available"
+	BannerWarning                   // something that might need the user's attention, such as "Connection lost"
+	BannerError                     // something that went wrong, such as "Could not save the file"
+)
+
+// ShowBanner shows a banner with the given text across the top of the Window, over its controls, with a button for each of actions and a button to dismiss it.
+// The text is shown on a single line, and is ellipsized if it doesn't fit, so keep it short.
+// Where the system can animate it, the banner slides in from the top, unless animations are off (see AnimationsEnabled).
+// The banner is drawn over the Window's controls, so they don't move to make room for it; keep this in mind when deciding what goes at the top of a Window that shows banners.
+//
+// The returned channel receives the index into actions of the button the user clicked, or -1 if the banner went away without an action being picked: if the user dismissed it, if HideBanner was called, if another banner replaced it, or if the Window was destroyed.
+// Either way, the banner goes away, and the channel receives exactly one value.
+// A Window shows at most one banner at a time.
+//
+// It panics if the Window has not been created or has been destroyed, or if kind isn't one of the BannerKind constants.
+func (w *Window) ShowBanner(text string, kind BannerKind, actions ...string) <-chan int {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if !w.created {
+		panic("attempt to show banner in Window before it has been created")
+	}
+	if w.destroyed {
+		panic("attempt to show banner in Window that has been destroyed")
+	}
+	if kind > BannerError {
+		panic(fmt.Errorf("invalid BannerKind %d given to Window.ShowBanner()", kind))
+	}
+	result := make(chan int, 1) // so the UI thread never waits on it
+	w.sysData.showBanner(text, kind, actions, result)
+	return result
+}
+
+// HideBanner hides the banner shown with ShowBanner, if there is one; its channel receives -1.
+// It panics if the Window has not been created.
+func (w *Window) HideBanner() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if !w.created {
+		panic("attempt to hide banner in Window before it has been created")
+	}
+	if w.destroyed {
+		return
+	}
+	w.sysData.hideBanner()
+}
+
+// the label of the button that dismisses a banner, on systems that don't have one of their own (GTK+ has a stock Close button)
+const bannerDismissText = "×"
+
+// each sysData.showBanner() and sysData.hideBanner() calls this on the UI thread when a banner goes away (and the first stores the new banner's channel after), as does the platform code when one of the banner's buttons is clicked
+// which is the index of the action or -1 if there wasn't one; see Window.ShowBanner()
+func (s *cSysData) bannerDone(which int) {
+	checkUIThread("sysData.bannerDone()")
+	if s.bannerResult != nil {
+		s.bannerResult <- which
+		s.bannerResult = nil
+	}
+}
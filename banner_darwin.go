@@ -0,0 +1,45 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// see banner_darwin.m
+
+func (s *sysData) showBanner(text string, kind BannerKind, actions []string, result chan int) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.removeBanner(-1)
+		banner := C.makeBanner(toNSString(text), C.intptr_t(kind))
+		for i, a := range actions {
+			C.bannerAddButton(banner, toNSString(a), C.intptr_t(i), appDelegate)
+		}
+		C.bannerAddButton(banner, toNSString(bannerDismissText), -1, appDelegate)
+		C.showBanner(s.id, banner)
+		s.banner = banner
+		s.bannerResult = result
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) hideBanner() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.removeBanner(-1)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// runs on the UI thread; which is for sysData.bannerDone()
+func (s *sysData) removeBanner(which int) {
+	if s.banner != nil {
+		C.removeBanner(s.banner)
+		s.banner = nil
+	}
+	s.bannerDone(which)
+}
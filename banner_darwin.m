@@ -0,0 +1,146 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSWindow.h>
+#import <AppKit/NSView.h>
+#import <AppKit/NSBox.h>
+#import <AppKit/NSButton.h>
+#import <AppKit/NSTextField.h>
+#import <AppKit/NSColor.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSWindow(x) to(NSWindow, (x))
+#define toNSBox(x) to(NSBox, (x))
+#define toNSButton(x) to(NSButton, (x))
+#define toNSView(x) to(NSView, (x))
+#define toNSTextField(x) to(NSTextField, (x))
+
+#define toNSInteger(x) ((NSInteger) (x))
+
+extern NSRect dummyRect;
+
+/*
+A banner is an NSBox drawn as a plain colored rectangle, with a label and buttons in it, put in the window's content view above everything else.
+The label is the first subview of the box's content view; the buttons follow in the order they were added.
+Autoresizing keeps the banner across the top of the window and the buttons at its right as the window is resized, so the Go side doesn't have to.
+The buttons send bannerClicked: to the app delegate with the index of their action (or -1 for the dismiss button) as their tag.
+*/
+
+#define bannerMargin 20		/* the HIG's window margin */
+#define bannerPadding 8
+
+// in the order of the BannerKind constants
+static NSColor *bannerColor(intptr_t kind)
+{
+	switch (kind) {
+	case 1:		// BannerWarning
+		return [NSColor colorWithCalibratedRed:0.99 green:0.97 blue:0.89 alpha:1];
+	case 2:		// BannerError
+		return [NSColor colorWithCalibratedRed:0.95 green:0.87 blue:0.87 alpha:1];
+	}
+	return [NSColor colorWithCalibratedRed:0.85 green:0.93 blue:0.97 alpha:1];	// BannerInfo
+}
+
+id makeBanner(id text, intptr_t kind)
+{
+	NSBox *box;
+	NSTextField *label;
+
+	box = [[NSBox alloc]
+		initWithFrame:dummyRect];
+	[box setBoxType:NSBoxCustom];
+	[box setTitlePosition:NSNoTitle];
+	[box setBorderType:NSLineBorder];
+	[box setBorderWidth:1];
+	if (highContrast()) {		// see highcontrast_darwin.m
+		[box setFillColor:[NSColor textBackgroundColor]];
+		[box setBorderColor:[NSColor textColor]];
+	} else {
+		[box setFillColor:bannerColor(kind)];
+		[box setBorderColor:[bannerColor(kind) shadowWithLevel:0.2]];
+	}
+	[box setContentViewMargins:NSZeroSize];
+	label = makeLabel();
+	applyStandardControlFont(label);
+	[label setStringValue:text];
+	[[label cell] setLineBreakMode:NSLineBreakByTruncatingTail];
+	[[box contentView] addSubview:label];
+	[label release];		// the content view has it now
+	return box;
+}
+
+void bannerAddButton(id banner, id title, intptr_t tag, id delegate)
+{
+	NSButton *button;
+
+	button = makeButton();
+	applyStandardControlFont(button);
+	[button setTitle:title];
+	[button setTag:toNSInteger(tag)];
+	[button setTarget:delegate];
+	[button setAction:@selector(bannerClicked:)];
+	[button sizeToFit];
+	[[toNSBox(banner) contentView] addSubview:button];
+	[button release];
+}
+
+void showBanner(id window, id banner)
+{
+	NSView *cv;
+	NSArray *views;
+	NSView *v;
+	NSRect r, final;
+	CGFloat height, x;
+	NSUInteger i;
+
+	cv = [toNSWindow(window) contentView];
+	views = [[toNSBox(banner) contentView] subviews];
+	height = 0;
+	for (i = 1; i < [views count]; i++)
+		height = MAX(height, NSHeight([toNSView([views objectAtIndex:i]) frame]));
+	height += 2 * bannerPadding;
+
+	// size the box first so its content view is the right size for the layout below
+	final = NSMakeRect(0, NSHeight([cv bounds]) - height, NSWidth([cv bounds]), height);
+	[toNSBox(banner) setFrame:final];
+	[toNSBox(banner) setAutoresizingMask:(NSViewWidthSizable | NSViewMinYMargin)];
+
+	// the last button added (the dismiss button) goes at the far right, with the others to its left in the order they were added
+	x = NSWidth(final) - bannerPadding;
+	for (i = [views count] - 1; i >= 1; i--) {
+		v = toNSView([views objectAtIndex:i]);
+		r = [v frame];
+		x -= NSWidth(r);
+		r.origin.x = x;
+		r.origin.y = (height - NSHeight(r)) / 2;
+		[v setFrame:r];
+		[v setAutoresizingMask:NSViewMinXMargin];
+		x -= bannerPadding;
+	}
+	v = toNSView([views objectAtIndex:0]);
+	[toNSTextField(v) sizeToFit];
+	r = [v frame];
+	r.origin.x = bannerMargin;
+	r.origin.y = (height - NSHeight(r)) / 2;
+	r.size.width = x - bannerMargin;
+	[v setFrame:r];
+	[v setAutoresizingMask:NSViewWidthSizable];
+
+	// start just above the top of the content view (which clips it) and slide down
+	r = final;
+	r.origin.y = NSHeight([cv bounds]);
+	[toNSBox(banner) setFrame:r];
+	[cv addSubview:banner positioned:NSWindowAbove relativeTo:nil];
+	[toNSBox(banner) release];		// the content view has it now
+	if (animationsEnabled())		// see animations_darwin.m
+		[[toNSBox(banner) animator] setFrame:final];
+	else
+		[toNSBox(banner) setFrame:final];
+}
+
+void removeBanner(id banner)
+{
+	// if one of the banner's buttons was clicked, we're in the middle of its action, so keep it alive until the event is done
+	[[toNSBox(banner) retain] autorelease];
+	[toNSBox(banner) removeFromSuperview];
+}
@@ -0,0 +1,116 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+/*
+A banner is a GtkInfoBar, which is made for this, put in the window's GtkLayout on top of the controls.
+The GtkInfoBar is in a GtkEventBox so it has its own GdkWindow; without one, controls that have their own GdkWindows (GtkEntry, for instance) would draw over it.
+GTK+ 3.4 has no way to animate the banner in (GtkRevealer is new in 3.10), so it just appears.
+*/
+
+// #include "gtk_unix.h"
+// extern void our_banner_response_callback(GtkInfoBar *, gint, gpointer);
+import "C"
+
+var bannerMessageTypes = map[BannerKind]C.GtkMessageType{
+	BannerInfo:    C.GTK_MESSAGE_INFO,
+	BannerWarning: C.GTK_MESSAGE_WARNING,
+	BannerError:   C.GTK_MESSAGE_ERROR,
+}
+
+func togtkinfobar(what *C.GtkWidget) *C.GtkInfoBar {
+	return (*C.GtkInfoBar)(unsafe.Pointer(what))
+}
+
+//export our_banner_response_callback
+func our_banner_response_callback(bar *C.GtkInfoBar, response C.gint, what C.gpointer) {
+	defer recoverUIPanic()
+	// called when the user clicks one of the banner's buttons
+	s := (*sysData)(unsafe.Pointer(what))
+	which := int(response)
+	if response < 0 { // GTK_RESPONSE_CLOSE from the dismiss button, or one of GTK+'s own
+		which = -1
+	}
+	s.removeBanner(which)
+}
+
+var banner_response_callback = C.GCallback(C.our_banner_response_callback)
+
+var bannerDismissStock = func() *C.gchar {
+	return togstr(C.CString("gtk-close")) // GTK_STOCK_CLOSE; never freed, as with logviewEndMark
+}()
+
+// runs on the UI thread
+func (s *sysData) newBanner(text string, kind BannerKind, actions []string) *C.GtkWidget {
+	bar := C.gtk_info_bar_new()
+	C.gtk_info_bar_set_message_type(togtkinfobar(bar), bannerMessageTypes[kind])
+	label := gtk_label_new()
+	gtk_label_set_text(label, text)
+	gtk_label_set_ellipsize(label, EllipsizeEnd)
+	content := C.gtk_info_bar_get_content_area(togtkinfobar(bar))
+	C.gtk_box_pack_start((*C.GtkBox)(unsafe.Pointer(content)), label, C.TRUE, C.TRUE, 0)
+	for i, a := range actions {
+		// not gtk_info_bar_add_button(); that would treat underscores as mnemonics, which a Button doesn't either
+		button := gtk_button_new()
+		gtk_button_set_label(button, a)
+		C.gtk_info_bar_add_action_widget(togtkinfobar(bar), button, C.gint(i))
+	}
+	C.gtk_info_bar_add_button(togtkinfobar(bar), bannerDismissStock, C.GTK_RESPONSE_CLOSE)
+	g_signal_connect(bar, "response", banner_response_callback, s)
+
+	box := C.gtk_event_box_new()
+	gtk_container_add(box, bar)
+	gtkAddWidgetToLayout(s.container, box)
+	gtkMoveWidgetInLayout(s.container, box, 0, 0)
+	C.gtk_widget_show_all(box)
+	// if the window was already realized, the event box's GdkWindow is new and so on top of the others already; if not, realizing it now (which realizes the window too) puts it after all the controls, which also puts it on top
+	C.gtk_widget_realize(box)
+	C.gdk_window_raise(C.gtk_widget_get_window(box))
+	return box
+}
+
+// makes the banner as wide as the window; called when the window is resized
+func (s *sysData) fitBanner() {
+	if s.banner != nil {
+		width, _ := gtk_window_get_size(s.widget)
+		gtk_widget_set_size_request(s.banner, width, -1)
+	}
+}
+
+func (s *sysData) showBanner(text string, kind BannerKind, actions []string, result chan int) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.removeBanner(-1)
+		s.banner = s.newBanner(text, kind, actions)
+		s.fitBanner()
+		s.bannerResult = result
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) hideBanner() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.removeBanner(-1)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// runs on the UI thread; which is for sysData.bannerDone()
+func (s *sysData) removeBanner(which int) {
+	if s.banner != nil {
+		C.gtk_widget_destroy(s.banner)
+		s.banner = nil
+	}
+	s.bannerDone(which)
+}
@@ -0,0 +1,359 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+/*
+Windows has no banner control, so a banner is a child window of our own class that draws its background and text itself and holds the buttons.
+It goes on top of the Window's other controls; they all have WS_CLIPSIBLINGS (see controlstyle) so they don't draw over it.
+The buttons are children of the banner, not of the Window, so their WM_COMMANDs come to bannerWndProc() and don't need a sysData.
+*/
+
+var (
+	bannerWndClass = toUTF16("gouibanner")
+)
+
+type banner struct {
+	hwnd         _HWND
+	owner        *sysData // the Window
+	text         []uint16
+	brush        _HBRUSH
+	textColor    uintptr // COLORREF; black, like a new DC, unless in high contrast
+	buttons      []_HWND // buttons[0] dismisses the banner; buttons[i] is action i - 1, which is also its control ID minus 1
+	widths       []int
+	height       int
+	buttonHeight int
+	margin       int
+	padding      int
+	textRight    int // where the buttons start; see banner.layout()
+}
+
+// COLORREFs are 0x00BBGGRR
+var bannerColors = map[BannerKind]uintptr{
+	BannerInfo:    0xF7EDD9,
+	BannerWarning: 0xE3F8FC,
+	BannerError:   0xDEDEF2,
+}
+
+const (
+	bannerButtonHeight = 14 // dialog units; the same as a button in a dialog
+	bannerButtonWidth  = 50 // minimum, in dialog units
+)
+
+var (
+	_animateWindow    = user32.NewProc("AnimateWindow")
+	_beginPaint       = user32.NewProc("BeginPaint")
+	_createSolidBrush = gdi32.NewProc("CreateSolidBrush")
+	_deleteObject     = gdi32.NewProc("DeleteObject")
+	_drawText         = user32.NewProc("DrawTextW")
+	_endPaint         = user32.NewProc("EndPaint")
+	_fillRect         = user32.NewProc("FillRect")
+	_setBkMode        = gdi32.NewProc("SetBkMode")
+)
+
+// runs on the UI thread
+func newBanner(owner *sysData, text string, kind BannerKind, actions []string) *banner {
+	b := &banner{
+		owner: owner,
+		text:  syscall.StringToUTF16(text),
+	}
+	background := bannerColors[kind]
+	if on, _ := currentHighContrast(); on { // the colors of tooltips; see highcontrast.go
+		background = sysColor(_COLOR_INFOBK)
+		b.textColor = sysColor(_COLOR_INFOTEXT)
+	}
+	r1, _, err := _createSolidBrush.Call(background)
+	if r1 == 0 { // failure
+		panic(newError(nil, "creating banner background brush", "CreateSolidBrush()", err))
+	}
+	b.brush = _HBRUSH(r1)
+
+	// sizes are in dialog units like everything else
+	baseX, baseY := dialogBaseUnits(owner.hwnd)
+	b.margin = muldiv(marginDialogUnits, baseX, 4)
+	b.padding = muldiv(paddingDialogUnits, baseX, 4)
+	b.buttonHeight = muldiv(bannerButtonHeight, baseY, 8)
+	b.height = b.buttonHeight + 2*muldiv(paddingDialogUnits, baseY, 8)
+
+	r1, _, err = _createWindowEx.Call(
+		uintptr(_WS_EX_CONTROLPARENT), // so tabbing goes into the buttons
+		utf16ToArg(bannerWndClass),
+		blankString,
+		uintptr(_WS_CHILD|_WS_CLIPCHILDREN|_WS_CLIPSIBLINGS), // not visible yet; see below
+		uintptr(0),
+		uintptr(0),
+		uintptr(0),
+		uintptr(0),
+		uintptr(owner.hwnd),
+		uintptr(0),
+		uintptr(hInstance),
+		uintptr(unsafe.Pointer(b)))
+	if r1 == 0 { // failure
+		_deleteObject.Call(uintptr(b.brush))
+		panic(newError(nil, "creating banner window", "CreateWindowEx()", err))
+	}
+	// b.hwnd was set in bannerWndProc()
+
+	buttonClass := toUTF16("BUTTON")
+	for i, t := range append([]string{bannerDismissText}, actions...) {
+		width := owner.textWidth(t) + 2*b.margin
+		if i == 0 {
+			width = b.buttonHeight // square
+		} else if min := muldiv(bannerButtonWidth, baseX, 4); width < min {
+			width = min
+		}
+		tt := toUTF16(t)
+		r1, _, err := _createWindowEx.Call(
+			uintptr(0),
+			utf16ToArg(buttonClass),
+			utf16ToArg(tt),
+			uintptr(_BS_PUSHBUTTON|controlstyle),
+			uintptr(0),
+			uintptr(0),
+			uintptr(width),
+			uintptr(b.buttonHeight),
+			uintptr(b.hwnd),
+			uintptr(i),
+			uintptr(hInstance),
+			uintptr(0))
+		if r1 == 0 { // failure
+			b.destroy()
+			panic(newError(nil, fmt.Sprintf("creating banner button %q", t), "CreateWindowEx()", err))
+		}
+		_sendMessage.Call(
+			r1,
+			uintptr(_WM_SETFONT),
+			uintptr(_WPARAM(controlFont)),
+			uintptr(_LPARAM(_TRUE)))
+		b.buttons = append(b.buttons, _HWND(r1))
+		b.widths = append(b.widths, width)
+	}
+
+	// new child windows go to the bottom of the z-order, so bring the banner to the top
+	_setWindowPos.Call(
+		uintptr(b.hwnd),
+		uintptr(_HWND_TOP),
+		uintptr(0),
+		uintptr(0),
+		uintptr(0),
+		uintptr(0),
+		uintptr(_SWP_NOMOVE|_SWP_NOSIZE|_SWP_NOACTIVATE))
+	b.fit()
+	// AnimateWindow() both slides the banner in and shows it; it fails if the Window isn't visible yet, in which case we just show the banner
+	r1 = 0
+	if currentAnimationsEnabled() {
+		r1, _, _ = _animateWindow.Call(
+			uintptr(b.hwnd),
+			uintptr(200), // milliseconds
+			uintptr(_AW_SLIDE|_AW_VER_POSITIVE))
+	}
+	if r1 == 0 {
+		_showWindow.Call(
+			uintptr(b.hwnd),
+			uintptr(_SW_SHOW))
+	}
+	return b
+}
+
+// makes the banner as wide as the Window; called when the Window is resized
+func (b *banner) fit() {
+	var r _RECT
+
+	r1, _, err := _getClientRect.Call(
+		uintptr(b.owner.hwnd),
+		uintptr(unsafe.Pointer(&r)))
+	if r1 == 0 { // failure
+		panic(newError(nil, "getting window client rect to fit banner", "GetClientRect()", err))
+	}
+	r1, _, err = _moveWindow.Call(
+		uintptr(b.hwnd),
+		uintptr(0),
+		uintptr(b.owner.toolbarHeight()), // below the toolbar, if any, which is still usable while the banner is up
+		uintptr(r.right),
+		uintptr(b.height),
+		uintptr(_TRUE))
+	if r1 == 0 { // failure; not fatal, the banner just stays the width it was
+		reportError(newError(nil, "resizing banner", "MoveWindow()", err))
+	}
+	// the banner's WM_SIZE calls banner.layout()
+}
+
+// the dismiss button goes at the far right, with the actions to its left, in order; the text gets what's left
+func (b *banner) layout() {
+	var r _RECT
+
+	if b.buttons == nil { // WM_SIZE during CreateWindowEx(); the buttons haven't been made yet
+		return
+	}
+	r1, _, err := _getClientRect.Call(
+		uintptr(b.hwnd),
+		uintptr(unsafe.Pointer(&r)))
+	if r1 == 0 { // failure
+		panic(newError(nil, "getting banner client rect for layout", "GetClientRect()", err))
+	}
+	x := int(r.right) - b.padding
+	y := (b.height - b.buttonHeight) / 2
+	place := func(i int) {
+		x -= b.widths[i]
+		r1, _, err := _moveWindow.Call(
+			uintptr(b.buttons[i]),
+			uintptr(x),
+			uintptr(y),
+			uintptr(b.widths[i]),
+			uintptr(b.buttonHeight),
+			uintptr(_TRUE))
+		if r1 == 0 { // failure; not fatal
+			reportError(newError(nil, "moving banner button", "MoveWindow()", err))
+		}
+		x -= b.padding
+	}
+	place(0)
+	for i := len(b.buttons) - 1; i > 0; i-- {
+		place(i)
+	}
+	b.textRight = x
+}
+
+func (b *banner) paint(dc _HANDLE) {
+	var r _RECT
+
+	// if any of these fail there's nothing we can do about it; the banner just doesn't look right until the next WM_PAINT
+	_getClientRect.Call(
+		uintptr(b.hwnd),
+		uintptr(unsafe.Pointer(&r)))
+	_fillRect.Call(
+		uintptr(dc),
+		uintptr(unsafe.Pointer(&r)),
+		uintptr(b.brush))
+	_selectObject.Call(
+		uintptr(dc),
+		uintptr(controlFont))
+	_setTextColor.Call(
+		uintptr(dc),
+		b.textColor)
+	_setBkMode.Call(
+		uintptr(dc),
+		uintptr(_TRANSPARENT))
+	r.left += int32(b.margin)
+	r.right = int32(b.textRight)
+	_drawText.Call(
+		uintptr(dc),
+		uintptr(unsafe.Pointer(&b.text[0])),
+		negConst(-1), // NUL-terminated
+		uintptr(unsafe.Pointer(&r)),
+		uintptr(_DT_SINGLELINE|_DT_VCENTER|_DT_END_ELLIPSIS|_DT_NOPREFIX))
+}
+
+// runs on the UI thread
+func (b *banner) destroy() {
+	// this destroys the buttons too
+	r1, _, err := _destroyWindow.Call(uintptr(b.hwnd))
+	if r1 == 0 { // failure
+		panic(newError(nil, "destroying banner", "DestroyWindow()", err))
+	}
+	_deleteObject.Call(uintptr(b.brush))
+}
+
+func bannerWndProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM) _LRESULT {
+	defer recoverUIPanic()
+	b := (*banner)(unsafe.Pointer(getWindowLongPtr(hwnd, negConst(_GWLP_USERDATA))))
+	if b == nil { // not yet saved
+		// like storeSysData(), but for a banner
+		if uMsg == _WM_NCCREATE {
+			baddr := *((*uintptr)(unsafe.Pointer(lParam)))
+			setWindowLongPtr(hwnd, negConst(_GWLP_USERDATA), baddr)
+			(*banner)(unsafe.Pointer(baddr)).hwnd = hwnd
+		}
+		return defWindowProc(hwnd, uMsg, wParam, lParam)
+	}
+	switch uMsg {
+	case _WM_COMMAND:
+		if wParam.HIWORD() == _BN_CLICKED {
+			b.owner.removeBanner(int(wParam.LOWORD()) - 1)
+		}
+		return 0
+	case _WM_SIZE:
+		b.layout()
+		return 0
+	case _WM_ERASEBKGND:
+		return 1 // banner.paint() draws the background
+	case _WM_PAINT:
+		var ps _PAINTSTRUCT
+
+		r1, _, err := _beginPaint.Call(
+			uintptr(hwnd),
+			uintptr(unsafe.Pointer(&ps)))
+		if r1 == 0 { // failure
+			panic(newError(nil, "beginning banner paint", "BeginPaint()", err))
+		}
+		b.paint(_HANDLE(r1))
+		_endPaint.Call(
+			uintptr(hwnd),
+			uintptr(unsafe.Pointer(&ps)))
+		return 0
+	case _WM_PRINTCLIENT:
+		// AnimateWindow() uses this to draw the banner as it slides in; themed buttons use it to draw the banner behind their rounded corners
+		b.paint(_HANDLE(wParam))
+		return 0
+	case _WM_CTLCOLORBTN:
+		// and unthemed buttons use this
+		return _LRESULT(b.brush)
+	default:
+		return defWindowProc(hwnd, uMsg, wParam, lParam)
+	}
+	panic(fmt.Sprintf("bannerWndProc message %d did not return: internal bug in ui library", uMsg))
+}
+
+func registerBannerWndClass() (err error) {
+	wc := &_WNDCLASS{
+		style:         _CS_HREDRAW | _CS_VREDRAW,
+		lpszClassName: utf16ToArg(bannerWndClass),
+		lpfnWndProc:   syscall.NewCallback(bannerWndProc),
+		hInstance:     hInstance,
+		hIcon:         icon,
+		hCursor:       cursor,
+		hbrBackground: _HBRUSH(_NULL), // no brush; we handle WM_ERASEBKGND
+	}
+	r1, _, err := _registerClass.Call(uintptr(unsafe.Pointer(wc)))
+	if r1 == 0 { // failure
+		return err
+	}
+	return nil
+}
+
+func (s *sysData) showBanner(text string, kind BannerKind, actions []string, result chan int) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.removeBanner(-1)
+		s.banner = newBanner(s, text, kind, actions)
+		s.bannerResult = result
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) hideBanner() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.removeBanner(-1)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// runs on the UI thread; which is for sysData.bannerDone()
+func (s *sysData) removeBanner(which int) {
+	if s.banner != nil {
+		b := s.banner
+		s.banner = nil
+		b.destroy()
+	}
+	s.bannerDone(which)
+}
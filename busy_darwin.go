@@ -0,0 +1,47 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// see busy_darwin.m
+
+func (s *sysData) setBusy(busy bool) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		if busy {
+			s.busy = C.makeBusyOverlay(s.id, s.banner, toBOOL(true))
+		} else {
+			C.removeBusyOverlay(s.busy)
+			s.busy = nil
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// this is the same as being busy, without the spinner; see Window.SetInputBlocked()
+func (s *sysData) setInputBlocked(blocked bool) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.inputBlocked = blocked
+		if blocked {
+			s.blockCover = C.makeBusyOverlay(s.id, s.banner, toBOOL(false))
+		} else {
+			C.removeBusyOverlay(s.blockCover)
+			s.blockCover = nil
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// a busy window covers the overlay of a blocked one too, as on the other systems; this runs on the UI thread
+func (s *sysData) raiseControls(controls []*sysData) {
+	for _, c := range controls {
+		C.raiseControl(s.id, c.id, s.busy)
+	}
+}
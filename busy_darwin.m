@@ -0,0 +1,113 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSWindow.h>
+#import <AppKit/NSView.h>
+#import <AppKit/NSColor.h>
+#import <AppKit/NSGraphics.h>
+#import <AppKit/NSProgressIndicator.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSWindow(x) to(NSWindow, (x))
+#define toNSView(x) to(NSView, (x))
+
+extern NSRect dummyRect;
+
+/*
+Not every control can be disabled on Mac OS X (an Area is just an NSView, for instance), so a busy window is covered with a translucent view instead; it's on top of the controls, so it gets all the clicks, and it takes the keyboard focus so that the controls don't get any keys either.
+It goes below the banner, if there is one, so the banner stays usable; see Window.SetBusy().
+The spinning progress indicator is in the middle of it.
+Window.SetInputBlocked() covers the window the same way, without the spinner; the controls of its overlay are moved above the cover afterward, so they still get clicks.
+*/
+
+@interface busyOverlay : NSView {
+	NSResponder *previousResponder;
+}
+- (void)takeFocus;
+- (void)giveFocusBack;
+@end
+
+@implementation busyOverlay
+
+- (void)drawRect:(NSRect)r
+{
+	[[NSColor colorWithCalibratedWhite:1 alpha:0.5] set];
+	NSRectFillUsingOperation(r, NSCompositeSourceOver);
+}
+
+- (BOOL)acceptsFirstResponder
+{
+	return YES;
+}
+
+// eat keys without passing them on; otherwise tabbing would still move between the controls underneath
+- (void)keyDown:(NSEvent *)e
+{
+}
+
+- (void)keyUp:(NSEvent *)e
+{
+}
+
+// the controls stay in the window for as long as we do, so we don't need to retain the one that had the focus
+- (void)takeFocus
+{
+	previousResponder = [[self window] firstResponder];
+	[[self window] makeFirstResponder:self];
+}
+
+- (void)giveFocusBack
+{
+	if ([[self window] firstResponder] == self)
+		[[self window] makeFirstResponder:previousResponder];
+}
+
+@end
+
+id makeBusyOverlay(id window, id banner, BOOL withSpinner)
+{
+	NSView *cv;
+	busyOverlay *o;
+	NSProgressIndicator *spinner;
+	NSRect r;
+
+	cv = [toNSWindow(window) contentView];
+	o = [[busyOverlay alloc]
+		initWithFrame:[cv bounds]];
+	[o setAutoresizingMask:(NSViewWidthSizable | NSViewHeightSizable)];
+
+	if (withSpinner) {
+		spinner = [[NSProgressIndicator alloc]
+			initWithFrame:dummyRect];
+		[spinner setStyle:NSProgressIndicatorSpinningStyle];
+		[spinner setControlSize:NSRegularControlSize];
+		[spinner sizeToFit];
+		r = [spinner frame];
+		r.origin.x = (NSWidth([o bounds]) - NSWidth(r)) / 2;
+		r.origin.y = (NSHeight([o bounds]) - NSHeight(r)) / 2;
+		[spinner setFrame:r];
+		// flexible margins on all sides keep it in the middle
+		[spinner setAutoresizingMask:(NSViewMinXMargin | NSViewMaxXMargin | NSViewMinYMargin | NSViewMaxYMargin)];
+		[o addSubview:spinner];
+		[spinner release];		// the overlay has it now
+		// without animations, the spinner stands still instead, which it only draws itself doing if asked to
+		if (animationsEnabled())		// see animations_darwin.m
+			[spinner startAnimation:o];
+		else
+			[spinner setDisplayedWhenStopped:YES];
+	}
+
+	if (banner != nil)
+		[cv addSubview:o positioned:NSWindowBelow relativeTo:toNSView(banner)];
+	else
+		[cv addSubview:o positioned:NSWindowAbove relativeTo:nil];
+	[o release];			// the content view has it now
+	[o takeFocus];
+	return o;
+}
+
+void removeBusyOverlay(id overlay)
+{
+	[to(busyOverlay, overlay) giveFocusBack];
+	[to(busyOverlay, overlay) removeFromSuperview];
+}
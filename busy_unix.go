@@ -0,0 +1,98 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+/*
+Insensitive widgets are drawn dimmed and get no mouse or keyboard input, which is everything Window.SetBusy() asks for; so we just make every control in the window insensitive.
+Window.SetInputBlocked() does the same, except to the controls of its overlay.
+We can't do that to the GtkLayout itself, as the GtkSpinner that shows the window is busy goes in there too, and it would be dimmed along with everything else.
+Like a banner, the spinner is in a GtkEventBox so it stays on top of controls that have their own GdkWindows; see banner_unix.go.
+*/
+
+// #include "gtk_unix.h"
+import "C"
+
+const busySpinnerSize = 32 // pixels; GtkSpinner's own size is too small to notice in the middle of a window
+
+func (s *sysData) setBusy(busy bool) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		if !busy {
+			C.gtk_widget_destroy(s.busy)
+			s.busy = nil
+			s.enableControls()
+			ret <- struct{}{}
+			return
+		}
+		spinner := C.gtk_spinner_new()
+		gtk_widget_set_size_request(spinner, busySpinnerSize, busySpinnerSize)
+		C.gtk_spinner_start((*C.GtkSpinner)(unsafe.Pointer(spinner)))
+		box := C.gtk_event_box_new()
+		gtk_container_add(box, spinner)
+		gtkAddWidgetToLayout(s.container, box)
+		C.gtk_widget_show_all(box)
+		// see sysData.newBanner()
+		C.gtk_widget_realize(box)
+		C.gdk_window_raise(C.gtk_widget_get_window(box))
+		s.busy = box
+		s.enableControls()
+		s.fitBusy()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// makes every control in the window sensitive or insensitive for whether it's busy or blocked (see sysData.doSetEnabled()); controls made insensitive by a ControlGroup or a Form stay that way either way
+// this runs on the UI thread
+func (s *sysData) enableControls() {
+	for _, c := range s.controls {
+		if c.made {
+			c.doSetEnabled()
+		}
+	}
+}
+
+// this is the same as being busy, without the spinner; see Window.SetInputBlocked()
+func (s *sysData) setInputBlocked(blocked bool) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.inputBlocked = blocked
+		s.enableControls()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// GtkLayout already draws the controls of an overlay over the rest, as they were added after them; only the ones with GdkWindows of their own have to be raised, as with the spinner; see Window.SetInputBlocked()
+// this runs on the UI thread
+func (s *sysData) raiseControls(controls []*sysData) {
+	for _, c := range controls {
+		widgets := []*C.GtkWidget{c.widget}
+		// the scrolled window around a Listbox or Area has no GdkWindow, but what's inside it does
+		if ct := classTypes[c.ctype]; ct.child != nil {
+			widgets = append(widgets, ct.child(c.widget))
+		}
+		for _, w := range widgets {
+			if fromgbool(C.gtk_widget_get_has_window(w)) {
+				C.gtk_widget_realize(w)
+				C.gdk_window_raise(C.gtk_widget_get_window(w))
+			}
+		}
+	}
+}
+
+// centers the spinner, if any; called when the window is resized
+func (s *sysData) fitBusy() {
+	if s.busy != nil {
+		width, height := gtk_window_get_size(s.widget)
+		gtkMoveWidgetInLayout(s.container, s.busy, (width-busySpinnerSize)/2, (height-busySpinnerSize)/2)
+	}
+}
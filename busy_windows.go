@@ -0,0 +1,151 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+/*
+Disabled controls are drawn dimmed and get no mouse or keyboard input (even if one of them has the keyboard focus), which is everything Window.SetBusy() asks for; so we just disable every control in the Window.
+Window.SetInputBlocked() does the same, except to the controls of its overlay.
+The busy indicator is a marquee progress bar on top of everything else, like a banner (see banner_windows.go); it's not one of the Window's children, so it doesn't get disabled with them.
+*/
+
+type busyIndicator struct {
+	hwnd   _HWND
+	width  int
+	height int
+}
+
+const (
+	busyIndicatorWidth  = 107 // dialog units; the short progress bar width from the Windows UX guidelines
+	busyIndicatorHeight = 8
+)
+
+var (
+	_enableWindow = user32.NewProc("EnableWindow")
+)
+
+func (s *sysData) setBusy(busy bool) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		if !busy {
+			r1, _, err := _destroyWindow.Call(uintptr(s.busy.hwnd))
+			if r1 == 0 { // failure
+				panic(s.newError("destroying busy indicator", "DestroyWindow()", err))
+			}
+			s.busy = nil
+			s.enableChildren()
+			ret <- struct{}{}
+			return
+		}
+		baseX, baseY := dialogBaseUnits(s.hwnd)
+		b := &busyIndicator{
+			width:  muldiv(busyIndicatorWidth, baseX, 4),
+			height: muldiv(busyIndicatorHeight, baseY, 8),
+		}
+		r1, _, err := _createWindowEx.Call(
+			uintptr(0),
+			utf16ToArg(classTypes[c_progressbar].name),
+			blankString,
+			uintptr(_PBS_MARQUEE|_WS_CHILD|_WS_VISIBLE|_WS_CLIPSIBLINGS),
+			uintptr(0),
+			uintptr(0),
+			uintptr(b.width),
+			uintptr(b.height),
+			uintptr(s.hwnd),
+			uintptr(0),
+			uintptr(hInstance),
+			uintptr(0))
+		if r1 == 0 { // failure
+			panic(s.newError("creating busy indicator", "CreateWindowEx()", err))
+		}
+		b.hwnd = _HWND(r1)
+		_sendMessage.Call(
+			uintptr(b.hwnd),
+			uintptr(_PBM_SETMARQUEE),
+			uintptr(_WPARAM(_TRUE)),
+			uintptr(0))
+		// see newBanner()
+		_setWindowPos.Call(
+			uintptr(b.hwnd),
+			uintptr(_HWND_TOP),
+			uintptr(0),
+			uintptr(0),
+			uintptr(0),
+			uintptr(0),
+			uintptr(_SWP_NOMOVE|_SWP_NOSIZE|_SWP_NOACTIVATE))
+		s.busy = b
+		s.enableChildren()
+		s.fitBusy()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// enables or disables every control in the Window for whether it's busy or blocked (see sysData.doSetEnabled()); controls disabled by a ControlGroup or a Form stay disabled either way
+// this runs on the UI thread
+func (s *sysData) enableChildren() {
+	s.childrenLock.Lock()
+	defer s.childrenLock.Unlock()
+
+	for _, c := range s.children {
+		if c.made {
+			c.doSetEnabled()
+		}
+	}
+}
+
+// this is the same as being busy, without the busy indicator; see Window.SetInputBlocked()
+func (s *sysData) setInputBlocked(blocked bool) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.inputBlocked = blocked
+		s.enableChildren()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// puts the controls of an overlay over everything else in the Window, in order, so a GroupBox or Tab in the overlay stays below the controls in it (see placeBelow()); see Window.SetInputBlocked()
+// this runs on the UI thread
+func (s *sysData) raiseControls(controls []*sysData) {
+	for _, c := range controls {
+		_setWindowPos.Call(
+			uintptr(c.hwnd),
+			uintptr(_HWND_TOP),
+			uintptr(0),
+			uintptr(0),
+			uintptr(0),
+			uintptr(0),
+			uintptr(_SWP_NOMOVE|_SWP_NOSIZE|_SWP_NOACTIVATE))
+	}
+}
+
+// centers the busy indicator, if any; called when the Window is resized
+func (s *sysData) fitBusy() {
+	var r _RECT
+
+	if s.busy == nil {
+		return
+	}
+	r1, _, err := _getClientRect.Call(
+		uintptr(s.hwnd),
+		uintptr(unsafe.Pointer(&r)))
+	if r1 == 0 { // failure
+		panic(s.newError("getting window client rect to center busy indicator", "GetClientRect()", err))
+	}
+	r1, _, err = _moveWindow.Call(
+		uintptr(s.busy.hwnd),
+		uintptr((int(r.right)-s.busy.width)/2),
+		uintptr((int(r.bottom)-s.busy.height)/2),
+		uintptr(s.busy.width),
+		uintptr(s.busy.height),
+		uintptr(_TRUE))
+	if r1 == 0 { // failure; not fatal, the busy indicator just stays where it was
+		reportError(s.newError("centering busy indicator", "MoveWindow()", err))
+	}
+}
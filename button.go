@@ -17,6 +17,7 @@ type Button struct {
 	created  bool
 	sysData  *sysData
 	initText string
+	badge    string
 }
 
 // NewButton creates a new button with the specified text.
@@ -51,28 +52,47 @@ func (b *Button) Text() string {
 	return b.initText
 }
 
+// SetBadge sets the badge drawn in the Button's top-right corner, such as a count of unread messages ("3") or a mark for pending changes ("!").
+// An empty string removes the badge; a Button has no badge by default.
+// The badge is drawn over the Button itself, as a white label on a red rounded background, and doesn't change the Button's preferred size, so keep it to a few characters.
+func (b *Button) SetBadge(badge string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.badge = badge // the system doesn't keep it for us, so Badge() always returns this
+	if b.created {
+		b.sysData.setBadge(badge)
+	}
+}
+
+// Badge returns the Button's badge.
+func (b *Button) Badge() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.badge
+}
+
 func (b *Button) make(window *sysData) error {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
 	b.sysData.event = b.Clicked
+	b.sysData.control = b
 	err := b.sysData.make(window)
 	if err != nil {
 		return err
 	}
 	b.sysData.setText(b.initText)
+	if b.badge != "" {
+		b.sysData.setBadge(b.badge)
+	}
 	b.created = true
 	return nil
 }
 
 func (b *Button) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
-	return []*allocation{&allocation{
-		x:       x,
-		y:       y,
-		width:   width,
-		height:  height,
-		this:		b,
-	}}
+	return b.sysData.singleAllocation(x, y, width, height, b)
 }
 
 func (b *Button) preferredSize(d *sysSizeData) (width int, height int) {
@@ -18,6 +18,11 @@ while we're at it the callback for our idle function will be handled here too
 // extern gboolean our_window_delete_event_callback(GtkWidget *, GdkEvent *, gpointer);
 // extern gboolean our_window_configure_event_callback(GtkWidget *, GdkEvent *, gpointer);
 // extern void our_button_clicked_callback(GtkButton *, gpointer);
+// extern void our_listbox_selection_changed_callback(GtkTreeSelection *, gpointer);
+// extern void our_listbox_row_activated_callback(GtkTreeView *, GtkTreePath *, GtkTreeViewColumn *, gpointer);
+// extern void our_listbox_scrolled_callback(GtkAdjustment *, gpointer);
+// extern gboolean our_listbox_key_press_event_callback(GtkWidget *, GdkEvent *, gpointer);
+// extern void our_setting_changed_callback(GtkWidget *, gpointer);
 // extern gboolean our_idle_callback(gpointer);
 // /* because cgo is flaky with macros; static inline because we have //exports */
 // static inline void gSignalConnect(GtkWidget *widget, char *signal, GCallback callback, void *data) { g_signal_connect(widget, signal, callback, data); }
@@ -25,6 +30,7 @@ import "C"
 
 //export our_window_delete_event_callback
 func our_window_delete_event_callback(widget *C.GtkWidget, event *C.GdkEvent, what C.gpointer) C.gboolean {
+	defer recoverUIPanic()
 	// called when the user tries to close the window
 	s := (*sysData)(unsafe.Pointer(what))
 	s.signal()
@@ -35,13 +41,17 @@ var window_delete_event_callback = C.GCallback(C.our_window_delete_event_callbac
 
 //export our_window_configure_event_callback
 func our_window_configure_event_callback(widget *C.GtkWidget, event *C.GdkEvent, what C.gpointer) C.gboolean {
+	defer recoverUIPanic()
 	// called when the window is resized
 	s := (*sysData)(unsafe.Pointer(what))
 	if s.container != nil && s.allocate != nil { // wait for init
 		width, height := gtk_window_get_size(s.widget)
+		height -= gtkWindowBarsHeight(s.widget, s.container)
 		// top-left is (0,0) here
 		s.resizeWindow(width, height)
 	}
+	s.fitBanner()
+	s.fitBusy()
 	// no need to manually redraw everything: since we use gtk_widget_set_size_request(), that queues both resize and redraw for us (thanks Company in irc.gimp.net/#gtk+)
 	return C.FALSE // continue the event chain
 }
@@ -50,6 +60,7 @@ var window_configure_event_callback = C.GCallback(C.our_window_configure_event_c
 
 //export our_button_clicked_callback
 func our_button_clicked_callback(button *C.GtkButton, what C.gpointer) {
+	defer recoverUIPanic()
 	// called when the user clicks a button
 	s := (*sysData)(unsafe.Pointer(what))
 	s.signal()
@@ -57,6 +68,75 @@ func our_button_clicked_callback(button *C.GtkButton, what C.gpointer) {
 
 var button_clicked_callback = C.GCallback(C.our_button_clicked_callback)
 
+//export our_listbox_selection_changed_callback
+func our_listbox_selection_changed_callback(sel *C.GtkTreeSelection, what C.gpointer) {
+	defer recoverUIPanic()
+	// called when the selection of a Listbox changes, including when we change it ourselves
+	s := (*sysData)(unsafe.Pointer(what))
+	s.signal()
+}
+
+var listbox_selection_changed_callback = C.GCallback(C.our_listbox_selection_changed_callback)
+
+//export our_listbox_row_activated_callback
+func our_listbox_row_activated_callback(tv *C.GtkTreeView, path *C.GtkTreePath, column *C.GtkTreeViewColumn, what C.gpointer) {
+	defer recoverUIPanic()
+	// called when the user double-clicks a row or presses Enter on it
+	s := (*sysData)(unsafe.Pointer(what))
+	s.signalActivated()
+}
+
+var listbox_row_activated_callback = C.GCallback(C.our_listbox_row_activated_callback)
+
+//export our_listbox_scrolled_callback
+func our_listbox_scrolled_callback(adj *C.GtkAdjustment, what C.gpointer) {
+	defer recoverUIPanic()
+	// called when a Listbox is scrolled, for Listbox.NearEnd; also when we scroll it ourselves or it changes size, but signalNearEnd() sorts that out
+	s := (*sysData)(unsafe.Pointer(what))
+	s.signalNearEnd(gListboxNearEnd(s.widget, adj))
+}
+
+var listbox_scrolled_callback = C.GCallback(C.our_listbox_scrolled_callback)
+
+//export our_listbox_key_press_event_callback
+func our_listbox_key_press_event_callback(widget *C.GtkWidget, event *C.GdkEvent, what C.gpointer) C.gboolean {
+	defer recoverUIPanic()
+	// called when a key is pressed in a Listbox; GtkTreeView doesn't copy on Ctrl+C by itself
+	s := (*sysData)(unsafe.Pointer(what))
+	e := (*C.GdkEventKey)(unsafe.Pointer(event))
+	mods := e.state & C.guint(C.gtk_accelerator_get_default_mod_mask())
+	if C.gdk_keyval_to_lower(e.keyval) == C.GDK_KEY_c && mods == C.GDK_CONTROL_MASK {
+		gListboxCopy(s.widget)
+		return C.TRUE // handled
+	}
+	return C.FALSE // continue the event chain
+}
+
+var listbox_key_press_event_callback = C.GCallback(C.our_listbox_key_press_event_callback)
+
+//export our_setting_changed_callback
+func our_setting_changed_callback(widget *C.GtkWidget, what C.gpointer) {
+	defer recoverUIPanic()
+	// called when the value of a Checkbox or Combobox bound with BindSetting() changes, including when we change it ourselves
+	s := (*sysData)(unsafe.Pointer(what))
+	s.settingChanged()
+}
+
+var setting_changed_callback = C.GCallback(C.our_setting_changed_callback)
+
+// connects the above for a control bound with BindSetting(); LineEdits (see sysData.make()) and Listboxes already tell us when they change
+func (s *sysData) settingConnect() {
+	switch s.ctype {
+	case c_checkbox:
+		g_signal_connect(s.widget, "toggled", setting_changed_callback, s)
+	case c_combobox:
+		g_signal_connect(s.widget, "changed", setting_changed_callback, s)
+		if s.alternate { // GtkComboBox only sends "changed" when the item selected changes, not for each key typed into its GtkEntry
+			g_signal_connect(gtkComboBoxEntry(s.widget), "changed", setting_changed_callback, s)
+		}
+	}
+}
+
 // this is the type of the signals fields in classData; here to avoid needing to import C
 type callbackMap map[string]C.GCallback
 
@@ -73,24 +153,14 @@ func g_signal_connect_pointer(obj *C.GtkWidget, sig string, callback C.GCallback
 	C.gSignalConnect(obj, csig, callback, p)
 }
 
-// there are two issues we solve here:
-// 1) we need to make sure the uitask request gets garbage collected when we're done so as to not waste memory, but only when we're done so as to not have craziness happen
-// 2) we need to make sure one idle function runs and finishes running before we start the next; otherwise we could wind up with weird things like the ret channel being closed early
-// so our_idle_callback() calls the uitask function in what and sends a message back to the dispatcher over done that it finished running; the dispatcher is still holding onto the uitask function so it won't be collected
-type gtkIdleOp struct {
-	what func()
-	done chan struct{}
-}
-
+// gdk_threads_add_idle() is safe to call from any thread, and it runs the function on the thread running gtk_main(); this is how we get uitask functions over to the UI thread
+// we only add one idle function each time uitask wakes us up, and it runs everything that's been queued; see uitask.go
 //export our_idle_callback
 func our_idle_callback(what C.gpointer) C.gboolean {
-	idleop := (*gtkIdleOp)(unsafe.Pointer(what))
-	idleop.what()
-	idleop.done <- struct{}{}
+	runuitasks()
 	return C.FALSE // remove this idle function; we're finished
 }
 
-func gdk_threads_add_idle(idleop *gtkIdleOp) {
-	C.gdk_threads_add_idle(C.GCallback(C.our_idle_callback),
-		C.gpointer(unsafe.Pointer(idleop)))
+func gdk_threads_add_idle() {
+	C.gdk_threads_add_idle(C.GCallback(C.our_idle_callback), nil)
 }
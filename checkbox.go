@@ -47,6 +47,7 @@ func (c *Checkbox) Text() string {
 }
 
 // SetChecked() changes the checked state of the Checkbox.
+// If the Window containing the Checkbox has not been created yet, SetChecked sets the state the Checkbox will start out with.
 func (c *Checkbox) SetChecked(checked bool) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -73,24 +74,22 @@ func (c *Checkbox) make(window *sysData) error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	c.sysData.control = c
 	err := c.sysData.make(window)
 	if err != nil {
 		return err
 	}
 	c.sysData.setText(c.initText)
 	c.sysData.setChecked(c.initCheck)
+	if c.sysData.setting != nil {
+		window.addSetting(c.sysData.setting)
+	}
 	c.created = true
 	return nil
 }
 
 func (c *Checkbox) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
-	return []*allocation{&allocation{
-		x:       x,
-		y:       y,
-		width:   width,
-		height:  height,
-		this:		c,
-	}}
+	return c.sysData.singleAllocation(x, y, width, height, c)
 }
 
 func (c *Checkbox) preferredSize(d *sysSizeData) (width int, height int) {
@@ -0,0 +1,151 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+)
+
+// ClipboardChanged is signaled whenever the contents of the system clipboard change, whether your program or another one changed them.
+// Use it with ClipboardHasText to enable or disable a Paste button, or with ClipboardText to keep a history of what the user copied, without checking the clipboard over and over yourself.
+// Mac OS X doesn't tell programs when the clipboard changes, so there package ui checks it twice a second, and ClipboardChanged can be signaled up to half a second late.
+// On Windows, ClipboardChanged needs Windows Vista or newer; it is never signaled on Windows XP.
+var ClipboardChanged chan struct{}
+
+func init() {
+	// like AppQuit
+	ClipboardChanged = newEvent()
+}
+
+// ClipboardHasText returns whether the clipboard has text on it, which is what a Paste button would paste.
+func ClipboardHasText() bool {
+	ret := make(chan bool)
+	defer close(ret)
+	uitask(func() {
+		ret <- clipboardHasText()
+	})
+	return <-ret
+}
+
+// ClipboardText returns the text on the clipboard, or an empty string if there isn't any.
+// On Windows, the "\r\n" line endings of the clipboard are turned into "\n", as everywhere else in package ui.
+func ClipboardText() string {
+	ret := make(chan string)
+	defer close(ret)
+	uitask(func() {
+		ret <- clipboardText()
+	})
+	return <-ret
+}
+
+// ClipboardFormat says what kinds of data are on the clipboard; see ClipboardFormats.
+type ClipboardFormat uint
+
+const (
+	ClipboardFormatText  ClipboardFormat = 1 << iota // plain text; see ClipboardText
+	ClipboardFormatHTML                              // an HTML fragment, as copied from a web browser; see ClipboardHTML
+	ClipboardFormatImage                             // a picture; see ClipboardImage
+	ClipboardFormatFiles                             // a list of files, as copied in the system's file manager; see ClipboardFiles
+)
+
+// ClipboardData is what SetClipboard puts on the clipboard.
+// Fill in every field you have something for: each one goes on the clipboard in its own format (often in several, for the programs that only know older ones), and the program the user pastes into picks the one it likes best.
+// For instance, a chart copied with both Image and Text (its numbers, separated by tabs) pastes as a picture into a word processor and as cells into a spreadsheet.
+type ClipboardData struct {
+	Text  string      // plain text, with "\n" line endings
+	HTML  string      // an HTML fragment, such as "<b>Total:</b> 42"; not a whole document
+	Image image.Image // kept with its alpha channel for the programs that can take it, and on white for the ones that can't; left off if empty
+	Files []string    // absolute paths
+}
+
+// SetClipboard replaces the contents of the clipboard with data; the fields of data that are empty are left off.
+// If all of them are empty, the clipboard is just emptied.
+func SetClipboard(data ClipboardData) {
+	// converting the image can take a while, so don't do it on the UI thread
+	c := newClipboardContents(data)
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		c.set()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// ClipboardFormats returns which kinds of data are on the clipboard.
+// Other programs can put several on it at once; see ClipboardData.
+func ClipboardFormats() ClipboardFormat {
+	ret := make(chan ClipboardFormat)
+	defer close(ret)
+	uitask(func() {
+		ret <- clipboardFormats()
+	})
+	return <-ret
+}
+
+// ClipboardHTML returns the HTML fragment on the clipboard, or an empty string if there isn't one.
+func ClipboardHTML() string {
+	ret := make(chan string)
+	defer close(ret)
+	uitask(func() {
+		ret <- clipboardHTML()
+	})
+	return <-ret
+}
+
+// ClipboardImage returns the picture on the clipboard, or nil if there isn't one (or if it's in a form package ui can't read).
+func ClipboardImage() image.Image {
+	ret := make(chan image.Image)
+	defer close(ret)
+	uitask(func() {
+		ret <- clipboardImage()
+	})
+	return <-ret
+}
+
+// ClipboardFiles returns the absolute paths of the files on the clipboard, or nil if there aren't any.
+func ClipboardFiles() []string {
+	ret := make(chan []string)
+	defer close(ret)
+	uitask(func() {
+		ret <- clipboardFiles()
+	})
+	return <-ret
+}
+
+// the platform code works with an *image.NRGBA starting at (0,0), since that's closest to what each system wants
+// returns nil for an empty image, which is left off the clipboard
+func toClipboardImage(img image.Image) *image.NRGBA {
+	if img.Bounds().Empty() {
+		return nil
+	}
+	if i, ok := img.(*image.NRGBA); ok && i.Rect.Min == image.ZP {
+		return i
+	}
+	b := img.Bounds()
+	i := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(i, i.Rect, img, b.Min, draw.Src)
+	return i
+}
+
+// for the systems that put images on the clipboard as PNG
+func toClipboardPNG(img *image.NRGBA) []byte {
+	var b bytes.Buffer
+
+	err := png.Encode(&b, img)
+	if err != nil { // can't happen with a valid NRGBA, but just in case, leave it off the clipboard
+		return nil
+	}
+	return b.Bytes()
+}
+
+func fromClipboardPNG(data []byte) image.Image {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	return img
+}
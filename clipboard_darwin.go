@@ -0,0 +1,123 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image"
+	"unsafe"
+)
+
+// #include "objc_darwin.h"
+import "C"
+
+// see clipboard_darwin.m
+
+// runs on the UI thread
+func setClipboardText(text string) {
+	C.setClipboardText(toNSString(text))
+}
+
+func (s *sysData) copyText(text string) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		setClipboardText(text)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+//export clipboard_changed
+func clipboard_changed() {
+	defer recoverUIPanic()
+	sendEvent(ClipboardChanged)
+}
+
+// runs on the UI thread
+func clipboardHasText() bool {
+	return C.clipboardHasText() != C.NO
+}
+
+// runs on the UI thread
+func clipboardText() string {
+	text := C.clipboardText()
+	if text == nil {
+		return ""
+	}
+	return fromNSString(text)
+}
+
+// for SetClipboard()
+type clipboardContents struct {
+	data ClipboardData
+	png  []byte
+}
+
+func newClipboardContents(data ClipboardData) *clipboardContents {
+	c := &clipboardContents{
+		data: data,
+	}
+	if data.Image != nil {
+		if img := toClipboardImage(data.Image); img != nil {
+			c.png = toClipboardPNG(img)
+		}
+	}
+	return c
+}
+
+// runs on the UI thread
+func (c *clipboardContents) set() {
+	var text, html C.id
+	var png unsafe.Pointer
+
+	if c.data.Text != "" {
+		text = toNSString(c.data.Text)
+	}
+	if c.data.HTML != "" {
+		html = toNSString(c.data.HTML)
+	}
+	if len(c.png) != 0 {
+		png = unsafe.Pointer(&c.png[0])
+	}
+	files := C.clipboardFilesNew()
+	for _, f := range c.data.Files {
+		C.clipboardFilesAdd(files, toNSString(f))
+	}
+	C.clipboardSet(text, html, png, C.intptr_t(len(c.png)), files)
+}
+
+// runs on the UI thread; the bits are in the same order
+func clipboardFormats() ClipboardFormat {
+	return ClipboardFormat(C.clipboardFormats())
+}
+
+// runs on the UI thread
+func clipboardHTML() string {
+	html := C.clipboardHTML()
+	if html == nil {
+		return ""
+	}
+	return fromNSString(html)
+}
+
+// runs on the UI thread
+func clipboardImage() image.Image {
+	png := C.clipboardPNG()
+	if png == nil {
+		return nil
+	}
+	return fromClipboardPNG(C.GoBytes(C.clipboardDataBytes(png), C.int(C.clipboardDataLen(png))))
+}
+
+// runs on the UI thread
+func clipboardFiles() (files []string) {
+	urls := C.clipboardFiles()
+	if urls == nil {
+		return nil
+	}
+	n := C.clipboardFilesLen(urls)
+	for i := C.intptr_t(0); i < n; i++ {
+		files = append(files, fromNSString(C.clipboardFileAt(urls, i)))
+	}
+	return files
+}
@@ -0,0 +1,214 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#include "_cgo_export.h"
+#import <Foundation/NSArray.h>
+#import <Foundation/NSDictionary.h>
+#import <Foundation/NSData.h>
+#import <Foundation/NSURL.h>
+#import <Foundation/NSValue.h>
+#import <Foundation/NSTimer.h>
+#import <Foundation/NSRunLoop.h>
+#import <AppKit/NSPasteboard.h>
+#import <AppKit/NSPasteboardItem.h>
+#import <AppKit/NSImage.h>
+#import <AppKit/NSBitmapImageRep.h>
+
+/*
+NSPasteboard has no notification for when its contents change; all we get is a change count that goes up each time.
+So for ClipboardChanged a timer checks it twice a second; the timer is added for NSRunLoopCommonModes so it keeps running while a modal dialog or a menu is up.
+
+For SetClipboard(), the text, HTML, and image all go in one NSPasteboardItem, each as its own type; the files are NSURLs, one item each, which is how the Finder copies them.
+Images go between Go and here as PNG; we also put TIFF on the pasteboard, since that's what older programs look for.
+*/
+
+#define clipboardInterval 0.5
+
+@interface goClipboardWatcher : NSObject {
+	NSInteger lastChangeCount;
+}
+- (void)check:(NSTimer *)t;
+@end
+
+@implementation goClipboardWatcher
+
+- (void)check:(NSTimer *)t
+{
+	NSInteger count;
+
+	count = [[NSPasteboard generalPasteboard] changeCount];
+	if (count == lastChangeCount)
+		return;
+	lastChangeCount = count;
+	clipboard_changed();
+}
+
+@end
+
+// called once, by initCocoa(); the watcher and its timer last for the life of the program
+void clipboardWatch(void)
+{
+	goClipboardWatcher *w;
+	NSTimer *timer;
+
+	w = [goClipboardWatcher new];
+	w->lastChangeCount = [[NSPasteboard generalPasteboard] changeCount];
+	timer = [NSTimer timerWithTimeInterval:clipboardInterval
+		target:w
+		selector:@selector(check:)
+		userInfo:nil
+		repeats:YES];
+	[[NSRunLoop mainRunLoop] addTimer:timer forMode:NSRunLoopCommonModes];
+	[w release];		// the timer has it now
+}
+
+void setClipboardText(id text)
+{
+	NSPasteboard *pb;
+
+	pb = [NSPasteboard generalPasteboard];
+	[pb clearContents];
+	[pb writeObjects:[NSArray arrayWithObject:text]];
+}
+
+BOOL clipboardHasText(void)
+{
+	return [[NSPasteboard generalPasteboard]
+		availableTypeFromArray:[NSArray arrayWithObject:NSPasteboardTypeString]] != nil;
+}
+
+// returns nil if there's no text
+id clipboardText(void)
+{
+	return [[NSPasteboard generalPasteboard] stringForType:NSPasteboardTypeString];
+}
+
+// for SetClipboard(); any of text, html, and png can be nil, and files can be empty
+void clipboardSet(id text, id html, void *png, intptr_t pngLen, id files)
+{
+	NSPasteboard *pb;
+	NSMutableArray *objects;
+	NSPasteboardItem *item;
+	BOOL used = NO;
+	NSData *data;
+	NSBitmapImageRep *rep;
+	NSString *f;
+
+	pb = [NSPasteboard generalPasteboard];
+	[pb clearContents];
+	objects = [NSMutableArray array];
+	item = [[NSPasteboardItem new] autorelease];
+	if (text != nil) {
+		[item setString:text forType:NSPasteboardTypeString];
+		used = YES;
+	}
+	if (html != nil) {
+		[item setString:html forType:NSPasteboardTypeHTML];
+		used = YES;
+	}
+	if (png != NULL) {
+		data = [NSData dataWithBytes:png length:(NSUInteger) pngLen];
+		[item setData:data forType:NSPasteboardTypePNG];
+		rep = [NSBitmapImageRep imageRepWithData:data];
+		if (rep != nil)
+			[item setData:[rep TIFFRepresentation] forType:NSPasteboardTypeTIFF];
+		used = YES;
+	}
+	if (used)
+		[objects addObject:item];
+	for (f in (NSArray *) files)
+		[objects addObject:[NSURL fileURLWithPath:f]];
+	if ([objects count] != 0)
+		[pb writeObjects:objects];
+}
+
+id clipboardFilesNew(void)
+{
+	return [NSMutableArray array];
+}
+
+void clipboardFilesAdd(id files, id f)
+{
+	[((NSMutableArray *) files) addObject:f];
+}
+
+static NSDictionary *fileURLsOnly(void)
+{
+	return [NSDictionary dictionaryWithObject:[NSNumber numberWithBool:YES]
+		forKey:NSPasteboardURLReadingFileURLsOnlyKey];
+}
+
+// the bits are in the same order as the ClipboardFormat constants
+intptr_t clipboardFormats(void)
+{
+	NSPasteboard *pb;
+	intptr_t f = 0;
+
+	pb = [NSPasteboard generalPasteboard];
+	if ([pb availableTypeFromArray:[NSArray arrayWithObject:NSPasteboardTypeString]] != nil)
+		f |= 1;
+	if ([pb availableTypeFromArray:[NSArray arrayWithObject:NSPasteboardTypeHTML]] != nil)
+		f |= 2;
+	if ([pb canReadObjectForClasses:[NSArray arrayWithObject:[NSImage class]] options:nil])
+		f |= 4;
+	if ([pb canReadObjectForClasses:[NSArray arrayWithObject:[NSURL class]] options:fileURLsOnly()])
+		f |= 8;
+	return f;
+}
+
+// returns nil if there's no HTML
+id clipboardHTML(void)
+{
+	return [[NSPasteboard generalPasteboard] stringForType:NSPasteboardTypeHTML];
+}
+
+// returns the image as PNG data, or nil if there's no image
+// PNG from the pasteboard is taken as is, so its alpha channel is kept; anything else NSImage can read (TIFF, PDF, and so on) is converted
+id clipboardPNG(void)
+{
+	NSPasteboard *pb;
+	NSData *png;
+	NSImage *img;
+	NSBitmapImageRep *rep;
+
+	pb = [NSPasteboard generalPasteboard];
+	png = [pb dataForType:NSPasteboardTypePNG];
+	if (png != nil)
+		return png;
+	img = [[NSImage alloc] initWithPasteboard:pb];
+	if (img == nil)
+		return nil;
+	rep = [NSBitmapImageRep imageRepWithData:[img TIFFRepresentation]];
+	[img release];
+	if (rep == nil)
+		return nil;
+	return [rep representationUsingType:NSPNGFileType properties:[NSDictionary dictionary]];
+}
+
+void *clipboardDataBytes(id data)
+{
+	return (void *) [((NSData *) data) bytes];
+}
+
+intptr_t clipboardDataLen(id data)
+{
+	return (intptr_t) [((NSData *) data) length];
+}
+
+// returns an array of NSURLs, or nil if there are no files
+id clipboardFiles(void)
+{
+	return [[NSPasteboard generalPasteboard]
+		readObjectsForClasses:[NSArray arrayWithObject:[NSURL class]]
+		options:fileURLsOnly()];
+}
+
+intptr_t clipboardFilesLen(id files)
+{
+	return (intptr_t) [((NSArray *) files) count];
+}
+
+id clipboardFileAt(id files, intptr_t i)
+{
+	return [((NSURL *) [((NSArray *) files) objectAtIndex:(NSUInteger) i]) path];
+}
@@ -0,0 +1,350 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"image"
+	"strings"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern void our_clipboard_owner_change_callback(GtkClipboard *, GdkEvent *, gpointer);
+// extern void our_clipboard_get_callback(GtkClipboard *, GtkSelectionData *, guint, gpointer);
+// extern void our_clipboard_clear_callback(GtkClipboard *, gpointer);
+// /* GDK_SELECTION_CLIPBOARD is a cast of a macro, which cgo can't do */
+// static inline GtkClipboard *gtkClipboard(void)
+// {
+// 	return gtk_clipboard_get(GDK_SELECTION_CLIPBOARD);
+// }
+// /* cgo can't give Go functions to C as function pointers, so do it here; static inline because we have //exports */
+// static inline gboolean gtkClipboardSetWithData(GtkTargetEntry *targets, guint n, gpointer data)
+// {
+// 	return gtk_clipboard_set_with_data(gtkClipboard(), targets, n,
+// 		our_clipboard_get_callback, our_clipboard_clear_callback, data);
+// }
+// static inline GdkAtom htmlAtom(void)
+// {
+// 	return gdk_atom_intern_static_string("text/html");
+// }
+// static inline GdkAtom gnomeCopiedFilesAtom(void)
+// {
+// 	return gdk_atom_intern_static_string("x-special/gnome-copied-files");
+// }
+import "C"
+
+/*
+For ClipboardChanged, GtkClipboard's "owner-change" is emitted when any program takes over the clipboard, including us; it's connected by uiinit().
+The gtk_clipboard_wait_*() functions run the main loop until the program with the clipboard answers, as gtk_dialog_run() does; uitasks can run in the meantime, which is fine, since they don't depend on each other.
+
+With X11 the clipboard holds nothing; the program that copied is asked for the data in the format the pasting program wants, when it pastes. So for SetClipboard() we give GtkClipboard a list of targets (formats) and a function that makes the data for one of them.
+GTK+ knows the names of the text, image, and URI list targets; the info number of each target tells us which kind it is.
+The clipboardContents has to stay around until another program takes the clipboard (GtkClipboard tells us with the clear function), so it's kept in clipboardOwned until then.
+gtk_clipboard_set_can_store() lets a clipboard manager (if one is running) take a copy, so the data is still there after our program quits.
+*/
+
+// runs on the UI thread
+func setClipboardText(text string) {
+	ctext := C.CString(text)
+	defer C.free(unsafe.Pointer(ctext))
+	C.gtk_clipboard_set_text(C.gtkClipboard(), togstr(ctext), -1)
+}
+
+func (s *sysData) copyText(text string) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		setClipboardText(text)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// called by uiinit()
+func clipboardWatch() {
+	g_signal_connect_pointer((*C.GtkWidget)(unsafe.Pointer(C.gtkClipboard())), "owner-change", clipboard_owner_change_callback, nil)
+}
+
+//export our_clipboard_owner_change_callback
+func our_clipboard_owner_change_callback(clipboard *C.GtkClipboard, event *C.GdkEvent, what C.gpointer) {
+	defer recoverUIPanic()
+	sendEvent(ClipboardChanged)
+}
+
+var clipboard_owner_change_callback = C.GCallback(C.our_clipboard_owner_change_callback)
+
+// runs on the UI thread
+func clipboardHasText() bool {
+	return C.gtk_clipboard_wait_is_text_available(C.gtkClipboard()) != C.FALSE
+}
+
+// runs on the UI thread
+func clipboardText() string {
+	text := C.gtk_clipboard_wait_for_text(C.gtkClipboard())
+	if text == nil {
+		return ""
+	}
+	defer C.g_free(C.gpointer(unsafe.Pointer(text)))
+	return fromgstr(text)
+}
+
+// the info numbers of our targets
+const (
+	clipboardInfoText = iota
+	clipboardInfoHTML
+	clipboardInfoImage
+	clipboardInfoFiles
+	clipboardInfoGnomeFiles // what Nautilus pastes files from
+)
+
+// web browsers put this at the start of the HTML they copy; without it, some programs take the HTML as Latin-1
+const clipboardHTMLCharset = `<meta http-equiv="content-type" content="text/html; charset=utf-8">`
+
+// for SetClipboard()
+type clipboardContents struct {
+	data   ClipboardData
+	image  *image.NRGBA
+	pixbuf *C.GdkPixbuf // made by set(), on the UI thread
+}
+
+// only touched on the UI thread
+var clipboardOwned *clipboardContents
+
+func newClipboardContents(data ClipboardData) *clipboardContents {
+	c := &clipboardContents{
+		data: data,
+	}
+	if data.Image != nil {
+		c.image = toClipboardImage(data.Image)
+	}
+	return c
+}
+
+// runs on the UI thread
+func (c *clipboardContents) set() {
+	list := C.gtk_target_list_new(nil, 0)
+	defer C.gtk_target_list_unref(list)
+	if c.data.Text != "" {
+		C.gtk_target_list_add_text_targets(list, clipboardInfoText)
+	}
+	if c.data.HTML != "" {
+		C.gtk_target_list_add(list, C.htmlAtom(), 0, clipboardInfoHTML)
+	}
+	if c.image != nil {
+		c.pixbuf = toPixbuf(c.image)
+		if c.pixbuf != nil {
+			C.gtk_target_list_add_image_targets(list, clipboardInfoImage, C.TRUE)
+		}
+	}
+	if len(c.data.Files) != 0 {
+		C.gtk_target_list_add_uri_targets(list, clipboardInfoFiles)
+		C.gtk_target_list_add(list, C.gnomeCopiedFilesAtom(), 0, clipboardInfoGnomeFiles)
+	}
+	var n C.gint
+	targets := C.gtk_target_table_new_from_list(list, &n)
+	if n == 0 { // nothing to copy; just empty the clipboard
+		C.gtk_clipboard_clear(C.gtkClipboard())
+		return
+	}
+	defer C.gtk_target_table_free(targets, n)
+	// this calls the clear function for what we had on the clipboard before, if anything, so set clipboardOwned after
+	if C.gtkClipboardSetWithData(targets, C.guint(n), C.gpointer(unsafe.Pointer(c))) == C.FALSE {
+		c.free()
+		reportError(newError(nil, "putting data on clipboard", "gtk_clipboard_set_with_data()", nil))
+		return
+	}
+	clipboardOwned = c
+	C.gtk_clipboard_set_can_store(C.gtkClipboard(), nil, 0)
+}
+
+func (c *clipboardContents) free() {
+	if c.pixbuf != nil {
+		C.g_object_unref(C.gpointer(unsafe.Pointer(c.pixbuf)))
+		c.pixbuf = nil
+	}
+}
+
+//export our_clipboard_get_callback
+func our_clipboard_get_callback(clipboard *C.GtkClipboard, sel *C.GtkSelectionData, info C.guint, data C.gpointer) {
+	defer recoverUIPanic()
+	c := (*clipboardContents)(unsafe.Pointer(data))
+	switch info {
+	case clipboardInfoText:
+		ctext := C.CString(c.data.Text)
+		defer C.free(unsafe.Pointer(ctext))
+		C.gtk_selection_data_set_text(sel, togstr(ctext), -1)
+	case clipboardInfoHTML:
+		setSelectionBytes(sel, clipboardHTMLCharset+c.data.HTML)
+	case clipboardInfoImage:
+		C.gtk_selection_data_set_pixbuf(sel, c.pixbuf)
+	case clipboardInfoFiles:
+		uris := fileURIs(c.data.Files)
+		curis := make([]*C.gchar, len(uris)+1) // NULL-terminated
+		for i, u := range uris {
+			cu := C.CString(u)
+			defer C.free(unsafe.Pointer(cu))
+			curis[i] = togstr(cu)
+		}
+		C.gtk_selection_data_set_uris(sel, &curis[0])
+	case clipboardInfoGnomeFiles:
+		setSelectionBytes(sel, "copy\n"+strings.Join(fileURIs(c.data.Files), "\n"))
+	}
+}
+
+//export our_clipboard_clear_callback
+func our_clipboard_clear_callback(clipboard *C.GtkClipboard, data C.gpointer) {
+	defer recoverUIPanic()
+	c := (*clipboardContents)(unsafe.Pointer(data))
+	c.free()
+	if clipboardOwned == c {
+		clipboardOwned = nil
+	}
+}
+
+// for the targets GTK+ doesn't know about; these are all UTF-8
+func setSelectionBytes(sel *C.GtkSelectionData, data string) {
+	cdata := C.CString(data)
+	defer C.free(unsafe.Pointer(cdata))
+	C.gtk_selection_data_set(sel, C.gtk_selection_data_get_target(sel), 8,
+		(*C.guchar)(unsafe.Pointer(cdata)), C.gint(len(data)))
+}
+
+// paths that can't be made into URIs (relative ones, for instance) are left out
+func fileURIs(files []string) []string {
+	var uris []string
+
+	for _, f := range files {
+		cf := C.CString(f)
+		uri := C.g_filename_to_uri(togstr(cf), nil, nil)
+		C.free(unsafe.Pointer(cf))
+		if uri == nil {
+			continue
+		}
+		uris = append(uris, fromgstr(uri))
+		C.g_free(C.gpointer(unsafe.Pointer(uri)))
+	}
+	return uris
+}
+
+// GdkPixbuf is also RGBA without premultiplied alpha, but its rows can be padded
+// returns nil (after reporting it) if there isn't enough memory for the GdkPixbuf, in which case the image is left off the clipboard
+func toPixbuf(img *image.NRGBA) *C.GdkPixbuf {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	pixbuf := C.gdk_pixbuf_new(C.GDK_COLORSPACE_RGB, C.TRUE, 8, C.int(width), C.int(height))
+	if pixbuf == nil {
+		reportError(newError(nil, "making GdkPixbuf for clipboard image", "gdk_pixbuf_new()", nil))
+		return nil
+	}
+	stride := int(C.gdk_pixbuf_get_rowstride(pixbuf))
+	pixels := (*[1 << 30]byte)(unsafe.Pointer(C.gdk_pixbuf_get_pixels(pixbuf)))
+	for y := 0; y < height; y++ {
+		copy(pixels[y*stride:y*stride+width*4], img.Pix[y*img.Stride:])
+	}
+	return pixbuf
+}
+
+func fromPixbuf(pixbuf *C.GdkPixbuf) *image.NRGBA {
+	width := int(C.gdk_pixbuf_get_width(pixbuf))
+	height := int(C.gdk_pixbuf_get_height(pixbuf))
+	stride := int(C.gdk_pixbuf_get_rowstride(pixbuf))
+	channels := int(C.gdk_pixbuf_get_n_channels(pixbuf)) // 3 without alpha, 4 with
+	hasAlpha := C.gdk_pixbuf_get_has_alpha(pixbuf) != C.FALSE
+	pixels := (*[1 << 30]byte)(unsafe.Pointer(C.gdk_pixbuf_get_pixels(pixbuf)))
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := pixels[y*stride+x*channels:]
+			q := img.Pix[y*img.Stride+x*4:]
+			q[0], q[1], q[2], q[3] = p[0], p[1], p[2], 255
+			if hasAlpha {
+				q[3] = p[3]
+			}
+		}
+	}
+	return img
+}
+
+// runs on the UI thread
+func clipboardFormats() (f ClipboardFormat) {
+	var targets *C.GdkAtom
+	var n C.gint
+
+	if C.gtk_clipboard_wait_for_targets(C.gtkClipboard(), &targets, &n) == C.FALSE {
+		return 0 // empty
+	}
+	defer C.g_free(C.gpointer(unsafe.Pointer(targets)))
+	if C.gtk_targets_include_text(targets, n) != C.FALSE {
+		f |= ClipboardFormatText
+	}
+	if C.gtk_targets_include_image(targets, n, C.FALSE) != C.FALSE {
+		f |= ClipboardFormatImage
+	}
+	if C.gtk_targets_include_uri(targets, n) != C.FALSE {
+		f |= ClipboardFormatFiles
+	}
+	html := C.htmlAtom()
+	for _, t := range (*[1 << 20]C.GdkAtom)(unsafe.Pointer(targets))[:n:n] {
+		if t == html {
+			f |= ClipboardFormatHTML
+			break
+		}
+	}
+	return f
+}
+
+// runs on the UI thread
+func clipboardHTML() string {
+	sel := C.gtk_clipboard_wait_for_contents(C.gtkClipboard(), C.htmlAtom())
+	if sel == nil {
+		return ""
+	}
+	defer C.gtk_selection_data_free(sel)
+	n := C.gtk_selection_data_get_length(sel)
+	if n <= 0 {
+		return ""
+	}
+	data := C.GoBytes(unsafe.Pointer(C.gtk_selection_data_get_data(sel)), C.int(n))
+	// Firefox copies HTML as UTF-16, with a byte order mark
+	if len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE {
+		u := make([]uint16, (len(data)-2)/2)
+		for i := range u {
+			u[i] = uint16(data[2+2*i]) | uint16(data[3+2*i])<<8
+		}
+		return string(utf16.Decode(u))
+	}
+	return string(data)
+}
+
+// runs on the UI thread
+func clipboardImage() image.Image {
+	pixbuf := C.gtk_clipboard_wait_for_image(C.gtkClipboard())
+	if pixbuf == nil {
+		return nil
+	}
+	defer C.g_object_unref(C.gpointer(unsafe.Pointer(pixbuf)))
+	return fromPixbuf(pixbuf)
+}
+
+// runs on the UI thread; URIs that aren't files (web pages, for instance) are left out
+func clipboardFiles() (files []string) {
+	uris := C.gtk_clipboard_wait_for_uris(C.gtkClipboard())
+	if uris == nil {
+		return nil
+	}
+	defer C.g_strfreev(uris)
+	for _, uri := range (*[1 << 20]*C.gchar)(unsafe.Pointer(uris))[:] {
+		if uri == nil {
+			break
+		}
+		f := C.g_filename_from_uri(uri, nil, nil)
+		if f == nil {
+			continue
+		}
+		files = append(files, fromgstr(f))
+		C.g_free(C.gpointer(unsafe.Pointer(f)))
+	}
+	return files
+}
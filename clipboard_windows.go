@@ -0,0 +1,298 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image"
+	"syscall"
+	"unsafe"
+)
+
+/*
+The clipboard holds a global memory handle for each format we put on it. For text we only need CF_UNICODETEXT; Windows makes CF_TEXT and CF_OEMTEXT from it for programs that ask for those.
+Once SetClipboardData() succeeds the memory belongs to the clipboard, so we only free it if something goes wrong before then.
+For ClipboardChanged, the invisible window that runs uitasks (see uitask_windows.go) is also a clipboard format listener, so it gets WM_CLIPBOARDUPDATE.
+SetClipboard() puts its formats on the clipboard as that window too. The formats other than text are in clipformats_windows.go.
+*/
+
+var (
+	_openClipboard    = user32.NewProc("OpenClipboard")
+	_emptyClipboard   = user32.NewProc("EmptyClipboard")
+	_setClipboardData = user32.NewProc("SetClipboardData")
+	_closeClipboard   = user32.NewProc("CloseClipboard")
+	_getClipboardData = user32.NewProc("GetClipboardData")
+	_globalAlloc      = kernel32.NewProc("GlobalAlloc")
+	_globalLock       = kernel32.NewProc("GlobalLock")
+	_globalUnlock     = kernel32.NewProc("GlobalUnlock")
+	_globalFree       = kernel32.NewProc("GlobalFree")
+	_globalSize       = kernel32.NewProc("GlobalSize")
+
+	_isClipboardFormatAvailable = user32.NewProc("IsClipboardFormatAvailable")
+	_addClipboardFormatListener = user32.NewProc("AddClipboardFormatListener")
+	_registerClipboardFormat    = user32.NewProc("RegisterClipboardFormatW")
+)
+
+// called by uiinit(); AddClipboardFormatListener() is new in Windows Vista, so on Windows XP we go without ClipboardChanged
+func clipboardWatch(hwnd _HWND) error {
+	if _addClipboardFormatListener.Find() != nil {
+		return nil
+	}
+	r1, _, err := _addClipboardFormatListener.Call(uintptr(hwnd))
+	if r1 == 0 { // failure
+		return err
+	}
+	return nil
+}
+
+// a format and what goes on the clipboard for it; see putClipboard()
+type clipboardItem struct {
+	format uintptr
+	data   []byte
+}
+
+// runs on the UI thread; the window that opens the clipboard owns it after EmptyClipboard()
+// returns an *Error without a Control for the caller to report, as failing to copy isn't worth panicking over (another program could have the clipboard open, for instance)
+func putClipboard(owner _HWND, items []clipboardItem) *Error {
+	r1, _, err := _openClipboard.Call(uintptr(owner))
+	if r1 == 0 { // failure
+		return newError(nil, "opening clipboard to copy", "OpenClipboard()", err)
+	}
+	defer _closeClipboard.Call()
+	r1, _, err = _emptyClipboard.Call()
+	if r1 == 0 { // failure
+		return newError(nil, "emptying clipboard to copy", "EmptyClipboard()", err)
+	}
+	for _, item := range items {
+		mem, _, err := _globalAlloc.Call(
+			uintptr(_GMEM_MOVEABLE),
+			uintptr(len(item.data)))
+		if mem == 0 { // failure
+			return newError(nil, "allocating memory for clipboard data", "GlobalAlloc()", err)
+		}
+		p, _, err := _globalLock.Call(mem)
+		if p == 0 { // failure
+			_globalFree.Call(mem)
+			return newError(nil, "locking memory for clipboard data", "GlobalLock()", err)
+		}
+		copy((*[1 << 30]byte)(unsafe.Pointer(p))[:len(item.data):len(item.data)], item.data)
+		_globalUnlock.Call(mem) // the return value is the lock count, not an error
+		r1, _, err = _setClipboardData.Call(
+			item.format,
+			mem)
+		if r1 == 0 { // failure
+			_globalFree.Call(mem)
+			return newError(nil, "putting data on clipboard", "SetClipboardData()", err)
+		}
+	}
+	return nil
+}
+
+// runs on the UI thread; returns nil if format isn't on the clipboard
+// like putClipboard(), errors are returned for the caller to report
+func getClipboard(format uintptr) ([]byte, *Error) {
+	// we're only reading, so the clipboard doesn't need to be opened by a window of ours
+	r1, _, err := _openClipboard.Call(uintptr(_NULL))
+	if r1 == 0 { // failure
+		return nil, newError(nil, "opening clipboard to paste", "OpenClipboard()", err)
+	}
+	defer _closeClipboard.Call()
+	mem, _, _ := _getClipboardData.Call(format)
+	if mem == 0 { // not there (or failure, which we can't tell apart)
+		return nil, nil
+	}
+	p, _, err := _globalLock.Call(mem)
+	if p == 0 { // failure
+		return nil, newError(nil, "locking clipboard data", "GlobalLock()", err)
+	}
+	defer _globalUnlock.Call(mem)
+	// the memory can be bigger than the data; each format has its own way to say how big the data really is
+	size, _, _ := _globalSize.Call(mem)
+	data := make([]byte, size)
+	copy(data, (*[1 << 30]byte)(unsafe.Pointer(p))[:size:size])
+	return data, nil
+}
+
+func clipboardFormatAvailable(format uintptr) bool {
+	r1, _, _ := _isClipboardFormatAvailable.Call(format)
+	return r1 != 0
+}
+
+// registered formats have the same ID in every program for as long as Windows runs; we get them whenever we need them, as RegisterClipboardFormat() just looks them up after the first time
+func registeredClipboardFormat(name string) uintptr {
+	r1, _, err := _registerClipboardFormat.Call(utf16ToArg(toUTF16(name)))
+	if r1 == 0 { // failure; 0 is not a valid format, so it's never available, and putting it on the clipboard fails, which is reported
+		reportError(newError(nil, "registering clipboard format "+name, "RegisterClipboardFormat()", err))
+	}
+	return r1
+}
+
+// CF_UNICODETEXT is null-terminated UTF-16, with "\r\n" line endings
+func toClipboardText(text string) []byte {
+	return utf16Bytes(syscall.StringToUTF16(toCRLF(text)))
+}
+
+func fromClipboardText(data []byte) string {
+	return fromCRLF(syscall.UTF16ToString(bytesUTF16(data)))
+}
+
+func utf16Bytes(u []uint16) []byte {
+	b := make([]byte, 2*len(u))
+	for i, c := range u {
+		b[2*i] = byte(c)
+		b[2*i+1] = byte(c >> 8)
+	}
+	return b
+}
+
+func bytesUTF16(b []byte) []uint16 {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i]) | uint16(b[2*i+1])<<8
+	}
+	return u
+}
+
+// runs on the UI thread; text has "\n" line endings, like everything else in package ui
+func (s *sysData) setClipboardText(text string) {
+	err := putClipboard(s.hwnd, []clipboardItem{
+		{_CF_UNICODETEXT, toClipboardText(text)},
+	})
+	if err != nil {
+		err.Control = s.control
+		reportError(err)
+	}
+}
+
+func (s *sysData) copyText(text string) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.setClipboardText(text)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// runs on the UI thread
+func clipboardHasText() bool {
+	return clipboardFormatAvailable(_CF_UNICODETEXT)
+}
+
+// runs on the UI thread
+func clipboardText() string {
+	data, err := getClipboard(_CF_UNICODETEXT)
+	if err != nil {
+		reportError(err)
+		return ""
+	}
+	return fromClipboardText(data)
+}
+
+// for SetClipboard(); everything is converted to clipboard formats up front, off the UI thread
+type clipboardContents struct {
+	text  []byte
+	html  []byte
+	png   []byte
+	dib   []byte
+	files []byte
+}
+
+func newClipboardContents(data ClipboardData) *clipboardContents {
+	c := new(clipboardContents)
+	if data.Text != "" {
+		c.text = toClipboardText(data.Text)
+	}
+	if data.HTML != "" {
+		c.html = toCFHTML(data.HTML)
+	}
+	if data.Image != nil {
+		if img := toClipboardImage(data.Image); img != nil {
+			c.png = toClipboardPNG(img)
+			c.dib = toDIB(img)
+		}
+	}
+	if len(data.Files) != 0 {
+		c.files = toHDROP(data.Files)
+	}
+	return c
+}
+
+// runs on the UI thread
+func (c *clipboardContents) set() {
+	var items []clipboardItem
+
+	if c.text != nil {
+		items = append(items, clipboardItem{_CF_UNICODETEXT, c.text})
+	}
+	if c.html != nil {
+		items = append(items, clipboardItem{registeredClipboardFormat(cfHTML), c.html})
+	}
+	// the programs that know about PNG look for it first, which is what keeps the alpha channel; everything else takes CF_DIB (and Windows makes CF_BITMAP from it for the really old ones)
+	if c.png != nil {
+		items = append(items, clipboardItem{registeredClipboardFormat(cfPNG), c.png})
+	}
+	if c.dib != nil {
+		items = append(items, clipboardItem{_CF_DIB, c.dib})
+	}
+	if c.files != nil {
+		items = append(items, clipboardItem{_CF_HDROP, c.files})
+	}
+	err := putClipboard(msghwnd, items)
+	if err != nil {
+		reportError(err)
+	}
+}
+
+// runs on the UI thread
+func clipboardFormats() (f ClipboardFormat) {
+	if clipboardFormatAvailable(_CF_UNICODETEXT) {
+		f |= ClipboardFormatText
+	}
+	if clipboardFormatAvailable(registeredClipboardFormat(cfHTML)) {
+		f |= ClipboardFormatHTML
+	}
+	if clipboardFormatAvailable(registeredClipboardFormat(cfPNG)) || clipboardFormatAvailable(_CF_DIB) {
+		f |= ClipboardFormatImage
+	}
+	if clipboardFormatAvailable(_CF_HDROP) {
+		f |= ClipboardFormatFiles
+	}
+	return f
+}
+
+// runs on the UI thread
+func clipboardHTML() string {
+	data, err := getClipboard(registeredClipboardFormat(cfHTML))
+	if err != nil {
+		reportError(err)
+		return ""
+	}
+	return fromCFHTML(data)
+}
+
+// runs on the UI thread
+func clipboardImage() image.Image {
+	data, err := getClipboard(registeredClipboardFormat(cfPNG))
+	if err == nil && data != nil {
+		if img := fromClipboardPNG(data); img != nil {
+			return img
+		}
+	}
+	// Windows makes CF_DIB from CF_BITMAP and CF_DIBV5, so this gets screenshots and such too
+	data, err = getClipboard(_CF_DIB)
+	if err != nil {
+		reportError(err)
+		return nil
+	}
+	return fromDIB(data)
+}
+
+// runs on the UI thread
+func clipboardFiles() []string {
+	data, err := getClipboard(_CF_HDROP)
+	if err != nil {
+		reportError(err)
+		return nil
+	}
+	return fromHDROP(data)
+}
@@ -0,0 +1,211 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// the clipboard formats other than text, for SetClipboard() and friends; see clipboard_windows.go
+
+// the registered formats; these names are what Office, the web browsers, and everyone else use
+const (
+	cfHTML = "HTML Format"
+	cfPNG  = "PNG"
+)
+
+/*
+CF_HTML is UTF-8: a header of byte offsets, then a whole HTML document with the fragment marked by comments; see http://msdn.microsoft.com/en-us/library/aa767917.aspx
+The offsets are written with a fixed number of digits so the header is the same length no matter what they are.
+*/
+
+const cfHTMLHeader = "Version:0.9\r\nStartHTML:%010d\r\nEndHTML:%010d\r\nStartFragment:%010d\r\nEndFragment:%010d\r\n"
+
+const (
+	cfHTMLPrefix = "<html><body>\r\n<!--StartFragment-->"
+	cfHTMLSuffix = "<!--EndFragment-->\r\n</body></html>"
+)
+
+func toCFHTML(fragment string) []byte {
+	startHTML := len(fmt.Sprintf(cfHTMLHeader, 0, 0, 0, 0))
+	startFragment := startHTML + len(cfHTMLPrefix)
+	endFragment := startFragment + len(fragment)
+	endHTML := endFragment + len(cfHTMLSuffix)
+	return []byte(fmt.Sprintf(cfHTMLHeader, startHTML, endHTML, startFragment, endFragment) +
+		cfHTMLPrefix + fragment + cfHTMLSuffix + "\x00")
+}
+
+// only the offsets matter; some programs write them with fewer digits, and some put other lines in the header
+func fromCFHTML(data []byte) string {
+	start, end := -1, -1
+	for _, line := range strings.Split(string(data), "\r\n") {
+		if strings.HasPrefix(line, "<") { // past the header
+			break
+		}
+		colon := strings.Index(line, ":")
+		if colon == -1 {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(line[colon+1:]))
+		if err != nil {
+			continue
+		}
+		switch line[:colon] {
+		case "StartFragment":
+			start = n
+		case "EndFragment":
+			end = n
+		}
+	}
+	if start < 0 || end < start || end > len(data) {
+		return ""
+	}
+	return string(data[start:end])
+}
+
+/*
+CF_DIB is a BITMAPINFOHEADER followed by the pixels, bottom row first, each row padded to four bytes.
+We write 32-bit BI_RGB, which most programs read without the alpha channel, so the image is put on white first; the PNG we also put on the clipboard has the real alpha channel.
+For reading, we handle the 24-bit and 32-bit formats without a palette, which is what Windows makes from screenshots and what other programs copy; anything else is left for PNG.
+*/
+
+// _BITMAPINFOHEADER is in area_windows.go; it has no padding, so it can be copied as is
+const bitmapInfoHeaderSize = 40
+
+func toDIB(img *image.NRGBA) []byte {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	h := _BITMAPINFOHEADER{
+		biSize:        bitmapInfoHeaderSize,
+		biWidth:       int32(width),
+		biHeight:      int32(height), // positive, so bottom-up
+		biPlanes:      1,
+		biBitCount:    32,
+		biCompression: _BI_RGB,
+		biSizeImage:   uint32(width * height * 4),
+	}
+	data := make([]byte, bitmapInfoHeaderSize, bitmapInfoHeaderSize+width*height*4)
+	copy(data, (*[bitmapInfoHeaderSize]byte)(unsafe.Pointer(&h))[:])
+	onWhite := func(c uint8, a uint8) byte {
+		return byte((uint(c)*uint(a) + 255*(255-uint(a))) / 255)
+	}
+	for y := height - 1; y >= 0; y-- {
+		row := img.Pix[y*img.Stride : y*img.Stride+width*4]
+		for x := 0; x < width*4; x += 4 {
+			r, g, b, a := row[x], row[x+1], row[x+2], row[x+3]
+			data = append(data, onWhite(b, a), onWhite(g, a), onWhite(r, a), 255)
+		}
+	}
+	return data
+}
+
+func fromDIB(data []byte) image.Image {
+	if len(data) < bitmapInfoHeaderSize {
+		return nil
+	}
+	le := binary.LittleEndian
+	size := le.Uint32(data[0:])
+	width := int(int32(le.Uint32(data[4:])))
+	height := int(int32(le.Uint32(data[8:])))
+	bitCount := le.Uint16(data[14:])
+	compression := le.Uint32(data[16:])
+	clrUsed := le.Uint32(data[32:])
+	bottomUp := true
+	if height < 0 {
+		height = -height
+		bottomUp = false
+	}
+	if size < bitmapInfoHeaderSize || width <= 0 || height == 0 || clrUsed != 0 {
+		return nil
+	}
+	offset := int(size)
+	switch {
+	case bitCount == 24 && compression == _BI_RGB:
+	case bitCount == 32 && compression == _BI_RGB:
+	case bitCount == 32 && compression == _BI_BITFIELDS:
+		// we assume the usual masks (the same layout as BI_RGB); with a plain BITMAPINFOHEADER they come after it, and the newer headers have them inside
+		if size == bitmapInfoHeaderSize {
+			offset += 12
+		}
+	default:
+		return nil
+	}
+	bpp := int(bitCount) / 8
+	stride := (width*bpp + 3) &^ 3
+	if offset+stride*height > len(data) {
+		return nil
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		srcy := y
+		if bottomUp {
+			srcy = height - 1 - y
+		}
+		row := data[offset+srcy*stride:]
+		for x := 0; x < width; x++ {
+			p := row[x*bpp:]
+			// the fourth byte of 32-bit BI_RGB isn't alpha to most programs, and is often 0, so ignore it
+			img.SetNRGBA(x, y, color.NRGBA{p[2], p[1], p[0], 255})
+		}
+	}
+	return img
+}
+
+/*
+CF_HDROP is a DROPFILES structure followed by the paths, each null-terminated, with another null at the end.
+We always write UTF-16 paths (fWide); Windows converts them for programs that ask for ANSI ones.
+*/
+
+const dropFilesSize = 20 // DWORD pFiles, POINT pt, BOOL fNC, BOOL fWide
+
+func toHDROP(files []string) []byte {
+	data := make([]byte, dropFilesSize)
+	binary.LittleEndian.PutUint32(data[0:], dropFilesSize) // pFiles: where the paths start
+	binary.LittleEndian.PutUint32(data[16:], 1)            // fWide
+	for _, f := range files {
+		data = append(data, utf16Bytes(syscall.StringToUTF16(f))...)
+	}
+	return append(data, 0, 0)
+}
+
+func fromHDROP(data []byte) []string {
+	if len(data) < dropFilesSize {
+		return nil
+	}
+	offset := binary.LittleEndian.Uint32(data[0:])
+	wide := binary.LittleEndian.Uint32(data[16:]) != 0
+	if offset > uint32(len(data)) {
+		return nil
+	}
+	data = data[offset:]
+	var files []string
+	if !wide { // ANSI; only programs from before Windows 2000 write this, and they only use ASCII anyway
+		for _, f := range strings.Split(string(data), "\x00") {
+			if f == "" {
+				break
+			}
+			files = append(files, f)
+		}
+		return files
+	}
+	u := bytesUTF16(data)
+	start := 0
+	for i, c := range u {
+		if c != 0 {
+			continue
+		}
+		if i == start { // the empty string at the end
+			break
+		}
+		files = append(files, string(utf16.Decode(u[start:i])))
+		start = i + 1
+	}
+	return files
+}
@@ -0,0 +1,106 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image/color"
+	"sync"
+)
+
+// A ColorButton is a Control that shows a color; clicking it lets the user pick another one, with the same dialog as ChooseColor.
+// On Windows, the color is drawn in a push button, which opens the dialog; on GTK+, a ColorButton is a GtkColorButton, and on Mac OS X it is an NSColorWell, which opens the system's color panel and follows it until another NSColorWell is clicked or the panel is closed.
+// The Windows color dialog has no alpha, so there a ColorButton keeps the alpha of its color as is, as ChooseColor does.
+type ColorButton struct {
+	// Changed is signaled when the user picks another color; on Mac OS X, that is every time they change the color in the color panel.
+	// It is not signaled for SetColor.
+	Changed chan struct{}
+
+	lock      sync.Mutex
+	created   bool
+	sysData   *sysData
+	initColor color.NRGBA
+}
+
+// NewColorButton creates a new ColorButton showing initial.
+// If allowAlpha is true, the user can also pick how opaque the color is (except on Windows; see above); otherwise the color is always opaque.
+func NewColorButton(initial color.Color, allowAlpha bool) *ColorButton {
+	b := &ColorButton{
+		Changed:   newEvent(),
+		sysData:   mksysdata(c_colorbutton),
+		initColor: toNRGBA(initial, allowAlpha),
+	}
+	b.sysData.alternate = allowAlpha
+	return b
+}
+
+// Color returns the color the ColorButton shows.
+func (b *ColorButton) Color() color.NRGBA {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.created {
+		return b.initColor
+	}
+	ret := make(chan color.NRGBA)
+	defer close(ret)
+	uitask(func() {
+		ret <- toNRGBA(b.sysData.colorButtonColor(), b.sysData.alternate)
+	})
+	return <-ret
+}
+
+// SetColor changes the color the ColorButton shows; if the ColorButton doesn't allow alpha, c is made opaque.
+func (b *ColorButton) SetColor(c color.Color) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	n := toNRGBA(c, b.sysData.alternate)
+	if !b.created {
+		b.initColor = n
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		b.sysData.colorButtonSetColor(n)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (b *ColorButton) make(window *sysData) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.sysData.control = b
+	b.sysData.event = b.Changed
+	err := b.sysData.make(window)
+	if err != nil {
+		return err
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		b.sysData.colorButtonSetColor(b.initColor)
+		ret <- struct{}{}
+	})
+	<-ret
+	b.created = true
+	return nil
+}
+
+func (b *ColorButton) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	return b.sysData.singleAllocation(x, y, width, height, b)
+}
+
+func (b *ColorButton) preferredSize(d *sysSizeData) (width int, height int) {
+	return b.sysData.preferredSize(d)
+}
+
+func (b *ColorButton) commitResize(a *allocation, d *sysSizeData) {
+	b.sysData.commitResize(a, d)
+}
+
+func (b *ColorButton) getAuxResizeInfo(d *sysSizeData) {
+	b.sysData.getAuxResizeInfo(d)
+}
@@ -0,0 +1,37 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image/color"
+)
+
+// #include "objc_darwin.h"
+import "C"
+
+/*
+On Mac OS X, a ColorButton is an NSColorWell; clicking it opens the shared color panel (not modally, unlike ChooseColor()) and the well follows the panel from then on, sending its action to the app delegate with every change (see appDelegate_colorButtonChanged()). setColor: doesn't send it.
+*/
+
+func makeColorButton(parentWindow C.id, alternate bool, s *sysData) C.id {
+	well := C.makeColorButton(toBOOL(alternate), appDelegate)
+	addControl(parentWindow, well)
+	return well
+}
+
+func (s *sysData) colorButtonSetColor(c color.NRGBA) {
+	rgba := toColorComponents(c)
+	C.colorButtonSetColor(s.id, &rgba[0])
+}
+
+func (s *sysData) colorButtonColor() color.NRGBA {
+	var rgba [4]C.double
+
+	C.colorButtonColor(s.id, &rgba[0])
+	return fromColorComponents(rgba[0], rgba[1], rgba[2], rgba[3])
+}
+
+func colorButtonPrefSize(control C.id) (width int, height int) {
+	r := C.colorButtonPrefSize(control)
+	return int(r.width), int(r.height)
+}
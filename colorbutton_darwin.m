@@ -0,0 +1,73 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSColorWell.h>
+#import <AppKit/NSColorPanel.h>
+#import <AppKit/NSColor.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSColorWell(x) to(NSColorWell, (x))
+
+extern NSRect dummyRect;
+
+/*
+The color panel is shared by the whole program, and whether it shows alpha is a setting of the panel, not of the NSColorWell; so each ColorButton sets it when it takes the panel over.
+*/
+
+// the size Interface Builder gives new NSColorWells; they don't have a size of their own
+#define colorWellWidth 44
+#define colorWellHeight 23
+
+@interface goColorWell : NSColorWell {
+@public
+	BOOL allowAlpha;
+}
+@end
+
+@implementation goColorWell
+
+- (void)activate:(BOOL)exclusive
+{
+	[[NSColorPanel sharedColorPanel] setShowsAlpha:allowAlpha];
+	[super activate:exclusive];
+}
+
+@end
+
+id makeColorButton(BOOL allowAlpha, id delegate)
+{
+	goColorWell *w;
+
+	w = [[goColorWell alloc]
+		initWithFrame:dummyRect];
+	w->allowAlpha = allowAlpha;
+	[w setTarget:delegate];
+	[w setAction:@selector(colorButtonChanged:)];
+	return w;
+}
+
+// components are in [0,1], not premultiplied, as with runColorPanel()
+void colorButtonSetColor(id well, double *rgba)
+{
+	[toNSColorWell(well) setColor:[NSColor colorWithCalibratedRed:rgba[0] green:rgba[1] blue:rgba[2] alpha:rgba[3]]];
+}
+
+void colorButtonColor(id well, double *rgba)
+{
+	CGFloat r, g, b, a;
+
+	[[[toNSColorWell(well) color] colorUsingColorSpaceName:NSCalibratedRGBColorSpace] getRed:&r green:&g blue:&b alpha:&a];
+	rgba[0] = (double) r;
+	rgba[1] = (double) g;
+	rgba[2] = (double) b;
+	rgba[3] = (double) a;
+}
+
+struct xsize colorButtonPrefSize(id well)
+{
+	struct xsize s;
+
+	s.width = colorWellWidth;
+	s.height = colorWellHeight;
+	return s;
+}
@@ -0,0 +1,45 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"image/color"
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+import "C"
+
+/*
+On GTK+, a ColorButton is a GtkColorButton, which runs a GtkColorChooserDialog of its own when clicked; it sends "color-set" only when the user picks a color, not for gtk_color_chooser_set_rgba().
+*/
+
+func gtkColorButtonNew() *C.GtkWidget {
+	button := C.gtk_color_button_new()
+	C.gtk_color_chooser_set_use_alpha(togtkcolorchooser(button), C.FALSE)
+	return button
+}
+
+func gtkColorButtonNewWithAlpha() *C.GtkWidget {
+	button := C.gtk_color_button_new()
+	C.gtk_color_chooser_set_use_alpha(togtkcolorchooser(button), C.TRUE)
+	return button
+}
+
+func togtkcolorchooser(what *C.GtkWidget) *C.GtkColorChooser {
+	return (*C.GtkColorChooser)(unsafe.Pointer(what))
+}
+
+func (s *sysData) colorButtonSetColor(c color.NRGBA) {
+	rgba := toGdkRGBA(c)
+	C.gtk_color_chooser_set_rgba(togtkcolorchooser(s.widget), &rgba)
+}
+
+func (s *sysData) colorButtonColor() color.NRGBA {
+	var rgba C.GdkRGBA
+
+	C.gtk_color_chooser_get_rgba(togtkcolorchooser(s.widget), &rgba)
+	return fromGdkRGBA(rgba)
+}
@@ -0,0 +1,88 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image/color"
+	"unsafe"
+)
+
+/*
+Windows has no color button, so a ColorButton is a push button with the color drawn on it the same way as the badges of Buttons (see badge_windows.go): after the button paints itself, in its NM_CUSTOMDRAW.
+Clicking it runs the color dialog of ChooseColor() right there on the UI thread, owned by the button's Window; see stdWndProc().
+The button doesn't hold the color, so sysData.buttonColor does.
+*/
+
+const (
+	colorButtonInset = 5 // pixels between the color and the edges of the button, so the focus rectangle still shows
+)
+
+var (
+	_frameRect        = user32.NewProc("FrameRect")
+	_getSysColorBrush = user32.NewProc("GetSysColorBrush")
+)
+
+func (s *sysData) colorButtonSetColor(c color.NRGBA) {
+	s.buttonColor = c
+	// this sends NM_CUSTOMDRAW again
+	r1, _, err := _redrawWindow.Call(
+		uintptr(s.hwnd),
+		uintptr(0),
+		uintptr(0),
+		uintptr(_RDW_ERASE|_RDW_INVALIDATE))
+	if r1 == 0 {
+		panic(s.newError("redrawing ColorButton after changing color", "RedrawWindow()", err))
+	}
+}
+
+func (s *sysData) colorButtonColor() color.NRGBA {
+	return s.buttonColor
+}
+
+// runs on the UI thread; window is the Window the button is in
+func (s *sysData) colorButtonClicked(window *sysData) {
+	preview := newColorPreview(nil)
+	defer preview.stop()
+	c, ok := runColorDialog(window.hwnd, s.buttonColor, nil, preview)
+	if !ok || c == s.buttonColor {
+		return
+	}
+	s.colorButtonSetColor(c)
+	s.signal()
+}
+
+// runs on the UI thread; the return value is what stdWndProc() should return for the NM_CUSTOMDRAW
+func (s *sysData) colorButtonCustomDraw(nm *_NMCUSTOMDRAW) _LRESULT {
+	switch nm.dwDrawStage {
+	case _CDDS_PREPAINT:
+		return _CDRF_NOTIFYPOSTPAINT
+	case _CDDS_POSTPAINT:
+		// if any of this fails there's nothing we can do about it; the button is still usable
+		r := nm.rc
+		r.left += colorButtonInset
+		r.top += colorButtonInset
+		r.right -= colorButtonInset
+		r.bottom -= colorButtonInset
+		frame := uintptr(_COLOR_BTNTEXT)
+		if nm.uItemState&_CDIS_DISABLED != 0 {
+			// leave the color out, like the text of a disabled button
+			frame = _COLOR_GRAYTEXT
+		} else {
+			brush, _, _ := _createSolidBrush.Call(uintptr(toCOLORREF(s.buttonColor)))
+			if brush != 0 {
+				_fillRect.Call(
+					uintptr(nm.hdc),
+					uintptr(unsafe.Pointer(&r)),
+					brush)
+				_deleteObject.Call(brush)
+			}
+		}
+		// system color brushes are never deleted
+		brush, _, _ := _getSysColorBrush.Call(frame)
+		_frameRect.Call(
+			uintptr(nm.hdc),
+			uintptr(unsafe.Pointer(&r)),
+			brush)
+	}
+	return _CDRF_DODEFAULT
+}
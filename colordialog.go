@@ -0,0 +1,110 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image/color"
+)
+
+// ColorOptions holds the optional parts of the dialog shown by ChooseColor.
+// A nil *ColorOptions is the same as a zero ColorOptions: no palette and no previewing.
+type ColorOptions struct {
+	// Palette is a set of swatches the user can pick from in addition to the system's own, such as the colors already used in the document.
+	// Windows only has room for 16 of them (the "custom colors" of its dialog); any more are not shown there.
+	Palette []color.Color
+
+	// If Changed is not nil, it is called with the color the user has picked so far every time they change it, so the program can preview the color before the user clicks OK.
+	// It is called on a goroutine of its own, never on the UI thread, so it can use package ui (to redraw an Area, for instance); if the user drags the picker faster than Changed returns, the colors in between are skipped.
+	// It is not called again once ChooseColor has returned.
+	Changed func(c color.NRGBA)
+}
+
+// ChooseColor shows the system's color dialog with initial selected, and returns the color the user picked and true if they clicked OK, or false if they canceled.
+// If allowAlpha is true, the user can also pick how opaque the color is; the Windows color dialog has no way to do that, so there the alpha of initial is returned as is.
+// If allowAlpha is false, the returned color is always opaque.
+//
+// If parent is not nil, the dialog is modal to parent; otherwise it is modal to the entire application (see "On Dialogs" in the package overview).
+// ChooseColor waits for the user to close the dialog; like everything else in package ui, it must not be called on the UI thread.
+func ChooseColor(parent *Window, initial color.Color, allowAlpha bool, options *ColorOptions) (c color.NRGBA, ok bool) {
+	if parent == nil {
+		parent = dialogWindow
+	} else if !parent.created {
+		panic("parent window passed to ChooseColor() before it was created")
+	}
+	if options == nil {
+		options = new(ColorOptions)
+	}
+	start := toNRGBA(initial, allowAlpha)
+	palette := make([]color.NRGBA, len(options.Palette))
+	for i, p := range options.Palette {
+		palette[i] = color.NRGBAModel.Convert(p).(color.NRGBA)
+	}
+	p := newColorPreview(options.Changed)
+	defer p.stop()
+	c, ok = chooseColor(parent, start, allowAlpha, palette, p)
+	if ok && !allowAlpha {
+		c.A = 0xFF
+	}
+	return c, ok
+}
+
+// the color as the dialogs and ColorButton take it; opaque if there's no alpha to pick
+func toNRGBA(c color.Color, allowAlpha bool) color.NRGBA {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if !allowAlpha {
+		n.A = 0xFF
+	}
+	return n
+}
+
+// colorPreview passes the colors the platform dialog reports while it is up to ColorOptions.Changed, with the same coalescing as the updates of RunWithProgress: the UI thread only records the latest color and never waits for Changed
+type colorPreview struct {
+	changed  func(c color.NRGBA)
+	latest   chan color.NRGBA // holds at most the latest color not yet given to changed
+	done     chan struct{}
+	finished chan struct{}
+}
+
+func newColorPreview(changed func(c color.NRGBA)) *colorPreview {
+	p := &colorPreview{
+		changed:  changed,
+		latest:   make(chan color.NRGBA, 1),
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	if changed == nil {
+		close(p.finished)
+		return p
+	}
+	go func() {
+		defer close(p.finished)
+		for {
+			select {
+			case c := <-p.latest:
+				p.changed(c)
+			case <-p.done:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// runs on the UI thread
+func (p *colorPreview) send(c color.NRGBA) {
+	if p.changed == nil {
+		return
+	}
+	// replace the color that's waiting, if any; only the UI thread sends, so the second send can't block
+	select {
+	case <-p.latest:
+	default:
+	}
+	p.latest <- c
+}
+
+// waits for changed to return if it's running, so it isn't called after ChooseColor returns
+func (p *colorPreview) stop() {
+	close(p.done)
+	<-p.finished
+}
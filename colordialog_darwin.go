@@ -0,0 +1,62 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image/color"
+	"unsafe"
+)
+
+// #include "objc_darwin.h"
+import "C"
+
+// see colordialog_darwin.m; parent is not used, since NSColorPanel can't be a sheet
+func chooseColor(parent *Window, initial color.NRGBA, allowAlpha bool, palette []color.NRGBA, preview *colorPreview) (c color.NRGBA, ok bool) {
+	rgba := toColorComponents(initial)
+	var ppalette *C.double
+	if len(palette) != 0 {
+		components := make([]C.double, 0, 4*len(palette))
+		for _, p := range palette {
+			components = append(components, toColorComponents(p)...)
+		}
+		ppalette = &components[0]
+	}
+	calpha := C.BOOL(C.NO)
+	if allowAlpha {
+		calpha = C.BOOL(C.YES)
+	}
+	ret := make(chan bool)
+	defer close(ret)
+	uitask(func() {
+		r := C.runColorPanel(&rgba[0], calpha, ppalette, C.intptr_t(len(palette)), unsafe.Pointer(preview))
+		ret <- r != C.NO
+	})
+	if !<-ret {
+		return color.NRGBA{}, false
+	}
+	return fromColorComponents(rgba[0], rgba[1], rgba[2], rgba[3]), true
+}
+
+//export colorPanel_changed
+func colorPanel_changed(data unsafe.Pointer, r C.double, g C.double, b C.double, a C.double) {
+	defer recoverUIPanic()
+	preview := (*colorPreview)(data)
+	preview.send(fromColorComponents(r, g, b, a))
+}
+
+// NSColor components are in [0,1], not premultiplied
+func toColorComponents(c color.NRGBA) []C.double {
+	return []C.double{
+		C.double(c.R) / 255,
+		C.double(c.G) / 255,
+		C.double(c.B) / 255,
+		C.double(c.A) / 255,
+	}
+}
+
+func fromColorComponents(r C.double, g C.double, b C.double, a C.double) color.NRGBA {
+	component := func(x C.double) uint8 {
+		return uint8(x*255 + 0.5)
+	}
+	return color.NRGBA{component(r), component(g), component(b), component(a)}
+}
@@ -0,0 +1,172 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#include "_cgo_export.h"
+#import <AppKit/NSApplication.h>
+#import <AppKit/NSColorPanel.h>
+#import <AppKit/NSColorList.h>
+#import <AppKit/NSColor.h>
+#import <AppKit/NSButton.h>
+#import <AppKit/NSView.h>
+#import <Foundation/NSProcessInfo.h>
+
+/*
+NSColorPanel is a single panel shared by the whole program, and it isn't a dialog: it has no OK or Cancel, and it applies colors as the user picks them.
+So we give it an accessory view with Cancel and OK buttons and run it modally until one of them (or the close button) stops it, then put it back the way it was.
+It can't be a sheet, so it's modal to the whole program even if a parent Window is given.
+The palette is an NSColorList attached to the panel for as long as it's up; the user finds it under the color palettes pane.
+*/
+
+#define panelMargin 20		/* the HIG's window margin */
+#define panelPadding 12		/* between buttons */
+
+@interface goColorPanelController : NSObject <NSWindowDelegate> {
+@public
+	void *preview;
+}
+- (IBAction)ok:(id)sender;
+- (IBAction)cancel:(id)sender;
+- (IBAction)colorChanged:(id)sender;
+@end
+
+static void getColor(NSColor *c, double *rgba)
+{
+	CGFloat r, g, b, a;
+
+	[[c colorUsingColorSpaceName:NSCalibratedRGBColorSpace] getRed:&r green:&g blue:&b alpha:&a];
+	rgba[0] = (double) r;
+	rgba[1] = (double) g;
+	rgba[2] = (double) b;
+	rgba[3] = (double) a;
+}
+
+static NSColor *makeColor(double *rgba)
+{
+	return [NSColor colorWithCalibratedRed:rgba[0] green:rgba[1] blue:rgba[2] alpha:rgba[3]];
+}
+
+@implementation goColorPanelController
+
+- (IBAction)ok:(id)sender
+{
+	[NSApp stopModalWithCode:NSOKButton];
+}
+
+- (IBAction)cancel:(id)sender
+{
+	[NSApp stopModalWithCode:NSCancelButton];
+}
+
+- (IBAction)colorChanged:(id)sender
+{
+	double rgba[4];
+
+	getColor([(NSColorPanel *) sender color], rgba);
+	colorPanel_changed(preview, rgba[0], rgba[1], rgba[2], rgba[3]);
+}
+
+- (BOOL)windowShouldClose:(id)win
+{
+	[NSApp stopModalWithCode:NSCancelButton];
+	return YES;
+}
+
+@end
+
+static NSButton *panelButton(NSString *title, SEL action, id target)
+{
+	NSButton *b;
+
+	b = (NSButton *) makeButton();
+	applyStandardControlFont(b);
+	[b setTitle:title];
+	[b setTarget:target];
+	[b setAction:action];
+	[b sizeToFit];
+	return b;
+}
+
+// Cancel then OK, on the right, like every other dialog; the font panel uses these too (see fontdialog_darwin.m), so target has to have ok: and cancel:
+NSView *panelButtons(id target)
+{
+	NSButton *ok, *cancel;
+	NSView *v;
+	NSRect okr, cancelr;
+
+	ok = panelButton(@"OK", @selector(ok:), target);
+	[ok setKeyEquivalent:@"\r"];
+	cancel = panelButton(@"Cancel", @selector(cancel:), target);
+	[cancel setKeyEquivalent:@"\033"];
+	okr = [ok frame];
+	cancelr = [cancel frame];
+	// make them the same width, as the HIG asks
+	if (okr.size.width < cancelr.size.width)
+		okr.size.width = cancelr.size.width;
+	cancelr.size.width = okr.size.width;
+	v = [[NSView alloc]
+		initWithFrame:NSMakeRect(0, 0,
+			panelMargin + cancelr.size.width + panelPadding + okr.size.width + panelMargin,
+			okr.size.height + 2 * panelPadding)];
+	okr.origin.x = [v frame].size.width - panelMargin - okr.size.width;
+	okr.origin.y = panelPadding;
+	cancelr.origin.x = okr.origin.x - panelPadding - cancelr.size.width;
+	cancelr.origin.y = panelPadding;
+	[ok setFrame:okr];
+	[cancel setFrame:cancelr];
+	// keep the buttons at the right as the panel is resized
+	[ok setAutoresizingMask:NSViewMinXMargin];
+	[cancel setAutoresizingMask:NSViewMinXMargin];
+	[v addSubview:cancel];
+	[v addSubview:ok];
+	[cancel release];		// the view has them now
+	[ok release];
+	[v setAutoresizingMask:NSViewWidthSizable];
+	return v;
+}
+
+BOOL runColorPanel(double *rgba, BOOL allowAlpha, double *palette, intptr_t npalette, void *preview)
+{
+	NSColorPanel *panel;
+	goColorPanelController *c;
+	NSColorList *list = nil;
+	NSView *buttons;
+	NSInteger res;
+	intptr_t i;
+
+	panel = [NSColorPanel sharedColorPanel];
+	c = [goColorPanelController new];
+	c->preview = preview;
+	if (npalette != 0) {
+		list = [[NSColorList alloc] initWithName:[[NSProcessInfo processInfo] processName]];
+		for (i = 0; i < npalette; i++)
+			[list insertColor:makeColor(&palette[4 * i])
+				key:[NSString stringWithFormat:@"%ld", (long) (i + 1)]
+				atIndex:(NSUInteger) i];
+		[panel attachColorList:list];
+	}
+	[panel setShowsAlpha:allowAlpha];
+	[panel setColor:makeColor(rgba)];
+	// set the target after the initial color, so it isn't reported as a change
+	[panel setTarget:c];
+	[panel setAction:@selector(colorChanged:)];
+	[panel setContinuous:YES];
+	[panel setDelegate:c];
+	buttons = panelButtons(c);
+	[panel setAccessoryView:buttons];
+	[buttons release];
+
+	res = [NSApp runModalForWindow:panel];
+	getColor([panel color], rgba);
+
+	[panel orderOut:panel];
+	[panel setAccessoryView:nil];
+	[panel setDelegate:nil];
+	[panel setTarget:nil];
+	[panel setAction:NULL];
+	if (list != nil) {
+		[panel detachColorList:list];
+		[list release];
+	}
+	[c release];
+	return res == NSOKButton;
+}
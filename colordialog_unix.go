@@ -0,0 +1,107 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"image/color"
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern void our_colordialog_rgba_callback(GObject *, GParamSpec *, gpointer);
+// static inline GtkColorChooser *gtkColorChooser(GtkWidget *w)
+// {
+// 	return GTK_COLOR_CHOOSER(w);
+// }
+import "C"
+
+/*
+GtkColorChooserDialog (new in GTK+ 3.4) has everything we need: alpha, custom palettes, and a rgba property that changes as the user picks.
+It's run through the same dialog struct as the message boxes in dialog_unix.go, so it's modal the same way; the color is read back in dialog.finish, before the dialog is destroyed.
+*/
+
+// like the GTK+ default palette
+const colorDialogPaletteColumns = 9
+
+func chooseColor(parent *Window, initial color.NRGBA, allowAlpha bool, palette []color.NRGBA, preview *colorPreview) (c color.NRGBA, ok bool) {
+	d := mkdialog(parent)
+	d.finish = func(box *C.GtkWidget, res C.gint) {
+		if res == C.gint(C.GTK_RESPONSE_OK) {
+			c = getColorDialogColor(box)
+		}
+	}
+	uitask(func() {
+		ctitle := C.CString("Select a Color")
+		defer C.free(unsafe.Pointer(ctitle))
+		d.run(func() *C.GtkWidget {
+			box := C.gtk_color_chooser_dialog_new((*C.gchar)(unsafe.Pointer(ctitle)), d.pwin)
+			C.gtk_window_set_modal(togtkwindow(box), C.TRUE)
+			chooser := C.gtkColorChooser(box)
+			if allowAlpha {
+				C.gtk_color_chooser_set_use_alpha(chooser, C.TRUE)
+			} else {
+				C.gtk_color_chooser_set_use_alpha(chooser, C.FALSE)
+			}
+			if len(palette) != 0 {
+				colors := make([]C.GdkRGBA, len(palette))
+				for i, p := range palette {
+					colors[i] = toGdkRGBA(p)
+				}
+				perLine := colorDialogPaletteColumns
+				if len(palette) < perLine {
+					perLine = len(palette)
+				}
+				// this goes above the default palette; GTK+ copies the colors
+				C.gtk_color_chooser_add_palette(chooser, C.GTK_ORIENTATION_HORIZONTAL,
+					C.gint(perLine), C.gint(len(colors)), &colors[0])
+			}
+			rgba := toGdkRGBA(initial)
+			C.gtk_color_chooser_set_rgba(chooser, &rgba)
+			// connect after setting the initial color, so it isn't reported as a change
+			g_signal_connect_pointer(box, "notify::rgba", colordialog_rgba_callback, unsafe.Pointer(preview))
+			return box
+		})
+	})
+	res := <-d.result
+	return c, res == int(C.GTK_RESPONSE_OK)
+}
+
+//export our_colordialog_rgba_callback
+func our_colordialog_rgba_callback(obj *C.GObject, pspec *C.GParamSpec, data C.gpointer) {
+	defer recoverUIPanic()
+	preview := (*colorPreview)(unsafe.Pointer(data))
+	preview.send(getColorDialogColor((*C.GtkWidget)(unsafe.Pointer(obj))))
+}
+
+var colordialog_rgba_callback = C.GCallback(C.our_colordialog_rgba_callback)
+
+func getColorDialogColor(box *C.GtkWidget) color.NRGBA {
+	var rgba C.GdkRGBA
+
+	C.gtk_color_chooser_get_rgba(C.gtkColorChooser(box), &rgba)
+	return fromGdkRGBA(rgba)
+}
+
+// GdkRGBA components are doubles in [0,1], not premultiplied
+func toGdkRGBA(c color.NRGBA) C.GdkRGBA {
+	return C.GdkRGBA{
+		red:   C.gdouble(c.R) / 255,
+		green: C.gdouble(c.G) / 255,
+		blue:  C.gdouble(c.B) / 255,
+		alpha: C.gdouble(c.A) / 255,
+	}
+}
+
+func fromGdkRGBA(rgba C.GdkRGBA) color.NRGBA {
+	component := func(x C.gdouble) uint8 {
+		return uint8(x*255 + 0.5)
+	}
+	return color.NRGBA{
+		R: component(rgba.red),
+		G: component(rgba.green),
+		B: component(rgba.blue),
+		A: component(rgba.alpha),
+	}
+}
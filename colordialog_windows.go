@@ -0,0 +1,162 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"syscall"
+	"unsafe"
+)
+
+/*
+ChooseColor() has no notification for the color changing while the dialog is up, but it fills in the Red, Green, and Blue edit controls of the full dialog every time the user picks something (including while dragging in the spectrum), and those send EN_CHANGE to the dialog.
+So we give it a hook procedure, which sees the dialog's WM_COMMANDs, and read the three edit controls back whenever one of them changes.
+The Windows color dialog has no alpha; the alpha of the initial color is what we return.
+*/
+
+var (
+	_chooseColor          = comdlg32.NewProc("ChooseColorW")
+	_commDlgExtendedError = comdlg32.NewProc("CommDlgExtendedError")
+	_getDlgItemInt        = user32.NewProc("GetDlgItemInt")
+)
+
+// the IDs of the Red, Green, and Blue edit controls; these are in colordlg.h, which windows.h doesn't include, so tools/windowsconstgen.go can't get them
+const (
+	colorDialogRed   = 706
+	colorDialogGreen = 707
+	colorDialogBlue  = 708
+)
+
+type _CHOOSECOLOR struct {
+	lStructSize    uint32
+	hwndOwner      _HWND
+	hInstance      _HWND
+	rgbResult      uint32
+	lpCustColors   *uint32
+	Flags          uint32
+	lCustData      _LPARAM
+	lpfnHook       uintptr
+	lpTemplateName *uint16
+}
+
+// COLORREFs are 0x00BBGGRR
+func toCOLORREF(c color.NRGBA) uint32 {
+	return uint32(c.R) | uint32(c.G)<<8 | uint32(c.B)<<16
+}
+
+func fromCOLORREF(cr uint32, alpha uint8) color.NRGBA {
+	return color.NRGBA{uint8(cr), uint8(cr >> 8), uint8(cr >> 16), alpha}
+}
+
+type colorDialog struct {
+	preview *colorPreview
+	alpha   uint8
+}
+
+// the hook procedure has no way to get at the colorDialog after WM_INITDIALOG, so remember it by dialog; only the UI thread uses this
+var colorDialogs = make(map[_HWND]*colorDialog)
+
+var colorDialogHookCallback uintptr
+
+func init() {
+	// not in the var declaration above, for the same reason as listboxSubclassCallback
+	colorDialogHookCallback = syscall.NewCallback(colorDialogHookProc)
+}
+
+func chooseColor(parent *Window, initial color.NRGBA, allowAlpha bool, palette []color.NRGBA, preview *colorPreview) (c color.NRGBA, ok bool) {
+	var owner _HWND
+	if parent != dialogWindow {
+		owner = parent.sysData.hwnd
+	}
+	// TODO without an owner the dialog isn't modal to anything; MessageBox() has MB_TASKMODAL for this, but ChooseColor() has nothing like it
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		c, ok = runColorDialog(owner, initial, palette, preview)
+		ret <- struct{}{}
+	})
+	<-ret
+	return c, ok
+}
+
+// runs on the UI thread; this is also what a ColorButton shows when clicked (see colorbutton_windows.go)
+func runColorDialog(owner _HWND, initial color.NRGBA, palette []color.NRGBA, preview *colorPreview) (c color.NRGBA, ok bool) {
+	// the custom colors; the ones the palette doesn't fill are left white, like the dialog does on its own
+	var custom [16]uint32
+	for i := range custom {
+		custom[i] = 0xFFFFFF
+		if i < len(palette) {
+			custom[i] = toCOLORREF(palette[i])
+		}
+	}
+	d := &colorDialog{
+		preview: preview,
+		alpha:   initial.A,
+	}
+	cc := &_CHOOSECOLOR{
+		rgbResult:    toCOLORREF(initial),
+		lpCustColors: &custom[0],
+		// CC_FULLOPEN shows the spectrum and the edit controls we read from right away
+		Flags:     _CC_RGBINIT | _CC_FULLOPEN | _CC_ANYCOLOR | _CC_ENABLEHOOK,
+		lCustData: _LPARAM(unsafe.Pointer(d)),
+		lpfnHook:  colorDialogHookCallback,
+		hwndOwner: owner,
+	}
+	cc.lStructSize = uint32(unsafe.Sizeof(*cc))
+	r1, _, _ := _chooseColor.Call(uintptr(unsafe.Pointer(cc)))
+	// ChooseColor() returns 0 both when the user cancels and when it fails; CommDlgExtendedError() tells the two apart (it's 0 for canceling)
+	if r1 == 0 {
+		r1, _, _ = _commDlgExtendedError.Call()
+		if r1 != 0 {
+			panic(fmt.Errorf("error showing color dialog: ChooseColor() failed with extended error 0x%X", r1))
+		}
+		return color.NRGBA{}, false
+	}
+	return fromCOLORREF(cc.rgbResult, d.alpha), true
+}
+
+func colorDialogHookProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM) uintptr {
+	defer recoverUIPanic()
+	switch uMsg {
+	case _WM_INITDIALOG:
+		cc := (*_CHOOSECOLOR)(unsafe.Pointer(lParam))
+		colorDialogs[hwnd] = (*colorDialog)(unsafe.Pointer(cc.lCustData))
+		return _TRUE // let the dialog set the focus
+	case _WM_COMMAND:
+		d, ok := colorDialogs[hwnd]
+		if !ok || wParam.HIWORD() != _EN_CHANGE {
+			break
+		}
+		switch wParam.LOWORD() {
+		case colorDialogRed, colorDialogGreen, colorDialogBlue:
+			d.preview.send(color.NRGBA{
+				R: colorDialogComponent(hwnd, colorDialogRed),
+				G: colorDialogComponent(hwnd, colorDialogGreen),
+				B: colorDialogComponent(hwnd, colorDialogBlue),
+				A: d.alpha,
+			})
+		}
+	case _WM_DESTROY:
+		delete(colorDialogs, hwnd)
+	}
+	return _FALSE // let the dialog handle everything itself
+}
+
+// the user can type anything into the edit controls; the dialog itself clamps to [0,255], so we do too
+func colorDialogComponent(hwnd _HWND, id uintptr) uint8 {
+	var translated int32
+
+	r1, _, _ := _getDlgItemInt.Call(
+		uintptr(hwnd),
+		id,
+		uintptr(unsafe.Pointer(&translated)),
+		uintptr(_FALSE)) // unsigned
+	if translated == 0 { // empty or not a number
+		return 0
+	}
+	if r1 > 255 {
+		return 255
+	}
+	return uint8(r1)
+}
@@ -7,18 +7,32 @@ import (
 	"sync"
 )
 
-// A Combobox is a drop-down list of items, of which at most one can be selected at any given time. You may optionally make the combobox editable to allow custom items. Initially, no item will be selected (and no text entered in an editable Combobox's entry field). What happens to the text shown in a Combobox if its width is too small is implementation-defined.
+// A Combobox is a drop-down list of items, of which at most one can be selected at any given time. You may optionally make the combobox editable to allow custom items. Initially, no item will be selected (and no text entered in an editable Combobox's entry field) unless SetSelected was called beforehand. What happens to the text shown in a Combobox if its width is too small is implementation-defined.
+// A long list can be divided into groups with separators and headers; see AppendSeparator and AppendHeader.
 type Combobox struct {
-	lock      sync.Mutex
-	created   bool
-	sysData   *sysData
-	initItems []string
+	lock         sync.Mutex
+	created      bool
+	sysData      *sysData
+	initItems    []string
+	initSelected int
+	kinds        []comboboxKind // of each item; unlike initItems, this is kept up to date once the Combobox is created
 }
 
+// the kinds of items in a Combobox; see Combobox.AppendSeparator() and Combobox.AppendHeader()
+type comboboxKind int
+
+const (
+	comboboxItem comboboxKind = iota
+	comboboxSeparator
+	comboboxHeader
+)
+
 func newCombobox(editable bool, items ...string) (c *Combobox) {
 	c = &Combobox{
-		sysData:   mksysdata(c_combobox),
-		initItems: items,
+		sysData:      mksysdata(c_combobox),
+		initItems:    items,
+		initSelected: -1,
+		kinds:        make([]comboboxKind, len(items)),
 	}
 	c.sysData.alternate = editable
 	return c
@@ -40,6 +54,7 @@ func (c *Combobox) Append(what ...string) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	c.kinds = append(c.kinds, make([]comboboxKind, len(what))...)
 	if c.created {
 		for _, s := range what {
 			c.sysData.append(s)
@@ -49,6 +64,35 @@ func (c *Combobox) Append(what ...string) {
 	c.initItems = append(c.initItems, what...)
 }
 
+// AppendSeparator adds a separator line to the end of the Combobox's list, to set the items after it apart from those before it.
+// Separators and headers (see AppendHeader) count as items for the indices of the items after them (and for Len and Delete), but they cannot be selected: SetSelected panics if given one, and the user can't pick one either.
+// On Windows, whose combo boxes have neither, a separator is an item showing a line and a header is an item with its text; the Combobox moves the selection on to the next item if the user selects one of them.
+// Both panic if the Combobox is editable, as the editable combo boxes of Mac OS X don't have separators or items that can't be selected.
+func (c *Combobox) AppendSeparator() {
+	c.appendSpecial("", comboboxSeparator, "AppendSeparator")
+}
+
+// AppendHeader adds an item with the given text to the end of the Combobox's list to name the group of items after it, such as "Recent" or "All devices".
+// On GTK+ and Mac OS X, headers are shown dimmed; on all systems, they cannot be selected (see AppendSeparator).
+func (c *Combobox) AppendHeader(text string) {
+	c.appendSpecial(text, comboboxHeader, "AppendHeader")
+}
+
+func (c *Combobox) appendSpecial(text string, kind comboboxKind, what string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.sysData.alternate {
+		panic(fmt.Errorf("Combobox.%s() called on an editable Combobox", what))
+	}
+	c.kinds = append(c.kinds, kind)
+	if c.created {
+		c.sysData.comboboxAppendSpecial(text, kind)
+		return
+	}
+	c.initItems = append(c.initItems, text)
+}
+
 // InsertBefore inserts a new item in the Combobox before the item at the given position. It panics if the given index is out of bounds.
 // InsertBefore will also panic if something goes wrong on platforms that do not abort themselves.
 func (c *Combobox) InsertBefore(what string, before int) {
@@ -61,16 +105,21 @@ func (c *Combobox) InsertBefore(what string, before int) {
 		if before < 0 || before >= c.sysData.len() {
 			goto badrange
 		}
+		c.kinds = append(c.kinds[:before], append([]comboboxKind{comboboxItem}, c.kinds[before:]...)...)
 		c.sysData.insertBefore(what, before)
 		return
 	}
 	if before < 0 || before >= len(c.initItems) {
 		goto badrange
 	}
+	c.kinds = append(c.kinds[:before], append([]comboboxKind{comboboxItem}, c.kinds[before:]...)...)
 	m = make([]string, 0, len(c.initItems)+1)
 	m = append(m, c.initItems[:before]...)
 	m = append(m, what)
 	c.initItems = append(m, c.initItems[before:]...)
+	if c.initSelected >= before { // the selected item moves down with the rest
+		c.initSelected++
+	}
 	return
 badrange:
 	panic(fmt.Errorf("index %d out of range in Combobox.InsertBefore()", before))
@@ -85,13 +134,20 @@ func (c *Combobox) Delete(index int) {
 		if index < 0 || index >= c.sysData.len() {
 			goto badrange
 		}
+		c.kinds = append(c.kinds[:index], c.kinds[index+1:]...)
 		c.sysData.delete(index)
 		return
 	}
 	if index < 0 || index >= len(c.initItems) {
 		goto badrange
 	}
+	c.kinds = append(c.kinds[:index], c.kinds[index+1:]...)
 	c.initItems = append(c.initItems[:index], c.initItems[index+1:]...)
+	if c.initSelected == index { // deleted items aren't selected anymore
+		c.initSelected = -1
+	} else if c.initSelected > index {
+		c.initSelected--
+	}
 	return
 badrange:
 	panic(fmt.Errorf("index %d out of range in Combobox.Delete()", index))
@@ -105,7 +161,10 @@ func (c *Combobox) Selection() string {
 	if c.created {
 		return c.sysData.text()
 	}
-	return ""
+	if c.initSelected == -1 {
+		return ""
+	}
+	return c.initItems[c.initSelected]
 }
 
 // SelectedIndex returns the index of the current selection in the Combobox. It returns -1 either if no selection was made or if text was manually entered in an editable Combobox.
@@ -116,7 +175,38 @@ func (c *Combobox) SelectedIndex() int {
 	if c.created {
 		return c.sysData.selectedIndex()
 	}
-	return -1
+	return c.initSelected
+}
+
+// SetSelected selects the item at the given index in the Combobox; for an editable Combobox, the item's text replaces whatever was in the entry field.
+// An index of -1 clears the selection (and the entry field of an editable Combobox).
+// If the Window containing the Combobox has not been created yet, SetSelected sets the item that will be selected when it is.
+// It panics if the given index is out of range or is that of a separator or header.
+func (c *Combobox) SetSelected(index int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if index >= 0 && index < len(c.kinds) && c.kinds[index] != comboboxItem {
+		panic(fmt.Errorf("index %d in Combobox.SetSelected() is a separator or header, which can't be selected", index))
+	}
+	if c.created {
+		if index < -1 || index >= c.sysData.len() {
+			goto badrange
+		}
+		if index == -1 {
+			c.sysData.selectIndices(nil)
+			return
+		}
+		c.sysData.selectIndices([]int{index})
+		return
+	}
+	if index < -1 || index >= len(c.initItems) {
+		goto badrange
+	}
+	c.initSelected = index
+	return
+badrange:
+	panic(fmt.Errorf("index %d out of range in Combobox.SetSelected()", index))
 }
 
 // Len returns the number of items in the Combobox.
@@ -136,25 +226,30 @@ func (c *Combobox) make(window *sysData) (err error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	c.sysData.control = c
 	err = c.sysData.make(window)
 	if err != nil {
 		return err
 	}
-	for _, s := range c.initItems {
+	for i, s := range c.initItems {
+		if c.kinds[i] != comboboxItem {
+			c.sysData.comboboxAppendSpecial(s, c.kinds[i])
+			continue
+		}
 		c.sysData.append(s)
 	}
+	if c.initSelected != -1 {
+		c.sysData.selectIndices([]int{c.initSelected})
+	}
+	if c.sysData.setting != nil {
+		window.addSetting(c.sysData.setting)
+	}
 	c.created = true
 	return nil
 }
 
 func (c *Combobox) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
-	return []*allocation{&allocation{
-		x:       x,
-		y:       y,
-		width:   width,
-		height:  height,
-		this:		c,
-	}}
+	return c.sysData.singleAllocation(x, y, width, height, c)
 }
 
 func (c *Combobox) preferredSize(d *sysSizeData) (width int, height int) {
@@ -0,0 +1,19 @@
+// 10 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// see combobox_darwin.m
+
+func (s *sysData) comboboxAppendSpecial(what string, kind comboboxKind) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		C.comboboxAppendSpecial(s.id, toNSString(what), toBOOL(kind == comboboxSeparator))
+		s.invalidatePreferredSize()
+		ret <- struct{}{}
+	})
+	<-ret
+}
@@ -4,6 +4,11 @@
 #import <AppKit/NSPopUpButton.h>
 #import <AppKit/NSComboBox.h>
 #import <AppKit/NSArrayController.h>
+#import <AppKit/NSMenu.h>
+#import <AppKit/NSMenuItem.h>
+#import <Foundation/NSIndexSet.h>
+#import <Foundation/NSValue.h>
+#import <Foundation/NSDictionary.h>
 
 /*
 Cocoa doesn't have combo boxes in the sense that other systems do. NSPopUpButton is not editable and technically behaves like a menu on a menubar. NSComboBox is editable and is the more traditional combo box, but the edit field and list are even more separated than they are on other platforms.
@@ -13,6 +18,10 @@ Unfortunately, their default internal storage mechanisms exhibit the automatic s
 The NSArrayController we use in our Listboxes already behaves the way we want. Consequently, you'll notice a bunch of functions here call functions in listbox_darwin.m. How convenient =P (TODO separate into objc_darwin.m?)
 
 TODO should we use NSComboBox's dataSource feature?
+
+Separators and headers (see Combobox.AppendSeparator()) are only for NSPopUpButtons; NSComboBox has neither.
+An NSPopUpButton bound to an NSArrayController makes a plain menu item for each object, so we mark separators and headers in their dictionaries and fix up the menu after every change: separators become [NSMenuItem separatorItem] and headers are disabled.
+The binding redoes the menu items whenever the array changes, but everything that changes the array goes through here, so fixing them each time is enough.
 */
 
 extern NSRect dummyRect;
@@ -20,14 +29,17 @@ extern NSRect dummyRect;
 #define to(T, x) ((T *) (x))
 #define toNSPopUpButton(x) to(NSPopUpButton, (x))
 #define toNSComboBox(x) to(NSComboBox, (x))
+#define toNSArrayController(x) to(NSArrayController, (x))
 
 #define toNSInteger(x) ((NSInteger) (x))
 #define fromNSInteger(x) ((intptr_t) (x))
+#define toNSUInteger(x) ((NSUInteger) (x))
 
 #define COMBOBOXKEY @"cbitem"
 static NSString *comboboxKey = COMBOBOXKEY;
 static NSString *comboboxBinding = @"contentValues";
 static NSString *comboboxKeyPath = @"arrangedObjects." COMBOBOXKEY;
+static NSString *comboboxSeparatorKey = @"cbseparator";		// NSNumber; present only for separators and headers
 
 id makeCombobox(BOOL editable)
 {
@@ -68,12 +80,53 @@ id comboboxText(id c, BOOL editable)
 	return [toNSComboBox(c) stringValue];
 }
 
+static void comboboxFixSpecial(id c)
+{
+	NSMenu *menu;
+	NSArray *items;
+	NSUInteger i;
+
+	if (![c isKindOfClass:[NSPopUpButton class]])
+		return;
+	menu = [toNSPopUpButton(c) menu];
+	// otherwise the menu enables every item with an action again
+	[menu setAutoenablesItems:NO];
+	items = [boundListboxArray(c, comboboxBinding) arrangedObjects];
+	for (i = 0; i < [items count]; i++) {
+		NSNumber *separator;
+
+		separator = [[items objectAtIndex:i] objectForKey:comboboxSeparatorKey];
+		if (separator == nil)
+			continue;
+		if ([separator boolValue]) {
+			if (![[menu itemAtIndex:toNSInteger(i)] isSeparatorItem]) {
+				[menu removeItemAtIndex:toNSInteger(i)];
+				[menu insertItem:[NSMenuItem separatorItem] atIndex:toNSInteger(i)];
+			}
+		} else
+			[[menu itemAtIndex:toNSInteger(i)] setEnabled:NO];
+	}
+}
+
 void comboboxAppend(id c, BOOL editable, id str)
 {
 	id ac;
 
 	ac = boundListboxArray(c, comboboxBinding);
 	listboxArrayAppend(ac, toListboxItem(comboboxKey, str));
+	comboboxFixSpecial(c);
+}
+
+void comboboxAppendSpecial(id c, id str, BOOL separator)
+{
+	id ac;
+	NSMutableDictionary *item;
+
+	ac = boundListboxArray(c, comboboxBinding);
+	item = (NSMutableDictionary *) toListboxItem(comboboxKey, str);
+	[item setObject:[NSNumber numberWithBool:separator] forKey:comboboxSeparatorKey];
+	listboxArrayAppend(ac, item);
+	comboboxFixSpecial(c);
 }
 
 void comboboxInsertBefore(id c, BOOL editable, id str, intptr_t before)
@@ -82,6 +135,7 @@ void comboboxInsertBefore(id c, BOOL editable, id str, intptr_t before)
 
 	ac = boundListboxArray(c, comboboxBinding);
 	listboxArrayInsertBefore(ac, toListboxItem(comboboxKey, str), before);
+	comboboxFixSpecial(c);
 }
 
 intptr_t comboboxSelectedIndex(id c)
@@ -90,12 +144,44 @@ intptr_t comboboxSelectedIndex(id c)
 	return fromNSInteger([toNSPopUpButton(c) indexOfSelectedItem]);
 }
 
+void comboboxSelect(id c, BOOL editable, intptr_t index)
+{
+	NSComboBox *cb;
+
+	if (!editable) {
+		id ac;
+
+		// the NSPopUpButton follows the selection of the NSArrayController it's bound to (see makeCombobox() above), so change that instead
+		ac = boundListboxArray(c, comboboxBinding);
+		if (index == -1)
+			[toNSArrayController(ac) setSelectionIndexes:[NSIndexSet indexSet]];
+		else
+			[toNSArrayController(ac) setSelectionIndex:toNSUInteger(index)];
+		return;
+	}
+
+	cb = toNSComboBox(c);
+	if (index == -1) {
+		NSInteger selected;
+
+		selected = [cb indexOfSelectedItem];
+		if (selected != -1)
+			[cb deselectItemAtIndex:selected];
+		[cb setStringValue:@""];
+		return;
+	}
+	[cb selectItemAtIndex:toNSInteger(index)];
+	// selecting an item doesn't necessarily change the text in the entry field, so do that too
+	[cb setObjectValue:[cb objectValueOfSelectedItem]];
+}
+
 void comboboxDelete(id c, intptr_t index)
 {
 	id ac;
 
 	ac = boundListboxArray(c, comboboxBinding);
 	listboxArrayDelete(ac, index);
+	comboboxFixSpecial(c);
 }
 
 intptr_t comboboxLen(id c)
@@ -103,3 +189,13 @@ intptr_t comboboxLen(id c)
 	// both satisfy the selector
 	return fromNSInteger([toNSPopUpButton(c) numberOfItems]);
 }
+
+// for BindSetting(); both send their action when the user picks an item, and an NSComboBox tells its delegate when the user types (see delegateuitask_darwin.m)
+void comboboxWatchChanges(id c, id delegate, BOOL editable)
+{
+	// both satisfy the selectors
+	[toNSPopUpButton(c) setTarget:delegate];
+	[toNSPopUpButton(c) setAction:@selector(buttonClicked:)];
+	if (editable)
+		[toNSComboBox(c) setDelegate:delegate];
+}
@@ -0,0 +1,75 @@
+// +build !windows,!darwin,!plan9
+
+// 10 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+/*
+GtkComboBoxText's model has an id column next to the text column, so we mark separators and headers there ("separator" and "header"; ordinary items have no id).
+gtk_combo_box_set_row_separator_func() draws the separators and keeps them from being selected; headers get a cell data function that makes them insensitive, which GtkComboBox also won't select.
+*/
+
+// #include "gtk_unix.h"
+// static gboolean gtkComboBoxIsSeparator(GtkTreeModel *model, GtkTreeIter *iter, gpointer data)
+// {
+// 	gchar *id;
+// 	gboolean ret;
+//
+// 	gtk_tree_model_get(model, iter, gtk_combo_box_get_id_column((GtkComboBox *) data), &id, -1);
+// 	ret = g_strcmp0(id, "separator") == 0;
+// 	g_free(id);
+// 	return ret;
+// }
+// static void gtkComboBoxHeaderCellData(GtkCellLayout *layout, GtkCellRenderer *cell, GtkTreeModel *model, GtkTreeIter *iter, gpointer data)
+// {
+// 	gchar *id;
+//
+// 	gtk_tree_model_get(model, iter, gtk_combo_box_get_id_column((GtkComboBox *) data), &id, -1);
+// 	g_object_set(cell, "sensitive", g_strcmp0(id, "header") != 0, NULL);
+// 	g_free(id);
+// }
+// static inline void gtkComboBoxSetSpecialFuncs(GtkWidget *w)
+// {
+// 	GList *renderer;
+//
+// 	gtk_combo_box_set_row_separator_func((GtkComboBox *) w, gtkComboBoxIsSeparator, w, NULL);
+// 	/* as in gtkSetComboBoxArbitrarilyResizeable(), the only cell renderer is the GtkCellRendererText */
+// 	renderer = gtk_cell_layout_get_cells((GtkCellLayout *) w);
+// 	gtk_cell_layout_set_cell_data_func((GtkCellLayout *) w, renderer->data, gtkComboBoxHeaderCellData, w, NULL);
+// 	g_list_free(renderer);
+// }
+import "C"
+
+var comboboxKindIDs = map[comboboxKind]string{
+	comboboxSeparator: "separator",
+	comboboxHeader:    "header",
+}
+
+// called by gtk_combo_box_text_new(); editable Comboboxes can't have separators or headers
+func gtkComboBoxInitSpecial(widget *C.GtkWidget) {
+	C.gtkComboBoxSetSpecialFuncs(widget)
+}
+
+func (s *sysData) comboboxAppendSpecial(what string, kind comboboxKind) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		cid := C.CString(comboboxKindIDs[kind])
+		defer C.free(unsafe.Pointer(cid))
+		ctext := C.CString(what)
+		defer C.free(unsafe.Pointer(ctext))
+		C.gtk_combo_box_text_append(togtkcombobox(s.widget), togstr(cid), togstr(ctext))
+		s.invalidatePreferredSize()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// the GtkEntry of an editable Combobox; see sysData.settingConnect()
+func gtkComboBoxEntry(widget *C.GtkWidget) *C.GtkWidget {
+	return C.gtk_bin_get_child((*C.GtkBin)(unsafe.Pointer(widget)))
+}
@@ -0,0 +1,94 @@
+// 10 july 2014
+
+package ui
+
+/*
+The standard combo box has no separators or headers, and drawing them ourselves would mean an owner-drawn combo box (CBS_OWNERDRAWFIXED | CBS_HASSTRINGS) that draws every item in WM_DRAWITEM.
+Instead, a separator is an item whose text is a line of box-drawing characters and a header is an item with its text, and each is marked with its kind in the item data (CB_SETITEMDATA), which moves with the item as others are inserted and deleted.
+When the user selects one of them, we move the selection on to the next ordinary item in the direction the selection was going, so the arrow keys skip them as they do on the other systems.
+*/
+
+const comboboxSeparatorText = "────────────"
+
+func (s *sysData) comboboxAppendSpecial(what string, kind comboboxKind) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		if kind == comboboxSeparator {
+			what = comboboxSeparatorText
+		}
+		pwhat := toUTF16(what)
+		r1, _, err := _sendMessage.Call(
+			uintptr(s.hwnd),
+			uintptr(_CB_ADDSTRING),
+			uintptr(_WPARAM(0)),
+			utf16ToLPARAM(pwhat))
+		if r1 == negConst(_CB_ERRSPACE) {
+			panic(s.newError("adding separator or header to combobox (out of space)", "SendMessage(CB_ADDSTRING)", err))
+		} else if r1 == negConst(_CB_ERR) {
+			panic(s.newError("adding separator or header to combobox", "SendMessage(CB_ADDSTRING)", err))
+		}
+		r1, _, err = _sendMessage.Call(
+			uintptr(s.hwnd),
+			uintptr(_CB_SETITEMDATA),
+			r1, // the index of the new item
+			uintptr(kind))
+		if r1 == negConst(_CB_ERR) {
+			panic(s.newError("marking combobox separator or header", "SendMessage(CB_SETITEMDATA)", err))
+		}
+		s.invalidatePreferredSize()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// runs on the UI thread
+func (s *sysData) comboboxKind(index int) comboboxKind {
+	r1, _, _ := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_CB_GETITEMDATA),
+		uintptr(_WPARAM(index)),
+		uintptr(0))
+	if r1 == negConst(_CB_ERR) {
+		return comboboxItem
+	}
+	return comboboxKind(r1)
+}
+
+// called by stdWndProc() on the UI thread when the user changes the selection of a Combobox
+func (s *sysData) comboboxSelChanged() {
+	sel := s.doSelectedIndex()
+	if sel == -1 || s.comboboxKind(sel) == comboboxItem {
+		s.comboboxSel = sel
+		return
+	}
+	n, _, _ := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_CB_GETCOUNT),
+		uintptr(0),
+		uintptr(0))
+	step := 1
+	if sel < s.comboboxSel {
+		step = -1
+	}
+	next := -1
+	// try the direction the selection was going first, then the other
+	for _, dir := range []int{step, -step} {
+		for i := sel + dir; i >= 0 && i < int(n); i += dir {
+			if s.comboboxKind(i) == comboboxItem {
+				next = i
+				break
+			}
+		}
+		if next != -1 {
+			break
+		}
+	}
+	// CB_SETCURSEL doesn't send CBN_SELCHANGE, so this doesn't come back to us
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_CB_SETCURSEL),
+		uintptr(_WPARAM(next)),
+		uintptr(0))
+	s.comboboxSel = next
+}
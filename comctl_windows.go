@@ -20,6 +20,16 @@ var (
 	_createActCtx   = kernel32.NewProc("CreateActCtxW")
 )
 
+// these are only available once comctl32 is loaded; see initCommonControls()
+var (
+	_setWindowSubclass    *syscall.LazyProc
+	_removeWindowSubclass *syscall.LazyProc
+	_defSubclassProc      *syscall.LazyProc
+	_imageListCreate      *syscall.LazyProc // for Toolbar; see toolbar_windows.go
+	_imageListAdd         *syscall.LazyProc
+	_imageListDestroy     *syscall.LazyProc
+)
+
 /*
 Windows requires a manifest file to enable Common Controls version 6.
 The only way to not require an external manifest is to synthesize the manifest ourselves.
@@ -85,20 +95,32 @@ func initCommonControls() (err error) {
 	}
 
 	icc.dwSize = uint32(unsafe.Sizeof(icc))
-	icc.dwICC = _ICC_PROGRESS_CLASS
+	icc.dwICC = _ICC_PROGRESS_CLASS | _ICC_TAB_CLASSES | _ICC_TREEVIEW_CLASSES | _ICC_BAR_CLASSES | _ICC_DATE_CLASSES
 
 	comctl32 = syscall.NewLazyDLL("comctl32.dll")
 	r1, _, err := comctl32.NewProc("InitCommonControlsEx").Call(uintptr(unsafe.Pointer(&icc)))
 	if r1 == _FALSE { // failure
 		return fmt.Errorf("error initializing Common Controls (comctl32.dll); Windows last error: %v", err)
 	}
+	_setWindowSubclass = comctl32.NewProc("SetWindowSubclass")
+	_removeWindowSubclass = comctl32.NewProc("RemoveWindowSubclass")
+	_defSubclassProc = comctl32.NewProc("DefSubclassProc")
+	_imageListCreate = comctl32.NewProc("ImageList_Create")
+	_imageListAdd = comctl32.NewProc("ImageList_Add")
+	_imageListDestroy = comctl32.NewProc("ImageList_Destroy")
 	return nil
 }
 
 // Common Controls class names.
 const (
 	// x (lowercase) prefix to avoid being caught by the constants generator
-	x_PROGRESS_CLASS = "msctls_progress32"
+	x_PROGRESS_CLASS     = "msctls_progress32"
+	x_WC_TABCONTROL      = "SysTabControl32"
+	x_WC_TREEVIEW        = "SysTreeView32"
+	x_TRACKBAR_CLASS     = "msctls_trackbar32"
+	x_DATETIMEPICK_CLASS = "SysDateTimePick32"
+	x_TOOLBARCLASSNAME   = "ToolbarWindow32"
+	x_STATUSCLASSNAME    = "msctls_statusbar32"
 )
 
 var manifest = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
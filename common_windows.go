@@ -13,6 +13,8 @@ var (
 	gdi32    = syscall.NewLazyDLL("gdi32.dll")
 	comctl32 *syscall.LazyDLL // comctl32 not defined here; see comctl_windows.go
 	msimg32  = syscall.NewLazyDLL("msimg32.dll")
+	comdlg32 = syscall.NewLazyDLL("comdlg32.dll")
+	oleaut32 = syscall.NewLazyDLL("oleaut32.dll")
 )
 
 type _HANDLE uintptr
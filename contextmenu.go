@@ -0,0 +1,46 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+)
+
+// SetContextMenu gives c a context menu, m, which opens where the user right-clicks c (or Control-clicks it, on Mac OS X), or where c is when the user presses the Menu key (Shift+F10 on Windows and GTK+) while c has the keyboard focus; pass nil to take it away.
+// The MenuItems of m work as they do in a menu bar, signaling Clicked when picked; m.SetOpening() works too, so the MenuItems can be fitted to c every time the menu opens.
+// A Menu that is replaced or taken away is destroyed, as with Window.SetMenuBar.
+// The context menu replaces the one c has of its own, if any (LineEdits, LogViews, and editable Comboboxes have one with Cut, Copy, and Paste); NewEditMenu only works in a menu bar, so don't put one in m.
+// SetContextMenu can be called before or after the Window that contains c has been created.
+// It panics if m is already used elsewhere, or if c is not one control of its own (Stack, Grid, Space, and RadioButtons are made of others; give a context menu to those instead).
+func SetContextMenu(c Control, m *Menu) {
+	s := controlSysData(c)
+	if s == nil {
+		panic(fmt.Errorf("%T passed to SetContextMenu() has no control of its own to give a context menu", c))
+	}
+	if m != nil {
+		m.lock.Lock()
+		if m.used {
+			m.lock.Unlock()
+			panic("Menu passed to SetContextMenu() is already used")
+		}
+		m.used = true
+		m.created = true
+		m.lock.Unlock()
+		markCreated(m.items)
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		old := s.contextMenu
+		s.contextMenu = m
+		if m != nil {
+			m.create(false)
+		}
+		s.contextMenuInit() // see contextmenu_*.go; if c isn't made yet, sysData.make() does this instead
+		if old != nil {
+			old.destroy()
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+}
@@ -0,0 +1,21 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// see contextmenu_darwin.m
+
+// runs on the UI thread; unlike on the other systems, this is done every time the context menu changes, not just once
+func (s *sysData) contextMenuInit() {
+	var menu C.id
+
+	if !s.made {
+		return
+	}
+	if s.contextMenu != nil {
+		menu = s.contextMenu.id
+	}
+	C.controlSetContextMenu(s.id, menu)
+}
@@ -0,0 +1,24 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSView.h>
+#import <AppKit/NSScrollView.h>
+#import <AppKit/NSMenu.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSView(x) to(NSView, (x))
+#define toNSMenu(x) to(NSMenu, (x))
+
+/*
+For SetContextMenu(), NSView already shows its menu on a right-click or Control-click, so all we do is set it.
+Controls in a scroll view (Listboxes, LogViews, Areas, and so on) are the scroll view; the view inside it gets the click, so it gets the menu too (NSTextView would show its own otherwise).
+A LineEdit shows the menu of its field editor instead while it's being edited; that's shared by the whole window, so it stays as it is.
+*/
+
+// menu can be nil, for none
+void controlSetContextMenu(id control, id menu)
+{
+	[toNSView(control) setMenu:toNSMenu(menu)];
+	if ([toNSView(control) isKindOfClass:[NSScrollView class]])
+		[[to(NSScrollView, control) documentView] setMenu:toNSMenu(menu)];
+}
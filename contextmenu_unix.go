@@ -0,0 +1,67 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+/*
+For SetContextMenu(), we connect to "button-press-event" for right-clicks and "popup-menu" for Shift+F10 and the Menu key, on the widget that gets them (the one inside the scrolled window, for controls that have one); both stop there when we show the menu, so GtkEntry and GtkTextView don't show their own.
+The handlers are only connected the first time a control gets a context menu; after that, they just let the event go on if the context menu has been taken away.
+The menu is popped up at the mouse pointer either way; GTK+ doesn't have a position for the keyboard without a positioning function of our own.
+Widgets without a window of their own, like GtkLabel, never see mouse clicks, so their context menus only open from the keyboard, if they can have the focus at all.
+*/
+
+// #include "gtk_unix.h"
+// extern gboolean our_context_menu_button_press_callback(GtkWidget *, GdkEvent *, gpointer);
+// extern gboolean our_context_menu_popup_menu_callback(GtkWidget *, gpointer);
+// static inline void gtkContextMenuConnect(GtkWidget *widget, gpointer data)
+// {
+// 	gtk_widget_add_events(widget, GDK_BUTTON_PRESS_MASK);
+// 	g_signal_connect(widget, "button-press-event", G_CALLBACK(our_context_menu_button_press_callback), data);
+// 	g_signal_connect(widget, "popup-menu", G_CALLBACK(our_context_menu_popup_menu_callback), data);
+// }
+// static inline void gtkContextMenuPopup(GtkWidget *menu, guint button, guint32 time)
+// {
+// 	gtk_menu_popup(GTK_MENU(menu), NULL, NULL, NULL, NULL, button, time);
+// }
+import "C"
+
+// runs on the UI thread
+func (s *sysData) contextMenuInit() {
+	if !s.made || s.contextMenuConnected {
+		return
+	}
+	widget := s.widget
+	if ct := classTypes[s.ctype]; ct.child != nil {
+		widget = ct.child(s.widget)
+	}
+	C.gtkContextMenuConnect(widget, C.gpointer(unsafe.Pointer(s)))
+	s.contextMenuConnected = true
+}
+
+//export our_context_menu_button_press_callback
+func our_context_menu_button_press_callback(widget *C.GtkWidget, event *C.GdkEvent, data C.gpointer) C.gboolean {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(data))
+	e := (*C.GdkEventButton)(unsafe.Pointer(event))
+	if s.contextMenu == nil || e._type != C.GDK_BUTTON_PRESS || e.button != 3 {
+		return C.FALSE // continue the event chain
+	}
+	C.gtkContextMenuPopup(s.contextMenu.widget, C.guint(e.button), C.guint32(e.time))
+	return C.TRUE
+}
+
+//export our_context_menu_popup_menu_callback
+func our_context_menu_popup_menu_callback(widget *C.GtkWidget, data C.gpointer) C.gboolean {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(data))
+	if s.contextMenu == nil {
+		return C.FALSE
+	}
+	C.gtkContextMenuPopup(s.contextMenu.widget, 0, C.gtk_get_current_event_time())
+	return C.TRUE
+}
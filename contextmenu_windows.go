@@ -0,0 +1,113 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+/*
+For SetContextMenu(), the control is subclassed (like for LineEdit history; see history_windows.go) so we see WM_CONTEXTMENU before it does; otherwise edit controls would show their own menu, and most other controls would pass the message on to the Window, which wouldn't know which control it was for.
+DefWindowProc() sends WM_CONTEXTMENU on a right-click and on Shift+F10 and the Menu key; for the keys, there are no coordinates, so the menu opens at the top-left corner of the control.
+Areas handle the mouse and keyboard themselves and never call DefWindowProc(), so for them the menu opens after the Area gets the WM_RBUTTONUP, and the keys only go to the Area.
+The menu is owned by the Window, which gets WM_INITMENUPOPUP and WM_COMMAND for it just as it does for its menu bar (see stdWndProc()).
+The subclass stays once added, even if the context menu is taken away again; the sysData is the reference data of the subclass.
+*/
+
+var contextMenuSubclassCallback uintptr
+
+func init() {
+	// not in the var declaration above, for the same reason as listboxSubclassCallback
+	contextMenuSubclassCallback = syscall.NewCallback(contextMenuSubclassProc)
+}
+
+var (
+	_trackPopupMenu = user32.NewProc("TrackPopupMenu")
+	_getAncestor    = user32.NewProc("GetAncestor")
+	_clientToScreen = user32.NewProc("ClientToScreen")
+)
+
+// runs on the UI thread
+func (s *sysData) contextMenuInit() {
+	if !s.made || s.contextMenuHooked {
+		return
+	}
+	r1, _, err := _setWindowSubclass.Call(
+		uintptr(s.hwnd),
+		contextMenuSubclassCallback,
+		uintptr(0),
+		uintptr(unsafe.Pointer(s)))
+	if r1 == 0 { // failure
+		panic(s.newError("subclassing control for context menu", "SetWindowSubclass()", err))
+	}
+	s.contextMenuHooked = true
+}
+
+func contextMenuSubclassProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM, id uintptr, data uintptr) _LRESULT {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(data))
+	switch uMsg {
+	case _WM_CONTEXTMENU:
+		if s.contextMenu == nil {
+			break
+		}
+		if lParam == _LPARAM(negConst(-1)) { // from the keyboard
+			var r _RECT
+
+			_getWindowRect.Call(
+				uintptr(hwnd),
+				uintptr(unsafe.Pointer(&r)))
+			s.popupContextMenu(r.left, r.top)
+			return 0
+		}
+		s.popupContextMenu(lParam.X(), lParam.Y())
+		return 0
+	case _WM_RBUTTONUP:
+		if s.contextMenu == nil || s.ctype != c_area {
+			break
+		}
+		r1, _, _ := _defSubclassProc.Call(
+			uintptr(hwnd),
+			uintptr(uMsg),
+			uintptr(wParam),
+			uintptr(lParam))
+		pt := _POINT{
+			x: lParam.X(),
+			y: lParam.Y(),
+		}
+		_clientToScreen.Call(
+			uintptr(hwnd),
+			uintptr(unsafe.Pointer(&pt)))
+		s.popupContextMenu(pt.x, pt.y)
+		return _LRESULT(r1)
+	case _WM_NCDESTROY:
+		// see gridNavSubclassProc()
+		_removeWindowSubclass.Call(
+			uintptr(hwnd),
+			contextMenuSubclassCallback,
+			id)
+	}
+	r1, _, _ := _defSubclassProc.Call(
+		uintptr(hwnd),
+		uintptr(uMsg),
+		uintptr(wParam),
+		uintptr(lParam))
+	return _LRESULT(r1)
+}
+
+// runs on the UI thread; x and y are in screen coordinates
+func (s *sysData) popupContextMenu(x int32, y int32) {
+	owner, _, _ := _getAncestor.Call(
+		uintptr(s.hwnd),
+		uintptr(_GA_ROOT))
+	// this returns once the menu is closed, after the Window gets WM_COMMAND if the user picked something; if it fails, the user just doesn't get a menu
+	_trackPopupMenu.Call(
+		uintptr(s.contextMenu.hmenu),
+		uintptr(_TPM_RIGHTBUTTON),
+		uintptr(x),
+		uintptr(y),
+		uintptr(0),
+		owner,
+		uintptr(0))
+}
@@ -7,3 +7,46 @@ type Control interface {
 	make(window *sysData) error
 	controlSizing
 }
+
+// controlSysData returns the sysData of a Control that wraps a native control, or nil for one that doesn't (Stack, Grid, Space, RadioButtons, whose buttons each wrap one); a Tab wraps one for its tabs, even though it also holds other Controls.
+func controlSysData(c Control) *sysData {
+	switch c := c.(type) {
+	case *Button:
+		return c.sysData
+	case *Checkbox:
+		return c.sysData
+	case *Combobox:
+		return c.sysData
+	case *LineEdit:
+		return c.sysData
+	case *Label:
+		return c.sysData
+	case *Listbox:
+		return c.sysData
+	case *ProgressBar:
+		return c.sysData
+	case *Area:
+		return c.sysData
+	case *LogView:
+		return c.sysData
+	case *LevelMeter:
+		return c.area.sysData
+	case *Tab:
+		return c.sysData
+	case *TreeView:
+		return c.sysData
+	case *Slider:
+		return c.sysData
+	case *radioButton:
+		return c.sysData
+	case *GroupBox:
+		return c.sysData
+	case *DateTimePicker:
+		return c.sysData
+	case *ColorButton:
+		return c.sysData
+	case *FontButton:
+		return c.sysData
+	}
+	return nil
+}
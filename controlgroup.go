@@ -0,0 +1,193 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"sync"
+	"time"
+)
+
+// A ControlGroup is a set of Controls that can be disabled, hidden, or cleared all at once, such as the controls of a form while it is being submitted.
+// A Stack, Grid, or Tab in a ControlGroup stands for all the Controls in it (on all the pages of a Tab), so a whole form can be one entry.
+// A Control can be in more than one ControlGroup; whichever call was made last decides whether it is disabled or hidden.
+// The methods of ControlGroup can be called from any goroutine, before or after the Windows containing its Controls are created (but, as with the rest of package ui, only once Go() has been called), as long as each Stack, Grid, and Tab is done being built when it is added.
+type ControlGroup struct {
+	lock     sync.Mutex
+	controls []Control
+}
+
+// NewControlGroup creates a new ControlGroup with the given Controls in it.
+func NewControlGroup(controls ...Control) *ControlGroup {
+	return &ControlGroup{
+		controls: controls,
+	}
+}
+
+// Add adds the given Controls to the ControlGroup.
+func (g *ControlGroup) Add(controls ...Control) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.controls = append(g.controls, controls...)
+}
+
+// DisableAll disables the Controls in the ControlGroup: they are dimmed and ignore the mouse and keyboard until EnableAll is called.
+// Not every control has a disabled state on every system; on Mac OS X, a LogView, ProgressBar, Area, or Tab stays as it is.
+// Controls disabled this way stay disabled when a Window stops being busy; see Window.SetBusy.
+func (g *ControlGroup) DisableAll() {
+	g.each(func(s *sysData) {
+		s.disabled = true
+		if s.made {
+			s.doSetEnabled()
+		}
+	})
+}
+
+// EnableAll undoes DisableAll. The Controls of a busy Window stay disabled until it is no longer busy.
+func (g *ControlGroup) EnableAll() {
+	g.each(func(s *sysData) {
+		s.disabled = false
+		if s.made {
+			s.doSetEnabled()
+		}
+	})
+}
+
+// HideAll hides the Controls in the ControlGroup until ShowAll is called.
+// Hidden controls keep their place in the layout, so they leave a blank space behind; put the Controls you want to hide at the end of a Stack if that matters.
+// A Control hidden this way on a page of a Tab stays hidden when its page is shown.
+func (g *ControlGroup) HideAll() {
+	g.each(func(s *sysData) {
+		s.hidden = true
+		if s.made {
+			s.updateShown()
+		}
+	})
+}
+
+// ShowAll undoes HideAll. Controls on pages of a Tab that aren't shown stay hidden until their page is.
+func (g *ControlGroup) ShowAll() {
+	g.each(func(s *sysData) {
+		s.hidden = false
+		if s.made {
+			s.updateShown()
+		}
+	})
+}
+
+// ResetAll clears the values of the Controls in the ControlGroup, as if the user were starting the form over: LineEdits are emptied, Checkboxes are unchecked, nothing is selected in Comboboxes, Listboxes, and TreeViews (which also clears what is typed in an editable Combobox), Sliders go back to their minimum, RadioButtons go back to their first button, and DateTimePickers go to the current date and time.
+// Other Controls, including those that show things rather than ask for them (such as Labels, LogViews, and ProgressBars), are left as they are.
+// The Controls don't send any events for this, as with their own setters.
+func (g *ControlGroup) ResetAll() {
+	var controls []Control
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	// the layouts belong to the UI thread once they're created, so we find the Controls there; but their setters send their own work to the UI thread, so we have to call those from here
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		for _, c := range g.controls {
+			eachControl(c, func(c Control) {
+				controls = append(controls, c)
+			})
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+	for _, c := range controls {
+		switch c := c.(type) {
+		case *LineEdit:
+			c.SetText("")
+		case *Checkbox:
+			c.SetChecked(false)
+		case *Combobox:
+			c.SetSelected(-1)
+		case *Listbox:
+			c.ClearSelection()
+		case *TreeView:
+			c.Select(nil)
+		case *Slider:
+			c.SetValue(c.min) // never changes, so no need for the lock
+		case *RadioButtons:
+			c.SetSelected(0)
+		case *DateTimePicker:
+			c.SetTime(time.Now())
+		}
+	}
+}
+
+// calls f on the UI thread with the sysData of each system control in the ControlGroup
+func (g *ControlGroup) each(f func(s *sysData)) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		for _, c := range g.controls {
+			eachControl(c, func(c Control) {
+				if s := controlSysData(c); s != nil {
+					f(s)
+				}
+			})
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// eachControl() calls f with every Control in c that isn't a Stack, Grid, or Space; for a Tab, that's the Tab itself and the Controls on all its pages, for RadioButtons, it's the RadioButtons and each of its buttons, and for a GroupBox, it's the GroupBox and the Controls in it.
+func eachControl(c Control, f func(c Control)) {
+	switch c := c.(type) {
+	case *Stack:
+		for _, cc := range c.controls {
+			eachControl(cc, f)
+		}
+	case *Grid:
+		for _, row := range c.controls {
+			for _, cc := range row {
+				eachControl(cc, f)
+			}
+		}
+	case *Tab:
+		f(c)
+		for _, p := range c.pages {
+			eachControl(p, f)
+		}
+	case *RadioButtons:
+		f(c)
+		for _, b := range c.buttons {
+			f(b)
+		}
+	case *GroupBox:
+		f(c)
+		eachControl(c.control, f)
+	default:
+		f(c)
+	}
+}
+
+// controlMade() is called by each sysData.make() on the UI thread once a control exists, to do what a ControlGroup did to the control before that.
+func (s *sysData) controlMade(window *sysData) {
+	s.window = window
+	s.made = true
+	if s.disabled {
+		s.doSetEnabled()
+	}
+	if s.hidden {
+		s.updateShown()
+	}
+	if s.contextMenu != nil {
+		s.contextMenuInit() // see SetContextMenu()
+	}
+}
+
+// a control is shown unless a ControlGroup hid it or it's on a page of a Tab that isn't shown; this runs on the UI thread
+func (s *sysData) updateShown() {
+	if s.destroyed { // a Tab showing the page a destroyed control was on; see DestroyControl()
+		return
+	}
+	s.doShowControl(!s.hidden && !s.offPage)
+}
@@ -2,6 +2,10 @@
 
 package ui
 
+import (
+	"time"
+)
+
 type allocation struct {
 	x		int
 	y		int
@@ -16,6 +20,7 @@ type cSysSizeData struct {
 	ymargin		int
 	xpadding		int
 	ypadding		int
+	checkPath		string		// for the layout checks in uidebug builds; see layoutcheck.go
 }
 
 // for verification; see sysdata.go
@@ -23,13 +28,27 @@ type sysDataSizingFunctions interface {
 	beginResize() *sysSizeData
 	endResize(*sysSizeData)
 	translateAllocationCoords([]*allocation, int, int)
-	preferredSize(*sysSizeData) (int, int)
+	sysPreferredSize(*sysSizeData) (int, int)
 	commitResize(*allocation, *sysSizeData)
 	getAuxResizeInfo(*sysSizeData)
 }
 
 func (s *sysData) resizeWindow(width, height int) {
+	checkUIThread("sysData.resizeWindow()")
+	s.layoutWidth = width
+	s.layoutHeight = height
+	if s.frozen {
+		// the layout will be redone all at once in sysData.thaw(); see Window.Freeze()
+		return
+	}
+	if !laidOut {
+		laidOut = true
+		defer setStartupTime(&startup.t.FirstLayout, time.Now())
+	}
 	d := s.beginResize()
+	if uidebug {
+		d.checkPath = "Window"
+	}
 	allocations := s.allocate(0, 0, width, height, d)
 	s.translateAllocationCoords(allocations, width, height)
 	// move in reverse so as to approximate right->left order so neighbors make sense
@@ -39,10 +58,145 @@ func (s *sysData) resizeWindow(width, height int) {
 	s.endResize(d)
 }
 
-// non-layout controls: allocate() should just return a one-element slice; preferredSize(), commitResize(), and getAuxResizeInfo() should defer to their sysData equivalents
+// Laying out a whole Window for a change to one part of it is wasteful, so containers that can change on their own (Stack.SetOrientation(), a Tab switching pages) remember where they were last laid out and lay out only themselves again when they can.
+// They can only if their preferred size didn't change: otherwise their parent might give them (and their neighbors) different space, and the parent's own preferred size might change, and so on up to the Window.
+type layoutRect struct {
+	x, y, width, height int
+	xmargin, ymargin    int  // the margins it was given, which it only has if it's the Window's control
+	ok                  bool // false until it has been laid out
+}
+
+// called by each container's allocate() before it does anything else
+func (r *layoutRect) set(x int, y int, width int, height int, d *sysSizeData) {
+	*r = layoutRect{
+		x:       x,
+		y:       y,
+		width:   width,
+		height:  height,
+		xmargin: d.xmargin,
+		ymargin: d.ymargin,
+		ok:      true,
+	}
+}
+
+// runs on the UI thread; lays out c, which is somewhere in this Window, in r again without touching anything else, as resizeWindow() would
+func (s *sysData) relayoutPart(c Control, r *layoutRect) {
+	checkUIThread("sysData.relayoutPart()")
+	if s.frozen {
+		return
+	}
+	if !r.ok { // we don't know where c goes, so the whole Window it is
+		s.doRelayout()
+		return
+	}
+	d := s.beginResize()
+	d.xmargin = r.xmargin
+	d.ymargin = r.ymargin
+	if uidebug {
+		d.checkPath = "Window > ..."
+	}
+	allocations := c.allocate(r.x, r.y, r.width, r.height, d)
+	s.translateAllocationCoords(allocations, s.layoutWidth, s.layoutHeight)
+	for i := len(allocations) - 1; i >= 0; i-- {
+		allocations[i].this.commitResize(allocations[i], d)
+	}
+	s.endResize(d)
+}
+
+// for Window.Relayout(); lays out the window's controls again at its current size, as if it had just been resized (so not at all if it's frozen)
+func (s *sysData) relayout() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.doRelayout()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// Asking the system for a control's preferred size can be slow, and the layout code asks for every control every time the window is resized, so we cache the answer.
+// Everything that can change a control's preferred size (setText(), append(), etc.) calls invalidatePreferredSize() on the UI thread once it's done; as preferredSize() is also only called on the UI thread, we don't need to lock anything.
+// On Windows the preferred size also depends on the window's font metrics (in sysSizeData); we don't have a way to change the font yet, so those are fixed for now.
+func (s *sysData) preferredSize(d *sysSizeData) (width int, height int) {
+	checkUIThread("sysData.preferredSize()")
+	if s.destroyed { // see DestroyControl()
+		return 0, 0
+	}
+	if !s.prefsizeok {
+		s.prefwidth, s.prefheight = s.sysPreferredSize(d)
+		s.prefsizeok = true
+	}
+	return s.prefwidth, s.prefheight
+}
+
+func (s *cSysData) invalidatePreferredSize() {
+	checkUIThread("sysData.invalidatePreferredSize()")
+	s.prefsizeok = false
+}
+
+// LineEdit.SetWidthInChars() and Label.SetWidthFromText() give us the width to use instead of the one the system would pick; each sysPreferredSize() turns these into pixels, as what gets measured (and the border around it) depends on the system.
+// Zero and "" mean no hint.
+func (s *cSysData) setWidthHint(chars int, text string) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.widthChars = chars
+		s.widthText = text
+		s.invalidatePreferredSize()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// non-layout controls: allocate() should just return sysData.singleAllocation(); preferredSize(), commitResize(), and getAuxResizeInfo() should defer to their sysData equivalents
 type controlSizing interface {
 	allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation
 	preferredSize(d *sysSizeData) (width, height int)
 	commitResize(c *allocation, d *sysSizeData)
 	getAuxResizeInfo(d *sysSizeData)
 }
+
+// Stack and Grid use these so that a Window too small for its controls cuts them off instead of giving them negative sizes or placing them outside the Window.
+// layoutInset() insets a position and size by margin on both sides, but not past the middle if size is smaller than both margins together.
+func layoutInset(pos int, size int, margin int) (int, int) {
+	if size < 0 {
+		size = 0
+	}
+	if size < 2*margin {
+		margin = size / 2
+	}
+	return pos + margin, size - 2*margin
+}
+
+// layoutClip() cuts off what is at pos with the given size at end; something that starts past end is moved to end with no size at all.
+func layoutClip(pos int, size int, end int) (int, int) {
+	if pos > end {
+		pos = end
+	}
+	if pos+size > end {
+		size = end - pos
+	}
+	if size < 0 {
+		size = 0
+	}
+	return pos, size
+}
+
+// This is what non-layout controls return from allocate().
+// The allocation and the slice holding it are kept in the sysData and reused on every resize, so continuously resizing a window doesn't produce garbage.
+// This is safe because allocations only live until the end of resizeWindow().
+// A control destroyed by DestroyControl() has nothing left to move, so it gets no allocation.
+func (s *sysData) singleAllocation(x int, y int, width int, height int, this Control) []*allocation {
+	if s.destroyed {
+		return nil
+	}
+	s.alloc = allocation{
+		x:       x,
+		y:       y,
+		width:   width,
+		height:  height,
+		this:		this,
+	}
+	s.allocs[0] = &s.alloc
+	return s.allocs[:]
+}
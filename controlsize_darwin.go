@@ -2,6 +2,10 @@
 
 package ui
 
+import (
+	"strings"
+)
+
 // #include "objc_darwin.h"
 import "C"
 
@@ -44,6 +48,8 @@ func (s *sysData) translateAllocationCoords(allocations []*allocation, winwidth,
 		// winheight - y because (0,0) is the bottom-left corner of the window and not the top-left corner
 		// (winheight - y) - height because (x, y) is the bottom-left corner of the control and not the top-left
 		a.y = (winheight - a.y) - a.height
+		// and the status bar, if any, is below all that
+		a.y += s.statusBarHeight()
 	}
 }
 
@@ -108,17 +114,41 @@ func areaPrefSize(control C.id) (width int, height int) {
 	return int(r.width), int(r.height)
 }
 
+// and LogViews don't have one at all; see prefsize_darwin.m
+func logviewPrefSize(control C.id) (width int, height int) {
+	r := C.logviewPrefSize(control)
+	return int(r.width), int(r.height)
+}
+
 var prefsizefuncs = [nctypes]func(C.id) (int, int){
-	c_button:      controlPrefSize,
-	c_checkbox:    controlPrefSize,
-	c_combobox:    controlPrefSize,
-	c_lineedit:    controlPrefSize,
-	c_label:       controlPrefSize,
-	c_listbox:     listboxPrefSize,
-	c_progressbar: pbarPrefSize,
-	c_area:        areaPrefSize,
+	c_button:         controlPrefSize,
+	c_checkbox:       controlPrefSize,
+	c_combobox:       controlPrefSize,
+	c_lineedit:       controlPrefSize,
+	c_label:          controlPrefSize,
+	c_listbox:        listboxPrefSize,
+	c_progressbar:    pbarPrefSize,
+	c_area:           areaPrefSize,
+	c_logview:        logviewPrefSize,
+	c_tab:            tabPrefSize,
+	c_treeview:       listboxPrefSize, // NSOutlineView is an NSTableView
+	c_slider:         sliderPrefSize,
+	c_radiobutton:    controlPrefSize,
+	c_groupbox:       groupBoxPrefSize,
+	c_datetimepicker: controlPrefSize,
+	c_colorbutton:    colorButtonPrefSize,
+	c_fontbutton:     controlPrefSize,
 }
 
-func (s *sysData) preferredSize(d *sysSizeData) (width int, height int) {
-	return prefsizefuncs[s.ctype](s.id)
+func (s *sysData) sysPreferredSize(d *sysSizeData) (width int, height int) {
+	width, height = prefsizefuncs[s.ctype](s.id)
+	// see sysData.setWidthHint()
+	if s.widthChars != 0 {
+		// the system font's digits are all the same width, which is close enough to the average character width
+		width = int(C.textFieldWidthForText(s.id, toNSString(strings.Repeat("0", s.widthChars))))
+	}
+	if s.widthText != "" {
+		width = int(C.textFieldWidthForText(s.id, toNSString(s.widthText)))
+	}
+	return width, height
 }
@@ -55,7 +55,7 @@ func (s *sysData) commitResize(c *allocation, d *sysSizeData) {
 }
 
 func (s *sysData) getAuxResizeInfo(d *sysSizeData) {
-	d.shouldVAlignTop = (s.ctype == c_listbox) || (s.ctype == c_area)
+	d.shouldVAlignTop = (s.ctype == c_listbox) || (s.ctype == c_area) || (s.ctype == c_logview) || (s.ctype == c_treeview)
 }
 
 // GTK+ 3 makes this easy: controls can tell us what their preferred size is!
@@ -68,11 +68,22 @@ func (s *sysData) getAuxResizeInfo(d *sysSizeData) {
 
 // We don't need to worry about y-offset because label alignment is "vertically center", which GtkLabel does for us.
 
-func (s *sysData) preferredSize(d *sysSizeData) (width int, height int) {
+func (s *sysData) sysPreferredSize(d *sysSizeData) (width int, height int) {
 	if s.ctype == c_area {
 		return s.areawidth, s.areaheight
 	}
 
 	_, _, width, height = gtk_widget_get_preferred_size(s.widget)
+	// see sysData.setWidthHint()
+	if s.widthChars != 0 {
+		// we set width-chars to 0 in gtk_entry_new(), so the natural width is just the border around the text; this is what GtkEntry itself would do for a nonzero width-chars
+		width += s.widthChars * gtkCharWidth(s.widget)
+	}
+	if s.widthText != "" {
+		width = gtkTextWidth(s.widget, s.widthText)
+	}
+	if s.ctype == c_slider {
+		width, height = s.sliderPreferredSize(width, height)
+	}
 	return width, height
 }
@@ -4,6 +4,7 @@ package ui
 
 import (
 	"fmt"
+	"syscall"
 	"unsafe"
 )
 
@@ -26,19 +27,7 @@ const (
 func (s *sysData) beginResize() (d *sysSizeData) {
 	d = new(sysSizeData)
 
-	dc := getTextDC(s.hwnd)
-	defer releaseTextDC(s.hwnd, dc)
-
-	var tm _TEXTMETRICS
-
-	r1, _, err := _getTextMetrics.Call(
-		uintptr(dc),
-		uintptr(unsafe.Pointer(&tm)))
-	if r1 == 0 { // failure
-		panic(fmt.Errorf("error getting text metrics for preferred size calculations: %v", err))
-	}
-	d.baseX = int(tm.tmAveCharWidth) // TODO not optimal; third reference has better way
-	d.baseY = int(tm.tmHeight)
+	d.baseX, d.baseY = dialogBaseUnits(s.hwnd)
 
 	if s.spaced {
 		d.xmargin = muldiv(marginDialogUnits, d.baseX, 4)
@@ -50,12 +39,36 @@ func (s *sysData) beginResize() (d *sysSizeData) {
 	return d
 }
 
+// the base units that dialog units are in terms of, for the control font in the given window
+// besides the layout, this is used by things we size ourselves, such as banners
+func dialogBaseUnits(hwnd _HWND) (baseX int, baseY int) {
+	var tm _TEXTMETRICS
+
+	dc := getTextDC(hwnd)
+	defer releaseTextDC(hwnd, dc)
+
+	r1, _, err := _getTextMetrics.Call(
+		uintptr(dc),
+		uintptr(unsafe.Pointer(&tm)))
+	if r1 == 0 { // failure
+		panic(newError(nil, "getting text metrics for preferred size calculations", "GetTextMetrics()", err))
+	}
+	baseX = int(tm.tmAveCharWidth) // TODO not optimal; third reference has better way
+	baseY = int(tm.tmHeight)
+	return baseX, baseY
+}
+
 func (s *sysData) endResize(d *sysSizeData) {
 	// redraw
 }
 
 func (s *sysData) translateAllocationCoords(allocations []*allocation, winwidth, winheight int) {
-	// no translation needed on windows
+	// no translation needed on windows, except to move everything below the toolbar, if any
+	if top := s.toolbarHeight(); top != 0 {
+		for _, a := range allocations {
+			a.y += top
+		}
+	}
 }
 
 func (s *sysData) commitResize(c *allocation, d *sysSizeData) {
@@ -68,7 +81,10 @@ func (s *sysData) commitResize(c *allocation, d *sysSizeData) {
 	}
 	c.y += yoff
 	// TODO move this here
-	s.setRect(c.x, c.y, c.width, c.height, 0)
+	err := s.setRect(c.x, c.y, c.width, c.height, 0)
+	if err != nil { // not fatal; the control just stays where it was until the next resize
+		reportError(err.(*Error))
+	}
 }
 
 func (s *sysData) getAuxResizeInfo(d *sysSizeData) {
@@ -141,6 +157,47 @@ var stdDlgSizes = [nctypes]dlgunits{
 	c_area: dlgunits{
 		area: true,
 	},
+	c_logview: dlgunits{
+		// there's nothing about read-only multi-line edit controls, so use the same size as a Listbox
+		longest: true,
+		height:  14 + 10 + 10,
+	},
+	c_treeview: dlgunits{
+		// nor about tree views
+		longest: true,
+		height:  14 + 10 + 10,
+	},
+	c_radiobutton: dlgunits{
+		// same as checkboxes
+		longest: true,
+		height:  10,
+	},
+	c_groupbox: dlgunits{
+		// group boxes are sized by their title; see sysPreferredSize()
+	},
+	c_datetimepicker: dlgunits{
+		// or date and time pickers; DTM_GETIDEALSIZE needs Windows Vista, so fall back to the size of a combobox, which is what they look like
+		width:   defaultWidth,
+		height:  14,
+		getsize: _DTM_GETIDEALSIZE,
+	},
+	c_colorbutton: dlgunits{
+		// a push button with no text, so the size of one with text; see colorbutton_windows.go
+		width:  50,
+		height: 14,
+	},
+	c_fontbutton: dlgunits{
+		// a push button
+		width:   50,
+		height:  14,
+		getsize: _BCM_GETIDEALSIZE,
+	},
+	c_slider: dlgunits{
+		// or trackbars; this fits one without tick marks
+		// this is for horizontal ones; see sysPreferredSize() for vertical ones
+		width:  defaultWidth,
+		height: 15,
+	},
 }
 
 var (
@@ -154,14 +211,14 @@ var (
 func getTextDC(hwnd _HWND) (dc _HANDLE) {
 	r1, _, err := _getDC.Call(uintptr(hwnd))
 	if r1 == 0 { // failure
-		panic(fmt.Errorf("error getting DC for preferred size calculations: %v", err))
+		panic(newError(nil, "getting DC for preferred size calculations", "GetDC()", err))
 	}
 	dc = _HANDLE(r1)
 	r1, _, err = _selectObject.Call(
 		uintptr(dc),
 		uintptr(controlFont))
 	if r1 == 0 { // failure
-		panic(fmt.Errorf("error loading control font into device context for preferred size calculation: %v", err))
+		panic(newError(nil, "loading control font into device context for preferred size calculation", "SelectObject()", err))
 	}
 	return dc
 }
@@ -171,16 +228,30 @@ func releaseTextDC(hwnd _HWND, dc _HANDLE) {
 		uintptr(hwnd),
 		uintptr(dc))
 	if r1 == 0 { // failure
-		panic(fmt.Errorf("error releasing DC for preferred size calculations: %v", err))
+		panic(newError(nil, "releasing DC for preferred size calculations", "ReleaseDC()", err))
 	}
 }
 
 // This function runs on uitask; call the functions directly.
-func (s *sysData) preferredSize(d *sysSizeData) (width int, height int) {
+func (s *sysData) sysPreferredSize(d *sysSizeData) (width int, height int) {
 	// the preferred size of an Area is its size
 	if stdDlgSizes[s.ctype].area {
 		return s.areawidth, s.areaheight
 	}
+	// and a Tab measures its tabs; see tab_windows.go
+	if s.ctype == c_tab {
+		return s.tabPreferredSize()
+	}
+	// as does a GroupBox with its title; see groupbox_windows.go
+	if s.ctype == c_groupbox {
+		return s.groupBoxPreferredSize(d)
+	}
+	// a vertical Slider is a horizontal one on its side
+	if s.ctype == c_slider && s.alternate {
+		width = muldiv(stdDlgSizes[s.ctype].height, d.baseY, 8)
+		height = muldiv(stdDlgSizes[s.ctype].width, d.baseX, 4)
+		return width, height
+	}
 
 	if msg := stdDlgSizes[s.ctype].getsize; msg != 0 {
 		var size _SIZE
@@ -205,9 +276,47 @@ func (s *sysData) preferredSize(d *sysSizeData) (width int, height int) {
 	width = muldiv(width, d.baseX, 4)   // equivalent to right of rect
 	height = muldiv(height, d.baseY, 8) // equivalent to bottom of rect
 
+	// see sysData.setWidthHint()
+	if s.widthChars != 0 {
+		// baseX is the average character width already
+		width = s.widthChars*d.baseX + s.editBorderWidth()
+	}
+	if s.widthText != "" {
+		width = s.textWidth(s.widthText)
+	}
+
 	return width, height
 }
 
+// the width of the text s would show in the control font
+func (s *sysData) textWidth(text string) int {
+	var size _SIZE
+
+	dc := getTextDC(s.hwnd)
+	defer releaseTextDC(s.hwnd, dc)
+	t := syscall.StringToUTF16(text)
+	r1, _, err := _getTextExtentPoint32.Call(
+		uintptr(dc),
+		uintptr(unsafe.Pointer(&t[0])),
+		uintptr(len(t)-1), // without the terminating NUL
+		uintptr(unsafe.Pointer(&size)))
+	if r1 == 0 { // failure
+		panic(s.newError(fmt.Sprintf("measuring text %q for preferred size calculations", text), "GetTextExtentPoint32()", err))
+	}
+	return int(size.cx)
+}
+
+// the space an edit control takes up around its text: the margins inside it and the WS_EX_CLIENTEDGE border
+func (s *sysData) editBorderWidth() int {
+	margins, _, _ := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_EM_GETMARGINS),
+		uintptr(0),
+		uintptr(0))
+	edge, _, _ := _getSystemMetrics.Call(uintptr(_SM_CXEDGE))
+	return int(margins&0xFFFF) + int(margins>>16&0xFFFF) + 2*int(edge)
+}
+
 var (
 	_mulDiv = kernel32.NewProc("MulDiv")
 )
@@ -0,0 +1,87 @@
+// 9 july 2014
+
+package ui
+
+// Credentials are what the user typed into the dialog shown by AskCredentials.
+type Credentials struct {
+	Username string
+	Password string
+	Domain   string // only if the dialog asked for it; see CredentialsDomain
+	Remember bool   // only if the dialog asked for it; see CredentialsRemember
+}
+
+// CredentialsFields says which of the optional fields the dialog shown by AskCredentials has.
+type CredentialsFields uint
+
+const (
+	CredentialsDomain   CredentialsFields = 1 << iota // a field for the domain (such as a Windows domain or a Kerberos realm), after the password
+	CredentialsRemember                               // a "Remember me" checkbox
+)
+
+// AskCredentials shows a dialog asking for a username and password, and returns what the user typed and true if they clicked OK, or false if they clicked Cancel or closed the dialog.
+// message is shown at the top of the dialog, such as "Enter your password for example.com"; title is the title of the dialog.
+// initial fills in the fields to begin with (for instance, the username the user gave last time); fields says which of the optional fields to show.
+// The password is typed into a password field (see NewPasswordEdit), and the OK and Cancel buttons are in the order the system puts them in.
+//
+// If parent is not nil, it is made busy (see Window.SetBusy) while the dialog is up, so the user can't use it in the meantime; if parent was busy already, it's left that way.
+// AskCredentials waits for the user to answer; like everything else in package ui, it must not be called on the UI thread.
+func AskCredentials(parent *Window, title string, message string, initial Credentials, fields CredentialsFields) (c Credentials, ok bool) {
+	username := NewLineEdit(initial.Username)
+	password := NewPasswordEdit()
+	password.SetText(initial.Password)
+	domain := NewLineEdit(initial.Domain)
+	remember := NewCheckbox("Remember me")
+	remember.SetChecked(initial.Remember)
+	okButton := NewButton("OK")
+	cancelButton := NewButton("Cancel")
+
+	label := func(text string, buddy Control) *Label {
+		l := NewLabel(text)
+		l.SetBuddy(buddy)
+		return l
+	}
+	grid := []Control{
+		label("Username:", username), username,
+		label("Password:", password), password,
+	}
+	if fields&CredentialsDomain != 0 {
+		grid = append(grid, label("Domain:", domain), domain)
+	}
+	g := NewGrid(2, grid...)
+	g.SetStretchy(0, 1)
+	for row := 1; row < len(grid)/2; row++ {
+		g.SetFilling(row, 1)
+	}
+	controls := []Control{NewStandaloneLabel(message), g}
+	if fields&CredentialsRemember != 0 {
+		controls = append(controls, remember)
+	}
+	controls = append(controls, dialogButtons(okButton, cancelButton))
+	s := NewVerticalStack(controls...)
+
+	w := NewWindow(title, 360, 100+30*len(grid)/2)
+	w.SetSpaced(true)
+	w.Create(s)
+	w.Center()
+	w.Show()
+	defer w.Destroy()
+
+	defer dialogParentBusy(parent)()
+
+	select {
+	case <-okButton.Clicked:
+	case <-cancelButton.Clicked:
+		return Credentials{}, false
+	case <-w.Closing:
+		return Credentials{}, false
+	}
+	c.Username = username.Text()
+	c.Password = password.Text()
+	if fields&CredentialsDomain != 0 {
+		c.Domain = domain.Text()
+	}
+	if fields&CredentialsRemember != 0 {
+		c.Remember = remember.Checked()
+	}
+	return c, true
+}
@@ -0,0 +1,144 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A DateTimePicker is a Control that lets the user pick a date, or a date and a time of day, without typing it in a format the program has to parse.
+// How it looks depends on the system: Windows and Mac OS X show the date (and time) as text whose parts the user can change one at a time, and Windows can also drop down a calendar; GTK+ shows a calendar, with the time of day below it.
+// The date and time are in the local time zone, to the second.
+// Newly-created DateTimePickers start at the time they were created with NewDatePicker or NewDateTimePicker.
+type DateTimePicker struct {
+	// Changed is signaled when the user picks another date or time.
+	// It is not signaled for SetTime.
+	Changed chan struct{}
+
+	lock     sync.Mutex
+	created  bool
+	sysData  *sysData
+	initTime time.Time
+}
+
+func newDateTimePicker(withTime bool) *DateTimePicker {
+	p := &DateTimePicker{
+		Changed: newEvent(),
+		sysData: mksysdata(c_datetimepicker),
+	}
+	p.sysData.alternate = withTime
+	p.initTime = p.sysData.dateTimePickerNormalize(time.Now())
+	return p
+}
+
+// NewDatePicker creates a new DateTimePicker that only picks a date; its Time is always midnight of that date.
+func NewDatePicker() *DateTimePicker {
+	return newDateTimePicker(false)
+}
+
+// NewDateTimePicker creates a new DateTimePicker that picks both a date and a time of day.
+func NewDateTimePicker() *DateTimePicker {
+	return newDateTimePicker(true)
+}
+
+// Time returns the date and time the DateTimePicker is at, in the local time zone.
+func (p *DateTimePicker) Time() time.Time {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.created {
+		return p.initTime
+	}
+	ret := make(chan time.Time)
+	defer close(ret)
+	uitask(func() {
+		ret <- p.sysData.dateTimePickerNormalize(p.sysData.dateTimePickerTime())
+	})
+	return <-ret
+}
+
+// SetTime moves the DateTimePicker to t, in the local time zone; fractions of a second are dropped, as is the time of day if the DateTimePicker only picks a date.
+// It panics if the year of t is before 1601 or after 9999, which is all Windows can show.
+func (p *DateTimePicker) SetTime(t time.Time) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	t = p.sysData.dateTimePickerNormalize(t)
+	if t.Year() < 1601 || t.Year() > 9999 {
+		panic(fmt.Errorf("time %v out of range in DateTimePicker.SetTime()", t))
+	}
+	if !p.created {
+		p.initTime = t
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		p.sysData.pickerTime = t // so the system telling us it changed doesn't signal Changed; see sysData.dateTimeChanged()
+		p.sysData.dateTimePickerSetTime(t)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (p *DateTimePicker) make(window *sysData) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.sysData.control = p
+	p.sysData.event = p.Changed
+	err := p.sysData.make(window)
+	if err != nil {
+		return err
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		p.sysData.pickerTime = p.initTime
+		p.sysData.dateTimePickerSetTime(p.initTime)
+		ret <- struct{}{}
+	})
+	<-ret
+	p.created = true
+	return nil
+}
+
+// what a DateTimePicker holds: local time, whole seconds, and midnight for date-only ones
+func (s *sysData) dateTimePickerNormalize(t time.Time) time.Time {
+	t = t.Local()
+	year, month, day := t.Date()
+	if !s.alternate {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	}
+	hour, minute, second := t.Clock()
+	return time.Date(year, month, day, hour, minute, second, 0, time.Local)
+}
+
+// called by the platform code on the UI thread when the user changes a DateTimePicker
+// some systems also tell us about our own changes, or tell us about each part of one change, so only actual changes are signaled
+func (s *sysData) dateTimeChanged() {
+	t := s.dateTimePickerNormalize(s.dateTimePickerTime())
+	if t.Equal(s.pickerTime) {
+		return
+	}
+	s.pickerTime = t
+	s.signal()
+}
+
+func (p *DateTimePicker) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	return p.sysData.singleAllocation(x, y, width, height, p)
+}
+
+func (p *DateTimePicker) preferredSize(d *sysSizeData) (width int, height int) {
+	return p.sysData.preferredSize(d)
+}
+
+func (p *DateTimePicker) commitResize(a *allocation, d *sysSizeData) {
+	p.sysData.commitResize(a, d)
+}
+
+func (p *DateTimePicker) getAuxResizeInfo(d *sysSizeData) {
+	p.sysData.getAuxResizeInfo(d)
+}
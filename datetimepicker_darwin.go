@@ -0,0 +1,31 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"time"
+)
+
+// #include "objc_darwin.h"
+import "C"
+
+/*
+On Mac OS X, a DateTimePicker is an NSDatePicker with a text field and a stepper, showing the date (and the time of day) the way the user's locale does.
+The app delegate is its target, so it's told when the user changes it; see appDelegate_dateTimePickerChanged(). NSDatePicker doesn't tell it about setDateValue:.
+NSDate is a moment in time, not a date in a time zone, so we pass it as seconds since the Unix epoch and let NSDatePicker show it in its time zone, which is the system's, as time.Local usually is; a DateTimePicker that only picks a date keeps the time of day it was given (midnight, from DateTimePicker.SetTime()), and the date is all that's read back (see sysData.dateTimePickerNormalize()).
+*/
+
+func makeDateTimePicker(parentWindow C.id, alternate bool, s *sysData) C.id {
+	picker := C.makeDateTimePicker(toBOOL(alternate), appDelegate)
+	applyStandardControlFont(picker)
+	addControl(parentWindow, picker)
+	return picker
+}
+
+func (s *sysData) dateTimePickerSetTime(t time.Time) {
+	C.dateTimePickerSetTime(s.id, C.int64_t(t.Unix()))
+}
+
+func (s *sysData) dateTimePickerTime() time.Time {
+	return time.Unix(int64(C.dateTimePickerTime(s.id)), 0)
+}
@@ -0,0 +1,38 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSDatePicker.h>
+#import <Foundation/NSDate.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSDatePicker(x) to(NSDatePicker, (x))
+
+extern NSRect dummyRect;
+
+id makeDateTimePicker(BOOL withTime, id delegate)
+{
+	NSDatePicker *dp;
+	NSDatePickerElementFlags elements;
+
+	dp = [[NSDatePicker alloc]
+		initWithFrame:dummyRect];
+	[dp setDatePickerStyle:NSTextFieldAndStepperDatePickerStyle];
+	elements = NSYearMonthDayDatePickerElementFlag;
+	if (withTime)
+		elements |= NSHourMinuteSecondDatePickerElementFlag;
+	[dp setDatePickerElements:elements];
+	[dp setTarget:delegate];
+	[dp setAction:@selector(dateTimePickerChanged:)];
+	return dp;
+}
+
+// in seconds since the Unix epoch; see datetimepicker_darwin.go
+void dateTimePickerSetTime(id dp, int64_t t)
+{
+	[toNSDatePicker(dp) setDateValue:[NSDate dateWithTimeIntervalSince1970:((NSTimeInterval) t)]];
+}
+
+int64_t dateTimePickerTime(id dp)
+{
+	return (int64_t) [[toNSDatePicker(dp) dateValue] timeIntervalSince1970];
+}
@@ -0,0 +1,146 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern void our_datetimepicker_changed_callback(GtkWidget *, gpointer);
+// extern gboolean our_datetimepicker_output_callback(GtkSpinButton *, gpointer);
+import "C"
+
+/*
+GTK+ has no date picker of its own, only GtkCalendar, which shows a whole month at a time; so on GTK+, a DateTimePicker is a vertical GtkBox with a GtkCalendar and, if it also picks the time of day, a row of GtkSpinButtons below it for the hour (0 to 23), minute, and second.
+GtkCalendar sends "day-selected" (and "month-changed") for each part of gtk_calendar_select_month() and gtk_calendar_select_day(), including ours, and GtkSpinButton sends "value-changed" for gtk_spin_button_set_value(); sysData.dtpSetting keeps ours from being passed on at all, as the dates in between would look like changes to sysData.dateTimeChanged().
+*/
+
+// the children of the GtkBox of a DateTimePicker
+const (
+	dtpCalendar = 0
+	dtpTime     = 1 // a GtkBox of the hour, ":", the minute, ":", and the second
+)
+
+func gtkDatePickerNew() *C.GtkWidget {
+	box := C.gtk_box_new(C.GTK_ORIENTATION_VERTICAL, 6)
+	C.gtk_box_pack_start((*C.GtkBox)(unsafe.Pointer(box)), C.gtk_calendar_new(), C.TRUE, C.TRUE, 0)
+	return box
+}
+
+func gtkDateTimePickerNew() *C.GtkWidget {
+	box := gtkDatePickerNew()
+	tod := C.gtk_box_new(C.GTK_ORIENTATION_HORIZONTAL, 0)
+	for i, max := range []C.gdouble{23, 59, 59} {
+		if i != 0 {
+			colon := C.CString(":")
+			C.gtk_box_pack_start((*C.GtkBox)(unsafe.Pointer(tod)), C.gtk_label_new(togstr(colon)), C.FALSE, C.FALSE, 0)
+			C.free(unsafe.Pointer(colon))
+		}
+		sb := C.gtk_spin_button_new_with_range(0, max, 1)
+		C.gtk_spin_button_set_wrap(togtkspinbutton(sb), C.TRUE)
+		C.gtk_entry_set_width_chars((*C.GtkEntry)(unsafe.Pointer(sb)), 2)
+		g_signal_connect(sb, "output", datetimepicker_output_callback, nil)
+		C.gtk_box_pack_start((*C.GtkBox)(unsafe.Pointer(tod)), sb, C.FALSE, C.FALSE, 0)
+	}
+	C.gtk_widget_set_halign(tod, C.GTK_ALIGN_CENTER)
+	C.gtk_box_pack_start((*C.GtkBox)(unsafe.Pointer(box)), tod, C.FALSE, C.FALSE, 0)
+	return box
+}
+
+// the child of the classData for DateTimePickers; this is also what the buddy of a Label focuses (see sysData.setBuddy())
+func gDateTimePickerCalendar(widget *C.GtkWidget) *C.GtkWidget {
+	return gtkNthChild(widget, dtpCalendar)
+}
+
+func gtkNthChild(box *C.GtkWidget, n int) *C.GtkWidget {
+	children := C.gtk_container_get_children((*C.GtkContainer)(unsafe.Pointer(box)))
+	defer C.g_list_free(children)
+	return (*C.GtkWidget)(unsafe.Pointer(C.g_list_nth_data(children, C.guint(n))))
+}
+
+func togtkcalendar(what *C.GtkWidget) *C.GtkCalendar {
+	return (*C.GtkCalendar)(unsafe.Pointer(what))
+}
+
+func togtkspinbutton(what *C.GtkWidget) *C.GtkSpinButton {
+	return (*C.GtkSpinButton)(unsafe.Pointer(what))
+}
+
+// the GtkSpinButtons for the hour, minute, and second
+func (s *sysData) dateTimePickerSpinButtons() (spins [3]*C.GtkWidget) {
+	tod := gtkNthChild(s.widget, dtpTime)
+	for i := range spins {
+		spins[i] = gtkNthChild(tod, 2*i)
+	}
+	return spins
+}
+
+// runs on the UI thread; called by sysData.make() for DateTimePickers with the time of day, as the calendar is connected by the classData
+func (s *sysData) dateTimePickerConnect() {
+	for _, sb := range s.dateTimePickerSpinButtons() {
+		g_signal_connect(sb, "value-changed", datetimepicker_changed_callback, s)
+	}
+}
+
+func (s *sysData) dateTimePickerSetTime(t time.Time) {
+	s.dtpSetting = true
+	defer func() {
+		s.dtpSetting = false
+	}()
+	cal := togtkcalendar(gDateTimePickerCalendar(s.widget))
+	// otherwise GtkCalendar would have to fit, say, the 31st into February on the way
+	C.gtk_calendar_select_day(cal, 1)
+	C.gtk_calendar_select_month(cal, C.guint(t.Month()-1), C.guint(t.Year()))
+	C.gtk_calendar_select_day(cal, C.guint(t.Day()))
+	if s.alternate {
+		spins := s.dateTimePickerSpinButtons()
+		C.gtk_spin_button_set_value(togtkspinbutton(spins[0]), C.gdouble(t.Hour()))
+		C.gtk_spin_button_set_value(togtkspinbutton(spins[1]), C.gdouble(t.Minute()))
+		C.gtk_spin_button_set_value(togtkspinbutton(spins[2]), C.gdouble(t.Second()))
+	}
+}
+
+func (s *sysData) dateTimePickerTime() time.Time {
+	var year, month, day C.guint
+	var hour, minute, second int
+
+	cal := togtkcalendar(gDateTimePickerCalendar(s.widget))
+	C.gtk_calendar_get_date(cal, &year, &month, &day)
+	if s.alternate {
+		spins := s.dateTimePickerSpinButtons()
+		hour = int(C.gtk_spin_button_get_value_as_int(togtkspinbutton(spins[0])))
+		minute = int(C.gtk_spin_button_get_value_as_int(togtkspinbutton(spins[1])))
+		second = int(C.gtk_spin_button_get_value_as_int(togtkspinbutton(spins[2])))
+	}
+	return time.Date(int(year), time.Month(month+1), int(day), hour, minute, second, 0, time.Local)
+}
+
+//export our_datetimepicker_changed_callback
+func our_datetimepicker_changed_callback(widget *C.GtkWidget, what C.gpointer) {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(what))
+	if s.dtpSetting {
+		return
+	}
+	s.dateTimeChanged()
+}
+
+var datetimepicker_changed_callback = C.GCallback(C.our_datetimepicker_changed_callback)
+
+// GtkSpinButton shows 5 minutes as "5" otherwise
+
+//export our_datetimepicker_output_callback
+func our_datetimepicker_output_callback(sb *C.GtkSpinButton, what C.gpointer) C.gboolean {
+	defer recoverUIPanic()
+	text := C.CString(fmt.Sprintf("%02d", int(C.gtk_spin_button_get_value_as_int(sb))))
+	defer C.free(unsafe.Pointer(text))
+	C.gtk_entry_set_text((*C.GtkEntry)(unsafe.Pointer(sb)), togstr(text))
+	return C.TRUE
+}
+
+var datetimepicker_output_callback = C.GCallback(C.our_datetimepicker_output_callback)
@@ -0,0 +1,61 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"time"
+	"unsafe"
+)
+
+/*
+On Windows, a DateTimePicker is a date and time picker control, which shows the date as text whose parts the user can change one at a time and can drop down a month calendar.
+It tells the window it's in that it changed with a DTN_DATETIMECHANGE notification; see stdWndProc(). The date and time it holds have no time zone, so we give it local time.
+The control can show a date (DTS_SHORTDATEFORMAT) or a time (DTS_TIMEFORMAT), but not both, unless we give it a format string of our own; for DateTimePickers with the time of day, that's the user's short date format followed by their time format, both from GetLocaleInfo(), which use the same format letters the control does.
+Unlike the built-in formats, ours doesn't follow the user changing their settings while the program runs.
+*/
+
+// runs on the UI thread; called by sysData.make()
+func (s *sysData) dateTimePickerInit() {
+	date := getLocaleInfo(_LOCALE_USER_DEFAULT, _LOCALE_SSHORTDATE, "short date format")
+	tod := getLocaleInfo(_LOCALE_USER_DEFAULT, _LOCALE_STIMEFORMAT, "time format")
+	if date == "" || tod == "" { // already reported; leave the date alone
+		return
+	}
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_DTM_SETFORMAT),
+		uintptr(0),
+		utf16ToArg(toUTF16(date+" "+tod)))
+}
+
+func (s *sysData) dateTimePickerSetTime(t time.Time) {
+	st := _SYSTEMTIME{
+		wYear:   uint16(t.Year()),
+		wMonth:  uint16(t.Month()),
+		wDay:    uint16(t.Day()),
+		wHour:   uint16(t.Hour()),
+		wMinute: uint16(t.Minute()),
+		wSecond: uint16(t.Second()),
+	}
+	r1, _, err := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_DTM_SETSYSTEMTIME),
+		uintptr(_GDT_VALID),
+		uintptr(unsafe.Pointer(&st)))
+	if r1 == 0 { // failure
+		panic(s.newError("setting DateTimePicker time", "DTM_SETSYSTEMTIME", err))
+	}
+}
+
+func (s *sysData) dateTimePickerTime() time.Time {
+	var st _SYSTEMTIME
+
+	// this can only fail with DTS_SHOWNONE, which we don't use
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_DTM_GETSYSTEMTIME),
+		uintptr(0),
+		uintptr(unsafe.Pointer(&st)))
+	return time.Date(int(st.wYear), time.Month(st.wMonth), int(st.wDay),
+		int(st.wHour), int(st.wMinute), int(st.wSecond), 0, time.Local)
+}
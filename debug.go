@@ -0,0 +1,8 @@
+// +build uidebug
+
+// 3 july 2014
+
+package ui
+
+// building with -tags uidebug turns on extra checks that cost too much to have on all the time
+const uidebug = true
@@ -7,7 +7,22 @@ This creates a class goAppDelegate that will be used as the delegate for /everyt
 	- runs uitask requests (uitask:)
 	- handles window close events (windowShouldClose:)
 	- handles window resize events (windowDidResize:)
-	- handles button click events (buttonClicked:)
+	- shows the menu bar of a window when it becomes the key window (windowDidBecomeKey:)
+	- handles clicks on menu items (menuItemClicked:)
+	- lets submenus change before they open (menuNeedsUpdate:)
+	- handles clicks on the buttons of Toolbars (toolbarItemClicked:)
+	- handles button click events, and changes to Checkboxes and Comboboxes bound with BindSetting() (buttonClicked:)
+	- handles listbox selection changes, double-clicks, and scrolling (tableViewSelectionDidChange:, listboxDoubleClicked:, listboxScrolled:)
+	- handles TreeView selection changes (outlineViewSelectionDidChange:)
+	- handles Slider movements (sliderMoved:)
+	- handles clicks on the buttons of RadioButtons (radioClicked:)
+	- handles DateTimePicker changes (dateTimePickerChanged:)
+	- handles ColorButton changes (colorButtonChanged:)
+	- handles clicks on FontButtons (fontButtonClicked:)
+	- handles the user turning Increase Contrast or Reduce motion on or off (highContrastChanged:)
+	- handles clicks on the buttons of Window banners (bannerClicked:)
+	- handles the arrow keys and Enter in LineEdits of Grids with keyboard navigation, Up and Down in LineEdits with a history, and Up, Down, Enter, and Escape in the LineEdit of a command palette (control:textView:doCommandBySelector:)
+	- handles text changes in the LineEdit of a command palette and the LineEdits of a Form, and in LineEdits and editable Comboboxes bound with BindSetting() (controlTextDidChange:)
 	- handles the application-global Quit event (such as from the Dock) (applicationShouldTerminate)
 */
 
@@ -25,30 +40,189 @@ func makeAppDelegate() {
 
 //export appDelegate_windowShouldClose
 func appDelegate_windowShouldClose(win C.id) {
+	defer recoverUIPanic()
 	sysData := getSysData(win)
 	sysData.signal()
 }
 
+//export appDelegate_windowDidBecomeKey
+func appDelegate_windowDidBecomeKey(win C.id) {
+	defer recoverUIPanic()
+	s := getSysData(win)
+	s.showMenuBar()
+	s.windowActivated()
+}
+
+//export appDelegate_menuItemClicked
+func appDelegate_menuItemClicked(tag C.intptr_t) {
+	defer recoverUIPanic()
+	menuItemClicked(uintptr(tag))
+}
+
+//export appDelegate_toolbarItemClicked
+func appDelegate_toolbarItemClicked(win C.id, n C.intptr_t) {
+	defer recoverUIPanic()
+	s := getSysData(win)
+	s.toolbar.items[n].clicked()
+}
+
+//export appDelegate_menuNeedsUpdate
+func appDelegate_menuNeedsUpdate(menu C.uintptr_t) {
+	defer recoverUIPanic()
+	menuOpening(uintptr(menu))
+}
+
 //export appDelegate_windowDidResize
 func appDelegate_windowDidResize(win C.id) {
+	defer recoverUIPanic()
 	s := getSysData(win)
 	wincv := C.windowGetContentView(win) // we want the content view's size, not the window's
 	r := C.frame(wincv)
 	// (0,0) is the bottom left corner but this is handled in sysData.translateAllocationCoords()
-	s.resizeWindow(int(r.width), int(r.height))
+	s.resizeWindow(int(r.width), int(r.height)-s.statusBarHeight())
 	C.display(win) // redraw everything
 }
 
 //export appDelegate_buttonClicked
 func appDelegate_buttonClicked(button C.id) {
+	defer recoverUIPanic()
 	sysData := getSysData(button)
 	sysData.signal()
 }
 
+//export appDelegate_listboxSelectionChanged
+func appDelegate_listboxSelectionChanged(listbox C.id) {
+	defer recoverUIPanic()
+	sysData := getSysData(listbox)
+	sysData.signal()
+}
+
+//export appDelegate_treeViewSelectionChanged
+func appDelegate_treeViewSelectionChanged(treeview C.id) {
+	defer recoverUIPanic()
+	sysData := getSysData(treeview)
+	sysData.signal()
+}
+
+//export appDelegate_sliderMoved
+func appDelegate_sliderMoved(slider C.id) {
+	defer recoverUIPanic()
+	sysData := getSysData(slider)
+	sysData.sliderChanged()
+}
+
+//export appDelegate_radioClicked
+func appDelegate_radioClicked(button C.id) {
+	defer recoverUIPanic()
+	sysData := getSysData(button)
+	sysData.radioSelect() // see radiobuttons_darwin.go
+	sysData.radioClicked()
+}
+
+//export appDelegate_dateTimePickerChanged
+func appDelegate_dateTimePickerChanged(picker C.id) {
+	defer recoverUIPanic()
+	sysData := getSysData(picker)
+	sysData.dateTimeChanged()
+}
+
+//export appDelegate_colorButtonChanged
+func appDelegate_colorButtonChanged(well C.id) {
+	defer recoverUIPanic()
+	sysData := getSysData(well)
+	sysData.signal()
+}
+
+//export appDelegate_fontButtonClicked
+func appDelegate_fontButtonClicked(button C.id) {
+	defer recoverUIPanic()
+	sysData := getSysData(button)
+	sysData.fontButtonClicked()
+}
+
+//export appDelegate_highContrastChanged
+func appDelegate_highContrastChanged() {
+	defer recoverUIPanic()
+	highContrastUpdate()
+	animationsUpdate() // same notification; see animations_darwin.m
+}
+
+//export appDelegate_tabSelected
+func appDelegate_tabSelected(tab C.id, item C.id) {
+	defer recoverUIPanic()
+	sysData := getSysData(tab)
+	sysData.tabSelected(int(C.tabIndexOf(tab, item)))
+}
+
+//export appDelegate_gridNavigate
+func appDelegate_gridNavigate(lineedit C.id, key C.intptr_t, atStart C.BOOL, atEnd C.BOOL) C.BOOL {
+	defer recoverUIPanic()
+	s := getSysData(lineedit)
+	if s.gridNav == nil { // only a history; see appDelegate_historyMove()
+		return C.NO
+	}
+	// key is in the order of the gridNav* constants
+	next := s.gridNav.next(gridNavKey(key), atStart != C.NO, atEnd != C.NO)
+	if next == nil {
+		return C.NO
+	}
+	C.gridNavFocus(next.id)
+	return C.YES
+}
+
+//export appDelegate_historyMove
+func appDelegate_historyMove(lineedit C.id, tv C.id, up C.BOOL) C.BOOL {
+	defer recoverUIPanic()
+	s := getSysData(lineedit)
+	if s.history == nil {
+		return C.NO
+	}
+	if text, ok := s.history.move(up != C.NO, fromNSString(C.historyEditorText(tv))); ok {
+		C.historySetEditorText(tv, toNSString(text))
+	}
+	// the field editor would otherwise move the cursor to the start or end, so eat the key even if there's nothing further in the history
+	return C.YES
+}
+
+//export appDelegate_paletteKey
+func appDelegate_paletteKey(lineedit C.id, key C.intptr_t) C.BOOL {
+	defer recoverUIPanic()
+	s := getSysData(lineedit)
+	if s.palette == nil {
+		return C.NO
+	}
+	// key is in the order of the palette* constants
+	s.paletteKey(paletteKey(key))
+	return C.YES
+}
+
+//export appDelegate_listboxActivated
+func appDelegate_listboxActivated(listbox C.id) {
+	defer recoverUIPanic()
+	sysData := getSysData(listbox)
+	sysData.signalActivated()
+}
+
+//export appDelegate_listboxScrolled
+func appDelegate_listboxScrolled(listbox C.id) {
+	defer recoverUIPanic()
+	sysData := getSysData(listbox)
+	sysData.signalNearEnd(C.listboxNearEnd(listbox) != C.NO, listboxLen(listbox))
+}
+
+//export appDelegate_bannerClicked
+func appDelegate_bannerClicked(win C.id, which C.intptr_t) {
+	defer recoverUIPanic()
+	s := getSysData(win)
+	s.removeBanner(int(which))
+}
+
 //export appDelegate_applicationShouldTerminate
 func appDelegate_applicationShouldTerminate() {
+	defer recoverUIPanic()
 	// asynchronous so as to return control to the event loop
 	go func() {
+		saveAllSettings() // see BindSetting()
 		AppQuit <- struct{}{}
 	}()
 }
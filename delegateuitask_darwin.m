@@ -10,6 +10,10 @@
 #import <Foundation/NSAutoreleasePool.h>
 #import <AppKit/NSEvent.h>
 #import <AppKit/NSAlert.h>
+#import <AppKit/NSTableView.h>
+#import <AppKit/NSScrollView.h>
+#import <AppKit/NSTabView.h>
+#import <AppKit/NSMenu.h>
 
 extern NSRect dummyRect;
 
@@ -56,9 +60,9 @@ extern NSRect dummyRect;
 
 @implementation appDelegate
 
-- (void)uitask:(NSValue *)fp
+- (void)uitask:(id)unused
 {
-	appDelegate_uitask([fp pointerValue]);
+	appDelegate_uitask();
 }
 
 - (BOOL)windowShouldClose:(id)win
@@ -67,6 +71,26 @@ extern NSRect dummyRect;
 	return NO;		// don't close
 }
 
+- (void)windowDidBecomeKey:(NSNotification *)n
+{
+	appDelegate_windowDidBecomeKey([n object]);
+}
+
+- (void)menuItemClicked:(id)item
+{
+	appDelegate_menuItemClicked((intptr_t) [item tag]);
+}
+
+- (void)toolbarItemClicked:(id)button
+{
+	appDelegate_toolbarItemClicked([button window], (intptr_t) [button tag]);
+}
+
+- (void)menuNeedsUpdate:(NSMenu *)menu
+{
+	appDelegate_menuNeedsUpdate((uintptr_t) menu);		// see Menu.key() in menu_darwin.go
+}
+
 - (void)windowDidResize:(NSNotification *)n
 {
 	appDelegate_windowDidResize([n object]);
@@ -77,6 +101,121 @@ extern NSRect dummyRect;
 	appDelegate_buttonClicked(button);
 }
 
+// Listboxes are stored in sysdatas by their scroll views; see listbox_darwin.go
+- (void)tableViewSelectionDidChange:(NSNotification *)n
+{
+	appDelegate_listboxSelectionChanged([[n object] enclosingScrollView]);
+}
+
+- (void)outlineViewSelectionDidChange:(NSNotification *)n
+{
+	appDelegate_treeViewSelectionChanged([[n object] enclosingScrollView]);
+}
+
+- (void)sliderMoved:(id)slider
+{
+	appDelegate_sliderMoved(slider);
+}
+
+- (void)radioClicked:(id)button
+{
+	appDelegate_radioClicked(button);
+}
+
+- (void)dateTimePickerChanged:(id)picker
+{
+	appDelegate_dateTimePickerChanged(picker);
+}
+
+- (void)colorButtonChanged:(id)well
+{
+	appDelegate_colorButtonChanged(well);
+}
+
+- (void)fontButtonClicked:(id)button
+{
+	appDelegate_fontButtonClicked(button);
+}
+
+- (void)highContrastChanged:(NSNotification *)n
+{
+	appDelegate_highContrastChanged();
+}
+
+- (void)tabView:(NSTabView *)tabView didSelectTabViewItem:(NSTabViewItem *)item
+{
+	appDelegate_tabSelected(tabView, item);
+}
+
+- (void)listboxDoubleClicked:(id)listbox
+{
+	// this is also sent for double-clicks on the empty space below the last item
+	if ([((NSTableView *) listbox) clickedRow] < 0)
+		return;
+	appDelegate_listboxActivated([((NSTableView *) listbox) enclosingScrollView]);
+}
+
+// the object is the listbox's clip view, whose superview is the scroll view; see listboxWatchScrolling()
+- (void)listboxScrolled:(NSNotification *)n
+{
+	appDelegate_listboxScrolled([[n object] superview]);
+}
+
+// the banner's buttons are tagged with the index of their action; see banner_darwin.m
+- (void)bannerClicked:(id)button
+{
+	appDelegate_bannerClicked([button window], (intptr_t) [button tag]);
+}
+
+// for Grid.SetKeyboardNavigation(), LineEdit.SetHistory(), and ShowCommandPalette(); we're also the delegate of LineEdits in a Form or bound with BindSetting() and of editable Comboboxes bound with BindSetting(), which have none of these, so everything here has to leave them alone (see lineeditWatchKeys() and comboboxWatchChanges())
+// the history goes first, so it gets Up and Down even in a Grid; a command palette's LineEdit has neither, so it can go first too
+// the field editor turns keys into these commands, so Shift+Left and the like (moveLeftAndModifySelection:) are left alone already
+- (BOOL)control:(NSControl *)control textView:(NSTextView *)tv doCommandBySelector:(SEL)sel
+{
+	intptr_t key;
+	NSRange r;
+	BOOL atStart, atEnd;
+
+	// these are in the order of the palette* constants
+	if (sel == @selector(moveUp:))
+		key = 0;
+	else if (sel == @selector(moveDown:))
+		key = 1;
+	else if (sel == @selector(insertNewline:))
+		key = 2;
+	else if (sel == @selector(cancelOperation:))		// Escape
+		key = 3;
+	else
+		key = -1;
+	if (key != -1 && appDelegate_paletteKey(control, key))
+		return YES;
+	if (sel == @selector(moveUp:) || sel == @selector(moveDown:))
+		if (appDelegate_historyMove(control, tv, sel == @selector(moveUp:)))
+			return YES;
+	if (sel == @selector(moveUp:))
+		key = 0;
+	else if (sel == @selector(moveDown:))
+		key = 1;
+	else if (sel == @selector(moveLeft:))
+		key = 2;
+	else if (sel == @selector(moveRight:))
+		key = 3;
+	else if (sel == @selector(insertNewline:))
+		key = 4;
+	else
+		return NO;		// let the field editor do it
+	r = [tv selectedRange];
+	atStart = r.length == 0 && r.location == 0;
+	atEnd = r.length == 0 && r.location == [[tv string] length];
+	return appDelegate_gridNavigate(control, key, atStart, atEnd);
+}
+
+// for ShowCommandPalette(), Form, and BindSetting(); this is only sent for changes made by the user, not for setStringValue:
+- (void)controlTextDidChange:(NSNotification *)note
+{
+	appDelegate_lineeditChanged([note object]);
+}
+
 - (NSApplicationTerminateReply)applicationShouldTerminate:(NSApplication *)app
 {
 	appDelegate_applicationShouldTerminate();
@@ -117,17 +256,16 @@ BOOL initCocoa(id appDelegate)
 	return YES;
 }
 
-void douitask(id appDelegate, void *p)
+void douitask(id appDelegate)
 {
 	NSAutoreleasePool *pool;
-	NSValue *fp;
 
 	// we need to make an NSAutoreleasePool, otherwise we get leak warnings on stderr
 	pool = [NSAutoreleasePool new];
-	fp = [NSValue valueWithPointer:p];
+	// don't wait: this can be called on the main thread itself (see uitask.go), and we'd deadlock waiting for ourselves
 	[appDelegate performSelectorOnMainThread:@selector(uitask:)
-		withObject:fp
-		waitUntilDone:YES];			// wait so we can properly drain the autorelease pool; on other platforms we wind up waiting anyway (since the main thread can only handle one thing at a time) so
+		withObject:nil
+		waitUntilDone:NO];
 	[pool release];
 }
 
@@ -0,0 +1,134 @@
+// 11 july 2014
+
+package ui
+
+import (
+	"sync"
+)
+
+// DestroyControl destroys c and every Control in it, including those on all the pages of a Tab, freeing everything the system allocated for them, without destroying the Window they are in.
+// Stacks and Grids can't have Controls taken out of them, so c keeps its place in its Stack or Grid, with a preferred size of zero and nothing drawn there; the Window is laid out again right away.
+// The settings of the destroyed Controls that were bound with BindSetting are saved first, and not after that; LevelMeters stop for good, as when their Window is destroyed, and the pages of a Tab that haven't been shown yet are never made.
+// Once DestroyControl returns, neither c nor any Control in it can be used again, except that ControlGroups holding them leave them alone from then on.
+// DestroyControl does nothing if c has no system controls in it, such as a Space.
+// It panics if c is not in a Window that has been created, is on a page of a Tab that hasn't been shown yet, or has already been destroyed (along with its Window or on its own).
+func DestroyControl(c Control) {
+	var window *sysData
+	var found, destroyed bool
+	var tabs []*Tab
+	var meters []*LevelMeter
+
+	controls := make(map[Control]bool)
+	ret := make(chan struct{})
+	defer close(ret)
+	// the layouts belong to the UI thread once they're created; see ControlGroup.ResetAll()
+	uitask(func() {
+		eachControl(c, func(c Control) {
+			controls[c] = true
+			switch c := c.(type) {
+			case *Tab:
+				tabs = append(tabs, c)
+			case *LevelMeter:
+				meters = append(meters, c)
+			}
+			s := controlSysData(c)
+			if s == nil {
+				return
+			}
+			found = true
+			if s.destroyed {
+				destroyed = true
+			}
+			if s.made && window == nil {
+				window = s.window
+			}
+		})
+		if window != nil && window.destroyed {
+			window = nil
+			destroyed = true
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+	if window == nil {
+		switch {
+		case destroyed:
+			panic("attempt to destroy Control that has already been destroyed")
+		case found:
+			panic("attempt to destroy Control before it has been created")
+		}
+		return
+	}
+	// in this order, so no Tab makes another page and no LevelMeter repaints while we destroy them, and the settings can still get their values
+	for _, t := range tabs {
+		t.stop()
+	}
+	for _, m := range meters {
+		m.handler.halt()
+	}
+	for _, s := range window.removeSettings(controls) {
+		s.save(true)
+	}
+	var made []*sysData
+	uitask(func() {
+		made = appendControls(nil, c)
+		for _, s := range made {
+			s.made = false
+			s.destroyed = true
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+	// the controls inside a Tab or GroupBox come after it, so this destroys them first
+	for i := len(made) - 1; i >= 0; i-- {
+		made[i].destroyControl()
+	}
+	uitask(func() {
+		window.doRelayout()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// the Windows that have been created and not yet destroyed, for teardown()
+var liveWindows struct {
+	lock    sync.Mutex
+	windows map[*Window]struct{}
+}
+
+// called by Window.Create()
+func addLiveWindow(w *Window) {
+	liveWindows.lock.Lock()
+	defer liveWindows.lock.Unlock()
+
+	if liveWindows.windows == nil {
+		liveWindows.windows = make(map[*Window]struct{})
+	}
+	liveWindows.windows[w] = struct{}{}
+}
+
+// called by Window.Destroy()
+func removeLiveWindow(w *Window) {
+	liveWindows.lock.Lock()
+	defer liveWindows.lock.Unlock()
+
+	delete(liveWindows.windows, w)
+}
+
+// teardown() is called by Go() once main returns, before it stops the message loop; it destroys the Windows main left behind, as Window.Destroy() would, so their settings are saved, their LevelMeters are stopped, and the system frees them before the program goes on without package ui
+func teardown() {
+	liveWindows.lock.Lock()
+	windows := make([]*Window, 0, len(liveWindows.windows))
+	for w := range liveWindows.windows {
+		windows = append(windows, w)
+	}
+	liveWindows.lock.Unlock()
+	for _, w := range windows {
+		w.lock.Lock()
+		// another goroutine may have destroyed it since
+		if !w.destroyed {
+			w.destroy()
+		}
+		w.lock.Unlock()
+	}
+}
@@ -42,3 +42,35 @@ func (w *Window) MsgBoxError(primaryText string, secondaryText string) (done cha
 	}
 	return w.msgBoxError(primaryText, secondaryText)
 }
+
+// dialogButtons lays out the OK and Cancel buttons of a dialog that package ui builds out of a Window (such as the one AskCredentials shows): on the right, in the system's usual order, followed by any others (such as the Apply button of ShowPreferences)
+func dialogButtons(ok *Button, cancel *Button, others ...*Button) Control {
+	first, second := ok, cancel
+	if dialogCancelFirst {
+		first, second = cancel, ok
+	}
+	controls := []Control{Space(), first, second}
+	for _, b := range others {
+		controls = append(controls, b)
+	}
+	s := NewHorizontalStack(controls...)
+	s.SetStretchy(0)
+	return s
+}
+
+// dialogParentBusy makes parent busy (see Window.SetBusy) while a dialog that package ui builds out of a Window is up, unless parent is nil or was busy already; call the function it returns once the dialog is gone
+func dialogParentBusy(parent *Window) (done func()) {
+	if parent == nil {
+		return func() {}
+	}
+	parent.lock.Lock()
+	wasBusy := parent.busy
+	parent.lock.Unlock()
+	if wasBusy {
+		return func() {}
+	}
+	parent.SetBusy(true)
+	return func() {
+		parent.SetBusy(false)
+	}
+}
@@ -9,8 +9,18 @@ import (
 // #include "objc_darwin.h"
 import "C"
 
+// the Apple Human Interface Guidelines put Cancel before OK, so OK is the rightmost button; see dialogButtons()
+const dialogCancelFirst = true
+
+// they also say preferences take effect right away, with no buttons in the preferences window at all; see ShowPreferences()
+const (
+	preferencesInstantApply = true
+	preferencesCloseButton  = false
+)
+
 //export dialog_send
 func dialog_send(pchan unsafe.Pointer, res C.intptr_t) {
+	defer recoverUIPanic()
 	rchan := (*chan int)(pchan)
 	go func() { // send it in a new goroutine like we do with everything else
 		*rchan <- int(res)
@@ -19,7 +29,7 @@ func dialog_send(pchan unsafe.Pointer, res C.intptr_t) {
 
 func _msgBox(parent *Window, primarytext string, secondarytext string, style uintptr) chan int {
 	ret := make(chan int)
-	uitask <- func() {
+	uitask(func() {
 		var pwin C.id = nil
 
 		if parent != dialogWindow {
@@ -36,7 +46,7 @@ func _msgBox(parent *Window, primarytext string, secondarytext string, style uin
 		case 1: // error
 			C.msgBoxError(pwin, primary, secondary, unsafe.Pointer(&ret))
 		}
-	}
+	})
 	return ret
 }
 
@@ -23,6 +23,15 @@ import (
 // }
 import "C"
 
+// the GNOME Human Interface Guidelines put Cancel before OK, so OK is the rightmost button; see dialogButtons()
+const dialogCancelFirst = true
+
+// they also say preferences take effect right away, so a preferences dialog only needs a Close button; see ShowPreferences()
+const (
+	preferencesInstantApply = true
+	preferencesCloseButton  = true
+)
+
 // dialog performs the bookkeeping involved for having a GtkDialog behave the way we want.
 type dialog struct {
 	parent    *Window
@@ -31,6 +40,9 @@ type dialog struct {
 	prevgroup *C.GtkWindowGroup
 	newgroup  *C.GtkWindowGroup
 	result    chan int
+
+	// if not nil, finish is called on the UI thread with the response before the dialog is destroyed, for dialogs that have more to return than the response (such as the color dialog)
+	finish func(box *C.GtkWidget, res C.gint)
 }
 
 func mkdialog(parent *Window) *dialog {
@@ -66,11 +78,14 @@ func (d *dialog) run(mk func() *C.GtkWidget) {
 		go func() {
 			res := make(chan C.gint)
 			defer close(res)
-			uitask <- func() {
+			uitask(func() {
 				r := C.gtk_dialog_run((*C.GtkDialog)(unsafe.Pointer(box)))
+				if d.finish != nil {
+					d.finish(box, r)
+				}
 				d.cleanup(box)
 				res <- r
-			}
+			})
 			d.send(<-res)
 		}()
 		return
@@ -82,7 +97,11 @@ func (d *dialog) run(mk func() *C.GtkWidget) {
 
 //export our_dialog_response_callback
 func our_dialog_response_callback(box *C.GtkDialog, res C.gint, data C.gpointer) {
+	defer recoverUIPanic()
 	d := (*dialog)(unsafe.Pointer(data))
+	if d.finish != nil {
+		d.finish((*C.GtkWidget)(unsafe.Pointer(box)), res)
+	}
 	d.cleanup((*C.GtkWidget)(unsafe.Pointer(box)))
 	go d.send(res) // send on another goroutine, like everything else
 }
@@ -109,7 +128,7 @@ func (d *dialog) send(res C.gint) {
 func _msgBox(parent *Window, primarytext string, secondarytext string, msgtype C.GtkMessageType, buttons C.GtkButtonsType) (result chan int) {
 	result = make(chan int)
 	d := mkdialog(parent)
-	uitask <- func() {
+	uitask(func() {
 		cprimarytext := C.CString(primarytext)
 		defer C.free(unsafe.Pointer(cprimarytext))
 		csecondarytext := (*C.char)(nil)
@@ -120,7 +139,7 @@ func _msgBox(parent *Window, primarytext string, secondarytext string, msgtype C
 		d.run(func() *C.GtkWidget {
 			return C.gtkNewMsgBox(d.pwin, msgtype, buttons, cprimarytext, csecondarytext)
 		})
-	}
+	})
 	return d.result
 }
 
@@ -11,6 +11,15 @@ var (
 	_messageBox = user32.NewProc("MessageBoxW")
 )
 
+// Windows puts OK before Cancel; see dialogButtons()
+const dialogCancelFirst = false
+
+// preferences take effect when the user clicks OK or Apply, as with property sheets; see ShowPreferences()
+const (
+	preferencesInstantApply = false
+	preferencesCloseButton  = false
+)
+
 func _msgBox(parent *Window, primarytext string, secondarytext string, uType uint32) (result chan int) {
 	// http://msdn.microsoft.com/en-us/library/windows/desktop/aa511267.aspx says "Use task dialogs whenever appropriate to achieve a consistent look and layout. Task dialogs require Windows Vista® or later, so they aren't suitable for earlier versions of Windows. If you must use a message box, separate the main instruction from the supplemental instruction with two line breaks."
 	text := primarytext
@@ -26,26 +35,18 @@ func _msgBox(parent *Window, primarytext string, secondarytext string, uType uin
 	} else {
 		uType |= _MB_TASKMODAL // make modal to every window in the program (they're all windows of the uitask, which is a single thread)
 	}
-	retchan := make(chan int)
-	go func() {
-		ret := make(chan uiret)
-		defer close(ret)
-		uitask <- &uimsg{
-			call: _messageBox,
-			p: []uintptr{
-				uintptr(parenthwnd),
-				utf16ToArg(ptext),
-				utf16ToArg(ptitle),
-				uintptr(uType),
-			},
-			ret: ret,
-		}
-		r := <-ret
-		if r.ret == 0 { // failure
-			panic(fmt.Sprintf("error displaying message box to user: %v\nstyle: 0x%08X\ntitle: %q\ntext:\n%s", r.err, uType, os.Args[0], text))
+	retchan := make(chan int, 1) // buffered so the UI thread doesn't have to wait for someone to receive
+	uitask(func() {
+		r1, _, err := _messageBox.Call(
+			uintptr(parenthwnd),
+			utf16ToArg(ptext),
+			utf16ToArg(ptitle),
+			uintptr(uType))
+		if r1 == 0 { // failure
+			panic(fmt.Sprintf("error displaying message box to user: %v\nstyle: 0x%08X\ntitle: %q\ntext:\n%s", err, uType, os.Args[0], text))
 		}
-		retchan <- int(r.ret)
-	}()
+		retchan <- int(r1)
+	})
 	return retchan
 }
 
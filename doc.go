@@ -72,14 +72,35 @@ The following Controls have scrolling support built in:
 
 	Listbox *
 	Area
+	LogView *
+	TreeView *
 
 All of the above controls have both horizontal and vertical scrollbars.
-These scrollbars hide themselves when not needed.
+These scrollbars hide themselves when not needed, except that on Windows the scrollbars of a LogView are always shown (and disabled when not needed), as with other multi-line text controls there.
 
 [FUTURE DISCUSSIONS: scrolling programmatically, MouseEvent/KeyEvent scroll overrides]
 
 [FUTURE PLAN: Controls that are not marked with a * in the above list can have their scrollbars disabled completely in code.]
 
 The result of resizing the window such that the scrollbars consider themselves too small is implementation-defined.
+
+Bidirectional Text
+
+Strings go into and come out of package ui in logical order: the order they are typed, read, and stored in.
+The systems reorder them for display, so text that mixes right-to-left scripts (such as Hebrew and Arabic) with left-to-right ones shows, and is edited (including where the caret goes when the user presses the arrow keys), the way it is in other programs on the same system; don't reorder or mark up strings yourself.
+Which way a LineEdit, Label, or LogView runs as a whole can be chosen with its SetTextDirection method; see TextDirection.
+
+Goroutines and the UI Thread
+
+Package ui owns one thread, the UI thread: the thread that called Go(). All native windows and controls live on it, and all layout happens on it.
+The exported methods of Window and the Controls that wrap native controls (Button, LineEdit, Listbox, and so on) can be called from any goroutine: each one hands its work to the UI thread, waits for it to finish, and returns the result, so these calls are serialized and see each other's effects in the order they were made.
+Layouts (Stack and Grid) are different: they have no native counterpart and do no marshalling of their own. Build them and call their setters from one goroutine (or synchronize the calls yourself) before creating the Window that contains them; after that, only the UI thread touches them.
+AreaHandler methods and other callbacks run on the UI thread itself, so they must not call package ui functions or methods (see Debugging below).
+
+Debugging
+
+Package ui talks to the system on the goroutine that called Go(), and calling package ui functions or methods on that goroutine (for instance, from an AreaHandler method) would deadlock; package ui panics instead.
+Building with the uidebug build tag (go build -tags uidebug) turns on additional checks of package ui's threading rules (for instance, that layouts are only laid out on the UI thread); these are slow, so only use them while tracking down bugs.
+Such builds also have functions of their own, whose names start with Debug; among them, DebugSetKeyboardChecks reports the controls of each Window that can't be reached or used with the keyboard alone, so a program can be checked for keyboard accessibility before it ships.
 */
 package ui
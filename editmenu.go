@@ -0,0 +1,88 @@
+// 9 july 2014
+
+package ui
+
+// NewEditMenu creates a new MenuItem with the Edit menu every program has: Undo, Redo, Cut, Copy, Paste, and Select All.
+// These work on whichever text control has the keyboard focus in the Window whose menu bar the Edit menu is in (a LineEdit, the text of a Combobox that can be edited, or a LogView), so your program doesn't have to do anything with them.
+// Each one is enabled only when it would do something there: Cut and Copy need text to be selected, Paste needs text on the clipboard and a control that can be changed, and so on.
+// The MenuItems show the usual keyboard shortcuts for each, which the text controls handle themselves.
+//
+// Plain edit controls on Windows can only undo the last change, and undoing it again redoes it, so Redo is always disabled there; GTK+ text controls can't undo at all, so Undo and Redo are always disabled there.
+// On Mac OS X, the shortcuts only work if a menu has them, so every menu bar of a Mac OS X program should have an Edit menu.
+func NewEditMenu() *MenuItem {
+	var items [nEditCommands]*MenuItem
+
+	for c := range items {
+		c := editCommand(c)
+		items[c] = NewMenuItem(editCommandNames[c])
+		items[c].shortcut = editCommandShortcuts[c]
+		items[c].action = func() {
+			if s := items[c].window(); s != nil {
+				s.editCommand(c)
+			}
+		}
+	}
+	menu := NewMenu(
+		items[editUndo],
+		items[editRedo],
+		NewMenuSeparator(),
+		items[editCut],
+		items[editCopy],
+		items[editPaste],
+		NewMenuSeparator(),
+		items[editSelectAll])
+	menu.SetOpening(func() {
+		var f editFocus
+
+		if s := items[0].window(); s != nil {
+			f = s.editFocus()
+		}
+		items[editUndo].SetEnabled(f.canUndo)
+		items[editRedo].SetEnabled(f.canRedo)
+		items[editCut].SetEnabled(f.editable && f.selected)
+		items[editCopy].SetEnabled(f.selected)
+		items[editPaste].SetEnabled(f.editable && clipboardHasText())
+		items[editSelectAll].SetEnabled(f.ok && !f.empty)
+	})
+	return NewSubmenu("Edit", menu)
+}
+
+type editCommand int
+
+const (
+	editUndo editCommand = iota
+	editRedo
+	editCut
+	editCopy
+	editPaste
+	editSelectAll
+	nEditCommands
+)
+
+var editCommandNames = [nEditCommands]string{
+	editUndo:      "Undo",
+	editRedo:      "Redo",
+	editCut:       "Cut",
+	editCopy:      "Copy",
+	editPaste:     "Paste",
+	editSelectAll: "Select All",
+}
+
+var editCommandShortcuts = [nEditCommands]menuShortcut{
+	editUndo:      {'Z', false},
+	editRedo:      redoShortcut, // see editmenu_*.go
+	editCut:       {'X', false},
+	editCopy:      {'C', false},
+	editPaste:     {'V', false},
+	editSelectAll: {'A', false},
+}
+
+// what the text control with the keyboard focus can do; see sysData.editFocus() in editmenu_*.go
+type editFocus struct {
+	ok       bool // false if the focus isn't on a text control; everything else is false too then
+	editable bool
+	selected bool // some text is selected
+	empty    bool
+	canUndo  bool
+	canRedo  bool
+}
@@ -0,0 +1,31 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// see editmenu_darwin.m
+
+var redoShortcut = menuShortcut{'Z', true}
+
+// runs on the UI thread
+func (s *sysData) editFocus() (f editFocus) {
+	var editable, selected, empty, canUndo, canRedo C.BOOL
+
+	if C.editFocus(s.id, &editable, &selected, &empty, &canUndo, &canRedo) == C.NO {
+		return f
+	}
+	f.ok = true
+	f.editable = editable != C.NO
+	f.selected = selected != C.NO
+	f.empty = empty != C.NO
+	f.canUndo = canUndo != C.NO
+	f.canRedo = canRedo != C.NO
+	return f
+}
+
+// runs on the UI thread
+func (s *sysData) editCommand(c editCommand) {
+	C.editCommand(s.id, C.intptr_t(c))
+}
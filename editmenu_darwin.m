@@ -0,0 +1,82 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSWindow.h>
+#import <AppKit/NSText.h>
+#import <AppKit/NSPasteboard.h>
+#import <Foundation/NSUndoManager.h>
+
+#define toNSWindow(x) ((NSWindow *) (x))
+
+/*
+The text controls of package ui are NSTextFields, which edit with the field editor of their window (an NSTextView that becomes the first responder while they have the focus), and NSTextViews (LogView), so the first responder is an NSText whenever one of them has the focus.
+Undo goes through the undo manager of the window, which NSTextView uses unless told not to.
+*/
+
+static NSText *focusedText(id window)
+{
+	id r;
+
+	r = [toNSWindow(window) firstResponder];
+	if (r != nil && [r isKindOfClass:[NSText class]])
+		return (NSText *) r;
+	return nil;
+}
+
+BOOL editFocus(id window, BOOL *editable, BOOL *selected, BOOL *empty, BOOL *canUndo, BOOL *canRedo)
+{
+	NSText *t;
+	NSUndoManager *um;
+
+	t = focusedText(window);
+	if (t == nil)
+		return NO;
+	*editable = [t isEditable];
+	*selected = [t selectedRange].length != 0;
+	*empty = [[t string] length] == 0;
+	um = [t undoManager];
+	if (um != nil && *editable) {
+		*canUndo = [um canUndo];
+		*canRedo = [um canRedo];
+	}
+	return YES;
+}
+
+// the kinds of editCommand in editmenu.go
+enum {
+	editUndo,
+	editRedo,
+	editCut,
+	editCopy,
+	editPaste,
+	editSelectAll,
+};
+
+void editCommand(id window, intptr_t c)
+{
+	NSText *t;
+
+	t = focusedText(window);
+	if (t == nil)
+		return;
+	switch (c) {
+	case editUndo:
+		[[t undoManager] undo];
+		break;
+	case editRedo:
+		[[t undoManager] redo];
+		break;
+	case editCut:
+		[t cut:nil];
+		break;
+	case editCopy:
+		[t copy:nil];
+		break;
+	case editPaste:
+		[t paste:nil];
+		break;
+	case editSelectAll:
+		[t selectAll:nil];
+		break;
+	}
+}
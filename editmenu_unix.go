@@ -0,0 +1,94 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// /* the text controls of package ui are GtkEntries (the entry of a Combobox that can be edited included) and GtkTextViews (LogView) */
+// static inline GtkWidget *gtkFocusedText(GtkWidget *window)
+// {
+// 	GtkWidget *focus;
+//
+// 	focus = gtk_window_get_focus(GTK_WINDOW(window));
+// 	if (focus != NULL && (GTK_IS_ENTRY(focus) || GTK_IS_TEXT_VIEW(focus)))
+// 		return focus;
+// 	return NULL;
+// }
+// static inline gboolean gtkTextEditable(GtkWidget *w)
+// {
+// 	if (GTK_IS_ENTRY(w))
+// 		return gtk_editable_get_editable(GTK_EDITABLE(w));
+// 	return gtk_text_view_get_editable(GTK_TEXT_VIEW(w));
+// }
+// static inline gboolean gtkTextSelected(GtkWidget *w)
+// {
+// 	if (GTK_IS_ENTRY(w))
+// 		return gtk_editable_get_selection_bounds(GTK_EDITABLE(w), NULL, NULL);
+// 	return gtk_text_buffer_get_has_selection(gtk_text_view_get_buffer(GTK_TEXT_VIEW(w)));
+// }
+// static inline gboolean gtkTextEmpty(GtkWidget *w)
+// {
+// 	if (GTK_IS_ENTRY(w))
+// 		return gtk_entry_get_text_length(GTK_ENTRY(w)) == 0;
+// 	return gtk_text_buffer_get_char_count(gtk_text_view_get_buffer(GTK_TEXT_VIEW(w))) == 0;
+// }
+// /* both GtkEntry and GtkTextView have these keybinding signals */
+// static inline void gtkTextEmit(GtkWidget *w, char *signal)
+// {
+// 	g_signal_emit_by_name(w, signal);
+// }
+// static inline void gtkTextSelectAll(GtkWidget *w)
+// {
+// 	if (GTK_IS_ENTRY(w)) {
+// 		gtk_editable_select_region(GTK_EDITABLE(w), 0, -1);
+// 		return;
+// 	}
+// 	g_signal_emit_by_name(w, "select-all", TRUE);
+// }
+import "C"
+
+// see NewEditMenu() for why Undo and Redo are never enabled
+
+var redoShortcut = menuShortcut{'Z', true}
+
+// runs on the UI thread
+func (s *sysData) editFocus() (f editFocus) {
+	w := C.gtkFocusedText(s.widget)
+	if w == nil {
+		return f
+	}
+	f.ok = true
+	f.editable = fromgbool(C.gtkTextEditable(w))
+	f.selected = fromgbool(C.gtkTextSelected(w))
+	f.empty = fromgbool(C.gtkTextEmpty(w))
+	return f
+}
+
+var editCommandSignals = [nEditCommands]string{
+	editCut:   "cut-clipboard",
+	editCopy:  "copy-clipboard",
+	editPaste: "paste-clipboard",
+}
+
+// runs on the UI thread
+func (s *sysData) editCommand(c editCommand) {
+	w := C.gtkFocusedText(s.widget)
+	if w == nil {
+		return
+	}
+	switch c {
+	case editUndo, editRedo:
+		return
+	case editSelectAll:
+		C.gtkTextSelectAll(w)
+		return
+	}
+	signal := C.CString(editCommandSignals[c])
+	defer C.free(unsafe.Pointer(signal))
+	C.gtkTextEmit(w, signal)
+}
@@ -0,0 +1,104 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// the text controls of package ui are all edit controls (the edit control of a Combobox that can be edited included), which we find by their window class
+// see NewEditMenu() for why Redo is never enabled
+
+// Windows programs use Ctrl+Y for Redo
+var redoShortcut = menuShortcut{'Y', false}
+
+var (
+	_getClassName = user32.NewProc("GetClassNameW")
+)
+
+// runs on the UI thread; returns the edit control with the focus in s, if any
+func (s *sysData) focusedEdit() _HWND {
+	var name [32]uint16
+
+	focus, _, _ := _getFocus.Call()
+	if _HWND(focus) == _HWND(_NULL) {
+		return _HWND(_NULL)
+	}
+	r1, _, _ := _isChild.Call(
+		uintptr(s.hwnd),
+		focus)
+	if r1 == 0 {
+		return _HWND(_NULL)
+	}
+	n, _, _ := _getClassName.Call(
+		focus,
+		uintptr(unsafe.Pointer(&name[0])),
+		uintptr(len(name)))
+	if !strings.EqualFold(syscall.UTF16ToString(name[:n]), "Edit") {
+		return _HWND(_NULL)
+	}
+	return _HWND(focus)
+}
+
+// runs on the UI thread
+func (s *sysData) editFocus() (f editFocus) {
+	var start, end uint32
+
+	hwnd := s.focusedEdit()
+	if hwnd == _HWND(_NULL) {
+		return f
+	}
+	f.ok = true
+	f.editable = getWindowLongPtr(hwnd, negConst(_GWL_STYLE))&_ES_READONLY == 0
+	_sendMessage.Call(
+		uintptr(hwnd),
+		uintptr(_EM_GETSEL),
+		uintptr(unsafe.Pointer(&start)),
+		uintptr(unsafe.Pointer(&end)))
+	f.selected = start != end
+	r1, _, _ := _sendMessage.Call(
+		uintptr(hwnd),
+		uintptr(_WM_GETTEXTLENGTH),
+		uintptr(0),
+		uintptr(0))
+	f.empty = r1 == 0
+	r1, _, _ = _sendMessage.Call(
+		uintptr(hwnd),
+		uintptr(_EM_CANUNDO),
+		uintptr(0),
+		uintptr(0))
+	f.canUndo = f.editable && r1 != 0
+	return f
+}
+
+// runs on the UI thread
+func (s *sysData) editCommand(c editCommand) {
+	var msg, wParam, lParam uintptr
+
+	hwnd := s.focusedEdit()
+	if hwnd == _HWND(_NULL) {
+		return
+	}
+	switch c {
+	case editUndo:
+		msg = _WM_UNDO
+	case editRedo:
+		return
+	case editCut:
+		msg = _WM_CUT
+	case editCopy:
+		msg = _WM_COPY
+	case editPaste:
+		msg = _WM_PASTE
+	case editSelectAll:
+		msg = _EM_SETSEL
+		lParam = negConst(-1)
+	}
+	_sendMessage.Call(
+		uintptr(hwnd),
+		msg,
+		wParam,
+		lParam)
+}
@@ -0,0 +1,77 @@
+// 6 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+)
+
+// Error is the type of errors reported by the system: a Windows API function failing, a GTK+ function returning NULL, and so on.
+// If package ui can't recover from such an error, it panics with an *Error (see SetPanicHandler); errors that package ui can recover from are passed to the function given to SetErrorHandler, if any.
+type Error struct {
+	Op      string  // what package ui was doing, such as "setting window/control text"
+	Call    string  // the system function that failed, such as "SetWindowText()"; empty if not known
+	Code    uintptr // the system's error code (GetLastError() on Windows, the GError code on Unix) if there is one, 0 otherwise
+	Control Control // the Control involved, or nil if there isn't one (or if it was a Window)
+	Err     error   // the underlying error, if any
+}
+
+func (e *Error) Error() string {
+	s := "error " + e.Op
+	if e.Control != nil {
+		s += fmt.Sprintf(" (%T)", e.Control)
+	}
+	if e.Call != "" {
+		s += ": " + e.Call + " failed"
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+func newError(c Control, op string, call string, err error) *Error {
+	e := &Error{
+		Op:      op,
+		Call:    call,
+		Control: c,
+		Err:     err,
+	}
+	if errno, ok := err.(syscall.Errno); ok {
+		e.Code = uintptr(errno)
+	}
+	return e
+}
+
+// the Control is filled in from s.control, which each Control's make() sets
+func (s *cSysData) newError(op string, call string, err error) *Error {
+	return newError(s.control, op, call, err)
+}
+
+var (
+	errorHandler     func(err *Error)
+	errorHandlerLock sync.Mutex
+)
+
+// SetErrorHandler sets the function that is called if the system reports an error that package ui can recover from; for instance, if a control could not be moved while laying out a Window.
+// Package ui carries on after the handler returns; errors it can't recover from cause a panic with an *Error instead (see SetPanicHandler).
+// Like the panic handler, the error handler runs on the UI thread and cannot call any other package ui function or method.
+// If no handler is set (or if SetErrorHandler is called with nil), these errors are ignored.
+func SetErrorHandler(handler func(err *Error)) {
+	errorHandlerLock.Lock()
+	defer errorHandlerLock.Unlock()
+
+	errorHandler = handler
+}
+
+// runs on the UI thread
+func reportError(err *Error) {
+	errorHandlerLock.Lock()
+	handler := errorHandler
+	errorHandlerLock.Unlock()
+	if handler != nil {
+		handler(err)
+	}
+}
@@ -0,0 +1,136 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A FilteredList shows the items of a list that pass a test in a Listbox, such as the items that match what the user is searching for.
+// The items themselves are kept by the FilteredList, in order; the Listbox shows the ones that pass, in the same order.
+// When the test changes (with SetFilter) or more items are added (with Append), only the items that appear or disappear are added to or removed from the Listbox, so search-as-you-type doesn't rebuild the Listbox on every key, and the selection stays on the items that are still shown.
+//
+// Once a Listbox is given to a FilteredList, don't add items to it or remove items from it yourself; use the FilteredList's methods instead.
+// Use Index to go from the indices of the Listbox (such as those from Listbox.SelectedIndices) to the indices of the items.
+type FilteredList struct {
+	lock  sync.Mutex
+	lb    *Listbox
+	items []string
+	keep  func(item string) bool
+	shown []int // indices into items of what's in lb, in order
+}
+
+// NewFilteredList creates a new FilteredList that shows those of items for which keep returns true in l; a nil keep shows every item.
+// keep is called from the goroutine that calls NewFilteredList, SetFilter, or Append, never from the UI thread.
+// It panics if l already has items.
+func NewFilteredList(l *Listbox, items []string, keep func(item string) bool) *FilteredList {
+	if n := l.Len(); n != 0 {
+		panic(fmt.Errorf("Listbox given to NewFilteredList() has %d items already; it must be empty", n))
+	}
+	f := &FilteredList{
+		lb:    l,
+		items: append([]string(nil), items...),
+	}
+	f.SetFilter(keep)
+	return f
+}
+
+// SetFilter changes the test that decides which items are shown; a nil keep shows every item.
+// Call it with the same function again to refilter if the function looks at something that has changed, such as the text being searched for.
+func (f *FilteredList) SetFilter(keep func(item string) bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.keep = keep
+	shown := make([]int, 0, len(f.items))
+	for i := range f.items {
+		if f.passes(i) {
+			shown = append(shown, i)
+		}
+	}
+	// both lists of indices are in order, so walk them together; row is where we are in the Listbox
+	// the changes are all worked out first and then made at once (see Listbox.edit()), so the Listbox is only redrawn once however many rows change
+	old := f.shown
+	var edits []listEdit
+	var tail []string // items past the last one that was shown before go on the end, the way Append adds them
+	row, a, b := 0, 0, 0
+	for a < len(old) || b < len(shown) {
+		switch {
+		case b == len(shown) || (a < len(old) && old[a] < shown[b]): // no longer shown
+			edits = append(edits, listEdit{index: row})
+			a++
+		case a == len(old) || shown[b] < old[a]: // newly shown
+			if a < len(old) {
+				edits = append(edits, listEdit{
+					index:  row,
+					insert: true,
+					what:   f.items[shown[b]],
+				})
+				row++
+			} else {
+				tail = append(tail, f.items[shown[b]])
+			}
+			b++
+		default: // still shown
+			row++
+			a++
+			b++
+		}
+	}
+	if len(edits) != 0 || len(tail) != 0 {
+		f.lb.edit(edits, tail)
+	}
+	f.shown = shown
+}
+
+// Append adds items to the end of the list, and shows those that pass the current test at the end of the Listbox.
+func (f *FilteredList) Append(items ...string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var show []string
+
+	for _, item := range items {
+		f.items = append(f.items, item)
+		if f.passes(len(f.items) - 1) {
+			f.shown = append(f.shown, len(f.items)-1)
+			show = append(show, item)
+		}
+	}
+	if len(show) != 0 {
+		f.lb.Append(show...)
+	}
+}
+
+// Index returns the index into the whole list of the item shown at the given index of the Listbox.
+// It panics if the index is out of range.
+func (f *FilteredList) Index(row int) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if row < 0 || row >= len(f.shown) {
+		panic(fmt.Errorf("index %d out of range in FilteredList.Index()", row))
+	}
+	return f.shown[row]
+}
+
+// Len returns the number of items in the whole list, shown or not.
+func (f *FilteredList) Len() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return len(f.items)
+}
+
+// ShownLen returns the number of items shown in the Listbox.
+func (f *FilteredList) ShownLen() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return len(f.shown)
+}
+
+func (f *FilteredList) passes(i int) bool {
+	return f.keep == nil || f.keep(f.items[i])
+}
@@ -0,0 +1,112 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A FontButton is a Control that shows the name and size of a font (see Font.String); clicking it lets the user pick another one, with the same dialog as ChooseFont.
+// On GTK+, a FontButton is a GtkFontButton; Windows and Mac OS X have no font button, so there it is a push button that opens the dialog.
+type FontButton struct {
+	// Changed is signaled when the user picks another font.
+	// It is not signaled for SetFont.
+	Changed chan struct{}
+
+	lock     sync.Mutex
+	created  bool
+	sysData  *sysData
+	initFont Font
+}
+
+// NewFontButton creates a new FontButton showing initial.
+// It panics if initial has no Family or no Size, as there would be nothing to show.
+func NewFontButton(initial Font) *FontButton {
+	b := &FontButton{
+		Changed: newEvent(),
+		sysData: mksysdata(c_fontbutton),
+	}
+	b.initFont = fontButtonCheck(initial, "NewFontButton()")
+	return b
+}
+
+func fontButtonCheck(f Font, what string) Font {
+	if f.Family == "" || f.Size <= 0 {
+		panic(fmt.Errorf("font %q with no family or size given to %s", f.String(), what))
+	}
+	return f.normalize()
+}
+
+// Font returns the font the FontButton shows.
+func (b *FontButton) Font() Font {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.created {
+		return b.initFont
+	}
+	ret := make(chan Font)
+	defer close(ret)
+	uitask(func() {
+		ret <- b.sysData.fontButtonFont().normalize()
+	})
+	return <-ret
+}
+
+// SetFont changes the font the FontButton shows.
+// It panics if f has no Family or no Size.
+func (b *FontButton) SetFont(f Font) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	f = fontButtonCheck(f, "FontButton.SetFont()")
+	if !b.created {
+		b.initFont = f
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		b.sysData.fontButtonSetFont(f)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (b *FontButton) make(window *sysData) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.sysData.control = b
+	b.sysData.event = b.Changed
+	err := b.sysData.make(window)
+	if err != nil {
+		return err
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		b.sysData.fontButtonSetFont(b.initFont)
+		ret <- struct{}{}
+	})
+	<-ret
+	b.created = true
+	return nil
+}
+
+func (b *FontButton) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	return b.sysData.singleAllocation(x, y, width, height, b)
+}
+
+func (b *FontButton) preferredSize(d *sysSizeData) (width int, height int) {
+	return b.sysData.preferredSize(d)
+}
+
+func (b *FontButton) commitResize(a *allocation, d *sysSizeData) {
+	b.sysData.commitResize(a, d)
+}
+
+func (b *FontButton) getAuxResizeInfo(d *sysSizeData) {
+	b.sysData.getAuxResizeInfo(d)
+}
@@ -0,0 +1,42 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+/*
+Mac OS X has no font button, so a FontButton is a push button whose title is the font; clicking it runs the font panel of ChooseFont() right there on the UI thread (see appDelegate_fontButtonClicked()), like the Windows FontButton (see fontbutton_windows.go).
+The button doesn't hold the font, so sysData.buttonFont does.
+*/
+
+func makeFontButton(parentWindow C.id, alternate bool, s *sysData) C.id {
+	button := C.makeFontButton(appDelegate)
+	applyStandardControlFont(button)
+	addControl(parentWindow, button)
+	return button
+}
+
+func (s *sysData) fontButtonSetFont(f Font) {
+	s.buttonFont = f
+	C.buttonSetText(s.id, toNSString(f.String()))
+	s.invalidatePreferredSize()
+}
+
+func (s *sysData) fontButtonFont() Font {
+	return s.buttonFont
+}
+
+// runs on the UI thread
+func (s *sysData) fontButtonClicked() {
+	f, ok := runFontPanel(s.buttonFont)
+	if !ok {
+		return
+	}
+	f = f.normalize()
+	if f == s.buttonFont {
+		return
+	}
+	s.fontButtonSetFont(f)
+	s.signal()
+}
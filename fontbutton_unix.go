@@ -0,0 +1,24 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+// #include "gtk_unix.h"
+import "C"
+
+/*
+On GTK+, a FontButton is a GtkFontButton, which runs a GtkFontChooserDialog of its own when clicked and shows the font with its size; it sends "font-set" only when the user picks a font, not for gtk_font_chooser_set_font_desc().
+*/
+
+func gtkFontButtonNew() *C.GtkWidget {
+	return C.gtk_font_button_new()
+}
+
+func (s *sysData) fontButtonSetFont(f Font) {
+	setFontChooserFont(s.widget, f)
+}
+
+func (s *sysData) fontButtonFont() Font {
+	return getFontChooserFont(s.widget)
+}
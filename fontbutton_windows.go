@@ -0,0 +1,37 @@
+// 9 july 2014
+
+package ui
+
+/*
+Windows has no font button, so a FontButton is a push button whose text is the font; clicking it runs the font dialog of ChooseFont() right there on the UI thread, owned by the button's Window, like a ColorButton does (see colorbutton_windows.go).
+The button doesn't hold the font, so sysData.buttonFont does.
+*/
+
+func (s *sysData) fontButtonSetFont(f Font) {
+	s.buttonFont = f
+	r1, _, err := _setWindowText.Call(
+		uintptr(s.hwnd),
+		utf16ToArg(toUTF16(f.String())))
+	if r1 == 0 { // failure
+		panic(s.newError("setting FontButton text", "SetWindowText()", err))
+	}
+	s.invalidatePreferredSize()
+}
+
+func (s *sysData) fontButtonFont() Font {
+	return s.buttonFont
+}
+
+// runs on the UI thread; window is the Window the button is in
+func (s *sysData) fontButtonClicked(window *sysData) {
+	f, ok := runFontDialog(window.hwnd, s.buttonFont)
+	if !ok {
+		return
+	}
+	f = f.normalize()
+	if f == s.buttonFont {
+		return
+	}
+	s.fontButtonSetFont(f)
+	s.signal()
+}
@@ -0,0 +1,98 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+)
+
+// Font describes a font, as picked with ChooseFont or a FontButton.
+// For now, it only says which font was picked: package ui can't yet set the font of a Control or draw text in an Area with it.
+type Font struct {
+	// Family is the name of the font family, as listed by Fonts.
+	Family string
+
+	// Size is the size of the font in points; fractions of a point are allowed where the system has them.
+	Size float64
+
+	// Weight is how bold the font is.
+	Weight FontWeight
+}
+
+// FontWeight says how bold a Font is, from 100 (the thinnest) to 900 (the boldest), as in CSS and OpenType.
+// Systems and fonts don't all have every weight, so a Font picked by the user may have a weight between the constants below.
+// The zero value is the same as FontWeightNormal.
+type FontWeight int
+
+const (
+	FontWeightThin       FontWeight = 100
+	FontWeightExtraLight FontWeight = 200
+	FontWeightLight      FontWeight = 300
+	FontWeightNormal     FontWeight = 400
+	FontWeightMedium     FontWeight = 500
+	FontWeightSemiBold   FontWeight = 600
+	FontWeightBold       FontWeight = 700
+	FontWeightExtraBold  FontWeight = 800
+	FontWeightHeavy      FontWeight = 900
+)
+
+// by weight / 100, rounded
+var fontWeightNames = []string{
+	"Thin", // for weights under 50, which no system uses
+	"Thin",
+	"Extra Light",
+	"Light",
+	"",
+	"Medium",
+	"Semi Bold",
+	"Bold",
+	"Extra Bold",
+	"Heavy",
+}
+
+// String returns the font the way the systems show it to the user: the family, then the weight unless it's FontWeightNormal, then the size; for instance, "Helvetica Bold 12".
+func (f Font) String() string {
+	w := int(f.normalize().Weight+50) / 100
+	if w >= len(fontWeightNames) {
+		w = len(fontWeightNames) - 1
+	}
+	s := f.Family
+	if fontWeightNames[w] != "" {
+		s += " " + fontWeightNames[w]
+	}
+	return fmt.Sprintf("%s %g", s, f.Size)
+}
+
+// what the platform code gets and gives back
+func (f Font) normalize() Font {
+	if f.Weight <= 0 {
+		f.Weight = FontWeightNormal
+	}
+	if f.Weight > FontWeightHeavy {
+		f.Weight = FontWeightHeavy
+	}
+	if f.Size < 0 {
+		f.Size = 0
+	}
+	return f
+}
+
+// ChooseFont shows the system's font dialog with initial selected, and returns the font the user picked and true if they clicked OK, or false if they canceled.
+// If initial.Family is empty, the dialog starts at the system's choice of font instead; if initial.Size is zero, it starts at the system's choice of size.
+// Only the family, size, and weight are returned; if the user also picks a style a Font has no room for, such as italics, it's dropped.
+//
+// If parent is not nil, the dialog is modal to parent; otherwise it is modal to the entire application (see "On Dialogs" in the package overview).
+// On Mac OS X, the font panel is shared by the whole program and can't be a sheet, so it's always modal to the entire application, with OK and Cancel buttons added, as with ChooseColor.
+// ChooseFont waits for the user to close the dialog; like everything else in package ui, it must not be called on the UI thread.
+func ChooseFont(parent *Window, initial Font) (f Font, ok bool) {
+	if parent == nil {
+		parent = dialogWindow
+	} else if !parent.created {
+		panic("parent window passed to ChooseFont() before it was created")
+	}
+	f, ok = chooseFont(parent, initial.normalize())
+	if !ok {
+		return Font{}, false
+	}
+	return f.normalize(), true
+}
@@ -0,0 +1,64 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// NSFontManager weights, by FontWeight / 100; see fontdialog_darwin.m
+var macFontWeights = []C.intptr_t{
+	2,  // for weights under 50
+	2,  // thin
+	3,  // extra light
+	4,  // light
+	5,  // normal
+	6,  // medium
+	8,  // semibold
+	9,  // bold
+	10, // extra bold
+	11, // heavy
+}
+
+func toMacFontWeight(w FontWeight) C.intptr_t {
+	return macFontWeights[int(w+50)/100]
+}
+
+func fromMacFontWeight(w C.intptr_t) FontWeight {
+	for i := 1; i < len(macFontWeights); i++ {
+		if w <= macFontWeights[i] {
+			return FontWeight(i * 100)
+		}
+	}
+	return FontWeightHeavy
+}
+
+// see colordialog_darwin.go; parent is not used, since NSFontPanel can't be a sheet either
+func chooseFont(parent *Window, initial Font) (f Font, ok bool) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		f, ok = runFontPanel(initial)
+		ret <- struct{}{}
+	})
+	<-ret
+	return f, ok
+}
+
+// runs on the UI thread; this is also what a FontButton shows when clicked (see fontbutton_darwin.go)
+func runFontPanel(initial Font) (f Font, ok bool) {
+	var family C.id
+
+	if initial.Family != "" {
+		family = toNSString(initial.Family)
+	}
+	size := C.double(initial.Size)
+	weight := toMacFontWeight(initial.Weight)
+	if C.runFontPanel(&family, &size, &weight) == C.NO {
+		return Font{}, false
+	}
+	return Font{
+		Family: fromNSString(family),
+		Size:   float64(size),
+		Weight: fromMacFontWeight(weight),
+	}, true
+}
@@ -0,0 +1,114 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSApplication.h>
+#import <AppKit/NSFontPanel.h>
+#import <AppKit/NSFontManager.h>
+#import <AppKit/NSFont.h>
+#import <AppKit/NSButton.h>
+#import <AppKit/NSView.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSButton(x) to(NSButton, (x))
+
+/*
+NSFontPanel is like NSColorPanel (see colordialog_darwin.m): a single panel shared by the whole program, with no OK or Cancel, that applies fonts as the user picks them.
+So we do the same: give it the same accessory view with Cancel and OK and run it modally.
+The panel doesn't hold a font; it sends changeFont: to the target of the shared NSFontManager, which asks the font manager to convert the font it had, so our controller keeps the font.
+Font weights on Mac OS X go from 0 to 15, with 5 for normal and 9 for bold; see fontdialog_darwin.go.
+*/
+
+extern NSView *panelButtons(id);
+
+@interface goFontPanelController : NSObject <NSWindowDelegate> {
+@public
+	NSFont *font;
+}
+- (IBAction)ok:(id)sender;
+- (IBAction)cancel:(id)sender;
+- (void)changeFont:(id)sender;
+@end
+
+@implementation goFontPanelController
+
+- (IBAction)ok:(id)sender
+{
+	[NSApp stopModalWithCode:NSOKButton];
+}
+
+- (IBAction)cancel:(id)sender
+{
+	[NSApp stopModalWithCode:NSCancelButton];
+}
+
+- (void)changeFont:(id)sender
+{
+	NSFont *f;
+
+	f = [sender convertFont:font];
+	[f retain];
+	[font release];
+	font = f;
+}
+
+- (BOOL)windowShouldClose:(id)win
+{
+	[NSApp stopModalWithCode:NSCancelButton];
+	return YES;
+}
+
+@end
+
+// family is nil for the system font, and size is 0 for the system font size; all three are replaced by what the user picked if this returns YES
+BOOL runFontPanel(id *family, double *size, intptr_t *weight)
+{
+	NSFontManager *fm;
+	NSFontPanel *panel;
+	goFontPanelController *c;
+	NSFont *initial = nil;
+	NSView *buttons;
+	NSInteger res;
+
+	fm = [NSFontManager sharedFontManager];
+	panel = [fm fontPanel:YES];
+	if (*size == 0)
+		*size = [NSFont systemFontSize];
+	if (*family != nil)
+		initial = [fm fontWithFamily:((NSString *) (*family))
+			traits:0
+			weight:(NSInteger) (*weight)
+			size:(CGFloat) (*size)];
+	if (initial == nil)		// no family given, or it isn't installed
+		initial = [NSFont systemFontOfSize:(CGFloat) (*size)];
+	c = [goFontPanelController new];
+	c->font = [initial retain];
+	[fm setSelectedFont:initial isMultiple:NO];
+	[fm setTarget:c];
+	[panel setDelegate:c];
+	buttons = panelButtons(c);
+	[panel setAccessoryView:buttons];
+	[buttons release];
+
+	res = [NSApp runModalForWindow:panel];
+	*family = [c->font familyName];
+	*size = (double) [c->font pointSize];
+	*weight = (intptr_t) [fm weightOfFont:c->font];
+
+	[panel orderOut:panel];
+	[panel setAccessoryView:nil];
+	[panel setDelegate:nil];
+	[fm setTarget:nil];
+	[c->font release];
+	[c release];
+	return res == NSOKButton;
+}
+
+id makeFontButton(id delegate)
+{
+	id button;
+
+	button = makeButton();
+	[toNSButton(button) setTarget:delegate];
+	[toNSButton(button) setAction:@selector(fontButtonClicked:)];
+	return button;
+}
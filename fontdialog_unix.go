@@ -0,0 +1,74 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+import "C"
+
+/*
+GtkFontChooserDialog (new in GTK+ 3.2) picks a PangoFontDescription, whose size is in points times PANGO_SCALE and whose weights are the same 100 to 900 as FontWeight; GtkFontButton picks one the same way (see fontbutton_unix.go).
+It's run through the same dialog struct as the message boxes in dialog_unix.go, like the color dialog (see colordialog_unix.go).
+*/
+
+func chooseFont(parent *Window, initial Font) (f Font, ok bool) {
+	d := mkdialog(parent)
+	d.finish = func(box *C.GtkWidget, res C.gint) {
+		if res == C.gint(C.GTK_RESPONSE_OK) {
+			f = getFontChooserFont(box)
+		}
+	}
+	uitask(func() {
+		ctitle := C.CString("Select a Font")
+		defer C.free(unsafe.Pointer(ctitle))
+		d.run(func() *C.GtkWidget {
+			box := C.gtk_font_chooser_dialog_new((*C.gchar)(unsafe.Pointer(ctitle)), d.pwin)
+			C.gtk_window_set_modal(togtkwindow(box), C.TRUE)
+			if initial.Family != "" {
+				setFontChooserFont(box, initial)
+			}
+			return box
+		})
+	})
+	res := <-d.result
+	return f, res == int(C.GTK_RESPONSE_OK)
+}
+
+func togtkfontchooser(what *C.GtkWidget) *C.GtkFontChooser {
+	return (*C.GtkFontChooser)(unsafe.Pointer(what))
+}
+
+func setFontChooserFont(chooser *C.GtkWidget, f Font) {
+	desc := C.pango_font_description_new()
+	defer C.pango_font_description_free(desc)
+	cfamily := C.CString(f.Family)
+	defer C.free(unsafe.Pointer(cfamily))
+	// this copies the family
+	C.pango_font_description_set_family(desc, cfamily)
+	if f.Size != 0 {
+		C.pango_font_description_set_size(desc, C.gint(f.Size*C.PANGO_SCALE+0.5))
+	}
+	C.pango_font_description_set_weight(desc, C.PangoWeight(f.Weight))
+	// and this copies desc
+	C.gtk_font_chooser_set_font_desc(togtkfontchooser(chooser), desc)
+}
+
+func getFontChooserFont(chooser *C.GtkWidget) Font {
+	desc := C.gtk_font_chooser_get_font_desc(togtkfontchooser(chooser))
+	if desc == nil { // nothing picked
+		return Font{}
+	}
+	defer C.pango_font_description_free(desc)
+	var f Font
+	if family := C.pango_font_description_get_family(desc); family != nil {
+		f.Family = C.GoString(family)
+	}
+	f.Size = float64(C.pango_font_description_get_size(desc)) / C.PANGO_SCALE
+	f.Weight = FontWeight(C.pango_font_description_get_weight(desc))
+	return f
+}
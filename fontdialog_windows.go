@@ -0,0 +1,108 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"math"
+	"syscall"
+	"unsafe"
+)
+
+/*
+ChooseFont() works with LOGFONTs, whose height is in pixels, not points; it gives the size in points back in iPointSize (in tenths of a point), but takes it only through the LOGFONT, so we convert with the screen's vertical DPI the way MSDN says to.
+We leave out CF_EFFECTS, so the dialog doesn't offer strikeout, underline, or colors; it still offers italics, which Font doesn't have room for.
+*/
+
+var (
+	_chooseFont    = comdlg32.NewProc("ChooseFontW")
+	_getDeviceCaps = gdi32.NewProc("GetDeviceCaps")
+)
+
+type _CHOOSEFONT struct {
+	lStructSize    uint32
+	hwndOwner      _HWND
+	hDC            _HANDLE
+	lpLogFont      *_LOGFONT
+	iPointSize     int32 // originally INT
+	Flags          uint32
+	rgbColors      uint32
+	lCustData      _LPARAM
+	lpfnHook       uintptr
+	lpTemplateName *uint16
+	hInstance      _HANDLE
+	lpszStyle      *uint16
+	nFontType      uint16
+	_              uint16 // originally ___MISSING_ALIGNMENT__
+	nSizeMin       int32  // originally INT
+	nSizeMax       int32  // originally INT
+}
+
+func chooseFont(parent *Window, initial Font) (f Font, ok bool) {
+	var owner _HWND
+	if parent != dialogWindow {
+		owner = parent.sysData.hwnd
+	}
+	// TODO same as in chooseColor()
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		f, ok = runFontDialog(owner, initial)
+		ret <- struct{}{}
+	})
+	<-ret
+	return f, ok
+}
+
+// runs on the UI thread; this is also what a FontButton shows when clicked (see fontbutton_windows.go)
+func runFontDialog(owner _HWND, initial Font) (f Font, ok bool) {
+	var lf _LOGFONT
+
+	cf := &_CHOOSEFONT{
+		hwndOwner: owner,
+		lpLogFont: &lf,
+		Flags:     _CF_SCREENFONTS | _CF_NOVERTFONTS | _CF_FORCEFONTEXIST,
+	}
+	cf.lStructSize = uint32(unsafe.Sizeof(*cf))
+	if initial.Family != "" {
+		cf.Flags |= _CF_INITTOLOGFONTSTRUCT
+		lf.lfCharSet = _DEFAULT_CHARSET
+		lf.lfWeight = int32(initial.Weight)
+		// lfHeight is negative to mean the height of the characters, not of the cells they're in, which is what points are
+		lf.lfHeight = -int32(math.Floor(initial.Size*float64(screenDPIY())/72 + 0.5))
+		// lfFaceName must be null-terminated, so leave room for that
+		copy(lf.lfFaceName[:_LF_FACESIZE-1], syscall.StringToUTF16(initial.Family))
+	}
+	r1, _, _ := _chooseFont.Call(uintptr(unsafe.Pointer(cf)))
+	// like ChooseColor()
+	if r1 == 0 {
+		r1, _, _ = _commDlgExtendedError.Call()
+		if r1 != 0 {
+			panic(fmt.Errorf("error showing font dialog: ChooseFont() failed with extended error 0x%X", r1))
+		}
+		return Font{}, false
+	}
+	return Font{
+		Family: syscall.UTF16ToString(lf.lfFaceName[:]),
+		Size:   float64(cf.iPointSize) / 10,
+		Weight: FontWeight(lf.lfWeight),
+	}, true
+}
+
+// the screen's vertical DPI; 96 if we can't get it, which is the usual one
+func screenDPIY() int {
+	r1, _, err := _getDC.Call(uintptr(_NULL))
+	if r1 == 0 { // failure; not fatal, as the dialog will just start at a slightly different size
+		reportError(newError(nil, "getting screen DC for font size", "GetDC()", err))
+		return 96
+	}
+	dc := _HANDLE(r1)
+	// GetDeviceCaps() can't fail for LOGPIXELSY
+	r1, _, _ = _getDeviceCaps.Call(
+		uintptr(dc),
+		uintptr(_LOGPIXELSY))
+	_releaseDC.Call(
+		uintptr(_NULL),
+		uintptr(dc))
+	return int(r1)
+}
@@ -0,0 +1,32 @@
+// 2 july 2014
+
+package ui
+
+import (
+	"sort"
+)
+
+// FontFamily describes a family of fonts installed on the system.
+type FontFamily struct {
+	// Name is the name of the font family as the system presents it to the user.
+	Name string
+
+	// Monospace is true if every character in the font family has the same width.
+	// How this is determined is implementation-defined; some systems check the family itself, others check a regular-weight member of the family.
+	Monospace bool
+}
+
+type fontFamilies []FontFamily
+
+func (f fontFamilies) Len() int           { return len(f) }
+func (f fontFamilies) Less(i, j int) bool { return f[i].Name < f[j].Name }
+func (f fontFamilies) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+
+// Fonts returns a list of all font families installed on the system, sorted by name.
+// Each family is listed only once.
+// Whether or not fonts that cannot be used for drawing text horizontally (such as the vertical fonts on Windows) are listed is implementation-defined.
+func Fonts() []FontFamily {
+	f := fontFamilies(sysFonts())
+	sort.Sort(f)
+	return []FontFamily(f)
+}
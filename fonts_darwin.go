@@ -0,0 +1,23 @@
+// 2 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+func sysFonts() []FontFamily {
+	ret := make(chan []FontFamily)
+	defer close(ret)
+	uitask(func() {
+		families := C.fontFamilies()
+		n := int(C.fontFamiliesCount(families))
+		f := make([]FontFamily, n)
+		for i := range f {
+			family := C.fontFamilyAt(families, C.uintptr_t(i))
+			f[i].Name = fromNSString(family)
+			f[i].Monospace = C.fontFamilyIsMonospace(family) != C.NO
+		}
+		ret <- f
+	})
+	return <-ret
+}
@@ -0,0 +1,44 @@
+// 2 july 2014
+
+#include "objc_darwin.h"
+#import <Foundation/NSArray.h>
+#import <Foundation/NSString.h>
+#import <AppKit/NSFont.h>
+#import <AppKit/NSFontManager.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSArray(x) to(NSArray, (x))
+#define _toNSString(x) to(NSString, (x))
+
+#define toNSUInteger(x) ((NSUInteger) (x))
+#define fromNSUInteger(x) ((uintptr_t) (x))
+
+id fontFamilies(void)
+{
+	return [[NSFontManager sharedFontManager] availableFontFamilies];
+}
+
+uintptr_t fontFamiliesCount(id families)
+{
+	return fromNSUInteger([toNSArray(families) count]);
+}
+
+id fontFamilyAt(id families, uintptr_t index)
+{
+	return [toNSArray(families) objectAtIndex:toNSUInteger(index)];
+}
+
+// there is no fixed-pitch property on a family itself, so we check a regular member of that family instead
+// weight 5 is the normal weight according to the NSFontManager documentation
+BOOL fontFamilyIsMonospace(id family)
+{
+	NSFont *font;
+
+	font = [[NSFontManager sharedFontManager] fontWithFamily:_toNSString(family)
+		traits:0
+		weight:5
+		size:12];
+	if (font == nil)		// no regular member; assume not
+		return NO;
+	return [font isFixedPitch];
+}
@@ -0,0 +1,38 @@
+// +build !windows,!darwin,!plan9
+
+// 2 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+import "C"
+
+// GTK+ draws all its text with Pango, so we ask Pango for the families it knows about.
+// gdk_pango_context_get() gives us a context for the default screen, which is the one all our widgets use.
+func sysFonts() []FontFamily {
+	ret := make(chan []FontFamily)
+	defer close(ret)
+	uitask(func() {
+		var families **C.PangoFontFamily
+		var n C.int
+
+		context := C.gdk_pango_context_get()
+		C.pango_context_list_families(context, &families, &n)
+		f := make([]FontFamily, n)
+		// there's no way to index a C array in Go directly, so
+		fam := (*[1 << 20]*C.PangoFontFamily)(unsafe.Pointer(families))[:n:n]
+		for i := range f {
+			f[i].Name = C.GoString(C.pango_font_family_get_name(fam[i]))
+			f[i].Monospace = fromgbool(C.pango_font_family_is_monospace(fam[i]))
+		}
+		// the array belongs to us but the families themselves belong to Pango
+		C.g_free(C.gpointer(unsafe.Pointer(families)))
+		C.g_object_unref(C.gpointer(unsafe.Pointer(context)))
+		ret <- f
+	})
+	return <-ret
+}
@@ -0,0 +1,62 @@
+// 2 july 2014
+
+package ui
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	_enumFontFamiliesEx = gdi32.NewProc("EnumFontFamiliesExW")
+)
+
+// EnumFontFamiliesEx() with DEFAULT_CHARSET lists a family once for every character set it supports, so we use a map to weed out the duplicates.
+// The enumeration is synchronous and only ever runs on uitask, so a single global is fine here.
+var enumFonts map[string]bool
+
+func enumFontFamExProc(lpelfe *_LOGFONT, lpntme uintptr, fontType uint32, lParam _LPARAM) uintptr {
+	name := syscall.UTF16ToString(lpelfe.lfFaceName[:])
+	// families whose names start with @ are the vertical versions of CJK fonts; we can't draw horizontal text with those
+	if len(name) != 0 && name[0] != '@' {
+		// use LOGFONT's pitch here, not TEXTMETRIC's; the latter's TMPF_FIXED_PITCH bit means the opposite of what it says
+		enumFonts[name] = (lpelfe.lfPitchAndFamily & 3) == _FIXED_PITCH
+	}
+	return 1 // continue enumeration
+}
+
+var enumFontFamExCallback = syscall.NewCallback(enumFontFamExProc)
+
+func sysFonts() []FontFamily {
+	ret := make(chan []FontFamily)
+	defer close(ret)
+	uitask(func() {
+		var lf _LOGFONT
+
+		r1, _, err := _getDC.Call(uintptr(_NULL))
+		if r1 == 0 { // failure
+			panic(newError(nil, "getting screen DC for listing fonts", "GetDC()", err))
+		}
+		dc := _HANDLE(r1)
+		lf.lfCharSet = _DEFAULT_CHARSET // and an empty lfFaceName means one entry per family
+		enumFonts = make(map[string]bool)
+		// the return value is the return value of the last callback, so we can't tell failure apart from success; don't bother checking
+		_enumFontFamiliesEx.Call(
+			uintptr(dc),
+			uintptr(unsafe.Pointer(&lf)),
+			enumFontFamExCallback,
+			uintptr(0),
+			uintptr(0))
+		releaseTextDC(_HWND(_NULL), dc)
+		f := make([]FontFamily, 0, len(enumFonts))
+		for name, mono := range enumFonts {
+			f = append(f, FontFamily{
+				Name:      name,
+				Monospace: mono,
+			})
+		}
+		enumFonts = nil
+		ret <- f
+	})
+	return <-ret
+}
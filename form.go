@@ -0,0 +1,150 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+)
+
+// A Form ties the validators of some LineEdits (see LineEdit.SetValidator) to the Button that submits them: the Button is disabled until every LineEdit's text is valid, and is checked again each time the text of any of them changes.
+// The LineEdits still show their own hints as the user leaves them; the Form only adds the Button, and Validate, to check them all at once.
+// LineEdits without a validator are always valid.
+// The LineEdits and the Button must all be in the same Window.
+type Form struct {
+	submit *Button
+	fields []*LineEdit
+}
+
+// NewForm creates a new Form for the given LineEdits; submit can be nil if you only want Validate.
+// A LineEdit can only be in one Form.
+// This cannot be called once the Window containing the LineEdits has been created.
+func NewForm(submit *Button, fields ...*LineEdit) *Form {
+	f := &Form{
+		submit: submit,
+		fields: fields,
+	}
+	for _, l := range fields {
+		l.lock.Lock()
+		if l.created {
+			l.lock.Unlock()
+			panic("call to NewForm() after LineEdit has been created")
+		}
+		if l.form != nil {
+			l.lock.Unlock()
+			panic("LineEdit given to NewForm() is already in a Form")
+		}
+		l.form = f
+		l.lock.Unlock()
+	}
+	return f
+}
+
+// Validate checks the text of every LineEdit in the Form, shows the hint of each LineEdit whose text is invalid (and removes it from the others), and moves the keyboard focus to the first of those, as if the user had tried to leave it.
+// It returns the errors of the invalid LineEdits in the order they were given to NewForm, or nil if the whole Form is valid.
+// Call it when the Form is submitted some other way than with its Button (by pressing Enter in a LineEdit, for instance).
+// Validate panics if the Window containing the Form has not been created yet.
+func (f *Form) Validate() (errs []error) {
+	for _, l := range f.fields {
+		l.lock.Lock()
+		created := l.created
+		l.lock.Unlock()
+		if !created {
+			panic(fmt.Errorf("Form.Validate() called before window create"))
+		}
+	}
+	ret := make(chan []error)
+	defer close(ret)
+	uitask(func() {
+		var errs []error
+
+		for _, l := range f.fields {
+			s := l.sysData
+			if s.validate == nil {
+				continue
+			}
+			err := s.validate(s.lineeditText())
+			if err == nil {
+				s.setValidationHint("", false)
+				continue
+			}
+			s.setValidationHint(err.Error(), errs == nil)
+			errs = append(errs, err)
+		}
+		f.gate(errs == nil)
+		ret <- errs
+	})
+	return <-ret
+}
+
+// update() enables the submit Button if every LineEdit is valid and disables it otherwise, without showing any hints.
+// It is called on the UI thread by Window.Create() once everything is made and by the LineEdits when their text changes (see sysData.lineeditChanged()).
+func (f *Form) update() {
+	valid := true
+	for _, l := range f.fields {
+		s := l.sysData
+		if !s.made { // still being created; Window.Create() calls us again afterward
+			return
+		}
+		if s.validate != nil && s.validate(s.lineeditText()) != nil {
+			valid = false
+			break
+		}
+	}
+	f.gate(valid)
+}
+
+// this runs on the UI thread
+func (f *Form) gate(valid bool) {
+	if f.submit == nil || !f.submit.sysData.made {
+		return
+	}
+	s := f.submit.sysData
+	if s.invalid == !valid {
+		return
+	}
+	s.invalid = !valid
+	s.doSetEnabled()
+}
+
+// adds f to the Forms of a Window, once no matter how many of its LineEdits are in the Window
+func (s *sysData) addForm(f *Form) {
+	s.windowLock.Lock()
+	defer s.windowLock.Unlock()
+
+	for _, ff := range s.forms {
+		if ff == f {
+			return
+		}
+	}
+	s.forms = append(s.forms, f)
+}
+
+// called by Window.Create() once all the controls are made, as the Buttons of Forms start out enabled, and again by Tab as it makes each page afterward
+func (s *sysData) updateForms() {
+	s.windowLock.Lock()
+	forms := s.forms
+	s.windowLock.Unlock()
+	if len(forms) == 0 {
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		for _, f := range forms {
+			f.update()
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// called by the platform code on the UI thread when the text of a LineEdit changes, whether by the user or by SetText(); on Mac OS X, also when what is typed in an editable Combobox bound with BindSetting() changes (see combobox_darwin.go)
+func (s *sysData) lineeditChanged() {
+	s.settingChanged()
+	if s.palette != nil {
+		s.paletteKey(paletteChanged)
+	}
+	if s.form != nil {
+		s.form.update()
+	}
+}
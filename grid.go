@@ -4,7 +4,6 @@ package ui
 
 import (
 	"fmt"
-	"sync"
 )
 
 // A Grid arranges Controls in a two-dimensional grid.
@@ -14,17 +13,36 @@ import (
 // Even if a Control is marked as filling, its preferred size is used to calculate cell sizes.
 // One Control can be marked as "stretchy": when the Window containing the Grid is resized, the cell containing that Control resizes to take any remaining space; its row and column are adjusted accordingly (so other filling controls in the same row and column will fill to the new height and width, respectively).
 // A stretchy Control implicitly fills its cell.
+// If the Grid is too small for the preferred sizes of its controls, the stretchy row and column shrink to nothing first, then the cells at the right and bottom are cut off, as with Stack.
 // All cooridnates in a Grid are given in (row,column) form with (0,0) being the top-left cell.
+//
+// For data entry forms, a Grid can let the user move between its LineEdits with the keyboard, like in a spreadsheet; see SetKeyboardNavigation().
+//
+// As with Stack, build a Grid and call SetFilling() and SetStretchy() from one goroutine (or synchronize the calls yourself) before creating the Window that contains it; afterward, the Grid belongs to the UI thread.
 type Grid struct {
-	lock                     sync.Mutex
 	created                  bool
 	controls                 [][]Control
 	filling                  [][]bool
 	stretchyrow, stretchycol int
 	widths, heights          [][]int // caches to avoid reallocating each time
 	rowheights, colwidths    []int
+	allocations              []*allocation // likewise; see Stack.allocate()
+	navArrows                bool
+	navEnter                 GridEnter
 }
 
+// GridEnter says what the Enter key does in the LineEdits of a Grid; see Grid.SetKeyboardNavigation().
+type GridEnter uint
+
+const (
+	// Enter does what it does in any other LineEdit.
+	GridEnterDefault GridEnter = iota
+	// Enter moves to the next LineEdit down; from the bottom of a column, it moves to the top of the next column with a LineEdit in it.
+	GridEnterDown
+	// Enter moves to the next LineEdit to the right; from the end of a row, it moves to the start of the next row with a LineEdit in it.
+	GridEnterRight
+)
+
 // NewGrid creates a new Grid with the given Controls.
 // NewGrid needs to know the number of Controls in a row (alternatively, the number of columns); it will determine the number in a column from the number of Controls given.
 // NewGrid panics if not given a full grid of Controls.
@@ -69,9 +87,6 @@ func NewGrid(nPerRow int, controls ...Control) *Grid {
 // This function cannot be called after the Window that contains the Grid has been created.
 // It panics if the given coordinate is invalid.
 func (g *Grid) SetFilling(row int, column int) {
-	g.lock.Lock()
-	defer g.lock.Unlock()
-
 	if g.created {
 		panic(fmt.Errorf("Grid.SetFilling() called after window create"))
 	}
@@ -87,9 +102,6 @@ func (g *Grid) SetFilling(row int, column int) {
 // This function cannot be called after the Window that contains the Grid has been created.
 // It panics if the given coordinate is invalid.
 func (g *Grid) SetStretchy(row int, column int) {
-	g.lock.Lock()
-	defer g.lock.Unlock()
-
 	if g.created {
 		panic(fmt.Errorf("Grid.SetFilling() called after window create"))
 	}
@@ -101,10 +113,22 @@ func (g *Grid) SetStretchy(row int, column int) {
 	// don't set filling here in case we call SetStretchy() multiple times; the filling is committed in make() below
 }
 
-func (g *Grid) make(window *sysData) error {
-	g.lock.Lock()
-	defer g.lock.Unlock()
+// SetKeyboardNavigation lets the user move between the LineEdits of the Grid with the keyboard, as in a spreadsheet or the data entry programs of old.
+// If arrows is true, Up and Down move to the nearest LineEdit above or below in the same column, and Left and Right move to the nearest LineEdit to the left or right in the same row; Left and Right only do so when the text cursor is already at the start or end of the text (with nothing selected), so they can still be used to move within the text.
+// enter says what the Enter key does; see GridEnter.
+// Keys pressed with Shift, Ctrl, Alt, or Command held down are left alone, and so are keys that have no LineEdit to move to.
+// Only LineEdits take part: other Controls (such as a Combobox or Listbox, which use the arrow keys themselves) are skipped over and keep their keys, and LineEdits inside other Grids or Stacks placed in the Grid are not reached.
+// Moving to a LineEdit selects all of its text, so typing replaces it.
+// This function cannot be called after the Window that contains the Grid has been created.
+func (g *Grid) SetKeyboardNavigation(arrows bool, enter GridEnter) {
+	if g.created {
+		panic(fmt.Errorf("Grid.SetKeyboardNavigation() called after window create"))
+	}
+	g.navArrows = arrows
+	g.navEnter = enter
+}
 
+func (g *Grid) make(window *sysData) error {
 	// commit filling for the stretchy control now (see SetStretchy() above)
 	if g.stretchyrow != -1 && g.stretchycol != -1 {
 		g.filling[g.stretchyrow][g.stretchycol] = true
@@ -114,6 +138,14 @@ func (g *Grid) make(window *sysData) error {
 	}
 	for row, xcol := range g.controls {
 		for col, c := range xcol {
+			if l, ok := c.(*LineEdit); ok && (g.navArrows || g.navEnter != GridEnterDefault) {
+				// set before make() so the platform code knows to watch the LineEdit's keys
+				l.sysData.gridNav = &gridNav{
+					g:   g,
+					row: row,
+					col: col,
+				}
+			}
 			err := c.make(window)
 			if err != nil {
 				return fmt.Errorf("error adding control (%d,%d) to Grid: %v", row, col, err)
@@ -125,6 +157,7 @@ func (g *Grid) make(window *sysData) error {
 }
 
 func (g *Grid) allocate(x int, y int, width int, height int, d *sysSizeData) (allocations []*allocation) {
+	checkUIThread("Grid.allocate()")
 	max := func(a int, b int) int {
 		if a > b {
 			return a
@@ -135,16 +168,19 @@ func (g *Grid) allocate(x int, y int, width int, height int, d *sysSizeData) (al
 	var current *allocation		// for neighboring
 
 	// TODO return if nControls == 0?
+	lc := beginLayoutCheck(d, "Grid", x, y, width, height)
+	// reuse the slice from the last resize; see Stack.allocate()
+	allocations = g.allocations[:0]
 	// before we do anything, steal the margin so nested Stacks/Grids don't double down
 	xmargin := d.xmargin
 	ymargin := d.ymargin
 	d.xmargin = 0
 	d.ymargin = 0
 	// 0) inset the available rect by the margins and needed padding
-	x += xmargin
-	y += ymargin
-	width -= xmargin * 2
-	height -= ymargin * 2
+	x, width = layoutInset(x, width, xmargin)
+	y, height = layoutInset(y, height, ymargin)
+	endx := x + width // where the cells have to stop; see step 4
+	endy := y + height
 	width -= (len(g.colwidths) - 1) * d.xpadding
 	height -= (len(g.rowheights) - 1) * d.ypadding
 	// 1) clear data structures
@@ -165,6 +201,7 @@ func (g *Grid) allocate(x int, y int, width int, height int, d *sysSizeData) (al
 		}
 	}
 	// 3) handle the stretchy control
+	// there's only one, so it gets all the space that's left, and there's no remainder to lose as there is in Stack.allocate()
 	if g.stretchyrow != -1 && g.stretchycol != -1 {
 		for i, w := range g.colwidths {
 			if i != g.stretchycol {
@@ -176,10 +213,17 @@ func (g *Grid) allocate(x int, y int, width int, height int, d *sysSizeData) (al
 				height -= h
 			}
 		}
+		// if the other rows and columns take up more than there is, the stretchy ones get nothing, and the rest are cut off in step 4
+		if width < 0 {
+			width = 0
+		}
+		if height < 0 {
+			height = 0
+		}
 		g.colwidths[g.stretchycol] = width
 		g.rowheights[g.stretchyrow] = height
 	}
-	// 4) draw
+	// 4) draw, cutting off the cells that go past the right or bottom
 	startx := x
 	for row, xcol := range g.controls {
 		current = nil		// reset on new columns
@@ -190,8 +234,11 @@ func (g *Grid) allocate(x int, y int, width int, height int, d *sysSizeData) (al
 				w = g.colwidths[col]
 				h = g.rowheights[row]
 			}
-			as := c.allocate(x, y, w, h, d)
-			if current != nil {			// connect first left to first right
+			cx, w := layoutClip(x, w, endx)
+			cy, h := layoutClip(y, h, endy)
+			lc.child(d, row, col, cx, cy, w, h)
+			as := c.allocate(cx, cy, w, h, d)
+			if current != nil && len(as) != 0 {	// connect first left to first right, if there's anything to align with (see Stack.allocate())
 				current.neighbor = c
 			}
 			if len(as) != 0 {
@@ -205,12 +252,15 @@ func (g *Grid) allocate(x int, y int, width int, height int, d *sysSizeData) (al
 		x = startx
 		y += g.rowheights[row] + d.ypadding
 	}
+	lc.done(d, false, 0)
+	g.allocations = allocations
 	return
 }
 
 // filling and stretchy are ignored for preferred size calculation
 // We don't consider the margins here, but will need to if Window.SizeToFit() is ever made a thing.
 func (g *Grid) preferredSize(d *sysSizeData) (width int, height int) {
+	checkUIThread("Grid.preferredSize()")
 	max := func(a int, b int) int {
 		if a > b {
 			return a
@@ -254,3 +304,77 @@ func (g *Grid) commitResize(c *allocation, d *sysSizeData) {
 func (g *Grid) getAuxResizeInfo(d *sysSizeData) {
 	// this is to satisfy Control; nothing to do here
 }
+
+// gridNav is what the platform code of a LineEdit in a Grid with keyboard navigation uses to find out where a key takes the user
+type gridNav struct {
+	g   *Grid
+	row int
+	col int
+}
+
+type gridNavKey uint
+
+const (
+	gridNavUp gridNavKey = iota
+	gridNavDown
+	gridNavLeft
+	gridNavRight
+	gridNavEnter
+)
+
+// runs on the UI thread, when key is pressed (without modifiers) in the LineEdit; atStart and atEnd say where the text cursor is, and are both false if there is a selection
+// returns the sysData of the LineEdit to move to, or nil if the LineEdit should handle the key itself
+func (n *gridNav) next(key gridNavKey, atStart bool, atEnd bool) *sysData {
+	g := n.g
+	switch key {
+	case gridNavUp:
+		if g.navArrows {
+			return g.lineEditFrom(n.row-1, n.col, -1, 0, false)
+		}
+	case gridNavDown:
+		if g.navArrows {
+			return g.lineEditFrom(n.row+1, n.col, 1, 0, false)
+		}
+	case gridNavLeft:
+		if g.navArrows && atStart {
+			return g.lineEditFrom(n.row, n.col-1, 0, -1, false)
+		}
+	case gridNavRight:
+		if g.navArrows && atEnd {
+			return g.lineEditFrom(n.row, n.col+1, 0, 1, false)
+		}
+	case gridNavEnter:
+		switch g.navEnter {
+		case GridEnterDown:
+			return g.lineEditFrom(n.row+1, n.col, 1, 0, true)
+		case GridEnterRight:
+			return g.lineEditFrom(n.row, n.col+1, 0, 1, true)
+		}
+	}
+	return nil
+}
+
+// returns the first LineEdit from (row,col) on in the direction (drow,dcol), or nil if there isn't one
+// if wrap is true, the search carries on from the start of the next column (going down) or row (going right) instead of stopping at the edge of the Grid
+func (g *Grid) lineEditFrom(row int, col int, drow int, dcol int, wrap bool) *sysData {
+	for {
+		if row < 0 || row >= len(g.controls) || col < 0 || col >= len(g.controls[row]) {
+			if !wrap {
+				return nil
+			}
+			switch {
+			case drow > 0 && col+1 < len(g.controls[0]):
+				row, col = 0, col+1
+			case dcol > 0 && row+1 < len(g.controls):
+				row, col = row+1, 0
+			default: // past the last cell
+				return nil
+			}
+		}
+		if l, ok := g.controls[row][col].(*LineEdit); ok {
+			return l.sysData
+		}
+		row += drow
+		col += dcol
+	}
+}
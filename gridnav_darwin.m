@@ -0,0 +1,16 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSTextField.h>
+#import <AppKit/NSWindow.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSTextField(x) to(NSTextField, (x))
+
+// for Grid.SetKeyboardNavigation(); the app delegate gets the keys through control:textView:doCommandBySelector: (see delegateuitask_darwin.m and lineeditWatchKeys())
+
+// making an NSTextField first responder selects all its text
+void gridNavFocus(id lineedit)
+{
+	[[toNSTextField(lineedit) window] makeFirstResponder:lineedit];
+}
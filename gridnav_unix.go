@@ -0,0 +1,63 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern gboolean our_gridnav_key_press_event_callback(GtkWidget *, GdkEvent *, gpointer);
+// static inline GtkEditable *gtkEditable(GtkWidget *w)
+// {
+// 	return GTK_EDITABLE(w);
+// }
+import "C"
+
+// for Grid.SetKeyboardNavigation(), "key-press-event" is connected to the GtkEntry of each LineEdit in the Grid by sysData.make(); it runs before the GtkEntry sees the key, so returning TRUE there keeps the key from the GtkEntry (and Enter from "activate")
+
+//export our_gridnav_key_press_event_callback
+func our_gridnav_key_press_event_callback(widget *C.GtkWidget, event *C.GdkEvent, what C.gpointer) C.gboolean {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(what))
+	e := (*C.GdkEventKey)(unsafe.Pointer(event))
+	if e.state&C.guint(C.gtk_accelerator_get_default_mod_mask()) != 0 {
+		return C.FALSE // continue the event chain
+	}
+	var key gridNavKey
+	switch e.keyval {
+	case C.GDK_KEY_Up, C.GDK_KEY_KP_Up:
+		key = gridNavUp
+	case C.GDK_KEY_Down, C.GDK_KEY_KP_Down:
+		key = gridNavDown
+	case C.GDK_KEY_Left, C.GDK_KEY_KP_Left:
+		key = gridNavLeft
+	case C.GDK_KEY_Right, C.GDK_KEY_KP_Right:
+		key = gridNavRight
+	case C.GDK_KEY_Return, C.GDK_KEY_KP_Enter, C.GDK_KEY_ISO_Enter:
+		key = gridNavEnter
+	default:
+		return C.FALSE
+	}
+	atStart, atEnd := gEntryCaret(widget)
+	if next := s.gridNav.next(key, atStart, atEnd); next != nil {
+		// this selects all the text in the GtkEntry, unless the user turned gtk-entry-select-on-focus off
+		C.gtk_widget_grab_focus(next.widget)
+		return C.TRUE // handled
+	}
+	return C.FALSE
+}
+
+var gridnav_key_press_event_callback = C.GCallback(C.our_gridnav_key_press_event_callback)
+
+// see gridNav.next() for what atStart and atEnd are
+func gEntryCaret(widget *C.GtkWidget) (atStart bool, atEnd bool) {
+	editable := C.gtkEditable(widget)
+	if C.gtk_editable_get_selection_bounds(editable, nil, nil) != C.FALSE { // selection
+		return false, false
+	}
+	pos := C.gtk_editable_get_position(editable)
+	return pos == 0, pos == C.gint(C.gtk_entry_get_text_length(togtkentry(widget)))
+}
@@ -0,0 +1,141 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+/*
+For Grid.SetKeyboardNavigation(), the edit controls of LineEdits in the Grid are subclassed (like listboxes; see listbox_windows.go) so we see their WM_KEYDOWNs first.
+Edit controls already ask IsDialogMessage() (see msgloop()) for the arrow keys, but not for Enter, which IsDialogMessage() would otherwise take for the default button; so when Enter moves, we ask for it in WM_GETDLGCODE too.
+A single-line edit control beeps when it gets Enter as a WM_CHAR, so that is eaten as well.
+The sysData is the reference data of the subclass.
+*/
+
+var gridNavSubclassCallback uintptr
+
+func init() {
+	// not in the var declaration above, for the same reason as listboxSubclassCallback
+	gridNavSubclassCallback = syscall.NewCallback(gridNavSubclassProc)
+}
+
+// the lParam of WM_GETDLGCODE, if not NULL
+type _MSG struct {
+	hwnd    _HWND
+	message uint32
+	wParam  _WPARAM
+	lParam  _LPARAM
+	time    uint32
+	pt      _POINT
+}
+
+// runs on the UI thread; called by sysData.make()
+func (s *sysData) gridNavInit() {
+	r1, _, err := _setWindowSubclass.Call(
+		uintptr(s.hwnd),
+		gridNavSubclassCallback,
+		uintptr(0),
+		uintptr(unsafe.Pointer(s)))
+	if r1 == 0 { // failure
+		panic(s.newError("subclassing edit control for Grid keyboard navigation", "SetWindowSubclass()", err))
+	}
+}
+
+func gridNavSubclassProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM, id uintptr, data uintptr) _LRESULT {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(data))
+	enterMoves := s.gridNav.g.navEnter != GridEnterDefault
+	switch uMsg {
+	case _WM_GETDLGCODE:
+		r1, _, _ := _defSubclassProc.Call(
+			uintptr(hwnd),
+			uintptr(uMsg),
+			uintptr(wParam),
+			uintptr(lParam))
+		if lParam != 0 && enterMoves {
+			msg := (*_MSG)(unsafe.Pointer(lParam))
+			if msg.message == _WM_KEYDOWN && msg.wParam == _VK_RETURN {
+				r1 |= _DLGC_WANTALLKEYS
+			}
+		}
+		return _LRESULT(r1)
+	case _WM_KEYDOWN:
+		if getModifiers() != 0 {
+			break
+		}
+		var key gridNavKey
+		switch wParam {
+		case _VK_UP:
+			key = gridNavUp
+		case _VK_DOWN:
+			key = gridNavDown
+		case _VK_LEFT:
+			key = gridNavLeft
+		case _VK_RIGHT:
+			key = gridNavRight
+		case _VK_RETURN:
+			key = gridNavEnter
+		default:
+			return gridNavDefSubclassProc(hwnd, uMsg, wParam, lParam)
+		}
+		atStart, atEnd := s.editCaret()
+		if next := s.gridNav.next(key, atStart, atEnd); next != nil {
+			next.gridNavFocus()
+			return 0
+		}
+	case _WM_CHAR:
+		if wParam == '\r' && enterMoves {
+			return 0
+		}
+	case _WM_NCDESTROY:
+		// this is the last message the edit control gets; the subclass has to be removed before the window is gone
+		_removeWindowSubclass.Call(
+			uintptr(hwnd),
+			gridNavSubclassCallback,
+			id)
+	}
+	return gridNavDefSubclassProc(hwnd, uMsg, wParam, lParam)
+}
+
+func gridNavDefSubclassProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM) _LRESULT {
+	r1, _, _ := _defSubclassProc.Call(
+		uintptr(hwnd),
+		uintptr(uMsg),
+		uintptr(wParam),
+		uintptr(lParam))
+	return _LRESULT(r1)
+}
+
+// runs on the UI thread; see gridNav.next() for what atStart and atEnd are
+func (s *sysData) editCaret() (atStart bool, atEnd bool) {
+	var start, end uint32
+
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_EM_GETSEL),
+		uintptr(unsafe.Pointer(&start)),
+		uintptr(unsafe.Pointer(&end)))
+	if start != end { // selection
+		return false, false
+	}
+	r1, _, _ := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_WM_GETTEXTLENGTH),
+		uintptr(0),
+		uintptr(0))
+	return start == 0, uintptr(end) == r1
+}
+
+// runs on the UI thread
+func (s *sysData) gridNavFocus() {
+	// don't bother checking SetFocus()'s error; see stdWndProc()
+	_setFocus.Call(uintptr(s.hwnd))
+	// unlike GTK+ and Cocoa, Windows doesn't select the text of an edit control given the focus with SetFocus() (only with the Tab key)
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_EM_SETSEL),
+		uintptr(0),
+		negConst(-1))
+}
@@ -0,0 +1,135 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A GroupBox is a Control that draws a frame with a title around another Control, usually a Stack or Grid, to show that the controls in it belong together.
+// The Control is laid out inside the frame the way a Window lays out its Control, inset from the frame by the padding between controls (see Window.SetSpaced).
+// The preferred size of a GroupBox is that of its Control plus the frame, or the size of the title if that is bigger.
+type GroupBox struct {
+	lock     sync.Mutex
+	created  bool
+	sysData  *sysData
+	initText string
+	control  Control
+
+	allocations []*allocation // belongs to the UI thread
+}
+
+// NewGroupBox creates a new GroupBox with the given title around the given Control.
+// As with Layout(), a nil Control leaves the GroupBox empty.
+func NewGroupBox(title string, control Control) *GroupBox {
+	if control == nil {
+		control = Space()
+	}
+	return &GroupBox{
+		sysData:  mksysdata(c_groupbox),
+		initText: title,
+		control:  control,
+	}
+}
+
+// SetTitle sets the GroupBox's title.
+func (g *GroupBox) SetTitle(title string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.created {
+		g.sysData.setText(title)
+		return
+	}
+	g.initText = title
+}
+
+// Title returns the GroupBox's title.
+func (g *GroupBox) Title() string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.created {
+		return g.sysData.text()
+	}
+	return g.initText
+}
+
+func (g *GroupBox) make(window *sysData) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.sysData.control = g
+	err := g.sysData.make(window)
+	if err != nil {
+		return err
+	}
+	g.sysData.setText(g.initText)
+	// the controls in the GroupBox are made in the Window like any others, over the frame, as with Tab
+	err = g.control.make(window)
+	if err != nil {
+		return fmt.Errorf("error adding control to GroupBox: %v", err)
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		g.sysData.placeBelow(appendControls(nil, g.control))
+		ret <- struct{}{}
+	})
+	<-ret
+	g.created = true
+	return nil
+}
+
+func (g *GroupBox) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	checkUIThread("GroupBox.allocate()")
+	if g.sysData.destroyed { // along with its Control; see DestroyControl()
+		return nil
+	}
+	lc := beginLayoutCheck(d, "GroupBox", x, y, width, height)
+	// as with Stack, steal the margin so our control doesn't get it too
+	xmargin := d.xmargin
+	ymargin := d.ymargin
+	d.xmargin = 0
+	d.ymargin = 0
+	x, width = layoutInset(x, width, xmargin)
+	y, height = layoutInset(y, height, ymargin)
+	// reuse the slice from the last resize; see Stack.allocate()
+	allocations := append(g.allocations[:0], g.sysData.singleAllocation(x, y, width, height, g)...)
+	left, top, right, bottom := g.sysData.groupBoxInsets(d)
+	cx, cwidth := layoutInset(x+left, width-left-right, d.xpadding)
+	cy, cheight := layoutInset(y+top, height-top-bottom, d.ypadding)
+	lc.child(d, 0, -1, cx, cy, cwidth, cheight)
+	allocations = append(allocations, g.control.allocate(cx, cy, cwidth, cheight, d)...)
+	lc.done(d, false, 0)
+	g.allocations = allocations
+	return allocations
+}
+
+func (g *GroupBox) preferredSize(d *sysSizeData) (width int, height int) {
+	checkUIThread("GroupBox.preferredSize()")
+	if g.sysData.destroyed {
+		return 0, 0
+	}
+	width, height = g.control.preferredSize(d)
+	left, top, right, bottom := g.sysData.groupBoxInsets(d)
+	width += left + right + 2*d.xpadding
+	height += top + bottom + 2*d.ypadding
+	w, h := g.sysData.preferredSize(d)
+	if width < w {
+		width = w
+	}
+	if height < h {
+		height = h
+	}
+	return width, height
+}
+
+func (g *GroupBox) commitResize(a *allocation, d *sysSizeData) {
+	g.sysData.commitResize(a, d)
+}
+
+func (g *GroupBox) getAuxResizeInfo(d *sysSizeData) {
+	g.sysData.getAuxResizeInfo(d)
+}
@@ -0,0 +1,27 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+/*
+On Mac OS X, a GroupBox is an NSBox whose content view is left empty; the GroupBox's control is in the window's content view like all the others, added after the NSBox so it is drawn over it, as with Tab (see tab_darwin.go).
+*/
+
+func makeGroupBox(parentWindow C.id, alternate bool, s *sysData) C.id {
+	box := C.makeGroupBox()
+	addControl(parentWindow, box)
+	return box
+}
+
+func (s *sysData) groupBoxInsets(d *sysSizeData) (left int, top int, right int, bottom int) {
+	r := C.groupBoxInsets(s.id)
+	return int(r.x), int(r.y), int(r.width), int(r.height)
+}
+
+// the preferred size of an NSBox is the border around its title; the control is added in GroupBox.preferredSize()
+func groupBoxPrefSize(control C.id) (width int, height int) {
+	r := C.groupBoxPrefSize(control)
+	return int(r.width), int(r.height)
+}
@@ -0,0 +1,70 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSBox.h>
+#import <AppKit/NSCell.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSBox(x) to(NSBox, (x))
+
+extern NSRect dummyRect;
+
+id makeGroupBox(void)
+{
+	NSBox *box;
+
+	box = [[NSBox alloc]
+		initWithFrame:dummyRect];
+	[box setBoxType:NSBoxPrimary];
+	[box setTitlePosition:NSNoTitle];
+	return box;
+}
+
+void groupBoxSetTitle(id box, id title)
+{
+	// otherwise NSBox leaves a gap in the border for the empty title
+	if ([title length] == 0) {
+		[toNSBox(box) setTitlePosition:NSNoTitle];
+		[toNSBox(box) setTitle:title];
+		return;
+	}
+	[toNSBox(box) setTitle:title];
+	[toNSBox(box) setTitlePosition:NSAtTop];
+}
+
+id groupBoxTitle(id box)
+{
+	return [toNSBox(box) title];
+}
+
+// the space between the edges of the box and its content view, which NSBox keeps up to date as the box is resized; NSBox isn't flipped, so the title is at the top of the bounds
+struct xrect groupBoxInsets(id box)
+{
+	NSRect bounds, content;
+	struct xrect r;
+
+	bounds = [toNSBox(box) bounds];
+	content = [[toNSBox(box) contentView] frame];
+	r.x = (intptr_t) (content.origin.x - bounds.origin.x);		// left
+	r.width = (intptr_t) ((bounds.origin.x + bounds.size.width) - (content.origin.x + content.size.width));		// right
+	r.y = (intptr_t) ((bounds.origin.y + bounds.size.height) - (content.origin.y + content.size.height));		// top
+	r.height = (intptr_t) (content.origin.y - bounds.origin.y);		// bottom
+	return r;
+}
+
+// NSBox has no minimum size of its own; this fits the title
+struct xsize groupBoxPrefSize(id box)
+{
+	struct xrect insets;
+	NSSize title;
+	struct xsize s;
+
+	insets = groupBoxInsets(box);
+	s.width = (intptr_t) (insets.x + insets.width);
+	s.height = (intptr_t) (insets.y + insets.height);
+	if ([toNSBox(box) titlePosition] != NSNoTitle) {
+		title = [[toNSBox(box) titleCell] cellSize];
+		s.width += (intptr_t) title.width;
+	}
+	return s;
+}
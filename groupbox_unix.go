@@ -0,0 +1,57 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+import "C"
+
+/*
+On GTK+, a GroupBox is an empty GtkFrame; the GroupBox's control is in the window's GtkLayout like all the others, added after the GtkFrame so it is drawn over it, as with Tab (see tab_unix.go).
+*/
+
+func togtkframe(what *C.GtkWidget) *C.GtkFrame {
+	return (*C.GtkFrame)(unsafe.Pointer(what))
+}
+
+func gtkGroupBoxNew() *C.GtkWidget {
+	return C.gtk_frame_new(nil)
+}
+
+func gtkGroupBoxSetText(widget *C.GtkWidget, text string) {
+	if text == "" {
+		// otherwise the GtkFrame leaves a gap in the frame for the empty label
+		C.gtk_frame_set_label(togtkframe(widget), nil)
+		return
+	}
+	ctext := C.CString(text)
+	defer C.free(unsafe.Pointer(ctext))
+	C.gtk_frame_set_label(togtkframe(widget), togstr(ctext))
+}
+
+func gtkGroupBoxText(widget *C.GtkWidget) string {
+	// this is NULL if there is no label; fromgstr() gives us the empty string then
+	return fromgstr(C.gtk_frame_get_label(togtkframe(widget)))
+}
+
+// since the GtkFrame is empty, its natural size (see sysPreferredSize()) is the frame and the label, as with the GtkNotebook of a Tab
+// GtkFrame puts its style border and padding between the frame and its child, so that is the size of the frame on the three sides without the label
+func (s *sysData) groupBoxInsets(d *sysSizeData) (left int, top int, right int, bottom int) {
+	var border, padding C.GtkBorder
+
+	style := C.gtk_widget_get_style_context(s.widget)
+	C.gtk_style_context_get_border(style, C.GTK_STATE_FLAG_NORMAL, &border)
+	C.gtk_style_context_get_padding(style, C.GTK_STATE_FLAG_NORMAL, &padding)
+	width := int(C.gtk_container_get_border_width((*C.GtkContainer)(unsafe.Pointer(s.widget))))
+	left = int(border.left) + int(padding.left) + width
+	right = int(border.right) + int(padding.right) + width
+	bottom = int(border.bottom) + int(padding.bottom) + width
+	_, _, _, height := gtk_widget_get_preferred_size(s.widget)
+	top = height - bottom
+	return left, top, right, bottom
+}
@@ -0,0 +1,29 @@
+// 9 july 2014
+
+package ui
+
+/*
+On Windows, a GroupBox is a BUTTON with BS_GROUPBOX, which only draws the frame and the title; the GroupBox's control is made in the window like all the others and placed over it, as with Tab (see tab_windows.go).
+Group boxes can't get the keyboard focus, so it isn't a tab stop.
+*/
+
+// group boxes don't say how much space their frame takes, so go by the guidelines: 11 dialog units between the top of the group box and the first control (the caption is 8 of those), and 7 on the other sides
+// GroupBox.allocate() adds the padding (4 dialog units) to these, so leave it out; what's left still clears the caption and the frame in Windows that aren't spaced
+func (s *sysData) groupBoxInsets(d *sysSizeData) (left int, top int, right int, bottom int) {
+	left = muldiv(3, d.baseX, 4)
+	top = muldiv(8, d.baseY, 8)
+	right = muldiv(3, d.baseX, 4)
+	bottom = muldiv(3, d.baseY, 8)
+	return left, top, right, bottom
+}
+
+// the preferred size of a group box is the frame around its title; the control is added in GroupBox.preferredSize()
+// this is called by sysPreferredSize()
+func (s *sysData) groupBoxPreferredSize(d *sysSizeData) (width int, height int) {
+	left, top, right, bottom := s.groupBoxInsets(d)
+	if text := s.doText(); text != "" {
+		// the title starts the width of a character in from the frame, and has that much space after it
+		width = s.textWidth(text) + 2*d.baseX
+	}
+	return left + width + right, top + bottom
+}
@@ -6,7 +6,7 @@
 package ui
 
 import (
-	"fmt"
+	"errors"
 	"unsafe"
 )
 
@@ -39,6 +39,16 @@ thanks tristan in irc.gimp.net/#gtk+
 }
 `)
 
+// GErrors carry their own code and message
+func newGError(op string, call string, err *C.GError) *Error {
+	return &Error{
+		Op:   op,
+		Call: call,
+		Code: uintptr(err.code),
+		Err:  errors.New(fromgstr(err.message)),
+	}
+}
+
 func gtk_init() error {
 	var err *C.GError = nil // redundant in Go, but let's explicitly assign it anyway
 
@@ -46,13 +56,13 @@ func gtk_init() error {
 	// don't worry about GTK+'s command-line arguments; they're also available as environment variables (thanks mclasen in irc.gimp.net/#gtk+)
 	result := C.gtk_init_with_args(nil, nil, nil, nil, nil, &err)
 	if result == C.FALSE {
-		return fmt.Errorf("error actually initilaizing GTK+: %s", fromgstr(err.message))
+		return newGError("actually initializing GTK+", "gtk_init_with_args()", err)
 	}
 
 	// now apply our custom program-global styles
 	provider := C.gtk_css_provider_new()
 	if C.gtk_css_provider_load_from_data(provider, (*C.gchar)(unsafe.Pointer(&gtkStyles[0])), C.gssize(len(gtkStyles)), &err) == C.FALSE {
-		return fmt.Errorf("error applying package ui's custom program-global styles to GTK+: %v", fromgstr(err.message))
+		return newGError("applying package ui's custom program-global styles to GTK+", "gtk_css_provider_load_from_data()", err)
 	}
 	// GDK (at least as far back as GTK+ 3.4, but officially documented as of 3.10) merges all screens into one big one, so we don't need to worry about multimonitor
 	// thanks to baedert and mclasen in irc.gimp.net/#gtk+
@@ -112,6 +122,31 @@ func gtkNewWindowLayout() *C.GtkWidget {
 	return scrollarea
 }
 
+// the layout goes in a vertical box so the menu bar can go above it; see gtkWindowBarsHeight()
+func gtkNewWindowBox(layout *C.GtkWidget) *C.GtkWidget {
+	box := C.gtk_box_new(C.GTK_ORIENTATION_VERTICAL, 0)
+	C.gtk_box_pack_start((*C.GtkBox)(unsafe.Pointer(box)), layout, C.TRUE, C.TRUE, 0)
+	return box
+}
+
+// the height of everything in the window's box other than its layout, such as the menu bar; the layout gets the rest of the window
+func gtkWindowBarsHeight(window *C.GtkWidget, layout *C.GtkWidget) (height int) {
+	box := C.gtk_bin_get_child((*C.GtkBin)(unsafe.Pointer(window)))
+	children := C.gtk_container_get_children((*C.GtkContainer)(unsafe.Pointer(box)))
+	defer C.g_list_free(children)
+	for l := children; l != nil; l = l.next {
+		child := (*C.GtkWidget)(unsafe.Pointer(l.data))
+		if child == layout || C.gtk_widget_get_visible(child) == C.FALSE {
+			continue
+		}
+		var natural C.gint
+
+		C.gtk_widget_get_preferred_height(child, nil, &natural)
+		height += int(natural)
+	}
+	return height
+}
+
 func gtk_container_add(container *C.GtkWidget, widget *C.GtkWidget) {
 	C.gtk_container_add(togtkcontainer(container), widget)
 }
@@ -160,6 +195,7 @@ func gtk_toggle_button_set_active(widget *C.GtkWidget, checked bool) {
 func gtk_combo_box_text_new() *C.GtkWidget {
 	w := C.gtk_combo_box_text_new()
 	C.gtkSetComboBoxArbitrarilyResizeable(w)
+	gtkComboBoxInitSpecial(w)
 	return w
 }
 
@@ -194,6 +230,21 @@ func gtk_combo_box_get_active(widget *C.GtkWidget) int {
 	return int(C.gtk_combo_box_get_active(cb))
 }
 
+// the setSel of a Combobox; there's at most one index
+func gtkComboBoxSelect(widget *C.GtkWidget, indices []int) {
+	cb := (*C.GtkComboBox)(unsafe.Pointer(widget))
+	if len(indices) != 0 {
+		// this also replaces the text of the entry, if any
+		C.gtk_combo_box_set_active(cb, C.gint(indices[0]))
+		return
+	}
+	C.gtk_combo_box_set_active(cb, -1)
+	// but unselecting leaves the entry text alone, so clear it ourselves to match the other platforms
+	if fromgbool(C.gtk_combo_box_get_has_entry(cb)) {
+		gtk_entry_set_text(C.gtk_bin_get_child((*C.GtkBin)(unsafe.Pointer(widget))), "")
+	}
+}
+
 func gtk_combo_box_text_remove(widget *C.GtkWidget, index int) {
 	C.gtk_combo_box_text_remove(togtkcombobox(widget), C.gint(index))
 }
@@ -215,6 +266,7 @@ func gtk_entry_new() *C.GtkWidget {
 
 func gtkPasswordEntryNew() *C.GtkWidget {
 	e := gtk_entry_new()
+	// with visibility off, GtkEntry shows the Caps Lock warning by itself (the caps-lock-warning property, which is on by default); the edit control (with ES_PASSWORD) and NSSecureTextField do the same
 	C.gtk_entry_set_visibility(togtkentry(e), C.FALSE)
 	return e
 }
@@ -268,6 +320,52 @@ func gtk_label_get_text(widget *C.GtkWidget) string {
 	return fromgstr(C.gtk_label_get_text(togtklabel(widget)))
 }
 
+var labelEllipsizeModes = map[Ellipsize]C.PangoEllipsizeMode{
+	EllipsizeNone:   C.PANGO_ELLIPSIZE_NONE,
+	EllipsizeStart:  C.PANGO_ELLIPSIZE_START,
+	EllipsizeMiddle: C.PANGO_ELLIPSIZE_MIDDLE,
+	EllipsizeEnd:    C.PANGO_ELLIPSIZE_END,
+}
+
+func gtk_label_set_ellipsize(widget *C.GtkWidget, e Ellipsize) {
+	C.gtk_label_set_ellipsize(togtklabel(widget), labelEllipsizeModes[e])
+}
+
+func gtk_label_set_selectable(widget *C.GtkWidget, selectable bool) {
+	C.gtk_label_set_selectable(togtklabel(widget), togbool(selectable))
+}
+
+// this also sets up the ATK labelled-by relation, which is what we're really after (see Label.SetBuddy())
+func gtk_label_set_mnemonic_widget(widget *C.GtkWidget, buddy *C.GtkWidget) {
+	C.gtk_label_set_mnemonic_widget(togtklabel(widget), buddy)
+}
+
+// the average character width GtkEntry uses for width-chars; see sysData.sysPreferredSize()
+func gtkCharWidth(widget *C.GtkWidget) int {
+	ctx := C.gtk_widget_get_pango_context(widget)
+	metrics := C.pango_context_get_metrics(ctx,
+		C.pango_context_get_font_description(ctx),
+		C.pango_context_get_language(ctx))
+	defer C.pango_font_metrics_unref(metrics)
+	width := C.pango_font_metrics_get_approximate_char_width(metrics)
+	if digit := C.pango_font_metrics_get_approximate_digit_width(metrics); digit > width {
+		width = digit
+	}
+	return int((width + C.PANGO_SCALE - 1) / C.PANGO_SCALE) // PANGO_PIXELS_CEIL(), which is a macro cgo can't call
+}
+
+// the width of text in widget's font; see sysData.sysPreferredSize()
+func gtkTextWidth(widget *C.GtkWidget, text string) int {
+	var width C.int
+
+	ctext := C.CString(text)
+	defer C.free(unsafe.Pointer(ctext))
+	layout := C.gtk_widget_create_pango_layout(widget, togstr(ctext))
+	defer C.g_object_unref(C.gpointer(unsafe.Pointer(layout)))
+	C.pango_layout_get_pixel_size(layout, &width, nil)
+	return int(width)
+}
+
 func gtk_widget_get_preferred_size(widget *C.GtkWidget) (minWidth int, minHeight int, natWidth int, natHeight int) {
 	var minimum, natural C.GtkRequisition
 
@@ -285,6 +383,14 @@ func gtk_progress_bar_set_fraction(w *C.GtkWidget, percent int) {
 	C.gtk_progress_bar_set_fraction(togtkprogressbar(w), p)
 }
 
+// an empty string hides the text instead of showing an empty box
+func gtk_progress_bar_set_text(w *C.GtkWidget, text string) {
+	ctext := C.CString(text)
+	defer C.free(unsafe.Pointer(ctext))
+	C.gtk_progress_bar_set_text(togtkprogressbar(w), togstr(ctext))
+	C.gtk_progress_bar_set_show_text(togtkprogressbar(w), togbool(text != ""))
+}
+
 func gtk_progress_bar_pulse(w *C.GtkWidget) {
 	C.gtk_progress_bar_pulse(togtkprogressbar(w))
 }
@@ -0,0 +1,79 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image/color"
+	"sync"
+)
+
+// HighContrastChanged is signaled when the user turns high contrast on or off; see HighContrast.
+var HighContrastChanged chan struct{}
+
+func init() {
+	// like AppQuit
+	HighContrastChanged = newEvent()
+}
+
+// HighContrast returns whether the user has asked the system for high contrast: a high contrast theme on Windows (Ease of Access) and GTK+ (GNOME's HighContrast themes), or Increase Contrast on Mac OS X 10.10 and newer (older versions have no such setting, so HighContrast is always false there).
+// The system's own controls follow the setting by themselves, and so do the things package ui draws itself, such as LevelMeters and the badges of Buttons: in high contrast, they are drawn in the system's colors instead of their own.
+// An Area is drawn by your AreaHandler, so it's up to you: if HighContrast is true, draw with as few colors as you can, don't tell things apart by color alone, and repaint the Area (see Area.RepaintAll) on HighContrastChanged.
+// HighContrast can be called from any goroutine, including from the methods of an AreaHandler, which run on the UI thread.
+//
+// On Windows, package ui hears about the change through its Windows, so HighContrast and HighContrastChanged only follow it while at least one Window exists.
+func HighContrast() bool {
+	highContrastLock.Lock()
+	known := highContrast.known
+	on := highContrast.on
+	highContrastLock.Unlock()
+	if !known { // package ui isn't set up yet; this sets it up
+		ret := make(chan struct{})
+		defer close(ret)
+		uitask(func() {
+			ret <- struct{}{}
+		})
+		<-ret
+		return HighContrast()
+	}
+	return on
+}
+
+// the system's colors, for what package ui draws in Go in high contrast; see LevelMeter.Paint()
+// each sysHighContrastColors() gets them from the system's settings or theme
+type highContrastColors struct {
+	background    color.RGBA // of text fields and lists
+	text          color.RGBA // on background
+	highlight     color.RGBA // of selected text
+	highlightText color.RGBA // on highlight
+}
+
+var (
+	highContrastLock sync.Mutex
+	highContrast     struct {
+		known  bool // set by the first highContrastUpdate(), which uiinit() calls
+		on     bool
+		colors highContrastColors
+	}
+)
+
+// called on the UI thread by uiinit() and whenever the system says the setting (or the colors) may have changed; the systems tell us about more than that, so only actual changes are signaled
+func highContrastUpdate() {
+	on := sysHighContrast()
+	colors := sysHighContrastColors()
+	highContrastLock.Lock()
+	changed := highContrast.known && on != highContrast.on
+	highContrast.known = true
+	highContrast.on = on
+	highContrast.colors = colors
+	highContrastLock.Unlock()
+	if changed {
+		sendEvent(HighContrastChanged)
+	}
+}
+
+// for package ui's own drawing: whether to use the system's colors, and which they are
+func currentHighContrast() (on bool, colors highContrastColors) {
+	highContrastLock.Lock()
+	defer highContrastLock.Unlock()
+	return highContrast.on, highContrast.colors
+}
@@ -0,0 +1,32 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image/color"
+)
+
+// #include "objc_darwin.h"
+import "C"
+
+func sysHighContrast() bool {
+	return C.highContrast() != C.NO
+}
+
+func sysHighContrastColors() highContrastColors {
+	get := func(which int) color.RGBA {
+		c := C.highContrastColorRGB(C.intptr_t(which))
+		return color.RGBA{
+			R: uint8(c >> 16),
+			G: uint8(c >> 8),
+			B: uint8(c),
+			A: 0xFF,
+		}
+	}
+	return highContrastColors{
+		background:    get(0),
+		text:          get(1),
+		highlight:     get(2),
+		highlightText: get(3),
+	}
+}
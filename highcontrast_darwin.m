@@ -0,0 +1,62 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSWorkspace.h>
+#import <AppKit/NSColor.h>
+#import <AppKit/NSColorSpace.h>
+#import <Foundation/NSNotification.h>
+
+/*
+Increase Contrast is new in 10.10, so neither -[NSWorkspace accessibilityDisplayShouldIncreaseContrast] nor the notification for it are in the headers we build with (see objc_darwin.h); we ask if the workspace has the method before calling it, and name the notification ourselves.
+The notification is posted to the workspace's notification center, not the default one; the app delegate gets it as highContrastChanged:.
+*/
+
+#define increaseContrastNotification @"NSWorkspaceAccessibilityDisplayOptionsDidChangeNotification"
+
+BOOL highContrast(void)
+{
+	NSWorkspace *ws;
+	SEL sel;
+
+	ws = [NSWorkspace sharedWorkspace];
+	sel = @selector(accessibilityDisplayShouldIncreaseContrast);
+	if (![ws respondsToSelector:sel])		// before 10.10
+		return NO;
+	return ((BOOL (*)(id, SEL)) objc_msgSend)(ws, sel);
+}
+
+// called once, by initCocoa()
+void highContrastWatch(id delegate)
+{
+	[[[NSWorkspace sharedWorkspace] notificationCenter] addObserver:delegate
+		selector:@selector(highContrastChanged:)
+		name:increaseContrastNotification
+		object:nil];
+}
+
+// in the order of the fields of highContrastColors
+static NSColor *highContrastColor(intptr_t which)
+{
+	switch (which) {
+	case 1:
+		return [NSColor textColor];
+	case 2:
+		return [NSColor selectedTextBackgroundColor];
+	case 3:
+		return [NSColor selectedTextColor];
+	}
+	return [NSColor textBackgroundColor];
+}
+
+// as 0xRRGGBB; the system colors can be patterns and such, so they're converted first
+uint32_t highContrastColorRGB(intptr_t which)
+{
+	NSColor *c;
+	CGFloat r, g, b, a;
+
+	c = [highContrastColor(which) colorUsingColorSpaceName:NSCalibratedRGBColorSpace];
+	if (c == nil)		// can't be converted; black on white it is
+		return (which == 0 || which == 3) ? 0xFFFFFF : 0x000000;
+	[c getRed:&r green:&g blue:&b alpha:&a];
+	return ((uint32_t) (r * 255) << 16) | ((uint32_t) (g * 255) << 8) | (uint32_t) (b * 255);
+}
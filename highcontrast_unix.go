@@ -0,0 +1,78 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"image/color"
+	"strings"
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern void our_highcontrast_theme_callback(GObject *, GParamSpec *, gpointer);
+// /* g_object_get() is variadic, which cgo can't call */
+// static inline gchar *gtkThemeName(void)
+// {
+// 	gchar *name = NULL;
+//
+// 	g_object_get(gtk_settings_get_default(), "gtk-theme-name", &name, NULL);
+// 	return name;
+// }
+import "C"
+
+/*
+GTK+ has no high contrast setting of its own; GNOME's is a theme, HighContrast (or HighContrastInverse), so we go by the name of the theme, which GtkSettings tells us about with "notify::gtk-theme-name" when it changes; it's connected by uiinit().
+The colors come from the named colors every theme defines for GTK+ 3 (the @theme_base_color and friends of its CSS), looked up on the style context of a throwaway GtkEntry.
+*/
+
+func sysHighContrast() bool {
+	name := C.gtkThemeName()
+	if name == nil {
+		return false
+	}
+	defer C.g_free(C.gpointer(unsafe.Pointer(name)))
+	return strings.Contains(strings.ToLower(fromgstr(name)), "highcontrast")
+}
+
+func sysHighContrastColors() highContrastColors {
+	entry := C.gtk_entry_new()
+	C.g_object_ref_sink(C.gpointer(unsafe.Pointer(entry)))
+	defer C.g_object_unref(C.gpointer(unsafe.Pointer(entry)))
+	context := C.gtk_widget_get_style_context(entry)
+	lookup := func(name string, def color.RGBA) color.RGBA {
+		var c C.GdkRGBA
+
+		cname := C.CString(name)
+		defer C.free(unsafe.Pointer(cname))
+		if C.gtk_style_context_lookup_color(context, togstr(cname), &c) == C.FALSE {
+			return def // not defined by this theme
+		}
+		return color.RGBA{
+			R: uint8(c.red * 0xFF),
+			G: uint8(c.green * 0xFF),
+			B: uint8(c.blue * 0xFF),
+			A: 0xFF,
+		}
+	}
+	return highContrastColors{
+		background:    lookup("theme_base_color", color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}),
+		text:          lookup("theme_text_color", color.RGBA{0, 0, 0, 0xFF}),
+		highlight:     lookup("theme_selected_bg_color", color.RGBA{0, 0, 0, 0xFF}),
+		highlightText: lookup("theme_selected_fg_color", color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}),
+	}
+}
+
+// called by uiinit()
+func highContrastWatch() {
+	g_signal_connect_pointer((*C.GtkWidget)(unsafe.Pointer(C.gtk_settings_get_default())), "notify::gtk-theme-name", highcontrast_theme_callback, nil)
+}
+
+//export our_highcontrast_theme_callback
+func our_highcontrast_theme_callback(object *C.GObject, pspec *C.GParamSpec, what C.gpointer) {
+	defer recoverUIPanic()
+	highContrastUpdate()
+}
+
+var highcontrast_theme_callback = C.GCallback(C.our_highcontrast_theme_callback)
@@ -0,0 +1,83 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image/color"
+	"unsafe"
+)
+
+/*
+Windows tells every top-level window about high contrast being turned on or off with WM_SETTINGCHANGE (wParam SPI_SETHIGHCONTRAST), and about the colors of the new theme with WM_SYSCOLORCHANGE; but the message-only window of uitask (see uitask_windows.go) isn't top-level, so it gets neither, and our Windows have to pass them on to highContrastUpdate() (see stdWndProc()).
+The common controls in a Window also need WM_SYSCOLORCHANGE passed on to them, or they keep drawing with the old colors.
+*/
+
+type _HIGHCONTRAST struct {
+	cbSize            uint32
+	dwFlags           uint32
+	lpszDefaultScheme *uint16
+}
+
+var (
+	_getSysColor = user32.NewProc("GetSysColor")
+)
+
+func sysHighContrast() bool {
+	var hc _HIGHCONTRAST
+
+	hc.cbSize = uint32(unsafe.Sizeof(hc))
+	r1, _, err := _systemParametersInfo.Call(
+		uintptr(_SPI_GETHIGHCONTRAST),
+		uintptr(unsafe.Sizeof(hc)),
+		uintptr(unsafe.Pointer(&hc)),
+		0)
+	if r1 == 0 { // failure; not fatal, we just draw our usual colors
+		reportError(newError(nil, "getting high contrast setting", "SystemParametersInfo()", err))
+		return false
+	}
+	return hc.dwFlags&_HCF_HIGHCONTRASTON != 0
+}
+
+// returns a COLORREF (0x00BBGGRR); GetSysColor() can't fail for the COLOR_xxx constants we use
+func sysColor(index uintptr) uintptr {
+	r1, _, _ := _getSysColor.Call(index)
+	return r1
+}
+
+func colorrefToRGBA(c uintptr) color.RGBA {
+	return color.RGBA{
+		R: uint8(c),
+		G: uint8(c >> 8),
+		B: uint8(c >> 16),
+		A: 0xFF,
+	}
+}
+
+func sysHighContrastColors() highContrastColors {
+	return highContrastColors{
+		background:    colorrefToRGBA(sysColor(_COLOR_WINDOW)),
+		text:          colorrefToRGBA(sysColor(_COLOR_WINDOWTEXT)),
+		highlight:     colorrefToRGBA(sysColor(_COLOR_HIGHLIGHT)),
+		highlightText: colorrefToRGBA(sysColor(_COLOR_HIGHLIGHTTEXT)),
+	}
+}
+
+// runs on the UI thread; called by stdWndProc() for WM_SETTINGCHANGE and WM_SYSCOLORCHANGE
+func (s *sysData) highContrastSettingChange(uMsg uint32, wParam _WPARAM, lParam _LPARAM) {
+	if uMsg == _WM_SYSCOLORCHANGE {
+		s.childrenLock.Lock()
+		children := make([]_HWND, 0, len(s.children))
+		for _, ss := range s.children {
+			children = append(children, ss.hwnd)
+		}
+		s.childrenLock.Unlock()
+		for _, hwnd := range children {
+			_sendMessage.Call(
+				uintptr(hwnd),
+				uintptr(uMsg),
+				uintptr(wParam),
+				uintptr(lParam))
+		}
+	}
+	highContrastUpdate()
+}
@@ -0,0 +1,126 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// A HistoryStore keeps the history of a LineEdit between runs of your program; see LineEdit.SetHistory().
+// Load and Save are called on the goroutine that called SetHistory or AddToHistory, never on the UI thread, so they can take as long as they need.
+type HistoryStore interface {
+	// Load returns the saved entries, oldest first.
+	// It is called once, by LineEdit.SetHistory().
+	Load() (entries []string, err error)
+
+	// Save is given all the entries, oldest first, each time an entry is added with LineEdit.AddToHistory().
+	Save(entries []string) error
+}
+
+// NewHistoryFile returns a HistoryStore that keeps the entries in the named file, one per line.
+// The file doesn't have to exist yet; the history starts out empty if it doesn't.
+func NewHistoryFile(filename string) HistoryStore {
+	return historyFile(filename)
+}
+
+type historyFile string
+
+func (h historyFile) Load() (entries []string, err error) {
+	f, err := os.Open(string(h))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entries = append(entries, scanner.Text())
+	}
+	return entries, scanner.Err()
+}
+
+func (h historyFile) Save(entries []string) error {
+	var data string
+
+	if len(entries) != 0 {
+		data = strings.Join(entries, "\n") + "\n"
+	}
+	return ioutil.WriteFile(string(h), []byte(data), 0600)
+}
+
+// history is shared between the LineEdit and its platform code, which calls history.move() on the UI thread when the user presses Up or Down
+type history struct {
+	lock    sync.Mutex
+	entries []string
+	max     int
+	store   HistoryStore
+	pos     int    // where the user is in entries; len(entries) means they're not going through the history
+	typed   string // what the user had typed before pressing Up, to go back to with Down
+}
+
+// entries is owned by the history after this
+func newHistory(max int, store HistoryStore, entries []string) *history {
+	h := &history{
+		max:   max,
+		store: store,
+	}
+	h.entries = h.trim(entries)
+	h.pos = len(h.entries)
+	return h
+}
+
+func (h *history) trim(entries []string) []string {
+	if len(entries) > h.max {
+		entries = entries[len(entries)-h.max:]
+	}
+	return entries
+}
+
+func (h *history) add(text string) error {
+	h.lock.Lock()
+	// like most shells, don't keep empty entries or the same entry twice in a row
+	if text != "" && (len(h.entries) == 0 || h.entries[len(h.entries)-1] != text) {
+		h.entries = h.trim(append(h.entries, text))
+	}
+	h.pos = len(h.entries)
+	h.typed = ""
+	entries := append([]string(nil), h.entries...)
+	h.lock.Unlock()
+
+	if h.store == nil {
+		return nil
+	}
+	return h.store.Save(entries)
+}
+
+// runs on the UI thread; current is the text of the LineEdit
+// returns the text to put in the LineEdit, or ok == false if there's nothing further up or down
+func (h *history) move(up bool, current string) (text string, ok bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if up {
+		if h.pos == 0 {
+			return "", false
+		}
+		if h.pos == len(h.entries) {
+			h.typed = current
+		}
+		h.pos--
+		return h.entries[h.pos], true
+	}
+	if h.pos == len(h.entries) {
+		return "", false
+	}
+	h.pos++
+	if h.pos == len(h.entries) {
+		return h.typed, true
+	}
+	return h.entries[h.pos], true
+}
@@ -0,0 +1,21 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSTextView.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSTextView(x) to(NSTextView, (x))
+
+// for LineEdit.SetHistory(); the app delegate gets Up and Down through control:textView:doCommandBySelector: (see delegateuitask_darwin.m), with the window's field editor, which has the text being typed
+
+id historyEditorText(id tv)
+{
+	return [toNSTextView(tv) string];
+}
+
+// replace the text with insertText: rather than setString: so the field editor treats it like typing (undo and all); this also puts the cursor at the end, as shells do
+void historySetEditorText(id tv, id text)
+{
+	[toNSTextView(tv) selectAll:nil];
+	[toNSTextView(tv) insertText:text];
+}
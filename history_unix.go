@@ -0,0 +1,43 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern gboolean our_history_key_press_event_callback(GtkWidget *, GdkEvent *, gpointer);
+import "C"
+
+// for LineEdit.SetHistory(), "key-press-event" is connected to the GtkEntry by sysData.make(), before the one for Grid keyboard navigation (see gridnav_unix.go), so the history gets Up and Down first
+// GtkEntry would otherwise move the focus with Up and Down, so we keep them from it even if there's nothing further in the history
+
+//export our_history_key_press_event_callback
+func our_history_key_press_event_callback(widget *C.GtkWidget, event *C.GdkEvent, what C.gpointer) C.gboolean {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(what))
+	e := (*C.GdkEventKey)(unsafe.Pointer(event))
+	if e.state&C.guint(C.gtk_accelerator_get_default_mod_mask()) != 0 {
+		return C.FALSE // continue the event chain
+	}
+	var up bool
+	switch e.keyval {
+	case C.GDK_KEY_Up, C.GDK_KEY_KP_Up:
+		up = true
+	case C.GDK_KEY_Down, C.GDK_KEY_KP_Down:
+		up = false
+	default:
+		return C.FALSE
+	}
+	if text, ok := s.history.move(up, gtk_entry_get_text(widget)); ok {
+		gtk_entry_set_text(widget, text)
+		// put the cursor at the end, as shells do
+		C.gtk_editable_set_position((*C.GtkEditable)(unsafe.Pointer(widget)), -1)
+	}
+	return C.TRUE // handled
+}
+
+var history_key_press_event_callback = C.GCallback(C.our_history_key_press_event_callback)
@@ -0,0 +1,83 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+/*
+For LineEdit.SetHistory(), the edit control is subclassed (like for Grid keyboard navigation; see gridnav_windows.go) so we see Up and Down before it does.
+This subclass is added after the Grid keyboard navigation one, if any, so it runs first; that's what gives the history the Up and Down keys in a Grid.
+A single-line edit control would otherwise move the caret with Up and Down, so we eat them even if there's nothing further in the history.
+The sysData is the reference data of the subclass.
+*/
+
+var historySubclassCallback uintptr
+
+func init() {
+	// not in the var declaration above, for the same reason as listboxSubclassCallback
+	historySubclassCallback = syscall.NewCallback(historySubclassProc)
+}
+
+// runs on the UI thread; called by sysData.make()
+func (s *sysData) historyInit() {
+	r1, _, err := _setWindowSubclass.Call(
+		uintptr(s.hwnd),
+		historySubclassCallback,
+		uintptr(0),
+		uintptr(unsafe.Pointer(s)))
+	if r1 == 0 { // failure
+		panic(s.newError("subclassing edit control for LineEdit history", "SetWindowSubclass()", err))
+	}
+}
+
+func historySubclassProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM, id uintptr, data uintptr) _LRESULT {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(data))
+	switch uMsg {
+	case _WM_KEYDOWN:
+		if getModifiers() != 0 || (wParam != _VK_UP && wParam != _VK_DOWN) {
+			break
+		}
+		if text, ok := s.history.move(wParam == _VK_UP, s.doText()); ok {
+			s.historySetText(text)
+		}
+		return 0
+	case _WM_NCDESTROY:
+		// see gridNavSubclassProc()
+		_removeWindowSubclass.Call(
+			uintptr(hwnd),
+			historySubclassCallback,
+			id)
+	}
+	r1, _, _ := _defSubclassProc.Call(
+		uintptr(hwnd),
+		uintptr(uMsg),
+		uintptr(wParam),
+		uintptr(lParam))
+	return _LRESULT(r1)
+}
+
+// runs on the UI thread
+func (s *sysData) historySetText(text string) {
+	r1, _, err := _setWindowText.Call(
+		uintptr(s.hwnd),
+		utf16ToArg(toUTF16(text)))
+	if r1 == 0 { // failure; the user can still type
+		reportError(s.newError("setting LineEdit text from history", "SetWindowText()", err))
+		return
+	}
+	// put the caret at the end, as shells do
+	r1, _, _ = _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_WM_GETTEXTLENGTH),
+		uintptr(0),
+		uintptr(0))
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_EM_SETSEL),
+		r1,
+		r1)
+}
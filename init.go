@@ -2,9 +2,15 @@
 
 package ui
 
-// Go sets up the UI environment and runs main in a goroutine.
-// If initialization fails, Go returns an error and main is not called.
-// Otherwise, Go does not return to its caller until main does, at which point it returns nil.
+import (
+	"runtime"
+	"time"
+)
+
+// Go runs main in a goroutine and sets up the UI environment for it.
+// The UI environment is not set up until main first uses package ui (for instance, by creating a Window), so a program that only sometimes shows a GUI does not pay for starting the system's GUI toolkit when it doesn't.
+// If initialization fails, Go returns an error right away; whatever package ui call main was making at the time will never return.
+// Otherwise, Go does not return to its caller until main does, at which point it destroys the Windows main didn't (see Window.Destroy) and returns nil.
 // After it returns, you cannot call future ui functions/methods meaningfully.
 //
 // It is not safe to call ui.Go() in a goroutine. It must be called directly from main().
@@ -14,7 +20,37 @@ package ui
 // Go does not process the command line for flags (that is, it does not call flag.Parse()), nor does package ui add any of the underlying toolkit's supported command-line flags.
 // If you must, and if the toolkit also has environment variable equivalents to these flags (for instance, GTK+), use those instead.
 func Go(main func()) error {
-	return ui(main)
+	runtime.LockOSThread()
+	uithread = curthread()
+	uigoroutine = curgoroutine()
+
+	done := make(chan struct{})
+	go func() {
+		main()
+		close(done)
+	}()
+	select {
+	case <-done: // main never needed us
+		return nil
+	case <-uineeded:
+	}
+
+	start := time.Now()
+	err := uiinit()
+	if err != nil {
+		return err
+	}
+	setStartupTime(&startup.t.Init, start)
+	uiinitialized()
+
+	go func() {
+		<-done
+		teardown()
+		uistop()
+	}()
+
+	uimsgloop()
+	return nil
 }
 
 // AppQuit is pulsed when the user decides to quit the program if their operating system provides a facility for quitting an entire application, rather than merely close all windows (for instance, Mac OS X via the Dock icon).
@@ -72,6 +72,10 @@ func doWindowsInit() (err error) {
 	if err != nil {
 		return fmt.Errorf("error registering Area window class: %v", err)
 	}
+	err = registerBannerWndClass()
+	if err != nil {
+		return fmt.Errorf("error registering banner window class (for Window.ShowBanner()): %v", err)
+	}
 	err = getStandardWindowFonts()
 	if err != nil {
 		return fmt.Errorf("error getting standard window fonts: %v", err)
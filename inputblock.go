@@ -0,0 +1,110 @@
+// 10 july 2014
+
+package ui
+
+import (
+	"fmt"
+)
+
+// SetInputBlocked blocks or unblocks all input to the Window's Control, for instance when a trial has expired or the user has to log in again, and shows overlay over it while input is blocked.
+// While input is blocked, the Window's Control is dimmed and ignores the mouse and keyboard as it does while the Window is busy (see SetBusy), but without the busy indicator.
+// The overlay is laid out at its preferred size in the middle of the Window, over everything else, and works as usual; a nil overlay blocks input without showing anything.
+// Nothing else about the blocked Controls changes: they keep their text, selection, and so on, and those disabled with a ControlGroup stay disabled once input is no longer blocked.
+// Blocking input again with another overlay replaces the one shown.
+// An overlay is hidden, not destroyed, when input is unblocked or it's replaced, so the same overlay can be passed again later; it is destroyed along with the Window, and can't be used with any other Window.
+// The Window can be busy and blocked at the same time; each is undone on its own, and while the Window is busy, the overlay is dimmed and ignores input too.
+// It panics if the Window has not been created or has been destroyed.
+func (w *Window) SetInputBlocked(blocked bool, overlay Control) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if !w.created {
+		panic("attempt to block input to Window before it has been created")
+	}
+	if w.destroyed {
+		panic("attempt to block input to Window that has been destroyed")
+	}
+	if !blocked {
+		overlay = nil
+	}
+	if blocked == w.blocked && overlay == w.overlay {
+		return
+	}
+	// first, so a new overlay's controls go over the cover of a blocked Window on Mac OS X
+	if blocked != w.blocked {
+		w.sysData.setInputBlocked(blocked)
+	}
+	if overlay != nil && !w.overlaysMade[overlay] {
+		err := overlay.make(w.sysData)
+		if err != nil {
+			panic(fmt.Errorf("error adding overlay to window: %v", err))
+		}
+		w.sysData.controlsMade(overlay)
+		if w.overlaysMade == nil {
+			w.overlaysMade = make(map[Control]bool)
+		}
+		w.overlaysMade[overlay] = true
+	}
+	w.sysData.showOverlay(overlay)
+	w.blocked = blocked
+	w.overlay = overlay
+}
+
+func (s *sysData) showOverlay(overlay Control) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		if s.overlay != nil {
+			showControls(s.overlay, false)
+		}
+		s.overlay = overlay
+		if overlay != nil {
+			controls := appendControls(nil, overlay)
+			for _, c := range controls {
+				c.inOverlay = true
+				c.doSetEnabled()
+			}
+			showControls(overlay, true)
+			s.raiseControls(controls)
+			if !s.overlayAllocate {
+				s.overlayAllocate = true
+				s.allocate = s.allocateWithOverlay(s.allocate)
+			}
+		}
+		s.doRelayout()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// allocateWithOverlay() wraps the allocate function of a Window (nil if the Window has no Control) to lay out its overlay, if any, over everything else
+func (s *sysData) allocateWithOverlay(allocate func(x int, y int, width int, height int, d *sysSizeData) []*allocation) func(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	return func(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+		var allocations []*allocation
+
+		if allocate != nil {
+			allocations = allocate(x, y, width, height, d)
+		}
+		if s.overlay == nil {
+			return allocations
+		}
+		// the overlay is not the Window's Control, so it gets no margins
+		d.xmargin = 0
+		d.ymargin = 0
+		owidth, oheight := s.overlay.preferredSize(d)
+		if owidth > width {
+			owidth = width
+		}
+		if oheight > height {
+			oheight = height
+		}
+		ox := x + (width-owidth)/2
+		oy := y + (height-oheight)/2
+		if uidebug {
+			d.checkPath = "Window overlay"
+		}
+		// containers reuse their allocations slices (see Stack.allocate()), so don't append to one of theirs
+		allocations = append(allocations[:len(allocations):len(allocations)], s.overlay.allocate(ox, oy, owidth, oheight, d)...)
+		return allocations
+	}
+}
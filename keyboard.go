@@ -0,0 +1,27 @@
+// 9 july 2014
+
+package ui
+
+// CapsLockOn returns whether Caps Lock is on.
+// PasswordEdits already warn the user about Caps Lock while they have the focus, as each system's own password fields do (see NewPasswordEdit); use CapsLockOn for a warning of your own, such as in the message of a login dialog after a failed attempt.
+func CapsLockOn() bool {
+	ret := make(chan bool)
+	defer close(ret)
+	uitask(func() {
+		ret <- capsLockOn()
+	})
+	return <-ret
+}
+
+// KeyboardLayout returns the name of the keyboard layout the user is typing with, as the system shows it to the user, or an empty string if the system doesn't say.
+// A login dialog can show this after a failed attempt, since a password typed with the wrong layout is a common reason for one.
+// On Windows this is the name of the layout's language, such as "English (United States)"; on Mac OS X it is the name of the input source, such as "U.S." or "Dvorak".
+// GTK+ has no way to ask for it, so on GTK+ it is always empty for now.
+func KeyboardLayout() string {
+	ret := make(chan string)
+	defer close(ret)
+	uitask(func() {
+		ret <- keyboardLayout()
+	})
+	return <-ret
+}
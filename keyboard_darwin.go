@@ -0,0 +1,22 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// see keyboard_darwin.m
+
+// runs on the UI thread
+func capsLockOn() bool {
+	return C.capsLockOn() != C.NO
+}
+
+// runs on the UI thread
+func keyboardLayout() string {
+	name := C.keyboardLayoutName()
+	if name == nil {
+		return ""
+	}
+	return fromNSString(name)
+}
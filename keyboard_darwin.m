@@ -0,0 +1,25 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSEvent.h>
+#import <Carbon/Carbon.h>
+
+BOOL capsLockOn(void)
+{
+	return ([NSEvent modifierFlags] & NSAlphaShiftKeyMask) != 0;
+}
+
+// the Text Input Sources API (in Carbon, but not deprecated) is the only way to get at the current keyboard layout; the returned string is owned by the input source, so retain it before letting go of the input source
+id keyboardLayoutName(void)
+{
+	TISInputSourceRef source;
+	NSString *name;
+
+	source = TISCopyCurrentKeyboardLayoutInputSource();
+	if (source == NULL)
+		return nil;
+	name = (NSString *) TISGetInputSourceProperty(source, kTISPropertyLocalizedName);
+	[[name retain] autorelease];
+	CFRelease(source);
+	return name;
+}
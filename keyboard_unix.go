@@ -0,0 +1,19 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+// #include "gtk_unix.h"
+import "C"
+
+// runs on the UI thread
+func capsLockOn() bool {
+	return C.gdk_keymap_get_caps_lock_state(C.gdk_keymap_get_default()) != C.FALSE
+}
+
+// GdkKeymap only knows the keys of the layout, not its name; on X11, XKB has the names of the layout groups, but that would mean using Xlib directly, and would still leave Wayland
+// runs on the UI thread
+func keyboardLayout() string {
+	return ""
+}
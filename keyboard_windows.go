@@ -0,0 +1,25 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"github.com/akavel/winq"
+)
+
+var (
+	_getKeyboardLayout = user32.NewProc("GetKeyboardLayout")
+)
+
+// runs on the UI thread
+func capsLockOn() bool {
+	// the low bit is whether the key is toggled on, rather than held down
+	return (new(winq.Try).A("GetKeyState", _VK_CAPITAL) & 1) != 0
+}
+
+// runs on the UI thread, which is the thread whose keyboard layout we want (each thread has its own)
+func keyboardLayout() string {
+	hkl, _, _ := _getKeyboardLayout.Call(uintptr(0)) // 0 == current thread
+	// the low word of the HKL is the language identifier, which is also a valid LCID
+	lcid := hkl & 0xFFFF
+	return getLocaleInfo(lcid, _LOCALE_SLANGUAGE, "keyboard layout name")
+}
@@ -0,0 +1,114 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+/*
+In uidebug builds, DebugSetKeyboardChecks() (see keyboarddebug.go) turns on checks of whether a Window can be used with the keyboard alone. Once Window.Create() has made the Window's controls, it walks them and reports
+	- each control the user interacts with (so not Labels that can't be selected, ProgressBars, or LevelMeters) that the system won't move the keyboard focus to with Tab or the arrow keys; each sysData.keyboardReachable() asks the system itself, so this also catches what the user's settings turn off
+	- each control with an action that some systems only offer to the mouse, such as Listbox.Activated
+Controls that are disabled, hidden, or on a page of a Tab that isn't shown are skipped, as the system won't move the focus to them either way.
+Problems are reported with the path from the Window to the control, as with the layout checks (see layoutcheck.go), such as "Window > vertical Stack[2] > Grid[1,0] > Listbox".
+*/
+
+var (
+	keyboardCheckLock   sync.Mutex
+	keyboardCheckReport func(problem string)
+)
+
+// called by Window.Create() once everything is made
+func checkKeyboard(c Control) {
+	if !uidebug {
+		return
+	}
+	keyboardCheckLock.Lock()
+	report := keyboardCheckReport
+	keyboardCheckLock.Unlock()
+	if report == nil {
+		return
+	}
+	// the layouts belong to the UI thread once they're created
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		keyboardCheckControl(c, "Window", report)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func keyboardCheckControl(c Control, path string, report func(problem string)) {
+	switch c := c.(type) {
+	case *Stack:
+		what := "horizontal Stack"
+		if c.orientation == Vertical {
+			what = "vertical Stack"
+		}
+		for i, cc := range c.controls {
+			keyboardCheckControl(cc, fmt.Sprintf("%s > %s[%d]", path, what, i), report)
+		}
+		return
+	case *Grid:
+		for row := range c.controls {
+			for col, cc := range c.controls[row] {
+				keyboardCheckControl(cc, fmt.Sprintf("%s > Grid[%d,%d]", path, row, col), report)
+			}
+		}
+		return
+	case *RadioButtons:
+		for i, b := range c.buttons {
+			keyboardCheckSysData(b.sysData, fmt.Sprintf("%s > RadioButtons[%d]", path, i), report)
+		}
+		return
+	case *Tab:
+		keyboardCheckSysData(c.sysData, path+" > Tab", report)
+		for i, p := range c.pages {
+			if !c.made[i] {
+				continue
+			}
+			keyboardCheckControl(p, fmt.Sprintf("%s > Tab[%d]", path, i), report)
+		}
+		return
+	case *GroupBox:
+		// the frame itself does nothing
+		keyboardCheckControl(c.control, path+" > GroupBox", report)
+		return
+	case *Label:
+		if !c.sysData.selectable {
+			return // nothing to do with the keyboard
+		}
+	case *ProgressBar, *LevelMeter:
+		return
+	case *Listbox:
+		if keyboardCheckShown(c.sysData) {
+			report(path + " > Listbox: Activated is only signaled by double-clicking on some systems (Windows sends Enter to the Window instead); if it does anything, also offer a way to do it with the keyboard, such as a Button")
+		}
+	case *Area:
+		if keyboardCheckShown(c.sysData) {
+			report(path + " > Area: the keyboard can only do what the AreaHandler's Key method does, which can't be checked automatically; make sure it covers everything Mouse does")
+		}
+	}
+	s := controlSysData(c)
+	if s == nil { // not a system control
+		return
+	}
+	keyboardCheckSysData(s, path+" > "+strings.TrimPrefix(fmt.Sprintf("%T", c), "*ui."), report)
+}
+
+func keyboardCheckShown(s *sysData) bool {
+	return !s.disabled && !s.hidden && !s.offPage
+}
+
+func keyboardCheckSysData(s *sysData, path string, report func(problem string)) {
+	if !keyboardCheckShown(s) {
+		return
+	}
+	if ok, why := s.keyboardReachable(); !ok {
+		report(path + ": can't be reached with Tab or the arrow keys: " + why)
+	}
+}
@@ -0,0 +1,18 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// see keyboardcheck.go and keyboardcheck_darwin.m
+// runs on the UI thread
+func (s *sysData) keyboardReachable() (bool, string) {
+	if C.canBecomeKeyView(s.id) == C.NO {
+		if C.fullKeyboardAccess() == C.NO {
+			return false, "it isn't in the key view loop; with Full Keyboard Access off (the default; see Keyboard Shortcuts in System Preferences), only text fields and lists are"
+		}
+		return false, "it isn't in the key view loop"
+	}
+	return true, ""
+}
@@ -0,0 +1,26 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSApplication.h>
+#import <AppKit/NSView.h>
+#import <AppKit/NSScrollView.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSView(x) to(NSView, (x))
+
+// Tab moves the focus to the views that can become the key view, which takes the user's Full Keyboard Access setting into account
+// for the controls in scroll views (Listboxes, LogViews, and the like), it's the view inside that gets the focus, not the scroll view
+BOOL canBecomeKeyView(id view)
+{
+	NSView *v;
+
+	v = toNSView(view);
+	if ([v isKindOfClass:[NSScrollView class]])
+		v = [to(NSScrollView, v) documentView];
+	return [v canBecomeKeyView];
+}
+
+BOOL fullKeyboardAccess(void)
+{
+	return [NSApp isFullKeyboardAccessEnabled];
+}
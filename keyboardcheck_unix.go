@@ -0,0 +1,33 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+// #include "gtk_unix.h"
+// /* some widgets, such as GtkComboBox and GtkScrolledWindow, can't have the focus themselves, but one of their children can; gtk_container_forall() also goes through the children GTK+ adds on its own, which gtk_container_get_children() doesn't */
+// static void findFocusable(GtkWidget *w, gpointer data)
+// {
+// 	if (gtk_widget_get_can_focus(w))
+// 		*((gboolean *) data) = TRUE;
+// 	else if (GTK_IS_CONTAINER(w))
+// 		gtk_container_forall(GTK_CONTAINER(w), findFocusable, data);
+// }
+// static gboolean canFocusWithin(GtkWidget *w)
+// {
+// 	gboolean can = FALSE;
+//
+// 	findFocusable(w, &can);
+// 	return can;
+// }
+import "C"
+
+// see keyboardcheck.go
+// GTK+ moves the focus with Tab and the arrow keys to any widget that can have it
+// runs on the UI thread
+func (s *sysData) keyboardReachable() (bool, string) {
+	if C.canFocusWithin(s.widget) == C.FALSE {
+		return false, "neither it nor any of its children can have the focus (see gtk_widget_set_can_focus())"
+	}
+	return true, ""
+}
@@ -0,0 +1,18 @@
+// 9 july 2014
+
+package ui
+
+// see keyboardcheck.go
+// IsDialogMessage() (see msgloop()) moves the focus with Tab to the controls with WS_TABSTOP, and with the arrow keys between the controls of a group
+// runs on the UI thread
+func (s *sysData) keyboardReachable() (bool, string) {
+	hwnd := s.hwnd
+	// the buttons of RadioButtons are reached with the arrow keys from the first, which is the group's only tab stop; see radiobuttons_windows.go
+	if s.radios != nil {
+		hwnd = s.radios.buttons[0].hwnd
+	}
+	if getWindowLongPtr(hwnd, negConst(_GWL_STYLE))&_WS_TABSTOP == 0 {
+		return false, "it doesn't have WS_TABSTOP"
+	}
+	return true, ""
+}
@@ -0,0 +1,19 @@
+// +build uidebug
+
+// 9 july 2014
+
+package ui
+
+// DebugSetKeyboardChecks turns on checks of whether each Window can be used with the keyboard alone, done once Window.Create has made the Window's controls.
+// Each control the user interacts with that the system won't move the keyboard focus to with Tab or the arrow keys is reported, as is each control with an action that some systems only offer to the mouse (such as Listbox.Activated); controls that only show things, such as non-selectable Labels and ProgressBars, aren't expected to be reachable.
+// Reachability is asked of the system, so it also reflects the user's settings: on Mac OS X with Full Keyboard Access off, which is the default, Tab only goes to text fields and lists, so every Button is reported there.
+// Controls that are disabled, hidden, or on a page of a Tab that isn't shown when the Window is created aren't checked.
+// Each problem is passed to report, as text that starts with the path to the control, like the layout checks (see DebugSetLayoutChecks), such as "Window > vertical Stack[2] > Grid[1,0] > Listbox: ...".
+// Like the error handler, report runs on the UI thread and cannot call any other package ui function or method.
+// Pass nil to turn the checks off, which is the default.
+func DebugSetKeyboardChecks(report func(problem string)) {
+	keyboardCheckLock.Lock()
+	defer keyboardCheckLock.Unlock()
+
+	keyboardCheckReport = report
+}
@@ -3,21 +3,38 @@
 package ui
 
 import (
+	"fmt"
 	"sync"
 )
 
 // A Label is a static line of text used to mark other controls.
-// Label text is drawn on a single line; text that does not fit is truncated.
+// Label text is drawn on a single line; text that does not fit is truncated, or ellipsized if you ask for it with SetEllipsize.
 // A Label can appear in one of two places: bound to a control or standalone.
 // This determines the vertical alignment of the label.
+// A Label can also name the Control it describes, its buddy; see SetBuddy.
 type Label struct {
 	lock     sync.Mutex
 	created  bool
 	sysData  *sysData
 	initText string
 	standalone	bool
+	buddy    *sysData
+	widthText string
+	ellipsize Ellipsize
+	selectable bool
+	textDirection TextDirection
 }
 
+// Ellipsize says how a Label shortens text that doesn't fit in the space it is given; see Label.SetEllipsize.
+type Ellipsize uintptr
+
+const (
+	EllipsizeNone   Ellipsize = iota // cut the text off at the end; this is the default
+	EllipsizeStart                   // replace the beginning with an ellipsis, such as "…/src/project/main.go"
+	EllipsizeMiddle                  // replace the middle with an ellipsis, such as "/home/user/…/main.go"
+	EllipsizeEnd                     // replace the end with an ellipsis, such as "/home/user/src/pro…"
+)
+
 // NewLabel creates a new Label with the specified text.
 // The label is set to be bound to a control, so its vertical position depends on its vertical cell size in an implementation-defined manner.
 func NewLabel(text string) *Label {
@@ -60,28 +77,132 @@ func (l *Label) Text() string {
 	return l.initText
 }
 
+// SetWidthFromText sets the Label's preferred width to the width of sample in the Label's font.
+// Use this for a Label whose text changes but should keep the same width, such as a clock (pass "88:88:88") or a counter, so that the controls around it don't move each time the text changes.
+// Text wider than sample may be cut off.
+// Pass "" to go back to the default, which on GTK+ and Mac OS X is the width of the Label's current text.
+func (l *Label) SetWidthFromText(sample string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.created {
+		l.sysData.setWidthHint(0, sample)
+		return
+	}
+	l.widthText = sample
+}
+
+// SetEllipsize sets how the Label shortens text that doesn't fit in the space it is given.
+// This doesn't change the Label's preferred width (the whole text still gets that space if it's available), so use it with a stretchy Stack cell or a Grid column, or with SetWidthFromText.
+// Windows has no native way to replace the beginning of the text, so there EllipsizeStart does the same as EllipsizeMiddle, which keeps as much as it can of the text after the last backslash; this suits Windows paths.
+// SetEllipsize panics if e isn't one of the Ellipsize constants.
+func (l *Label) SetEllipsize(e Ellipsize) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if e > EllipsizeEnd {
+		panic(fmt.Errorf("invalid Ellipsize %d given to Label.SetEllipsize()", e))
+	}
+	if l.created {
+		l.sysData.setEllipsize(e)
+		return
+	}
+	l.ellipsize = e
+}
+
+// SetSelectable sets whether the user can select the Label's text with the mouse or keyboard and copy it, as they'd want to with an error message or an ID number.
+// A selectable Label can also be tabbed to.
+// On Windows, a selectable Label has to be a different kind of control, so SetSelectable only has an effect there if it is called before the Label's Window is created; after that, calls are ignored on Windows.
+// Also on Windows, selectable Labels can't be ellipsized; see SetEllipsize.
+func (l *Label) SetSelectable(selectable bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.created {
+		l.sysData.setSelectable(selectable)
+		return
+	}
+	l.selectable = selectable
+}
+
+// SetBuddy sets the Control that the Label describes, its buddy; a nil Control removes the buddy.
+// On Windows, clicking the Label then moves the keyboard focus to the buddy, as clicking the label of a form field does in native Windows programs.
+// GTK+ and Mac OS X labels don't react to clicks natively, so they don't here either; on all platforms, however, accessibility tools (such as screen readers) are told which Control the Label describes.
+// (Label text has no keyboard mnemonics yet.)
+// The buddy must wrap a native control (that is, it can't be a Stack, a Grid, or a Space), must not be the Label itself, and should be in the same Window as the Label; SetBuddy panics if either of the first two isn't true.
+func (l *Label) SetBuddy(c Control) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var buddy *sysData
+
+	if c != nil {
+		buddy = controlSysData(c)
+		if buddy == nil {
+			panic(fmt.Errorf("Label.SetBuddy() given a %T, which doesn't wrap a native control", c))
+		}
+		if buddy == l.sysData {
+			panic("Label.SetBuddy() given the Label itself")
+		}
+	}
+	l.buddy = buddy
+	if l.created {
+		l.sysData.setBuddy(buddy)
+	}
+}
+
+// the buddy is usually made after the Label (for instance, if it's next in a Stack), so Window.Create() calls this once everything is made
+func (l *Label) linkBuddy() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.sysData.setBuddy(l.buddy)
+}
+
+// SetTextDirection sets which way the Label's text runs; see TextDirection.
+// This function cannot be called after the Window that contains the Label has been created, and panics if d isn't one of the TextDirection constants.
+func (l *Label) SetTextDirection(d TextDirection) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.created {
+		panic(fmt.Errorf("Label.SetTextDirection() called after window create"))
+	}
+	checkTextDirection(d, "Label")
+	l.textDirection = d
+}
+
 func (l *Label) make(window *sysData) error {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
 	l.sysData.alternate = l.standalone
+	l.sysData.selectable = l.selectable
+	l.sysData.textDirection = l.textDirection
+	l.sysData.control = l
 	err := l.sysData.make(window)
 	if err != nil {
 		return err
 	}
 	l.sysData.setText(l.initText)
+	if l.widthText != "" {
+		l.sysData.setWidthHint(0, l.widthText)
+	}
+	if l.ellipsize != EllipsizeNone {
+		l.sysData.setEllipsize(l.ellipsize)
+	}
+	if l.selectable {
+		l.sysData.setSelectable(true)
+	}
+	if l.buddy != nil {
+		window.addLabel(l)
+	}
 	l.created = true
 	return nil
 }
 
 func (l *Label) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
-	return []*allocation{&allocation{
-		x:       x,
-		y:       y,
-		width:   width,
-		height:  height,
-		this:		l,
-	}}
+	return l.sysData.singleAllocation(x, y, width, height, l)
 }
 
 func (l *Label) preferredSize(d *sysSizeData) (width int, height int) {
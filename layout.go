@@ -10,7 +10,7 @@ func resetControls(parent *Stack) {
 			stack.orientation = !parent.orientation
 			resetControls(stack)
 		case nil:
-			emptySpace := newStack(horizontal)
+			emptySpace := newStack(Horizontal)
 			parent.controls[i] = emptySpace
 			parent.stretchy[i] = true
 		}
@@ -23,7 +23,7 @@ func resetControls(parent *Stack) {
 // between them.
 func Layout(controls ...Control) *Stack {
 	stack := &Stack{
-		orientation:  vertical,
+		orientation:  Vertical,
 		controls:     controls,
 		stretchy:     make([]bool, len(controls)),
 		width:        make([]int, len(controls)),
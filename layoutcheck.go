@@ -0,0 +1,116 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+/*
+In uidebug builds, DebugSetLayoutChecks() (see layoutdebug.go) turns on checks of what Stacks and Grids allocate: as each one places its controls, it checks that
+	- no control has a negative width or height
+	- every control is within the rect the Stack or Grid was given
+	- in a Stack, no control overlaps the one before it, and stretchy controls fill the Stack to its end
+A Tab is checked like a Stack of one control, its page, though only for the first two.
+Violations are reported with the path from the Window to the Stack or Grid, such as "Window > vertical Stack[2] > Grid[1,0] > Tab[1] > horizontal Stack".
+The path is handed down to each control through the sysSizeData, and is only built while the checks are on, so the layout code doesn't make garbage otherwise.
+*/
+
+var (
+	layoutCheckLock   sync.Mutex
+	layoutCheckReport func(violation string)
+)
+
+// a layoutCheck is the state of the checks for one call of Stack.allocate() or Grid.allocate(); its methods do nothing on a nil layoutCheck, which is what beginLayoutCheck() returns when the checks are off
+type layoutCheck struct {
+	report  func(violation string)
+	path    string
+	oldPath string
+	x       int
+	y       int
+	width   int
+	height  int
+	stack   bool
+	o       Orientation
+	end     int // where the last control of a Stack ended, along its orientation
+	n       int // the number of controls checked so far
+}
+
+// what is "Grid", "vertical Stack", or the like; x, y, width, and height are what the Stack or Grid was given, before the margins are taken off
+func beginLayoutCheck(d *sysSizeData, what string, x int, y int, width int, height int) *layoutCheck {
+	if !uidebug {
+		return nil
+	}
+	layoutCheckLock.Lock()
+	report := layoutCheckReport
+	layoutCheckLock.Unlock()
+	if report == nil {
+		return nil
+	}
+	path := what
+	if d.checkPath != "" {
+		path = d.checkPath + " > " + what
+	}
+	return &layoutCheck{
+		report:  report,
+		path:    path,
+		oldPath: d.checkPath,
+		x:       x,
+		y:       y,
+		width:   width,
+		height:  height,
+	}
+}
+
+func (l *layoutCheck) setStack(o Orientation) {
+	if l == nil {
+		return
+	}
+	l.stack = true
+	l.o = o
+}
+
+func (l *layoutCheck) violation(format string, args ...interface{}) {
+	l.report(l.path + ": " + fmt.Sprintf(format, args...))
+}
+
+// called before allocating each control; where is "[i]" for a Stack or the page of a Tab (col is -1 for both) or "[row,col]" for a Grid, and is added to the path for the control's own checks, if it's a Stack or Grid
+func (l *layoutCheck) child(d *sysSizeData, row int, col int, x int, y int, width int, height int) {
+	if l == nil {
+		return
+	}
+	where := fmt.Sprintf("[%d]", row)
+	if col >= 0 {
+		where = fmt.Sprintf("[%d,%d]", row, col)
+	}
+	if width < 0 || height < 0 {
+		l.violation("control %s has negative size %dx%d", where, width, height)
+	}
+	if x < l.x || y < l.y || x+width > l.x+l.width || y+height > l.y+l.height {
+		l.violation("control %s at (%d,%d) size %dx%d is outside (%d,%d) size %dx%d", where, x, y, width, height, l.x, l.y, l.width, l.height)
+	}
+	if l.stack {
+		start, end := x, x+width
+		if l.o == Vertical {
+			start, end = y, y+height
+		}
+		if l.n != 0 && start < l.end {
+			l.violation("control %s overlaps the control before it by %d pixels", where, l.end-start)
+		}
+		l.end = end
+	}
+	l.n++
+	d.checkPath = l.path + where
+}
+
+// called once every control is allocated; for a Stack with stretchy controls, end is where the last control should end, along its orientation
+func (l *layoutCheck) done(d *sysSizeData, stretchy bool, end int) {
+	if l == nil {
+		return
+	}
+	if l.stack && stretchy && l.n != 0 && l.end != end {
+		l.violation("stretchy controls end at %d instead of %d, leaving a %d-pixel gap", l.end, end, end-l.end)
+	}
+	d.checkPath = l.oldPath
+}
@@ -0,0 +1,96 @@
+// +build uidebug
+
+// 5 july 2014
+
+package ui
+
+import (
+	"fmt"
+)
+
+// The functions in this file are only available in uidebug builds (see debug.go).
+// They run the layout code on its own, without a real Window (or even a call to Go()), so its speed can be measured; see test/layoutbench.
+
+type fakeControl struct {
+	width  int
+	height int
+	alloc  allocation
+	allocs [1]*allocation
+}
+
+// DebugFakeControl returns a Control that always asks for the given size and has nothing behind it on the system side.
+// It can only be laid out with DebugLayout; putting one in a Window panics.
+func DebugFakeControl(width int, height int) Control {
+	return &fakeControl{
+		width:  width,
+		height: height,
+	}
+}
+
+func (f *fakeControl) make(window *sysData) error {
+	return fmt.Errorf("DebugFakeControl() Controls cannot be put in a Window")
+}
+
+// see sysData.singleAllocation()
+func (f *fakeControl) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	f.alloc = allocation{
+		x:       x,
+		y:       y,
+		width:   width,
+		height:  height,
+		this:		f,
+	}
+	f.allocs[0] = &f.alloc
+	return f.allocs[:]
+}
+
+func (f *fakeControl) preferredSize(d *sysSizeData) (width int, height int) {
+	return f.width, f.height
+}
+
+func (f *fakeControl) commitResize(c *allocation, d *sysSizeData) {
+	// nothing to commit to
+}
+
+func (f *fakeControl) getAuxResizeInfo(d *sysSizeData) {
+	// nothing to report
+}
+
+// DebugLayout lays out c as the Control of a width by height Window would be when the Window is resized, and returns the number of allocations made (one for every Control that isn't a Stack, Grid, or Space).
+// c must be made of only Stacks, Grids, Spaces, and DebugFakeControls.
+// If spaced is true, c is laid out with margins and padding of 8 pixels each, to exercise that code; these are not the sizes any real system uses.
+// The goroutine that calls DebugLayout stands in for the UI thread while it runs, so don't call it while Go is running.
+func DebugLayout(c Control, width int, height int, spaced bool) int {
+	// the layout code checks that it runs on the UI thread (see checkUIThread()), and there is none without Go(), so for this pass that's us
+	old := uigoroutine
+	uigoroutine = curgoroutine()
+	defer func() {
+		uigoroutine = old
+	}()
+
+	d := new(sysSizeData)
+	if spaced {
+		d.xmargin = 8
+		d.ymargin = 8
+		d.xpadding = 8
+		d.ypadding = 8
+	}
+	// see sysData.resizeWindow(); there's no system here so there's nothing to translate
+	allocations := c.allocate(0, 0, width, height, d)
+	for i := len(allocations) - 1; i >= 0; i-- {
+		allocations[i].this.commitResize(allocations[i], d)
+	}
+	return len(allocations)
+}
+
+// DebugSetLayoutChecks turns on checks of the layout done by every Stack and Grid, in Windows and in DebugLayout alike: that no control gets a negative size, that every control is within the space its Stack or Grid was given, and that the controls of a Stack neither overlap nor (if any are stretchy) leave a gap at its end.
+// Each violation is passed to report, as text that starts with the path to the Stack or Grid, such as "Window > vertical Stack[2] > Grid[1,0] > horizontal Stack: control [0] has negative size -3x20".
+// Stacks and Grids too small for their controls cut them off rather than break any of these rules (see Stack), so nothing should be reported at any size, however small; a fuzzer can try whatever sizes it likes.
+// Like the error handler, report runs on the UI thread for Windows (and on the goroutine that called DebugLayout otherwise) and cannot call any other package ui function or method.
+// Pass nil to turn the checks off, which is the default.
+func DebugSetLayoutChecks(report func(violation string)) {
+	layoutCheckLock.Lock()
+	defer layoutCheckLock.Unlock()
+
+	layoutCheckReport = report
+}
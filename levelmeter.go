@@ -0,0 +1,239 @@
+// 8 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"time"
+)
+
+// Levels are the levels of the two channels of a stereo audio signal, as sent to a LevelMeter.
+// Each level is normalized: 0 is silence and 1 is full scale; the LevelMeter clamps anything outside that range.
+// The levels are shown as given, so do any conversion to decibels (and the normalization that goes with it) before sending them.
+type Levels struct {
+	Left  float64
+	Right float64
+}
+
+// A LevelMeter shows the levels of a stereo audio signal as two horizontal bars, the left channel above the right one.
+// Each bar jumps up to a new level immediately and falls back slowly, and a mark shows the highest recent level of each channel (its peak) for a moment before falling back as well; if animations are off (see AnimationsEnabled), they drop back at once instead.
+// Bars are green up to 0.7, yellow up to 0.9, and red past that; in high contrast (see HighContrast), they are in the system's text color up to 0.9 and its highlight color past that, on its background color.
+//
+// A LevelMeter reads its levels from a channel given to NewLevelMeter, usually fed by the goroutine that reads the audio; sending to the channel never waits for the UI, as the LevelMeter reads levels as fast as they come and only redraws at the rate of a typical display.
+// Close the channel to stop the LevelMeter; it then falls back to silence and stops redrawing.
+// The LevelMeter also stops for good when it or its Window is destroyed (see DestroyControl and Window.Destroy); it no longer reads the channel then, so don't keep sending to it if that would block.
+//
+// None of the systems package ui runs on have a level meter control, so a LevelMeter is drawn by package ui itself, in an Area; as such, it has a fixed size like an Area does.
+type LevelMeter struct {
+	area    *Area
+	handler *levelMeterHandler
+}
+
+// this is kept separate from LevelMeter so that the AreaHandler methods aren't part of LevelMeter's API
+type levelMeterHandler struct {
+	lock    sync.Mutex  // for everything below; Paint() runs on the UI thread while run() updates levels on its own goroutine
+	img     *image.RGBA // reused by every Paint()
+	level   [2]float64
+	peak    [2]float64
+	peakAge [2]time.Duration // how long peak has been held
+	stop    chan struct{}    // closed by halt()
+	stopped sync.Once
+	done    chan struct{} // closed by run() when it returns
+}
+
+const (
+	levelMeterFrame     = time.Second / 30
+	levelMeterFall      = 1.5 // full scale per second
+	levelMeterPeakHold  = 1500 * time.Millisecond
+	levelMeterBarGap    = 2 // pixels between the two bars
+	levelMeterPeakWidth = 2 // pixels
+)
+
+var (
+	levelMeterBackground = color.RGBA{0x20, 0x20, 0x20, 0xFF}
+	levelMeterGreen      = color.RGBA{0x30, 0xC0, 0x30, 0xFF}
+	levelMeterYellow     = color.RGBA{0xE0, 0xC0, 0x20, 0xFF}
+	levelMeterRed        = color.RGBA{0xE0, 0x30, 0x20, 0xFF}
+)
+
+// NewLevelMeter creates a new LevelMeter of the given size in pixels that shows the Levels sent on levels.
+// It panics if levels is nil, if width is zero or negative, or if height is less than 4 (the smallest height that fits both bars).
+func NewLevelMeter(width int, height int, levels <-chan Levels) *LevelMeter {
+	if levels == nil {
+		panic("levels channel passed to NewLevelMeter() must not be nil")
+	}
+	if width <= 0 || height < 2+levelMeterBarGap {
+		panic(fmt.Errorf("invalid size %dx%d in NewLevelMeter()", width, height))
+	}
+	h := &levelMeterHandler{
+		img:  image.NewRGBA(image.Rect(0, 0, width, height)),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	m := &LevelMeter{
+		area:    NewArea(width, height, h),
+		handler: h,
+	}
+	go h.run(levels, m.area)
+	return m
+}
+
+func (m *levelMeterHandler) run(levels <-chan Levels, area *Area) {
+	var latest [2]float64 // highest level received since the last frame
+
+	defer close(m.done)
+	ticker := time.NewTicker(levelMeterFrame)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case l, ok := <-levels:
+			if !ok {
+				levels = nil // stop receiving, but keep animating until we're silent
+				continue
+			}
+			latest[0] = maxLevel(latest[0], l.Left)
+			latest[1] = maxLevel(latest[1], l.Right)
+		case <-ticker.C:
+			changed := m.step(latest)
+			latest = [2]float64{}
+			if changed {
+				area.RepaintAll()
+			} else if levels == nil {
+				return
+			}
+		}
+	}
+}
+
+func maxLevel(old float64, level float64) float64 {
+	if level > 1 {
+		level = 1
+	}
+	if level > old {
+		return level
+	}
+	return old
+}
+
+// advances the animation one frame with the highest levels received during that frame; returns whether anything needs redrawing
+func (m *levelMeterHandler) step(latest [2]float64) (changed bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	fall := levelMeterFall * levelMeterFrame.Seconds()
+	if !currentAnimationsEnabled() { // drop straight to the new level instead
+		fall = 1
+	}
+	for i := range m.level {
+		old, oldpeak := m.level[i], m.peak[i]
+		m.level[i] -= fall
+		if latest[i] > m.level[i] {
+			m.level[i] = latest[i]
+		}
+		if m.level[i] < 0 {
+			m.level[i] = 0
+		}
+		m.peakAge[i] += levelMeterFrame
+		if m.peakAge[i] > levelMeterPeakHold {
+			m.peak[i] -= fall
+		}
+		if m.level[i] >= m.peak[i] {
+			m.peak[i] = m.level[i]
+			m.peakAge[i] = 0
+		}
+		if m.level[i] != old || m.peak[i] != oldpeak {
+			changed = true
+		}
+	}
+	return changed
+}
+
+func levelMeterColor(x int, width int, highContrast bool, colors highContrastColors) color.RGBA {
+	if highContrast {
+		if x*10 < width*9 {
+			return colors.text
+		}
+		return colors.highlight
+	}
+	switch {
+	case x*10 < width*7:
+		return levelMeterGreen
+	case x*10 < width*9:
+		return levelMeterYellow
+	}
+	return levelMeterRed
+}
+
+func (m *levelMeterHandler) Paint(cliprect image.Rectangle) *image.RGBA {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	bounds := m.img.Bounds()
+	width := bounds.Dx()
+	barheight := (bounds.Dy() - levelMeterBarGap) / 2
+	hc, colors := currentHighContrast()
+	background := levelMeterBackground
+	if hc {
+		background = colors.background
+	}
+	draw.Draw(m.img, bounds, image.NewUniform(background), image.ZP, draw.Src)
+	for i := range m.level {
+		top := i * (barheight + levelMeterBarGap)
+		end := int(m.level[i] * float64(width))
+		peak := int(m.peak[i] * float64(width))
+		for x := 0; x < width; x++ {
+			if x < end || (x >= peak-levelMeterPeakWidth && x < peak && peak > 0) {
+				c := levelMeterColor(x, width, hc, colors)
+				for y := top; y < top+barheight; y++ {
+					m.img.SetRGBA(x, y, c)
+				}
+			}
+		}
+	}
+	return m.img.SubImage(cliprect).(*image.RGBA)
+}
+
+func (m *levelMeterHandler) Mouse(e MouseEvent) (repaint bool) {
+	return false
+}
+
+func (m *levelMeterHandler) Key(e KeyEvent) (repaint bool) {
+	return false
+}
+
+func (m *LevelMeter) make(window *sysData) error {
+	window.addStopper(m.handler.halt)
+	return m.area.make(window)
+}
+
+// the Area is destroyed along with the Window, or by DestroyControl(), so run() must not repaint it after that; waiting for run() to return means its last RepaintAll() is queued before the Area is destroyed
+// whichever of the two comes first stops run(); the other only waits
+func (m *levelMeterHandler) halt() {
+	m.stopped.Do(func() {
+		close(m.stop)
+	})
+	<-m.done
+}
+
+func (m *LevelMeter) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	// the allocation has to name the Area, since that's what gets moved
+	return m.area.allocate(x, y, width, height, d)
+}
+
+func (m *LevelMeter) preferredSize(d *sysSizeData) (width int, height int) {
+	return m.area.preferredSize(d)
+}
+
+func (m *LevelMeter) commitResize(a *allocation, d *sysSizeData) {
+	m.area.commitResize(a, d)
+}
+
+func (m *LevelMeter) getAuxResizeInfo(d *sysSizeData) {
+	m.area.getAuxResizeInfo(d)
+}
@@ -3,31 +3,49 @@
 package ui
 
 import (
+	"fmt"
 	"sync"
 )
 
 // A LineEdit is a control which allows you to enter a single line of text.
 type LineEdit struct {
-	lock     sync.Mutex
-	created  bool
-	sysData  *sysData
-	initText string
-	password bool
+	// GotFocus is signaled when the LineEdit gets the keyboard focus, and LostFocus when it loses it.
+	// Whether they are also signaled when the Window containing the LineEdit is itself activated or deactivated is implementation-defined.
+	GotFocus  chan struct{}
+	LostFocus chan struct{}
+
+	lock          sync.Mutex
+	created       bool
+	sysData       *sysData
+	initText      string
+	password      bool
+	widthChars    int
+	validate      func(text string) error
+	keepFocus     bool
+	history       *history
+	palette       chan paletteKey // only set by ShowCommandPalette()
+	form          *Form           // see NewForm()
+	textDirection TextDirection
 }
 
 // NewLineEdit makes a new LineEdit with the specified text.
 func NewLineEdit(text string) *LineEdit {
 	return &LineEdit{
-		sysData:  mksysdata(c_lineedit),
-		initText: text,
+		GotFocus:  newEvent(),
+		LostFocus: newEvent(),
+		sysData:   mksysdata(c_lineedit),
+		initText:  text,
 	}
 }
 
 // NewPasswordEdit makes a new LineEdit which allows the user to enter a password.
+// Like the system's own password fields, it warns the user while it has the focus if Caps Lock is on; see also CapsLockOn and KeyboardLayout.
 func NewPasswordEdit() *LineEdit {
 	return &LineEdit{
-		sysData:  mksysdata(c_lineedit),
-		password: true,
+		GotFocus:  newEvent(),
+		LostFocus: newEvent(),
+		sysData:   mksysdata(c_lineedit),
+		password:  true,
 	}
 }
 
@@ -38,6 +56,16 @@ func (l *LineEdit) SetText(text string) {
 
 	if l.created {
 		l.sysData.setText(text)
+		if l.form != nil {
+			// Mac OS X only tells us when the user changes the text; see sysData.lineeditChanged()
+			ret := make(chan struct{})
+			defer close(ret)
+			uitask(func() {
+				l.form.update()
+				ret <- struct{}{}
+			})
+			<-ret
+		}
 		return
 	}
 	l.initText = text
@@ -54,28 +82,132 @@ func (l *LineEdit) Text() string {
 	return l.initText
 }
 
+// SetWidthInChars sets the LineEdit's preferred width to the width of n characters of average width in the LineEdit's font, plus the LineEdit's border.
+// Use this if you know how long the text in the LineEdit is going to be (a ten-digit ID number, for instance); otherwise the preferred width is a system default, which may be too narrow for the text or much too wide.
+// SetWidthInChars does not limit the length of the text itself.
+// Pass 0 to go back to the system default.
+// SetWidthInChars panics if n is negative.
+func (l *LineEdit) SetWidthInChars(n int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if n < 0 {
+		panic(fmt.Errorf("negative width %d given to LineEdit.SetWidthInChars()", n))
+	}
+	if l.created {
+		l.sysData.setWidthHint(n, "")
+		return
+	}
+	l.widthChars = n
+}
+
+// SetValidator has validate check the text of the LineEdit whenever the user moves the keyboard focus from the LineEdit to another control in the same Window, as forms usually do.
+// If validate returns an error, the error's text is shown by the LineEdit as a hint (a balloon on Windows, an icon with a tooltip on GTK+, and a popover on Mac OS X); the hint is removed the next time validate returns nil.
+// If keepFocus is true, the focus is also moved back to the LineEdit, so the user can't leave it until the text is valid; use this sparingly, as it also keeps the user from reaching a Cancel button with the keyboard.
+// Validation doesn't happen when the Window itself is deactivated, so the user can switch to another program to look something up.
+// Like AreaHandler methods, validate runs on the UI thread and cannot call any package ui function or method; it should only look at the text.
+// Pass nil to not validate, which is the default.
+// To check several LineEdits together and keep a Button disabled until they are all valid, see NewForm.
+// This function cannot be called after the Window that contains the LineEdit has been created.
+func (l *LineEdit) SetValidator(validate func(text string) error, keepFocus bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.created {
+		panic(fmt.Errorf("LineEdit.SetValidator() called after window create"))
+	}
+	l.validate = validate
+	l.keepFocus = keepFocus
+}
+
+// SetHistory gives the LineEdit a history of up to max entries, which the user can go back and forth through with the Up and Down arrow keys, like the command line of a shell.
+// Entries are added with AddToHistory; the LineEdit doesn't add anything on its own, since only your program knows when the text has been accepted (a command run, a search made, and so on).
+// If store is not nil, the history is loaded from it now and saved to it each time an entry is added, so it lasts between runs of your program; the error from loading it, if any, is returned, and the history starts out empty.
+// The Up and Down keys go to the history, even in a Grid with keyboard navigation (see Grid.SetKeyboardNavigation()).
+// This function cannot be called after the Window that contains the LineEdit has been created, and panics if max is not positive.
+func (l *LineEdit) SetHistory(max int, store HistoryStore) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.created {
+		panic(fmt.Errorf("LineEdit.SetHistory() called after window create"))
+	}
+	if max <= 0 {
+		panic(fmt.Errorf("invalid history size %d given to LineEdit.SetHistory()", max))
+	}
+	var entries []string
+	var err error
+	if store != nil {
+		entries, err = store.Load()
+		if err != nil {
+			entries = nil
+		}
+	}
+	l.history = newHistory(max, store, entries)
+	return err
+}
+
+// AddToHistory adds text to the end of the LineEdit's history, dropping the oldest entry if the history is full, and saves the history to its HistoryStore, if any, returning the HistoryStore's error.
+// Empty text, and text that is the same as the last entry, is not added.
+// The next press of the Up key goes to this entry.
+// AddToHistory panics if SetHistory was not called first.
+func (l *LineEdit) AddToHistory(text string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.history == nil {
+		panic(fmt.Errorf("LineEdit.AddToHistory() called without LineEdit.SetHistory()"))
+	}
+	return l.history.add(text)
+}
+
+// SetTextDirection sets which way the LineEdit's text runs; see TextDirection.
+// This function cannot be called after the Window that contains the LineEdit has been created, and panics if d isn't one of the TextDirection constants.
+func (l *LineEdit) SetTextDirection(d TextDirection) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.created {
+		panic(fmt.Errorf("LineEdit.SetTextDirection() called after window create"))
+	}
+	checkTextDirection(d, "LineEdit")
+	l.textDirection = d
+}
+
 func (l *LineEdit) make(window *sysData) error {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
 	l.sysData.alternate = l.password
+	l.sysData.control = l
+	l.sysData.gotFocus = l.GotFocus
+	l.sysData.lostFocus = l.LostFocus
+	l.sysData.validate = l.validate
+	l.sysData.keepFocus = l.keepFocus
+	l.sysData.history = l.history
+	l.sysData.palette = l.palette
+	l.sysData.form = l.form
+	l.sysData.textDirection = l.textDirection
 	err := l.sysData.make(window)
 	if err != nil {
 		return err
 	}
 	l.sysData.setText(l.initText)
+	if l.widthChars != 0 {
+		l.sysData.setWidthHint(l.widthChars, "")
+	}
+	if l.sysData.setting != nil {
+		window.addSetting(l.sysData.setting)
+	}
+	if l.form != nil {
+		window.addForm(l.form)
+	}
 	l.created = true
 	return nil
 }
 
 func (l *LineEdit) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
-	return []*allocation{&allocation{
-		x:       x,
-		y:       y,
-		width:   width,
-		height:  height,
-		this:		l,
-	}}
+	return l.sysData.singleAllocation(x, y, width, height, l)
 }
 
 func (l *LineEdit) preferredSize(d *sysSizeData) (width int, height int) {
@@ -89,3 +221,26 @@ func (l *LineEdit) commitResize(a *allocation, d *sysSizeData) {
 func (l *LineEdit) getAuxResizeInfo(d *sysSizeData) {
 	l.sysData.getAuxResizeInfo(d)
 }
+
+// called by the platform code on the UI thread when a LineEdit gets (got is true) or loses the keyboard focus
+// validate is whether the focus went to another control in the same window, which is the only time the text is validated; text is the LineEdit's text
+func (s *sysData) lineeditFocus(got bool, validate bool, text string) {
+	if got {
+		sendEvent(s.gotFocus)
+		return
+	}
+	sendEvent(s.lostFocus)
+	if s.validate == nil || !validate {
+		return
+	}
+	hint := ""
+	err := s.validate(text)
+	if err != nil {
+		hint = err.Error()
+	}
+	refocus := err != nil && s.keepFocus
+	// we're in the middle of the focus change, and none of the systems like having the focus changed again from under them, so wait until it's over
+	go uitask(func() {
+		s.setValidationHint(hint, refocus)
+	})
+}
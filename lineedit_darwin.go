@@ -0,0 +1,39 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// see lineedit_darwin.m
+
+//export lineEdit_focusChanged
+func lineEdit_focusChanged(lineedit C.id, got C.BOOL, validate C.BOOL) {
+	defer recoverUIPanic()
+	s := getSysData(lineedit)
+	text := ""
+	if validate != C.NO && s.validate != nil {
+		text = fromNSString(C.lineeditText(lineedit))
+	}
+	s.lineeditFocus(got != C.NO, validate != C.NO, text)
+}
+
+//export appDelegate_lineeditChanged
+func appDelegate_lineeditChanged(lineedit C.id) {
+	defer recoverUIPanic()
+	getSysData(lineedit).lineeditChanged()
+}
+
+// runs on the UI thread
+func (s *sysData) lineeditText() string {
+	return fromNSString(C.lineeditText(s.id))
+}
+
+func (s *sysData) setValidationHint(hint string, refocus bool) {
+	var chint C.id // nil removes the hint
+
+	if hint != "" {
+		chint = toNSString(hint)
+	}
+	C.lineeditSetValidationHint(s.id, chint, toBOOL(refocus))
+}
@@ -0,0 +1,146 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#include "_cgo_export.h"
+#import <AppKit/NSTextField.h>
+#import <AppKit/NSSecureTextField.h>
+#import <AppKit/NSText.h>
+#import <AppKit/NSWindow.h>
+#import <AppKit/NSPopover.h>
+#import <AppKit/NSViewController.h>
+#import <AppKit/NSFont.h>
+#import <Foundation/NSDictionary.h>
+#import <Foundation/NSValue.h>
+
+extern NSRect dummyRect;
+
+#define to(T, x) ((T *) (x))
+#define toNSTextField(x) to(NSTextField, (x))
+
+/*
+NSTextFields don't tell anyone when they get or lose the focus, so LineEdits are subclasses that do.
+An NSTextField is only first responder for a moment: it hands the focus to the window's field editor (an NSTextView shared by all the window's text fields), and it's the field editor that has the focus while the user types. So the focus comes in at becomeFirstResponder, and it goes out when the field editor is done with us, at textDidEndEditing:.
+The field editor stays around when the window is deactivated, so LineEdit.LostFocus isn't signaled then; if the window is closed instead, the window is no longer key by the time we find out, which is how we know not to validate.
+NSSecureTextField is a subclass of NSTextField, not the other way around, so the two subclasses have to share their methods with a macro.
+The hint for LineEdit.SetValidator() is an NSPopover (new in 10.7) below the LineEdit; it's kept with the LineEdit as an associated object.
+*/
+
+#define lineEditMethods \
+	- (BOOL)becomeFirstResponder \
+	{ \
+		BOOL r; \
+		\
+		r = [super becomeFirstResponder]; \
+		if (r) \
+			lineEdit_focusChanged(self, YES, NO); \
+		return r; \
+	} \
+	\
+	- (void)textDidEndEditing:(NSNotification *)note \
+	{ \
+		NSInteger movement; \
+		\
+		[super textDidEndEditing:note]; \
+		movement = [[[note userInfo] objectForKey:@"NSTextMovement"] integerValue]; \
+		/* Return ends editing, but the text field selects its text and keeps the focus */ \
+		if (movement == NSReturnTextMovement) \
+			return; \
+		lineEdit_focusChanged(self, NO, [[self window] isKeyWindow]); \
+	}
+
+@interface goLineEdit : NSTextField
+@end
+
+@implementation goLineEdit
+lineEditMethods
+@end
+
+@interface goPasswordEdit : NSSecureTextField
+@end
+
+@implementation goPasswordEdit
+lineEditMethods
+@end
+
+id makeLineEdit(BOOL password)
+{
+	id c;
+
+	if (password)
+		c = [[goPasswordEdit alloc]
+			initWithFrame:dummyRect];
+	else
+		c = [[goLineEdit alloc]
+			initWithFrame:dummyRect];
+	// Interface Builder does this to make the text box behave properly
+	// see makeLabel() for other side effects
+	[[toNSTextField(c) cell] setLineBreakMode:NSLineBreakByClipping];
+	// Interface Builder also sets this to allow horizontal scrolling
+	[[toNSTextField(c) cell] setScrollable:YES];
+	return c;
+}
+
+// for Grid.SetKeyboardNavigation(), LineEdit.SetHistory(), ShowCommandPalette(), Form, and BindSetting(); the delegate gets the keys the field editor doesn't handle itself through control:textView:doCommandBySelector: (see delegateuitask_darwin.m)
+void lineeditWatchKeys(id lineedit, id delegate)
+{
+	[toNSTextField(lineedit) setDelegate:delegate];
+}
+
+#define hintMargin 8
+
+static char hintKey;		/* only its address is used */
+
+static NSPopover *makeHint(NSString *text)
+{
+	NSTextField *label;
+	NSView *v;
+	NSViewController *vc;
+	NSPopover *popover;
+	NSRect r;
+
+	label = [[NSTextField alloc] initWithFrame:dummyRect];
+	[label setEditable:NO];
+	[label setSelectable:NO];
+	[label setBordered:NO];
+	[label setDrawsBackground:NO];
+	[label setFont:[NSFont systemFontOfSize:[NSFont systemFontSizeForControlSize:NSRegularControlSize]]];
+	[label setStringValue:text];
+	[label sizeToFit];
+	r = [label frame];
+	v = [[NSView alloc] initWithFrame:NSMakeRect(0, 0, r.size.width + 2 * hintMargin, r.size.height + 2 * hintMargin)];
+	[label setFrameOrigin:NSMakePoint(hintMargin, hintMargin)];
+	[v addSubview:label];
+	[label release];		// the view has it now
+	vc = [NSViewController new];
+	[vc setView:v];
+	[v release];
+	popover = [NSPopover new];
+	[popover setContentViewController:vc];
+	[popover setContentSize:[v frame].size];
+	[vc release];
+	// stay up until the text is valid, rather than going away with the next click
+	[popover setBehavior:NSPopoverBehaviorApplicationDefined];
+	return popover;
+}
+
+// hint is nil to remove the hint
+void lineeditSetValidationHint(id lineedit, id hint, BOOL refocus)
+{
+	NSPopover *popover;
+
+	if (refocus)
+		[[toNSTextField(lineedit) window] makeFirstResponder:lineedit];
+	popover = (NSPopover *) objc_getAssociatedObject(lineedit, &hintKey);
+	if (popover != nil) {
+		[popover close];
+		objc_setAssociatedObject(lineedit, &hintKey, nil, OBJC_ASSOCIATION_RETAIN);
+	}
+	if (hint == nil)
+		return;
+	popover = makeHint((NSString *) hint);
+	objc_setAssociatedObject(lineedit, &hintKey, popover, OBJC_ASSOCIATION_RETAIN);
+	[popover release];		// the association has it now
+	[popover showRelativeToRect:[toNSTextField(lineedit) bounds]
+		ofView:toNSTextField(lineedit)
+		preferredEdge:NSMinYEdge];
+}
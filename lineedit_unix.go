@@ -0,0 +1,83 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern gboolean our_lineedit_focus_in_event_callback(GtkWidget *, GdkEvent *, gpointer);
+// extern gboolean our_lineedit_focus_out_event_callback(GtkWidget *, GdkEvent *, gpointer);
+// extern void our_lineedit_changed_callback(GtkEditable *, gpointer);
+import "C"
+
+/*
+LineEdit.GotFocus and LineEdit.LostFocus come from "focus-in-event" and "focus-out-event" on the GtkEntry (see the classData for LineEdits).
+GTK+ also sends these when the window is activated or deactivated; by the time we get "focus-out-event" for that, the window is no longer active, which is how we know not to validate.
+The hint for LineEdit.SetValidator() is an error icon at the end of the GtkEntry, with the hint as its tooltip.
+"changed" is only connected for LineEdits that need it (those of a command palette or a Form, and those bound with BindSetting(); see sysData.lineeditChanged()); GtkEntry also sends it for gtk_entry_set_text().
+*/
+
+//export our_lineedit_focus_in_event_callback
+func our_lineedit_focus_in_event_callback(widget *C.GtkWidget, event *C.GdkEvent, what C.gpointer) C.gboolean {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(what))
+	s.lineeditFocus(true, false, "")
+	return C.FALSE // continue the event chain; GtkEntry needs it to draw the cursor
+}
+
+var lineedit_focus_in_event_callback = C.GCallback(C.our_lineedit_focus_in_event_callback)
+
+//export our_lineedit_focus_out_event_callback
+func our_lineedit_focus_out_event_callback(widget *C.GtkWidget, event *C.GdkEvent, what C.gpointer) C.gboolean {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(what))
+	validate := false
+	toplevel := C.gtk_widget_get_toplevel(widget)
+	if C.gtk_widget_is_toplevel(toplevel) != C.FALSE {
+		validate = C.gtk_window_is_active(togtkwindow(toplevel)) != C.FALSE
+	}
+	text := ""
+	if validate && s.validate != nil {
+		text = gtk_entry_get_text(widget)
+	}
+	s.lineeditFocus(false, validate, text)
+	return C.FALSE // continue the event chain
+}
+
+var lineedit_focus_out_event_callback = C.GCallback(C.our_lineedit_focus_out_event_callback)
+
+//export our_lineedit_changed_callback
+func our_lineedit_changed_callback(editable *C.GtkEditable, what C.gpointer) {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(what))
+	s.lineeditChanged()
+}
+
+var lineedit_changed_callback = C.GCallback(C.our_lineedit_changed_callback)
+
+// runs on the UI thread
+func (s *sysData) lineeditText() string {
+	return gtk_entry_get_text(s.widget)
+}
+
+// see sysData.lineeditFocus()
+func (s *sysData) setValidationHint(hint string, refocus bool) {
+	entry := togtkentry(s.widget)
+	if refocus {
+		C.gtk_widget_grab_focus(s.widget)
+	}
+	if hint == "" {
+		C.gtk_entry_set_icon_from_icon_name(entry, C.GTK_ENTRY_ICON_SECONDARY, nil)
+		return
+	}
+	cicon := C.CString("dialog-error")
+	defer C.free(unsafe.Pointer(cicon))
+	C.gtk_entry_set_icon_from_icon_name(entry, C.GTK_ENTRY_ICON_SECONDARY, togstr(cicon))
+	chint := C.CString(hint)
+	defer C.free(unsafe.Pointer(chint))
+	C.gtk_entry_set_icon_tooltip_text(entry, C.GTK_ENTRY_ICON_SECONDARY, togstr(chint))
+}
@@ -0,0 +1,76 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+/*
+Edit controls tell their parent (the Window, so stdWndProc()) when they get and lose the focus with EN_SETFOCUS and EN_KILLFOCUS; see LineEdit.GotFocus and LineEdit.LostFocus.
+The hint for LineEdit.SetValidator() is a balloon tip, which edit controls have built in since Common Controls 6 (see comctl_windows.go); the balloon goes away by itself when the user types, and we hide it once the text is valid.
+*/
+
+type _EDITBALLOONTIP struct {
+	cbStruct uint32
+	pszTitle *uint16
+	pszText  *uint16
+	ttiIcon  int32
+}
+
+var validationHintTitle = toUTF16("Invalid Entry")
+
+// runs on the UI thread; window is the sysData of the Window
+func (s *sysData) lineeditKillFocus(window *sysData) {
+	// by the time we get EN_KILLFOCUS, the focus has moved; if it isn't one of our window's controls, the window is being deactivated, or the user went to another Window
+	validate := false
+	focus, _, _ := _getFocus.Call()
+	if _HWND(focus) != _HWND(_NULL) {
+		r1, _, _ := _isChild.Call(
+			uintptr(window.hwnd),
+			focus)
+		validate = r1 != 0
+	}
+	text := ""
+	if validate && s.validate != nil {
+		text = s.doText()
+	}
+	s.lineeditFocus(false, validate, text)
+}
+
+// runs on the UI thread
+func (s *sysData) lineeditText() string {
+	return s.doText()
+}
+
+// runs on the UI thread; see sysData.lineeditFocus()
+func (s *sysData) setValidationHint(hint string, refocus bool) {
+	if refocus {
+		// don't bother checking SetFocus()'s error; see stdWndProc()
+		_setFocus.Call(uintptr(s.hwnd))
+	}
+	if hint == "" {
+		// the return value is whether there was a balloon to hide
+		_sendMessage.Call(
+			uintptr(s.hwnd),
+			uintptr(_EM_HIDEBALLOONTIP),
+			uintptr(0),
+			uintptr(0))
+		return
+	}
+	text := toUTF16(hint)
+	bt := &_EDITBALLOONTIP{
+		pszTitle: validationHintTitle,
+		pszText:  text,
+		ttiIcon:  _TTI_ERROR,
+	}
+	bt.cbStruct = uint32(unsafe.Sizeof(*bt))
+	r1, _, _ := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_EM_SHOWBALLOONTIP),
+		uintptr(0),
+		uintptr(unsafe.Pointer(bt)))
+	if r1 == 0 { // failure; not worth panicking over, as the LineEdit still works
+		reportError(s.newError("showing validation hint", "EM_SHOWBALLOONTIP", nil))
+	}
+}
@@ -3,25 +3,50 @@
 package ui
 
 import (
+	"bytes"
 	"fmt"
+	"html"
+	"sort"
+	"strings"
 	"sync"
 )
 
 // A Listbox is a vertical list of items, of which either at most one or any number of items can be selected at any given time.
-// On creation, no item is selected.
+// On creation, no item is selected (unless Select was called beforehand).
+// In a multiple-selection Listbox, the user extends the selection by holding down the platform's usual modifier keys (Shift and Control on Windows and GTK+, Shift and Command on Mac OS X); there is no mode where clicking an item simply toggles it, as that doesn't work like anything else on most systems.
+// The user can copy the selected items with the system's Copy shortcut (Ctrl+C, or Command+C on Mac OS X); they go on the clipboard as plain text, one item per line, and as an HTML table with one item per row, so spreadsheets paste them as a column and word processors as a table.
 // For information on scrollbars, see "Scrollbars" in the Overview.
 // Due to implementation issues, the presence of horizontal scrollbars is currently implementation-defined.
 type Listbox struct {
-	lock      sync.Mutex
-	created   bool
-	sysData   *sysData
-	initItems []string
+	// SelectionChanged is signaled when the user changes which items are selected.
+	// Whether changing the selection in code (with Select, ClearSelection, or Delete) also signals SelectionChanged is implementation-defined.
+	SelectionChanged chan struct{}
+
+	// Activated is signaled when the user activates an item, usually by double-clicking it.
+	// Whether pressing Enter also activates the selected item is implementation-defined (Windows sends Enter to the Window instead).
+	// Use SelectedIndices to find out which item was activated.
+	Activated chan struct{}
+
+	// NearEnd is signaled when the user scrolls the Listbox close to its last item: when less than a screenful of items is left below the ones showing.
+	// Use it to load more items as they are needed, with Append, for long lists such as feeds and search results.
+	// NearEnd is only signaled once for each number of items in the Listbox, so it isn't signaled again while scrolling around the end until items are appended (or deleted).
+	// As only scrolling signals NearEnd, a Listbox whose items all fit never signals it; load enough items to fill the Listbox to begin with.
+	NearEnd chan struct{}
+
+	lock         sync.Mutex
+	created      bool
+	sysData      *sysData
+	initItems    []string
+	initSelected []int
 }
 
 func newListbox(multiple bool, items ...string) (l *Listbox) {
 	l = &Listbox{
-		sysData:   mksysdata(c_listbox),
-		initItems: items,
+		SelectionChanged: newEvent(),
+		Activated:        newEvent(),
+		NearEnd:          newEvent(),
+		sysData:          mksysdata(c_listbox),
+		initItems:        items,
 	}
 	l.sysData.alternate = multiple
 	return l
@@ -38,15 +63,14 @@ func NewMultiSelListbox(items ...string) *Listbox {
 }
 
 // Append adds items to the end of the Listbox's list.
+// All the items are added at once, without redrawing the Listbox in between, so appending a batch of items (for instance, on NearEnd) is much faster than appending them one at a time.
 // Append will panic if something goes wrong on platforms that do not abort themselves.
 func (l *Listbox) Append(what ...string) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
 	if l.created {
-		for _, s := range what {
-			l.sysData.append(s)
-		}
+		l.sysData.appendMany(what)
 		return
 	}
 	l.initItems = append(l.initItems, what...)
@@ -58,8 +82,6 @@ func (l *Listbox) InsertBefore(what string, before int) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
-	var m []string
-
 	if l.created {
 		if before < 0 || before >= l.sysData.len() {
 			goto badrange
@@ -70,15 +92,25 @@ func (l *Listbox) InsertBefore(what string, before int) {
 	if before < 0 || before >= len(l.initItems) {
 		goto badrange
 	}
-	m = make([]string, 0, len(l.initItems)+1)
-	m = append(m, l.initItems[:before]...)
-	m = append(m, what)
-	l.initItems = append(m, l.initItems[before:]...)
+	l.initInsertBefore(what, before)
 	return
 badrange:
 	panic(fmt.Errorf("index %d out of range in Listbox.InsertBefore()", before))
 }
 
+// before the Listbox is created; the index has already been checked
+func (l *Listbox) initInsertBefore(what string, before int) {
+	m := make([]string, 0, len(l.initItems)+1)
+	m = append(m, l.initItems[:before]...)
+	m = append(m, what)
+	l.initItems = append(m, l.initItems[before:]...)
+	for i := range l.initSelected { // the selected items move down with the rest
+		if l.initSelected[i] >= before {
+			l.initSelected[i]++
+		}
+	}
+}
+
 // Delete removes the given item from the Listbox. It panics if the given index is out of bounds.
 func (l *Listbox) Delete(index int) {
 	l.lock.Lock()
@@ -94,12 +126,55 @@ func (l *Listbox) Delete(index int) {
 	if index < 0 || index >= len(l.initItems) {
 		goto badrange
 	}
-	l.initItems = append(l.initItems[:index], l.initItems[index+1:]...)
+	l.initDelete(index)
 	return
 badrange:
 	panic(fmt.Errorf("index %d out of range in Listbox.Delete()", index))
 }
 
+// before the Listbox is created; the index has already been checked
+func (l *Listbox) initDelete(index int) {
+	l.initItems = append(l.initItems[:index], l.initItems[index+1:]...)
+	sel := l.initSelected[:0]
+	for _, i := range l.initSelected {
+		if i == index { // deleted items aren't selected anymore
+			continue
+		}
+		if i > index {
+			i--
+		}
+		sel = append(sel, i)
+	}
+	l.initSelected = sel
+}
+
+// a listEdit is one of the changes given to Listbox.edit(): what is inserted before the item at index if insert is set; otherwise the item at index is deleted
+type listEdit struct {
+	index  int
+	insert bool
+	what   string
+}
+
+// edit makes the changes in edits, in order, and then appends tail, all in one go, the way Append adds its items; this is for FilteredList.SetFilter(), which works out every change first
+// the indices aren't checked: each has to be in range once the edits before it are made
+func (l *Listbox) edit(edits []listEdit, tail []string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.created {
+		l.sysData.editMany(edits, tail)
+		return
+	}
+	for _, e := range edits {
+		if e.insert {
+			l.initInsertBefore(e.what, e.index)
+		} else {
+			l.initDelete(e.index)
+		}
+	}
+	l.initItems = append(l.initItems, tail...)
+}
+
 // Selection returns a list of strings currently selected in the Listbox, or an empty list if none have been selected. This list will have at most one item on a single-selection Listbox.
 func (l *Listbox) Selection() []string {
 	l.lock.Lock()
@@ -108,7 +183,14 @@ func (l *Listbox) Selection() []string {
 	if l.created {
 		return l.sysData.selectedTexts()
 	}
-	return nil
+	if len(l.initSelected) == 0 {
+		return nil
+	}
+	texts := make([]string, len(l.initSelected))
+	for i, index := range l.initSelected {
+		texts[i] = l.initItems[index]
+	}
+	return texts
 }
 
 // SelectedIndices returns a list of the currently selected indexes in the Listbox, or an empty list if none have been selected. This list will have at most one item on a single-selection Listbox.
@@ -119,7 +201,55 @@ func (l *Listbox) SelectedIndices() []int {
 	if l.created {
 		return l.sysData.selectedIndices()
 	}
-	return nil
+	if len(l.initSelected) == 0 {
+		return nil
+	}
+	return append([]int(nil), l.initSelected...)
+}
+
+// Select changes the selection of the Listbox to exactly the items at the given indices; any other items are deselected.
+// Calling Select with no indices deselects everything, as ClearSelection does.
+// If the Window containing the Listbox has not been created yet, Select sets the items that will be selected when it is.
+// It panics if any index is out of range, or if more than one index is given for a single-selection Listbox.
+// Select does not scroll the Listbox to show the new selection.
+func (l *Listbox) Select(indices ...int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if len(indices) > 1 && !l.sysData.alternate {
+		panic(fmt.Errorf("%d indices given to Listbox.Select() on a single-selection Listbox", len(indices)))
+	}
+	n := len(l.initItems)
+	if l.created {
+		n = l.sysData.len()
+	}
+	sel := make([]int, 0, len(indices))
+	for _, index := range indices {
+		if index < 0 || index >= n {
+			panic(fmt.Errorf("index %d out of range in Listbox.Select()", index))
+		}
+		sel = append(sel, index)
+	}
+	// keep things in the same order SelectedIndices() returns them and drop duplicates
+	sort.Ints(sel)
+	j := 0
+	for i := range sel {
+		if i == 0 || sel[i] != sel[j-1] {
+			sel[j] = sel[i]
+			j++
+		}
+	}
+	sel = sel[:j]
+	if l.created {
+		l.sysData.selectIndices(sel)
+		return
+	}
+	l.initSelected = sel
+}
+
+// ClearSelection deselects all items in the Listbox.
+func (l *Listbox) ClearSelection() {
+	l.Select()
 }
 
 // Len returns the number of items in the Listbox.
@@ -139,25 +269,45 @@ func (l *Listbox) make(window *sysData) (err error) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
+	l.sysData.control = l
+	l.sysData.event = l.SelectionChanged
+	l.sysData.activated = l.Activated
+	l.sysData.nearEnd = l.NearEnd
 	err = l.sysData.make(window)
 	if err != nil {
 		return err
 	}
-	for _, s := range l.initItems {
-		l.sysData.append(s)
+	l.sysData.appendMany(l.initItems)
+	if len(l.initSelected) != 0 {
+		l.sysData.selectIndices(l.initSelected)
+	}
+	if l.sysData.setting != nil {
+		window.addSetting(l.sysData.setting)
 	}
 	l.created = true
 	return nil
 }
 
+// what the platform code puts on the clipboard when the user copies from a Listbox
+// no trailing newline on the text, so pasting into a text field doesn't leave an empty line after
+func listboxClipboardData(texts []string) ClipboardData {
+	var b bytes.Buffer
+
+	b.WriteString("<table>")
+	for _, t := range texts {
+		b.WriteString("<tr><td>")
+		b.WriteString(html.EscapeString(t))
+		b.WriteString("</td></tr>")
+	}
+	b.WriteString("</table>")
+	return ClipboardData{
+		Text: strings.Join(texts, "\n"),
+		HTML: b.String(),
+	}
+}
+
 func (l *Listbox) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
-	return []*allocation{&allocation{
-		x:       x,
-		y:       y,
-		width:   width,
-		height:  height,
-		this:		l,
-	}}
+	return l.sysData.singleAllocation(x, y, width, height, l)
 }
 
 func (l *Listbox) preferredSize(d *sysSizeData) (width int, height int) {
@@ -157,8 +157,9 @@ We'll handle selections from the NSTableView too. The only trickery is dealing w
 */
 
 func makeListbox(parentWindow C.id, alternate bool, s *sysData) C.id {
-	listbox := C.makeListbox(makeListboxTableColumn(), toBOOL(alternate))
+	listbox := C.makeListbox(makeListboxTableColumn(), toBOOL(alternate), appDelegate)
 	listbox = makeListboxScrollView(listbox)
+	C.listboxWatchScrolling(listbox, appDelegate) // for Listbox.NearEnd (listboxScrolled:)
 	addControl(parentWindow, listbox)
 	return listbox
 }
@@ -168,6 +169,15 @@ func listboxAppend(listbox C.id, what string, alternate bool) {
 	listboxArrayAppend(array, what)
 }
 
+func listboxAppendMany(listbox C.id, what []string, alternate bool) {
+	array := listboxArray(listbox)
+	items := C.listboxItemsNew()
+	for _, w := range what {
+		C.listboxItemsAdd(items, toListboxItem(w))
+	}
+	C.listboxArrayAppendItems(array, items)
+}
+
 func listboxInsertBefore(listbox C.id, what string, before int, alternate bool) {
 	array := listboxArray(listbox)
 	listboxArrayInsertBefore(array, what, before)
@@ -203,11 +213,30 @@ func listboxSelectedTexts(listbox C.id) (texts []string) {
 	return texts
 }
 
+// and going the other way we have to build a NSIndexSet one index at a time
+func listboxSelectIndices(listbox C.id, indices []int, alternate bool) {
+	set := C.listboxIndexesNew()
+	for _, index := range indices {
+		C.listboxIndexesAdd(set, C.uintptr_t(index))
+	}
+	C.listboxSelectRowIndexes(listboxInScrollView(listbox), set)
+}
+
 func listboxDelete(listbox C.id, index int) {
 	array := listboxArray(listbox)
 	listboxArrayDelete(array, index)
 }
 
+//export listboxView_copy
+func listboxView_copy(listbox C.id) {
+	defer recoverUIPanic()
+	texts := listboxSelectedTexts(listbox)
+	if len(texts) == 0 { // don't empty the clipboard for nothing
+		return
+	}
+	newClipboardContents(listboxClipboardData(texts)).set()
+}
+
 func listboxLen(listbox C.id) int {
 	return int(C.listboxLen(listboxInScrollView(listbox)))
 }
@@ -1,11 +1,17 @@
 // 13 may 2014
 
 #include "objc_darwin.h"
+#include "_cgo_export.h"
 #import <Foundation/NSDictionary.h>
+#import <Foundation/NSArray.h>
+#import <Foundation/NSNotification.h>
 #import <AppKit/NSArrayController.h>
 #import <AppKit/NSTableColumn.h>
 #import <AppKit/NSTableView.h>
 #import <Foundation/NSIndexSet.h>
+#import <AppKit/NSScrollView.h>
+#import <AppKit/NSClipView.h>
+#import <AppKit/NSEvent.h>
 
 #define to(T, x) ((T *) (x))
 #define toNSMutableDictionary(x) to(NSMutableDictionary, (x))
@@ -13,6 +19,8 @@
 #define toNSTableColumn(x) to(NSTableColumn, (x))
 #define toNSTableView(x) to(NSTableView, (x))
 #define toNSIndexSet(x) to(NSIndexSet, (x))
+#define toNSMutableArray(x) to(NSMutableArray, (x))
+#define toNSScrollView(x) to(NSScrollView, (x))
 
 #define toNSInteger(x) ((NSInteger) (x))
 #define fromNSInteger(x) ((intptr_t) (x))
@@ -49,6 +57,22 @@ void listboxArrayAppend(id ac, id item)
 	[toNSArrayController(ac) addObject:item];
 }
 
+// for appending many items at once; the array controller only tells the table view about them once this way
+id listboxItemsNew(void)
+{
+	return [NSMutableArray array];
+}
+
+void listboxItemsAdd(id items, id item)
+{
+	[toNSMutableArray(items) addObject:item];
+}
+
+void listboxArrayAppendItems(id ac, id items)
+{
+	[toNSArrayController(ac) addObjects:toNSMutableArray(items)];
+}
+
 void listboxArrayInsertBefore(id ac, id item, uintptr_t before)
 {
 	[toNSArrayController(ac) insertObject:item atArrangedObjectIndex:toNSUInteger(before)];
@@ -102,16 +126,45 @@ id listboxTableColumn(id listbox, id identifier)
 	return [toNSTableView(listbox) tableColumnWithIdentifier:identifier];
 }
 
-id makeListbox(id tableColumn, BOOL multisel)
+// NSTableView doesn't copy by itself; the Go side (listboxView_copy()) gets the selected items and hands the text to setClipboardText() in clipboard_darwin.m
+@interface goListboxTableView : NSTableView
+@end
+
+@implementation goListboxTableView
+
+// for Edit > Copy, if the program ever has a menu with one
+- (IBAction)copy:(id)sender
+{
+	listboxView_copy([self enclosingScrollView]);
+}
+
+// and for Command+C without one; NSTableView would just beep
+- (void)keyDown:(NSEvent *)e
+{
+	if (([e modifierFlags] & NSDeviceIndependentModifierFlagsMask) == NSCommandKeyMask &&
+		[[e charactersIgnoringModifiers] isEqualToString:@"c"]) {
+		[self copy:self];
+		return;
+	}
+	[super keyDown:e];
+}
+
+@end
+
+id makeListbox(id tableColumn, BOOL multisel, id delegate)
 {
 	NSTableView *listbox;
 
-	listbox = [[NSTableView alloc]
+	listbox = [[goListboxTableView alloc]
 		initWithFrame:dummyRect];
 	[listbox addTableColumn:tableColumn];
 	[listbox setAllowsMultipleSelection:multisel];
 	[listbox setAllowsEmptySelection:YES];
 	[listbox setHeaderView:nil];
+	// for Listbox.SelectionChanged (tableViewSelectionDidChange:) and Listbox.Activated (listboxDoubleClicked:)
+	[listbox setDelegate:delegate];
+	[listbox setTarget:delegate];
+	[listbox setDoubleAction:@selector(listboxDoubleClicked:)];
 	// TODO other prperties?
 	return listbox;
 }
@@ -140,3 +193,43 @@ intptr_t listboxLen(id listbox)
 {
 	return fromNSInteger([toNSTableView(listbox) numberOfRows]);
 }
+
+id listboxIndexesNew(void)
+{
+	return [NSMutableIndexSet indexSet];
+}
+
+void listboxIndexesAdd(id indexes, uintptr_t index)
+{
+	[((NSMutableIndexSet *) indexes) addIndex:toNSUInteger(index)];
+}
+
+void listboxSelectRowIndexes(id listbox, id indexes)
+{
+	// an empty index set clears the selection, since we allow empty selections
+	[toNSTableView(listbox) selectRowIndexes:toNSIndexSet(indexes) byExtendingSelection:NO];
+}
+
+// for Listbox.NearEnd: the clip view of the scroll view changes its bounds when it scrolls, and can tell the delegate about it (listboxScrolled:)
+void listboxWatchScrolling(id scrollview, id delegate)
+{
+	NSClipView *clip;
+
+	clip = [toNSScrollView(scrollview) contentView];
+	[clip setPostsBoundsChangedNotifications:YES];
+	[[NSNotificationCenter defaultCenter] addObserver:delegate
+		selector:@selector(listboxScrolled:)
+		name:NSViewBoundsDidChangeNotification
+		object:clip];
+}
+
+// see cSysData.signalNearEnd(); NSTableView is flipped, so the end is at the bottom of the document view
+BOOL listboxNearEnd(id scrollview)
+{
+	NSScrollView *sv;
+	NSRect visible;
+
+	sv = toNSScrollView(scrollview);
+	visible = [sv documentVisibleRect];
+	return NSMaxY(visible) + NSHeight(visible) >= NSHeight([[sv documentView] bounds]);
+}
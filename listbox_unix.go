@@ -103,6 +103,16 @@ func getTreeViewFrom(widget *C.GtkWidget) *C.GtkTreeView {
 	return (*C.GtkTreeView)(unsafe.Pointer(wid))
 }
 
+func gListboxTreeView(widget *C.GtkWidget) *C.GtkWidget {
+	return fromgtktreeview(getTreeViewFrom(widget))
+}
+
+// a GtkTreeSelection isn't a GtkWidget, but g_signal_connect() doesn't care; see sysData.make()
+func gListboxSelection(treeview *C.GtkWidget) *C.GtkWidget {
+	sel := C.gtk_tree_view_get_selection(togtktreeview(treeview))
+	return (*C.GtkWidget)(unsafe.Pointer(sel))
+}
+
 func gListboxText(widget *C.GtkWidget) string {
 	var model *C.GtkTreeModel
 	var iter C.GtkTreeIter
@@ -178,7 +188,7 @@ func gListboxSelMultiTexts(widget *C.GtkWidget) (texts []string) {
 	for i := C.guint(0); i < len; i++ {
 		path := (*C.GtkTreePath)(unsafe.Pointer(rows.data))
 		if C.gtk_tree_model_get_iter(model, &iter, path) == C.FALSE {
-			panic("gtk_tree_model_get_iter() failed getting Listbox selected texts; reason unknown")
+			panic(newError(nil, "getting Listbox selected texts (reason unknown)", "gtk_tree_model_get_iter()", nil))
 		}
 		C.gtkTreeModelGet(model, &iter, &gs)
 		texts[i] = fromgstr(gs)
@@ -187,13 +197,37 @@ func gListboxSelMultiTexts(widget *C.GtkWidget) (texts []string) {
 	return texts
 }
 
+// see Listbox; called by our_listbox_key_press_event_callback() on Ctrl+C
+func gListboxCopy(widget *C.GtkWidget) {
+	texts := gListboxSelMultiTexts(widget)
+	if len(texts) == 0 { // don't empty the clipboard for nothing
+		return
+	}
+	newClipboardContents(listboxClipboardData(texts)).set()
+}
+
+func gListboxSelectIndices(widget *C.GtkWidget, indices []int) {
+	var iter C.GtkTreeIter
+
+	tv := getTreeViewFrom(widget)
+	model := C.gtk_tree_view_get_model(tv)
+	sel := C.gtk_tree_view_get_selection(tv)
+	C.gtk_tree_selection_unselect_all(sel)
+	for _, index := range indices {
+		if C.gtk_tree_model_iter_nth_child(model, &iter, (*C.GtkTreeIter)(nil), C.gint(index)) == C.FALSE {
+			panic(newError(nil, fmt.Sprintf("selecting row %d in GTK+ Listbox (no such index or some other error)", index), "gtk_tree_model_iter_nth_child()", nil))
+		}
+		C.gtk_tree_selection_select_iter(sel, &iter)
+	}
+}
+
 func gListboxDelete(widget *C.GtkWidget, index int) {
 	var iter C.GtkTreeIter
 
 	tv := getTreeViewFrom(widget)
 	ls := (*C.GtkListStore)(unsafe.Pointer(C.gtk_tree_view_get_model(tv)))
 	if C.gtk_tree_model_iter_nth_child((*C.GtkTreeModel)(unsafe.Pointer(ls)), &iter, (*C.GtkTreeIter)(nil), C.gint(index)) == C.FALSE {
-		panic(fmt.Errorf("error deleting row %d from GTK+ Listbox: no such index or some other error", index))
+		panic(newError(nil, fmt.Sprintf("deleting row %d from GTK+ Listbox (no such index or some other error)", index), "gtk_tree_model_iter_nth_child()", nil))
 	}
 	C.gtk_list_store_remove(ls, &iter)
 }
@@ -209,3 +243,18 @@ func gListboxLen(widget *C.GtkWidget) int {
 	model := C.gtk_tree_view_get_model(tv)
 	return gtkTreeModelListLen(model)
 }
+
+// see sysData.make()
+func gListboxVAdjustment(widget *C.GtkWidget) *C.GtkWidget {
+	adj := C.gtk_scrolled_window_get_vadjustment((*C.GtkScrolledWindow)(unsafe.Pointer(widget)))
+	return (*C.GtkWidget)(unsafe.Pointer(adj))
+}
+
+// see cSysData.signalNearEnd() for what near and n are
+// the adjustment is in pixels; the page size is the height of what's showing
+func gListboxNearEnd(widget *C.GtkWidget, adj *C.GtkAdjustment) (near bool, n int) {
+	value := C.gtk_adjustment_get_value(adj)
+	page := C.gtk_adjustment_get_page_size(adj)
+	upper := C.gtk_adjustment_get_upper(adj)
+	return value+2*page >= upper, gListboxLen(widget)
+}
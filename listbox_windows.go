@@ -0,0 +1,101 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+/*
+A listbox doesn't tell its parent when it scrolls, so to know when to signal Listbox.NearEnd we subclass it (with the Common Controls 6 subclassing functions; see comctl_windows.go) and check where it is after every message that scrolls it.
+The sysData is the reference data of the subclass.
+The subclass also handles Ctrl+C, which a listbox otherwise ignores.
+*/
+
+var listboxSubclassCallback uintptr
+
+func init() {
+	// not in the var declaration above; listboxSubclassProc() uses it too, so that would be an initialization cycle
+	listboxSubclassCallback = syscall.NewCallback(listboxSubclassProc)
+}
+
+// runs on the UI thread; called by sysData.make()
+func (s *sysData) listboxInit() {
+	r1, _, err := _setWindowSubclass.Call(
+		uintptr(s.hwnd),
+		listboxSubclassCallback,
+		uintptr(0),
+		uintptr(unsafe.Pointer(s)))
+	if r1 == 0 { // failure
+		panic(s.newError("subclassing listbox to watch it scroll", "SetWindowSubclass()", err))
+	}
+}
+
+func listboxSubclassProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM, id uintptr, data uintptr) _LRESULT {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(data))
+	// let the listbox scroll first, then see where it ended up
+	r1, _, _ := _defSubclassProc.Call(
+		uintptr(hwnd),
+		uintptr(uMsg),
+		uintptr(wParam),
+		uintptr(lParam))
+	switch uMsg {
+	case _WM_KEYDOWN:
+		if wParam == 'C' && getModifiers() == Ctrl {
+			s.listboxCopy()
+		}
+		s.signalNearEnd(s.listboxNearEnd())
+	case _WM_VSCROLL, _WM_MOUSEWHEEL:
+		s.signalNearEnd(s.listboxNearEnd())
+	case _WM_NCDESTROY:
+		// this is the last message the listbox gets; the subclass has to be removed before the window is gone
+		_removeWindowSubclass.Call(
+			uintptr(hwnd),
+			listboxSubclassCallback,
+			id)
+	}
+	return _LRESULT(r1)
+}
+
+// runs on the UI thread; see cSysData.signalNearEnd() for what near and n are
+func (s *sysData) listboxNearEnd() (near bool, n int) {
+	var r _RECT
+
+	send := func(msg uintptr, wParam uintptr) int {
+		r1, _, _ := _sendMessage.Call(
+			uintptr(s.hwnd),
+			msg,
+			wParam,
+			uintptr(0))
+		return int(r1)
+	}
+
+	n = send(_LB_GETCOUNT, 0)
+	top := send(_LB_GETTOPINDEX, 0)
+	// all items are the same height
+	// LB_ERR shouldn't happen, but we can't tell where the end is without the height
+	height := send(_LB_GETITEMHEIGHT, 0)
+	if height <= 0 {
+		return false, n
+	}
+	r1, _, err := _getClientRect.Call(
+		uintptr(s.hwnd),
+		uintptr(unsafe.Pointer(&r)))
+	if r1 == 0 { // failure
+		panic(s.newError("getting listbox client rect to see if it's near the end", "GetClientRect()", err))
+	}
+	page := int(r.bottom) / height
+	// top+page is the first item below the visible ones
+	return top+2*page >= n, n
+}
+
+// runs on the UI thread; see Listbox
+func (s *sysData) listboxCopy() {
+	texts := s.doSelectedTexts()
+	if len(texts) == 0 { // don't empty the clipboard for nothing
+		return
+	}
+	newClipboardContents(listboxClipboardData(texts)).set()
+}
@@ -0,0 +1,163 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// numberFormat is how the user's locale writes numbers, as the system gives it to us; see sysNumberFormat() in locale_*.go.
+type numberFormat struct {
+	decimal  string // the decimal separator, such as "." or ","
+	group    string // the digit grouping separator, such as "," or "."; empty if digits aren't grouped
+	grouping []int  // the sizes of the groups of digits before the decimal separator, from the decimal separator out, such as {3} or {3, 2} (for India's 12,34,567)
+	repeat   bool   // whether the last size in grouping repeats for the rest of the digits; otherwise they stay in one group
+}
+
+// FormatNumber formats f with the given number of digits after the decimal separator, rounding if need be, the way the user's locale writes numbers, such as "1,234.56" or "1.234,56".
+// The locale is the one the user picked in the system's settings, which isn't necessarily the one Go (or the environment) says.
+// decimals must not be negative; otherwise, FormatNumber panics.
+func FormatNumber(f float64, decimals int) string {
+	if decimals < 0 {
+		panic(fmt.Errorf("negative number of decimals %d given to FormatNumber()", decimals))
+	}
+	ret := make(chan *numberFormat)
+	defer close(ret)
+	uitask(func() {
+		ret <- sysNumberFormat()
+	})
+	return (<-ret).format(f, decimals)
+}
+
+func (n *numberFormat) format(f float64, decimals int) string {
+	s := strconv.FormatFloat(f, 'f', decimals, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign = "-"
+		s = s[1:]
+	}
+	integer, fraction := s, ""
+	if i := strings.Index(s, "."); i != -1 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+	// build the groups from the right, then put them back in order
+	var groups []string
+	for i := 0; n.group != "" && i < len(n.grouping); {
+		size := n.grouping[i]
+		if size <= 0 || len(integer) <= size {
+			break
+		}
+		groups = append(groups, integer[len(integer)-size:])
+		integer = integer[:len(integer)-size]
+		if i < len(n.grouping)-1 || !n.repeat {
+			i++
+		}
+	}
+	groups = append(groups, integer)
+	for i, j := 0, len(groups)-1; i < j; i, j = i+1, j-1 {
+		groups[i], groups[j] = groups[j], groups[i]
+	}
+	s = sign + strings.Join(groups, n.group)
+	if fraction != "" {
+		s += n.decimal + fraction
+	}
+	return s
+}
+
+// ParseNumber reads a number written the way the user's locale writes them (see FormatNumber), such as "1,234.56" or "1.234,56", as a user would type it into a LineEdit.
+// Digit grouping separators are optional, and ignored wherever they are before the decimal separator; if the locale groups digits with a space, any space will do, as users can't type the one most locales use.
+// Leading and trailing spaces are ignored.
+// ParseNumber returns an error if s is not a number in the user's locale.
+func ParseNumber(s string) (float64, error) {
+	ret := make(chan *numberFormat)
+	defer close(ret)
+	uitask(func() {
+		ret <- sysNumberFormat()
+	})
+	return (<-ret).parse(s)
+}
+
+func (n *numberFormat) parse(s string) (float64, error) {
+	t := strings.TrimSpace(s)
+	sign := ""
+	if strings.HasPrefix(t, "-") || strings.HasPrefix(t, "+") {
+		sign = t[:1]
+		t = t[1:]
+	}
+	integer, fraction := t, ""
+	if i := strings.Index(t, n.decimal); i != -1 {
+		integer, fraction = t[:i], t[i+len(n.decimal):]
+	}
+	if n.group != "" {
+		integer = strings.Replace(integer, n.group, "", -1)
+		// most locales that group with a space use a no-break space, which users can't type
+		if r, _ := utf8.DecodeRuneInString(n.group); unicode.IsSpace(r) {
+			integer = strings.Map(func(r rune) rune {
+				if unicode.IsSpace(r) {
+					return -1
+				}
+				return r
+			}, integer)
+		}
+	}
+	// strconv.ParseFloat() also takes exponents, "Inf", and the like, which a user typing a number doesn't mean, so only let digits through
+	digits := func(s string) bool {
+		for _, r := range s {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+		return true
+	}
+	if integer == "" && fraction == "" || !digits(integer) || !digits(fraction) {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	f, err := strconv.ParseFloat(sign+"0"+integer+"."+fraction+"0", 64)
+	if err != nil { // out of range
+		return 0, fmt.Errorf("%q is not a number: %v", s, err)
+	}
+	return f, nil
+}
+
+// FormatDate formats the date of t (in t's own time zone) the way the user's locale writes short dates, such as "7/9/14" or "09.07.2014".
+// The locale is the one the user picked in the system's settings, which isn't necessarily the one Go (or the environment) says; the date may also be shown in another calendar than the Gregorian one if the user picked one.
+func FormatDate(t time.Time) string {
+	year, month, day := t.Date()
+	ret := make(chan string)
+	defer close(ret)
+	uitask(func() {
+		ret <- sysFormatDate(year, month, day)
+	})
+	return <-ret
+}
+
+// ParseDate reads a date written the way the user's locale writes short dates (see FormatDate), as a user would type it into a LineEdit, and returns midnight of that date in the local time zone.
+// How lenient ParseDate is about the format is implementation-defined, but it is meant to take at least what FormatDate returns.
+// ParseDate returns an error if s is not a date in the user's locale.
+func ParseDate(s string) (time.Time, error) {
+	type result struct {
+		year  int
+		month time.Month
+		day   int
+		ok    bool
+	}
+
+	ret := make(chan result)
+	defer close(ret)
+	uitask(func() {
+		var r result
+
+		r.year, r.month, r.day, r.ok = sysParseDate(strings.TrimSpace(s))
+		ret <- r
+	})
+	r := <-ret
+	if !r.ok {
+		return time.Time{}, fmt.Errorf("%q is not a date", s)
+	}
+	return time.Date(r.year, r.month, r.day, 0, 0, 0, 0, time.Local), nil
+}
@@ -0,0 +1,58 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"time"
+)
+
+// #include "objc_darwin.h"
+import "C"
+
+/*
+On Mac OS X, the number format comes from an NSNumberFormatter and dates are formatted and parsed with an NSDateFormatter, both with the user's current locale, which is what they picked in System Preferences (including any customizations); see locale_darwin.m.
+*/
+
+// runs on the UI thread
+func sysNumberFormat() *numberFormat {
+	var decimal, group C.id
+	var size, secondarySize C.intptr_t
+
+	C.localeNumberFormat(&decimal, &group, &size, &secondarySize)
+	n := new(numberFormat)
+	n.decimal = fromNSString(decimal)
+	if n.decimal == "" {
+		n.decimal = "."
+	}
+	if group != nil {
+		n.group = fromNSString(group)
+	}
+	// the secondary grouping size is for every group after the first; 0 means it's the same as the first
+	if size > 0 {
+		n.grouping = append(n.grouping, int(size))
+		if secondarySize > 0 && secondarySize != size {
+			n.grouping = append(n.grouping, int(secondarySize))
+		}
+		n.repeat = true
+	}
+	return n
+}
+
+// runs on the UI thread
+func sysFormatDate(year int, month time.Month, day int) string {
+	s := C.localeFormatDate(C.intptr_t(year), C.intptr_t(month), C.intptr_t(day))
+	if s == nil {
+		return ""
+	}
+	return fromNSString(s)
+}
+
+// runs on the UI thread
+func sysParseDate(s string) (year int, month time.Month, day int, ok bool) {
+	var y, m, d C.intptr_t
+
+	if C.localeParseDate(toNSString(s), &y, &m, &d) == C.NO {
+		return 0, 0, 0, false
+	}
+	return int(y), time.Month(m), int(d), true
+}
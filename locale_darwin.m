@@ -0,0 +1,85 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <Foundation/NSNumberFormatter.h>
+#import <Foundation/NSDateFormatter.h>
+#import <Foundation/NSCalendar.h>
+#import <Foundation/NSTimeZone.h>
+#import <Foundation/NSLocale.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSString(x) to(NSString, (x))
+
+void localeNumberFormat(id *decimal, id *group, intptr_t *size, intptr_t *secondarySize)
+{
+	NSNumberFormatter *f;
+
+	f = [[NSNumberFormatter alloc] init];
+	[f setLocale:[NSLocale autoupdatingCurrentLocale]];
+	[f setNumberStyle:NSNumberFormatterDecimalStyle];
+	*decimal = [[[f decimalSeparator] retain] autorelease];
+	*group = nil;
+	if ([f usesGroupingSeparator])
+		*group = [[[f groupingSeparator] retain] autorelease];
+	*size = (intptr_t) [f groupingSize];
+	*secondarySize = (intptr_t) [f secondaryGroupingSize];
+	[f release];
+}
+
+/*
+The date formatter goes by the user's calendar, which isn't necessarily Gregorian, so we go between dates and year/month/day through a Gregorian NSCalendar.
+Both work in GMT, so the day doesn't change on the way; we use noon when formatting to stay clear of the day boundary.
+*/
+
+static NSCalendar *gregorian(void)
+{
+	NSCalendar *c;
+
+	c = [[NSCalendar alloc] initWithCalendarIdentifier:NSGregorianCalendar];
+	[c setTimeZone:[NSTimeZone timeZoneForSecondsFromGMT:0]];
+	return [c autorelease];
+}
+
+static NSDateFormatter *shortDateFormatter(void)
+{
+	NSDateFormatter *f;
+
+	f = [[NSDateFormatter alloc] init];
+	[f setLocale:[NSLocale autoupdatingCurrentLocale]];
+	[f setDateStyle:NSDateFormatterShortStyle];
+	[f setTimeStyle:NSDateFormatterNoStyle];
+	[f setTimeZone:[NSTimeZone timeZoneForSecondsFromGMT:0]];
+	[f setLenient:YES];
+	return [f autorelease];
+}
+
+id localeFormatDate(intptr_t year, intptr_t month, intptr_t day)
+{
+	NSDateComponents *c;
+	NSDate *date;
+
+	c = [[NSDateComponents alloc] init];
+	[c setYear:(NSInteger) year];
+	[c setMonth:(NSInteger) month];
+	[c setDay:(NSInteger) day];
+	[c setHour:12];
+	date = [gregorian() dateFromComponents:c];
+	[c release];
+	return [shortDateFormatter() stringFromDate:date];
+}
+
+BOOL localeParseDate(id str, intptr_t *year, intptr_t *month, intptr_t *day)
+{
+	NSDate *date;
+	NSDateComponents *c;
+
+	date = [shortDateFormatter() dateFromString:toNSString(str)];
+	if (date == nil)
+		return NO;
+	c = [gregorian() components:(NSYearCalendarUnit | NSMonthCalendarUnit | NSDayCalendarUnit)
+		fromDate:date];
+	*year = (intptr_t) [c year];
+	*month = (intptr_t) [c month];
+	*day = (intptr_t) [c day];
+	return YES;
+}
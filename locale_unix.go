@@ -0,0 +1,82 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"time"
+	"unsafe"
+)
+
+// // for strptime()
+// #define _XOPEN_SOURCE 700
+// #include <stdlib.h>
+// #include <limits.h>
+// #include <locale.h>
+// #include <time.h>
+import "C"
+
+/*
+On Unix, there's no locale setting of GTK+'s own: gtk_init() calls setlocale(LC_ALL, ""), so we go by the C library's locale, which comes from the environment (LC_ALL, LC_NUMERIC, LC_TIME, and LANG), which is how the desktop passes the user's choice down.
+The number format is from localeconv(); dates are formatted and parsed with strftime() and strptime() with %x, the locale's short date.
+*/
+
+// runs on the UI thread
+func sysNumberFormat() *numberFormat {
+	n := new(numberFormat)
+	lc := C.localeconv()
+	n.decimal = C.GoString(lc.decimal_point)
+	if n.decimal == "" {
+		n.decimal = "."
+	}
+	n.group = C.GoString(lc.thousands_sep)
+	// grouping is one byte per group size; CHAR_MAX means the rest of the digits aren't grouped, and the terminating 0 means the size before it repeats
+	n.repeat = true
+	for _, size := range []byte(C.GoString(lc.grouping)) {
+		if size == C.CHAR_MAX {
+			n.repeat = false
+			break
+		}
+		n.grouping = append(n.grouping, int(size))
+	}
+	return n
+}
+
+// runs on the UI thread
+func sysFormatDate(year int, month time.Month, day int) string {
+	var tm C.struct_tm
+	var buf [256]C.char
+
+	cformat := C.CString("%x")
+	defer C.free(unsafe.Pointer(cformat))
+	tm.tm_year = C.int(year - 1900)
+	tm.tm_mon = C.int(month - time.January)
+	tm.tm_mday = C.int(day)
+	tm.tm_wday = C.int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday())
+	n := C.strftime(&buf[0], C.size_t(len(buf)), cformat, &tm)
+	if n == 0 { // doesn't fit; no locale's short date is this long
+		return ""
+	}
+	return C.GoStringN(&buf[0], C.int(n))
+}
+
+// runs on the UI thread
+func sysParseDate(s string) (year int, month time.Month, day int, ok bool) {
+	var tm C.struct_tm
+
+	cs := C.CString(s)
+	defer C.free(unsafe.Pointer(cs))
+	cformat := C.CString("%x")
+	defer C.free(unsafe.Pointer(cformat))
+	end := C.strptime(cs, cformat, &tm)
+	if end == nil || *end != 0 { // not a date, or not only a date
+		return 0, 0, 0, false
+	}
+	year, month, day = int(tm.tm_year)+1900, time.Month(tm.tm_mon)+time.January, int(tm.tm_mday)
+	// strptime() takes days that aren't in the month, like 31 February; this also catches a string without a day, as tm_mday stays 0
+	if y, m, d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Date(); y != year || m != month || d != day {
+		return 0, 0, 0, false
+	}
+	return year, month, day, true
+}
@@ -0,0 +1,133 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+/*
+On Windows, the number format comes from GetLocaleInfo() and dates are formatted with GetDateFormat(), both for LOCALE_USER_DEFAULT, which is what the user picked in Region and Language (including any customizations), not the language Windows is in.
+Dates are parsed with VarDateFromStr(), which is what OLE Automation (and so Visual Basic) uses; it's lenient about separators, and orders the parts the way the locale's short date does.
+*/
+
+var (
+	_getLocaleInfo  = kernel32.NewProc("GetLocaleInfoW")
+	_getDateFormat  = kernel32.NewProc("GetDateFormatW")
+	_varDateFromStr = oleaut32.NewProc("VarDateFromStr")
+)
+
+type _SYSTEMTIME struct {
+	wYear         uint16
+	wMonth        uint16
+	wDayOfWeek    uint16
+	wDay          uint16
+	wHour         uint16
+	wMinute       uint16
+	wSecond       uint16
+	wMilliseconds uint16
+}
+
+// runs on the UI thread; returns "" (after reporting the error) on failure
+func getLocaleInfo(lcid uintptr, lctype uintptr, what string) string {
+	r1, _, err := _getLocaleInfo.Call(
+		lcid,
+		lctype,
+		uintptr(0),
+		uintptr(0))
+	if r1 == 0 { // failure
+		reportError(newError(nil, "getting "+what+" length", "GetLocaleInfo()", err))
+		return ""
+	}
+	s := make([]uint16, r1) // includes the terminating null
+	r1, _, err = _getLocaleInfo.Call(
+		lcid,
+		lctype,
+		uintptr(unsafe.Pointer(&s[0])),
+		uintptr(len(s)))
+	if r1 == 0 { // failure
+		reportError(newError(nil, "getting "+what, "GetLocaleInfo()", err))
+		return ""
+	}
+	return syscall.UTF16ToString(s)
+}
+
+// runs on the UI thread
+func sysNumberFormat() *numberFormat {
+	n := new(numberFormat)
+	n.decimal = getLocaleInfo(_LOCALE_USER_DEFAULT, _LOCALE_SDECIMAL, "decimal separator")
+	if n.decimal == "" {
+		n.decimal = "."
+	}
+	n.group = getLocaleInfo(_LOCALE_USER_DEFAULT, _LOCALE_STHOUSAND, "digit grouping separator")
+	// LOCALE_SGROUPING is the group sizes separated by semicolons; a trailing 0 means the size before it repeats ("3;0" is every 3 digits, "3;2;0" is 3 then every 2); without one, the rest of the digits aren't grouped
+	for _, g := range strings.Split(getLocaleInfo(_LOCALE_USER_DEFAULT, _LOCALE_SGROUPING, "digit grouping"), ";") {
+		size, err := strconv.Atoi(g)
+		if err != nil {
+			break
+		}
+		if size == 0 {
+			n.repeat = true
+			break
+		}
+		n.grouping = append(n.grouping, size)
+	}
+	return n
+}
+
+// runs on the UI thread
+func sysFormatDate(year int, month time.Month, day int) string {
+	st := _SYSTEMTIME{
+		wYear:  uint16(year),
+		wMonth: uint16(month),
+		wDay:   uint16(day),
+	}
+	r1, _, err := _getDateFormat.Call(
+		uintptr(_LOCALE_USER_DEFAULT),
+		uintptr(_DATE_SHORTDATE),
+		uintptr(unsafe.Pointer(&st)),
+		uintptr(0), // the locale's own format
+		uintptr(0),
+		uintptr(0))
+	if r1 == 0 { // failure; this includes years Windows can't do (before 1601)
+		reportError(newError(nil, "getting formatted date length", "GetDateFormat()", err))
+		return ""
+	}
+	s := make([]uint16, r1) // includes the terminating null
+	r1, _, err = _getDateFormat.Call(
+		uintptr(_LOCALE_USER_DEFAULT),
+		uintptr(_DATE_SHORTDATE),
+		uintptr(unsafe.Pointer(&st)),
+		uintptr(0),
+		uintptr(unsafe.Pointer(&s[0])),
+		uintptr(len(s)))
+	if r1 == 0 { // failure
+		reportError(newError(nil, "formatting date", "GetDateFormat()", err))
+		return ""
+	}
+	return syscall.UTF16ToString(s)
+}
+
+// runs on the UI thread
+func sysParseDate(s string) (year int, month time.Month, day int, ok bool) {
+	var date float64 // an OLE Automation DATE
+
+	if s == "" {
+		return 0, 0, 0, false
+	}
+	r1, _, _ := _varDateFromStr.Call(
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(s))),
+		uintptr(_LOCALE_USER_DEFAULT),
+		uintptr(_VAR_DATEVALUEONLY),
+		uintptr(unsafe.Pointer(&date)))
+	if r1 != 0 { // not S_OK; this is how it says s isn't a date, so it's not an error to report
+		return 0, 0, 0, false
+	}
+	// a DATE is days since 30 December 1899; VAR_DATEVALUEONLY leaves out the time of day, so it's a whole number
+	year, month, day = time.Date(1899, time.December, 30+int(date), 0, 0, 0, 0, time.UTC).Date()
+	return year, month, day, true
+}
@@ -0,0 +1,112 @@
+// 7 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A LogView is a read-only, scrollable view of many lines of text in a monospace font, such as the output of a program, a log file, a diff, or source code.
+// Lines are never wrapped; a LogView scrolls horizontally instead.
+// Lines are separated by "\n".
+// The user can select and copy text in a LogView, but cannot change it.
+// For information on scrollbars, see "Scrollbars" in the Overview.
+type LogView struct {
+	lock          sync.Mutex
+	created       bool
+	sysData       *sysData
+	initText      string
+	textDirection TextDirection
+}
+
+// NewLogView creates a new LogView with the given text.
+func NewLogView(text string) *LogView {
+	return &LogView{
+		sysData:  mksysdata(c_logview),
+		initText: text,
+	}
+}
+
+// Append adds text to the end of the LogView's text.
+// Append does not add a line separator of its own, so to add a line, end text with "\n".
+// Append is meant to be called many times with a little text each time, as when showing the output of a program as it runs; it doesn't copy the text already in the LogView to do its work.
+// After Append, the end of the text is scrolled into view, unless the user has text selected, in which case the selection and the scroll position are left alone.
+func (l *LogView) Append(text string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.created {
+		l.sysData.append(text)
+		return
+	}
+	l.initText += text
+}
+
+// SetText replaces the LogView's text.
+func (l *LogView) SetText(text string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.created {
+		l.sysData.setText(text)
+		return
+	}
+	l.initText = text
+}
+
+// Text returns the LogView's text.
+func (l *LogView) Text() string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.created {
+		return l.sysData.text()
+	}
+	return l.initText
+}
+
+// SetTextDirection sets which way the LogView's text runs; see TextDirection.
+// Right-to-left LogViews also have their vertical scrollbar on the left.
+// This function cannot be called after the Window that contains the LogView has been created, and panics if d isn't one of the TextDirection constants.
+func (l *LogView) SetTextDirection(d TextDirection) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.created {
+		panic(fmt.Errorf("LogView.SetTextDirection() called after window create"))
+	}
+	checkTextDirection(d, "LogView")
+	l.textDirection = d
+}
+
+func (l *LogView) make(window *sysData) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.sysData.control = l
+	l.sysData.textDirection = l.textDirection
+	err := l.sysData.make(window)
+	if err != nil {
+		return err
+	}
+	l.sysData.setText(l.initText)
+	l.created = true
+	return nil
+}
+
+func (l *LogView) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	return l.sysData.singleAllocation(x, y, width, height, l)
+}
+
+func (l *LogView) preferredSize(d *sysSizeData) (width int, height int) {
+	return l.sysData.preferredSize(d)
+}
+
+func (l *LogView) commitResize(a *allocation, d *sysSizeData) {
+	l.sysData.commitResize(a, d)
+}
+
+func (l *LogView) getAuxResizeInfo(d *sysSizeData) {
+	l.sysData.getAuxResizeInfo(d)
+}
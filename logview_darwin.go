@@ -0,0 +1,22 @@
+// 7 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+/*
+A LogView is an NSTextView in an NSScrollView, just like a Listbox is an NSTableView in one.
+*/
+
+func makeLogView(parentWindow C.id, alternate bool, s *sysData) C.id {
+	logview := C.makeLogView()
+	logview = makeScrollView(logview)
+	C.giveScrollViewBezelBorder(logview) // like a Listbox
+	addControl(parentWindow, logview)
+	return logview
+}
+
+func logviewAppend(id C.id, what string, alternate bool) {
+	C.logviewAppend(id, toNSString(what))
+}
@@ -0,0 +1,71 @@
+// 7 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSTextView.h>
+#import <AppKit/NSTextContainer.h>
+#import <AppKit/NSTextStorage.h>
+#import <AppKit/NSScrollView.h>
+#import <AppKit/NSFont.h>
+#import <Foundation/NSAttributedString.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSTextView(x) to(NSTextView, (x))
+#define toNSScrollView(x) to(NSScrollView, (x))
+
+#define logviewInScrollView(x) toNSTextView([toNSScrollView((x)) documentView])
+
+extern NSRect dummyRect;
+
+/*
+An NSTextView wraps lines by default; turning that off takes a few steps, from "Setting Up a Horizontal Scrolling Text View" in the Text System User Interface Layer Programming Guide.
+*/
+id makeLogView(void)
+{
+	NSTextView *tv;
+
+	tv = [[NSTextView alloc]
+		initWithFrame:dummyRect];
+	[tv setEditable:NO];
+	[tv setSelectable:YES];
+	[tv setRichText:NO];
+	[tv setFont:[NSFont userFixedPitchFontOfSize:0]];		// 0 = the user's preferred size
+	[tv setHorizontallyResizable:YES];
+	[tv setVerticallyResizable:YES];
+	[tv setMaxSize:NSMakeSize(FLT_MAX, FLT_MAX)];
+	[tv setAutoresizingMask:(NSViewWidthSizable | NSViewHeightSizable)];
+	[[tv textContainer] setContainerSize:NSMakeSize(FLT_MAX, FLT_MAX)];
+	[[tv textContainer] setWidthTracksTextView:NO];
+	return tv;
+}
+
+void logviewSetText(id scrollview, id text)
+{
+	[logviewInScrollView(scrollview) setString:text];
+}
+
+id logviewText(id scrollview)
+{
+	// copy it; -[NSTextView string] returns the text storage's own string, which changes as the text does
+	return [[[logviewInScrollView(scrollview) string] copy] autorelease];
+}
+
+// -[NSTextView setString:] would copy everything already there; going through the NSTextStorage doesn't
+void logviewAppend(id scrollview, id text)
+{
+	NSTextView *tv;
+	NSAttributedString *as;
+	NSUInteger len;
+
+	tv = logviewInScrollView(scrollview);
+	// strings given to a plain-text NSTextStorage need the text view's font (and its writing direction; see textdirection_darwin.m); they don't pick them up on their own, so give them the typing attributes, which have both
+	as = [[NSAttributedString alloc]
+		initWithString:text
+		attributes:[tv typingAttributes]];
+	[[tv textStorage] appendAttributedString:as];
+	[as release];
+	// see LogView.Append() for why we don't scroll if there's a selection
+	if ([tv selectedRange].length == 0) {
+		len = [[tv textStorage] length];
+		[tv scrollRangeToVisible:NSMakeRange(len, 0)];
+	}
+}
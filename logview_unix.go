@@ -0,0 +1,86 @@
+// +build !windows,!darwin,!plan9
+
+// 7 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+/*
+A LogView is a GtkTextView in a GtkScrolledWindow, like how a Listbox is a GtkTreeView in one.
+We use the GtkTextBuffer's "end" mark, which stays at the end of the text as it grows, to scroll the end into view after an append.
+*/
+
+// #include "gtk_unix.h"
+import "C"
+
+func togtktextview(what *C.GtkWidget) *C.GtkTextView {
+	return (*C.GtkTextView)(unsafe.Pointer(what))
+}
+
+func gLogViewNew() *C.GtkWidget {
+	widget := C.gtk_text_view_new()
+	tv := togtktextview(widget)
+	C.gtk_text_view_set_editable(tv, C.FALSE)
+	C.gtk_text_view_set_cursor_visible(tv, C.FALSE)
+	C.gtk_text_view_set_wrap_mode(tv, C.GTK_WRAP_NONE)
+	// gtk_text_view_set_monospace() is new in GTK+ 3.16, so do it the old way
+	cfont := C.CString("Monospace")
+	defer C.free(unsafe.Pointer(cfont))
+	font := C.pango_font_description_from_string(cfont)
+	C.gtk_widget_override_font(widget, font)
+	C.pango_font_description_free(font)
+	buf := C.gtk_text_view_get_buffer(tv)
+	var end C.GtkTextIter
+	C.gtk_text_buffer_get_end_iter(buf, &end)
+	C.gtk_text_buffer_create_mark(buf, logviewEndMark, &end, C.FALSE) // FALSE: right gravity, so it moves along with text inserted at it
+	scrollarea := C.gtk_scrolled_window_new((*C.GtkAdjustment)(nil), (*C.GtkAdjustment)(nil))
+	C.gtk_scrolled_window_set_shadow_type((*C.GtkScrolledWindow)(unsafe.Pointer(scrollarea)), C.GTK_SHADOW_IN)
+	C.gtk_container_add((*C.GtkContainer)(unsafe.Pointer(scrollarea)), widget)
+	return scrollarea
+}
+
+var logviewEndMark = func() *C.gchar {
+	return togstr(C.CString("ui-logview-end")) // never freed; we need it for as long as the program runs
+}()
+
+func gLogViewTextView(widget *C.GtkWidget) *C.GtkWidget {
+	return C.gtk_bin_get_child((*C.GtkBin)(unsafe.Pointer(widget)))
+}
+
+func gLogViewBuffer(widget *C.GtkWidget) *C.GtkTextBuffer {
+	return C.gtk_text_view_get_buffer(togtktextview(gLogViewTextView(widget)))
+}
+
+func gLogViewSetText(widget *C.GtkWidget, text string) {
+	ctext := C.CString(text)
+	defer C.free(unsafe.Pointer(ctext))
+	C.gtk_text_buffer_set_text(gLogViewBuffer(widget), togstr(ctext), -1)
+}
+
+func gLogViewText(widget *C.GtkWidget) string {
+	var start, end C.GtkTextIter
+
+	buf := gLogViewBuffer(widget)
+	C.gtk_text_buffer_get_bounds(buf, &start, &end)
+	ctext := C.gtk_text_buffer_get_text(buf, &start, &end, C.TRUE)
+	defer C.g_free(C.gpointer(unsafe.Pointer(ctext)))
+	return fromgstr(ctext)
+}
+
+func gLogViewAppend(widget *C.GtkWidget, text string) {
+	var end C.GtkTextIter
+
+	buf := gLogViewBuffer(widget)
+	ctext := C.CString(text)
+	defer C.free(unsafe.Pointer(ctext))
+	C.gtk_text_buffer_get_end_iter(buf, &end)
+	C.gtk_text_buffer_insert(buf, &end, togstr(ctext), -1)
+	// see LogView.Append() for why we don't scroll if there's a selection
+	if !fromgbool(C.gtk_text_buffer_get_has_selection(buf)) {
+		mark := C.gtk_text_buffer_get_mark(buf, logviewEndMark)
+		C.gtk_text_view_scroll_mark_onscreen(togtktextview(gLogViewTextView(widget)), mark)
+	}
+}
@@ -0,0 +1,62 @@
+// 7 july 2014
+
+package ui
+
+import (
+	"strings"
+	"unsafe"
+)
+
+/*
+A LogView is a read-only multi-line edit control. Edit controls want their lines separated by "\r\n", so we convert to that on the way in and back to "\n" on the way out; this is done by sysData.setText(), sysData.text(), and sysData.append().
+*/
+
+// ES_NOHIDESEL keeps the selection visible when the LogView loses focus, so the user can see what they're about to copy (from a menu, for instance)
+const logviewStyle = _ES_MULTILINE | _ES_READONLY | _ES_AUTOHSCROLL | _ES_AUTOVSCROLL | _ES_NOHIDESEL | _WS_HSCROLL | _WS_VSCROLL | controlstyle
+
+func toCRLF(s string) string {
+	return strings.Replace(strings.Replace(s, "\r\n", "\n", -1), "\n", "\r\n", -1)
+}
+
+func fromCRLF(s string) string {
+	return strings.Replace(s, "\r\n", "\n", -1)
+}
+
+// runs on the UI thread; called by sysData.make()
+func (s *sysData) logviewInit() {
+	// the default limit for a multi-line edit control is 32767 characters, which a log gets past quickly; 0 means as much as there is memory for
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_EM_SETLIMITTEXT),
+		uintptr(0),
+		uintptr(0))
+}
+
+// runs on the UI thread; called by sysData.append()
+// replacing an empty selection at the end of the text appends without copying the whole text out and back in like SetWindowText() would
+func (s *sysData) logviewAppend(text string) {
+	var start, end uint32
+
+	send := func(msg uintptr, wParam uintptr, lParam uintptr) uintptr {
+		r1, _, _ := _sendMessage.Call(
+			uintptr(s.hwnd),
+			msg,
+			wParam,
+			lParam)
+		return r1
+	}
+	send(_EM_GETSEL, uintptr(unsafe.Pointer(&start)), uintptr(unsafe.Pointer(&end)))
+	first := send(_EM_GETFIRSTVISIBLELINE, 0, 0)
+	n := send(_WM_GETTEXTLENGTH, 0, 0)
+	send(_EM_SETSEL, n, n)
+	ptext := toUTF16(toCRLF(text))
+	send(_EM_REPLACESEL, uintptr(_FALSE), utf16ToLPARAM(ptext)) // FALSE: can't be undone (it's read-only anyway)
+	if start != end {
+		// the user has text selected; put the selection back and scroll back to where we were (EM_REPLACESEL scrolled to the end)
+		send(_EM_SETSEL, uintptr(start), uintptr(end))
+		now := send(_EM_GETFIRSTVISIBLELINE, 0, 0)
+		send(_EM_LINESCROLL, 0, uintptr(int(first)-int(now)))
+		return
+	}
+	send(_EM_SCROLLCARET, 0, 0)
+}
@@ -0,0 +1,515 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A Menu is a list of MenuItems, shown either as the menu bar of a Window (see Window.SetMenuBar) or as the submenu of a MenuItem (see NewSubmenu).
+// A Menu can only be used once: as the menu bar of one Window, or as the submenu of one MenuItem.
+//
+// On Mac OS X, there is only one menu bar, at the top of the screen; it shows the menu bar of whichever Window is active, after the menu Mac OS X programs always have first (named after the program, with Quit; see NewAboutMenuItem for how to put more there).
+// As Mac OS X only opens submenus from there, every MenuItem of a menu bar should be a submenu.
+//
+// The MenuItems of a Menu can be changed at any time with SetItems, including just before the Menu opens; see SetOpening.
+type Menu struct {
+	lock       sync.Mutex
+	used       bool
+	created    bool
+	items      []*MenuItem // only touched on the UI thread once created
+	destroyed  bool        // only touched on the UI thread
+	opening    func()
+	parentItem *MenuItem // for submenus
+	window     *sysData  // for menu bars; set on the UI thread once the Window has been created
+	sysMenu              // see menu_windows.go, menu_unix.go, and menu_darwin.go
+}
+
+// NewMenu creates a new Menu with the given MenuItems, in order.
+// It panics if one of the MenuItems is already in a Menu.
+func NewMenu(items ...*MenuItem) *Menu {
+	m := &Menu{
+		items: items,
+	}
+	m.claim(items, "NewMenu()")
+	return m
+}
+
+func (m *Menu) claim(items []*MenuItem, what string) {
+	for _, i := range items {
+		i.lock.Lock()
+		if i.parent != nil {
+			i.lock.Unlock()
+			panic(fmt.Errorf("MenuItem %q passed to %s is already in a Menu", i.text, what))
+		}
+		i.parent = m
+		i.lock.Unlock()
+	}
+}
+
+// SetItems replaces the MenuItems of the Menu with items, in order.
+// MenuItems taken out of a Menu that's in a Window are destroyed, as if their Menu was replaced with Window.SetMenuBar(); otherwise, they can be put in a Menu again.
+// It panics if one of the MenuItems is already in a Menu; the MenuItems of this Menu only count if it's in a Window.
+func (m *Menu) SetItems(items ...*MenuItem) {
+	m.lock.Lock()
+	if !m.created {
+		defer m.lock.Unlock()
+		for _, i := range m.items {
+			i.lock.Lock()
+			i.parent = nil
+			i.lock.Unlock()
+		}
+		m.items = nil
+		m.claim(items, "Menu.SetItems()")
+		m.items = items
+		return
+	}
+	m.lock.Unlock()
+	// the old MenuItems of a created Menu are only known to the UI thread, so they stay claimed until they're destroyed there
+	m.claim(items, "Menu.SetItems()")
+	markCreated(items)
+	menutask(func() {
+		m.replace(items)
+	})
+}
+
+// SetOpening sets a function to run every time the Menu is about to open, so it can change its MenuItems (with SetItems and the methods of MenuItem) first; the change shows in the Menu that opens.
+// Pass nil to stop.
+// This only happens for submenus; a menu bar is always open.
+// The function runs on the UI thread, and the Menu waits for it, so it has to be quick, and it must not call any function or method of package ui other than the methods of Menu and MenuItem and the functions that make them.
+func (m *Menu) SetOpening(f func()) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.opening = f
+}
+
+type menuItemKind int
+
+const (
+	menuItemNormal menuItemKind = iota
+	menuItemCheck
+	menuItemSeparator
+	menuItemSubmenu
+	menuItemRadio
+)
+
+// A MenuItem is an item of a Menu: something the user can pick, one that can be checked and unchecked, a separator, or a submenu.
+// Once the Menu it's in is in a Window, a MenuItem is created along with it; until then, its methods just remember what to show.
+// The methods of a MenuItem can be called from the function given to Menu.SetOpening(); see there.
+type MenuItem struct {
+	// Clicked is signaled when the user picks the MenuItem; for a check or radio item, that's after it's been checked or unchecked.
+	// It is never signaled for separators and submenus, or for MenuItems that are disabled.
+	Clicked chan struct{}
+
+	lock    sync.Mutex
+	created bool
+	parent  *Menu
+
+	// only touched on the UI thread once created
+	kind     menuItemKind
+	text     string
+	checked  bool
+	disabled bool
+	submenu  *Menu
+	id       uintptr // see newMenuItemID(); 0 once destroyed
+	action   func()  // for the MenuItems package ui makes itself; runs on the UI thread before Clicked is signaled
+	shortcut menuShortcut
+	role     appMenuRole // see appmenu.go
+	sysMenuItem
+}
+
+// a keyboard shortcut shown next to a MenuItem: Ctrl (Command on Mac OS X) plus key, which is an uppercase letter or punctuation, plus Shift if shift
+// only the MenuItems of NewEditMenu() and NewPreferencesMenuItem() have these for now, and only Mac OS X acts on them (elsewhere the text controls handle the keys themselves); see the menu_*.go files
+type menuShortcut struct {
+	key   rune // 0 for none
+	shift bool
+}
+
+func newMenuItem(kind menuItemKind, text string) *MenuItem {
+	return &MenuItem{
+		Clicked: newEvent(),
+		kind:    kind,
+		text:    text,
+	}
+}
+
+// NewMenuItem creates a new MenuItem that the user can pick.
+func NewMenuItem(text string) *MenuItem {
+	return newMenuItem(menuItemNormal, text)
+}
+
+// NewCheckMenuItem creates a new MenuItem that the user can check and uncheck by picking it, starting checked if checked is true.
+func NewCheckMenuItem(text string, checked bool) *MenuItem {
+	i := newMenuItem(menuItemCheck, text)
+	i.checked = checked
+	return i
+}
+
+// NewRadioMenuItem creates a new MenuItem that the user can check by picking it, starting checked if checked is true.
+// The radio items right next to each other in a Menu are a group: checking one of them unchecks the others.
+// If more than one radio item of a group starts checked, only the first one is.
+func NewRadioMenuItem(text string, checked bool) *MenuItem {
+	i := newMenuItem(menuItemRadio, text)
+	i.checked = checked
+	return i
+}
+
+// NewMenuSeparator creates a new MenuItem that is a line between the MenuItems around it.
+func NewMenuSeparator() *MenuItem {
+	return newMenuItem(menuItemSeparator, "")
+}
+
+// NewSubmenu creates a new MenuItem that opens submenu.
+// It panics if submenu is already used elsewhere.
+func NewSubmenu(text string, submenu *Menu) *MenuItem {
+	i := newMenuItem(menuItemSubmenu, text)
+	submenu.lock.Lock()
+	defer submenu.lock.Unlock()
+	if submenu.used {
+		panic(fmt.Errorf("Menu passed to NewSubmenu(%q) is already used", text))
+	}
+	submenu.used = true
+	submenu.parentItem = i
+	i.submenu = submenu
+	return i
+}
+
+// runs f, which reads or changes the state of i; the lock is never held across a uitask, so the UI thread can lock a MenuItem (see Menu.SetOpening())
+func (i *MenuItem) do(f func()) {
+	i.lock.Lock()
+	if !i.created {
+		defer i.lock.Unlock()
+		f()
+		return
+	}
+	i.lock.Unlock()
+	menutask(f)
+}
+
+// runs f on the UI thread and waits for it, or just runs f if this is the UI thread, as it is for the function given to Menu.SetOpening()
+func menutask(f func()) {
+	if onUIThread() {
+		f()
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		f()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// true if i has been created and not yet destroyed, so its sys* methods can be called; only meaningful on the UI thread
+func (i *MenuItem) alive() bool {
+	return i.id != 0
+}
+
+// Text returns the text of the MenuItem.
+func (i *MenuItem) Text() (text string) {
+	i.do(func() {
+		text = i.text
+	})
+	return text
+}
+
+// SetText changes the text of the MenuItem; for a submenu, it's also the title of the submenu.
+// It does nothing for separators.
+func (i *MenuItem) SetText(text string) {
+	i.do(func() {
+		if i.kind == menuItemSeparator {
+			return
+		}
+		i.text = text
+		if i.alive() {
+			i.sysSetText()
+		}
+	})
+}
+
+// Enabled returns whether the MenuItem is enabled; MenuItems start enabled.
+func (i *MenuItem) Enabled() (enabled bool) {
+	i.do(func() {
+		enabled = !i.disabled
+	})
+	return enabled
+}
+
+// SetEnabled enables or disables the MenuItem; the user can't pick a disabled MenuItem, or open a disabled submenu.
+func (i *MenuItem) SetEnabled(enabled bool) {
+	i.do(func() {
+		i.disabled = !enabled
+		if i.alive() {
+			i.sysSetEnabled()
+		}
+	})
+}
+
+// Checked returns whether a MenuItem made with NewCheckMenuItem or NewRadioMenuItem is checked; it returns false for every other MenuItem.
+func (i *MenuItem) Checked() (checked bool) {
+	i.do(func() {
+		checked = i.checked
+	})
+	return checked
+}
+
+// SetChecked checks or unchecks a MenuItem made with NewCheckMenuItem or NewRadioMenuItem, without signaling Clicked; checking a radio item unchecks the others in its group.
+// It does nothing for every other MenuItem.
+func (i *MenuItem) SetChecked(checked bool) {
+	i.do(func() {
+		if i.kind != menuItemCheck && i.kind != menuItemRadio {
+			return
+		}
+		if !i.created {
+			// the other radio items of the group are sorted out when created; see Menu.appendItems()
+			i.checked = checked
+			return
+		}
+		i.setChecked(checked)
+	})
+}
+
+// runs on the UI thread once created
+func (i *MenuItem) setChecked(checked bool) {
+	if checked && i.kind == menuItemRadio && i.parent != nil {
+		for _, j := range i.parent.radioGroup(i) {
+			if j != i && j.checked {
+				j.checked = false
+				if j.alive() {
+					j.sysSetChecked()
+				}
+			}
+		}
+	}
+	i.checked = checked
+	if i.alive() {
+		i.sysSetChecked()
+	}
+}
+
+// runs on the UI thread once created; returns the radio items around i, including i
+func (m *Menu) radioGroup(i *MenuItem) []*MenuItem {
+	start, end := -1, -1
+	for n, j := range m.items {
+		if j == i {
+			start, end = n, n+1
+			break
+		}
+	}
+	if start == -1 { // no longer in m
+		return nil
+	}
+	for start > 0 && m.items[start-1].kind == menuItemRadio {
+		start--
+	}
+	for end < len(m.items) && m.items[end].kind == menuItemRadio {
+		end++
+	}
+	return m.items[start:end]
+}
+
+// SetMenuBar sets the menu bar of the Window to m, replacing the one it had, if any; pass nil to remove it.
+// A Menu that is replaced or removed is destroyed: it can't be used again, and its MenuItems are never signaled again.
+// It panics if m is already used elsewhere, or if the Window has been destroyed.
+func (w *Window) SetMenuBar(m *Menu) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.destroyed {
+		panic("attempt to set menu bar of Window that has been destroyed")
+	}
+	if m != nil {
+		m.lock.Lock()
+		if m.used {
+			m.lock.Unlock()
+			panic("Menu passed to Window.SetMenuBar() is already used")
+		}
+		m.used = true
+		m.lock.Unlock()
+	}
+	if !w.created {
+		w.initMenuBar = m
+		return
+	}
+	w.sysData.changeMenuBar(m)
+}
+
+// called by Window.SetMenuBar() and Window.Create() with the Window locked
+func (s *sysData) changeMenuBar(m *Menu) {
+	if m != nil {
+		m.lock.Lock()
+		m.created = true
+		m.lock.Unlock()
+		markCreated(m.items)
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		old := s.menuBar
+		s.menuBar = m
+		if m != nil {
+			m.window = s
+			m.create(true)
+		}
+		s.setMenuBar(old, m)
+		if old != nil {
+			old.destroy()
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// from here on, the methods of items and their submenus go through the UI thread; see MenuItem.do()
+// this doesn't run on the UI thread, and items is either new or belongs to a Menu that is only now being created, so nothing else changes it
+func markCreated(items []*MenuItem) {
+	for _, i := range items {
+		i.lock.Lock()
+		i.created = true
+		i.lock.Unlock()
+		if i.submenu != nil {
+			i.submenu.lock.Lock()
+			i.submenu.created = true
+			i.submenu.lock.Unlock()
+			markCreated(i.submenu.items)
+		}
+	}
+}
+
+// MenuItems by id, so the platform code can find them when the user picks one, and Menus by the key the platform code uses for them (see sysMenu.key()), so it can find them when they open; only touched on the UI thread
+var (
+	menuItems  = make(map[uintptr]*MenuItem)
+	nextMenuID uintptr
+	menus      = make(map[uintptr]*Menu)
+)
+
+// Windows only has 16 bits for the id in WM_COMMAND, so ids are reused once the Menus that had them are destroyed; 0 is never used
+func newMenuItemID(i *MenuItem) uintptr {
+	for {
+		nextMenuID = (nextMenuID + 1) & 0xFFFF
+		if nextMenuID == 0 {
+			continue
+		}
+		if _, ok := menuItems[nextMenuID]; !ok {
+			break
+		}
+	}
+	menuItems[nextMenuID] = i
+	return nextMenuID
+}
+
+// runs on the UI thread; creates the platform's menu for m and everything in it
+func (m *Menu) create(bar bool) {
+	m.sysCreate(bar)
+	menus[m.key()] = m
+	m.appendItems()
+}
+
+// runs on the UI thread
+func (m *Menu) appendItems() {
+	checked := false // for the current radio group
+	for _, i := range m.items {
+		if i.kind != menuItemRadio {
+			checked = false
+		} else if i.checked {
+			if checked {
+				i.checked = false
+			}
+			checked = true
+		}
+		i.id = newMenuItemID(i)
+		if i.submenu != nil {
+			i.submenu.create(false)
+		}
+		m.sysAppend(i)
+	}
+}
+
+// runs on the UI thread
+func (m *Menu) replace(items []*MenuItem) {
+	if m.destroyed { // its Window took it away before we got here; items stay as they are, never to be created
+		m.items = items
+		return
+	}
+	for n := len(m.items) - 1; n >= 0; n-- {
+		m.sysRemove(n)
+		m.items[n].destroy()
+	}
+	m.items = items
+	m.appendItems()
+}
+
+// runs on the UI thread
+func (m *Menu) destroy() {
+	for _, i := range m.items {
+		i.destroy()
+	}
+	delete(menus, m.key())
+	m.destroyed = true
+	m.sysDestroy()
+}
+
+// runs on the UI thread; the platform menu item itself goes along with the platform menu (or with Menu.sysRemove())
+func (i *MenuItem) destroy() {
+	if i.submenu != nil {
+		i.submenu.destroy()
+	}
+	delete(menuItems, i.id)
+	i.id = 0
+}
+
+// called by the platform code on the UI thread when the Menu with the given key (see sysMenu.key()) is about to open
+func menuOpening(key uintptr) {
+	m, ok := menus[key]
+	if !ok {
+		return
+	}
+	m.lock.Lock()
+	f := m.opening
+	m.lock.Unlock()
+	if f != nil {
+		f()
+	}
+}
+
+// runs on the UI thread; returns the Window whose menu bar i is in, or nil if i isn't in one (any more)
+func (i *MenuItem) window() *sysData {
+	for m := i.parent; m != nil; m = m.parentItem.parent {
+		if m.window != nil {
+			return m.window
+		}
+		if m.parentItem == nil {
+			break
+		}
+	}
+	return nil
+}
+
+// called by the platform code on the UI thread when the user picks the MenuItem with the given id
+func menuItemClicked(id uintptr) {
+	i, ok := menuItems[id]
+	if !ok { // already destroyed
+		return
+	}
+	if i.disabled {
+		return
+	}
+	switch i.kind {
+	case menuItemCheck:
+		i.setChecked(!i.checked)
+	case menuItemRadio:
+		i.setChecked(true)
+	case menuItemSeparator, menuItemSubmenu:
+		return
+	}
+	if i.role == appMenuQuit {
+		appQuit()
+		return
+	}
+	if i.action != nil {
+		i.action()
+	}
+	sendEvent(i.Clicked)
+}
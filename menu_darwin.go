@@ -0,0 +1,113 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// #include "objc_darwin.h"
+import "C"
+
+// see menu_darwin.m
+
+type sysMenu struct {
+	id C.id
+}
+
+type sysMenuItem struct {
+	item C.id
+}
+
+func (m *Menu) sysCreate(bar bool) {
+	m.id = C.makeMenu(toBOOL(bar), appDelegate)
+}
+
+func (m *Menu) key() uintptr {
+	return uintptr(unsafe.Pointer(m.id))
+}
+
+func (m *Menu) sysAppend(i *MenuItem) {
+	var submenu C.id
+
+	switch i.role {
+	case appMenuQuit: // the application menu already has one; see NewQuitMenuItem()
+		i.item = nil
+		return
+	case appMenuAbout, appMenuPreferences:
+		i.item = C.appMenuAppend(m.bar().id, C.intptr_t(i.role), toNSString(i.text), C.intptr_t(i.id), toBOOL(!i.disabled), appDelegate)
+	default:
+		if i.submenu != nil {
+			submenu = i.submenu.id
+		}
+		i.item = C.menuAppend(m.id, C.intptr_t(i.kind), toNSString(i.text), C.intptr_t(i.id), toBOOL(i.checked), toBOOL(!i.disabled), submenu, appDelegate)
+	}
+	if i.shortcut.key != 0 {
+		key := strings.ToLower(string(i.shortcut.key))
+		if i.shortcut.shift {
+			key = string(i.shortcut.key)
+		}
+		C.menuItemSetShortcut(i.item, toNSString(key))
+	}
+}
+
+func (m *Menu) sysRemove(n int) {
+	switch m.items[n].role {
+	case appMenuQuit:
+		// never added
+	case appMenuAbout, appMenuPreferences:
+		C.appMenuRemove(m.bar().id, m.items[n].item)
+	default:
+		C.menuRemove(m.id, m.items[n].item)
+	}
+}
+
+// runs on the UI thread; returns the menu bar m is in, whose application menu gets About and Preferences
+func (m *Menu) bar() *Menu {
+	for m.parentItem != nil {
+		m = m.parentItem.parent
+	}
+	return m
+}
+
+func (m *Menu) sysDestroy() {
+	if m.parentItem != nil { // released with its parent
+		return
+	}
+	C.menuRelease(m.id)
+}
+
+func (i *MenuItem) sysSetChecked() {
+	C.menuItemSetChecked(i.item, toBOOL(i.checked))
+}
+
+func (i *MenuItem) sysSetText() {
+	C.menuItemSetText(i.item, toNSString(i.text))
+}
+
+func (i *MenuItem) sysSetEnabled() {
+	C.menuItemSetEnabled(i.item, toBOOL(!i.disabled))
+}
+
+func (s *sysData) setMenuBar(old *Menu, m *Menu) {
+	var oldid, id C.id
+
+	if old != nil {
+		oldid = old.id
+	}
+	if m != nil {
+		id = m.id
+	}
+	C.windowSetMenuBar(s.id, oldid, id)
+}
+
+// runs on the UI thread when the Window becomes the key window; see appDelegate_windowDidBecomeKey()
+func (s *sysData) showMenuBar() {
+	var id C.id
+
+	if s.menuBar != nil {
+		id = s.menuBar.id
+	}
+	C.windowShowMenuBar(s.id, id)
+}
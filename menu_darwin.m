@@ -0,0 +1,208 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSApplication.h>
+#import <AppKit/NSMenu.h>
+#import <AppKit/NSMenuItem.h>
+#import <AppKit/NSWindow.h>
+#import <Foundation/NSProcessInfo.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSMenu(x) to(NSMenu, (x))
+#define toNSMenuItem(x) to(NSMenuItem, (x))
+#define toNSWindow(x) to(NSWindow, (x))
+
+/*
+There is only one menu bar, [NSApp mainMenu], so the menu bar of a Window is an NSMenu of its own that becomes the main menu whenever the Window becomes the key window (see appDelegate_windowDidBecomeKey()); when a Window without one is key, the main menu is defaultMainMenu().
+The first item of the main menu is always shown as the application menu, named after the program, so every menu bar starts with one of those, with Quit; the Window's MenuItems come after it.
+The About and Preferences MenuItems of a menu bar go in its application menu instead of their own Menu (see appMenuAppend()); its Quit is the only one there is.
+We enable and disable menu items ourselves, so menus don't ask for validateMenuItem:.
+Each NSMenuItem has the id of its MenuItem as its tag, which the app delegate's menuItemClicked: passes on.
+Radio items are check items; Mac OS X draws both with a check mark.
+Submenus have the app delegate as their delegate, so its menuNeedsUpdate: can run menuOpening(); the key of a Menu is its NSMenu.
+*/
+
+// the kinds of menuItemKind in menu.go
+enum {
+	menuItemNormal,
+	menuItemCheck,
+	menuItemSeparator,
+	menuItemSubmenu,
+	menuItemRadio,
+};
+
+// the roles of appMenuRole in appmenu.go
+enum {
+	appMenuNone,
+	appMenuAbout,
+	appMenuPreferences,
+	appMenuQuit,
+};
+
+static NSMenuItem *appMenuItem(void)
+{
+	NSMenuItem *item, *quit;
+	NSMenu *menu;
+	NSString *name;
+
+	name = [[NSProcessInfo processInfo] processName];
+	menu = [[NSMenu alloc] initWithTitle:name];
+	quit = [[NSMenuItem alloc] initWithTitle:[@"Quit " stringByAppendingString:name]
+		action:@selector(terminate:)		// which asks the app delegate, so this is AppQuit; see appDelegate_applicationShouldTerminate()
+		keyEquivalent:@"q"];
+	[quit setTarget:NSApp];
+	[menu addItem:quit];
+	[quit release];		// the menu has it now
+	item = [[NSMenuItem alloc] initWithTitle:name action:NULL keyEquivalent:@""];
+	[item setSubmenu:menu];
+	[menu release];		// the item has it now
+	return item;
+}
+
+id makeMenu(BOOL bar, id delegate)
+{
+	NSMenu *menu;
+	NSMenuItem *app;
+
+	menu = [[NSMenu alloc] initWithTitle:@""];
+	[menu setAutoenablesItems:NO];
+	if (bar) {
+		app = appMenuItem();
+		[menu addItem:app];
+		[app release];
+	} else
+		[menu setDelegate:delegate];
+	return menu;
+}
+
+static id defaultMainMenu(void)
+{
+	static id menu = nil;
+
+	if (menu == nil)
+		menu = makeMenu(YES, nil);
+	return menu;
+}
+
+// called once, by initCocoa(), so the program has its application menu before any Window does
+void initMainMenu(void)
+{
+	[NSApp setMainMenu:toNSMenu(defaultMainMenu())];
+}
+
+id menuAppend(id menu, intptr_t kind, id text, intptr_t tag, BOOL checked, BOOL enabled, id submenu, id delegate)
+{
+	NSMenuItem *item;
+
+	if (kind == menuItemSeparator) {
+		item = [NSMenuItem separatorItem];
+		[toNSMenu(menu) addItem:item];
+		return item;
+	}
+	item = [[NSMenuItem alloc] initWithTitle:((NSString *) text) action:NULL keyEquivalent:@""];
+	if (kind == menuItemSubmenu) {
+		// the menu bar shows the title of the submenu, not of the item
+		[toNSMenu(submenu) setTitle:((NSString *) text)];
+		[item setSubmenu:toNSMenu(submenu)];
+		[toNSMenu(submenu) release];		// the item has it now
+	} else {
+		[item setTarget:delegate];
+		[item setAction:@selector(menuItemClicked:)];
+		[item setTag:(NSInteger) tag];
+	}
+	if ((kind == menuItemCheck || kind == menuItemRadio) && checked)
+		[item setState:NSOnState];
+	[item setEnabled:enabled];
+	[toNSMenu(menu) addItem:item];
+	[item release];		// the menu has it now
+	return item;
+}
+
+// About goes at the top of the application menu and Preferences right above Quit, which is always last, each with a separator after it
+id appMenuAppend(id bar, intptr_t role, id text, intptr_t tag, BOOL enabled, id delegate)
+{
+	NSMenu *app;
+	NSMenuItem *item;
+	NSInteger index;
+
+	app = [[toNSMenu(bar) itemAtIndex:0] submenu];
+	index = 0;
+	if (role == appMenuPreferences)
+		index = [app numberOfItems] - 1;
+	item = [[NSMenuItem alloc] initWithTitle:((NSString *) text) action:@selector(menuItemClicked:) keyEquivalent:@""];
+	[item setTarget:delegate];
+	[item setTag:(NSInteger) tag];
+	[item setEnabled:enabled];
+	[app insertItem:[NSMenuItem separatorItem] atIndex:index];
+	[app insertItem:item atIndex:index];
+	[item release];		// the menu has it now
+	return item;
+}
+
+// this releases the item, along with the separator after it
+void appMenuRemove(id bar, id item)
+{
+	NSMenu *app;
+	NSInteger index;
+
+	app = [[toNSMenu(bar) itemAtIndex:0] submenu];
+	index = [app indexOfItem:toNSMenuItem(item)];
+	if (index == -1)
+		return;
+	[app removeItemAtIndex:index];
+	[app removeItemAtIndex:index];
+}
+
+void menuItemSetChecked(id item, BOOL checked)
+{
+	if (checked)
+		[toNSMenuItem(item) setState:NSOnState];
+	else
+		[toNSMenuItem(item) setState:NSOffState];
+}
+
+// this releases the item, and with it its submenu
+void menuRemove(id menu, id item)
+{
+	[toNSMenu(menu) removeItem:toNSMenuItem(item)];
+}
+
+void menuItemSetText(id item, id text)
+{
+	[toNSMenuItem(item) setTitle:((NSString *) text)];
+	if ([toNSMenuItem(item) hasSubmenu])
+		[[toNSMenuItem(item) submenu] setTitle:((NSString *) text)];
+}
+
+// Command is the default modifier; an uppercase key adds Shift
+void menuItemSetShortcut(id item, id key)
+{
+	[toNSMenuItem(item) setKeyEquivalent:((NSString *) key)];
+}
+
+void menuItemSetEnabled(id item, BOOL enabled)
+{
+	[toNSMenuItem(item) setEnabled:enabled];
+}
+
+void menuRelease(id menu)
+{
+	[toNSMenu(menu) release];
+}
+
+// called when window gets a new menu bar, which may be nil; old, if not nil, is released right after
+void windowSetMenuBar(id window, id old, id bar)
+{
+	if (bar == nil)
+		bar = defaultMainMenu();
+	if ([toNSWindow(window) isKeyWindow] || (old != nil && [NSApp mainMenu] == old))
+		[NSApp setMainMenu:toNSMenu(bar)];
+}
+
+// called when window becomes the key window
+void windowShowMenuBar(id window, id bar)
+{
+	if (bar == nil)
+		bar = defaultMainMenu();
+	[NSApp setMainMenu:toNSMenu(bar)];
+}
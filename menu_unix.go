@@ -0,0 +1,174 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern void our_menuitem_activate_callback(GtkMenuItem *, gpointer);
+// extern void our_menu_show_callback(GtkWidget *, gpointer);
+// /* the id of the MenuItem is the data of the signal, which cgo can't turn into a pointer without complaint */
+// static inline void gtkMenuItemConnect(GtkWidget *item, guintptr id)
+// {
+// 	g_signal_connect(item, "activate", G_CALLBACK(our_menuitem_activate_callback), GUINT_TO_POINTER(id));
+// }
+// static inline guintptr gtkMenuItemID(gpointer data)
+// {
+// 	return (guintptr) data;
+// }
+// /* this only shows the shortcut; see menuShortcut */
+// static inline void gtkMenuItemSetShortcut(GtkWidget *item, gunichar key, gboolean shift)
+// {
+// 	GdkModifierType mods = GDK_CONTROL_MASK;
+//
+// 	if (shift)
+// 		mods |= GDK_SHIFT_MASK;
+// 	gtk_accel_label_set_accel(GTK_ACCEL_LABEL(gtk_bin_get_child(GTK_BIN(item))), gdk_unicode_to_keyval(g_unichar_tolower(key)), mods);
+// }
+// static inline void gtkMenuConnect(GtkWidget *menu)
+// {
+// 	g_signal_connect(menu, "show", G_CALLBACK(our_menu_show_callback), NULL);
+// }
+import "C"
+
+/*
+A menu bar is a GtkMenuBar, packed above the layout of the Window in its box (see gtkNewWindowBox()); submenus are GtkMenus set as the submenus of their GtkMenuItems.
+Picking a GtkMenuItem sends "activate"; so does gtk_check_menu_item_set_active() when it changes the check, so sysMenuItem.setting keeps ours from being taken for the user's.
+GtkCheckMenuItem checks and unchecks itself before our "activate" handler runs, so menuItemClicked() setting it again does nothing.
+Radio items are GtkCheckMenuItems drawn as radio items, not GtkRadioMenuItems, since menuItemClicked() keeps track of the groups itself.
+A GtkMenu is shown just before it opens, so that's when menuOpening() runs; its key is the GtkMenu.
+gtk_widget_destroy() destroys a menu along with its items and their submenus, and takes a menu bar out of the Window.
+*/
+
+type sysMenu struct {
+	widget *C.GtkWidget
+}
+
+type sysMenuItem struct {
+	widget  *C.GtkWidget
+	setting bool
+}
+
+func togtkmenushell(what *C.GtkWidget) *C.GtkMenuShell {
+	return (*C.GtkMenuShell)(unsafe.Pointer(what))
+}
+
+func togtkmenuitem(what *C.GtkWidget) *C.GtkMenuItem {
+	return (*C.GtkMenuItem)(unsafe.Pointer(what))
+}
+
+func togtkcheckmenuitem(what *C.GtkWidget) *C.GtkCheckMenuItem {
+	return (*C.GtkCheckMenuItem)(unsafe.Pointer(what))
+}
+
+func (m *Menu) sysCreate(bar bool) {
+	if bar {
+		m.widget = C.gtk_menu_bar_new()
+		return
+	}
+	m.widget = C.gtk_menu_new()
+	C.gtkMenuConnect(m.widget)
+}
+
+func (m *Menu) key() uintptr {
+	return uintptr(unsafe.Pointer(m.widget))
+}
+
+func (m *Menu) sysAppend(i *MenuItem) {
+	if i.kind == menuItemSeparator {
+		i.widget = C.gtk_separator_menu_item_new()
+	} else {
+		ctext := C.CString(i.text)
+		defer C.free(unsafe.Pointer(ctext))
+		if i.kind == menuItemCheck || i.kind == menuItemRadio {
+			i.widget = C.gtk_check_menu_item_new_with_label(togstr(ctext))
+			if i.kind == menuItemRadio {
+				C.gtk_check_menu_item_set_draw_as_radio(togtkcheckmenuitem(i.widget), C.TRUE)
+			}
+			i.sysSetChecked()
+		} else {
+			i.widget = C.gtk_menu_item_new_with_label(togstr(ctext))
+		}
+		if i.kind == menuItemSubmenu {
+			C.gtk_menu_item_set_submenu(togtkmenuitem(i.widget), i.submenu.widget)
+		} else {
+			C.gtkMenuItemConnect(i.widget, C.guintptr(i.id))
+		}
+	}
+	if i.disabled {
+		i.sysSetEnabled()
+	}
+	if i.shortcut.key != 0 {
+		C.gtkMenuItemSetShortcut(i.widget, C.gunichar(i.shortcut.key), togbool(i.shortcut.shift))
+	}
+	C.gtk_menu_shell_append(togtkmenushell(m.widget), i.widget)
+	C.gtk_widget_show(i.widget)
+}
+
+// this also destroys the submenu of the item
+func (m *Menu) sysRemove(n int) {
+	C.gtk_widget_destroy(m.items[n].widget)
+}
+
+func (m *Menu) sysDestroy() {
+	if m.parentItem != nil { // destroyed with its parent
+		return
+	}
+	C.gtk_widget_destroy(m.widget)
+}
+
+func (i *MenuItem) sysSetChecked() {
+	i.setting = true
+	defer func() {
+		i.setting = false
+	}()
+	C.gtk_check_menu_item_set_active(togtkcheckmenuitem(i.widget), togbool(i.checked))
+}
+
+func (i *MenuItem) sysSetText() {
+	ctext := C.CString(i.text)
+	defer C.free(unsafe.Pointer(ctext))
+	C.gtk_menu_item_set_label(togtkmenuitem(i.widget), togstr(ctext))
+}
+
+func (i *MenuItem) sysSetEnabled() {
+	C.gtk_widget_set_sensitive(i.widget, togbool(!i.disabled))
+}
+
+// old is destroyed after this, which takes it out of the Window; hiding it now leaves it out of gtkWindowBarsHeight() already
+func (s *sysData) setMenuBar(old *Menu, m *Menu) {
+	if old != nil {
+		C.gtk_widget_hide(old.widget)
+	}
+	if m != nil {
+		box := C.gtk_bin_get_child((*C.GtkBin)(unsafe.Pointer(s.widget)))
+		C.gtk_box_pack_start((*C.GtkBox)(unsafe.Pointer(box)), m.widget, C.FALSE, C.FALSE, 0)
+		C.gtk_box_reorder_child((*C.GtkBox)(unsafe.Pointer(box)), m.widget, 0)
+		C.gtk_widget_show(m.widget)
+	}
+	// the window stays the same size, so there's no "configure-event" to lay it out again for the new height of the layout
+	if s.allocate != nil {
+		width, height := gtk_window_get_size(s.widget)
+		s.resizeWindow(width, height-gtkWindowBarsHeight(s.widget, s.container))
+	}
+}
+
+//export our_menuitem_activate_callback
+func our_menuitem_activate_callback(item *C.GtkMenuItem, data C.gpointer) {
+	defer recoverUIPanic()
+	id := uintptr(C.gtkMenuItemID(data))
+	if i, ok := menuItems[id]; ok && i.setting {
+		return
+	}
+	menuItemClicked(id)
+}
+
+//export our_menu_show_callback
+func our_menu_show_callback(menu *C.GtkWidget, data C.gpointer) {
+	defer recoverUIPanic()
+	menuOpening(uintptr(unsafe.Pointer(menu)))
+}
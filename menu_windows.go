@@ -0,0 +1,226 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+/*
+A menu bar is a menu from CreateMenu() given to the Window with SetMenu(); submenus are menus from CreatePopupMenu() appended to their parent menus with MF_POPUP.
+Picking a MenuItem sends WM_COMMAND to the Window with the id of the MenuItem and no control (lParam is 0); see stdWndProc().
+Windows doesn't check and uncheck check items by itself, so menuItemClicked() does it; radio items are check items drawn with a bullet (MFT_RADIOCHECK).
+Before a submenu opens, the Window gets WM_INITMENUPOPUP with its HMENU, which is the key of a Menu (see menuOpening()).
+Changes to the items of a menu bar don't show until DrawMenuBar().
+DestroyMenu() destroys submenus along with their parent menu, so only menus without a parent are destroyed ourselves; likewise, DestroyWindow() destroys the menu bar of a window, which is why Window.Destroy() takes the menu bar away first.
+*/
+
+type sysMenu struct {
+	hmenu _HMENU
+}
+
+type sysMenuItem struct{}
+
+var (
+	_appendMenu      = user32.NewProc("AppendMenuW")
+	_checkMenuItem   = user32.NewProc("CheckMenuItem")
+	_createMenu      = user32.NewProc("CreateMenu")
+	_createPopupMenu = user32.NewProc("CreatePopupMenu")
+	_deleteMenu      = user32.NewProc("DeleteMenu")
+	_destroyMenu     = user32.NewProc("DestroyMenu")
+	_drawMenuBar     = user32.NewProc("DrawMenuBar")
+	_enableMenuItem  = user32.NewProc("EnableMenuItem")
+	_setMenu         = user32.NewProc("SetMenu")
+	_setMenuItemInfo = user32.NewProc("SetMenuItemInfoW")
+)
+
+type _MENUITEMINFO struct {
+	cbSize        uint32
+	fMask         uint32
+	fType         uint32
+	fState        uint32
+	wID           uint32
+	hSubMenu      _HMENU
+	hbmpChecked   _HANDLE
+	hbmpUnchecked _HANDLE
+	dwItemData    uintptr
+	dwTypeData    uintptr
+	cch           uint32
+	hbmpItem      _HANDLE
+}
+
+func (m *Menu) key() uintptr {
+	return uintptr(m.hmenu)
+}
+
+func (m *Menu) sysCreate(bar bool) {
+	create := _createPopupMenu
+	if bar {
+		create = _createMenu
+	}
+	r1, _, err := create.Call()
+	if r1 == 0 { // failure
+		panic(newError(nil, "creating menu", "CreateMenu()/CreatePopupMenu()", err))
+	}
+	m.hmenu = _HMENU(r1)
+}
+
+// & marks the mnemonic in menu item text, so a literal & has to be doubled; the shortcut, if any, goes after a tab, which Windows lines up in a column of its own
+func (i *MenuItem) sysText() uintptr {
+	text := strings.Replace(i.text, "&", "&&", -1)
+	if i.shortcut.key != 0 {
+		text += "\tCtrl+"
+		if i.shortcut.shift {
+			text += "Shift+"
+		}
+		text += string(i.shortcut.key)
+	}
+	return utf16ToArg(toUTF16(text))
+}
+
+func (m *Menu) sysAppend(i *MenuItem) {
+	flags := uintptr(_MF_STRING)
+	id := i.id
+	text := uintptr(0)
+	switch i.kind {
+	case menuItemCheck, menuItemRadio:
+		if i.checked {
+			flags |= _MF_CHECKED
+		}
+	case menuItemSeparator:
+		flags = _MF_SEPARATOR
+	case menuItemSubmenu:
+		flags |= _MF_POPUP
+		id = uintptr(i.submenu.hmenu)
+	}
+	if i.kind != menuItemSeparator {
+		text = i.sysText()
+	}
+	if i.disabled {
+		flags |= _MF_GRAYED
+	}
+	r1, _, err := _appendMenu.Call(
+		uintptr(m.hmenu),
+		flags,
+		id,
+		text)
+	if r1 == 0 { // failure
+		panic(newError(nil, "adding menu item", "AppendMenu()", err))
+	}
+	if i.kind == menuItemRadio {
+		// AppendMenu() can't make radio items
+		var mii _MENUITEMINFO
+
+		mii.cbSize = uint32(unsafe.Sizeof(mii))
+		mii.fMask = _MIIM_FTYPE
+		mii.fType = _MFT_STRING | _MFT_RADIOCHECK
+		i.setInfo(&mii)
+	}
+	m.redraw()
+}
+
+// DeleteMenu() also destroys the submenu of the item
+func (m *Menu) sysRemove(n int) {
+	r1, _, err := _deleteMenu.Call(
+		uintptr(m.hmenu),
+		uintptr(n),
+		uintptr(_MF_BYPOSITION))
+	if r1 == 0 { // failure
+		panic(newError(nil, "removing menu item", "DeleteMenu()", err))
+	}
+	m.redraw()
+}
+
+// if m is a menu bar, show what changed
+func (m *Menu) redraw() {
+	if m.window == nil {
+		return
+	}
+	r1, _, err := _drawMenuBar.Call(uintptr(m.window.hwnd))
+	if r1 == 0 { // failure
+		panic(m.window.newError("redrawing menu bar", "DrawMenuBar()", err))
+	}
+}
+
+// submenus have no id of their own, so we go by position
+func (i *MenuItem) position() uintptr {
+	for n, j := range i.parent.items {
+		if j == i {
+			return uintptr(n)
+		}
+	}
+	panic(fmt.Errorf("MenuItem %q not found in its own Menu", i.text))
+}
+
+func (i *MenuItem) setInfo(mii *_MENUITEMINFO) {
+	r1, _, err := _setMenuItemInfo.Call(
+		uintptr(i.parent.hmenu),
+		i.position(),
+		uintptr(_TRUE), // by position
+		uintptr(unsafe.Pointer(mii)))
+	if r1 == 0 { // failure
+		panic(newError(nil, "changing menu item", "SetMenuItemInfo()", err))
+	}
+}
+
+func (i *MenuItem) sysSetText() {
+	var mii _MENUITEMINFO
+
+	mii.cbSize = uint32(unsafe.Sizeof(mii))
+	mii.fMask = _MIIM_STRING
+	mii.dwTypeData = i.sysText()
+	i.setInfo(&mii)
+	i.parent.redraw()
+}
+
+func (i *MenuItem) sysSetEnabled() {
+	enable := uintptr(_MF_ENABLED)
+	if i.disabled {
+		enable = _MF_GRAYED
+	}
+	// the return value is the previous state, or -1 if the item doesn't exist, which can't happen here
+	_enableMenuItem.Call(
+		uintptr(i.parent.hmenu),
+		i.position(),
+		uintptr(_MF_BYPOSITION)|enable)
+	i.parent.redraw()
+}
+
+func (m *Menu) sysDestroy() {
+	if m.parentItem != nil { // destroyed with its parent
+		return
+	}
+	r1, _, err := _destroyMenu.Call(uintptr(m.hmenu))
+	if r1 == 0 { // failure
+		panic(newError(nil, "destroying menu", "DestroyMenu()", err))
+	}
+}
+
+func (i *MenuItem) sysSetChecked() {
+	check := uintptr(_MF_UNCHECKED)
+	if i.checked {
+		check = _MF_CHECKED
+	}
+	// the return value is the previous state, or -1 if the item doesn't exist, which can't happen here
+	_checkMenuItem.Call(
+		uintptr(i.parent.hmenu),
+		i.id,
+		uintptr(_MF_BYCOMMAND)|check)
+}
+
+// old is destroyed after this
+func (s *sysData) setMenuBar(old *Menu, m *Menu) {
+	hmenu := _HMENU(_NULL)
+	if m != nil {
+		hmenu = m.hmenu
+	}
+	// this resizes the client area, which lays the Window out again
+	r1, _, err := _setMenu.Call(
+		uintptr(s.hwnd),
+		uintptr(hmenu))
+	if r1 == 0 { // failure
+		panic(s.newError("setting menu bar", "SetMenu()", err))
+	}
+}
@@ -0,0 +1,8 @@
+// +build !uidebug
+
+// 3 july 2014
+
+package ui
+
+// see debug.go
+const uidebug = false
@@ -42,6 +42,7 @@ struct xalignment {
 extern id toNSString(char *);
 extern char *fromNSString(id);
 extern void display(id);
+extern void setNeedsDisplay(id);
 extern struct xrect frame(id);
 extern id makeScrollView(id);
 extern void giveScrollViewBezelBorder(id);
@@ -59,11 +60,14 @@ extern intptr_t clickCount(id);
 extern uintptr_t pressedMouseButtons(void);
 extern uintptr_t keyCode(id);
 
+/* areacaret_darwin.m */
+extern void areaSetCaret(id, struct xrect);
+
 /* delegateuitask_darwin.m */
 extern id makeAppDelegate(void);
 extern id windowGetContentView(id);
 extern BOOL initCocoa(id);
-extern void douitask(id, void *);
+extern void douitask(id);
 extern void breakMainLoop(void);
 extern void cocoaMainLoop(void);
 
@@ -71,11 +75,20 @@ extern void cocoaMainLoop(void);
 extern void msgBox(id, id, id, void *);
 extern void msgBoxError(id, id, id, void *);
 
+/* fonts_darwin.m */
+extern id fontFamilies(void);
+extern uintptr_t fontFamiliesCount(id);
+extern id fontFamilyAt(id, uintptr_t);
+extern BOOL fontFamilyIsMonospace(id);
+
 /* listbox_darwin.m */
 extern id toListboxItem(id, id);
 extern id fromListboxItem(id, id);
 extern id makeListboxArray(void);
 extern void listboxArrayAppend(id, id);
+extern id listboxItemsNew(void);
+extern void listboxItemsAdd(id, id);
+extern void listboxArrayAppendItems(id, id);
 extern void listboxArrayInsertBefore(id, id, uintptr_t);
 extern void listboxArrayDelete(id, uintptr_t);
 extern id listboxArrayItemAt(id, uintptr_t);
@@ -83,24 +96,100 @@ extern void bindListboxArray(id, id, id, id);
 extern id boundListboxArray(id, id);
 extern id makeListboxTableColumn(id);
 extern id listboxTableColumn(id, id);
-extern id makeListbox(id, BOOL);
+extern id makeListbox(id, BOOL, id);
 extern id listboxSelectedRowIndexes(id);
 extern uintptr_t listboxIndexesCount(id);
 extern uintptr_t listboxIndexesFirst(id);
 extern uintptr_t listboxIndexesNext(id, uintptr_t);
 extern intptr_t listboxLen(id);
+extern id listboxIndexesNew(void);
+extern void listboxIndexesAdd(id, uintptr_t);
+extern void listboxSelectRowIndexes(id, id);
+extern void listboxWatchScrolling(id, id);
+extern BOOL listboxNearEnd(id);
+
+/* logview_darwin.m */
+extern id makeLogView(void);
+extern void logviewSetText(id, id);
+extern id logviewText(id);
+extern void logviewAppend(id, id);
+
+/* tab_darwin.m */
+extern id makeTab(id);
+extern void tabAppend(id, id);
+extern void tabSelect(id, intptr_t);
+extern intptr_t tabIndexOf(id, id);
+extern struct xrect tabInsets(id);
+extern struct xsize tabPrefSize(id);
+
+/* treeview_darwin.m */
+extern id makeTreeView(id);
+extern id treeViewInsert(id, id, id, uintptr_t);
+extern void treeViewDelete(id, id);
+extern void treeViewExpand(id, id, BOOL);
+extern void treeViewSelect(id, id);
+extern uintptr_t treeViewSelected(id);
+
+/* textdirection_darwin.m */
+extern void setTextDirection(id, BOOL);
+
+/* slider_darwin.m */
+extern id makeSlider(BOOL, id);
+extern void sliderSetRange(id, intptr_t);
+extern void sliderSetPosition(id, intptr_t);
+extern intptr_t sliderPosition(id);
+extern struct xsize sliderPrefSize(id);
+
+/* radiobuttons_darwin.m */
+extern id makeRadioGroupView(id);
+extern id makeRadioButton(id, id);
+extern void radioSetSelected(id, BOOL);
+
+/* groupbox_darwin.m */
+extern id makeGroupBox(void);
+extern void groupBoxSetTitle(id, id);
+extern id groupBoxTitle(id);
+extern struct xrect groupBoxInsets(id);
+extern struct xsize groupBoxPrefSize(id);
+
+/* datetimepicker_darwin.m */
+extern id makeDateTimePicker(BOOL, id);
+extern void dateTimePickerSetTime(id, int64_t);
+extern int64_t dateTimePickerTime(id);
+
+/* colorbutton_darwin.m */
+extern id makeColorButton(BOOL, id);
+extern void colorButtonSetColor(id, double *);
+extern void colorButtonColor(id, double *);
+extern struct xsize colorButtonPrefSize(id);
+
+/* highcontrast_darwin.m */
+extern BOOL highContrast(void);
+extern void highContrastWatch(id);
+extern uint32_t highContrastColorRGB(intptr_t);
+
+/* animations_darwin.m */
+extern BOOL animationsEnabled(void);
+
+/* keyboardcheck_darwin.m */
+extern BOOL canBecomeKeyView(id);
+extern BOOL fullKeyboardAccess(void);
 
 /* prefsize_darwin.m */
 extern struct xsize controlPrefSize(id);
 extern struct xsize listboxPrefSize(id);
 extern struct xsize pbarPrefSize(id);
 extern struct xsize areaPrefSize(id);
+extern struct xsize logviewPrefSize(id);
+extern intptr_t textFieldWidthForText(id, id);
 extern struct xalignment alignmentInfo(id, struct xrect);
 
 /* sysdata_darwin.m */
 extern void addControl(id, id);
+extern void raiseControl(id, id, id);
 extern void controlShow(id);
 extern void controlHide(id);
+extern void controlSetEnabled(id, BOOL);
 extern void applyStandardControlFont(id);
 extern id makeWindow(id);
 extern void windowShow(id);
@@ -112,10 +201,12 @@ extern void buttonSetTargetAction(id, id);
 extern void buttonSetText(id, id);
 extern id buttonText(id);
 extern id makeCheckbox(void);
-extern id makeLineEdit(BOOL);
 extern void lineeditSetText(id, id);
 extern id lineeditText(id);
 extern id makeLabel(void);
+extern void labelSetBuddy(id, id, id);
+extern void labelSetEllipsize(id, intptr_t);
+extern void labelSetSelectable(id, BOOL);
 extern id makeProgressBar(void);
 extern void setRect(id, intptr_t, intptr_t, intptr_t, intptr_t);
 extern BOOL isCheckboxChecked(id);
@@ -124,14 +215,119 @@ extern void setProgress(id, intptr_t);
 extern void setAreaSize(id, intptr_t, intptr_t);
 extern void center(id);
 extern void setCheckboxChecked(id, BOOL);
+extern void windowFreeze(id);
+extern void windowThaw(id);
+extern void windowDestroy(id);
+extern void controlDestroy(id);
+
+/* badge_darwin.m */
+extern id setButtonBadge(id, id, id);
+
+/* banner_darwin.m */
+extern id makeBanner(id, intptr_t);
+extern void bannerAddButton(id, id, intptr_t, id);
+extern void showBanner(id, id);
+extern void removeBanner(id);
+
+/* busy_darwin.m */
+extern id makeBusyOverlay(id, id, BOOL);
+extern void removeBusyOverlay(id);
 
 /* combobox_darwin.m */
 extern id makeCombobox(BOOL);
 extern id comboboxText(id, BOOL);
 extern void comboboxAppend(id, BOOL, id);
+extern void comboboxAppendSpecial(id, id, BOOL);
 extern void comboboxInsertBefore(id, BOOL, id, intptr_t);
 extern intptr_t comboboxSelectedIndex(id);
+extern void comboboxSelect(id, BOOL, intptr_t);
 extern void comboboxDelete(id, intptr_t);
 extern intptr_t comboboxLen(id);
+extern void comboboxWatchChanges(id, id, BOOL);
+
+/* clipboard_darwin.m */
+extern void clipboardWatch(void);
+extern void setClipboardText(id);
+extern BOOL clipboardHasText(void);
+extern id clipboardText(void);
+extern void clipboardSet(id, id, void *, intptr_t, id);
+extern id clipboardFilesNew(void);
+extern void clipboardFilesAdd(id, id);
+extern intptr_t clipboardFormats(void);
+extern id clipboardHTML(void);
+extern id clipboardPNG(void);
+extern void *clipboardDataBytes(id);
+extern intptr_t clipboardDataLen(id);
+extern id clipboardFiles(void);
+extern intptr_t clipboardFilesLen(id);
+extern id clipboardFileAt(id, intptr_t);
+
+/* colordialog_darwin.m */
+extern BOOL runColorPanel(double *, BOOL, double *, intptr_t, void *);
+
+/* menu_darwin.m */
+extern id makeMenu(BOOL, id);
+extern void initMainMenu(void);
+extern id menuAppend(id, intptr_t, id, intptr_t, BOOL, BOOL, id, id);
+extern void menuItemSetChecked(id, BOOL);
+extern void menuRemove(id, id);
+extern void menuItemSetText(id, id);
+extern void menuItemSetEnabled(id, BOOL);
+extern void menuItemSetShortcut(id, id);
+extern void menuRelease(id);
+extern void windowSetMenuBar(id, id, id);
+extern void windowShowMenuBar(id, id);
+extern id appMenuAppend(id, intptr_t, id, intptr_t, BOOL, id);
+extern void appMenuRemove(id, id);
+
+/* toolbar_darwin.m */
+extern id makeToolbar(void);
+extern id toolbarAppend(id, intptr_t, id, void *, intptr_t, id, BOOL, BOOL, intptr_t, id);
+extern void windowSetToolbar(id, id);
+extern void toolbarButtonSetChecked(id, BOOL);
+extern void toolbarButtonSetEnabled(id, BOOL);
+
+/* statusbar_darwin.m */
+extern intptr_t statusBarGetHeight(void);
+extern id makeStatusBar(id);
+extern void statusBarAddPane(id);
+extern void statusBarAddProgress(id, id);
+extern void statusBarSetText(id, intptr_t, id);
+extern void statusBarLayout(id, BOOL);
+
+/* contextmenu_darwin.m */
+extern void controlSetContextMenu(id, id);
+
+/* windowmenu_darwin.m */
+extern void windowMinimize(id);
+
+/* editmenu_darwin.m */
+extern BOOL editFocus(id, BOOL *, BOOL *, BOOL *, BOOL *, BOOL *);
+extern void editCommand(id, intptr_t);
+
+/* fontdialog_darwin.m */
+extern BOOL runFontPanel(id *, double *, intptr_t *);
+extern id makeFontButton(id);
+
+/* gridnav_darwin.m */
+extern void gridNavFocus(id);
+
+/* lineedit_darwin.m */
+extern id makeLineEdit(BOOL);
+extern void lineeditWatchKeys(id, id);
+extern void lineeditSetValidationHint(id, id, BOOL);
+
+/* history_darwin.m */
+extern id historyEditorText(id);
+extern void historySetEditorText(id, id);
+
+/* keyboard_darwin.m */
+extern BOOL capsLockOn(void);
+extern id keyboardLayoutName(void);
+
+/* locale_darwin.m */
+extern void localeNumberFormat(id *, id *, intptr_t *, intptr_t *);
+extern id localeFormatDate(intptr_t, intptr_t, intptr_t);
+extern BOOL localeParseDate(id, intptr_t *, intptr_t *, intptr_t *);
 
 #endif
@@ -36,6 +36,11 @@ void display(id view)
 	[toNSView(view) display];
 }
 
+void setNeedsDisplay(id view)
+{
+	[toNSView(view) setNeedsDisplay:YES];
+}
+
 struct xrect frame(id view)
 {
 	NSRect r;
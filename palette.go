@@ -0,0 +1,149 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"unicode"
+)
+
+// ShowCommandPalette shows a command palette, as power-user programs do for Ctrl+Shift+P: a LineEdit to search with above a Listbox of commands, which only shows the commands that match what has been typed so far.
+// Matching is fuzzy: a command matches if it contains the typed characters in order, ignoring case, though not necessarily next to each other, so "ops" matches "Open Settings"; the command that matches best (characters at the starts of words and runs of characters next to each other count the most) is selected.
+// The commands stay in the order given, so the same command doesn't jump around as the user types.
+// The palette can be used from the keyboard alone: the LineEdit keeps the focus, Up and Down move the selection, Enter chooses the selected command, and Escape closes the palette without choosing anything.
+// Double-clicking a command also chooses it.
+//
+// ShowCommandPalette returns the index into commands of the command chosen and true, or false if the user pressed Escape or closed the palette.
+// If parent is not nil, it is made busy (see Window.SetBusy) while the palette is up; if parent was busy already, it's left that way.
+// ShowCommandPalette waits for the user to answer; like everything else in package ui, it must not be called on the UI thread.
+func ShowCommandPalette(parent *Window, commands []string) (index int, ok bool) {
+	keys := make(chan paletteKey, 32)
+	edit := NewLineEdit("")
+	edit.palette = keys
+	list := NewListbox()
+	f := NewFilteredList(list, commands, nil)
+	if len(commands) != 0 {
+		list.Select(0)
+	}
+	s := NewVerticalStack(edit, list)
+	s.SetStretchy(1)
+
+	w := NewWindow("Commands", 400, 300)
+	w.SetSpaced(true)
+	w.Create(s)
+	w.Center()
+	w.Show()
+	defer w.Destroy()
+
+	if parent != nil {
+		parent.lock.Lock()
+		wasBusy := parent.busy
+		parent.lock.Unlock()
+		if !wasBusy {
+			parent.SetBusy(true)
+			defer parent.SetBusy(false)
+		}
+	}
+
+	// the shown row that is selected, or -1 if nothing is shown
+	selected := func() int {
+		sel := list.SelectedIndices()
+		if len(sel) == 0 {
+			return -1
+		}
+		return sel[0]
+	}
+	for {
+		select {
+		case k := <-keys:
+			switch k {
+			case paletteChanged:
+				pattern := edit.Text()
+				best, bestScore := -1, 0
+				row := 0
+				f.SetFilter(func(item string) bool {
+					score, ok := fuzzyMatch(pattern, item)
+					if ok {
+						// keep is called on the items in order, so row follows the rows of the Listbox
+						if best == -1 || score > bestScore {
+							best, bestScore = row, score
+						}
+						row++
+					}
+					return ok
+				})
+				if best != -1 {
+					list.Select(best)
+				}
+			case paletteUp, paletteDown:
+				row := selected()
+				if k == paletteUp && row > 0 {
+					list.Select(row - 1)
+				} else if k == paletteDown && row+1 < f.ShownLen() {
+					list.Select(row + 1)
+				}
+			case paletteEnter:
+				if row := selected(); row != -1 {
+					return f.Index(row), true
+				}
+			case paletteEscape:
+				return 0, false
+			}
+		case <-list.Activated:
+			if row := selected(); row != -1 {
+				return f.Index(row), true
+			}
+		case <-w.Closing:
+			return 0, false
+		}
+	}
+}
+
+// the keys the LineEdit of a command palette hands to ShowCommandPalette() instead of handling them itself, plus paletteChanged for when its text changes
+type paletteKey int
+
+const (
+	paletteUp paletteKey = iota
+	paletteDown
+	paletteEnter
+	paletteEscape
+	paletteChanged
+)
+
+// called by the platform code on the UI thread; as that can't wait on ShowCommandPalette(), a key is dropped if too many are waiting already
+func (s *sysData) paletteKey(k paletteKey) {
+	select {
+	case s.palette <- k:
+	default:
+	}
+}
+
+// fuzzyMatch says whether the runes of pattern appear in s in order, ignoring case, and if so, how well they match: higher is better
+// each rune matched counts, more so right after the previous one and much more so at the start of a word, so "ops" matches "Open Settings" better than "Drop Shadow"
+// this goes left to right and takes the first match for each rune, which isn't always the best-scoring one, but is good enough for ranking commands
+func fuzzyMatch(pattern string, s string) (score int, ok bool) {
+	p := []rune(pattern)
+	if len(p) == 0 {
+		return 0, true
+	}
+	prev := ' '
+	last := -2 // index in s of the last rune matched
+	i := 0
+	for j, r := range []rune(s) {
+		if unicode.ToLower(r) == unicode.ToLower(p[i]) {
+			score++
+			if last == j-1 {
+				score += 4
+			}
+			if (!unicode.IsLetter(prev) && !unicode.IsDigit(prev)) || (unicode.IsLower(prev) && unicode.IsUpper(r)) {
+				score += 8
+			}
+			last = j
+			i++
+			if i == len(p) {
+				return score, true
+			}
+		}
+		prev = r
+	}
+	return 0, false
+}
@@ -0,0 +1,41 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern gboolean our_palette_key_press_event_callback(GtkWidget *, GdkEvent *, gpointer);
+import "C"
+
+// for ShowCommandPalette(), "key-press-event" and "changed" (see lineedit_unix.go) are connected to the GtkEntry by sysData.make()
+// returning TRUE from "key-press-event" keeps Up and Down from moving the focus, Enter from "activate", and Escape from the window
+
+//export our_palette_key_press_event_callback
+func our_palette_key_press_event_callback(widget *C.GtkWidget, event *C.GdkEvent, what C.gpointer) C.gboolean {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(what))
+	e := (*C.GdkEventKey)(unsafe.Pointer(event))
+	if e.state&C.guint(C.gtk_accelerator_get_default_mod_mask()) != 0 {
+		return C.FALSE // continue the event chain
+	}
+	switch e.keyval {
+	case C.GDK_KEY_Up, C.GDK_KEY_KP_Up:
+		s.paletteKey(paletteUp)
+	case C.GDK_KEY_Down, C.GDK_KEY_KP_Down:
+		s.paletteKey(paletteDown)
+	case C.GDK_KEY_Return, C.GDK_KEY_KP_Enter, C.GDK_KEY_ISO_Enter:
+		s.paletteKey(paletteEnter)
+	case C.GDK_KEY_Escape:
+		s.paletteKey(paletteEscape)
+	default:
+		return C.FALSE
+	}
+	return C.TRUE // handled
+}
+
+var palette_key_press_event_callback = C.GCallback(C.our_palette_key_press_event_callback)
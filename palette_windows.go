@@ -0,0 +1,82 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+/*
+For ShowCommandPalette(), the edit control is subclassed (like for LineEdit.SetHistory(); see history_windows.go) so Up, Down, Enter, and Escape go to the palette instead.
+IsDialogMessage() (see msgloop()) would otherwise take Enter for the default button and Escape for IDCANCEL, so we ask for those in WM_GETDLGCODE (as gridnav_windows.go does for Enter), and eat them as WM_CHARs so the edit control doesn't beep.
+Text changes come in as EN_CHANGE; see stdWndProc().
+The sysData is the reference data of the subclass.
+*/
+
+var paletteSubclassCallback uintptr
+
+func init() {
+	// not in the var declaration above, for the same reason as listboxSubclassCallback
+	paletteSubclassCallback = syscall.NewCallback(paletteSubclassProc)
+}
+
+// runs on the UI thread; called by sysData.make()
+func (s *sysData) paletteInit() {
+	r1, _, err := _setWindowSubclass.Call(
+		uintptr(s.hwnd),
+		paletteSubclassCallback,
+		uintptr(0),
+		uintptr(unsafe.Pointer(s)))
+	if r1 == 0 { // failure
+		panic(s.newError("subclassing edit control for command palette", "SetWindowSubclass()", err))
+	}
+}
+
+func paletteSubclassProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM, id uintptr, data uintptr) _LRESULT {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(data))
+	switch uMsg {
+	case _WM_GETDLGCODE:
+		r1, _, _ := _defSubclassProc.Call(
+			uintptr(hwnd),
+			uintptr(uMsg),
+			uintptr(wParam),
+			uintptr(lParam))
+		if lParam != 0 {
+			msg := (*_MSG)(unsafe.Pointer(lParam))
+			if msg.message == _WM_KEYDOWN && (msg.wParam == _VK_RETURN || msg.wParam == _VK_ESCAPE) {
+				r1 |= _DLGC_WANTALLKEYS
+			}
+		}
+		return _LRESULT(r1)
+	case _WM_KEYDOWN:
+		if getModifiers() != 0 {
+			break
+		}
+		switch wParam {
+		case _VK_UP:
+			s.paletteKey(paletteUp)
+		case _VK_DOWN:
+			s.paletteKey(paletteDown)
+		case _VK_RETURN:
+			s.paletteKey(paletteEnter)
+		case _VK_ESCAPE:
+			s.paletteKey(paletteEscape)
+		default:
+			return gridNavDefSubclassProc(hwnd, uMsg, wParam, lParam)
+		}
+		return 0
+	case _WM_CHAR:
+		if wParam == '\r' || wParam == 0x1B {
+			return 0
+		}
+	case _WM_NCDESTROY:
+		// see gridNavSubclassProc()
+		_removeWindowSubclass.Call(
+			uintptr(hwnd),
+			paletteSubclassCallback,
+			id)
+	}
+	return gridNavDefSubclassProc(hwnd, uMsg, wParam, lParam)
+}
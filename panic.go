@@ -0,0 +1,47 @@
+// 4 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+)
+
+var (
+	panicHandler     func(v interface{})
+	panicHandlerLock sync.Mutex
+)
+
+// SetPanicHandler sets the function that is called if code running on the UI thread panics.
+// This includes your AreaHandler methods as well as package ui's own code; in particular, package ui panics with an *Error if the system reports an error it can't recover from.
+// The handler runs on the UI thread and receives the value passed to panic(); it can call runtime/debug.Stack() to get a stack trace of the code that panicked.
+// Like AreaHandler methods, it cannot call any other package ui function or method, and it must not panic itself.
+// If the handler returns, the event loop keeps running, but whatever panicked is abandoned; if a goroutine was waiting for it to finish (for instance, a Control method call), that goroutine will wait forever.
+//
+// If no handler is set (or if SetPanicHandler is called with nil), package ui prints the panic value and a stack trace to standard error and exits the program with status 2, just as an unrecovered panic would.
+// Either way, the panic never unwinds through the system's code, which is not prepared for it.
+// For a handler that shows the user what happened before exiting, see ShowPanicReports.
+func SetPanicHandler(handler func(v interface{})) {
+	panicHandlerLock.Lock()
+	defer panicHandlerLock.Unlock()
+
+	panicHandler = handler
+}
+
+// this is deferred at every place the system calls into package ui (callbacks, window procedures, uitask functions)
+func recoverUIPanic() {
+	v := recover()
+	if v == nil {
+		return
+	}
+	panicHandlerLock.Lock()
+	handler := panicHandler
+	panicHandlerLock.Unlock()
+	if handler == nil {
+		fmt.Fprintf(os.Stderr, "panic on the UI thread: %v\n\n%s", v, debug.Stack())
+		os.Exit(2)
+	}
+	handler(v)
+}
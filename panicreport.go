@@ -0,0 +1,130 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+var (
+	panicReportSubmit func(report string) error
+	panicReporting    bool // once a report is up, later panics are only printed; the first report exits the program
+	panicReportLock   sync.Mutex
+)
+
+// ShowPanicReports sets a panic handler (see SetPanicHandler) that, instead of exiting right away, shows the user a window with what panicked and a stack trace, so they have something to send the program's developers.
+// The window has a button to copy the report to the clipboard and, if submit is not nil, a button that calls submit with the report (to send it to a bug tracker, for instance); submit is called on a goroutine of its own and can take as long as it needs.
+// The program exits with status 2 once the user closes the window, just as it would have without it.
+// The report is printed to standard error first either way, in case the panic has left the UI unable to show anything.
+//
+// Panics on goroutines of your own aren't seen by SetPanicHandler; defer ReportPanic at the top of those goroutines (including the one given to Go) to get the same window for them.
+func ShowPanicReports(submit func(report string) error) {
+	panicReportLock.Lock()
+	panicReportSubmit = submit
+	panicReportLock.Unlock()
+	SetPanicHandler(func(v interface{}) {
+		report := panicReport(v)
+		if startPanicReport(report) {
+			// we're on the UI thread, so the window has to be run from somewhere else; once this returns, the event loop keeps going and can show it
+			go runPanicReport(report)
+		}
+	})
+}
+
+// ReportPanic, when deferred, recovers a panic on the current goroutine and shows the window described in ShowPanicReports for it, then exits the program with status 2.
+// It does nothing if the goroutine isn't panicking.
+// Use it at the top of goroutines of your own; it must not be used on the UI thread, where ShowPanicReports does the same job.
+// It uses the submit function given to ShowPanicReports, if any.
+func ReportPanic() {
+	v := recover()
+	if v == nil {
+		return
+	}
+	report := panicReport(v)
+	if !startPanicReport(report) {
+		select {} // another report is up, and will exit the program
+	}
+	runPanicReport(report)
+}
+
+// runtime/debug.Stack() includes the frames from the panic on down, since deferred functions run on top of the goroutine that panicked
+func panicReport(v interface{}) string {
+	return fmt.Sprintf("%s (%s, %s/%s)\n\npanic: %v\n\n%s",
+		filepath.Base(os.Args[0]), runtime.Version(), runtime.GOOS, runtime.GOARCH,
+		v, debug.Stack())
+}
+
+// returns whether the caller should show the report
+func startPanicReport(report string) bool {
+	fmt.Fprintf(os.Stderr, "%s", report)
+	panicReportLock.Lock()
+	defer panicReportLock.Unlock()
+
+	if panicReporting {
+		return false
+	}
+	panicReporting = true
+	return true
+}
+
+// runs on a goroutine other than the UI thread; never returns
+func runPanicReport(report string) {
+	panicReportLock.Lock()
+	submit := panicReportSubmit
+	panicReportLock.Unlock()
+
+	name := filepath.Base(os.Args[0])
+	message := NewStandaloneLabel(fmt.Sprintf("%s has stopped because of an internal error. The report below can help its developers fix it.", name))
+	details := NewLogView(report)
+	copyButton := NewButton("Copy to Clipboard")
+	send := NewButton("Send Report")
+	quit := NewButton("Quit")
+	buttons := []Control{Space(), copyButton}
+	if submit != nil {
+		buttons = append(buttons, send)
+	}
+	buttons = append(buttons, quit)
+	b := NewHorizontalStack(buttons...)
+	b.SetStretchy(0)
+	s := NewVerticalStack(message, details, b)
+	s.SetStretchy(1)
+	w := NewWindow(name+" has crashed", 600, 400)
+	w.SetSpaced(true)
+	w.Create(s)
+	w.Center()
+	w.Show()
+
+	sent := make(chan error, 1)
+	sending := false
+	for {
+		select {
+		case <-copyButton.Clicked:
+			w.sysData.copyText(report)
+		case <-send.Clicked:
+			if sending { // already sent or being sent; once is enough
+				break
+			}
+			sending = true
+			message.SetText("Sending report...")
+			go func() {
+				sent <- submit(report)
+			}()
+		case err := <-sent:
+			if err != nil {
+				message.SetText(fmt.Sprintf("The report could not be sent: %v", err))
+				sending = false // let them try again
+				break
+			}
+			message.SetText("The report has been sent. Thank you.")
+		case <-quit.Clicked:
+			os.Exit(2)
+		case <-w.Closing:
+			os.Exit(2)
+		}
+	}
+}
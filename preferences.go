@@ -0,0 +1,91 @@
+// 10 july 2014
+
+package ui
+
+// A PreferencesPage is one page of the dialog shown by ShowPreferences: the name on its tab and the Control shown on it, usually a Stack or Grid.
+type PreferencesPage struct {
+	Name    string
+	Control Control
+}
+
+const (
+	preferencesWidth  = 480
+	preferencesHeight = 360
+)
+
+// ShowPreferences shows a preferences dialog with the given title and pages, one per tab, and waits for the user to close it.
+// What buttons the dialog has and when apply is called follow each system's conventions:
+// on Windows, as with property sheets, there are OK, Cancel, and Apply buttons, in that order, and apply is called by OK and Apply; Cancel and closing the dialog don't call it.
+// GTK+ and Mac OS X expect preferences to take effect without the user having to ask, so the dialog has only a Close button on GTK+ and no buttons at all on Mac OS X, and apply is called when the dialog is closed.
+// (Programs that want a change to take effect sooner than that on GTK+ and Mac OS X can watch the events of the Controls on the pages themselves.)
+// If apply returns an error, the error is shown in a message box and the dialog stays open.
+//
+// The Controls on the pages are made part of the dialog, so they cannot be used again after ShowPreferences returns; make new ones each time.
+// If parent is not nil, it is made busy while the dialog is up, as with AskCredentials.
+// Like AskCredentials, ShowPreferences must not be called on the UI thread.
+func ShowPreferences(parent *Window, title string, pages []PreferencesPage, apply func() error) {
+	var okButton, cancelButton, applyButton, closeButton *Button
+	var okClicked, cancelClicked, applyClicked, closeClicked chan struct{} // nil for the buttons the dialog doesn't have, so they're never selected below
+
+	t := NewTab()
+	for _, p := range pages {
+		t.AddPage(p.Name, p.Control)
+	}
+	controls := []Control{t}
+	switch {
+	case !preferencesInstantApply:
+		okButton = NewButton("OK")
+		cancelButton = NewButton("Cancel")
+		applyButton = NewButton("Apply")
+		okClicked = okButton.Clicked
+		cancelClicked = cancelButton.Clicked
+		applyClicked = applyButton.Clicked
+		controls = append(controls, dialogButtons(okButton, cancelButton, applyButton))
+	case preferencesCloseButton:
+		closeButton = NewButton("Close")
+		closeClicked = closeButton.Clicked
+		buttons := NewHorizontalStack(Space(), closeButton)
+		buttons.SetStretchy(0)
+		controls = append(controls, buttons)
+	}
+	s := NewVerticalStack(controls...)
+	s.SetStretchy(0)
+
+	w := NewWindow(title, preferencesWidth, preferencesHeight)
+	w.SetSpaced(true)
+	w.Create(s)
+	w.Center()
+	w.Show()
+	defer w.Destroy()
+
+	defer dialogParentBusy(parent)()
+
+	applied := func() bool {
+		err := apply()
+		if err != nil {
+			<-w.MsgBoxError("Your preferences could not be applied.", err.Error())
+			return false
+		}
+		return true
+	}
+	for {
+		select {
+		case <-okClicked:
+			if applied() {
+				return
+			}
+		case <-applyClicked:
+			applied()
+		case <-cancelClicked:
+			return
+		case <-closeClicked:
+			if applied() {
+				return
+			}
+		case <-w.Closing:
+			if !preferencesInstantApply || applied() {
+				return
+			}
+		}
+	}
+}
@@ -2,10 +2,13 @@
 
 #include "objc_darwin.h"
 #import <AppKit/NSControl.h>
+#import <AppKit/NSCell.h>
 #import <AppKit/NSScrollView.h>
 #import <AppKit/NSTableView.h>
 #import <AppKit/NSProgressIndicator.h>
 #import <AppKit/NSView.h>
+#import <AppKit/NSTextView.h>
+#import <AppKit/NSLayoutManager.h>
 // needed for the methods called by alignmentInfo()
 #import <AppKit/NSLayoutConstraint.h>
 
@@ -15,6 +18,7 @@
 #define toNSTableView(x) to(NSTableView, (x))
 #define toNSProgressIndicator(x) to(NSProgressIndicator, (x))
 #define toNSView(x) to(NSView, (x))
+#define toNSTextView(x) to(NSTextView, (x))
 
 #define inScrollView(x) ([toNSScrollView((x)) documentView])
 #define listboxInScrollView(x) toNSTableView(inScrollView((x)))
@@ -76,6 +80,36 @@ struct xsize areaPrefSize(id scrollview)
 	return s;
 }
 
+// an NSTextView's frame is the size of its text, which could be anything, so we can't size to fit
+// instead, as on Windows (where this is all guesswork too), we use the size of three lines and some width, plus the scroll view's border; LogViews are meant to be stretched anyway
+struct xsize logviewPrefSize(id scrollview)
+{
+	NSTextView *tv;
+	NSSize content;
+	struct xsize s;
+
+	tv = toNSTextView(inScrollView(scrollview));
+	content.width = 100;
+	content.height = 3 * [[tv layoutManager] defaultLineHeightForFont:[tv font]];
+	content = [NSScrollView frameSizeForContentSize:content
+		hasHorizontalScroller:NO
+		hasVerticalScroller:NO
+		borderType:[toNSScrollView(scrollview) borderType]];
+	s.width = (intptr_t) content.width;
+	s.height = (intptr_t) content.height;
+	return s;
+}
+
+// for sysData.setWidthHint(): how wide the NSTextField would need to be to show text, border included; we measure with a copy of its cell so the NSTextField itself doesn't change
+intptr_t textFieldWidthForText(id control, id text)
+{
+	NSCell *cell;
+
+	cell = [[[toNSControl(control) cell] copy] autorelease];
+	[cell setStringValue:text];
+	return (intptr_t) [cell cellSize].width;
+}
+
 struct xalignment alignmentInfo(id c, struct xrect newrect)
 {
 	NSView *v;
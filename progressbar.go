@@ -3,67 +3,123 @@
 package ui
 
 import (
+	"fmt"
 	"sync"
 )
 
 // A ProgressBar is a horizontal rectangle that fills up from left to right to indicate the progress of a long-running task.
-// This progress is represented by an integer within the range [0,100], representing a percentage.
+// This progress is represented by an integer within a range, [0,100] (a percentage) by default; see SetRange.
 // Alternatively, a progressbar can show an animation indicating that progress is being made but how much is indeterminate.
-// Newly-created ProgressBars default to showing 0% progress.
+// Newly-created ProgressBars default to showing no progress (the minimum of the range).
+// A ProgressBar can also show a line of text, such as "42 of 97 files", on platforms that support it; see SetText.
+// Like the other Controls, a ProgressBar can be updated from any goroutine, so a long-running task can report its progress as it goes; to also keep the user out of a Window until the task finishes, see RunWithProgress.
 type ProgressBar struct {
 	lock     sync.Mutex
 	created  bool
 	sysData  *sysData
-	initProg int
+	min, max int
+	prog     int // in [min,max] or -1; we need this to recompute the percentage if the range changes
+	text     string
 }
 
 // NewProgressBar creates a new ProgressBar.
 func NewProgressBar() *ProgressBar {
 	return &ProgressBar{
 		sysData: mksysdata(c_progressbar),
+		max:     100,
 	}
 }
 
 // SetProgress sets the currently indicated progress amount on the ProgressBar.
-// If percent is in the range [0,100], the progressBar shows that much percent complete.
-// If percent is -1, the ProgressBar is made indeterminate.
+// If value is in the range [min,max] (see SetRange), the ProgressBar shows that much of the range complete.
+// If value is -1, the ProgressBar is made indeterminate.
 // Otherwise, SetProgress panics.
 // Calling SetProgress(-1) repeatedly will neither leave indeterminate mode nor stop any animation involved in indeterminate mode indefinitely; any other side-effect of doing so is implementation-defined.
-func (p *ProgressBar) SetProgress(percent int) {
+func (p *ProgressBar) SetProgress(value int) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
-	if percent < -1 || percent > 100 {
-		panic("percent value out of range")
+	if value != -1 && (value < p.min || value > p.max) {
+		panic(fmt.Errorf("progress value %d out of range [%d,%d]", value, p.min, p.max))
 	}
+	p.prog = value
 	if p.created {
-		p.sysData.setProgress(percent)
-		return
+		p.sysData.setProgress(p.percent())
 	}
-	p.initProg = percent
+}
+
+// SetRange changes the range of values given to SetProgress to [min,max].
+// min must not be negative (so -1 can still mean indeterminate) and must be less than max; otherwise, SetRange panics.
+// The current progress is kept, but moved into the new range if it falls outside it; an indeterminate ProgressBar stays indeterminate.
+func (p *ProgressBar) SetRange(min int, max int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if min < 0 || min >= max {
+		panic(fmt.Errorf("invalid range [%d,%d] given to ProgressBar.SetRange()", min, max))
+	}
+	p.min = min
+	p.max = max
+	if p.prog != -1 {
+		if p.prog < min {
+			p.prog = min
+		} else if p.prog > max {
+			p.prog = max
+		}
+	}
+	if p.created {
+		p.sysData.setProgress(p.percent())
+	}
+}
+
+// the system only deals in percentages
+// use int64 so large ranges (such as byte counts) don't overflow on 32-bit systems
+func (p *ProgressBar) percent() int {
+	if p.prog == -1 {
+		return -1
+	}
+	return int(int64(p.prog-p.min) * 100 / int64(p.max-p.min))
+}
+
+// SetText sets the text shown on the ProgressBar; pass an empty string to show no text.
+// Only GTK+ shows the text, in or next to the bar; on Windows and Mac OS X, the native progress bars have no place for text, so it is kept but not shown.
+func (p *ProgressBar) SetText(text string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.text = text
+	if p.created {
+		p.sysData.setText(text)
+	}
+}
+
+// Text returns the text set with SetText.
+func (p *ProgressBar) Text() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.text
 }
 
 func (p *ProgressBar) make(window *sysData) error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
+	p.sysData.control = p
 	err := p.sysData.make(window)
 	if err != nil {
 		return err
 	}
-	p.sysData.setProgress(p.initProg)
+	p.sysData.setProgress(p.percent())
+	if p.text != "" {
+		p.sysData.setText(p.text)
+	}
 	p.created = true
 	return nil
 }
 
 func (p *ProgressBar) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
-	return []*allocation{&allocation{
-		x:       x,
-		y:       y,
-		width:   width,
-		height:  height,
-		this:		p,
-	}}
+	return p.sysData.singleAllocation(x, y, width, height, p)
 }
 
 func (p *ProgressBar) preferredSize(d *sysSizeData) (width int, height int) {
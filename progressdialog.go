@@ -0,0 +1,140 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Progress is how the work given to RunWithProgress reports how far along it is and finds out if the user canceled it.
+// Its methods can be called from any goroutine, as often as the work likes: they only record what to show, and the dialog shows the latest of it a few times a second, so reporting progress never waits on the UI.
+type Progress struct {
+	// Canceled is closed when the user clicks Cancel or closes the dialog.
+	// The work isn't stopped for it; check Canceled between steps (or select on it) and return early once it's closed.
+	Canceled <-chan struct{}
+
+	p *progress
+}
+
+type progress struct {
+	lock     sync.Mutex
+	percent  int
+	message  string
+	changed  bool
+	cancel   chan struct{}
+	canceled bool
+}
+
+// how often the dialog shows what the work last reported
+const progressUpdateInterval = 100 * time.Millisecond
+
+// Set sets how much of the work is done, as a percentage, or -1 if that isn't known; see ProgressBar.SetProgress.
+// It panics if percent is not -1 and not in [0,100].
+func (p Progress) Set(percent int) {
+	if percent < -1 || percent > 100 {
+		panic(fmt.Errorf("invalid percent %d given to Progress.Set()", percent))
+	}
+	p.p.lock.Lock()
+	defer p.p.lock.Unlock()
+
+	p.p.percent = percent
+	p.p.changed = true
+}
+
+// SetMessage sets the line of text shown above the ProgressBar, such as the name of the file being worked on.
+func (p Progress) SetMessage(message string) {
+	p.p.lock.Lock()
+	defer p.p.lock.Unlock()
+
+	p.p.message = message
+	p.p.changed = true
+}
+
+// RunWithProgress runs work on a new goroutine while showing a dialog with the given title: a line of text, a ProgressBar, and a Cancel button, all kept up to date from what work reports through its Progress.
+// If parent is not nil, it is made busy (see Window.SetBusy) while the dialog is up, so the user can't use it until the work is done; if parent was busy already, it's left that way.
+// RunWithProgress waits for work to return, destroys the dialog, and returns what work returned.
+// Canceling doesn't stop work by itself; see Progress.Canceled.
+func RunWithProgress(parent *Window, title string, work func(p Progress) error) error {
+	p := &progress{
+		cancel: make(chan struct{}),
+	}
+	label := NewStandaloneLabel("")
+	label.SetEllipsize(EllipsizeEnd) // so long messages don't change the size of the dialog
+	bar := NewProgressBar()
+	cancel := NewButton("Cancel")
+	buttons := NewHorizontalStack(Space(), cancel)
+	buttons.SetStretchy(0)
+	w := NewWindow(title, 360, 120)
+	w.SetSpaced(true)
+	w.Create(NewVerticalStack(label, bar, buttons))
+	w.Center()
+	w.Show()
+
+	wasBusy := false
+	if parent != nil {
+		parent.lock.Lock()
+		wasBusy = parent.busy
+		parent.lock.Unlock()
+		if !wasBusy {
+			parent.SetBusy(true)
+		}
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- work(Progress{
+			Canceled: p.cancel,
+			p:        p,
+		})
+	}()
+	ticker := time.NewTicker(progressUpdateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			w.Destroy()
+			if parent != nil && !wasBusy {
+				parent.SetBusy(false)
+			}
+			return err
+		case <-ticker.C:
+			p.show(label, bar)
+		case <-cancel.Clicked:
+			p.doCancel(label)
+		case <-w.Closing:
+			p.doCancel(label)
+		}
+	}
+}
+
+// the controls are changed after letting go of the lock, so the work doesn't wait on the UI in Set and SetMessage
+func (p *progress) show(label *Label, bar *ProgressBar) {
+	p.lock.Lock()
+	changed, canceled := p.changed, p.canceled
+	percent, message := p.percent, p.message
+	p.changed = false
+	p.lock.Unlock()
+
+	if !changed {
+		return
+	}
+	if !canceled { // keep "Canceling..."
+		label.SetText(message)
+	}
+	bar.SetProgress(percent)
+}
+
+func (p *progress) doCancel(label *Label) {
+	p.lock.Lock()
+	already := p.canceled
+	p.canceled = true
+	p.lock.Unlock()
+
+	if already { // clicked more than once
+		return
+	}
+	close(p.cancel)
+	label.SetText("Canceling...")
+}
@@ -0,0 +1,187 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RadioButtons is a column of round buttons with labels, of which exactly one is selected at any time; selecting one deselects the one that was selected before, which the system does on its own.
+// Newly-created RadioButtons have their first button selected.
+// The Window's spacing (see Window.SetSpaced) is between the buttons, as in a vertical Stack.
+// RadioButtons can't be the buddy of a Label (see Label.SetBuddy), as there is no single native control to give it; put a Label above the RadioButtons instead.
+type RadioButtons struct {
+	// SelectionChanged is signaled when the user selects another button.
+	// It is not signaled for SetSelected, nor when the user clicks the button that is already selected.
+	SelectionChanged chan struct{}
+
+	lock         sync.Mutex
+	created      bool
+	buttons      []*radioButton
+	stack        *Stack // lays out the buttons
+	group        *radioGroup
+	initSelected int
+}
+
+// the system controls of a RadioButtons share this; it is only touched on the UI thread once they are made
+type radioGroup struct {
+	buttons  []*sysData
+	selected int // the button last selected, either by SetSelected() or by the user; see sysData.radioClicked()
+}
+
+// each button is its own system control, so it needs its own Control for the Stack to lay it out
+type radioButton struct {
+	sysData *sysData
+	text    string
+}
+
+// NewRadioButtons creates a new RadioButtons with a button for each of the given labels, in order from top to bottom.
+// It panics if no labels are given.
+func NewRadioButtons(labels ...string) *RadioButtons {
+	if len(labels) == 0 {
+		panic("no labels given to NewRadioButtons()")
+	}
+	r := &RadioButtons{
+		SelectionChanged: newEvent(),
+		group:            new(radioGroup),
+	}
+	controls := make([]Control, len(labels))
+	for i, label := range labels {
+		b := &radioButton{
+			sysData: mksysdata(c_radiobutton),
+			text:    label,
+		}
+		// the first button starts the group on all platforms; see radiobuttons_*.go
+		b.sysData.alternate = i == 0
+		b.sysData.radios = r.group
+		r.group.buttons = append(r.group.buttons, b.sysData)
+		r.buttons = append(r.buttons, b)
+		controls[i] = b
+	}
+	r.stack = NewVerticalStack(controls...)
+	return r
+}
+
+// Len returns the number of buttons.
+func (r *RadioButtons) Len() int {
+	return len(r.buttons) // never changes, so no need for the lock
+}
+
+// Selected returns the index of the selected button.
+func (r *RadioButtons) Selected() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.created {
+		return r.initSelected
+	}
+	ret := make(chan int)
+	defer close(ret)
+	uitask(func() {
+		ret <- r.group.selected
+	})
+	return <-ret
+}
+
+// SetSelected selects the button at the given index.
+// If the Window containing the RadioButtons has not been created yet, SetSelected sets the button that will be selected when it is.
+// It panics if the given index is out of range.
+func (r *RadioButtons) SetSelected(index int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if index < 0 || index >= len(r.buttons) {
+		panic(fmt.Errorf("index %d out of range in RadioButtons.SetSelected()", index))
+	}
+	if !r.created {
+		r.initSelected = index
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		r.group.selected = index // so the system telling us it was selected doesn't signal SelectionChanged; see sysData.radioClicked()
+		r.group.buttons[index].radioSelect()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (r *RadioButtons) make(window *sysData) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, b := range r.buttons {
+		b.sysData.control = r
+		b.sysData.event = r.SelectionChanged
+	}
+	// the buttons have to be made in order, with nothing in between, for the systems to group them; Stack.make() does that
+	err := r.stack.make(window)
+	if err != nil {
+		return err
+	}
+	for _, b := range r.buttons {
+		b.sysData.setText(b.text)
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		r.group.selected = r.initSelected
+		r.group.buttons[r.initSelected].radioSelect()
+		ret <- struct{}{}
+	})
+	<-ret
+	r.created = true
+	return nil
+}
+
+// called by the platform code on the UI thread when the user selects a button
+// the systems also tell us when the user clicks the button that is already selected (and GTK+ when we select one ourselves), so only actual changes are signaled
+func (s *sysData) radioClicked() {
+	for i, b := range s.radios.buttons {
+		if b == s {
+			if i != s.radios.selected {
+				s.radios.selected = i
+				s.signal()
+			}
+			return
+		}
+	}
+}
+
+func (r *RadioButtons) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	return r.stack.allocate(x, y, width, height, d)
+}
+
+func (r *RadioButtons) preferredSize(d *sysSizeData) (width int, height int) {
+	return r.stack.preferredSize(d)
+}
+
+func (r *RadioButtons) commitResize(a *allocation, d *sysSizeData) {
+	// this is to satisfy Control; the buttons are committed on their own
+}
+
+func (r *RadioButtons) getAuxResizeInfo(d *sysSizeData) {
+	// this is to satisfy Control; nothing to do here
+}
+
+func (b *radioButton) make(window *sysData) error {
+	return b.sysData.make(window)
+}
+
+func (b *radioButton) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	return b.sysData.singleAllocation(x, y, width, height, b)
+}
+
+func (b *radioButton) preferredSize(d *sysSizeData) (width int, height int) {
+	return b.sysData.preferredSize(d)
+}
+
+func (b *radioButton) commitResize(a *allocation, d *sysSizeData) {
+	b.sysData.commitResize(a, d)
+}
+
+func (b *radioButton) getAuxResizeInfo(d *sysSizeData) {
+	b.sysData.getAuxResizeInfo(d)
+}
@@ -0,0 +1,28 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+/*
+On Mac OS X, the buttons of RadioButtons are NSButtons of type NSRadioButton; the app delegate is their target, so it's told when one is clicked (see appDelegate_radioClicked()). setState: doesn't tell it.
+Since 10.8, radio buttons with the same superview and action act as a group on their own, so each RadioButtons puts its buttons in a view of their own, which the first button makes; it covers the content view exactly, so the buttons are placed as if they were in the content view like the other controls (see sysData.setRect()).
+Before 10.8 nothing groups them, so sysData.radioSelect() unchecks the others too, including when the user clicks one.
+*/
+
+func makeRadioButton(parentWindow C.id, alternate bool, s *sysData) C.id {
+	if alternate {
+		s.radioView = C.makeRadioGroupView(parentWindow)
+	}
+	button := C.makeRadioButton(s.radios.buttons[0].radioView, appDelegate)
+	applyStandardControlFont(button)
+	return button
+}
+
+// runs on the UI thread
+func (s *sysData) radioSelect() {
+	for _, b := range s.radios.buttons {
+		C.radioSetSelected(b.id, toBOOL(b == s))
+	}
+}
@@ -0,0 +1,66 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSWindow.h>
+#import <AppKit/NSView.h>
+#import <AppKit/NSButton.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSWindow(x) to(NSWindow, (x))
+#define toNSView(x) to(NSView, (x))
+#define toNSButton(x) to(NSButton, (x))
+
+extern NSRect dummyRect;
+
+// see radiobuttons_darwin.go; this covers the whole content view, but clicks that don't land on one of its buttons go through to whatever is underneath
+@interface radioGroupView : NSView
+@end
+
+@implementation radioGroupView
+
+- (NSView *)hitTest:(NSPoint)p
+{
+	NSView *v;
+
+	v = [super hitTest:p];
+	if (v == self)
+		return nil;
+	return v;
+}
+
+@end
+
+id makeRadioGroupView(id parentWindow)
+{
+	NSView *cv;
+	radioGroupView *v;
+
+	cv = [toNSWindow(parentWindow) contentView];
+	v = [[radioGroupView alloc]
+		initWithFrame:[cv bounds]];
+	[v setAutoresizingMask:(NSViewWidthSizable | NSViewHeightSizable)];
+	[cv addSubview:v];
+	return v;
+}
+
+id makeRadioButton(id groupView, id delegate)
+{
+	NSButton *button;
+
+	button = [[NSButton alloc]
+		initWithFrame:dummyRect];
+	[button setButtonType:NSRadioButton];
+	[button setTarget:delegate];
+	[button setAction:@selector(radioClicked:)];
+	[toNSView(groupView) addSubview:button];
+	return button;
+}
+
+void radioSetSelected(id button, BOOL selected)
+{
+	if (selected) {
+		[toNSButton(button) setState:NSOnState];
+		return;
+	}
+	[toNSButton(button) setState:NSOffState];
+}
@@ -0,0 +1,44 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern void our_radiobutton_toggled_callback(GtkToggleButton *, gpointer);
+import "C"
+
+/*
+On GTK+, the buttons of RadioButtons are GtkRadioButtons; each but the first joins the group of the first once it's made (see sysData.make()), and GTK+ keeps exactly one of them active from then on.
+"toggled" is sent to both the button that becomes active and the one that stops being active, including for gtk_toggle_button_set_active(), so only the former is passed on, and sysData.radioClicked() leaves out the ones we caused.
+*/
+
+func gtkRadioButtonNew() *C.GtkWidget {
+	return C.gtk_radio_button_new(nil)
+}
+
+// runs on the UI thread; the first button is made first (see RadioButtons.make())
+func (s *sysData) radioJoin() {
+	C.gtk_radio_button_join_group((*C.GtkRadioButton)(unsafe.Pointer(s.widget)),
+		(*C.GtkRadioButton)(unsafe.Pointer(s.radios.buttons[0].widget)))
+}
+
+// runs on the UI thread; GTK+ deactivates the one that was active
+func (s *sysData) radioSelect() {
+	gtk_toggle_button_set_active(s.widget, true)
+}
+
+//export our_radiobutton_toggled_callback
+func our_radiobutton_toggled_callback(button *C.GtkToggleButton, what C.gpointer) {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(what))
+	if gtk_toggle_button_get_active(s.widget) {
+		s.radioClicked()
+	}
+}
+
+var radiobutton_toggled_callback = C.GCallback(C.our_radiobutton_toggled_callback)
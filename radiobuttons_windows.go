@@ -0,0 +1,25 @@
+// 9 july 2014
+
+package ui
+
+/*
+On Windows, the buttons of RadioButtons are BS_AUTORADIOBUTTONs; the first has WS_GROUP, which starts a group that goes on until the next control with WS_GROUP, so as long as the buttons are made one after another (which RadioButtons.make() does), clicking one unchecks the others in its group and no others.
+The first button is also the group's only tab stop; the dialog manager (see msgloop()) moves the focus to the checked button when tabbing into the group, and the arrow keys move through the group and check the button they land on. (No other control has WS_GROUP, so to the arrow keys the group also takes in the controls made after it, as all the controls of a Window are one group to them otherwise.)
+Each click (including on the button already checked, and those the arrow keys make) is a BN_CLICKED; see sysData.radioClicked().
+BM_SETCHECK doesn't uncheck the others for us, nor does it send BN_CLICKED.
+*/
+
+// runs on the UI thread
+func (s *sysData) radioSelect() {
+	for _, b := range s.radios.buttons {
+		check := uintptr(_BST_UNCHECKED)
+		if b == s {
+			check = uintptr(_BST_CHECKED)
+		}
+		_sendMessage.Call(
+			uintptr(b.hwnd),
+			uintptr(_BM_SETCHECK),
+			check,
+			uintptr(0))
+	}
+}
@@ -0,0 +1,284 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A SettingsStore keeps the values of settings between runs of your program, by key; see BindSetting.
+// Load and Save are never called on the UI thread, so they can take as long as they need, but they can be called from more than one goroutine at once.
+type SettingsStore interface {
+	// Load returns the value saved for key, or ok == false if there is none.
+	Load(key string) (value string, ok bool, err error)
+
+	// Save saves value for key, replacing whatever was there.
+	Save(key string, value string) error
+}
+
+// NewSettingsFile returns a SettingsStore that keeps every setting in the named file, as a JSON object of strings.
+// The file doesn't have to exist yet; there are no saved settings if it doesn't.
+// Each Save rewrites the whole file, keeping the other settings in it, so use one SettingsStore for each file rather than several.
+func NewSettingsFile(filename string) SettingsStore {
+	return &settingsFile{
+		filename: filename,
+	}
+}
+
+type settingsFile struct {
+	lock     sync.Mutex
+	filename string
+}
+
+func (f *settingsFile) read() (map[string]string, error) {
+	m := make(map[string]string)
+	data, err := ioutil.ReadFile(f.filename)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, fmt.Errorf("error reading settings file %q: %v", f.filename, err)
+	}
+	return m, nil
+}
+
+func (f *settingsFile) Load(key string) (value string, ok bool, err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	m, err := f.read()
+	if err != nil {
+		return "", false, err
+	}
+	value, ok = m[key]
+	return value, ok, nil
+}
+
+func (f *settingsFile) Save(key string, value string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	// don't replace a file we can't read with one that only has this setting in it
+	m, err := f.read()
+	if err != nil {
+		return err
+	}
+	m[key] = value
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f.filename, append(data, '\n'), 0600)
+}
+
+// BindSetting ties the value of c to the setting key of store: the value saved for key, if any, is given to c now, so c shows it once its Window is created, and c's value is saved back to key as the user changes it.
+// This takes the place of copying settings into controls before showing a preferences window and back out after.
+// The values are strings: a LineEdit's is its text; a Checkbox's is "true" or "false", for whether it is checked; a Combobox's is the selected item's text (or, for an editable Combobox, what is typed in it); and a Listbox's is the texts of the selected items, one per line.
+// A saved value that isn't one of the items of a Combobox (including anything typed into an editable one) selects nothing, and saved items that aren't in a Listbox are left out.
+// The items of a Combobox or Listbox have to be added (by its constructor or by Append) before calling BindSetting, so the saved selection can be found among them.
+// A setting is saved a second after the user last changed it, so typing into a LineEdit saves once rather than on every key; changes made by your program may have to wait for the next save.
+// Settings are also saved right away when the user closes the Window, before its Closing is signaled; when the user asks to quit the program, before AppQuit is signaled; and when the Window or c is destroyed (see Window.Destroy and DestroyControl), including when the function given to Go returns.
+// So they are kept whether your program destroys its Windows, just hides them, quits right away, or crashes a while after the user's last change.
+// Errors from saving are passed to the handler given to SetErrorHandler.
+// If loading the setting fails, the error is returned and c is neither changed nor bound, so a store that can't be read is never overwritten with c's value.
+// BindSetting panics if c is not one of the controls above; it cannot be called after the Window that contains c has been created.
+func BindSetting(c Control, store SettingsStore, key string) error {
+	switch c.(type) {
+	case *LineEdit, *Checkbox, *Combobox, *Listbox:
+	default:
+		panic(fmt.Errorf("unsupported Control %T given to BindSetting()", c))
+	}
+	value, ok, err := store.Load(key)
+	if err != nil {
+		return err
+	}
+	s := &setting{
+		store:   store,
+		key:     key,
+		control: c,
+	}
+	switch c := c.(type) {
+	case *LineEdit:
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		if c.created {
+			panic(fmt.Errorf("BindSetting() called after window create"))
+		}
+		if ok {
+			c.initText = value
+		}
+		s.get = c.Text
+		c.sysData.setting = s
+	case *Checkbox:
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		if c.created {
+			panic(fmt.Errorf("BindSetting() called after window create"))
+		}
+		if checked, err := strconv.ParseBool(value); ok && err == nil {
+			c.initCheck = checked
+		}
+		s.get = func() string {
+			return strconv.FormatBool(c.Checked())
+		}
+		c.sysData.setting = s
+	case *Combobox:
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		if c.created {
+			panic(fmt.Errorf("BindSetting() called after window create"))
+		}
+		if ok {
+			c.initSelected = -1
+			for i, item := range c.initItems {
+				if item == value && c.kinds[i] == comboboxItem {
+					c.initSelected = i
+					break
+				}
+			}
+		}
+		s.get = c.Selection
+		c.sysData.setting = s
+	case *Listbox:
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		if c.created {
+			panic(fmt.Errorf("BindSetting() called after window create"))
+		}
+		if ok {
+			selected := make(map[string]bool)
+			if value != "" {
+				for _, item := range strings.Split(value, "\n") {
+					selected[item] = true
+				}
+			}
+			c.initSelected = nil
+			for i, item := range c.initItems {
+				if selected[item] {
+					c.initSelected = append(c.initSelected, i)
+					if !c.sysData.alternate { // single-selection
+						break
+					}
+				}
+			}
+		}
+		s.get = func() string {
+			return strings.Join(c.Selection(), "\n")
+		}
+		c.sysData.setting = s
+	}
+	return nil
+}
+
+// a setting is given by each bound control's make() to its Window, which saves it when closed (see cSysData.signal()) and in Window.Destroy(); it is also saved when the program is asked to quit (see saveAllSettings()) and shortly after the user changes it (see changed())
+type setting struct {
+	store   SettingsStore
+	key     string
+	control Control
+	get     func() string // the control's value; only called once the control is made
+	lock    sync.Mutex    // held while saving, so nothing saves after Window.Destroy() has; never taken on the UI thread, as get() goes through it
+	done    bool          // set by the last save, in Window.Destroy() or DestroyControl(); the control is gone after that
+	timer   *time.Timer   // for changed(); only touched on the UI thread
+}
+
+// how long after the user last changed a bound control its setting is saved; see changed()
+const settingSaveDelay = time.Second
+
+// changed() is called on the UI thread when the value of the control changes (see cSysData.settingChanged()); the setting is saved once it has stopped changing for settingSaveDelay, on another goroutine, as saving can't happen on the UI thread
+// the changes the control's make() makes itself happen before the Window adds its settings to liveSettings, and once the last save is done the setting is gone from there again, so neither of those saves anything
+func (s *setting) changed() {
+	liveSettings.lock.Lock()
+	_, live := liveSettings.settings[s]
+	liveSettings.lock.Unlock()
+	if !live {
+		return
+	}
+	if s.timer == nil {
+		s.timer = time.AfterFunc(settingSaveDelay, func() {
+			s.save(false)
+		})
+		return
+	}
+	s.timer.Reset(settingSaveDelay)
+}
+
+// never runs on the UI thread; final is true for the save in Window.Destroy() or DestroyControl(), before the controls are destroyed
+func (s *setting) save(final bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.done {
+		return
+	}
+	if final {
+		s.done = true
+		liveSettings.lock.Lock()
+		delete(liveSettings.settings, s)
+		liveSettings.lock.Unlock()
+	}
+	err := s.store.Save(s.key, s.get())
+	if err == nil {
+		return
+	}
+	e := newError(s.control, fmt.Sprintf("saving setting %q", s.key), "SettingsStore.Save()", err)
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		reportError(e)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// the settings of every Window that has been created and not yet destroyed, for saveAllSettings()
+var liveSettings struct {
+	lock     sync.Mutex
+	settings map[*setting]struct{}
+}
+
+// called by Window.Create() once the controls have been made
+func addLiveSettings(settings []*setting) {
+	liveSettings.lock.Lock()
+	defer liveSettings.lock.Unlock()
+
+	if liveSettings.settings == nil {
+		liveSettings.settings = make(map[*setting]struct{})
+	}
+	for _, s := range settings {
+		liveSettings.settings[s] = struct{}{}
+	}
+}
+
+// saves the settings of every Window that hasn't been destroyed; called on its own goroutine before AppQuit is signaled, so a program that quits on AppQuit keeps them
+func saveAllSettings() {
+	liveSettings.lock.Lock()
+	settings := make([]*setting, 0, len(liveSettings.settings))
+	for s := range liveSettings.settings {
+		settings = append(settings, s)
+	}
+	liveSettings.lock.Unlock()
+	for _, s := range settings {
+		s.save(false)
+	}
+}
+
+// called by cSysData.signal() on the UI thread when the user closes a Window with settings; they're saved on another goroutine (they can't be on the UI thread), and Closing is only signaled once they are, so a program that quits on Closing keeps them
+func saveSettingsAndSignal(settings []*setting, event chan struct{}) {
+	go func() {
+		for _, s := range settings {
+			s.save(false)
+		}
+		sendEvent(event)
+	}()
+}
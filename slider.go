@@ -0,0 +1,165 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A Slider is a bar with a thumb the user drags along it to pick an integer in a range, [min,max], in increments of step.
+// A horizontal Slider has its minimum at the left; a vertical Slider has its minimum at the bottom.
+// If max-min is not a multiple of step, the largest value the user can pick is the last step before max.
+// Newly-created Sliders start at their minimum.
+type Slider struct {
+	// ValueChanged is signaled when the user moves the Slider to another value.
+	// It is not signaled for SetValue, nor as the user drags the thumb between two steps.
+	ValueChanged chan struct{}
+
+	lock     sync.Mutex
+	created  bool
+	sysData  *sysData
+	min, max int
+	step     int
+	n        int // the values are min, min+step, ..., min+n*step; the system only deals in these positions, so they are all the system snaps to
+	initPos  int
+}
+
+func newSlider(vertical bool, min int, max int, step int) *Slider {
+	if step <= 0 || min >= max {
+		which := "Horizontal"
+		if vertical {
+			which = "Vertical"
+		}
+		panic(fmt.Errorf("invalid range [%d,%d] and step %d given to New%sSlider()", min, max, step, which))
+	}
+	s := &Slider{
+		ValueChanged: newEvent(),
+		sysData:      mksysdata(c_slider),
+		min:          min,
+		max:          max,
+		step:         step,
+		n:            (max - min) / step,
+	}
+	s.sysData.alternate = vertical
+	return s
+}
+
+// NewHorizontalSlider creates a new horizontal Slider with the given range and step.
+// min must be less than max and step must be positive; otherwise, NewHorizontalSlider panics.
+func NewHorizontalSlider(min int, max int, step int) *Slider {
+	return newSlider(false, min, max, step)
+}
+
+// NewVerticalSlider creates a new vertical Slider with the given range and step.
+// min must be less than max and step must be positive; otherwise, NewVerticalSlider panics.
+func NewVerticalSlider(min int, max int, step int) *Slider {
+	return newSlider(true, min, max, step)
+}
+
+// Value returns the value the Slider is at.
+func (s *Slider) Value() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.created {
+		return s.min + s.initPos*s.step
+	}
+	ret := make(chan int)
+	defer close(ret)
+	uitask(func() {
+		ret <- s.sysData.sliderPosition()
+	})
+	return s.min + <-ret*s.step
+}
+
+// SetValue moves the Slider to the step nearest to value.
+// It panics if value is outside the range given to NewHorizontalSlider or NewVerticalSlider.
+func (s *Slider) SetValue(value int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if value < s.min || value > s.max {
+		panic(fmt.Errorf("value %d out of range [%d,%d] in Slider.SetValue()", value, s.min, s.max))
+	}
+	pos := (value - s.min + s.step/2) / s.step
+	if pos > s.n {
+		pos = s.n
+	}
+	if !s.created {
+		s.initPos = pos
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.sysData.sliderPos = pos // so the system telling us it moved doesn't signal ValueChanged; see sysData.sliderChanged()
+		s.sysData.sliderSetPosition(pos)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *Slider) make(window *sysData) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.sysData.control = s
+	s.sysData.event = s.ValueChanged
+	err := s.sysData.make(window)
+	if err != nil {
+		return err
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.sysData.sliderPos = s.initPos
+		s.sysData.sliderSetRange(s.n)
+		s.sysData.sliderSetPosition(s.initPos)
+		ret <- struct{}{}
+	})
+	<-ret
+	s.created = true
+	return nil
+}
+
+// called by the platform code on the UI thread when the user moves a Slider
+// the systems tell us about every movement of the thumb, not just the ones that reach another step, so only the latter are signaled
+func (s *sysData) sliderChanged() {
+	pos := s.sliderPosition()
+	if pos == s.sliderPos {
+		return
+	}
+	s.sliderPos = pos
+	s.signal()
+}
+
+// a Slider only needs its preferred thickness, so rather than stretch it across a whole Stack or Grid cell, center it in the cell
+// (on Mac OS X this also keeps a vertical Slider taller than it is wide, which is how NSSlider knows it is vertical; see slider_darwin.go)
+func (s *Slider) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	prefwidth, prefheight := s.sysData.preferredSize(d)
+	if s.sysData.alternate {
+		if width > prefwidth {
+			x += (width - prefwidth) / 2
+			width = prefwidth
+		}
+	} else {
+		if height > prefheight {
+			y += (height - prefheight) / 2
+			height = prefheight
+		}
+	}
+	return s.sysData.singleAllocation(x, y, width, height, s)
+}
+
+func (s *Slider) preferredSize(d *sysSizeData) (width int, height int) {
+	return s.sysData.preferredSize(d)
+}
+
+func (s *Slider) commitResize(a *allocation, d *sysSizeData) {
+	s.sysData.commitResize(a, d)
+}
+
+func (s *Slider) getAuxResizeInfo(d *sysSizeData) {
+	s.sysData.getAuxResizeInfo(d)
+}
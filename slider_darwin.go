@@ -0,0 +1,37 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+/*
+On Mac OS X, a Slider is an NSSlider, whose values are the Slider's positions (see slider.go).
+NSSlider works in doubles, and can only snap to tick marks, which it would then draw, one per position; so it doesn't snap, and sysData.sliderChanged() only signals when the rounded position changes.
+The app delegate is its target, and it is continuous, so it's told about every movement of the thumb; see appDelegate_sliderMoved(). NSSlider doesn't tell it about setDoubleValue:.
+NSSlider has no orientation of its own: it's vertical if it's taller than it is wide. We make it that way, and Slider.allocate() keeps it that way.
+*/
+
+func makeSlider(parentWindow C.id, alternate bool, s *sysData) C.id {
+	slider := C.makeSlider(toBOOL(alternate), appDelegate)
+	addControl(parentWindow, slider)
+	return slider
+}
+
+func (s *sysData) sliderSetRange(n int) {
+	C.sliderSetRange(s.id, C.intptr_t(n))
+}
+
+func (s *sysData) sliderSetPosition(pos int) {
+	C.sliderSetPosition(s.id, C.intptr_t(pos))
+}
+
+func (s *sysData) sliderPosition() int {
+	return int(C.sliderPosition(s.id))
+}
+
+// -[NSSlider sizeToFit] would make it as long as it is thick, which can turn a vertical one horizontal
+func sliderPrefSize(control C.id) (width int, height int) {
+	r := C.sliderPrefSize(control)
+	return int(r.width), int(r.height)
+}
@@ -0,0 +1,67 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSSlider.h>
+#import <AppKit/NSSliderCell.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSSlider(x) to(NSSlider, (x))
+
+// how long a Slider asks to be; NSSlider has no preferred length
+#define sliderLength 100
+
+id makeSlider(BOOL vertical, id delegate)
+{
+	NSSlider *slider;
+	NSRect frame;
+
+	// see slider_darwin.go for why the shape of the frame matters
+	frame = NSMakeRect(0, 0, sliderLength, 20);
+	if (vertical)
+		frame = NSMakeRect(0, 0, 20, sliderLength);
+	slider = [[NSSlider alloc]
+		initWithFrame:frame];
+	// the range is set by Slider.make()
+	[slider setMinValue:0];
+	[slider setMaxValue:1];
+	[slider setDoubleValue:0];
+	[slider setContinuous:YES];
+	[slider setTarget:delegate];
+	[slider setAction:@selector(sliderMoved:)];
+	return slider;
+}
+
+void sliderSetRange(id slider, intptr_t n)
+{
+	[toNSSlider(slider) setMaxValue:((double) n)];
+}
+
+void sliderSetPosition(id slider, intptr_t pos)
+{
+	[toNSSlider(slider) setDoubleValue:((double) pos)];
+}
+
+// the positions are never negative, so this rounds
+intptr_t sliderPosition(id slider)
+{
+	return (intptr_t) ([toNSSlider(slider) doubleValue] + 0.5);
+}
+
+struct xsize sliderPrefSize(id slider)
+{
+	NSSlider *s;
+	NSSize size;
+	struct xsize xs;
+
+	s = toNSSlider(slider);
+	size = [[s cell] cellSize];
+	// the cell's size is just enough for the knob, so only take its thickness
+	if ([s isVertical] == 1) {
+		xs.width = (intptr_t) size.width;
+		xs.height = sliderLength;
+	} else {
+		xs.width = sliderLength;
+		xs.height = (intptr_t) size.height;
+	}
+	return xs;
+}
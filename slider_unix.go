@@ -0,0 +1,88 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern void our_slider_value_changed_callback(GtkRange *, gpointer);
+import "C"
+
+/*
+On GTK+, a Slider is a GtkScale, whose values are the Slider's positions (see slider.go); it tells us it was moved with "value-changed" (see the classData for Sliders), which it also sends for gtk_range_set_value(), hence sysData.sliderPos.
+GtkScale works in doubles; rounding its digits to none makes it snap to whole positions as the user drags it.
+It would show the position above the thumb, which isn't the Slider's value, and the other systems don't show a value at all, so we turn that off.
+*/
+
+// GtkScale's natural length is next to nothing, so a Slider asks for this much
+const gtkSliderLength = 100 // pixels
+
+func togtkrange(what *C.GtkWidget) *C.GtkRange {
+	return (*C.GtkRange)(unsafe.Pointer(what))
+}
+
+func gtkSliderNew(orientation C.GtkOrientation) *C.GtkWidget {
+	// the range is set by Slider.make()
+	widget := C.gtk_scale_new_with_range(orientation, 0, 1, 1)
+	C.gtk_scale_set_draw_value((*C.GtkScale)(unsafe.Pointer(widget)), C.FALSE)
+	C.gtk_range_set_round_digits(togtkrange(widget), 0)
+	return widget
+}
+
+func gtkHorizontalSliderNew() *C.GtkWidget {
+	return gtkSliderNew(C.GTK_ORIENTATION_HORIZONTAL)
+}
+
+func gtkVerticalSliderNew() *C.GtkWidget {
+	widget := gtkSliderNew(C.GTK_ORIENTATION_VERTICAL)
+	// a vertical GtkScale has its minimum at the top otherwise
+	C.gtk_range_set_inverted(togtkrange(widget), C.TRUE)
+	return widget
+}
+
+func (s *sysData) sliderSetRange(n int) {
+	r := togtkrange(s.widget)
+	C.gtk_range_set_range(r, 0, C.gdouble(n))
+	// the arrow keys move one step and Page Up and Page Down a fifth of the way, as on Windows
+	page := n / 5
+	if page < 1 {
+		page = 1
+	}
+	C.gtk_range_set_increments(r, 1, C.gdouble(page))
+}
+
+func (s *sysData) sliderSetPosition(pos int) {
+	C.gtk_range_set_value(togtkrange(s.widget), C.gdouble(pos))
+}
+
+func (s *sysData) sliderPosition() int {
+	// the positions are never negative, so this rounds
+	return int(C.gtk_range_get_value(togtkrange(s.widget)) + 0.5)
+}
+
+//export our_slider_value_changed_callback
+func our_slider_value_changed_callback(r *C.GtkRange, what C.gpointer) {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(what))
+	s.sliderChanged()
+}
+
+var slider_value_changed_callback = C.GCallback(C.our_slider_value_changed_callback)
+
+// see sysPreferredSize()
+func (s *sysData) sliderPreferredSize(width int, height int) (int, int) {
+	if s.alternate {
+		if height < gtkSliderLength {
+			height = gtkSliderLength
+		}
+	} else {
+		if width < gtkSliderLength {
+			width = gtkSliderLength
+		}
+	}
+	return width, height
+}
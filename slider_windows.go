@@ -0,0 +1,65 @@
+// 9 july 2014
+
+package ui
+
+/*
+On Windows, a Slider is a trackbar, whose positions are the Slider's steps (see slider.go).
+A trackbar tells the window it's in that it was moved with WM_HSCROLL or WM_VSCROLL; see stdWndProc(). It doesn't for TBM_SETPOS.
+A vertical trackbar has its minimum at the top, and can't be told otherwise, so for vertical Sliders we turn the positions around ourselves.
+*/
+
+func (s *sysData) sliderSetRange(n int) {
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TBM_SETRANGEMIN),
+		uintptr(_FALSE), // don't redraw yet
+		uintptr(0))
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TBM_SETRANGEMAX),
+		uintptr(_TRUE),
+		uintptr(n))
+	// Page Up and Page Down (and clicking beside the thumb) move a fifth of the way, which MSDN says is the default; set it anyway, as the range changed since the trackbar was made
+	page := n / 5
+	if page < 1 {
+		page = 1
+	}
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TBM_SETPAGESIZE),
+		uintptr(0),
+		uintptr(page))
+}
+
+func (s *sysData) sliderMax() int {
+	r1, _, _ := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TBM_GETRANGEMAX),
+		uintptr(0),
+		uintptr(0))
+	return int(r1)
+}
+
+func (s *sysData) sliderSetPosition(pos int) {
+	if s.alternate {
+		pos = s.sliderMax() - pos
+	}
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TBM_SETPOS),
+		uintptr(_TRUE), // redraw
+		uintptr(pos))
+}
+
+func (s *sysData) sliderPosition() int {
+	r1, _, _ := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TBM_GETPOS),
+		uintptr(0),
+		uintptr(0))
+	pos := int(r1)
+	if s.alternate {
+		pos = s.sliderMax() - pos
+	}
+	return pos
+}
@@ -4,31 +4,37 @@ package ui
 
 import (
 	"fmt"
-	"sync"
 )
 
-type orientation bool
+// Orientation is which way a Stack stacks its controls; see Stack.SetOrientation.
+type Orientation bool
 
 const (
-	horizontal orientation = false
-	vertical   orientation = true
+	Horizontal Orientation = false
+	Vertical   Orientation = true
 )
 
 // A Stack stacks controls horizontally or vertically within the Stack's parent.
 // A horizontal Stack gives all controls the same height and their preferred widths.
 // A vertical Stack gives all controls the same width and their preferred heights.
 // Any extra space at the end of a Stack is left blank.
+// If the Stack is too small for the preferred sizes of its controls, its stretchy controls shrink to nothing first, then the controls at its end (right or bottom) are cut off, down to nothing if need be; no control is ever given a negative size or placed outside the Stack.
 // Some controls may be marked as "stretchy": when the Window they are in changes size, stretchy controls resize to take up the remaining space after non-stretchy controls are laid out. If multiple controls are marked stretchy, they are alloted equal distribution of the remaining space.
+//
+// Unlike other Controls, a Stack is not safe for concurrent use while you build it: create it, fill it, and call SetStretchy() from one goroutine (or synchronize the calls yourself) before creating the Window that contains it. Once the Window is created, the Stack belongs to the UI thread; see "Goroutines and the UI Thread" in the package documentation.
 type Stack struct {
-	lock          sync.Mutex
 	created       bool
-	orientation   orientation
+	orientation   Orientation
+	window        *sysData // for SetOrientation(); set by make()
+	breakpoint    int      // see SetBreakpoint()
 	controls      []Control
 	stretchy      []bool
 	width, height []int // caches to avoid reallocating these each time
+	allocations   []*allocation // likewise
+	laidOut       layoutRect    // see SetOrientation()
 }
 
-func newStack(o orientation, controls ...Control) *Stack {
+func newStack(o Orientation, controls ...Control) *Stack {
 	return &Stack{
 		orientation: o,
 		controls:    controls,
@@ -40,20 +46,17 @@ func newStack(o orientation, controls ...Control) *Stack {
 
 // NewHorizontalStack creates a new Stack that arranges the given Controls horizontally.
 func NewHorizontalStack(controls ...Control) *Stack {
-	return newStack(horizontal, controls...)
+	return newStack(Horizontal, controls...)
 }
 
 // NewVerticalStack creates a new Stack that arranges the given Controls vertically.
 func NewVerticalStack(controls ...Control) *Stack {
-	return newStack(vertical, controls...)
+	return newStack(Vertical, controls...)
 }
 
 // SetStretchy marks a control in a Stack as stretchy. This cannot be called once the Window containing the Stack has been created.
 // It panics if index is out of range.
 func (s *Stack) SetStretchy(index int) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
-
 	if s.created {
 		panic("call to Stack.SetStretchy() after Stack has been created")
 	}
@@ -63,10 +66,51 @@ func (s *Stack) SetStretchy(index int) {
 	s.stretchy[index] = true
 }
 
-func (s *Stack) make(window *sysData) error {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+// SetOrientation changes which way the Stack stacks its controls; NewHorizontalStack and NewVerticalStack set it to begin with.
+// Unlike SetStretchy, SetOrientation can also be called once the Window containing the Stack has been created, from any goroutine; the Stack is then laid out again right away (and the rest of the Window with it if its preferred size changed; see Window.Relayout), so a Window can, for instance, show two panes side by side when it's wide and one above the other when it's narrow.
+// Which controls are stretchy stays the same, so a stretchy control takes the extra width in one orientation and the extra height in the other.
+func (s *Stack) SetOrientation(o Orientation) {
+	if !s.created {
+		s.orientation = o
+		return
+	}
+	// once created, the Stack belongs to the UI thread
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		d := s.window.beginResize()
+		oldwidth, oldheight := s.preferredSize(d)
+		s.orientation = o
+		width, height := s.preferredSize(d)
+		if width == oldwidth && height == oldheight {
+			s.window.relayoutPart(s, &s.laidOut)
+		} else {
+			s.window.doRelayout()
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// SetBreakpoint makes the Stack choose its orientation by its width each time it is laid out: horizontal when it is given at least width pixels (margins included), and vertical when it is narrower.
+// Use it for layouts that adapt to small Windows the way phone apps do, such as a list and its details side by side in a wide Window but one above the other in a narrow one; nest such Stacks to adapt more than one part of the layout.
+// Which controls are stretchy stays the same, so a stretchy control takes the extra width in one orientation and the extra height in the other.
+// The Stack's parent asks for its preferred size before the Stack knows its width, so the preferred size is the one for the orientation it was last laid out with; a Stack that isn't stretchy in its parent can thus be cut off until the next resize after it switches, which making it stretchy avoids.
+// While a breakpoint is set, it decides the orientation, and SetOrientation only changes what the Stack reports as its preferred size until it is next laid out.
+// Pass 0 to remove the breakpoint; the Stack then keeps the orientation it had last.
+// This cannot be called once the Window containing the Stack has been created; it panics if width is negative.
+func (s *Stack) SetBreakpoint(width int) {
+	if s.created {
+		panic("call to Stack.SetBreakpoint() after Stack has been created")
+	}
+	if width < 0 {
+		panic(fmt.Errorf("negative width %d given to Stack.SetBreakpoint()", width))
+	}
+	s.breakpoint = width
+}
 
+func (s *Stack) make(window *sysData) error {
+	s.window = window
 	for i, c := range s.controls {
 		err := c.make(window)
 		if err != nil {
@@ -78,23 +122,41 @@ func (s *Stack) make(window *sysData) error {
 }
 
 func (s *Stack) allocate(x int, y int, width int, height int, d *sysSizeData) (allocations []*allocation) {
+	checkUIThread("Stack.allocate()")
 	var stretchywid, stretchyht int
 	var current *allocation		// for neighboring
 
 	if len(s.controls) == 0 { // do nothing if there's nothing to do
 		return nil
 	}
+	s.laidOut.set(x, y, width, height, d)
+	if s.breakpoint != 0 {
+		s.orientation = Horizontal
+		if width < s.breakpoint {
+			s.orientation = Vertical
+		}
+	}
+	what := "horizontal Stack"
+	if s.orientation == Vertical {
+		what = "vertical Stack"
+	}
+	lc := beginLayoutCheck(d, what, x, y, width, height)
+	lc.setStack(s.orientation)
+	// reuse the slice from the last resize; our parent copies our allocations into its own slice before we can be called again
+	allocations = s.allocations[:0]
 	// before we do anything, steal the margin so nested Stacks/Grids don't double down
 	xmargin := d.xmargin
 	ymargin := d.ymargin
 	d.xmargin = 0
 	d.ymargin = 0
 	// 0) inset the available rect by the margins and needed padding
-	x += xmargin
-	y += ymargin
-	width -= xmargin * 2
-	height -= ymargin * 2
-	if s.orientation == horizontal {
+	x, width = layoutInset(x, width, xmargin)
+	y, height = layoutInset(y, height, ymargin)
+	end := x + width // where the controls have to stop, and where the last one should end if any are stretchy
+	if s.orientation == Vertical {
+		end = y + height
+	}
+	if s.orientation == Horizontal {
 		width -= (len(s.controls) - 1) * d.xpadding
 	} else {
 		height -= (len(s.controls) - 1) * d.ypadding
@@ -109,7 +171,7 @@ func (s *Stack) allocate(x int, y int, width int, height int, d *sysSizeData) (a
 			continue
 		}
 		w, h := c.preferredSize(d)
-		if s.orientation == horizontal { // all controls have same height
+		if s.orientation == Horizontal { // all controls have same height
 			s.width[i] = w
 			s.height[i] = height
 			stretchywid -= w
@@ -120,10 +182,21 @@ func (s *Stack) allocate(x int, y int, width int, height int, d *sysSizeData) (a
 		}
 	}
 	// 2) figure out size of stretchy controls
+	// if the other controls take up more than there is, the stretchy controls get nothing, and the other controls are cut off in step 3
+	if stretchywid < 0 {
+		stretchywid = 0
+	}
+	if stretchyht < 0 {
+		stretchyht = 0
+	}
+	// the pixels left over from dividing go one each to the first stretchy controls, so the stretchy controls fill the Stack exactly instead of leaving a gap at the end
+	extra := 0
 	if nStretchy != 0 {
-		if s.orientation == horizontal { // split rest of width
+		if s.orientation == Horizontal { // split rest of width
+			extra = stretchywid % nStretchy
 			stretchywid /= nStretchy
 		} else { // split rest of height
+			extra = stretchyht % nStretchy
 			stretchyht /= nStretchy
 		}
 	}
@@ -133,12 +206,27 @@ func (s *Stack) allocate(x int, y int, width int, height int, d *sysSizeData) (a
 		}
 		s.width[i] = stretchywid
 		s.height[i] = stretchyht
+		if extra > 0 {
+			if s.orientation == Horizontal {
+				s.width[i]++
+			} else {
+				s.height[i]++
+			}
+			extra--
+		}
 	}
-	// 3) now actually place controls
+	// 3) now actually place controls, cutting off those that go past the end
 	for i, c := range s.controls {
-		as := c.allocate(x, y, s.width[i], s.height[i], d)
-		if s.orientation == horizontal {		// no vertical neighbors
-			if current != nil {			// connect first left to first right
+		w, h := s.width[i], s.height[i]
+		if s.orientation == Horizontal {
+			x, w = layoutClip(x, w, end)
+		} else {
+			y, h = layoutClip(y, h, end)
+		}
+		lc.child(d, i, -1, x, y, w, h)
+		as := c.allocate(x, y, w, h, d)
+		if s.orientation == Horizontal {		// no vertical neighbors
+			if current != nil && len(as) != 0 {	// connect first left to first right, if there's anything to align with (not so for a destroyed Control; see DestroyControl())
 				current.neighbor = c
 			}
 			if len(as) != 0 {
@@ -148,18 +236,21 @@ func (s *Stack) allocate(x int, y int, width int, height int, d *sysSizeData) (a
 			}
 		}
 		allocations = append(allocations, as...)
-		if s.orientation == horizontal {
-			x += s.width[i] + d.xpadding
+		if s.orientation == Horizontal {
+			x += w + d.xpadding
 		} else {
-			y += s.height[i] + d.ypadding
+			y += h + d.ypadding
 		}
 	}
+	lc.done(d, nStretchy != 0, end)
+	s.allocations = allocations
 	return allocations
 }
 
 // The preferred size of a Stack is the sum of the preferred sizes of non-stretchy controls + (the number of stretchy controls * the largest preferred size among all stretchy controls).
 // We don't consider the margins here, but will need to if Window.SizeToFit() is ever made a thing.
 func (s *Stack) preferredSize(d *sysSizeData) (width int, height int) {
+	checkUIThread("Stack.preferredSize()")
 	max := func(a int, b int) int {
 		if a > b {
 			return a
@@ -173,7 +264,7 @@ func (s *Stack) preferredSize(d *sysSizeData) (width int, height int) {
 	if len(s.controls) == 0 { // no controls, so return emptiness
 		return 0, 0
 	}
-	if s.orientation == horizontal {
+	if s.orientation == Horizontal {
 		width = (len(s.controls) - 1) * d.xpadding
 	} else {
 		height = (len(s.controls) - 1) * d.ypadding
@@ -185,7 +276,7 @@ func (s *Stack) preferredSize(d *sysSizeData) (width int, height int) {
 			maxswid = max(maxswid, w)
 			maxsht = max(maxsht, h)
 		}
-		if s.orientation == horizontal { // max vertical size
+		if s.orientation == Horizontal { // max vertical size
 			if !s.stretchy[i] {
 				width += w
 			}
@@ -197,7 +288,7 @@ func (s *Stack) preferredSize(d *sysSizeData) (width int, height int) {
 			}
 		}
 	}
-	if s.orientation == horizontal {
+	if s.orientation == Horizontal {
 		width += nStretchy * maxswid
 	} else {
 		height += nStretchy * maxsht
@@ -227,4 +318,4 @@ func Space() Control {
 }
 
 // As above, a Stack with no controls draws nothing and reports no errors; its parent will still size it properly if made stretchy.
-var space Control = newStack(horizontal)
+var space Control = newStack(Horizontal)
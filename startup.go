@@ -0,0 +1,51 @@
+// 4 july 2014
+
+package ui
+
+import (
+	"sync"
+	"time"
+)
+
+// Timings holds how long package ui took to do various things the first time it did them.
+// A zero field means that thing hasn't happened yet.
+type Timings struct {
+	// Init is how long it took to initialize the system's GUI toolkit (for instance, gtk_init() on GTK+).
+	// This happens the first time package ui is used, not when Go() is called; see Go().
+	Init time.Duration
+
+	// FirstWindow is how long the first call to Window.Create() (or Window.Open()) took to create the Window and all its Controls.
+	FirstWindow time.Duration
+
+	// FirstLayout is how long it took to lay out a Window's Controls the first time any Window was laid out.
+	// Depending on the system, this happens either during the first Window.Create() (and thus is included in FirstWindow) or when the Window is first shown.
+	FirstLayout time.Duration
+}
+
+var startup struct {
+	lock sync.Mutex
+	t    Timings
+}
+
+// StartupTimings returns how long various parts of starting up took.
+// Use it to find out where the time goes before your program's first Window appears.
+func StartupTimings() Timings {
+	startup.lock.Lock()
+	defer startup.lock.Unlock()
+
+	return startup.t
+}
+
+// only the first call for a given field has any effect
+func setStartupTime(which *time.Duration, start time.Time) {
+	d := time.Since(start)
+	startup.lock.Lock()
+	defer startup.lock.Unlock()
+
+	if *which == 0 {
+		*which = d
+	}
+}
+
+// only touched on the UI thread; see sysData.resizeWindow()
+var laidOut = false
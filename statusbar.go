@@ -0,0 +1,133 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A StatusBar is a row of text panes along the bottom of a Window, below its controls, with an optional ProgressBar at the right end; see Window.SetStatusBar.
+// The first pane takes up whatever space the others don't; each of the others is as wide as its text.
+// Like the Controls, a StatusBar can be changed from any goroutine, so a long-running task can report on itself there.
+//
+// On Mac OS X, the StatusBar is drawn in the bottom border of the Window, as Mac OS X programs do.
+type StatusBar struct {
+	lock         sync.Mutex
+	used         bool
+	created      bool
+	texts        []string // only changed on the UI thread once created
+	progress     *ProgressBar
+	window       *sysData
+	sysStatusBar // see statusbar_windows.go, statusbar_unix.go, and statusbar_darwin.go
+}
+
+// NewStatusBar creates a new StatusBar with a text pane for each of texts, showing it; with no texts, there is one empty pane.
+func NewStatusBar(texts ...string) *StatusBar {
+	if len(texts) == 0 {
+		texts = []string{""}
+	}
+	return &StatusBar{
+		texts: append([]string(nil), texts...),
+	}
+}
+
+// SetProgressBar puts p at the right end of the StatusBar; pass nil to take it away again.
+// Use p as you would any other ProgressBar, but don't put it in a Window yourself.
+// This function cannot be called after the Window with the StatusBar has been created.
+func (b *StatusBar) SetProgressBar(p *ProgressBar) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.created {
+		panic("StatusBar.SetProgressBar() called after the Window has been created")
+	}
+	b.progress = p
+}
+
+// Text returns the text of the given pane, counting from 0.
+// It panics if there is no such pane.
+func (b *StatusBar) Text(pane int) string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.checkPane(pane, "Text")
+	return b.texts[pane]
+}
+
+// SetText changes the text of the given pane, counting from 0.
+// It panics if there is no such pane.
+func (b *StatusBar) SetText(pane int, text string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.checkPane(pane, "SetText")
+	if !b.created {
+		b.texts[pane] = text
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		b.texts[pane] = text
+		b.window.statusBarSetText(pane)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (b *StatusBar) checkPane(pane int, what string) {
+	if pane < 0 || pane >= len(b.texts) {
+		panic(fmt.Errorf("pane %d out of range [0,%d) in StatusBar.%s()", pane, len(b.texts), what))
+	}
+}
+
+// SetStatusBar gives the Window the StatusBar b, replacing the one given before, if any; pass nil to have none.
+// This function cannot be called after the Window has been created, and panics if b is already in a Window.
+func (w *Window) SetStatusBar(b *StatusBar) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.created {
+		panic("Window.SetStatusBar() called after the Window has been created")
+	}
+	if b != nil {
+		b.lock.Lock()
+		if b.used {
+			b.lock.Unlock()
+			panic("StatusBar passed to Window.SetStatusBar() is already used")
+		}
+		b.used = true
+		b.lock.Unlock()
+	}
+	if w.initStatusBar != nil {
+		w.initStatusBar.lock.Lock()
+		w.initStatusBar.used = false
+		w.initStatusBar.lock.Unlock()
+	}
+	w.initStatusBar = b
+}
+
+// called by Window.Create() with the Window locked
+func (s *sysData) makeStatusBar(b *StatusBar) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.progress != nil {
+		// the ProgressBar is made like any other control of the Window, then moved into the StatusBar
+		err := b.progress.make(s)
+		if err != nil {
+			panic(fmt.Errorf("error adding status bar's ProgressBar: %v", err))
+		}
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.statusBar = b
+		s.sysMakeStatusBar(b)
+		ret <- struct{}{}
+	})
+	<-ret
+	b.window = s
+	b.created = true
+}
@@ -0,0 +1,40 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// see statusbar_darwin.m
+
+type sysStatusBar struct {
+	id C.id
+}
+
+// runs on the UI thread
+func (s *sysData) sysMakeStatusBar(b *StatusBar) {
+	b.id = C.makeStatusBar(s.id)
+	for n := range b.texts {
+		C.statusBarAddPane(b.id)
+		C.statusBarSetText(b.id, C.intptr_t(n), toNSString(b.texts[n]))
+	}
+	if b.progress != nil {
+		C.statusBarAddProgress(b.id, b.progress.sysData.id)
+	}
+	C.statusBarLayout(b.id, toBOOL(b.progress != nil))
+}
+
+// runs on the UI thread
+func (s *sysData) statusBarSetText(pane int) {
+	b := s.statusBar
+	C.statusBarSetText(b.id, C.intptr_t(pane), toNSString(b.texts[pane]))
+	C.statusBarLayout(b.id, toBOOL(b.progress != nil))
+}
+
+// runs on the UI thread
+func (s *sysData) statusBarHeight() int {
+	if s.statusBar == nil {
+		return 0
+	}
+	return int(C.statusBarGetHeight())
+}
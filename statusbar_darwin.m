@@ -0,0 +1,107 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSWindow.h>
+#import <AppKit/NSView.h>
+#import <AppKit/NSTextField.h>
+#import <AppKit/NSFont.h>
+#import <Foundation/NSArray.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSWindow(x) to(NSWindow, (x))
+#define toNSView(x) to(NSView, (x))
+#define toNSTextField(x) to(NSTextField, (x))
+
+extern NSRect dummyRect;
+
+/*
+A StatusBar is an NSView across the bottom of the window's content view, which the window draws as its bottom border (the content border), as Mac OS X programs with status bars do; the controls of the window are laid out above it (see sysData.statusBarHeight()).
+The panes are labels in the small system font, in order; the ProgressBar, if any, is made as a control of the window and then moved in after them.
+Autoresizing keeps the bar across the window, the first pane stretching, and everything else at the right as the window is resized; statusBarLayout() only needs to run again when the text of a pane changes.
+*/
+
+#define statusBarHeight 22		/* the HIG's small bottom border */
+#define statusBarPadding 8
+#define statusBarProgressWidth 120
+
+intptr_t statusBarGetHeight(void)
+{
+	return statusBarHeight;
+}
+
+id makeStatusBar(id window)
+{
+	NSView *cv;
+	NSView *bar;
+
+	[toNSWindow(window) setAutorecalculatesContentBorderThickness:NO forEdge:NSMinYEdge];
+	[toNSWindow(window) setContentBorderThickness:statusBarHeight forEdge:NSMinYEdge];
+	cv = [toNSWindow(window) contentView];
+	bar = [[NSView alloc]
+		initWithFrame:NSMakeRect(0, 0, NSWidth([cv bounds]), statusBarHeight)];
+	[bar setAutoresizingMask:(NSViewWidthSizable | NSViewMaxYMargin)];
+	[cv addSubview:bar];
+	[bar release];		// the content view has it now
+	return bar;
+}
+
+void statusBarAddPane(id bar)
+{
+	NSTextField *label;
+
+	label = makeLabel();
+	[label setFont:[NSFont systemFontOfSize:[NSFont systemFontSizeForControlSize:NSSmallControlSize]]];
+	[[label cell] setLineBreakMode:NSLineBreakByTruncatingTail];
+	[toNSView(bar) addSubview:label];
+	[label release];		// the bar has it now
+}
+
+void statusBarAddProgress(id bar, id progress)
+{
+	[toNSView(progress) retain];		// or taking it out of the content view releases it
+	[toNSView(progress) removeFromSuperview];
+	[toNSView(bar) addSubview:toNSView(progress)];
+	[toNSView(progress) release];		// the bar has it now
+}
+
+void statusBarSetText(id bar, intptr_t pane, id text)
+{
+	[toNSTextField([[toNSView(bar) subviews] objectAtIndex:pane]) setStringValue:((NSString *) text)];
+}
+
+// lays out the subviews of the bar from right to left; the last one is the ProgressBar if hasProgress
+void statusBarLayout(id bar, BOOL hasProgress)
+{
+	NSArray *views;
+	NSView *v;
+	NSRect r;
+	CGFloat x;
+	NSUInteger i;
+
+	views = [toNSView(bar) subviews];
+	x = NSWidth([toNSView(bar) bounds]) - statusBarPadding;
+	for (i = [views count] - 1; i >= 1; i--) {
+		v = toNSView([views objectAtIndex:i]);
+		if (hasProgress && i == [views count] - 1)
+			r = NSMakeRect(0, 0, statusBarProgressWidth, NSHeight([v frame]));
+		else {
+			[toNSTextField(v) sizeToFit];
+			r = [v frame];
+		}
+		x -= NSWidth(r);
+		r.origin.x = x;
+		r.origin.y = (statusBarHeight - NSHeight(r)) / 2;
+		[v setFrame:r];
+		[v setAutoresizingMask:NSViewMinXMargin];
+		x -= statusBarPadding;
+	}
+	// and the first pane gets the rest
+	v = toNSView([views objectAtIndex:0]);
+	[toNSTextField(v) sizeToFit];
+	r = [v frame];
+	r.origin.x = statusBarPadding;
+	r.origin.y = (statusBarHeight - NSHeight(r)) / 2;
+	r.size.width = x - statusBarPadding;
+	[v setFrame:r];
+	[v setAutoresizingMask:NSViewWidthSizable];
+}
@@ -0,0 +1,70 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// /* GtkStatusbar is a stack of messages; we only ever show one */
+// static inline void gtkStatusbarSetText(GtkStatusbar *bar, char *text)
+// {
+// 	guint context;
+//
+// 	context = gtk_statusbar_get_context_id(bar, "ui");
+// 	gtk_statusbar_remove_all(bar, context);
+// 	gtk_statusbar_push(bar, context, text);
+// }
+import "C"
+
+/*
+A StatusBar is a GtkStatusbar, packed below the layout of the Window in its box, so gtkWindowBarsHeight() counts it like the menu bar and toolbar.
+The GtkStatusbar itself shows the first pane; it is a GtkBox, so the other panes are GtkLabels packed after it, each with a separator before it, and the ProgressBar goes at the end.
+The ProgressBar is made as a control of the Window and then moved out of its layout into the GtkStatusbar.
+*/
+
+type sysStatusBar struct {
+	widget *C.GtkWidget
+	labels []*C.GtkWidget // for the panes other than the first
+}
+
+// runs on the UI thread
+func (s *sysData) sysMakeStatusBar(b *StatusBar) {
+	b.widget = C.gtk_statusbar_new()
+	bar := (*C.GtkBox)(unsafe.Pointer(b.widget))
+	for range b.texts[1:] {
+		C.gtk_box_pack_start(bar, C.gtk_separator_new(C.GTK_ORIENTATION_VERTICAL), C.FALSE, C.FALSE, 0)
+		label := C.gtk_label_new(nil)
+		C.gtk_box_pack_start(bar, label, C.FALSE, C.FALSE, 0)
+		b.labels = append(b.labels, label)
+	}
+	if b.progress != nil {
+		p := b.progress.sysData.widget
+		C.g_object_ref(C.gpointer(unsafe.Pointer(p))) // or removing it from the layout destroys it
+		C.gtk_container_remove((*C.GtkContainer)(unsafe.Pointer(C.gtk_widget_get_parent(p))), p)
+		C.gtk_widget_set_valign(p, C.GTK_ALIGN_CENTER)
+		C.gtk_box_pack_start(bar, p, C.FALSE, C.FALSE, 0)
+		C.g_object_unref(C.gpointer(unsafe.Pointer(p)))
+	}
+	for n := range b.texts {
+		s.statusBarSetText(n)
+	}
+	box := C.gtk_bin_get_child((*C.GtkBin)(unsafe.Pointer(s.widget)))
+	C.gtk_box_pack_start((*C.GtkBox)(unsafe.Pointer(box)), b.widget, C.FALSE, C.FALSE, 0)
+	C.gtk_widget_show_all(b.widget)
+}
+
+// runs on the UI thread
+func (s *sysData) statusBarSetText(pane int) {
+	b := s.statusBar
+	ctext := C.CString(b.texts[pane])
+	defer C.free(unsafe.Pointer(ctext))
+	if pane == 0 {
+		C.gtkStatusbarSetText((*C.GtkStatusbar)(unsafe.Pointer(b.widget)), ctext)
+		return
+	}
+	C.gtk_label_set_text((*C.GtkLabel)(unsafe.Pointer(b.labels[pane-1])), togstr(ctext))
+}
@@ -0,0 +1,184 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+/*
+A StatusBar is a status bar control along the bottom of the Window's client area; the controls of the Window are laid out in what's left above it (see sysData.statusBarHeight()).
+The status bar puts itself at the bottom when sent WM_SIZE, which the Window does every time it's resized (see stdWndProc()); the parts are worked out again then, from right to left, as only the first one stretches.
+The ProgressBar, if any, is made as a control of the Window and then made a child of the status bar, in a part of its own at the end.
+*/
+
+type sysStatusBar struct {
+	hwnd _HWND
+}
+
+var (
+	_setParent = user32.NewProc("SetParent")
+)
+
+const (
+	statusBarPadding       = 8   // on each side of the text of a part other than the first, at 96 DPI
+	statusBarProgressWidth = 150 // likewise
+)
+
+// runs on the UI thread
+func (s *sysData) sysMakeStatusBar(b *StatusBar) {
+	r1, _, err := _createWindowEx.Call(
+		uintptr(0),
+		utf16ToArg(toUTF16(x_STATUSCLASSNAME)),
+		blankString,
+		uintptr(_WS_CHILD|_WS_VISIBLE|_WS_CLIPSIBLINGS|_SBARS_SIZEGRIP),
+		uintptr(0),
+		uintptr(0),
+		uintptr(0),
+		uintptr(0),
+		uintptr(s.hwnd),
+		uintptr(0),
+		uintptr(hInstance),
+		uintptr(0))
+	if r1 == 0 { // failure
+		panic(s.newError("creating status bar", "CreateWindowEx()", err))
+	}
+	b.hwnd = _HWND(r1)
+	// in the font of the controls, so it can be measured with getTextDC()
+	_sendMessage.Call(
+		uintptr(b.hwnd),
+		uintptr(_WM_SETFONT),
+		uintptr(controlFont),
+		uintptr(_TRUE))
+	if b.progress != nil {
+		r1, _, err = _setParent.Call(
+			uintptr(b.progress.sysData.hwnd),
+			uintptr(b.hwnd))
+		if r1 == 0 { // failure
+			panic(s.newError("moving ProgressBar into status bar", "SetParent()", err))
+		}
+	}
+	s.statusBarAutosize()
+	for n := range b.texts {
+		s.statusBarSetText(n)
+	}
+}
+
+// runs on the UI thread
+func (s *sysData) statusBarSetText(pane int) {
+	b := s.statusBar
+	r1, _, err := _sendMessage.Call(
+		uintptr(b.hwnd),
+		uintptr(_SB_SETTEXTW),
+		uintptr(pane),
+		utf16ToArg(toUTF16(b.texts[pane])))
+	if r1 == 0 { // failure
+		panic(s.newError("setting status bar text", "SB_SETTEXT", err))
+	}
+	// the other panes are as wide as their text
+	if pane != 0 {
+		s.statusBarAutosize()
+	}
+}
+
+// runs on the UI thread when the Window is resized, or a pane's text changes
+func (s *sysData) statusBarAutosize() {
+	var r _RECT
+
+	b := s.statusBar
+	if b == nil {
+		return
+	}
+	_sendMessage.Call(
+		uintptr(b.hwnd),
+		uintptr(_WM_SIZE),
+		uintptr(0),
+		uintptr(0))
+	r1, _, err := _getClientRect.Call(
+		uintptr(b.hwnd),
+		uintptr(unsafe.Pointer(&r)))
+	if r1 == 0 { // failure
+		panic(s.newError("getting status bar width", "GetClientRect()", err))
+	}
+	dpi := screenDPIY()
+	widths := make([]int, 0, len(b.texts)+1)
+	for _, text := range b.texts[1:] {
+		widths = append(widths, statusBarTextWidth(b.hwnd, text)+2*muldiv(statusBarPadding, dpi, 96))
+	}
+	if b.progress != nil {
+		widths = append(widths, muldiv(statusBarProgressWidth, dpi, 96))
+	}
+	// the right edge of each part; the last one goes all the way
+	edges := make([]int32, len(widths)+1)
+	x := int(r.right)
+	for n := len(widths) - 1; n >= 0; n-- {
+		edges[n+1] = int32(x)
+		x -= widths[n]
+	}
+	edges[0] = int32(x)
+	edges[len(edges)-1] = -1
+	r1, _, err = _sendMessage.Call(
+		uintptr(b.hwnd),
+		uintptr(_SB_SETPARTS),
+		uintptr(len(edges)),
+		uintptr(unsafe.Pointer(&edges[0])))
+	if r1 == 0 { // failure
+		panic(s.newError("setting status bar parts", "SB_SETPARTS", err))
+	}
+	if b.progress != nil {
+		r1, _, err = _sendMessage.Call(
+			uintptr(b.hwnd),
+			uintptr(_SB_GETRECT),
+			uintptr(len(edges)-1),
+			uintptr(unsafe.Pointer(&r)))
+		if r1 == 0 { // failure
+			panic(s.newError("getting status bar ProgressBar part", "SB_GETRECT", err))
+		}
+		// keep clear of the size grip, which is drawn over the last part
+		grip, _, _ := _getSystemMetrics.Call(uintptr(_SM_CXVSCROLL))
+		r1, _, err = _moveWindow.Call(
+			uintptr(b.progress.sysData.hwnd),
+			uintptr(r.left),
+			uintptr(r.top),
+			uintptr(r.right-r.left-int32(grip)),
+			uintptr(r.bottom-r.top),
+			uintptr(_TRUE))
+		if r1 == 0 { // failure
+			panic(s.newError("moving ProgressBar in status bar", "MoveWindow()", err))
+		}
+	}
+}
+
+func statusBarTextWidth(hwnd _HWND, text string) int {
+	var size _SIZE
+
+	if text == "" {
+		return 0
+	}
+	dc := getTextDC(hwnd)
+	defer releaseTextDC(hwnd, dc)
+	t := syscall.StringToUTF16(text)
+	_getTextExtentPoint32.Call(
+		uintptr(dc),
+		uintptr(unsafe.Pointer(&t[0])),
+		uintptr(len(t)-1), // without the terminating NUL
+		uintptr(unsafe.Pointer(&size)))
+	return int(size.cx)
+}
+
+// runs on the UI thread
+func (s *sysData) statusBarHeight() int {
+	var r _RECT
+
+	if s.statusBar == nil {
+		return 0
+	}
+	r1, _, err := _getWindowRect.Call(
+		uintptr(s.statusBar.hwnd),
+		uintptr(unsafe.Pointer(&r)))
+	if r1 == 0 { // failure
+		panic(s.newError("getting status bar height", "GetWindowRect()", err))
+	}
+	return int(r.bottom - r.top)
+}
@@ -4,6 +4,7 @@ package ui
 
 import (
 	"fmt"
+	"syscall"
 	"unsafe"
 )
 
@@ -13,6 +14,7 @@ var (
 	smallTitleFont _HANDLE
 	menubarFont    _HANDLE
 	statusbarFont  _HANDLE
+	monospaceFont  _HANDLE // for LogView
 )
 
 type _LOGFONT struct {
@@ -96,5 +98,14 @@ func getStandardWindowFonts() (err error) {
 	if err != nil {
 		return err
 	}
+	// there's no system monospace font, so ask for Consolas at the size of the control font; if Consolas isn't there (it comes with Windows Vista and newer), FIXED_PITCH gets us some other monospace font (usually Courier New)
+	mono := ncm.lfMessageFont
+	mono.lfPitchAndFamily = _FIXED_PITCH | _FF_MODERN
+	mono.lfFaceName = [_LF_FACESIZE]uint16{}
+	copy(mono.lfFaceName[:], syscall.StringToUTF16("Consolas"))
+	monospaceFont, err = getfont(&mono, "monospace")
+	if err != nil {
+		return err
+	}
 	return nil // all good
 }
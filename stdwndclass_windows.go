@@ -103,12 +103,30 @@ func (s *sysData) handleFocus(wParam _WPARAM) {
 }
 
 func stdWndProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM) _LRESULT {
+	defer recoverUIPanic()
 	s := getSysData(hwnd)
 	if s == nil { // not yet saved
 		return storeSysData(hwnd, uMsg, wParam, lParam)
 	}
 	switch uMsg {
+	case _WM_INITMENUPOPUP:
+		menuOpening(uintptr(wParam))
+		return 0
 	case _WM_COMMAND:
+		// menus send WM_COMMAND with no control, and with 0 in the high word; see menu_windows.go
+		if lParam == 0 {
+			if wParam.HIWORD() == 0 {
+				menuItemClicked(uintptr(wParam.LOWORD()))
+			}
+			return 0
+		}
+		// and so do toolbars, with the index of the button plus one; see toolbar_windows.go
+		if s.toolbar != nil && _HWND(lParam) == s.toolbar.hwnd {
+			if n := int(wParam.LOWORD()) - 1; n >= 0 && n < len(s.toolbar.items) {
+				s.toolbar.items[n].clicked()
+			}
+			return 0
+		}
 		id := _HMENU(wParam.LOWORD())
 		s.childrenLock.Lock()
 		ss := s.children[id]
@@ -136,11 +154,106 @@ func stdWndProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM) _LRESUL
 					uintptr(_BM_SETCHECK),
 					state, // already uintptr
 					uintptr(0))
+				ss.settingChanged()
+			}
+		case c_radiobutton:
+			if wParam.HIWORD() == _BN_CLICKED {
+				ss.radioClicked()
+			}
+		case c_colorbutton:
+			if wParam.HIWORD() == _BN_CLICKED {
+				ss.colorButtonClicked(s)
+			}
+		case c_fontbutton:
+			if wParam.HIWORD() == _BN_CLICKED {
+				ss.fontButtonClicked(s)
+			}
+		case c_combobox:
+			switch wParam.HIWORD() {
+			case _CBN_SELCHANGE:
+				ss.comboboxSelChanged()
+				ss.settingChanged()
+			case _CBN_EDITCHANGE: // the user typed in an editable Combobox
+				ss.settingChanged()
+			}
+		case c_listbox:
+			// we get these because of LBS_NOTIFY
+			switch wParam.HIWORD() {
+			case _LBN_SELCHANGE:
+				ss.signal()
+			case _LBN_DBLCLK:
+				ss.signalActivated()
+			}
+		case c_lineedit:
+			switch wParam.HIWORD() {
+			case _EN_SETFOCUS:
+				ss.lineeditFocus(true, false, "")
+			case _EN_KILLFOCUS:
+				ss.lineeditKillFocus(s)
+			case _EN_CHANGE:
+				ss.lineeditChanged()
+			}
+		case c_label:
+			// we get this because of SS_NOTIFY; see Label.SetBuddy()
+			if wParam.HIWORD() == _STN_CLICKED && ss.buddy != nil && ss.buddy.hwnd != _NULL {
+				_setFocus.Call(uintptr(ss.buddy.hwnd))
 			}
 		}
 		return 0
+	case _WM_NOTIFY:
+		nm := lParam.NMHDR()
+		// the toolbar asks us for the tooltips of its buttons; see toolbar_windows.go
+		if s.toolbar != nil && nm.hwndFrom == s.toolbar.hwnd {
+			if nm.code == uint32(negConst(_TBN_GETINFOTIPW)) {
+				s.toolbarTooltip(lParam.NMTBGETINFOTIP())
+			}
+			return 0
+		}
+		s.childrenLock.Lock()
+		ss := s.children[_HMENU(nm.idFrom)]
+		s.childrenLock.Unlock()
+		// buttons send NM_CUSTOMDRAW so we can draw their badges; see badge_windows.go
+		if ss != nil && ss.ctype == c_button && nm.code == uint32(negConst(_NM_CUSTOMDRAW)) {
+			return ss.badgeCustomDraw(lParam.NMCUSTOMDRAW())
+		}
+		// and so do ColorButtons, so we can draw their color; see colorbutton_windows.go
+		if ss != nil && ss.ctype == c_colorbutton && nm.code == uint32(negConst(_NM_CUSTOMDRAW)) {
+			return ss.colorButtonCustomDraw(lParam.NMCUSTOMDRAW())
+		}
+		// and tabs tell us the user picked another page; see tab_windows.go
+		if ss != nil && ss.ctype == c_tab && nm.code == uint32(negConst(_TCN_SELCHANGE)) {
+			ss.tabChanged()
+			return 0
+		}
+		// and tree views tell us the selection changed; see treeview_windows.go
+		if ss != nil && ss.ctype == c_treeview && nm.code == uint32(negConst(_TVN_SELCHANGED)) {
+			ss.signal()
+			return 0
+		}
+		// and date and time pickers tell us they changed; see datetimepicker_windows.go
+		if ss != nil && ss.ctype == c_datetimepicker && nm.code == uint32(negConst(_DTN_DATETIMECHANGE)) {
+			ss.dateTimeChanged()
+			return 0
+		}
+		return defWindowProc(hwnd, uMsg, wParam, lParam)
+	case _WM_HSCROLL, _WM_VSCROLL:
+		// trackbars tell us they were moved with these, with their window handle in lParam; see slider_windows.go
+		if lParam != 0 {
+			id := _HMENU(getWindowLongPtr(_HWND(lParam), negConst(_GWLP_ID)))
+			s.childrenLock.Lock()
+			ss := s.children[id]
+			s.childrenLock.Unlock()
+			if ss != nil && ss.ctype == c_slider {
+				ss.sliderChanged()
+				return 0
+			}
+		}
+		return defWindowProc(hwnd, uMsg, wParam, lParam)
 	case _WM_ACTIVATE:
 		s.handleFocus(wParam)
+		if uint32(wParam.LOWORD()) != _WA_INACTIVE {
+			s.windowActivated()
+		}
 		return 0
 	case _WM_GETMINMAXINFO:
 		mm := lParam.MINMAXINFO()
@@ -148,6 +261,8 @@ func stdWndProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM) _LRESUL
 		_ = mm
 		return 0
 	case _WM_SIZE:
+		s.toolbarAutosize()
+		s.statusBarAutosize()
 		if s.allocate != nil {
 			var r _RECT
 
@@ -158,11 +273,20 @@ func stdWndProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM) _LRESUL
 				panic("GetClientRect failed: " + err.Error())
 			}
 			// top-left corner of a client rect is always (0,0) so no need for left/top
-			s.resizeWindow(int(r.right), int(r.bottom))
+			s.resizeWindow(int(r.right), int(r.bottom)-s.toolbarHeight()-s.statusBarHeight())
 			// TODO use the Defer movement functions here?
 			// TODO redraw window and all children here?
 		}
+		if s.banner != nil {
+			s.banner.fit()
+		}
+		s.fitBusy()
 		return 0
+	case _WM_SETTINGCHANGE, _WM_SYSCOLORCHANGE:
+		// see highcontrast_windows.go and animations_windows.go
+		s.highContrastSettingChange(uMsg, wParam, lParam)
+		animationsUpdate()
+		return defWindowProc(hwnd, uMsg, wParam, lParam)
 	case _WM_CLOSE:
 		s.signal()
 		return 0
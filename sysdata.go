@@ -2,6 +2,12 @@
 
 package ui
 
+import (
+	"image"
+	"sync"
+	"time"
+)
+
 const eventbufsiz = 100 // suggested by skelterjohn
 
 // newEvent returns a new channel suitable for listening for events.
@@ -13,15 +19,69 @@ func newEvent() chan struct{} {
 type cSysData struct {
 	ctype     int
 	event     chan struct{}
+	activated chan struct{} // for Listbox.Activated; see signalActivated()
+	nearEnd   chan struct{} // for Listbox.NearEnd; see signalNearEnd()
+	nearEndLen int          // the number of items the last time nearEnd was signaled; only touched on the UI thread
 	allocate    func(x int, y int, width int, height int, d *sysSizeData) []*allocation
 	spaced	bool
-	alternate bool        // editable for Combobox, multi-select for listbox, password for lineedit
+	alternate bool        // editable for Combobox, multi-select for listbox, password for lineedit, vertical for Slider, first of its group for the buttons of RadioButtons, with the time of day for DateTimePicker, with alpha for ColorButton
 	handler   AreaHandler // for Areas
+	control   Control     // the Control that owns this sysData, for Error; nil for Windows
+	buddy     *sysData    // for Labels; see Label.SetBuddy(); only touched on the UI thread once the Label is created
+	windowLock sync.Mutex // for Window sysDatas: guards labels, settings, stoppers, and forms, which a Tab adds to when it makes a page after the Window is created (see Tab.makePage())
+	labels    []*Label    // for Window sysDatas: Labels whose buddies have to be connected once everything is made; see Window.Create()
+	settings  []*setting  // for Window sysDatas: the settings of the controls bound with BindSetting(), saved when the Window is closed and by Window.Destroy()
+	stoppers  []func()    // for Window sysDatas: run by Window.Destroy() before it destroys anything, to stop the goroutines of controls that would otherwise keep using them (see LevelMeter)
+	frozen    bool        // for Window.Freeze(); only touched on the UI thread
+	alloc     allocation  // for sysData.singleAllocation()
+	allocs    [1]*allocation
+	prefwidth int         // for sysData.preferredSize()
+	prefheight int
+	prefsizeok bool
+	layoutWidth  int // for Window sysDatas: the size last given to resizeWindow(); see relayoutPart()
+	layoutHeight int
+	widthChars int    // for LineEdit.SetWidthInChars(); see sysData.setWidthHint()
+	widthText  string // for Label.SetWidthFromText(); see sysData.setWidthHint()
+	selectable bool   // for Label.SetSelectable(); on Windows this has to be known before the control is made
+	textDirection TextDirection // for SetTextDirection() on LineEdits, Labels, and LogViews; set before the control is made
+	badge      string // for Button.SetBadge(); only touched on the UI thread
+	bannerResult chan int // for Window sysDatas: the channel of the banner being shown, if any; see Window.ShowBanner() and sysData.bannerDone(); only touched on the UI thread
+	repaintPending int32 // for Area.RepaintAll(); use sync/atomic
+	gridNav    *gridNav // for LineEdits in a Grid with keyboard navigation; see Grid.SetKeyboardNavigation(); set before the LineEdit is made
+	gotFocus   chan struct{}           // for LineEdit.GotFocus and LineEdit.LostFocus; see sysData.lineeditFocus()
+	lostFocus  chan struct{}
+	validate   func(text string) error // for LineEdit.SetValidator(); set before the LineEdit is made
+	keepFocus  bool
+	history    *history // for LineEdit.SetHistory(); set before the LineEdit is made
+	palette    chan paletteKey // for the LineEdit of ShowCommandPalette(); see sysData.paletteKey(); set before the LineEdit is made
+	form       *Form    // for LineEdits in a Form; see sysData.lineeditChanged(); set before the LineEdit is made
+	setting    *setting // for controls bound with BindSetting(); see cSysData.settingChanged(); set before the control is made
+	forms      []*Form  // for Window sysDatas: the Forms of its LineEdits, whose Buttons are enabled or disabled once everything is made; see Window.Create()
+	window     *sysData // for controls: the Window they are in; see sysData.controlMade()
+	made       bool     // whether the control exists on the system side yet; the rest are for ControlGroup (see controlgroup.go) and Tab, and are only touched on the UI thread
+	disabled   bool
+	hidden     bool
+	destroyed  bool // by DestroyControl() for controls (which also clears made), and by Window.Destroy() for Windows
+	offPage    bool // on a page of a Tab that isn't shown, or in an overlay of Window.SetInputBlocked() that isn't shown
+	inOverlay  bool // in an overlay of Window.SetInputBlocked(), so not disabled along with the rest of the Window
+	inputBlocked bool    // for Window sysDatas: see Window.SetInputBlocked()
+	overlay      Control // for Window sysDatas: the overlay being shown by Window.SetInputBlocked(), if any
+	overlayAllocate bool // for Window sysDatas: whether allocate has been wrapped by allocateWithOverlay()
+	invalid    bool // for the Button of a Form whose LineEdits aren't all valid; see Form.gate()
+	sliderPos  int  // for Sliders: the position last set or signaled; see sysData.sliderChanged(); only touched on the UI thread
+	radios     *radioGroup // for the buttons of RadioButtons: their group; see radiobuttons.go
+	pickerTime time.Time   // for DateTimePickers: the time last set or signaled; see sysData.dateTimeChanged(); only touched on the UI thread
+	areaCaret  image.Rectangle // for Areas with an AreaCaretHandler: the caret last given to the system; see sysData.areaCaretUpdate(); only touched on the UI thread
+	menuBar    *Menu           // for Window sysDatas: the menu bar, if any; see Window.SetMenuBar(); only touched on the UI thread
+	toolbar    *Toolbar        // for Window sysDatas: the toolbar, if any; see Window.SetToolbar(); only touched on the UI thread
+	statusBar  *StatusBar      // for Window sysDatas: the status bar, if any; see Window.SetStatusBar(); only touched on the UI thread
+	contextMenu *Menu          // for control sysDatas: the context menu, if any; see SetContextMenu(); only touched on the UI thread
 }
 
 // this interface is used to make sure all sysDatas are synced
 var _xSysData interface {
 	sysDataSizingFunctions
+	preferredSize(*sysSizeData) (int, int)
 	make(window *sysData) error
 	firstShow() error
 	show()
@@ -31,10 +91,13 @@ var _xSysData interface {
 	isChecked() bool
 	text() string
 	append(string)
+	appendMany([]string)
+	editMany([]listEdit, []string)
 	insertBefore(string, int)
 	selectedIndex() int
 	selectedIndices() []int
 	selectedTexts() []string
+	selectIndices([]int)
 	setWindowSize(int, int) error
 	setProgress(int)
 	len() int
@@ -42,15 +105,108 @@ var _xSysData interface {
 	repaintAll()
 	center()
 	setChecked(bool)
+	freeze()
+	thaw()
+	destroy()
+	destroyControl()
+	setBuddy(*sysData)
+	setEllipsize(Ellipsize)
+	setSelectable(bool)
+	showBanner(string, BannerKind, []string, chan int)
+	hideBanner()
+	setBusy(bool)
+	setInputBlocked(bool)
+	raiseControls([]*sysData)
+	setBadge(string)
+	comboboxAppendSpecial(string, comboboxKind)
+	copyText(string)
 } = &sysData{} // this line will error if there's an inconsistency
 
 // signal sends the event signal. This raise is done asynchronously to avoid deadlocking the UI task.
 // Thanks skelterjohn for this techinque: if we can't queue any more events, drop them
 func (s *cSysData) signal() {
-	if s.event != nil {
+	if settings := s.boundSettings(); len(settings) != 0 { // a Window being closed; see BindSetting()
+		saveSettingsAndSignal(settings, s.event)
+		return
+	}
+	s.settingChanged() // for Listbox.SelectionChanged, and Checkboxes and Comboboxes on Mac OS X (see sysData.make())
+	sendEvent(s.event)
+}
+
+// settingChanged is called by the platform code on the UI thread when the value of a control bound with BindSetting() changes, so the setting can be saved; the platform code only watches for these changes in controls that are bound
+func (s *cSysData) settingChanged() {
+	if s.setting != nil {
+		s.setting.changed()
+	}
+}
+
+// the rest of these are for Window sysDatas; the Controls call the add functions from make()
+
+func (s *cSysData) addLabel(l *Label) {
+	s.windowLock.Lock()
+	defer s.windowLock.Unlock()
+
+	s.labels = append(s.labels, l)
+}
+
+func (s *cSysData) addSetting(st *setting) {
+	s.windowLock.Lock()
+	defer s.windowLock.Unlock()
+
+	s.settings = append(s.settings, st)
+}
+
+func (s *cSysData) addStopper(stop func()) {
+	s.windowLock.Lock()
+	defer s.windowLock.Unlock()
+
+	s.stoppers = append(s.stoppers, stop)
+}
+
+func (s *cSysData) boundSettings() []*setting {
+	s.windowLock.Lock()
+	defer s.windowLock.Unlock()
+
+	return s.settings
+}
+
+// for DestroyControl(); takes the settings bound to any of controls out of the Window and returns them
+func (s *cSysData) removeSettings(controls map[Control]bool) (removed []*setting) {
+	s.windowLock.Lock()
+	defer s.windowLock.Unlock()
+
+	var kept []*setting
+	for _, st := range s.settings {
+		if controls[st.control] {
+			removed = append(removed, st)
+			continue
+		}
+		kept = append(kept, st)
+	}
+	s.settings = kept
+	return removed
+}
+
+// signalActivated is the same, but for the second event of controls that have one (Listbox.Activated).
+func (s *cSysData) signalActivated() {
+	sendEvent(s.activated)
+}
+
+// signalNearEnd is called by the platform code on the UI thread when the user scrolls a Listbox; near is whether less than a screenful of items is left below the visible ones and n is the number of items.
+// So a program that loads more items on Listbox.NearEnd isn't asked for them over and over while it's loading them, the event is only sent once for each number of items.
+func (s *cSysData) signalNearEnd(near bool, n int) {
+	if !near || n == s.nearEndLen {
+		return
+	}
+	s.nearEndLen = n
+	sendEvent(s.nearEnd)
+}
+
+func sendEvent(event chan struct{}) {
+	if event != nil {
 		go func() {
 			select {
-			case s.event <- struct{}{}:
+			case event <- struct{}{}:
 			default:
 			}
 		}()
@@ -67,6 +223,15 @@ const (
 	c_listbox
 	c_progressbar
 	c_area
+	c_logview
+	c_tab
+	c_treeview
+	c_slider
+	c_radiobutton
+	c_groupbox
+	c_datetimepicker
+	c_colorbutton
+	c_fontbutton
 	nctypes
 )
 
@@ -5,6 +5,7 @@ package ui
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 // #include "objc_darwin.h"
@@ -14,7 +15,15 @@ type sysData struct {
 	cSysData
 
 	id           C.id
-	trackingArea C.id // for Area
+	trackingArea C.id       // for Area
+	key          C.id       // in sysdatas
+	controls     []*sysData // for a window: the controls in it; for sysData.destroy() and destroyControl(); only changed on the UI thread
+	banner       C.id       // for a window: the banner being shown, if any; see Window.ShowBanner()
+	busy         C.id       // for a window: the view covering it while it's busy, if any; see Window.SetBusy()
+	blockCover   C.id       // for a window: the view covering it while its input is blocked, if any; see Window.SetInputBlocked()
+	badgeView    C.id       // for a button: the view drawing its badge, if any; see Button.SetBadge()
+	radioView    C.id       // for the first button of RadioButtons: the view its group's buttons are in; see radiobuttons_darwin.go
+	buttonFont   Font       // for a FontButton; the button doesn't hold it for us
 }
 
 type classData struct {
@@ -25,10 +34,12 @@ type classData struct {
 	settext      func(what C.id, text C.id)
 	text         func(what C.id, alternate bool) C.id
 	append       func(id C.id, what string, alternate bool)
+	appendMany   func(id C.id, what []string, alternate bool) // optional; if nil, append is used for each item
 	insertBefore func(id C.id, what string, before int, alternate bool)
 	selIndex     func(id C.id) int
 	selIndices   func(id C.id) []int
 	selTexts     func(id C.id) []string
+	setSel       func(id C.id, indices []int, alternate bool)
 	delete       func(id C.id, index int)
 	len          func(id C.id) int
 }
@@ -94,6 +105,9 @@ var classTypes = [nctypes]*classData{
 		make: func(parentWindow C.id, alternate bool, s *sysData) C.id {
 			checkbox := C.makeCheckbox()
 			applyStandardControlFont(checkbox)
+			if s.setting != nil { // see BindSetting()
+				C.buttonSetTargetAction(checkbox, appDelegate)
+			}
 			addControl(parentWindow, checkbox)
 			return checkbox
 		},
@@ -110,6 +124,9 @@ var classTypes = [nctypes]*classData{
 		make: func(parentWindow C.id, alternate bool, s *sysData) C.id {
 			combobox := C.makeCombobox(toBOOL(alternate))
 			applyStandardControlFont(combobox)
+			if s.setting != nil { // see BindSetting()
+				C.comboboxWatchChanges(combobox, appDelegate, toBOOL(alternate))
+			}
 			addControl(parentWindow, combobox)
 			return combobox
 		},
@@ -128,6 +145,13 @@ var classTypes = [nctypes]*classData{
 		selIndex: func(id C.id) int {
 			return int(C.comboboxSelectedIndex(id))
 		},
+		setSel: func(id C.id, indices []int, alternate bool) {
+			index := -1
+			if len(indices) != 0 {
+				index = indices[0]
+			}
+			C.comboboxSelect(id, toBOOL(alternate), C.intptr_t(index))
+		},
 		delete: func(id C.id, index int) {
 			C.comboboxDelete(id, C.intptr_t(index))
 		},
@@ -139,6 +163,9 @@ var classTypes = [nctypes]*classData{
 		make: func(parentWindow C.id, alternate bool, s *sysData) C.id {
 			lineedit := C.makeLineEdit(toBOOL(alternate))
 			applyStandardControlFont(lineedit)
+			if s.gridNav != nil || s.history != nil || s.palette != nil || s.form != nil || s.setting != nil {
+				C.lineeditWatchKeys(lineedit, appDelegate)
+			}
 			addControl(parentWindow, lineedit)
 			return lineedit
 		},
@@ -172,9 +199,11 @@ var classTypes = [nctypes]*classData{
 		show:         controlShow,
 		hide:         controlHide,
 		append:       listboxAppend,
+		appendMany:   listboxAppendMany,
 		insertBefore: listboxInsertBefore,
 		selIndices:   listboxSelectedIndices,
 		selTexts:     listboxSelectedTexts,
+		setSel:       listboxSelectIndices,
 		delete:       listboxDelete,
 		len:          listboxLen,
 	},
@@ -186,6 +215,8 @@ var classTypes = [nctypes]*classData{
 		},
 		show: controlShow,
 		hide: controlHide,
+		// NSProgressIndicator can't show text; ProgressBar keeps the text for ProgressBar.Text() itself
+		settext: func(what C.id, text C.id) {},
 	},
 	c_area: &classData{
 		make:      makeArea,
@@ -193,6 +224,73 @@ var classTypes = [nctypes]*classData{
 		show:      controlShow,
 		hide:      controlHide,
 	},
+	c_logview: &classData{
+		make: makeLogView,
+		show: controlShow,
+		hide: controlHide,
+		settext: func(what C.id, text C.id) {
+			C.logviewSetText(what, text)
+		},
+		text: func(what C.id, alternate bool) C.id {
+			return C.logviewText(what)
+		},
+		append: logviewAppend,
+	},
+	c_tab: &classData{
+		make: makeTab,
+		show: controlShow,
+		hide: controlHide,
+	},
+	c_treeview: &classData{
+		make: makeTreeView,
+		show: controlShow,
+		hide: controlHide,
+	},
+	c_slider: &classData{
+		make: makeSlider,
+		show: controlShow,
+		hide: controlHide,
+	},
+	c_radiobutton: &classData{
+		make: makeRadioButton,
+		show: controlShow,
+		hide: controlHide,
+		settext: func(what C.id, text C.id) {
+			C.buttonSetText(what, text)
+		},
+		text: func(what C.id, alternate bool) C.id {
+			return C.buttonText(what)
+		},
+	},
+	c_groupbox: &classData{
+		make: makeGroupBox,
+		show: controlShow,
+		hide: controlHide,
+		settext: func(what C.id, text C.id) {
+			C.groupBoxSetTitle(what, text)
+		},
+		text: func(what C.id, alternate bool) C.id {
+			return C.groupBoxTitle(what)
+		},
+	},
+	c_datetimepicker: &classData{
+		make: makeDateTimePicker,
+		show: controlShow,
+		hide: controlHide,
+	},
+	c_colorbutton: &classData{
+		make: makeColorButton,
+		show: controlShow,
+		hide: controlHide,
+	},
+	c_fontbutton: &classData{
+		make: makeFontButton,
+		show: controlShow,
+		hide: controlHide,
+		settext: func(what C.id, text C.id) {
+			C.buttonSetText(what, text)
+		},
+	},
 }
 
 // I need to access sysData from appDelegate, but appDelegate doesn't store any data. So, this.
@@ -207,6 +305,12 @@ func addSysData(key C.id, value *sysData) {
 	sysdatalock.Unlock()
 }
 
+func delSysData(key C.id) {
+	sysdatalock.Lock()
+	delete(sysdatas, key)
+	sysdatalock.Unlock()
+}
+
 func getSysData(key C.id) *sysData {
 	sysdatalock.Lock()
 	defer sysdatalock.Unlock()
@@ -227,18 +331,28 @@ func (s *sysData) make(window *sysData) error {
 	}
 	ret := make(chan C.id)
 	defer close(ret)
-	uitask <- func() {
-		ret <- ct.make(parentWindow, s.alternate, s)
-	}
-	s.id = <-ret
+	uitask(func() {
+		s.id = ct.make(parentWindow, s.alternate, s)
+		if s.textDirection != TextDirectionNatural {
+			s.applyTextDirection()
+		}
+		if window != nil {
+			s.controlMade(window)
+			// on the UI thread, as a Tab makes its pages while the Window is up (see Tab.makePage())
+			window.controls = append(window.controls, s)
+		}
+		ret <- s.id
+	})
+	<-ret
 	if ct.getinside != nil {
-		uitask <- func() {
+		uitask(func() {
 			ret <- ct.getinside(s.id)
-		}
-		addSysData(<-ret, s)
+		})
+		s.key = <-ret
 	} else {
-		addSysData(s.id, s)
+		s.key = s.id
 	}
+	addSysData(s.key, s)
 	return nil
 }
 
@@ -251,30 +365,46 @@ func (s *sysData) firstShow() error {
 func (s *sysData) show() {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		classTypes[s.ctype].show(s.id)
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
 func (s *sysData) hide() {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		classTypes[s.ctype].hide(s.id)
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
+// runs on the UI thread; see ControlGroup.DisableAll() and Form
+// a busy window's controls are covered instead of disabled (see busy_darwin.go), so there's nothing to do for that here
+func (s *sysData) doSetEnabled() {
+	C.controlSetEnabled(s.id, toBOOL(!s.disabled && !s.invalid))
+}
+
+// runs on the UI thread; see sysData.updateShown()
+func (s *sysData) doShowControl(shown bool) {
+	if shown {
+		classTypes[s.ctype].show(s.id)
+	} else {
+		classTypes[s.ctype].hide(s.id)
+	}
+}
+
 func (s *sysData) setText(text string) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		classTypes[s.ctype].settext(s.id, toNSString(text))
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
@@ -290,76 +420,133 @@ func (s *sysData) setRect(x int, y int, width int, height int, winheight int) er
 func (s *sysData) isChecked() bool {
 	ret := make(chan bool)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- C.isCheckboxChecked(s.id) != C.NO
-	}
+	})
 	return <-ret
 }
 
 func (s *sysData) text() string {
 	ret := make(chan string)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		str := classTypes[s.ctype].text(s.id, s.alternate)
 		ret <- fromNSString(str)
-	}
+	})
 	return <-ret
 }
 
 func (s *sysData) append(what string) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		classTypes[s.ctype].append(s.id, what, s.alternate)
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
+func (s *sysData) appendMany(what []string) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.addItems(what)
+		s.invalidatePreferredSize()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) editMany(edits []listEdit, tail []string) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		// AppKit only redraws once we return to the run loop, so this redraws once for all of these
+		ct := classTypes[s.ctype]
+		for _, e := range edits {
+			if e.insert {
+				ct.insertBefore(s.id, e.what, e.index, s.alternate)
+			} else {
+				ct.delete(s.id, e.index)
+			}
+		}
+		if len(tail) != 0 {
+			s.addItems(tail)
+		}
+		s.invalidatePreferredSize()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// runs on the UI thread; for sysData.appendMany() and sysData.editMany()
+func (s *sysData) addItems(what []string) {
+	ct := classTypes[s.ctype]
+	if ct.appendMany != nil {
+		ct.appendMany(s.id, what, s.alternate)
+		return
+	}
+	for _, w := range what {
+		ct.append(s.id, w, s.alternate)
+	}
+}
+
 func (s *sysData) insertBefore(what string, before int) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		classTypes[s.ctype].insertBefore(s.id, what, before, s.alternate)
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
 func (s *sysData) selectedIndex() int {
 	ret := make(chan int)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- classTypes[s.ctype].selIndex(s.id)
-	}
+	})
 	return <-ret
 }
 
 func (s *sysData) selectedIndices() []int {
 	ret := make(chan []int)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- classTypes[s.ctype].selIndices(s.id)
-	}
+	})
 	return <-ret
 }
 
 func (s *sysData) selectedTexts() []string {
 	ret := make(chan []string)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- classTypes[s.ctype].selTexts(s.id)
-	}
+	})
 	return <-ret
 }
 
+func (s *sysData) selectIndices(indices []int) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		classTypes[s.ctype].setSel(s.id, indices, s.alternate)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
 func (s *sysData) setWindowSize(width int, height int) error {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		C.windowSetContentSize(s.id, C.intptr_t(width), C.intptr_t(height))
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 	return nil
 }
@@ -367,68 +554,191 @@ func (s *sysData) setWindowSize(width int, height int) error {
 func (s *sysData) delete(index int) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		classTypes[s.ctype].delete(s.id, index)
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
+	<-ret
+}
+
+func (s *sysData) setBuddy(buddy *sysData) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		var oldbuddy, newbuddy C.id
+
+		if s.buddy != nil {
+			oldbuddy = s.buddy.id
+		}
+		if buddy != nil {
+			newbuddy = buddy.id
+		}
+		C.labelSetBuddy(s.id, oldbuddy, newbuddy)
+		s.buddy = buddy
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) setEllipsize(e Ellipsize) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		C.labelSetEllipsize(s.id, C.intptr_t(e))
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) setSelectable(selectable bool) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		C.labelSetSelectable(s.id, toBOOL(selectable))
+		ret <- struct{}{}
+	})
 	<-ret
 }
 
 func (s *sysData) setProgress(percent int) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		C.setProgress(s.id, C.intptr_t(percent))
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
 func (s *sysData) len() int {
 	ret := make(chan int)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- classTypes[s.ctype].len(s.id)
-	}
+	})
 	return <-ret
 }
 
 func (s *sysData) setAreaSize(width int, height int) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		C.setAreaSize(s.id, C.intptr_t(width), C.intptr_t(height))
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
+// see Area.RepaintAll(); we don't wait for this one
 func (s *sysData) repaintAll() {
+	uitask(func() {
+		atomic.StoreInt32(&s.repaintPending, 0)
+		// unlike -[NSView display], this just marks the view for redrawing the next time the window is drawn, so several of these in a row only result in one redraw
+		C.setNeedsDisplay(areaInScrollView(s.id))
+	})
+}
+
+func (s *sysData) center() {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
-		C.display(s.id)
+	uitask(func() {
+		C.center(s.id)
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
-func (s *sysData) center() {
+func (s *sysData) setChecked(checked bool) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
-		C.center(s.id)
+	uitask(func() {
+		C.setCheckboxChecked(s.id, toBOOL(checked))
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) freeze() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.frozen = true
+		C.windowFreeze(s.id)
 		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// runs on the UI thread; see sysData.relayout()
+func (s *sysData) doRelayout() {
+	if s.allocate != nil {
+		// see appDelegate_windowDidResize()
+		r := C.frame(C.windowGetContentView(s.id))
+		s.resizeWindow(int(r.width), int(r.height)-s.statusBarHeight())
 	}
+}
+
+func (s *sysData) thaw() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.frozen = false
+		s.doRelayout()
+		C.windowThaw(s.id)
+		ret <- struct{}{}
+	})
 	<-ret
 }
 
-func (s *sysData) setChecked(checked bool) {
+func (s *sysData) destroy() {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
-		C.setCheckboxChecked(s.id, toBOOL(checked))
+	uitask(func() {
+		// this releases all the child views (our controls) too
+		C.windowDestroy(s.id)
+		s.destroyed = true
 		ret <- struct{}{}
+	})
+	<-ret
+	for _, c := range s.controls {
+		delSysData(c.key)
 	}
+	delSysData(s.key)
+	s.controls = nil
+}
+
+// for DestroyControl(); the control's window stays as it is
+func (s *sysData) destroyControl() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		// a Label and its buddy refer to each other for accessibility (see labelSetBuddy()), so whichever of the two goes, the other has to forget it
+		if s.buddy != nil {
+			C.labelSetBuddy(s.id, s.buddy.id, nil)
+			s.buddy = nil
+		}
+		controls := s.window.controls
+		for i := 0; i < len(controls); i++ {
+			c := controls[i]
+			if c == s {
+				controls = append(controls[:i], controls[i+1:]...)
+				i--
+				continue
+			}
+			if c.buddy == s {
+				C.labelSetBuddy(c.id, s.id, nil)
+				c.buddy = nil
+			}
+		}
+		s.window.controls = controls
+		C.controlDestroy(s.id)
+		if s.radioView != nil { // the first button of RadioButtons is destroyed after the others; see DestroyControl()
+			C.controlDestroy(s.radioView)
+		}
+		ret <- struct{}{}
+	})
 	<-ret
+	delSysData(s.key)
 }
@@ -8,7 +8,6 @@
 #import <AppKit/NSControl.h>
 #import <AppKit/NSButton.h>
 #import <AppKit/NSTextField.h>
-#import <AppKit/NSSecureTextField.h>
 #import <AppKit/NSProgressIndicator.h>
 #import <AppKit/NSScrollView.h>
 
@@ -36,6 +35,18 @@ void addControl(id parentWindow, id control)
 	[[toNSWindow(parentWindow) contentView] addSubview:control];
 }
 
+// for the overlay of Window.SetInputBlocked(), whose controls have to be over the view covering the rest (but below the one covering a busy window, if any); adding a subview its superview already has moves it
+void raiseControl(id parentWindow, id control, id below)
+{
+	NSView *cv;
+
+	cv = [toNSWindow(parentWindow) contentView];
+	if (below != nil)
+		[cv addSubview:toNSView(control) positioned:NSWindowBelow relativeTo:toNSView(below)];
+	else
+		[cv addSubview:toNSView(control) positioned:NSWindowAbove relativeTo:nil];
+}
+
 void controlShow(id what)
 {
 	[toNSView(what) setHidden:NO];
@@ -46,6 +57,18 @@ void controlHide(id what)
 	[toNSView(what) setHidden:YES];
 }
 
+// for ControlGroup.DisableAll(); NSControls can be disabled, and so can the NSTableView of a Listbox, which is in a scroll view (see listbox_darwin.go), but the other views we use have no disabled state
+void controlSetEnabled(id what, BOOL enabled)
+{
+	id view;
+
+	view = what;
+	if ([view isKindOfClass:[NSScrollView class]])
+		view = [toNSScrollView(view) documentView];
+	if ([view isKindOfClass:[NSControl class]])
+		[toNSControl(view) setEnabled:enabled];
+}
+
 #define systemFontOfSize(s) ([NSFont systemFontOfSize:[NSFont systemFontSizeForControlSize:(s)]])
 
 void applyStandardControlFont(id what)
@@ -123,24 +146,6 @@ id makeCheckbox(void)
 	return checkbox;
 }
 
-id makeLineEdit(BOOL password)
-{
-	id c;
-
-	if (password)
-		c = [[NSSecureTextField alloc]
-			initWithFrame:dummyRect];
-	else
-		c = [[NSTextField alloc]
-			initWithFrame:dummyRect];
-	// Interface Builder does this to make the text box behave properly
-	// see makeLabel() for other side effects
-	[[toNSTextField(c) cell] setLineBreakMode:NSLineBreakByClipping];
-	// Interface Builder also sets this to allow horizontal scrolling
-	[[toNSTextField(c) cell] setScrollable:YES];
-	return c;
-}
-
 void lineeditSetText(id lineedit, id text)
 {
 	[toNSTextField(lineedit) setStringValue:text];
@@ -168,6 +173,55 @@ id makeLabel(void)
 	return label;
 }
 
+// see Label.SetEllipsize(); clipping is what makeLabel() sets up
+void labelSetEllipsize(id label, intptr_t mode)
+{
+	NSLineBreakMode modes[] = {
+		NSLineBreakByClipping,
+		NSLineBreakByTruncatingHead,
+		NSLineBreakByTruncatingMiddle,
+		NSLineBreakByTruncatingTail,
+	};
+
+	[[toNSTextField(label) cell] setLineBreakMode:modes[mode]];
+	[toNSTextField(label) setNeedsDisplay:YES];
+}
+
+void labelSetSelectable(id label, BOOL selectable)
+{
+	[toNSTextField(label) setSelectable:selectable];
+}
+
+// Mac OS X labels don't do anything when clicked, so the only thing a buddy gets us is telling VoiceOver and friends which control the label is for
+// the accessibility objects of controls are their cells; for a Listbox or Area, it's the view inside the scroll view that matters
+static id buddyElement(id buddy)
+{
+	if ([buddy isKindOfClass:[NSScrollView class]])
+		buddy = inScrollView(buddy);
+	if ([buddy isKindOfClass:[NSControl class]] && [toNSControl(buddy) cell] != nil)
+		return [toNSControl(buddy) cell];
+	return buddy;
+}
+
+void labelSetBuddy(id label, id oldbuddy, id buddy)
+{
+	id labelElement;
+
+	labelElement = [toNSTextField(label) cell];
+	if (oldbuddy != nil)
+		[buddyElement(oldbuddy) accessibilitySetOverrideValue:nil
+			forAttribute:NSAccessibilityTitleUIElementAttribute];
+	if (buddy == nil) {
+		[labelElement accessibilitySetOverrideValue:nil
+			forAttribute:NSAccessibilityServesAsTitleForUIElementsAttribute];
+		return;
+	}
+	[buddyElement(buddy) accessibilitySetOverrideValue:labelElement
+		forAttribute:NSAccessibilityTitleUIElementAttribute];
+	[labelElement accessibilitySetOverrideValue:[NSArray arrayWithObject:buddyElement(buddy)]
+		forAttribute:NSAccessibilityServesAsTitleForUIElementsAttribute];
+}
+
 id makeProgressBar(void)
 {
 	NSProgressIndicator *pbar;
@@ -238,3 +292,36 @@ void setCheckboxChecked(id checkbox, BOOL check)
 	}
 	[toNSButton(checkbox) setState:NSOffState];
 }
+
+void windowFreeze(id w)
+{
+	// this keeps the window from putting anything we draw on screen until we say so
+	[toNSWindow(w) disableFlushWindow];
+}
+
+void windowThaw(id w)
+{
+	NSWindow *win;
+
+	win = toNSWindow(w);
+	[win enableFlushWindow];
+	[win display];			// redraw everything
+}
+
+void windowDestroy(id w)
+{
+	NSWindow *win;
+
+	win = toNSWindow(w);
+	// we don't want any more delegate messages, least of all for a window that's gone
+	[win setDelegate:nil];
+	// windows not loaded from a nib are released when closed by default, and we never change that; -[NSWindow close] doesn't call -[windowShouldClose:] either
+	[win close];
+}
+
+// for DestroyControl(); the views of our controls are never released after they're added to their superview (see addControl()), so we still own them here
+void controlDestroy(id what)
+{
+	[toNSView(what) removeFromSuperview];
+	[toNSView(what) release];
+}
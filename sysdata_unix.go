@@ -5,6 +5,7 @@
 package ui
 
 import (
+	"sync/atomic"
 	"time"
 )
 
@@ -19,8 +20,14 @@ type sysData struct {
 	pulse        chan bool    // for sysData.progressPulse()
 	clickCounter clickCounter // for Areas
 	// we probably don't need to save these, but we'll do so for sysData.preferredSize() just in case
-	areawidth  int
-	areaheight int
+	areawidth      int
+	areaheight     int
+	controls       []*sysData   // for a window: the controls in it; for sysData.destroy() and setBusy(); only changed on the UI thread
+	banner         *C.GtkWidget // for a window: the banner being shown, if any; see Window.ShowBanner()
+	busy           *C.GtkWidget // for a window: the spinner shown while the window is busy, if any; see Window.SetBusy()
+	badgeConnected bool         // for a button: whether our draw handler is connected; see badge_unix.go
+	dtpSetting     bool         // for a DateTimePicker: set while we change it ourselves; see datetimepicker_unix.go
+	contextMenuConnected bool   // for a control: whether our context menu handlers are connected; see contextmenu_unix.go
 }
 
 type classData struct {
@@ -33,12 +40,19 @@ type classData struct {
 	selected func(widget *C.GtkWidget) int
 	selMulti func(widget *C.GtkWidget) []int
 	smtexts  func(widget *C.GtkWidget) []string
+	setSel   func(widget *C.GtkWidget, indices []int)
 	delete   func(widget *C.GtkWidget, index int)
 	len      func(widget *C.GtkWidget) int
 	// ...
 	signals   callbackMap
 	child     func(widget *C.GtkWidget) *C.GtkWidget
 	childsigs callbackMap
+	// for Listbox: the selection of a GtkTreeView is a separate object, and it's what tells us the selection changed
+	selection func(child *C.GtkWidget) *C.GtkWidget
+	selsigs   callbackMap
+	// for Listbox: the adjustment of the vertical scrollbar tells us when it's scrolled; it isn't a GtkWidget either
+	vadjustment func(widget *C.GtkWidget) *C.GtkWidget
+	vadjsigs    callbackMap
 }
 
 var classTypes = [nctypes]*classData{
@@ -49,6 +63,7 @@ var classTypes = [nctypes]*classData{
 		signals: callbackMap{
 			"delete-event":    window_delete_event_callback,
 			"configure-event": window_configure_event_callback,
+			"focus-in-event":  window_focus_in_event_callback,
 		},
 	},
 	c_button: &classData{
@@ -71,6 +86,7 @@ var classTypes = [nctypes]*classData{
 		append:   gtk_combo_box_text_append_text,
 		insert:   gtk_combo_box_text_insert_text,
 		selected: gtk_combo_box_get_active,
+		setSel:   gtkComboBoxSelect,
 		delete:   gtk_combo_box_text_remove,
 		len:      gtkComboBoxLen,
 	},
@@ -79,6 +95,10 @@ var classTypes = [nctypes]*classData{
 		makeAlt: gtkPasswordEntryNew,
 		setText: gtk_entry_set_text,
 		text:    gtk_entry_get_text,
+		signals: callbackMap{
+			"focus-in-event":  lineedit_focus_in_event_callback,
+			"focus-out-event": lineedit_focus_out_event_callback,
+		},
 	},
 	c_label: &classData{
 		make:    gtk_label_new,
@@ -94,11 +114,26 @@ var classTypes = [nctypes]*classData{
 		insert:   gListboxInsert,
 		selMulti: gListboxSelectedMulti,
 		smtexts:  gListboxSelMultiTexts,
+		setSel:   gListboxSelectIndices,
 		delete:   gListboxDelete,
 		len:      gListboxLen,
+		child:    gListboxTreeView,
+		childsigs: callbackMap{
+			"row-activated":   listbox_row_activated_callback,
+			"key-press-event": listbox_key_press_event_callback,
+		},
+		selection: gListboxSelection,
+		selsigs: callbackMap{
+			"changed": listbox_selection_changed_callback,
+		},
+		vadjustment: gListboxVAdjustment,
+		vadjsigs: callbackMap{
+			"value-changed": listbox_scrolled_callback,
+		},
 	},
 	c_progressbar: &classData{
-		make: gtk_progress_bar_new,
+		make:    gtk_progress_bar_new,
+		setText: gtk_progress_bar_set_text,
 	},
 	c_area: &classData{
 		make:  gtkAreaNew,
@@ -114,33 +149,101 @@ var classTypes = [nctypes]*classData{
 			"key-release-event":    area_key_release_event_callback,
 		},
 	},
+	c_logview: &classData{
+		make:    gLogViewNew,
+		setText: gLogViewSetText,
+		text:    gLogViewText,
+		append:  gLogViewAppend,
+		child:   gLogViewTextView,
+	},
+	c_tab: &classData{
+		make: gtkTabNew,
+		signals: callbackMap{
+			"switch-page": tab_switch_page_callback,
+		},
+	},
+	c_treeview: &classData{
+		make: gTreeViewNew,
+		// the GtkTreeView is in a GtkScrolledWindow, as with Listbox, so these work the same way
+		child:     gListboxTreeView,
+		selection: gListboxSelection,
+		selsigs: callbackMap{
+			"changed": listbox_selection_changed_callback,
+		},
+	},
+	c_slider: &classData{
+		make:    gtkHorizontalSliderNew,
+		makeAlt: gtkVerticalSliderNew,
+		signals: callbackMap{
+			"value-changed": slider_value_changed_callback,
+		},
+	},
+	c_radiobutton: &classData{
+		make:    gtkRadioButtonNew,
+		makeAlt: gtkRadioButtonNew,
+		setText: gtk_button_set_label,
+		text:    gtk_button_get_label,
+		signals: callbackMap{
+			"toggled": radiobutton_toggled_callback,
+		},
+	},
+	c_groupbox: &classData{
+		make:    gtkGroupBoxNew,
+		setText: gtkGroupBoxSetText,
+		text:    gtkGroupBoxText,
+	},
+	c_datetimepicker: &classData{
+		make:    gtkDatePickerNew,
+		makeAlt: gtkDateTimePickerNew,
+		// the GtkCalendar is in a GtkBox, with the time of day below it if any; see datetimepicker_unix.go
+		child: gDateTimePickerCalendar,
+		childsigs: callbackMap{
+			"day-selected":  datetimepicker_changed_callback,
+			"month-changed": datetimepicker_changed_callback,
+		},
+	},
+	c_colorbutton: &classData{
+		make:    gtkColorButtonNew,
+		makeAlt: gtkColorButtonNewWithAlpha,
+		signals: callbackMap{
+			"color-set": button_clicked_callback, // GtkColorButton is a GtkButton, and "color-set" looks the same as "clicked"
+		},
+	},
+	c_fontbutton: &classData{
+		make: gtkFontButtonNew,
+		signals: callbackMap{
+			"font-set": button_clicked_callback, // likewise for GtkFontButton
+		},
+	},
 }
 
 func (s *sysData) make(window *sysData) error {
 	ct := classTypes[s.ctype]
 	ret := make(chan *C.GtkWidget)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		if s.alternate {
 			ret <- ct.makeAlt()
 			return
 		}
 		ret <- ct.make()
-	}
+	})
 	s.widget = <-ret
 	if window == nil {
-		uitask <- func() {
+		uitask(func() {
 			fixed := gtkNewWindowLayout()
-			gtk_container_add(s.widget, fixed)
+			gtk_container_add(s.widget, gtkNewWindowBox(fixed))
 			for signame, sigfunc := range ct.signals {
 				g_signal_connect(s.widget, signame, sigfunc, s)
 			}
 			ret <- fixed
-		}
+		})
 		s.container = <-ret
 	} else {
 		s.container = window.container
-		uitask <- func() {
+		uitask(func() {
+			// on the UI thread, as setBusy() reads it there, and a Tab makes its pages while the Window is up (see Tab.makePage())
+			window.controls = append(window.controls, s)
 			gtkAddWidgetToLayout(s.container, s.widget)
 			for signame, sigfunc := range ct.signals {
 				g_signal_connect(s.widget, signame, sigfunc, s)
@@ -150,9 +253,46 @@ func (s *sysData) make(window *sysData) error {
 				for signame, sigfunc := range ct.childsigs {
 					g_signal_connect(child, signame, sigfunc, s)
 				}
+				if ct.selection != nil {
+					sel := ct.selection(child)
+					for signame, sigfunc := range ct.selsigs {
+						g_signal_connect(sel, signame, sigfunc, s)
+					}
+				}
+			}
+			if ct.vadjustment != nil {
+				adj := ct.vadjustment(s.widget)
+				for signame, sigfunc := range ct.vadjsigs {
+					g_signal_connect(adj, signame, sigfunc, s)
+				}
+			}
+			if s.history != nil { // see history_unix.go
+				g_signal_connect(s.widget, "key-press-event", history_key_press_event_callback, s)
+			}
+			if s.gridNav != nil { // see gridnav_unix.go
+				g_signal_connect(s.widget, "key-press-event", gridnav_key_press_event_callback, s)
 			}
+			if s.palette != nil { // see palette_unix.go
+				g_signal_connect(s.widget, "key-press-event", palette_key_press_event_callback, s)
+			}
+			if s.palette != nil || s.form != nil || (s.setting != nil && s.ctype == c_lineedit) { // see lineedit_unix.go
+				g_signal_connect(s.widget, "changed", lineedit_changed_callback, s)
+			}
+			if s.setting != nil { // see BindSetting()
+				s.settingConnect()
+			}
+			if s.textDirection != TextDirectionNatural {
+				s.applyTextDirection()
+			}
+			if s.ctype == c_radiobutton && !s.alternate { // see radiobuttons_unix.go
+				s.radioJoin()
+			}
+			if s.ctype == c_datetimepicker && s.alternate { // see datetimepicker_unix.go
+				s.dateTimePickerConnect()
+			}
+			s.controlMade(window)
 			ret <- nil
-		}
+		})
 		<-ret
 	}
 	return nil
@@ -172,32 +312,45 @@ func (s *sysData) firstShow() error {
 func (s *sysData) show() {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		gtk_widget_show(s.widget)
 		s.resetposition()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
 func (s *sysData) hide() {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		gtk_widget_hide(s.widget)
 		s.resetposition()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
+// runs on the UI thread; see ControlGroup.DisableAll() and Form
+func (s *sysData) doSetEnabled() {
+	// the controls of a busy or blocked window stay insensitive until it's no longer busy or blocked, except those of the overlay of a blocked window (unless it's busy too); see setBusy() and setInputBlocked()
+	blocked := s.window.busy != nil || (s.window.inputBlocked && !s.inOverlay)
+	C.gtk_widget_set_sensitive(s.widget, togbool(!s.disabled && !s.invalid && !blocked))
+}
+
+// runs on the UI thread; see sysData.updateShown() and the comment in tab_unix.go
+func (s *sysData) doShowControl(shown bool) {
+	C.gtk_widget_set_child_visible(s.widget, togbool(shown))
+}
+
 func (s *sysData) setText(text string) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		classTypes[s.ctype].setText(s.widget, text)
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
@@ -210,77 +363,125 @@ func (s *sysData) setRect(x int, y int, width int, height int, winheight int) er
 func (s *sysData) isChecked() bool {
 	ret := make(chan bool)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- gtk_toggle_button_get_active(s.widget)
-	}
+	})
 	return <-ret
 }
 
 func (s *sysData) text() string {
 	ret := make(chan string)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- classTypes[s.ctype].text(s.widget)
-	}
+	})
 	return <-ret
 }
 
 func (s *sysData) append(what string) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		classTypes[s.ctype].append(s.widget, what)
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
+	<-ret
+}
+
+func (s *sysData) appendMany(what []string) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		// doing them all in one go is what makes this faster; GTK+ only redraws once we return to the main loop anyway
+		for _, w := range what {
+			classTypes[s.ctype].append(s.widget, w)
+		}
+		s.invalidatePreferredSize()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) editMany(edits []listEdit, tail []string) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		// as with appendMany(), GTK+ redraws once for all of these
+		ct := classTypes[s.ctype]
+		for _, e := range edits {
+			if e.insert {
+				ct.insert(s.widget, e.index, e.what)
+			} else {
+				ct.delete(s.widget, e.index)
+			}
+		}
+		for _, w := range tail {
+			ct.append(s.widget, w)
+		}
+		s.invalidatePreferredSize()
+		ret <- struct{}{}
+	})
 	<-ret
 }
 
 func (s *sysData) insertBefore(what string, before int) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		classTypes[s.ctype].insert(s.widget, before, what)
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
 func (s *sysData) selectedIndex() int {
 	ret := make(chan int)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- classTypes[s.ctype].selected(s.widget)
-	}
+	})
 	return <-ret
 }
 
 func (s *sysData) selectedIndices() []int {
 	ret := make(chan []int)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- classTypes[s.ctype].selMulti(s.widget)
-	}
+	})
 	return <-ret
 }
 
 func (s *sysData) selectedTexts() []string {
 	ret := make(chan []string)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- classTypes[s.ctype].smtexts(s.widget)
-	}
+	})
 	return <-ret
 }
 
+func (s *sysData) selectIndices(indices []int) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		classTypes[s.ctype].setSel(s.widget, indices)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
 func (s *sysData) setWindowSize(width int, height int) error {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		// does not take window geometry into account (and cannot, since the window manager won't give that info away)
 		// thanks to TingPing in irc.gimp.net/#gtk+
 		gtk_window_resize(s.widget, width, height)
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 	return nil
 }
@@ -288,10 +489,11 @@ func (s *sysData) setWindowSize(width int, height int) error {
 func (s *sysData) delete(index int) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		classTypes[s.ctype].delete(s.widget, index)
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
@@ -300,10 +502,10 @@ func (s *sysData) progressPulse() {
 	pulse := func() {
 		ret := make(chan struct{})
 		defer close(ret)
-		uitask <- func() {
+		uitask(func() {
 			gtk_progress_bar_pulse(s.widget)
 			ret <- struct{}{}
-		}
+		})
 		<-ret
 	}
 
@@ -315,7 +517,10 @@ func (s *sysData) progressPulse() {
 
 	for {
 		select {
-		case start := <-s.pulse:
+		case start, ok := <-s.pulse:
+			if !ok { // closed by sysData.destroy() or destroyControl(); the ticker has already been stopped
+				return
+			}
 			if start {
 				ticker = time.NewTicker(pulseRate)
 				tickchan = ticker.C
@@ -334,6 +539,46 @@ func (s *sysData) progressPulse() {
 	}
 }
 
+func (s *sysData) setBuddy(buddy *sysData) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.buddy = buddy
+		var w *C.GtkWidget
+
+		if buddy != nil && buddy.widget != nil {
+			w = buddy.widget
+			// the scrolled window around a Listbox or Area doesn't take focus; what's inside it does
+			if ct := classTypes[buddy.ctype]; ct.child != nil {
+				w = ct.child(w)
+			}
+		}
+		gtk_label_set_mnemonic_widget(s.widget, w)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) setEllipsize(e Ellipsize) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		gtk_label_set_ellipsize(s.widget, e)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) setSelectable(selectable bool) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		gtk_label_set_selectable(s.widget, selectable)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
 func (s *sysData) setProgress(percent int) {
 	if s.pulse == nil {
 		s.pulse = make(chan bool)
@@ -347,51 +592,51 @@ func (s *sysData) setProgress(percent int) {
 	<-s.pulse // wait for sysData.progressPulse() to register that
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		gtk_progress_bar_set_fraction(s.widget, percent)
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
 func (s *sysData) len() int {
 	ret := make(chan int)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- classTypes[s.ctype].len(s.widget)
-	}
+	})
 	return <-ret
 }
 
 func (s *sysData) setAreaSize(width int, height int) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		c := gtkAreaGetControl(s.widget)
 		gtk_widget_set_size_request(c, width, height)
 		s.areawidth = width // for sysData.preferredSize()
 		s.areaheight = height
 		C.gtk_widget_queue_draw(c)
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
+// see Area.RepaintAll(); we don't wait for this one
 func (s *sysData) repaintAll() {
-	ret := make(chan struct{})
-	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
+		atomic.StoreInt32(&s.repaintPending, 0)
+		// GTK+ already combines queued draws and only draws once per frame
 		c := gtkAreaGetControl(s.widget)
 		C.gtk_widget_queue_draw(c)
-		ret <- struct{}{}
-	}
-	<-ret
+	})
 }
 
 func (s *sysData) center() {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		if C.gtk_widget_get_visible(s.widget) == C.FALSE {
 			// hint to the WM to make it centered when it is shown again
 			// thanks to Jasper in irc.gimp.net/#gtk+
@@ -407,16 +652,97 @@ func (s *sysData) center() {
 				(C.gdk_screen_height() / 2) - (width / 2))
 		}
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
 func (s *sysData) setChecked(checked bool) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		gtk_toggle_button_set_active(s.widget, checked)
 		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) freeze() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.frozen = true
+		// there is no GdkWindow to freeze until the window has been realized; realizing a window does not show it
+		C.gtk_widget_realize(s.widget)
+		C.gdk_window_freeze_updates(C.gtk_widget_get_window(s.widget))
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// runs on the UI thread; see sysData.relayout()
+func (s *sysData) doRelayout() {
+	if s.container != nil && s.allocate != nil {
+		width, height := gtk_window_get_size(s.widget)
+		s.resizeWindow(width, height)
+	}
+}
+
+func (s *sysData) thaw() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.frozen = false
+		s.doRelayout()
+		// as in our_window_configure_event_callback(), the gtk_widget_set_size_request() calls above queue the redraws for us
+		C.gdk_window_thaw_updates(C.gtk_widget_get_window(s.widget))
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) destroy() {
+	// stop any progressbar pulsing goroutines first so they don't try to pulse a destroyed progressbar
+	for _, c := range s.controls {
+		if c.pulse != nil {
+			c.pulse <- false
+			<-c.pulse
+			close(c.pulse)
+		}
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		// this destroys all the child widgets (our controls) too
+		C.gtk_widget_destroy(s.widget)
+		s.destroyed = true
+		ret <- struct{}{}
+	})
+	<-ret
+	s.controls = nil
+}
+
+// for DestroyControl(); the control's window stays as it is
+func (s *sysData) destroyControl() {
+	// as in destroy()
+	if s.pulse != nil {
+		s.pulse <- false
+		<-s.pulse
+		close(s.pulse)
 	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		// this also takes it out of the GtkLayout; a Label whose buddy this was forgets it on its own
+		C.gtk_widget_destroy(s.widget)
+		s.widget = nil
+		controls := s.window.controls
+		for i, c := range controls {
+			if c == s {
+				s.window.controls = append(controls[:i], controls[i+1:]...)
+				break
+			}
+		}
+		ret <- struct{}{}
+	})
 	<-ret
 }
@@ -4,7 +4,9 @@ package ui
 
 import (
 	"fmt"
+	"image/color"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"unsafe"
 )
@@ -15,6 +17,7 @@ type sysData struct {
 	hwnd         _HWND
 	children     map[_HMENU]*sysData
 	nextChildID  _HMENU
+	childID      _HMENU // for a control: its ID among its window's children; see sysData.destroyControl()
 	childrenLock sync.Mutex
 	isMarquee    bool // for sysData.setProgress()
 	// unlike with GTK+ and Mac OS X, we're responsible for sizing Area properly ourselves
@@ -22,6 +25,12 @@ type sysData struct {
 	areaheight   int
 	clickCounter clickCounter
 	lastfocus    _HWND
+	banner       *banner        // for Window.ShowBanner()
+	busy         *busyIndicator // for Window.SetBusy()
+	buttonColor  color.NRGBA    // for a ColorButton; the button doesn't hold it for us
+	buttonFont   Font           // for a FontButton; likewise
+	contextMenuHooked bool      // see contextmenu_windows.go
+	comboboxSel  int            // for Comboboxes: the last item selected that wasn't a separator or header; see comboboxSelChanged()
 }
 
 type classData struct {
@@ -36,13 +45,20 @@ type classData struct {
 	deleteMsg        uintptr
 	selectedIndexMsg uintptr
 	selectedIndexErr uintptr
+	setIndexMsg      uintptr
 	addSpaceErr      uintptr
 	lenMsg           uintptr
 }
 
-const controlstyle = _WS_CHILD | _WS_VISIBLE | _WS_TABSTOP
+// WS_CLIPSIBLINGS keeps controls from drawing over a banner; see banner_windows.go
+const controlstyle = _WS_CHILD | _WS_VISIBLE | _WS_TABSTOP | _WS_CLIPSIBLINGS
 const controlxstyle = 0
 
+// STATIC controls can't be selected from, but read-only EDIT controls can, and they're drawn with the same colors as a STATIC control (they even send WM_CTLCOLORSTATIC); so that's what a selectable Label is
+// leaving out WS_EX_CLIENTEDGE leaves out the border
+// see Label.SetSelectable()
+const selectableLabelStyle = _ES_READONLY | _ES_AUTOHSCROLL | controlstyle
+
 var classTypes = [nctypes]*classData{
 	c_window: &classData{
 		name:          stdWndClass,
@@ -72,6 +88,7 @@ var classTypes = [nctypes]*classData{
 		deleteMsg:        _CB_DELETESTRING,
 		selectedIndexMsg: _CB_GETCURSEL,
 		selectedIndexErr: negConst(_CB_ERR),
+		setIndexMsg:      _CB_SETCURSEL,
 		addSpaceErr:      negConst(_CB_ERRSPACE),
 		lenMsg:           _CB_GETCOUNT,
 	},
@@ -85,13 +102,13 @@ var classTypes = [nctypes]*classData{
 	},
 	c_label: &classData{
 		name: toUTF16("STATIC"),
-		// SS_NOPREFIX avoids accelerator translation; SS_LEFTNOWORDWRAP clips text past the end
+		// SS_NOPREFIX avoids accelerator translation; SS_LEFTNOWORDWRAP clips text past the end; SS_NOTIFY gets us STN_CLICKED for Label.SetBuddy()
 		// controls are vertically aligned to the top by default (thanks Xeek in irc.freenode.net/#winapi)
 		// also note that tab stops are remove dfor labels
-		style:  (_SS_NOPREFIX | _SS_LEFTNOWORDWRAP | controlstyle) &^ _WS_TABSTOP,
+		style:  (_SS_NOPREFIX | _SS_LEFTNOWORDWRAP | _SS_NOTIFY | controlstyle) &^ _WS_TABSTOP,
 		xstyle: 0 | controlxstyle,
 		// MAKE SURE THIS IS THE SAME
-		altStyle:		(_SS_NOPREFIX | _SS_LEFTNOWORDWRAP | controlstyle) &^ _WS_TABSTOP,
+		altStyle:		(_SS_NOPREFIX | _SS_LEFTNOWORDWRAP | _SS_NOTIFY | controlstyle) &^ _WS_TABSTOP,
 	},
 	c_listbox: &classData{
 		name: toUTF16("LISTBOX"),
@@ -106,6 +123,7 @@ var classTypes = [nctypes]*classData{
 		deleteMsg:        _LB_DELETESTRING,
 		selectedIndexMsg: _LB_GETCURSEL,
 		selectedIndexErr: negConst(_LB_ERR),
+		setIndexMsg:      _LB_SETCURSEL,
 		addSpaceErr:      negConst(_LB_ERRSPACE),
 		lenMsg:           _LB_GETCOUNT,
 	},
@@ -123,6 +141,62 @@ var classTypes = [nctypes]*classData{
 		storeSysData:  true,
 		doNotLoadFont: true,
 	},
+	c_logview: &classData{
+		name:   toUTF16("EDIT"),
+		style:  logviewStyle,
+		xstyle: _WS_EX_CLIENTEDGE | controlxstyle,
+	},
+	c_tab: &classData{
+		name:   toUTF16(x_WC_TABCONTROL),
+		style:  controlstyle,
+		xstyle: 0 | controlxstyle,
+	},
+	c_treeview: &classData{
+		name: toUTF16(x_WC_TREEVIEW),
+		// TVS_LINESATROOT gives the top-level items expanders too; TVS_SHOWSELALWAYS keeps the selection showing without the focus, as with a Listbox
+		style:  _TVS_HASBUTTONS | _TVS_HASLINES | _TVS_LINESATROOT | _TVS_SHOWSELALWAYS | controlstyle,
+		xstyle: _WS_EX_CLIENTEDGE | controlxstyle,
+	},
+	c_slider: &classData{
+		name: toUTF16(x_TRACKBAR_CLASS),
+		// trackbars can't snap to anything but whole positions, and their tick marks are sized by the same, so leave them out
+		style:    _TBS_HORZ | _TBS_NOTICKS | controlstyle,
+		xstyle:   0 | controlxstyle,
+		altStyle: _TBS_VERT | _TBS_NOTICKS | controlstyle,
+	},
+	c_radiobutton: &classData{
+		name: toUTF16("BUTTON"),
+		// a group of radio buttons goes from one with WS_GROUP to the next, and is one tab stop; see radiobuttons_windows.go
+		style:    _BS_AUTORADIOBUTTON | (controlstyle &^ _WS_TABSTOP),
+		xstyle:   0 | controlxstyle,
+		altStyle: _BS_AUTORADIOBUTTON | _WS_GROUP | controlstyle,
+	},
+	c_groupbox: &classData{
+		name:   toUTF16("BUTTON"),
+		style:  _BS_GROUPBOX | (controlstyle &^ _WS_TABSTOP),
+		xstyle: 0 | controlxstyle,
+	},
+	c_datetimepicker: &classData{
+		name: toUTF16(x_DATETIMEPICK_CLASS),
+		// the time of day is added with DTM_SETFORMAT; see datetimepicker_windows.go
+		style:    _DTS_SHORTDATEFORMAT | controlstyle,
+		xstyle:   0 | controlxstyle,
+		altStyle: _DTS_SHORTDATEFORMAT | controlstyle,
+	},
+	c_colorbutton: &classData{
+		name: toUTF16("BUTTON"),
+		// the color is drawn on top of the button; see colorbutton_windows.go
+		style:    _BS_PUSHBUTTON | controlstyle,
+		xstyle:   0 | controlxstyle,
+		altStyle: _BS_PUSHBUTTON | controlstyle,
+	},
+	c_fontbutton: &classData{
+		name: toUTF16("BUTTON"),
+		// the text is the font; see fontbutton_windows.go
+		style:    _BS_PUSHBUTTON | controlstyle,
+		xstyle:   0 | controlxstyle,
+		altStyle: _BS_PUSHBUTTON | controlstyle,
+	},
 }
 
 func (s *sysData) addChild(child *sysData) _HMENU {
@@ -150,7 +224,7 @@ var (
 func (s *sysData) make(window *sysData) (err error) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ct := classTypes[s.ctype]
 		cid := _HMENU(0)
 		pwin := uintptr(_NULL)
@@ -158,17 +232,27 @@ func (s *sysData) make(window *sysData) (err error) {
 			cid = window.addChild(s)
 			pwin = uintptr(window.hwnd)
 		}
+		name := ct.name
 		style := uintptr(ct.style)
 		if s.alternate {
 			style = uintptr(ct.altStyle)
 		}
+		if s.ctype == c_label && s.selectable {
+			name = classTypes[c_lineedit].name
+			style = uintptr(selectableLabelStyle)
+		}
+		xstyle := uintptr(ct.xstyle)
+		// mirroring the control makes it read right to left, line up on the right, and (for LogViews) put the vertical scrollbar on the left, which is what Microsoft recommends for right-to-left controls over the older WS_EX_RTLREADING and WS_EX_RIGHT (which mirroring would turn back around)
+		if s.textDirection == TextDirectionRightToLeft {
+			xstyle |= _WS_EX_LAYOUTRTL
+		}
 		lpParam := uintptr(_NULL)
 		if ct.storeSysData {
 			lpParam = uintptr(unsafe.Pointer(s))
 		}
 		r1, _, err := _createWindowEx.Call(
-			uintptr(ct.xstyle),
-			utf16ToArg(ct.name),
+			xstyle,
+			utf16ToArg(name),
 			blankString, // we set the window text later
 			style,
 			negConst(_CW_USEDEFAULT),
@@ -183,22 +267,48 @@ func (s *sysData) make(window *sysData) (err error) {
 			if window != nil {
 				window.delChild(cid)
 			}
-			panic(fmt.Errorf("error actually creating window/control: %v", err))
+			panic(s.newError("actually creating window/control", "CreateWindowEx()", err))
 		}
+		s.childID = cid
 		if !ct.storeSysData { // regular control; store s.hwnd ourselves
 			s.hwnd = _HWND(r1)
 		} else if s.hwnd != _HWND(r1) { // we store sysData in storeSysData(); sanity check
 			panic(fmt.Errorf("hwnd mismatch creating window/control: storeSysData() stored 0x%X but CreateWindowEx() returned 0x%X", s.hwnd, r1))
 		}
 		if !ct.doNotLoadFont {
+			font := controlFont
+			if s.ctype == c_logview {
+				font = monospaceFont
+			}
 			_sendMessage.Call(
 				uintptr(s.hwnd),
 				uintptr(_WM_SETFONT),
-				uintptr(_WPARAM(controlFont)),
+				uintptr(_WPARAM(font)),
 				uintptr(_LPARAM(_TRUE)))
 		}
+		if s.ctype == c_logview {
+			s.logviewInit()
+		}
+		if s.ctype == c_listbox {
+			s.listboxInit()
+		}
+		if s.ctype == c_lineedit && s.gridNav != nil {
+			s.gridNavInit()
+		}
+		if s.ctype == c_lineedit && s.history != nil { // after gridNavInit(); see history_windows.go
+			s.historyInit()
+		}
+		if s.ctype == c_lineedit && s.palette != nil {
+			s.paletteInit()
+		}
+		if s.ctype == c_datetimepicker && s.alternate {
+			s.dateTimePickerInit()
+		}
+		if window != nil {
+			s.controlMade(window)
+		}
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 	return nil
 }
@@ -213,16 +323,16 @@ var (
 func (s *sysData) firstShow() error {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		_showWindow.Call(
 			uintptr(s.hwnd),
 			uintptr(nCmdShow))
 		r1, _, err := _updateWindow.Call(uintptr(s.hwnd))
 		if r1 == 0 { // failure
-			panic(fmt.Errorf("error updating window for the first time: %v", err))
+			panic(s.newError("updating window for the first time", "UpdateWindow()", err))
 		}
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 	return nil
 }
@@ -230,40 +340,68 @@ func (s *sysData) firstShow() error {
 func (s *sysData) show() {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		_showWindow.Call(
 			uintptr(s.hwnd),
 			uintptr(_SW_SHOW))
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
 func (s *sysData) hide() {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		_showWindow.Call(
 			uintptr(s.hwnd),
 			uintptr(_SW_HIDE))
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
+// runs on the UI thread; see ControlGroup.DisableAll() and Form
+func (s *sysData) doSetEnabled() {
+	enable := uintptr(_TRUE)
+	// the controls of a busy or blocked Window stay disabled until it's no longer busy or blocked, except those of the overlay of a blocked Window (unless it's busy too); see setBusy() and setInputBlocked()
+	if s.disabled || s.invalid || s.window.busy != nil || (s.window.inputBlocked && !s.inOverlay) {
+		enable = uintptr(_FALSE)
+	}
+	// the return value is the previous state, not an error
+	_enableWindow.Call(
+		uintptr(s.hwnd),
+		enable)
+}
+
+// runs on the UI thread; see sysData.updateShown()
+func (s *sysData) doShowControl(shown bool) {
+	cmd := uintptr(_SW_HIDE)
+	if shown {
+		cmd = uintptr(_SW_SHOW)
+	}
+	_showWindow.Call(
+		uintptr(s.hwnd),
+		cmd)
+}
+
 func (s *sysData) setText(text string) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
+		if s.ctype == c_logview {
+			text = toCRLF(text)
+		}
 		ptext := toUTF16(text)
 		r1, _, err := _setWindowText.Call(
 			uintptr(s.hwnd),
 			utf16ToArg(ptext))
 		if r1 == 0 { // failure
-			panic(fmt.Errorf("error setting window/control text: %v", err))
+			panic(s.newError("setting window/control text", "SetWindowText()", err))
 		}
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
@@ -277,7 +415,7 @@ func (s *sysData) setRect(x int, y int, width int, height int, winheight int) er
 		uintptr(height),
 		uintptr(_TRUE))
 	if r1 == 0 { // failure
-		return fmt.Errorf("error setting window/control rect: %v", err)
+		return s.newError("setting window/control rect", "MoveWindow()", err)
 	}
 	return nil
 }
@@ -285,80 +423,166 @@ func (s *sysData) setRect(x int, y int, width int, height int, winheight int) er
 func (s *sysData) isChecked() bool {
 	ret := make(chan bool)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		r1, _, _ := _sendMessage.Call(
 			uintptr(s.hwnd),
 			uintptr(_BM_GETCHECK),
 			uintptr(0),
 			uintptr(0))
 		ret <- r1 == _BST_CHECKED
-	}
+	})
 	return <-ret
 }
 
 func (s *sysData) text() (str string) {
 	ret := make(chan string)
 	defer close(ret)
-	uitask <- func() {
-		var tc []uint16
+	uitask(func() {
+		ret <- s.doText()
+	})
+	return <-ret
+}
 
-		r1, _, _ := _sendMessage.Call(
-			uintptr(s.hwnd),
-			uintptr(_WM_GETTEXTLENGTH),
-			uintptr(0),
-			uintptr(0))
-		length := r1 + 1 // terminating null
-		tc = make([]uint16, length)
-		_sendMessage.Call(
-			uintptr(s.hwnd),
-			uintptr(_WM_GETTEXT),
-			uintptr(_WPARAM(length)),
-			uintptr(_LPARAM(unsafe.Pointer(&tc[0]))))
-		ret <- syscall.UTF16ToString(tc)
+// runs on the UI thread
+func (s *sysData) doText() string {
+	var tc []uint16
+
+	r1, _, _ := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_WM_GETTEXTLENGTH),
+		uintptr(0),
+		uintptr(0))
+	length := r1 + 1 // terminating null
+	tc = make([]uint16, length)
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_WM_GETTEXT),
+		uintptr(_WPARAM(length)),
+		uintptr(_LPARAM(unsafe.Pointer(&tc[0]))))
+	if s.ctype == c_logview {
+		return fromCRLF(syscall.UTF16ToString(tc))
 	}
-	return <-ret
+	return syscall.UTF16ToString(tc)
 }
 
 func (s *sysData) append(what string) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
-		pwhat := toUTF16(what)
-		r1, _, err := _sendMessage.Call(
-			uintptr(s.hwnd),
-			uintptr(classTypes[s.ctype].appendMsg),
-			uintptr(_WPARAM(0)),
-			utf16ToLPARAM(pwhat))
-		if r1 == uintptr(classTypes[s.ctype].addSpaceErr) {
-			panic(fmt.Errorf("out of space adding item to combobox/listbox (last error: %v)", err))
-		} else if r1 == uintptr(classTypes[s.ctype].selectedIndexErr) {
-			panic(fmt.Errorf("failed to add item to combobox/listbox (last error: %v)", err))
+	uitask(func() {
+		if s.ctype == c_logview {
+			s.logviewAppend(what)
+			ret <- struct{}{}
+			return
 		}
+		s.addItem(what)
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
-func (s *sysData) insertBefore(what string, index int) {
+func (s *sysData) appendMany(what []string) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
-		pwhat := toUTF16(what)
-		r1, _, err := _sendMessage.Call(
-			uintptr(s.hwnd),
-			uintptr(classTypes[s.ctype].insertBeforeMsg),
-			uintptr(_WPARAM(index)),
-			utf16ToLPARAM(pwhat))
-		if r1 == uintptr(classTypes[s.ctype].addSpaceErr) {
-			panic(fmt.Errorf("out of space adding item to combobox/listbox (last error: %v)", err))
-		} else if r1 == uintptr(classTypes[s.ctype].selectedIndexErr) {
-			panic(fmt.Errorf("failed to add item to combobox/listbox (last error: %v)", err))
-		}
+	uitask(func() {
+		s.withoutRedraw(func() {
+			for _, w := range what {
+				s.addItem(w)
+			}
+		})
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) editMany(edits []listEdit, tail []string) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.withoutRedraw(func() {
+			for _, e := range edits {
+				if e.insert {
+					s.insertItem(e.what, e.index)
+				} else {
+					s.deleteItem(e.index)
+				}
+			}
+			for _, w := range tail {
+				s.addItem(w)
+			}
+		})
+		s.invalidatePreferredSize()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// runs on the UI thread; for sysData.appendMany() and sysData.editMany(), so the listbox isn't redrawn for every item that f changes
+func (s *sysData) withoutRedraw(f func()) {
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_WM_SETREDRAW),
+		uintptr(_WPARAM(_FALSE)),
+		uintptr(0))
+	f()
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_WM_SETREDRAW),
+		uintptr(_WPARAM(_TRUE)),
+		uintptr(0))
+	// turning redraw back on doesn't redraw by itself; the frame is for the scrollbar
+	r1, _, err := _redrawWindow.Call(
+		uintptr(s.hwnd),
+		uintptr(0),
+		uintptr(0),
+		uintptr(_RDW_ERASE|_RDW_FRAME|_RDW_INVALIDATE))
+	if r1 == 0 { // failure
+		panic(s.newError("redrawing listbox after changing items", "RedrawWindow()", err))
 	}
+}
+
+// runs on the UI thread; for sysData.append() and sysData.appendMany()
+func (s *sysData) addItem(what string) {
+	pwhat := toUTF16(what)
+	r1, _, err := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(classTypes[s.ctype].appendMsg),
+		uintptr(_WPARAM(0)),
+		utf16ToLPARAM(pwhat))
+	if r1 == uintptr(classTypes[s.ctype].addSpaceErr) {
+		panic(s.newError("adding item to combobox/listbox (out of space)", "SendMessage()", err))
+	} else if r1 == uintptr(classTypes[s.ctype].selectedIndexErr) {
+		panic(s.newError("adding item to combobox/listbox", "SendMessage()", err))
+	}
+}
+
+func (s *sysData) insertBefore(what string, index int) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.insertItem(what, index)
+		s.invalidatePreferredSize()
+		ret <- struct{}{}
+	})
 	<-ret
 }
 
+// runs on the UI thread; for sysData.insertBefore() and sysData.editMany()
+func (s *sysData) insertItem(what string, index int) {
+	pwhat := toUTF16(what)
+	r1, _, err := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(classTypes[s.ctype].insertBeforeMsg),
+		uintptr(_WPARAM(index)),
+		utf16ToLPARAM(pwhat))
+	if r1 == uintptr(classTypes[s.ctype].addSpaceErr) {
+		panic(s.newError("adding item to combobox/listbox (out of space)", "SendMessage()", err))
+	} else if r1 == uintptr(classTypes[s.ctype].selectedIndexErr) {
+		panic(s.newError("adding item to combobox/listbox", "SendMessage()", err))
+	}
+}
+
 // runs on uitask
 func (s *sysData) doSelectedIndex() int {
 	r1, _, _ := _sendMessage.Call(
@@ -375,9 +599,9 @@ func (s *sysData) doSelectedIndex() int {
 func (s *sysData) selectedIndex() int {
 	ret := make(chan int)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- s.doSelectedIndex()
-	}
+	})
 	return <-ret
 }
 
@@ -397,19 +621,24 @@ func (s *sysData) doSelectedIndices() []int {
 		uintptr(0),
 		uintptr(0))
 	if r1 == negConst(_LB_ERR) {
-		panic(fmt.Errorf("error: LB_ERR from LB_GETSELCOUNT in what we know is a multi-selection listbox: %v", err))
+		panic(s.newError("getting selection count of what we know is a multi-selection listbox", "SendMessage(LB_GETSELCOUNT)", err))
 	}
 	if r1 == 0 { // nothing selected
 		return nil
 	}
-	indices := make([]int, r1)
+	// LB_GETSELITEMS fills an array of C ints, which are 32 bits wide even on 64-bit Windows
+	sel := make([]int32, r1)
 	r1, _, err = _sendMessage.Call(
 		uintptr(s.hwnd),
 		uintptr(_LB_GETSELITEMS),
 		uintptr(_WPARAM(r1)),
-		uintptr(_LPARAM(unsafe.Pointer(&indices[0]))))
+		uintptr(_LPARAM(unsafe.Pointer(&sel[0]))))
 	if r1 == negConst(_LB_ERR) {
-		panic(fmt.Errorf("error: LB_ERR from LB_GETSELITEMS in what we know is a multi-selection listbox: %v", err))
+		panic(s.newError("getting selected items of what we know is a multi-selection listbox", "SendMessage(LB_GETSELITEMS)", err))
+	}
+	indices := make([]int, len(sel))
+	for i, v := range sel {
+		indices[i] = int(v)
 	}
 	return indices
 }
@@ -417,63 +646,115 @@ func (s *sysData) doSelectedIndices() []int {
 func (s *sysData) selectedIndices() []int {
 	ret := make(chan []int)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		ret <- s.doSelectedIndices()
-	}
+	})
 	return <-ret
 }
 
-func (s *sysData) selectedTexts() []string {
-	ret := make(chan []string)
+func (s *sysData) selectIndices(indices []int) {
+	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
-		indices := s.doSelectedIndices()
-		strings := make([]string, len(indices))
-		for i, v := range indices {
+	uitask(func() {
+		if s.ctype == c_combobox || !s.alternate { // combobox or single-selection list box
+			index := -1 // no selection
+			if len(indices) != 0 {
+				index = indices[0]
+			}
+			// CB_SETCURSEL and LB_SETCURSEL return an error when clearing the selection even though that isn't an error, so we can only check if we did select something
+			// CB_SETCURSEL also replaces the text of an editable combobox
 			r1, _, err := _sendMessage.Call(
 				uintptr(s.hwnd),
-				uintptr(_LB_GETTEXTLEN),
-				uintptr(_WPARAM(v)),
+				classTypes[s.ctype].setIndexMsg,
+				uintptr(_WPARAM(index)),
 				uintptr(0))
-			if r1 == negConst(_LB_ERR) {
-				panic(fmt.Errorf("error: LB_ERR from LB_GETTEXTLEN in what we know is a valid listbox index (came from LB_GETSELITEMS): %v", err))
+			if index != -1 && r1 == classTypes[s.ctype].selectedIndexErr {
+				panic(s.newError("selecting combobox/listbox item", "SendMessage()", err))
 			}
-			str := make([]uint16, r1)
-			r1, _, err = _sendMessage.Call(
+			s.comboboxSel = index
+			ret <- struct{}{}
+			return
+		}
+		// an index of -1 applies LB_SETSEL to every item
+		r1, _, err := _sendMessage.Call(
+			uintptr(s.hwnd),
+			uintptr(_LB_SETSEL),
+			uintptr(_FALSE),
+			negConst(-1))
+		if r1 == negConst(_LB_ERR) {
+			panic(s.newError("clearing listbox selection", "SendMessage(LB_SETSEL)", err))
+		}
+		for _, index := range indices {
+			r1, _, err := _sendMessage.Call(
 				uintptr(s.hwnd),
-				uintptr(_LB_GETTEXT),
-				uintptr(_WPARAM(v)),
-				uintptr(_LPARAM(unsafe.Pointer(&str[0]))))
+				uintptr(_LB_SETSEL),
+				uintptr(_TRUE),
+				uintptr(_LPARAM(index)))
 			if r1 == negConst(_LB_ERR) {
-				panic(fmt.Errorf("error: LB_ERR from LB_GETTEXT in what we know is a valid listbox index (came from LB_GETSELITEMS): %v", err))
+				panic(s.newError(fmt.Sprintf("selecting listbox item %d", index), "SendMessage(LB_SETSEL)", err))
 			}
-			strings[i] = syscall.UTF16ToString(str)
 		}
-		ret <- strings
-	}
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (s *sysData) selectedTexts() []string {
+	ret := make(chan []string)
+	defer close(ret)
+	uitask(func() {
+		ret <- s.doSelectedTexts()
+	})
 	return <-ret
 }
 
+// runs on the UI thread; also used when copying from a listbox (see listbox_windows.go)
+func (s *sysData) doSelectedTexts() []string {
+	indices := s.doSelectedIndices()
+	strings := make([]string, len(indices))
+	for i, v := range indices {
+		r1, _, err := _sendMessage.Call(
+			uintptr(s.hwnd),
+			uintptr(_LB_GETTEXTLEN),
+			uintptr(_WPARAM(v)),
+			uintptr(0))
+		if r1 == negConst(_LB_ERR) {
+			panic(s.newError("getting text length of what we know is a valid listbox index (came from LB_GETSELITEMS)", "SendMessage(LB_GETTEXTLEN)", err))
+		}
+		str := make([]uint16, r1)
+		r1, _, err = _sendMessage.Call(
+			uintptr(s.hwnd),
+			uintptr(_LB_GETTEXT),
+			uintptr(_WPARAM(v)),
+			uintptr(_LPARAM(unsafe.Pointer(&str[0]))))
+		if r1 == negConst(_LB_ERR) {
+			panic(s.newError("getting text of what we know is a valid listbox index (came from LB_GETSELITEMS)", "SendMessage(LB_GETTEXT)", err))
+		}
+		strings[i] = syscall.UTF16ToString(str)
+	}
+	return strings
+}
+
 func (s *sysData) setWindowSize(width int, height int) error {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		var rect _RECT
 
 		r1, _, err := _getClientRect.Call(
 			uintptr(s.hwnd),
 			uintptr(unsafe.Pointer(&rect)))
 		if r1 == 0 {
-			panic(fmt.Errorf("error getting upper-left of window for resize: %v", err))
+			panic(s.newError("getting upper-left of window for resize", "GetClientRect()", err))
 		}
 		// TODO AdjustWindowRect() on the result
 		// 0 because (0,0) is top-left so no winheight
 		err = s.setRect(int(rect.left), int(rect.top), width, height, 0)
 		if err != nil {
-			panic(fmt.Errorf("error actually resizing window: %v", err))
+			panic(err) // already an *Error
 		}
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 	return nil
 }
@@ -481,30 +762,36 @@ func (s *sysData) setWindowSize(width int, height int) error {
 func (s *sysData) delete(index int) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
-		r1, _, err := _sendMessage.Call(
-			uintptr(s.hwnd),
-			uintptr(classTypes[s.ctype].deleteMsg),
-			uintptr(_WPARAM(index)),
-			uintptr(0))
-		if r1 == uintptr(classTypes[s.ctype].selectedIndexErr) {
-			panic(fmt.Errorf("failed to delete item from combobox/listbox (last error: %v)", err))
-		}
+	uitask(func() {
+		s.deleteItem(index)
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
+// runs on the UI thread; for sysData.delete() and sysData.editMany()
+func (s *sysData) deleteItem(index int) {
+	r1, _, err := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(classTypes[s.ctype].deleteMsg),
+		uintptr(_WPARAM(index)),
+		uintptr(0))
+	if r1 == uintptr(classTypes[s.ctype].selectedIndexErr) {
+		panic(s.newError("deleting item from combobox/listbox", "SendMessage()", err))
+	}
+}
+
 func (s *sysData) setIndeterminate() {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		r1, _, err := _setWindowLongPtr.Call(
 			uintptr(s.hwnd),
 			negConst(_GWL_STYLE),
 			uintptr(classTypes[s.ctype].style | _PBS_MARQUEE))
 		if r1 == 0 {
-			panic(fmt.Errorf("error setting progress bar style to enter indeterminate mode: %v", err))
+			panic(s.newError("setting progress bar style to enter indeterminate mode", "SetWindowLongPtr()", err))
 		}
 		_sendMessage.Call(
 			uintptr(s.hwnd),
@@ -513,10 +800,68 @@ func (s *sysData) setIndeterminate() {
 			uintptr(0))
 		s.isMarquee = true
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
+// there's no native buddy relation for STATIC controls (that's for up-down controls), so we just remember the buddy; stdWndProc() focuses it when the label is clicked
+func (s *sysData) setBuddy(buddy *sysData) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.buddy = buddy
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// there's no style for an ellipsis at the start, so EllipsizeStart uses SS_PATHELLIPSIS too; see Label.SetEllipsize()
+var labelEllipsisStyles = map[Ellipsize]uint32{
+	EllipsizeNone:   0,
+	EllipsizeStart:  _SS_PATHELLIPSIS,
+	EllipsizeMiddle: _SS_PATHELLIPSIS,
+	EllipsizeEnd:    _SS_ENDELLIPSIS,
+}
+
+func (s *sysData) setEllipsize(e Ellipsize) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		if s.selectable {
+			// edit controls can't ellipsize, and these styles mean something else to them
+			ret <- struct{}{}
+			return
+		}
+		style := classTypes[s.ctype].style
+		if s.alternate {
+			style = classTypes[s.ctype].altStyle
+		}
+		r1, _, err := _setWindowLongPtr.Call(
+			uintptr(s.hwnd),
+			negConst(_GWL_STYLE),
+			uintptr(style | labelEllipsisStyles[e]))
+		if r1 == 0 {
+			panic(s.newError("setting label style to change ellipsizing", "SetWindowLongPtr()", err))
+		}
+		// static controls read their style when they paint, so we just have to get them to paint
+		r1, _, err = _redrawWindow.Call(
+			uintptr(s.hwnd),
+			uintptr(0),
+			uintptr(0),
+			uintptr(_RDW_ERASE | _RDW_INVALIDATE))
+		if r1 == 0 {
+			panic(s.newError("redrawing label after changing ellipsizing", "RedrawWindow()", err))
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// the class of the control was picked in sysData.make(), and we can't change it now
+func (s *sysData) setSelectable(selectable bool) {
+	// do nothing
+}
+
 func (s *sysData) setProgress(percent int) {
 	if percent == -1 {
 		s.setIndeterminate()
@@ -524,7 +869,7 @@ func (s *sysData) setProgress(percent int) {
 	}
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		if s.isMarquee {
 			// turn off marquee before switching back
 			_sendMessage.Call(
@@ -537,7 +882,7 @@ func (s *sysData) setProgress(percent int) {
 				negConst(_GWL_STYLE),
 				uintptr(classTypes[s.ctype].style))
 			if r1 == 0 {
-				panic(fmt.Errorf("error setting progress bar style to leave indeterminate mode (percent %d): %v", percent, err))
+				panic(s.newError(fmt.Sprintf("setting progress bar style to leave indeterminate mode (percent %d)", percent), "SetWindowLongPtr()", err))
 			}
 			s.isMarquee = false
 		}
@@ -561,53 +906,52 @@ func (s *sysData) setProgress(percent int) {
 			send(_PBM_SETRANGE32, 0, 100)
 		}
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
 func (s *sysData) len() int {
 	ret := make(chan int)
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		r1, _, err := _sendMessage.Call(
 			uintptr(s.hwnd),
 			uintptr(classTypes[s.ctype].lenMsg),
 			uintptr(_WPARAM(0)),
 			uintptr(_LPARAM(0)))
 		if r1 == uintptr(classTypes[s.ctype].selectedIndexErr) {
-			panic(fmt.Errorf("unexpected error return from sysData.len(); GetLastError() says %v", err))
+			panic(s.newError("getting combobox/listbox length in sysData.len()", "SendMessage()", err))
 		}
 		ret <- int(r1)
-	}
+	})
 	return <-ret
 }
 
 func (s *sysData) setAreaSize(width int, height int) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		_sendMessage.Call(
 			uintptr(s.hwnd),
 			uintptr(msgSetAreaSize),
 			uintptr(width), // WPARAM is UINT_PTR on Windows XP and newer at least, so we're good with this
 			uintptr(height))
+		s.invalidatePreferredSize()
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
+// see Area.RepaintAll(); we don't wait for this one
 func (s *sysData) repaintAll() {
-	ret := make(chan struct{})
-	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
+		atomic.StoreInt32(&s.repaintPending, 0)
 		_sendMessage.Call(
 			uintptr(s.hwnd),
 			uintptr(msgRepaintAll),
 			uintptr(0),
 			uintptr(0))
-		ret <- struct{}{}
-	}
-	<-ret
+	})
 }
 
 var (
@@ -617,14 +961,14 @@ var (
 func (s *sysData) center() {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		var ws _RECT
 
 		r1, _, err := _getWindowRect.Call(
 			uintptr(s.hwnd),
 			uintptr(unsafe.Pointer(&ws)))
 		if r1 == 0 {
-			panic(fmt.Errorf("error getting window rect for sysData.center(): %v", err))
+			panic(s.newError("getting window rect for sysData.center()", "GetWindowRect()", err))
 		}
 		// TODO should this be using the monitor functions instead? http://blogs.msdn.com/b/oldnewthing/archive/2005/05/05/414910.aspx
 		// error returns from GetSystemMetrics() is meaningless because the return value, 0, is still valid
@@ -634,16 +978,19 @@ func (s *sysData) center() {
 		wh := ws.bottom - ws.top
 		wx := (int32(dw) / 2) - (ww / 2)
 		wy := (int32(dh) / 2) - (wh / 2)
-		s.setRect(int(wx), int(wy), int(ww), int(wh), 0)
+		err = s.setRect(int(wx), int(wy), int(ww), int(wh), 0)
+		if err != nil { // not fatal; the window just stays where it was
+			reportError(err.(*Error))
+		}
 		ret <- struct{}{}
-	}
+	})
 	<-ret
 }
 
 func (s *sysData) setChecked(checked bool) {
 	ret := make(chan struct{})
 	defer close(ret)
-	uitask <- func() {
+	uitask(func() {
 		c := uintptr(_BST_CHECKED)
 		if !checked {
 			c = uintptr(_BST_UNCHECKED)
@@ -654,6 +1001,110 @@ func (s *sysData) setChecked(checked bool) {
 			c,
 			uintptr(0))
 		ret <- struct{}{}
+	})
+	<-ret
+}
+
+var (
+	_redrawWindow = user32.NewProc("RedrawWindow")
+)
+
+func (s *sysData) freeze() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.frozen = true
+		// this stops the window and all its children from redrawing
+		_sendMessage.Call(
+			uintptr(s.hwnd),
+			uintptr(_WM_SETREDRAW),
+			uintptr(_FALSE),
+			uintptr(0))
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// runs on the UI thread; see sysData.relayout()
+func (s *sysData) doRelayout() {
+	var r _RECT
+
+	if s.allocate == nil {
+		return
+	}
+	r1, _, err := _getClientRect.Call(
+		uintptr(s.hwnd),
+		uintptr(unsafe.Pointer(&r)))
+	if r1 == 0 {
+		panic(s.newError("getting window client rect for sysData.doRelayout()", "GetClientRect()", err))
 	}
+	s.resizeWindow(int(r.right), int(r.bottom)-s.toolbarHeight()-s.statusBarHeight())
+}
+
+func (s *sysData) thaw() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.frozen = false
+		_sendMessage.Call(
+			uintptr(s.hwnd),
+			uintptr(_WM_SETREDRAW),
+			uintptr(_TRUE),
+			uintptr(0))
+		s.doRelayout()
+		// turning WM_SETREDRAW back on does not redraw anything on its own, so we have to ask
+		r1, _, err := _redrawWindow.Call(
+			uintptr(s.hwnd),
+			uintptr(0),
+			uintptr(0),
+			uintptr(_RDW_ERASE | _RDW_FRAME | _RDW_INVALIDATE | _RDW_ALLCHILDREN))
+		if r1 == 0 {
+			panic(s.newError("redrawing window for sysData.thaw()", "RedrawWindow()", err))
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+var (
+	_destroyWindow = user32.NewProc("DestroyWindow")
+)
+
+func (s *sysData) destroy() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		// this destroys all the child windows (our controls) too
+		r1, _, err := _destroyWindow.Call(uintptr(s.hwnd))
+		if r1 == 0 { // failure
+			panic(s.newError("destroying window", "DestroyWindow()", err))
+		}
+		s.childrenLock.Lock()
+		s.children = nil
+		s.childrenLock.Unlock()
+		s.toolbarDestroy()
+		s.destroyed = true
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// for DestroyControl(); the control's window stays as it is
+func (s *sysData) destroyControl() {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		r1, _, err := _destroyWindow.Call(uintptr(s.hwnd))
+		if r1 == 0 { // failure
+			panic(s.newError("destroying control", "DestroyWindow()", err))
+		}
+		s.window.delChild(s.childID)
+		if s.window.lastfocus == s.hwnd {
+			s.window.lastfocus = _HWND(_NULL)
+		}
+		// a Label whose buddy this was checks for this before focusing it (see stdwndclass_windows.go)
+		s.hwnd = _HWND(_NULL)
+		ret <- struct{}{}
+	})
 	<-ret
 }
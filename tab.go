@@ -0,0 +1,341 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A Tab is a Control that shows one of several pages of Controls at a time, with a row of labeled tabs the user clicks to choose which page is shown.
+// Each page is a single Control, usually a Stack or Grid, which is laid out in the area under the tabs the way a Window lays out its Control, inset from the edges of that area by the padding between controls.
+// The controls of a page are only created when the page is first shown, so a Tab with many pages doesn't make the Window slow to open; until then the Controls on it work as they do before their Window is created.
+// After that, the controls on pages that aren't shown are hidden, not destroyed, so they keep whatever was typed or selected in them when the user comes back to their page.
+// The preferred size of a Tab is big enough for the biggest page shown so far, so the Tab only changes size when a bigger page is shown for the first time.
+// A Label on a page should not have a buddy on another page (see Label.SetBuddy), as the buddy may not exist yet when the Label is created.
+// The first page added is shown to begin with; see SelectPage.
+type Tab struct {
+	// SelectionChanged gets a message when the user selects another page; it does not get one for SelectPage.
+	// You cannot change it once the Window containing the Tab has been created.
+	SelectionChanged chan struct{}
+
+	lock         sync.Mutex
+	created      bool
+	sysData      *sysData
+	names        []string
+	pages        []Control
+	initSelected int
+	gone         bool // set when the Tab or its Window is destroyed, so a page being made after that isn't; see makePage() and stop()
+
+	// once the Tab is created, these belong to the UI thread
+	window      *sysData // to lay out again when the page changes; nil until the Tab is ready, so the system selecting pages while we add them is ignored
+	selected    int
+	shown       bool // false if the Tab is on a page of another Tab that isn't shown
+	allocations []*allocation
+	laidOut     layoutRect // see switchPage()
+	made        []bool     // which pages have had their controls made; see makePage()
+	making      []bool
+}
+
+// NewTab creates a new Tab with no pages.
+func NewTab() *Tab {
+	return &Tab{
+		sysData:          mksysdata(c_tab),
+		SelectionChanged: newEvent(),
+	}
+}
+
+// AddPage adds a page with the given tab label and Control after the last page of the Tab.
+// As with Layout(), a nil Control is an empty page.
+// This cannot be called once the Window containing the Tab has been created.
+func (t *Tab) AddPage(name string, control Control) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.created {
+		panic("call to Tab.AddPage() after Tab has been created")
+	}
+	if control == nil {
+		control = Space()
+	}
+	t.names = append(t.names, name)
+	t.pages = append(t.pages, control)
+}
+
+// SelectedPage returns the index of the page being shown, or -1 if the Tab has no pages.
+func (t *Tab) SelectedPage() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.pages) == 0 {
+		return -1
+	}
+	if t.created {
+		ret := make(chan int)
+		defer close(ret)
+		uitask(func() {
+			ret <- t.selected
+		})
+		return <-ret
+	}
+	return t.initSelected
+}
+
+// SelectPage shows the page at the given index, as if the user had clicked its tab (but without a message on SelectionChanged).
+// It panics if index is out of range.
+func (t *Tab) SelectPage(index int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if index < 0 || index >= len(t.pages) {
+		panic(fmt.Errorf("index %d out of range in Tab.SelectPage()", index))
+	}
+	if !t.created {
+		t.initSelected = index
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		// switch first, so tabSelected() knows the system selecting the page was us
+		t.switchPage(index)
+		t.sysData.tabSelect(index)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (t *Tab) make(window *sysData) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.sysData.event = t.SelectionChanged
+	t.sysData.control = t
+	err := t.sysData.make(window)
+	if err != nil {
+		return err
+	}
+	// the controls of the pages are made in the Window like any others, over the Tab; only the first page shown is made now, and the rest when they are first selected
+	t.made = make([]bool, len(t.pages))
+	t.making = make([]bool, len(t.pages))
+	if len(t.pages) != 0 {
+		err := t.pages[t.initSelected].make(window)
+		if err != nil {
+			return fmt.Errorf("error adding page %d to Tab: %v", t.initSelected, err)
+		}
+	}
+	window.addStopper(t.stop)
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		for _, name := range t.names {
+			t.sysData.tabAppend(name)
+		}
+		t.selected = t.initSelected
+		if len(t.pages) != 0 {
+			t.sysData.tabSelect(t.selected)
+			t.made[t.selected] = true
+		}
+		t.sysData.placeBelow(t.madeControls())
+		// if we're on a page of another Tab that isn't shown, that Tab hides us again once it's made
+		t.show(true)
+		t.window = window
+		ret <- struct{}{}
+	})
+	<-ret
+	t.created = true
+	return nil
+}
+
+// makePage() makes the controls of the page at index on its own goroutine, as switchPage() runs on the UI thread and making controls can't; the page is shown and the Window laid out again once they're made, if the page is still selected by then.
+func (t *Tab) makePage(index int, window *sysData) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.gone {
+		return
+	}
+	err := t.pages[index].make(window)
+	if err != nil {
+		panic(fmt.Errorf("error adding page %d to Tab: %v", index, err))
+	}
+	window.controlsMade(t.pages[index])
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		t.made[index] = true
+		t.making[index] = false
+		if t.sysData.inOverlay { // see Window.SetInputBlocked()
+			for _, c := range appendControls(nil, t.pages[index]) {
+				c.inOverlay = true
+				c.doSetEnabled()
+			}
+		}
+		// the tab control has to go below the new controls too
+		t.sysData.placeBelow(t.madeControls())
+		showControls(t.pages[index], t.shown && index == t.selected)
+		// this page may be bigger than the others, so everything may have to move
+		window.doRelayout()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// stop() keeps the pages that haven't been made from ever being made; it waits for a page being made to be done first
+func (t *Tab) stop() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.gone = true
+}
+
+// madeControls() returns the sysData of every system control on the pages made so far; this runs on the UI thread.
+func (t *Tab) madeControls() (controls []*sysData) {
+	for i, c := range t.pages {
+		if t.made[i] {
+			controls = appendControls(controls, c)
+		}
+	}
+	return controls
+}
+
+// appendControls() appends the sysData of every system control in c that has been made to controls, including those on the pages of any Tabs in c; this runs on the UI thread.
+func appendControls(controls []*sysData, c Control) []*sysData {
+	eachControl(c, func(c Control) {
+		if s := controlSysData(c); s != nil && s.made {
+			controls = append(controls, s)
+		}
+	})
+	return controls
+}
+
+// showControls() shows or hides the system controls in c, except that Tabs in c only show their selected page and controls hidden by a ControlGroup stay hidden.
+// This runs on the UI thread.
+func showControls(c Control, shown bool) {
+	switch c := c.(type) {
+	case *Stack:
+		for _, cc := range c.controls {
+			showControls(cc, shown)
+		}
+	case *Grid:
+		for _, row := range c.controls {
+			for _, cc := range row {
+				showControls(cc, shown)
+			}
+		}
+	case *Tab:
+		c.show(shown)
+	case *RadioButtons:
+		showControls(c.stack, shown)
+	case *GroupBox:
+		c.sysData.offPage = !shown
+		c.sysData.updateShown()
+		showControls(c.control, shown)
+	default:
+		if s := controlSysData(c); s != nil {
+			s.offPage = !shown
+			s.updateShown()
+		}
+	}
+}
+
+// this runs on the UI thread
+func (t *Tab) show(shown bool) {
+	t.shown = shown
+	t.sysData.offPage = !shown
+	t.sysData.updateShown()
+	for i, c := range t.pages {
+		if t.made[i] {
+			showControls(c, shown && i == t.selected)
+		}
+	}
+}
+
+// this runs on the UI thread
+func (t *Tab) switchPage(index int) {
+	t.selected = index
+	t.show(t.shown)
+	if !t.made[index] && !t.making[index] {
+		t.making[index] = true
+		go t.makePage(index, t.window)
+	}
+	// our preferred size is that of our biggest page whichever is shown, so nothing outside of us has to move
+	t.window.relayoutPart(t, &t.laidOut)
+}
+
+// tabSelected() is called by the system code on the UI thread when the page shown by a Tab changes, which it does when the user clicks a tab but also (on some systems) when pages are added and when we select one ourselves.
+func (s *sysData) tabSelected(index int) {
+	t := s.control.(*Tab)
+	if t.window == nil || index == t.selected {
+		return
+	}
+	t.switchPage(index)
+	s.signal()
+}
+
+func (t *Tab) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	checkUIThread("Tab.allocate()")
+	if t.sysData.destroyed { // along with its pages; see DestroyControl()
+		return nil
+	}
+	t.laidOut.set(x, y, width, height, d)
+	lc := beginLayoutCheck(d, "Tab", x, y, width, height)
+	// as with Stack, steal the margin so our pages don't get it too
+	xmargin := d.xmargin
+	ymargin := d.ymargin
+	d.xmargin = 0
+	d.ymargin = 0
+	x, width = layoutInset(x, width, xmargin)
+	y, height = layoutInset(y, height, ymargin)
+	// reuse the slice from the last resize; see Stack.allocate()
+	allocations := append(t.allocations[:0], t.sysData.singleAllocation(x, y, width, height, t)...)
+	if t.shown && len(t.pages) != 0 && t.made[t.selected] {
+		left, top, right, bottom := t.sysData.tabInsets()
+		px, pwidth := layoutInset(x+left, width-left-right, d.xpadding)
+		py, pheight := layoutInset(y+top, height-top-bottom, d.ypadding)
+		lc.child(d, t.selected, -1, px, py, pwidth, pheight)
+		allocations = append(allocations, t.pages[t.selected].allocate(px, py, pwidth, pheight, d)...)
+	}
+	lc.done(d, false, 0)
+	t.allocations = allocations
+	return allocations
+}
+
+// The preferred size of a Tab is the biggest preferred size of the pages made so far plus the space the Tab takes around them, or the size of the tabs themselves if that is bigger.
+func (t *Tab) preferredSize(d *sysSizeData) (width int, height int) {
+	checkUIThread("Tab.preferredSize()")
+	if t.sysData.destroyed {
+		return 0, 0
+	}
+	for i, c := range t.pages {
+		if !t.made[i] {
+			continue
+		}
+		w, h := c.preferredSize(d)
+		if width < w {
+			width = w
+		}
+		if height < h {
+			height = h
+		}
+	}
+	left, top, right, bottom := t.sysData.tabInsets()
+	width += left + right + 2*d.xpadding
+	height += top + bottom + 2*d.ypadding
+	w, h := t.sysData.preferredSize(d)
+	if width < w {
+		width = w
+	}
+	if height < h {
+		height = h
+	}
+	return width, height
+}
+
+func (t *Tab) commitResize(a *allocation, d *sysSizeData) {
+	t.sysData.commitResize(a, d)
+}
+
+func (t *Tab) getAuxResizeInfo(d *sysSizeData) {
+	t.sysData.getAuxResizeInfo(d)
+}
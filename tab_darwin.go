@@ -0,0 +1,44 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+/*
+On Mac OS X, a Tab is an NSTabView whose pages are empty views; it only draws the tabs and the border around the pages.
+The controls of the pages are in the window's content view like all the others, added after the NSTabView so they are drawn over it, and hidden and shown as the pages change (see tab.go).
+The app delegate is the NSTabView's delegate, so it's told when the user picks another tab; see appDelegate_tabSelected().
+*/
+
+func makeTab(parentWindow C.id, alternate bool, s *sysData) C.id {
+	tab := C.makeTab(appDelegate)
+	addControl(parentWindow, tab)
+	return tab
+}
+
+func (s *sysData) tabAppend(name string) {
+	C.tabAppend(s.id, toNSString(name))
+	s.invalidatePreferredSize()
+}
+
+func (s *sysData) tabSelect(index int) {
+	// this tells the delegate; see Tab.SelectPage()
+	C.tabSelect(s.id, C.intptr_t(index))
+}
+
+func (s *sysData) tabInsets() (left int, top int, right int, bottom int) {
+	r := C.tabInsets(s.id)
+	return int(r.x), int(r.y), int(r.width), int(r.height)
+}
+
+// for Tabs and GroupBoxes
+func (s *sysData) placeBelow(controls []*sysData) {
+	// nothing to do; views added to the content view after the NSTabView (or NSBox) are drawn over it
+}
+
+// NSTabView's minimum size is just the tabs and the border; the pages are added in Tab.preferredSize()
+func tabPrefSize(control C.id) (width int, height int) {
+	r := C.tabPrefSize(control)
+	return int(r.width), int(r.height)
+}
@@ -0,0 +1,79 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSTabView.h>
+#import <AppKit/NSTabViewItem.h>
+#import <AppKit/NSView.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSTabView(x) to(NSTabView, (x))
+
+extern NSRect dummyRect;
+
+id makeTab(id delegate)
+{
+	NSTabView *tv;
+
+	tv = [[NSTabView alloc]
+		initWithFrame:dummyRect];
+	[tv setDelegate:delegate];
+	return tv;
+}
+
+void tabAppend(id tab, id name)
+{
+	NSTabViewItem *item;
+
+	item = [[NSTabViewItem alloc] initWithIdentifier:nil];
+	[item setLabel:name];
+	// the pages are empty; see tab_darwin.go
+	[item setView:[[[NSView alloc] initWithFrame:dummyRect] autorelease]];
+	[toNSTabView(tab) addTabViewItem:item];
+	[item release];
+}
+
+void tabSelect(id tab, intptr_t index)
+{
+	[toNSTabView(tab) selectTabViewItemAtIndex:((NSInteger) index)];
+}
+
+intptr_t tabIndexOf(id tab, id item)
+{
+	return (intptr_t) [toNSTabView(tab) indexOfTabViewItem:item];
+}
+
+// the space between the edges of the tab view and its page area; this doesn't depend on the size of the tab view, so we can use whatever frame it has
+struct xrect tabInsets(id tab)
+{
+	NSTabView *tv;
+	NSRect bounds, content;
+	struct xrect r;
+
+	tv = toNSTabView(tab);
+	bounds = [tv bounds];
+	content = [tv contentRect];
+	r.x = (intptr_t) (content.origin.x - bounds.origin.x);		// left
+	r.width = (intptr_t) ((bounds.origin.x + bounds.size.width) - (content.origin.x + content.size.width));		// right
+	// the tabs are at the top, wherever the origin is
+	r.y = (intptr_t) ((bounds.origin.y + bounds.size.height) - (content.origin.y + content.size.height));		// top
+	r.height = (intptr_t) (content.origin.y - bounds.origin.y);		// bottom
+	if ([tv isFlipped]) {
+		intptr_t t;
+
+		t = r.y;
+		r.y = r.height;
+		r.height = t;
+	}
+	return r;
+}
+
+struct xsize tabPrefSize(id tab)
+{
+	NSSize size;
+	struct xsize s;
+
+	size = [toNSTabView(tab) minimumSize];
+	s.width = (intptr_t) size.width;
+	s.height = (intptr_t) size.height;
+	return s;
+}
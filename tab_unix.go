@@ -0,0 +1,75 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern void our_tab_switch_page_callback(GtkNotebook *, GtkWidget *, guint, gpointer);
+import "C"
+
+/*
+On GTK+, a Tab is a GtkNotebook whose pages are empty boxes; it only draws the tabs and the frame around the pages.
+The controls of the pages are in the window's GtkLayout like all the others, added after the GtkNotebook so they are drawn over it, and hidden and shown as the pages change (see tab.go).
+We hide them with gtk_widget_set_child_visible() and not gtk_widget_hide(), because showing the window shows everything in it that's merely hidden.
+*/
+
+func togtknotebook(what *C.GtkWidget) *C.GtkNotebook {
+	return (*C.GtkNotebook)(unsafe.Pointer(what))
+}
+
+func gtkTabNew() *C.GtkWidget {
+	return C.gtk_notebook_new()
+}
+
+func (s *sysData) tabAppend(name string) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	page := C.gtk_box_new(C.GTK_ORIENTATION_HORIZONTAL, 0)
+	// GtkNotebook doesn't show the tabs of hidden pages
+	C.gtk_widget_show(page)
+	C.gtk_notebook_append_page(togtknotebook(s.widget), page, C.gtk_label_new(togstr(cname)))
+	s.invalidatePreferredSize()
+}
+
+func (s *sysData) tabSelect(index int) {
+	// this emits switch-page; see Tab.SelectPage()
+	C.gtk_notebook_set_current_page(togtknotebook(s.widget), C.gint(index))
+}
+
+//export our_tab_switch_page_callback
+func our_tab_switch_page_callback(notebook *C.GtkNotebook, page *C.GtkWidget, pageNum C.guint, what C.gpointer) {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(what))
+	// GtkNotebook also sends switch-page as its pages are removed when the window is destroyed; the user can only switch pages of a GtkNotebook on screen
+	if C.gtk_widget_get_mapped(s.widget) == C.FALSE {
+		return
+	}
+	s.tabSelected(int(pageNum))
+}
+
+var tab_switch_page_callback = C.GCallback(C.our_tab_switch_page_callback)
+
+// since our pages are empty, the natural size of the GtkNotebook (see sysPreferredSize()) is the tabs and the frame around the pages
+// GtkNotebook puts its style padding between the frame and the pages, so that is the size of the frame on the three sides without tabs
+func (s *sysData) tabInsets() (left int, top int, right int, bottom int) {
+	var padding C.GtkBorder
+
+	C.gtk_style_context_get_padding(C.gtk_widget_get_style_context(s.widget), C.GTK_STATE_FLAG_NORMAL, &padding)
+	border := int(C.gtk_container_get_border_width((*C.GtkContainer)(unsafe.Pointer(s.widget))))
+	left = int(padding.left) + border
+	right = int(padding.right) + border
+	bottom = int(padding.bottom) + border
+	_, _, _, height := gtk_widget_get_preferred_size(s.widget)
+	top = height - bottom
+	return left, top, right, bottom
+}
+
+// for Tabs and GroupBoxes
+func (s *sysData) placeBelow(controls []*sysData) {
+	// nothing to do; GtkLayout draws the controls added after the GtkNotebook (or GtkFrame) over it
+}
@@ -0,0 +1,137 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+/*
+On Windows, a Tab is a tab control, which only draws the tabs and the border around the pages; the controls of the pages are children of the window like all the others, placed over the tab control and hidden and shown as the pages change (see tab.go).
+The tab control tells the window it's in that the user picked another tab with a TCN_SELCHANGE notification; see stdWndProc().
+*/
+
+type _TCITEM struct {
+	mask        uint32
+	dwState     uint32
+	dwStateMask uint32
+	pszText     *uint16
+	cchTextMax  int32
+	iImage      int32
+	lParam      _LPARAM
+}
+
+var (
+	_getWindow = user32.NewProc("GetWindow")
+)
+
+func (s *sysData) tabAppend(name string) {
+	var item _TCITEM
+
+	n, _, _ := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TCM_GETITEMCOUNT),
+		uintptr(0),
+		uintptr(0))
+	item.mask = _TCIF_TEXT
+	item.pszText = toUTF16(name)
+	r1, _, err := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TCM_INSERTITEM),
+		n,
+		uintptr(unsafe.Pointer(&item)))
+	if r1 == negConst(-1) { // failure
+		panic(s.newError("adding page to Tab", "TCM_INSERTITEM", err))
+	}
+	s.invalidatePreferredSize()
+}
+
+func (s *sysData) tabSelect(index int) {
+	// this doesn't send TCN_SELCHANGE
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TCM_SETCURSEL),
+		uintptr(index),
+		uintptr(0))
+}
+
+// called by stdWndProc() on TCN_SELCHANGE
+func (s *sysData) tabChanged() {
+	r1, _, _ := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TCM_GETCURSEL),
+		uintptr(0),
+		uintptr(0))
+	s.tabSelected(int(r1))
+}
+
+// the border around the pages (and the tabs above them) doesn't depend on the size of the tab control (as we only have one row of tabs), so we can measure it with any rect
+func (s *sysData) tabInsets() (left int, top int, right int, bottom int) {
+	const size = 1000
+
+	r := _RECT{
+		right:  size,
+		bottom: size,
+	}
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TCM_ADJUSTRECT),
+		uintptr(_FALSE), // rect of the whole control -> rect of the page area
+		uintptr(unsafe.Pointer(&r)))
+	return int(r.left), int(r.top), size - int(r.right), size - int(r.bottom)
+}
+
+// the preferred size of a tab control is the tabs and the border; the pages are added in Tab.preferredSize()
+// this is called by sysPreferredSize()
+func (s *sysData) tabPreferredSize() (width int, height int) {
+	var r _RECT
+
+	_, top, right, bottom := s.tabInsets()
+	n, _, _ := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TCM_GETITEMCOUNT),
+		uintptr(0),
+		uintptr(0))
+	if n != 0 {
+		_sendMessage.Call(
+			uintptr(s.hwnd),
+			uintptr(_TCM_GETITEMRECT),
+			n-1,
+			uintptr(unsafe.Pointer(&r)))
+		width = int(r.right) + right
+	}
+	return width, top + bottom
+}
+
+// the tab control has to be below the controls of its pages in the z-order, or it draws over them (and so does the group box of a GroupBox)
+// we put it right below the lowest of them, whichever that is; this also puts it right after them in the tab order (which also follows the z-order), which is the best we can do
+func (s *sysData) placeBelow(controls []*sysData) {
+	var last _HWND
+
+	if len(controls) == 0 {
+		return
+	}
+	pages := make(map[_HWND]bool, len(controls))
+	for _, c := range controls {
+		pages[c.hwnd] = true
+	}
+	hwnd, _, _ := _getWindow.Call(
+		uintptr(s.hwnd),
+		uintptr(_GW_HWNDFIRST))
+	for hwnd != 0 {
+		if pages[_HWND(hwnd)] {
+			last = _HWND(hwnd)
+		}
+		hwnd, _, _ = _getWindow.Call(
+			hwnd,
+			uintptr(_GW_HWNDNEXT))
+	}
+	_setWindowPos.Call(
+		uintptr(s.hwnd),
+		uintptr(last),
+		uintptr(0),
+		uintptr(0),
+		uintptr(0),
+		uintptr(0),
+		uintptr(_SWP_NOMOVE|_SWP_NOSIZE|_SWP_NOACTIVATE))
+}
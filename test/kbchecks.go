@@ -0,0 +1,24 @@
+// +build uidebug
+
+// 9 july 2014
+
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	. "github.com/andlabs/ui"
+)
+
+var kbChecks = flag.Bool("kbchecks", false, "print keyboard accessibility problems (see DebugSetKeyboardChecks()) of every window opened")
+
+func init() {
+	debugSetup = append(debugSetup, func() {
+		if *kbChecks {
+			DebugSetKeyboardChecks(func(problem string) {
+				fmt.Println("keyboard:", problem)
+			})
+		}
+	})
+}
@@ -0,0 +1,75 @@
+// +build uidebug
+
+// 5 july 2014
+package main
+
+import (
+	"fmt"
+	"flag"
+	"runtime"
+	"time"
+	. "github.com/andlabs/ui"
+)
+
+// this measures the layout code on its own; build with -tags uidebug
+// it doesn't call ui.Go() as there's no need to: nothing here touches the system
+
+var rows = flag.Int("rows", 200, "number of rows")
+var cols = flag.Int("cols", 4, "number of columns per row")
+var passes = flag.Int("passes", 1000, "number of layout passes to time")
+var useGrid = flag.Bool("grid", false, "lay out the rows in a Grid instead of nested Stacks")
+var spaced = flag.Bool("spaced", false, "lay out with margins and padding")
+var check = flag.Bool("check", false, "print layout check violations (see DebugSetLayoutChecks()) from the first pass instead of timing")
+
+func stacks() Control {
+	rs := make([]Control, *rows)
+	for i := range rs {
+		cs := make([]Control, *cols)
+		for j := range cs {
+			cs[j] = DebugFakeControl(80, 20)
+		}
+		s := NewHorizontalStack(cs...)
+		s.SetStretchy(*cols - 1)
+		rs[i] = s
+	}
+	return NewVerticalStack(rs...)
+}
+
+func grid() Control {
+	cs := make([]Control, *rows * *cols)
+	for i := range cs {
+		cs[i] = DebugFakeControl(80, 20)
+	}
+	return NewGrid(*cols, cs...)
+}
+
+func main() {
+	var before, after runtime.MemStats
+
+	flag.Parse()
+	c := stacks()
+	if *useGrid {
+		c = grid()
+	}
+	if *check {
+		DebugSetLayoutChecks(func(violation string) {
+			fmt.Println(violation)
+		})
+		DebugLayout(c, 640, 480, *spaced)
+		return
+	}
+	n := DebugLayout(c, 640, 480, *spaced)		// warm up the caches
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < *passes; i++ {
+		// vary the size like a user dragging the window edge would
+		DebugLayout(c, 640 + i % 100, 480 + i % 100, *spaced)
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	fmt.Printf("%d controls, %d passes: %v per pass, %d allocations (%d bytes) per pass\n",
+		n, *passes,
+		elapsed / time.Duration(*passes),
+		(after.Mallocs - before.Mallocs) / uint64(*passes),
+		(after.TotalAlloc - before.TotalAlloc) / uint64(*passes))
+}
@@ -11,7 +11,11 @@ import (
 	"bytes"
 	"time"
 	"strconv"
+	"strings"
+	"math/rand"
 	"sync"
+	"os"
+	"path/filepath"
 	. "github.com/andlabs/ui"
 )
 
@@ -211,9 +215,13 @@ func areaTest() {
 	}
 	a := NewArea(320, 240, areahandler)
 	timedisp := NewLabel("")
+	timedisp.SetWidthFromText("8888-88-88 88:88:88.888888888 -8888 MST")
+	timedisp.SetEllipsize(EllipsizeMiddle)
 	timechan := time.Tick(time.Second)
 	widthbox := NewLineEdit("320")
+	widthbox.SetWidthInChars(5)
 	heightbox := NewLineEdit("240")
+	heightbox.SetWidthInChars(5)
 	resize := NewButton("Resize")
 	sizeStack := NewHorizontalStack(widthbox, heightbox, resize)
 	sizeStack.SetStretchy(0)
@@ -280,9 +288,1753 @@ func areaboundsTest() {
 	<-w.Closing
 }
 
+var destroyTest = flag.Bool("destroy", false, "create and destroy windows forever instead (watch memory usage)")
+func destroyLoop() {
+	for i := 0; ; i++ {
+		w := NewWindow(fmt.Sprintf("Destroy Test %d", i), 200, 100)
+		pbar := NewProgressBar()
+		w.Open(NewVerticalStack(NewLabel("Destroying in 100ms"), pbar))
+		pbar.SetProgress(-1)		// make sure pulsing progressbars go away properly too
+		time.Sleep(100 * time.Millisecond)
+		w.Destroy()
+	}
+}
+
+var destroyControlTest = flag.Bool("destroycontrol", false, "run DestroyControl test instead (the button destroys the controls above it)")
+func destroyControlLoop() {
+	pbar := NewProgressBar()
+	tab := NewTab()
+	tab.AddPage("Shown", NewLabel("Page shown first"))
+	tab.AddPage("Never shown", NewLineEdit("never made if the Tab is destroyed before this page is shown"))
+	part := NewVerticalStack(NewLabel("Destroyed by the button below"), pbar, tab)
+	part.SetStretchy(2)
+	destroy := NewButton("Destroy")
+	s := NewVerticalStack(part, destroy)
+	s.SetStretchy(0)
+	w := NewWindow("DestroyControl Test", 320, 240)
+	w.Open(s)
+	pbar.SetProgress(-1)		// make sure pulsing progressbars go away properly too
+	for {
+		select {
+		case <-w.Closing:
+			return
+		case <-destroy.Clicked:
+			if part != nil {		// destroying it again would panic
+				DestroyControl(part)
+				part = nil
+			}
+		}
+	}
+}
+
+var logviewTest = flag.Bool("logview", false, "run LogView test instead (appends a line every 100ms)")
+func logviewLoop() {
+	lv := NewLogView("started at " + time.Now().String() + "\n")
+	clear := NewButton("Clear")
+	w := NewWindow("LogView Test", 500, 300)
+	s := NewVerticalStack(lv, clear)
+	s.SetStretchy(0)
+	w.Open(s)
+	ticker := time.Tick(100 * time.Millisecond)
+	for i := 0; ; i++ {
+		select {
+		case t := <-ticker:
+			lv.Append(fmt.Sprintf("line %d at %v; this line is long enough that you should have to scroll horizontally to see the end of it\n", i, t))
+		case <-clear.Clicked:
+			lv.SetText("")
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var levelmeterTest = flag.Bool("levelmeter", false, "run LevelMeter test instead (random levels for 10 seconds)")
+func levelmeterLoop() {
+	levels := make(chan Levels)
+	m := NewLevelMeter(300, 20, levels)
+	w := NewWindow("LevelMeter Test", 320, 60)
+	w.Open(m)
+	go func() {
+		stop := time.After(10 * time.Second)
+		for {
+			select {
+			case <-time.After(10 * time.Millisecond):
+				levels <- Levels{rand.Float64(), rand.Float64() / 2}
+			case <-stop:
+				close(levels)
+				return
+			}
+		}
+	}()
+	<-w.Closing
+}
+
+var bannerTest = flag.Bool("banner", false, "run Window.ShowBanner() test instead")
+func bannerLoop() {
+	e := NewLineEdit("the banner should cover this without moving it")
+	info := NewButton("Info")
+	warning := NewButton("Warning")
+	errb := NewButton("Error")
+	hide := NewButton("Hide")
+	status := NewLabel("no banner yet")
+	w := NewWindow("Banner Test", 500, 200)
+	w.SetSpaced(true)
+	w.Open(NewVerticalStack(e, NewHorizontalStack(info, warning, errb, hide), status))
+	var result <-chan int
+	for {
+		select {
+		case <-info.Clicked:
+			result = w.ShowBanner("A new version is available.", BannerInfo, "Download", "Later")
+		case <-warning.Clicked:
+			result = w.ShowBanner("Connection lost", BannerWarning, "Retry")
+		case <-errb.Clicked:
+			result = w.ShowBanner("Could not save the file, because this message is long enough that it should have to be shortened", BannerError)
+		case <-hide.Clicked:
+			w.HideBanner()
+		case n := <-result:
+			status.SetText(fmt.Sprintf("banner result: %d", n))
+			result = nil
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var busyTest = flag.Bool("busy", false, "run Window.SetBusy() test instead")
+func busyLoop() {
+	lv := NewLogView("")
+	e := NewLineEdit("try typing here while loading")
+	load := NewButton("Load")
+	w := NewWindow("Busy Test", 400, 300)
+	w.SetSpaced(true)
+	s := NewVerticalStack(lv, e, load)
+	s.SetStretchy(0)
+	w.Open(s)
+	var done <-chan time.Time
+	var cancel <-chan int
+	for {
+		select {
+		case <-load.Clicked:
+			w.SetBusy(true)
+			cancel = w.ShowBanner("Loading...", BannerInfo, "Cancel")
+			done = time.After(3 * time.Second)
+		case <-done:
+			lv.Append(fmt.Sprintf("loaded at %v\n", time.Now()))
+			w.HideBanner()
+			w.SetBusy(false)
+			done = nil
+			cancel = nil
+		case <-cancel:
+			lv.Append("canceled\n")
+			w.SetBusy(false)
+			done = nil
+			cancel = nil
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var nearEndTest = flag.Bool("nearend", false, "run Listbox.NearEnd test instead (loads 50 more items each time, up to 500)")
+func nearEndLoop() {
+	batch := func(start int) []string {
+		items := make([]string, 50)
+		for i := range items {
+			items[i] = fmt.Sprintf("item %d", start+i)
+		}
+		return items
+	}
+	lb := NewListbox(batch(0)...)
+	w := NewWindow("NearEnd Test", 300, 300)
+	w.Open(lb)
+	for {
+		select {
+		case <-lb.NearEnd:
+			n := lb.Len()
+			if n < 500 {
+				println("near end at", n, "items; loading more")
+				lb.Append(batch(n)...)
+			}
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var filterTest = flag.Bool("filter", false, "run FilteredList test instead (type in the LineEdit to filter)")
+func filterLoop() {
+	items := make([]string, 1000)
+	for i := range items {
+		items[i] = fmt.Sprintf("item %d", i)
+	}
+	e := NewLineEdit("")
+	lb := NewListbox()
+	more := NewButton("Append 10")
+	w := NewWindow("FilteredList Test", 300, 400)
+	s := NewVerticalStack(e, lb, more)
+	s.SetStretchy(1)
+	w.Open(s)
+	search := ""
+	keep := func(item string) bool {
+		return strings.Contains(item, search)
+	}
+	f := NewFilteredList(lb, items, keep)
+	// LineEdit has no change event, so poll it
+	ticker := time.Tick(200 * time.Millisecond)
+	for {
+		select {
+		case <-ticker:
+			if t := e.Text(); t != search {
+				search = t
+				f.SetFilter(keep)
+				println("showing", f.ShownLen(), "of", f.Len())
+			}
+		case <-more.Clicked:
+			n := f.Len()
+			for i := n; i < n+10; i++ {
+				f.Append(fmt.Sprintf("item %d", i))
+			}
+		case <-lb.SelectionChanged:
+			for _, row := range lb.SelectedIndices() {
+				println("selected item", f.Index(row))
+			}
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var progressTest = flag.Bool("progress", false, "run RunWithProgress() test instead")
+func progressLoop() {
+	lv := NewLogView("")
+	start := NewButton("Start")
+	w := NewWindow("RunWithProgress Test", 400, 300)
+	s := NewVerticalStack(lv, start)
+	s.SetStretchy(0)
+	w.Open(s)
+	for {
+		select {
+		case <-start.Clicked:
+			err := RunWithProgress(w, "Copying Files", func(p Progress) error {
+				p.SetMessage("Counting files...")
+				p.Set(-1)
+				time.Sleep(time.Second)
+				for i := 0; i <= 100; i++ {
+					select {
+					case <-p.Canceled:
+						return fmt.Errorf("canceled at file %d", i)
+					default:
+					}
+					p.SetMessage(fmt.Sprintf("Copying file %d of 100", i))
+					p.Set(i)
+					time.Sleep(30 * time.Millisecond)
+				}
+				return nil
+			})
+			lv.Append(fmt.Sprintf("done: %v\n", err))
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var credentialsTest = flag.Bool("credentials", false, "run AskCredentials() test instead")
+func credentialsLoop() {
+	lv := NewLogView("")
+	ask := NewButton("Log In")
+	domain := NewCheckbox("Ask for Domain")
+	remember := NewCheckbox("Ask to Remember")
+	w := NewWindow("AskCredentials Test", 400, 300)
+	s := NewVerticalStack(lv, domain, remember, ask)
+	s.SetStretchy(0)
+	w.Open(s)
+	last := Credentials{}
+	for {
+		select {
+		case <-ask.Clicked:
+			var fields CredentialsFields
+			if domain.Checked() {
+				fields |= CredentialsDomain
+			}
+			if remember.Checked() {
+				fields |= CredentialsRemember
+			}
+			c, ok := AskCredentials(w, "Log In", "Enter your password for example.com", Credentials{
+				Username: last.Username,
+				Domain:   last.Domain,
+				Remember: last.Remember,
+			}, fields)
+			if !ok {
+				lv.Append("canceled\n")
+				break
+			}
+			last = c
+			lv.Append(fmt.Sprintf("username %q, %d-character password, domain %q, remember %v\n", c.Username, len(c.Password), c.Domain, c.Remember))
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var preferencesTest = flag.Bool("preferences", false, "run ShowPreferences() test instead")
+func preferencesLoop() {
+	lv := NewLogView("")
+	show := NewButton("Preferences")
+	fail := NewCheckbox("Fail to Apply")
+	w := NewWindow("ShowPreferences Test", 400, 300)
+	s := NewVerticalStack(lv, fail, show)
+	s.SetStretchy(0)
+	w.Open(s)
+	name := "Anonymous"
+	dark := false
+	for {
+		select {
+		case <-show.Clicked:
+			nameEdit := NewLineEdit(name)
+			darkCheck := NewCheckbox("Dark Theme")
+			darkCheck.SetChecked(dark)
+			general := NewGrid(2,
+				NewLabel("Name"), nameEdit)
+			general.SetStretchy(0, 1)
+			ShowPreferences(w, "Preferences", []PreferencesPage{
+				{Name: "General", Control: general},
+				{Name: "Appearance", Control: NewVerticalStack(darkCheck)},
+			}, func() error {
+				if fail.Checked() {
+					return fmt.Errorf("the test was asked to fail")
+				}
+				name = nameEdit.Text()
+				dark = darkCheck.Checked()
+				lv.Append(fmt.Sprintf("applied: name %q, dark theme %v\n", name, dark))
+				return nil
+			})
+			lv.Append("closed\n")
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var blockTest = flag.Bool("block", false, "run Window.SetInputBlocked() test instead")
+func blockLoop() {
+	lv := NewLogView("")
+	e := NewLineEdit("type something, then block")
+	block := NewButton("Block")
+	w := NewWindow("SetInputBlocked Test", 400, 300)
+	s := NewVerticalStack(lv, e, block)
+	s.SetStretchy(0)
+	w.Open(s)
+	unblock := NewButton("Buy Now")
+	overlay := NewVerticalStack(
+		NewStandaloneLabel("Your trial has expired."),
+		unblock)
+	for {
+		select {
+		case <-block.Clicked:
+			w.SetInputBlocked(true, overlay)
+			lv.Append("blocked\n")
+		case <-unblock.Clicked:
+			w.SetInputBlocked(false, nil)
+			lv.Append(fmt.Sprintf("unblocked; text is still %q\n", e.Text()))
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var colorTest = flag.Bool("color", false, "run ChooseColor() test instead")
+func colorLoop() {
+	lv := NewLogView("")
+	alpha := NewCheckbox("Allow Alpha")
+	choose := NewButton("Choose Color")
+	w := NewWindow("ChooseColor Test", 400, 300)
+	s := NewVerticalStack(lv, alpha, choose)
+	s.SetStretchy(0)
+	w.Open(s)
+	var current color.Color = color.NRGBA{0x33, 0x66, 0x99, 0xFF}
+	for {
+		select {
+		case <-choose.Clicked:
+			c, ok := ChooseColor(w, current, alpha.Checked(), &ColorOptions{
+				Palette: []color.Color{
+					color.NRGBA{0xFF, 0x00, 0x00, 0xFF},
+					color.NRGBA{0x00, 0xFF, 0x00, 0xFF},
+					color.NRGBA{0x00, 0x00, 0xFF, 0x80},
+				},
+				Changed: func(c color.NRGBA) {
+					lv.Append(fmt.Sprintf("preview %v\n", c))
+				},
+			})
+			if !ok {
+				lv.Append("canceled\n")
+				break
+			}
+			current = c
+			lv.Append(fmt.Sprintf("chose %v\n", c))
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var panicReportTest = flag.Bool("panicreport", false, "run ShowPanicReports() test instead")
+type panicArea struct{}
+func (panicArea) Paint(r image.Rectangle) *image.RGBA {
+	i := image.NewRGBA(r)
+	draw.Draw(i, r, &image.Uniform{color.RGBA{0xFF, 0xCC, 0xCC, 0xFF}}, image.ZP, draw.Src)
+	return i
+}
+func (panicArea) Mouse(e MouseEvent) bool {
+	if e.Down != 0 {
+		panic("clicked on the panicking Area")
+	}
+	return false
+}
+func (panicArea) Key(e KeyEvent) bool { return false }
+func panicReportLoop() {
+	ShowPanicReports(func(report string) error {
+		time.Sleep(2 * time.Second)
+		if rand.Intn(2) == 0 {
+			return fmt.Errorf("pretend the network is down")
+		}
+		println("sent", len(report), "bytes")
+		return nil
+	})
+	defer ReportPanic()
+	a := NewArea(200, 100, panicArea{})
+	b := NewButton("Panic Here")
+	w := NewWindow("ShowPanicReports Test", 300, 200)
+	s := NewVerticalStack(NewStandaloneLabel("Click the Area to panic on the UI thread."), a, b)
+	s.SetStretchy(1)
+	w.Open(s)
+	for {
+		select {
+		case <-b.Clicked:
+			var m map[string]int
+			m["boom"]++		// panics on this goroutine
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var gridNavTest = flag.Bool("gridnav", false, "run Grid.SetKeyboardNavigation() test instead")
+func gridNavLoop() {
+	const rows, cols = 4, 3
+	var controls []Control
+	var edits []*LineEdit
+	for row := 0; row < rows; row++ {
+		controls = append(controls, NewLabel(fmt.Sprintf("Row %d", row + 1)))
+		for col := 0; col < cols; col++ {
+			if row == 2 && col == 1 {		// something to skip over
+				controls = append(controls, NewCheckbox("Skip me"))
+				continue
+			}
+			e := NewLineEdit(fmt.Sprintf("%c%d", 'A' + col, row + 1))
+			controls = append(controls, e)
+			edits = append(edits, e)
+		}
+	}
+	g := NewGrid(cols + 1, controls...)
+	g.SetKeyboardNavigation(true, GridEnterDown)
+	show := NewButton("Print Contents")
+	w := NewWindow("Grid Keyboard Navigation Test", 400, 200)
+	s := NewVerticalStack(g, show)
+	s.SetStretchy(0)
+	w.Open(s)
+	for {
+		select {
+		case <-show.Clicked:
+			for _, e := range edits {
+				print(e.Text(), " ")
+			}
+			println()
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var validateTest = flag.Bool("validate", false, "run LineEdit focus events and LineEdit.SetValidator() test instead")
+func validateLoop() {
+	name := NewLineEdit("")
+	age := NewLineEdit("")
+	age.SetValidator(func(text string) error {
+		n, err := strconv.Atoi(text)
+		if err != nil || n < 0 || n > 150 {
+			return fmt.Errorf("Age must be a number from 0 to 150.")
+		}
+		return nil
+	}, true)
+	email := NewLineEdit("")
+	email.SetValidator(func(text string) error {
+		if !strings.Contains(text, "@") {
+			return fmt.Errorf("That doesn't look like an email address.")
+		}
+		return nil
+	}, false)
+	g := NewGrid(2,
+		NewLabel("Name (no validation)"), name,
+		NewLabel("Age (keeps focus)"), age,
+		NewLabel("Email"), email)
+	w := NewWindow("LineEdit Validation Test", 400, 150)
+	w.Open(g)
+	for {
+		select {
+		case <-name.GotFocus:
+			println("name got focus")
+		case <-name.LostFocus:
+			println("name lost focus")
+		case <-age.GotFocus:
+			println("age got focus")
+		case <-age.LostFocus:
+			println("age lost focus:", age.Text())
+		case <-email.GotFocus:
+			println("email got focus")
+		case <-email.LostFocus:
+			println("email lost focus:", email.Text())
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var historyTest = flag.Bool("history", false, "run LineEdit.SetHistory() test instead")
+var historyFile = flag.String("historyfile", "", "file to keep the -history test's history in (default: none)")
+func historyLoop() {
+	e := NewLineEdit("")
+	var store HistoryStore
+	if *historyFile != "" {
+		store = NewHistoryFile(*historyFile)
+	}
+	err := e.SetHistory(10, store)
+	if err != nil {
+		println("error loading history:", err.Error())
+	}
+	run := NewButton("Run")
+	s := NewHorizontalStack(e, run)
+	s.SetStretchy(0)
+	w := NewWindow("LineEdit History Test", 400, 60)
+	w.Open(s)
+	for {
+		select {
+		case <-run.Clicked:
+			println("running", e.Text())
+			err := e.AddToHistory(e.Text())
+			if err != nil {
+				println("error saving history:", err.Error())
+			}
+			e.SetText("")
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var keyboardTest = flag.Bool("keyboard", false, "run CapsLockOn() and KeyboardLayout() test instead")
+func keyboardLoop() {
+	password := NewPasswordEdit()
+	status := NewStandaloneLabel("")
+	check := NewButton("Log In")
+	s := NewVerticalStack(password, status, check)
+	s.SetStretchy(1)
+	w := NewWindow("Caps Lock and Keyboard Layout Test", 300, 120)
+	w.Open(s)
+	for {
+		select {
+		case <-check.Clicked:
+			msg := fmt.Sprintf("Wrong password. Keyboard layout: %q.", KeyboardLayout())
+			if CapsLockOn() {
+				msg += " Caps Lock is on."
+			}
+			status.SetText(msg)
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var clipboardTest = flag.Bool("clipboard", false, "run ClipboardChanged and SetClipboard() test instead")
+func clipboardLoop() {
+	log := NewLogView("")
+	status := NewStandaloneLabel("")
+	copyChart := NewButton("Copy Chart")
+	copyFile := NewButton("Copy This Program")
+	buttons := NewHorizontalStack(copyChart, copyFile)
+	s := NewVerticalStack(status, buttons, log)
+	s.SetStretchy(2)
+	w := NewWindow("Clipboard Test", 400, 300)
+	w.Open(s)
+	update := func() {
+		f := ClipboardFormats()
+		if f&ClipboardFormatText != 0 {
+			status.SetText("Clipboard has text; Paste would be enabled.")
+		} else {
+			status.SetText("Clipboard has no text; Paste would be disabled.")
+		}
+		if f&ClipboardFormatText != 0 {
+			log.Append(fmt.Sprintf("text: %q\n", ClipboardText()))
+		}
+		if f&ClipboardFormatHTML != 0 {
+			log.Append(fmt.Sprintf("HTML: %q\n", ClipboardHTML()))
+		}
+		if f&ClipboardFormatImage != 0 {
+			if img := ClipboardImage(); img != nil {
+				log.Append(fmt.Sprintf("image: %v\n", img.Bounds()))
+			} else {
+				log.Append("image: (unreadable)\n")
+			}
+		}
+		if f&ClipboardFormatFiles != 0 {
+			log.Append(fmt.Sprintf("files: %q\n", ClipboardFiles()))
+		}
+		log.Append("--\n")
+	}
+	update()
+	for {
+		select {
+		case <-ClipboardChanged:
+			update()
+		case <-copyChart.Clicked:
+			values := []int{3, 7, 4, 9, 6}
+			img := image.NewNRGBA(image.Rect(0, 0, 100, 100))
+			text := ""
+			html := "<table>"
+			for i, v := range values {
+				draw.Draw(img, image.Rect(i * 20 + 2, 100 - v * 10, i * 20 + 18, 100),
+					&image.Uniform{color.NRGBA{0, 0, 255, 192}}, image.ZP, draw.Src)
+				text += fmt.Sprintf("%d\t%d\n", i + 1, v)
+				html += fmt.Sprintf("<tr><td>%d</td><td>%d</td></tr>", i + 1, v)
+			}
+			html += "</table>"
+			SetClipboard(ClipboardData{
+				Text:	text,
+				HTML:	html,
+				Image:	img,
+			})
+		case <-copyFile.Clicked:
+			exe, _ := filepath.Abs(os.Args[0])
+			SetClipboard(ClipboardData{
+				Files:	[]string{exe},
+			})
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var paletteTest = flag.Bool("palette", false, "run ShowCommandPalette() test instead")
+func paletteLoop() {
+	commands := []string{
+		"New File",
+		"Open File...",
+		"Open Recent",
+		"Save",
+		"Save As...",
+		"Close Window",
+		"Toggle Word Wrap",
+		"Go to Line...",
+		"Find in Files",
+		"Format Document",
+		"Open Settings",
+		"Drop Shadow",
+	}
+	status := NewStandaloneLabel("No command run yet.")
+	show := NewButton("Show Command Palette")
+	s := NewVerticalStack(status, show)
+	s.SetStretchy(0)
+	w := NewWindow("Command Palette Test", 300, 100)
+	w.Open(s)
+	for {
+		select {
+		case <-show.Clicked:
+			if i, ok := ShowCommandPalette(w, commands); ok {
+				status.SetText(fmt.Sprintf("Ran %q.", commands[i]))
+			} else {
+				status.SetText("Canceled.")
+			}
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var settingsTest = flag.Bool("settings", false, "run BindSetting() test instead")
+var settingsFile = flag.String("settingsfile", filepath.Join(os.TempDir(), "uitest-settings.json"), "file for -settings")
+func settingsLoop() {
+	store := NewSettingsFile(*settingsFile)
+	name := NewLineEdit("")
+	wrap := NewCheckbox("Wrap long lines")
+	theme := NewCombobox("Light", "Dark", "System")
+	plugins := NewMultiSelListbox("Spelling", "Git", "Markdown Preview", "Vim Keys")
+	for _, b := range []struct {
+		c	Control
+		key	string
+	}{
+		{name, "name"},
+		{wrap, "wrap"},
+		{theme, "theme"},
+		{plugins, "plugins"},
+	} {
+		if err := BindSetting(b.c, store, b.key); err != nil {
+			fmt.Fprintf(os.Stderr, "error loading setting %q: %v\n", b.key, err)
+		}
+	}
+	g := NewGrid(2,
+		NewLabel("Name:"), name,
+		NewLabel("Theme:"), theme,
+		NewLabel("Plugins:"), plugins)
+	g.SetStretchy(2, 1)
+	g.SetFilling(0, 1)
+	s := NewVerticalStack(g, wrap)
+	s.SetStretchy(0)
+	w := NewWindow("Settings Test", 300, 250)
+	w.SetSpaced(true)
+	w.Open(s)
+	<-w.Closing
+	w.Destroy()
+	fmt.Printf("settings saved to %s\n", *settingsFile)
+}
+
+var relayoutTest = flag.Bool("relayout", false, "run Window.Relayout() test instead")
+func relayoutLoop() {
+	button := NewButton("Short")
+	label := NewLabel("Label:")
+	edit := NewLineEdit("the LineEdit should move with the Label")
+	row := NewHorizontalStack(label, edit)
+	row.SetStretchy(1)
+	relayout := NewCheckbox("Relayout after changing text")
+	relayout.SetChecked(true)
+	s := NewVerticalStack(NewHorizontalStack(button, Space()), row, relayout)
+	w := NewWindow("Relayout Test", 400, 150)
+	w.Open(s)
+	long := false
+	for {
+		select {
+		case <-button.Clicked:
+			long = !long
+			if long {
+				button.SetText("A much, much longer caption")
+				label.SetText("A much longer label:")
+			} else {
+				button.SetText("Short")
+				label.SetText("Label:")
+			}
+			if relayout.Checked() {
+				w.Relayout()
+			}
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var orientationTest = flag.Bool("orientation", false, "run Stack.SetOrientation() and Stack.SetBreakpoint() test instead")
+func orientationLoop() {
+	panes := NewHorizontalStack(NewListbox("Inbox", "Sent", "Drafts"), NewLogView("the message would be here\n"))
+	panes.SetStretchy(0)
+	panes.SetStretchy(1)
+	toggle := NewButton("Stack Panes Vertically")
+	auto := NewHorizontalStack(NewStandaloneLabel("Side by side when 400 pixels or wider:"), NewLineEdit("resize the window"))
+	auto.SetStretchy(1)
+	auto.SetBreakpoint(400)
+	s := NewVerticalStack(panes, toggle, auto)
+	s.SetStretchy(0)
+	w := NewWindow("Stack Orientation Test", 400, 300)
+	w.Open(s)
+	o := Horizontal
+	for {
+		select {
+		case <-toggle.Clicked:
+			if o == Horizontal {
+				o = Vertical
+				toggle.SetText("Put Panes Side by Side")
+			} else {
+				o = Horizontal
+				toggle.SetText("Stack Panes Vertically")
+			}
+			panes.SetOrientation(o)
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var tabTest = flag.Bool("tab", false, "run Tab test instead")
+func tabLoop() {
+	name := NewLineEdit("")
+	g := NewGrid(2,
+		NewLabel("Name"), name,
+		NewLabel("Email"), NewLineEdit(""))
+	g.SetStretchy(1, 1)
+	inner := NewTab()
+	inner.AddPage("Inner One", NewCheckbox("A Tab in a Tab"))
+	inner.AddPage("Inner Two", NewListbox("Its pages", "stay hidden", "with the outer page"))
+	log := NewLogView("switching pages keeps what's typed in them\n")
+	t := NewTab()
+	t.AddPage("Form", g)
+	t.AddPage("Nested", inner)
+	t.AddPage("Log", log)
+	t.AddPage("Empty", nil)
+	next := NewButton("Next Page")
+	status := NewLabel("Page 0")
+	s := NewVerticalStack(t, NewHorizontalStack(next, status))
+	s.SetStretchy(0)
+	w := NewWindow("Tab Test", 400, 300)
+	w.SetSpaced(true)
+	w.Open(s)
+	for {
+		select {
+		case <-next.Clicked:
+			t.SelectPage((t.SelectedPage() + 1) % 4)
+			status.SetText(fmt.Sprintf("Page %d (selected by SelectPage)", t.SelectedPage()))
+		case <-t.SelectionChanged:
+			status.SetText(fmt.Sprintf("Page %d", t.SelectedPage()))
+			log.Append(fmt.Sprintf("SelectionChanged: page %d\n", t.SelectedPage()))
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var groupTest = flag.Bool("group", false, "run ControlGroup test instead")
+func groupLoop() {
+	name := NewLineEdit("")
+	subscribe := NewCheckbox("Subscribe")
+	plan := NewCombobox("Free", "Pro", "Enterprise")
+	extras := NewListbox("Stickers", "T-shirt", "Mug")
+	form := NewGrid(2,
+		NewLabel("Name"), name,
+		NewLabel("Plan"), plan,
+		Space(), subscribe,
+		NewLabel("Extras"), extras)
+	form.SetStretchy(3, 1)
+	submit := NewButton("Submit")
+	toggle := NewButton("Hide Extras")
+	busy := NewButton("Busy for 2 Seconds")
+	status := NewLabel("")
+	s := NewVerticalStack(form, NewHorizontalStack(submit, toggle, busy), status)
+	s.SetStretchy(0)
+	everything := NewControlGroup(form, submit)
+	optional := NewControlGroup(extras)
+	w := NewWindow("ControlGroup Test", 400, 300)
+	w.SetSpaced(true)
+	w.Open(s)
+	hidden := false
+	done := make(chan struct{})
+	for {
+		select {
+		case <-submit.Clicked:
+			everything.DisableAll()
+			status.SetText("submitting " + name.Text() + "...")
+			go func() {
+				time.Sleep(2 * time.Second)
+				done <- struct{}{}
+			}()
+		case <-done:
+			everything.EnableAll()
+			everything.ResetAll()
+			status.SetText("submitted; the form was reset")
+		case <-toggle.Clicked:
+			hidden = !hidden
+			if hidden {
+				optional.HideAll()
+				toggle.SetText("Show Extras")
+			} else {
+				optional.ShowAll()
+				toggle.SetText("Hide Extras")
+			}
+		case <-busy.Clicked:
+			// a form disabled by Submit stays disabled after this
+			w.SetBusy(true)
+			time.Sleep(2 * time.Second)
+			w.SetBusy(false)
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var formTest = flag.Bool("form", false, "run Form test instead")
+func formLoop() {
+	user := NewLineEdit("")
+	user.SetValidator(func(text string) error {
+		if len(text) < 3 {
+			return fmt.Errorf("User names are at least 3 characters long.")
+		}
+		return nil
+	}, false)
+	port := NewLineEdit("8080")
+	port.SetValidator(func(text string) error {
+		n, err := strconv.Atoi(text)
+		if err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("The port must be a number from 1 to 65535.")
+		}
+		return nil
+	}, false)
+	comment := NewLineEdit("")
+	connect := NewButton("Connect")
+	check := NewButton("Check")
+	clear := NewButton("Clear")
+	status := NewLabel("")
+	f := NewForm(connect, user, port, comment)
+	g := NewGrid(2,
+		NewLabel("User"), user,
+		NewLabel("Port"), port,
+		NewLabel("Comment (no validation)"), comment)
+	s := NewVerticalStack(g, NewHorizontalStack(connect, check, clear), status)
+	w := NewWindow("Form Test", 400, 200)
+	w.SetSpaced(true)
+	w.Open(s)
+	for {
+		select {
+		case <-connect.Clicked:
+			status.SetText("connecting as " + user.Text() + " on port " + port.Text())
+		case <-check.Clicked:
+			errs := f.Validate()
+			status.SetText(fmt.Sprintf("%d invalid field(s)", len(errs)))
+		case <-clear.Clicked:
+			// Connect should be disabled again
+			user.SetText("")
+			port.SetText("")
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var treeTest = flag.Bool("tree", false, "run TreeView test instead")
+func treeLoop() {
+	t := NewTreeView()
+	src := t.Add(nil, "src")
+	ui := t.Add(src, "ui")
+	t.Add(ui, "tree.go")
+	t.Add(ui, "tree_windows.go")
+	t.Add(src, "main.go")
+	docs := t.Add(nil, "docs")
+	readme := t.Add(docs, "README")
+	t.Expand(ui) // expands src too
+	t.Select(readme)
+	name := NewLineEdit("new file")
+	add := NewButton("Add Under Selected")
+	remove := NewButton("Remove Selected")
+	expand := NewButton("Expand Selected")
+	collapse := NewButton("Collapse Selected")
+	status := NewLabel("")
+	s := NewVerticalStack(t,
+		NewHorizontalStack(name, add, remove),
+		NewHorizontalStack(expand, collapse),
+		status)
+	s.SetStretchy(0)
+	w := NewWindow("TreeView Test", 400, 400)
+	w.SetSpaced(true)
+	w.Open(s)
+	for {
+		select {
+		case <-t.SelectionChanged:
+			n := t.Selected()
+			if n == nil {
+				status.SetText("nothing selected")
+				break
+			}
+			path := n.Text()
+			for p := n.Parent(); p != nil; p = p.Parent() {
+				path = p.Text() + "/" + path
+			}
+			status.SetText(path)
+		case <-add.Clicked:
+			t.Select(t.Add(t.Selected(), name.Text()))
+		case <-remove.Clicked:
+			if n := t.Selected(); n != nil {
+				t.Remove(n)
+			}
+		case <-expand.Clicked:
+			if n := t.Selected(); n != nil {
+				t.Expand(n)
+			}
+		case <-collapse.Clicked:
+			if n := t.Selected(); n != nil {
+				t.Collapse(n)
+			}
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var sliderTest = flag.Bool("slider", false, "run Slider test instead")
+func sliderLoop() {
+	volume := NewHorizontalSlider(0, 100, 1)
+	volume.SetValue(75)
+	zoom := NewHorizontalSlider(25, 400, 25) // steps of 25%; 400 is a step too
+	zoom.SetValue(100)
+	odd := NewHorizontalSlider(0, 10, 3) // 0, 3, 6, 9; SetValue(10) should go to 9
+	odd.SetValue(10)
+	level := NewVerticalSlider(-10, 10, 2)
+	status := NewLabel("")
+	reset := NewButton("Reset")
+	g := NewControlGroup(volume, zoom, odd, level)
+	labels := NewVerticalStack(NewLabel("Volume"), NewLabel("Zoom"), NewLabel("Odd Steps"))
+	sliders := NewVerticalStack(volume, zoom, odd)
+	top := NewHorizontalStack(labels, sliders, level)
+	top.SetStretchy(1)
+	s := NewVerticalStack(top, status, reset)
+	s.SetStretchy(0)
+	w := NewWindow("Slider Test", 400, 300)
+	w.SetSpaced(true)
+	w.Open(s)
+	show := func() {
+		status.SetText(fmt.Sprintf("volume %d zoom %d%% odd %d level %d",
+			volume.Value(), zoom.Value(), odd.Value(), level.Value()))
+	}
+	show()
+	for {
+		select {
+		case <-volume.ValueChanged:
+			show()
+		case <-zoom.ValueChanged:
+			show()
+		case <-odd.ValueChanged:
+			show()
+		case <-level.ValueChanged:
+			show()
+		case <-reset.Clicked:
+			g.ResetAll()
+			show()
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var bidiTest = flag.Bool("bidi", false, "run bidirectional text test instead")
+func bidiLoop() {
+	const mixed = "שלום, world! גרסה 1.2 (beta)"
+	directions := []TextDirection{TextDirectionNatural, TextDirectionLeftToRight, TextDirectionRightToLeft}
+	names := []string{"Natural", "Left to Right", "Right to Left"}
+	var controls []Control
+	for i, d := range directions {
+		l := NewLabel(mixed)
+		l.SetTextDirection(d)
+		e := NewLineEdit(mixed)
+		e.SetTextDirection(d)
+		controls = append(controls, NewLabel(names[i]), l, e)
+	}
+	g := NewGrid(3, controls...)
+	for row := range directions {
+		g.SetFilling(row, 1)
+		g.SetFilling(row, 2)
+	}
+	log := NewLogView(mixed + "\nhello, עולם\n" + "123 - ארבע\n")
+	log.SetTextDirection(TextDirectionRightToLeft)
+	s := NewVerticalStack(g, log)
+	s.SetStretchy(1)
+	w := NewWindow("Bidirectional Text Test", 500, 300)
+	w.SetSpaced(true)
+	w.Open(s)
+	<-w.Closing
+}
+
+var localeTest = flag.Bool("locale", false, "run locale number and date formatting test instead")
+func localeLoop() {
+	number := NewLineEdit(FormatNumber(1234567.891, 2))
+	date := NewLineEdit(FormatDate(time.Now()))
+	status := NewLabel("")
+	parse := NewButton("Parse")
+	g := NewGrid(2,
+		NewLabel("Number"), number,
+		NewLabel("Date"), date)
+	g.SetFilling(0, 1)
+	g.SetFilling(1, 1)
+	s := NewVerticalStack(g, status, parse)
+	w := NewWindow("Locale Test", 400, 150)
+	w.SetSpaced(true)
+	w.Open(s)
+	for {
+		select {
+		case <-parse.Clicked:
+			// both are formatted back so the round trip shows
+			text := ""
+			if f, err := ParseNumber(number.Text()); err != nil {
+				text += err.Error()
+			} else {
+				text += fmt.Sprintf("%g (%s)", f, FormatNumber(f, 3))
+			}
+			text += "; "
+			if t, err := ParseDate(date.Text()); err != nil {
+				text += err.Error()
+			} else {
+				text += fmt.Sprintf("%s (%s)", t.Format("2006-01-02"), FormatDate(t))
+			}
+			status.SetText(text)
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var radioTest = flag.Bool("radio", false, "run RadioButtons test instead")
+func radioLoop() {
+	size := NewRadioButtons("Small", "Medium", "Large")
+	size.SetSelected(1)
+	color := NewRadioButtons("Red", "Green", "Blue", "Other")
+	status := NewLabel("")
+	next := NewButton("Next Size")
+	reset := NewButton("Reset")
+	hide := NewButton("Hide Colors")
+	g := NewControlGroup(size, color)
+	colors := NewControlGroup(color)
+	top := NewHorizontalStack(size, color)
+	s := NewVerticalStack(top, status, NewHorizontalStack(next, reset, hide))
+	s.SetStretchy(0)
+	w := NewWindow("RadioButtons Test", 400, 250)
+	w.SetSpaced(true)
+	w.Open(s)
+	show := func() {
+		status.SetText(fmt.Sprintf("size %d color %d", size.Selected(), color.Selected()))
+	}
+	show()
+	hidden := false
+	for {
+		select {
+		case <-size.SelectionChanged:
+			show()
+		case <-color.SelectionChanged:
+			show()
+		case <-next.Clicked:
+			// shouldn't signal SelectionChanged
+			size.SetSelected((size.Selected() + 1) % size.Len())
+			show()
+		case <-hide.Clicked:
+			hidden = !hidden
+			if hidden {
+				colors.HideAll()
+				hide.SetText("Show Colors")
+			} else {
+				colors.ShowAll()
+				hide.SetText("Hide Colors")
+			}
+		case <-reset.Clicked:
+			g.ResetAll()
+			show()
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var groupBoxTest = flag.Bool("groupbox", false, "run GroupBox test instead")
+func groupBoxLoop() {
+	name := NewLineEdit("")
+	email := NewLineEdit("")
+	g := NewGrid(2,
+		NewLabel("Name"), name,
+		NewLabel("Email"), email)
+	g.SetFilling(0, 1)
+	g.SetFilling(1, 1)
+	g.SetStretchy(0, 1)
+	person := NewGroupBox("Person", g)
+	size := NewGroupBox("Size", NewRadioButtons("Small", "Medium", "Large"))
+	notify := NewCheckbox("Send notifications")
+	options := NewGroupBox("", notify)
+	tab := NewTab()
+	tab.AddPage("Box", NewGroupBox("In a Tab", NewButton("Inside")))
+	tab.AddPage("Empty", Space())
+	title := NewButton("Change Title")
+	hide := NewButton("Hide Size")
+	sizes := NewControlGroup(size)
+	s := NewVerticalStack(person, NewHorizontalStack(size, options, tab), NewHorizontalStack(title, hide))
+	s.SetStretchy(1)
+	w := NewWindow("GroupBox Test", 400, 300)
+	w.SetSpaced(true)
+	w.Open(s)
+	hidden := false
+	for {
+		select {
+		case <-title.Clicked:
+			// an empty title should also drop the gap in the frame
+			if options.Title() == "" {
+				options.SetTitle("Options")
+			} else {
+				options.SetTitle("")
+			}
+		case <-hide.Clicked:
+			hidden = !hidden
+			if hidden {
+				sizes.HideAll()
+				hide.SetText("Show Size")
+			} else {
+				sizes.ShowAll()
+				hide.SetText("Hide Size")
+			}
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var dateTimeTest = flag.Bool("datetime", false, "run DateTimePicker test instead")
+func dateTimeLoop() {
+	date := NewDatePicker()
+	when := NewDateTimePicker()
+	when.SetTime(time.Date(2014, time.July, 9, 13, 5, 0, 0, time.Local))
+	status := NewLabel("")
+	now := NewButton("Now")
+	reset := NewButton("Reset")
+	g := NewControlGroup(date, when)
+	s := NewVerticalStack(date, when, status, NewHorizontalStack(now, reset))
+	s.SetStretchy(2)
+	w := NewWindow("DateTimePicker Test", 400, 400)
+	w.SetSpaced(true)
+	w.Open(s)
+	show := func() {
+		status.SetText(fmt.Sprintf("date %v\ntime %v", date.Time(), when.Time()))
+	}
+	show()
+	for {
+		select {
+		case <-date.Changed:
+			show()
+		case <-when.Changed:
+			show()
+		case <-now.Clicked:
+			// shouldn't signal Changed
+			when.SetTime(time.Now())
+			show()
+		case <-reset.Clicked:
+			g.ResetAll()
+			show()
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var colorButtonTest = flag.Bool("colorbutton", false, "run ColorButton test instead")
+func colorButtonLoop() {
+	opaque := NewColorButton(color.RGBA{0xFF, 0, 0, 0xFF}, false)
+	alpha := NewColorButton(color.NRGBA{0, 0, 0xFF, 0x80}, true)
+	status := NewLabel("")
+	swap := NewButton("Swap")
+	s := NewVerticalStack(NewHorizontalStack(opaque, alpha), status, swap)
+	s.SetStretchy(1)
+	w := NewWindow("ColorButton Test", 300, 150)
+	w.SetSpaced(true)
+	w.Open(s)
+	show := func() {
+		status.SetText(fmt.Sprintf("opaque %v\nalpha %v", opaque.Color(), alpha.Color()))
+	}
+	show()
+	for {
+		select {
+		case <-opaque.Changed:
+			show()
+		case <-alpha.Changed:
+			show()
+		case <-swap.Clicked:
+			// shouldn't signal Changed; opaque should stay opaque
+			a, b := opaque.Color(), alpha.Color()
+			opaque.SetColor(b)
+			alpha.SetColor(a)
+			show()
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+// a grid of 20x30 "characters" with a caret moved by the arrow keys, big enough to scroll
+type caretArea struct {
+	lock	sync.Mutex
+	x, y	int
+}
+const caretCols, caretRows = 40, 10
+func (c *caretArea) Paint(rect image.Rectangle) *image.RGBA {
+	c.lock.Lock(); defer c.lock.Unlock()
+	img := image.NewRGBA(image.Rect(0, 0, caretCols*20, caretRows*30))
+	draw.Draw(img, img.Rect, image.White, image.ZP, draw.Src)
+	for y := 0; y < caretRows; y++ {
+		for x := 0; x < caretCols; x++ {
+			draw.Draw(img, image.Rect(x*20+4, y*30+8, x*20+16, y*30+24), image.NewUniform(color.Gray{0xC0}), image.ZP, draw.Src)
+		}
+	}
+	draw.Draw(img, c.caret().Inset(-1), image.Black, image.ZP, draw.Src)
+	return img.SubImage(rect).(*image.RGBA)
+}
+func (c *caretArea) caret() image.Rectangle {
+	return image.Rect(c.x*20, c.y*30+4, c.x*20, c.y*30+28)
+}
+func (c *caretArea) Caret() image.Rectangle {
+	c.lock.Lock(); defer c.lock.Unlock()
+	return c.caret()
+}
+func (c *caretArea) Mouse(e MouseEvent) bool { return false }
+func (c *caretArea) Key(e KeyEvent) bool {
+	c.lock.Lock(); defer c.lock.Unlock()
+	if e.Up {
+		return false
+	}
+	switch e.ExtKey {
+	case Left:
+		c.x = (c.x + caretCols - 1) % caretCols
+	case Right:
+		c.x = (c.x + 1) % caretCols
+	case Up:
+		c.y = (c.y + caretRows - 1) % caretRows
+	case Down:
+		c.y = (c.y + 1) % caretRows
+	default:
+		return false
+	}
+	return true
+}
+
+var caretTest = flag.Bool("caret", false, "run AreaCaretHandler test instead (turn on Magnifier or Zoom and move the caret with the arrow keys)")
+func caretLoop() {
+	a := NewArea(caretCols*20, caretRows*30, &caretArea{})
+	w := NewWindow("AreaCaretHandler Test", 400, 200)
+	s := NewVerticalStack(NewLineEdit("magnifiers follow this caret on their own"), a)
+	s.SetStretchy(1)
+	w.Open(s)
+	<-w.Closing
+}
+
+var highContrastTest = flag.Bool("highcontrast", false, "run HighContrast() test instead (turn high contrast on and off while it runs)")
+func highContrastLoop() {
+	levels := make(chan Levels)
+	defer close(levels)
+	m := NewLevelMeter(300, 20, levels)
+	b := NewButton("Badge")
+	b.SetBadge("3")
+	status := NewLabel("")
+	w := NewWindow("HighContrast Test", 320, 150)
+	w.SetSpaced(true)
+	w.Open(NewVerticalStack(status, m, b))
+	show := func() {
+		status.SetText(fmt.Sprintf("HighContrast() %v", HighContrast()))
+	}
+	show()
+	tick := time.Tick(10 * time.Millisecond)
+	for {
+		select {
+		case <-tick:
+			levels <- Levels{0.95, 0.5} // keep the bars up; both colors in the top one
+		case <-HighContrastChanged:
+			show()
+			w.ShowBanner("High contrast changed.", BannerInfo)
+		case <-b.Clicked:
+			w.HideBanner()
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var animationsTest = flag.Bool("animations", false, "run AnimationsEnabled() test instead (turn animations off to see the difference)")
+func animationsLoop() {
+	levels := make(chan Levels)
+	defer close(levels)
+	m := NewLevelMeter(300, 20, levels)
+	busy := NewButton("Busy")
+	status := NewLabel(fmt.Sprintf("AnimationsEnabled() %v", AnimationsEnabled()))
+	w := NewWindow("AnimationsEnabled Test", 320, 150)
+	w.SetSpaced(true)
+	w.Open(NewVerticalStack(status, m, busy))
+	tick := time.Tick(500 * time.Millisecond)
+	high := false
+	var done <-chan int
+	for {
+		select {
+		case <-tick:
+			high = !high
+			if high {
+				levels <- Levels{0.9, 0.6}
+			}
+			status.SetText(fmt.Sprintf("AnimationsEnabled() %v", AnimationsEnabled()))
+		case <-busy.Clicked:
+			w.SetBusy(true)
+			done = w.ShowBanner("Did this slide in?", BannerInfo, "Not Busy")
+		case <-done:
+			done = nil
+			w.SetBusy(false)
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var fontTest = flag.Bool("font", false, "run FontButton and ChooseFont() test instead")
+func fontLoop() {
+	fb := NewFontButton(Font{"Arial", 12, FontWeightBold})
+	choose := NewButton("ChooseFont()")
+	chooseDefault := NewButton("ChooseFont() from system font")
+	status := NewLabel(fb.Font().String())
+	w := NewWindow("Font Test", 320, 150)
+	w.SetSpaced(true)
+	w.Open(NewVerticalStack(fb, choose, chooseDefault, status))
+	for {
+		select {
+		case <-fb.Changed:
+			status.SetText(fmt.Sprintf("Changed: %q %#v", fb.Font(), fb.Font()))
+		case <-choose.Clicked:
+			f, ok := ChooseFont(w, fb.Font())
+			status.SetText(fmt.Sprintf("%q %#v %v", f, f, ok))
+			if ok {
+				fb.SetFont(f)
+			}
+		case <-chooseDefault.Clicked:
+			f, ok := ChooseFont(nil, Font{})
+			status.SetText(fmt.Sprintf("%q %#v %v", f, f, ok))
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var menuTest = flag.Bool("menu", false, "run Menu and Window.SetMenuBar() test instead")
+func menuLoop() {
+	quit := NewQuitMenuItem()
+	var about, prefs *MenuItem // made by newItems(), as each menu bar needs its own
+	wrap := NewCheckMenuItem("Word Wrap", true)
+	status := NewCheckMenuItem("Status Bar", false)
+	deep := NewMenuItem("Deep && Deeper")
+	small := NewRadioMenuItem("Small", false)
+	medium := NewRadioMenuItem("Medium", true)
+	large := NewRadioMenuItem("Large", true) // should start unchecked
+	undo := NewMenuItem("Undo")
+	var lock sync.Mutex // for typed and recentItems, which the SetOpening() functions read on the UI thread
+	typed := 0
+	recent := NewMenu()
+	recentItems := []string{}
+	recentClicked := make(chan string)
+	newItems := func() (*MenuItem, *Menu) {
+		open := NewMenuItem("Open")
+		about = NewAboutMenuItem()
+		prefs = NewPreferencesMenuItem()
+		edit := NewMenu(undo, NewMenuSeparator(), prefs)
+		// these run on the UI thread, so they can only change the Menus
+		edit.SetOpening(func() {
+			lock.Lock()
+			defer lock.Unlock()
+			undo.SetEnabled(typed != 0)
+			undo.SetText(fmt.Sprintf("Undo Typing (%d)", typed))
+		})
+		recent.SetOpening(func() {
+			lock.Lock()
+			defer lock.Unlock()
+			items := []*MenuItem{}
+			for _, name := range recentItems {
+				i := NewMenuItem(name)
+				name := name
+				go func() {
+					for range i.Clicked {
+						recentClicked <- name
+					}
+				}()
+				items = append(items, i)
+			}
+			if len(items) == 0 {
+				none := NewMenuItem("(none)")
+				none.SetEnabled(false)
+				items = append(items, none)
+			}
+			recent.SetItems(items...)
+		})
+		return open, NewMenu(
+			NewSubmenu("File", NewMenu(
+				open,
+				NewSubmenu("Open Recent", recent),
+				NewMenuSeparator(),
+				quit)),
+			NewEditMenu(),
+			NewSubmenu("Actions", edit),
+			NewSubmenu("View", NewMenu(
+				wrap,
+				status,
+				NewMenuSeparator(),
+				small,
+				medium,
+				large,
+				NewMenuSeparator(),
+				NewSubmenu("More", NewMenu(deep)))),
+			NewSubmenu("Help", NewMenu(about)))
+	}
+	open, bar := newItems()
+	replace := NewButton("Replace Menu Bar")
+	remove := NewButton("Remove Menu Bar")
+	toggleOpen := NewButton("Enable/Disable Open")
+	rename := NewButton("Rename Deep")
+	check := NewButton("Check Small")
+	typing := NewButton("Type Something")
+	label := NewLabel("")
+	// for the Edit menu
+	lineedit := NewLineEdit("select some of this text and try the Edit menu")
+	logview := NewLogView("this can be copied\nbut not changed\n")
+	w := NewWindow("Menu Test", 320, 400)
+	w.SetMenuBar(bar)
+	s := NewVerticalStack(replace, remove, toggleOpen, rename, check, typing, label, lineedit, logview)
+	s.SetStretchy(8)
+	w.Open(s)
+	for {
+		select {
+		case <-open.Clicked:
+			lock.Lock()
+			recentItems = append(recentItems, fmt.Sprintf("File %d", len(recentItems)+1))
+			lock.Unlock()
+			label.SetText("Open clicked; added to Open Recent")
+		case name := <-recentClicked:
+			label.SetText(name + " clicked")
+		case <-AppQuit:
+			return
+		case <-about.Clicked:
+			label.SetText("About clicked")
+		case <-prefs.Clicked:
+			label.SetText("Preferences clicked")
+		case <-wrap.Clicked:
+			label.SetText(fmt.Sprintf("Word Wrap: %v", wrap.Checked()))
+		case <-status.Clicked:
+			label.SetText(fmt.Sprintf("Status Bar: %v", status.Checked()))
+		case <-small.Clicked:
+		case <-medium.Clicked:
+		case <-large.Clicked:
+		case <-deep.Clicked:
+			label.SetText(deep.Text() + " clicked")
+		case <-undo.Clicked:
+			lock.Lock()
+			typed--
+			lock.Unlock()
+			label.SetText("undone")
+		case <-replace.Clicked:
+			// the old menu bar had these, but they can only be in one Menu, so make new ones
+			quit = NewQuitMenuItem()
+			wrap = NewCheckMenuItem("Word Wrap", wrap.Checked())
+			status = NewCheckMenuItem("Status Bar", status.Checked())
+			deep = NewMenuItem("Deep && Deeper")
+			small = NewRadioMenuItem("Small", small.Checked())
+			medium = NewRadioMenuItem("Medium", medium.Checked())
+			large = NewRadioMenuItem("Large", large.Checked())
+			undo = NewMenuItem("Undo")
+			recent = NewMenu()
+			open, bar = newItems()
+			w.SetMenuBar(bar)
+			label.SetText("replaced")
+		case <-remove.Clicked:
+			w.SetMenuBar(nil)
+			label.SetText("removed")
+		case <-toggleOpen.Clicked:
+			open.SetEnabled(!open.Enabled())
+			label.SetText(fmt.Sprintf("Open enabled: %v", open.Enabled()))
+		case <-rename.Clicked:
+			deep.SetText(deep.Text() + " && Deeper")
+		case <-check.Clicked:
+			small.SetChecked(true)
+		case <-typing.Clicked:
+			lock.Lock()
+			typed++
+			n := typed
+			lock.Unlock()
+			// not with lock held: the UI thread might be waiting for it in a SetOpening() function, and then it couldn't run SetText()
+			label.SetText(fmt.Sprintf("typed %d", n))
+		case <-w.Closing:
+			return
+		}
+		label.SetText(label.Text() + fmt.Sprintf(" [small %v medium %v large %v]", small.Checked(), medium.Checked(), large.Checked()))
+	}
+}
+
+var toolbarTest = flag.Bool("toolbar", false, "run Toolbar and Window.SetToolbar() test instead")
+func toolbarLoop() {
+	icon := func(c color.NRGBA) image.Image {
+		img := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+		draw.Draw(img, image.Rect(4, 4, 28, 28), image.NewUniform(c), image.ZP, draw.Src)
+		return img
+	}
+	newb := NewToolbarButton("New", icon(color.NRGBA{0, 128, 0, 255}), "Make a new thing")
+	open := NewToolbarButton("Open", icon(color.NRGBA{0, 0, 192, 255}), "")
+	textonly := NewToolbarButton("Text Only", nil, "This one has no icon")
+	bold := NewToolbarToggle("Bold", icon(color.NRGBA{0, 0, 0, 255}), "Toggle bold", true)
+	italic := NewToolbarToggle("Italic", icon(color.NRGBA{128, 128, 128, 128}), "Toggle italic (half transparent icon)", false)
+	tb := NewToolbar(newb, open, textonly, NewToolbarSeparator(), bold, italic)
+	toggleOpen := NewButton("Enable/Disable Open")
+	checkItalic := NewButton("Check/Uncheck Italic")
+	label := NewLabel("")
+	w := NewWindow("Toolbar Test", 400, 200)
+	w.SetToolbar(tb)
+	w.Open(NewVerticalStack(toggleOpen, checkItalic, label))
+	for {
+		select {
+		case <-newb.Clicked:
+			label.SetText("New clicked")
+		case <-open.Clicked:
+			label.SetText("Open clicked")
+		case <-textonly.Clicked:
+			label.SetText("Text Only clicked")
+		case <-bold.Clicked:
+			label.SetText(fmt.Sprintf("Bold clicked; checked %v", bold.Checked()))
+		case <-italic.Clicked:
+			label.SetText(fmt.Sprintf("Italic clicked; checked %v", italic.Checked()))
+		case <-toggleOpen.Clicked:
+			open.SetEnabled(!open.Enabled())
+			label.SetText(fmt.Sprintf("Open enabled: %v", open.Enabled()))
+		case <-checkItalic.Clicked:
+			italic.SetChecked(!italic.Checked())
+			label.SetText(fmt.Sprintf("Italic checked: %v", italic.Checked()))
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var statusBarTest = flag.Bool("statusbar", false, "run StatusBar and Window.SetStatusBar() test instead")
+func statusBarLoop() {
+	sb := NewStatusBar("Ready", "Line 1", "INS")
+	pbar := NewProgressBar()
+	sb.SetProgressBar(pbar)
+	work := NewButton("Do Some Work")
+	line := NewButton("Next Line")
+	ins := NewButton("Toggle INS/OVR")
+	w := NewWindow("StatusBar Test", 400, 200)
+	w.SetStatusBar(sb)
+	w.Open(NewVerticalStack(work, line, ins))
+	done := make(chan struct{})
+	lines := 1
+	for {
+		select {
+		case <-work.Clicked:
+			go func() {
+				for i := 0; i <= 100; i += 5 {
+					sb.SetText(0, fmt.Sprintf("Working... %d%%", i))
+					pbar.SetProgress(i)
+					time.Sleep(100 * time.Millisecond)
+				}
+				done <- struct{}{}
+			}()
+		case <-done:
+			sb.SetText(0, "Done")
+			pbar.SetProgress(0)
+		case <-line.Clicked:
+			lines *= 10
+			sb.SetText(1, fmt.Sprintf("Line %d", lines))
+		case <-ins.Clicked:
+			if sb.Text(2) == "INS" {
+				sb.SetText(2, "OVR")
+			} else {
+				sb.SetText(2, "INS")
+			}
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var contextMenuTest = flag.Bool("contextmenu", false, "run SetContextMenu() test instead")
+func contextMenuLoop() {
+	b := NewButton("Right-click Me")
+	l := NewLineEdit("right-click this text too")
+	lb := NewListbox("Right-click", "this", "Listbox")
+	label := NewLabel("")
+	swap := NewButton("Swap the Button's menu")
+	remove := NewButton("Take away the LineEdit's menu")
+	hello := NewMenuItem("Say Hello")
+	bold := NewCheckMenuItem("Bold", false)
+	SetContextMenu(b, NewMenu(hello, NewMenuSeparator(), bold))
+	var lock sync.Mutex // for opened, which the SetOpening() function changes on the UI thread
+	opened := 0
+	count := NewMenuItem("")
+	clear := NewMenuItem("Clear")
+	lm := NewMenu(count, clear)
+	lm.SetOpening(func() {
+		lock.Lock()
+		defer lock.Unlock()
+		opened++
+		count.SetText(fmt.Sprintf("Opened %d times", opened))
+	})
+	SetContextMenu(l, lm)
+	first := NewMenuItem("Listbox Item")
+	SetContextMenu(lb, NewMenu(first))
+	w := NewWindow("Context Menu Test", 320, 300)
+	s := NewVerticalStack(b, l, lb, swap, remove, label)
+	s.SetStretchy(2)
+	w.Open(s)
+	bye := make(chan struct{})
+	for {
+		select {
+		case <-hello.Clicked:
+			label.SetText("Hello!")
+		case <-bold.Clicked:
+			label.SetText(fmt.Sprintf("Bold: %v", bold.Checked()))
+		case <-count.Clicked:
+			label.SetText(count.Text())
+		case <-clear.Clicked:
+			l.SetText("")
+		case <-first.Clicked:
+			label.SetText(fmt.Sprintf("Listbox selection: %q", lb.Selection()))
+		case <-swap.Clicked:
+			// hello and bold are destroyed along with their old menu
+			goodbye := NewMenuItem("Say Goodbye")
+			go func(i *MenuItem) {
+				for range i.Clicked {
+					bye <- struct{}{}
+				}
+			}(goodbye)
+			SetContextMenu(b, NewMenu(goodbye))
+			label.SetText("swapped")
+		case <-bye:
+			label.SetText("Goodbye!")
+		case <-remove.Clicked:
+			SetContextMenu(l, nil)
+			label.SetText("the LineEdit has its own menu again")
+		case <-w.Closing:
+			return
+		}
+	}
+}
+
+var windowMenuTest = flag.Bool("windowmenu", false, "run NewWindowMenu() test instead")
+func windowMenuLoop() {
+	opened := make(chan struct{})
+	closed := make(chan *Window)
+	n, count := 0, 0
+	newWindow := func() {
+		n++
+		count++
+		newItem := NewMenuItem("New Window")
+		w := NewWindow(fmt.Sprintf("Document %d", n), 320, 120)
+		w.SetMenuBar(NewMenu(NewSubmenu("File", NewMenu(newItem)), NewWindowMenu()))
+		hide := NewButton("Hide for 3 Seconds")
+		w.Open(NewVerticalStack(NewLabel("Open a few windows and switch between them with the Window menu"), hide))
+		go func() {
+			for {
+				select {
+				case <-newItem.Clicked:
+					opened <- struct{}{}
+				case <-hide.Clicked:
+					// a hidden Window shouldn't be in the list
+					w.Hide()
+					time.AfterFunc(3*time.Second, w.Show)
+				case <-w.Closing:
+					closed <- w
+					return
+				}
+			}
+		}()
+	}
+	newWindow()
+	for count != 0 {
+		select {
+		case <-opened:
+			newWindow()
+		case w := <-closed:
+			w.Destroy()
+			count--
+		}
+	}
+}
+
 var dialogTest = flag.Bool("dialog", false, "add Window.MsgBox() channel test window")
 var labelAlignTest = flag.Bool("label", false, "show Label Alignment test window")
 var spacingTest = flag.Bool("spacing", false, "margins and padding on Window")
+var timings = flag.Bool("timings", false, "print startup timings after opening the main window")
 
 func myMain() {
 	if *spacetest != "" {
@@ -301,6 +2053,178 @@ func myMain() {
 		areaboundsTest()
 		return
 	}
+	if *destroyControlTest {
+		destroyControlLoop()
+		return
+	}
+	if *destroyTest {
+		destroyLoop()
+		return
+	}
+	if *logviewTest {
+		logviewLoop()
+		return
+	}
+	if *levelmeterTest {
+		levelmeterLoop()
+		return
+	}
+	if *bannerTest {
+		bannerLoop()
+		return
+	}
+	if *busyTest {
+		busyLoop()
+		return
+	}
+	if *nearEndTest {
+		nearEndLoop()
+		return
+	}
+	if *filterTest {
+		filterLoop()
+		return
+	}
+	if *progressTest {
+		progressLoop()
+		return
+	}
+	if *credentialsTest {
+		credentialsLoop()
+		return
+	}
+	if *preferencesTest {
+		preferencesLoop()
+		return
+	}
+	if *blockTest {
+		blockLoop()
+		return
+	}
+	if *colorTest {
+		colorLoop()
+		return
+	}
+	if *panicReportTest {
+		panicReportLoop()
+		return
+	}
+	if *gridNavTest {
+		gridNavLoop()
+		return
+	}
+	if *validateTest {
+		validateLoop()
+		return
+	}
+	if *historyTest {
+		historyLoop()
+		return
+	}
+	if *keyboardTest {
+		keyboardLoop()
+		return
+	}
+	if *clipboardTest {
+		clipboardLoop()
+		return
+	}
+	if *paletteTest {
+		paletteLoop()
+		return
+	}
+	if *settingsTest {
+		settingsLoop()
+		return
+	}
+	if *relayoutTest {
+		relayoutLoop()
+		return
+	}
+	if *orientationTest {
+		orientationLoop()
+		return
+	}
+	if *tabTest {
+		tabLoop()
+		return
+	}
+	if *groupTest {
+		groupLoop()
+		return
+	}
+	if *formTest {
+		formLoop()
+		return
+	}
+	if *treeTest {
+		treeLoop()
+		return
+	}
+	if *sliderTest {
+		sliderLoop()
+		return
+	}
+	if *bidiTest {
+		bidiLoop()
+		return
+	}
+	if *localeTest {
+		localeLoop()
+		return
+	}
+	if *radioTest {
+		radioLoop()
+		return
+	}
+	if *groupBoxTest {
+		groupBoxLoop()
+		return
+	}
+	if *dateTimeTest {
+		dateTimeLoop()
+		return
+	}
+	if *colorButtonTest {
+		colorButtonLoop()
+		return
+	}
+	if *caretTest {
+		caretLoop()
+		return
+	}
+	if *highContrastTest {
+		highContrastLoop()
+		return
+	}
+	if *animationsTest {
+		animationsLoop()
+		return
+	}
+	if *fontTest {
+		fontLoop()
+		return
+	}
+	if *menuTest {
+		menuLoop()
+		return
+	}
+	if *toolbarTest {
+		toolbarLoop()
+		return
+	}
+	if *statusBarTest {
+		statusBarLoop()
+		return
+	}
+	if *contextMenuTest {
+		contextMenuLoop()
+		return
+	}
+	if *windowMenuTest {
+		windowMenuLoop()
+		return
+	}
 	w := NewWindow("Main Window", 320, 240)
 	b := NewButton("Click Me")
 	b2 := NewButton("Or Me")
@@ -310,8 +2234,13 @@ func myMain() {
 	c := NewCheckbox("Check Me")
 	cb1 := NewEditableCombobox("You can edit me!", "Yes you can!", "Yes you will!")
 	cb2 := NewCombobox("You can't edit me!", "No you can't!", "No you won't!")
+	cb2.AppendSeparator()
+	cb2.AppendHeader("You can't pick me either")
+	cb2.Append("But you can pick me")
 	e := NewLineEdit("Enter text here too")
 	l := NewLabel("This is a label")
+	l.SetBuddy(e)
+	l.SetSelectable(true)
 	resetl := func() {
 		l.SetText("This is a label")
 	}
@@ -342,6 +2271,8 @@ func myMain() {
 	doAdjustments()
 	cb1.Append("append multi 1", "append multi 2")
 	lb2.Append("append multi 1", "append multi 2")
+	lb1.Select(0, 2)
+	cb2.SetSelected(1)
 	s1 := NewVerticalStack(lb2, lb1)
 	s1.SetStretchy(0)
 	s1.SetStretchy(1)
@@ -353,6 +2284,9 @@ func myMain() {
 	}
 	w.SetSpaced(*spacingTest)
 	w.Open(s)
+	if *timings {
+		fmt.Printf("%+v\n", StartupTimings())
+	}
 	if *gridtest {
 		gridWindow()
 	}
@@ -443,6 +2377,19 @@ _=curtime
 			if lb2.Len() > 4 {
 				lb2.Delete(4)
 			}
+			lb1.ClearSelection()
+			lb2.Select(lb2.Len() - 1)
+			cb1.SetSelected(cb1.Len() - 1)
+			cb2.SetSelected(-1)
+			c.SetChecked(!c.Checked())
+		case <-lb1.SelectionChanged:
+			println("lb1 selection changed", fmt.Sprint(lb1.SelectedIndices()))
+		case <-lb2.SelectionChanged:
+			println("lb2 selection changed", fmt.Sprint(lb2.SelectedIndices()))
+		case <-lb1.Activated:
+			println("lb1 activated", fmt.Sprint(lb1.SelectedIndices()))
+		case <-lb2.Activated:
+			println("lb2 activated", fmt.Sprint(lb2.SelectedIndices()))
 		case <-b3.Clicked:
 			f := MsgBox
 			if c.Checked() {
@@ -460,8 +2407,10 @@ _=curtime
 				prog = 100
 			}
 			pbar.SetProgress(prog)
+			pbar.SetText(fmt.Sprintf("%d of 100", prog))
 			cb1.Append("append multi 1", "append multi 2")
 			lb2.Append("append multi 1", "append multi 2")
+			b3.SetBadge(fmt.Sprint(lb2.Len()))
 		case <-decButton.Clicked:
 			prog--
 			if prog < 0 {
@@ -492,8 +2441,17 @@ _=curtime
 	w.Hide()
 }
 
+// filled in by the files only built with -tags uidebug
+var debugSetup []func()
+
 func main() {
 	flag.Parse()
+	for _, f := range debugSetup {
+		f()
+	}
+	SetErrorHandler(func(err *Error) {
+		fmt.Printf("ui error: %v (code %d)\n", err, err.Code)
+	})
 	err := Go(myMain)
 	if err != nil {
 		panic(err)
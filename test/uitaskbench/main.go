@@ -0,0 +1,84 @@
+// +build uidebug
+
+// 10 july 2014
+package main
+
+import (
+	"fmt"
+	"flag"
+	"sync"
+	"time"
+	. "github.com/andlabs/ui"
+)
+
+// this measures how many small calls a second get to the UI thread; build with -tags uidebug
+// it compares the uitask queue (see uitask.go) against the dispatcher it replaced, where a goroutine took one function at a time off a channel and waited for the system to run it before taking the next, so every call woke the UI thread on its own
+// the dispatcher is rebuilt here on top of DebugUitask(), which waits for each function the way it did; that costs the same number of UI thread wakeups, so it's a fair stand-in
+
+var goroutines = flag.Int("goroutines", 8, "number of goroutines making calls at once")
+var calls = flag.Int("calls", 20000, "number of calls each goroutine makes")
+
+// each call does about as much as setting a ProgressBar's value would on the Go side
+var counter int
+
+func work() {
+	counter++
+}
+
+// the old dispatcher
+var olduitask = make(chan func())
+
+func olddispatcher() {
+	for f := range olduitask {
+		DebugUitask(f)
+	}
+}
+
+func oldcall() {
+	done := make(chan struct{})
+	olduitask <- func() {
+		work()
+		close(done)
+	}
+	<-done
+}
+
+func run(name string, call func()) {
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for i := 0; i < *goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			for j := 0; j < *calls; j++ {
+				call()
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	n := *goroutines * *calls
+	fmt.Printf("%s: %d calls from %d goroutines in %v: %v per call, %.0f calls per second\n",
+		name, n, *goroutines, elapsed,
+		elapsed / time.Duration(n),
+		float64(n) / elapsed.Seconds())
+}
+
+func myMain() {
+	DebugUitask(work)		// start up the system first so it isn't timed
+	go olddispatcher()
+	run("channel dispatcher", oldcall)
+	run("uitask queue", func() {
+		DebugUitask(work)
+	})
+	close(olduitask)
+}
+
+func main() {
+	flag.Parse()
+	err := Go(myMain)
+	if err != nil {
+		panic(err)
+	}
+}
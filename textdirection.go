@@ -0,0 +1,27 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+)
+
+// TextDirection says which way the text of a LineEdit, Label, or LogView runs as a whole; see their SetTextDirection methods.
+// It decides which side the text starts from and lines up against, and which way runs of text without letters of their own (digits and punctuation, most of all) go.
+// The words of each script within the text run their own way regardless; see "Bidirectional Text" in the package documentation.
+// On GTK+, a paragraph that starts with a letter still runs the way that letter does, whatever its TextDirection; the TextDirection still decides the rest.
+type TextDirection uintptr
+
+const (
+	// TextDirectionNatural leaves the direction to the system, as if SetTextDirection was never called.
+	// GTK+ and Mac OS X go by the text itself (or the user's language, for text with no letters); Windows goes left to right.
+	TextDirectionNatural TextDirection = iota
+	TextDirectionLeftToRight
+	TextDirectionRightToLeft
+)
+
+func checkTextDirection(d TextDirection, what string) {
+	if d > TextDirectionRightToLeft {
+		panic(fmt.Errorf("invalid TextDirection %d given to %s.SetTextDirection()", d, what))
+	}
+}
@@ -0,0 +1,16 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// NSTextField (LineEdits and Labels) and NSTextView (LogViews) both take a base writing direction, which Cocoa's text system uses for the paragraph as a whole; see textdirection_darwin.m
+// called by sysData.make() on the UI thread
+func (s *sysData) applyTextDirection() {
+	text := s.id
+	if s.ctype == c_logview { // in a scroll view; see logview_darwin.go
+		text = getScrollViewContent(s.id)
+	}
+	C.setTextDirection(text, toBOOL(s.textDirection == TextDirectionRightToLeft))
+}
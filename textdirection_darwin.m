@@ -0,0 +1,18 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSText.h>
+#import <AppKit/NSParagraphStyle.h>
+
+// both NSControl and NSText have these methods, so text can be either
+void setTextDirection(id text, BOOL rtl)
+{
+	NSWritingDirection dir;
+
+	dir = NSWritingDirectionLeftToRight;
+	if (rtl)
+		dir = NSWritingDirectionRightToLeft;
+	[text setBaseWritingDirection:dir];
+	// natural alignment follows the writing direction; left alignment (which a new control may have) doesn't
+	[text setAlignment:NSNaturalTextAlignment];
+}
@@ -0,0 +1,22 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+// #include "gtk_unix.h"
+import "C"
+
+// GTK+ widgets flip their alignment (and GtkScrolledWindow, its scrollbar) for right-to-left; the text itself is laid out by Pango, which picks the direction of each paragraph from its first letter and only uses the widget's direction for text without one
+// called by sysData.make() on the UI thread
+func (s *sysData) applyTextDirection() {
+	dir := C.GtkTextDirection(C.GTK_TEXT_DIR_LTR)
+	if s.textDirection == TextDirectionRightToLeft {
+		dir = C.GTK_TEXT_DIR_RTL
+	}
+	C.gtk_widget_set_direction(s.widget, dir)
+	// gtk_widget_set_direction() doesn't go down to the GtkTextView of a LogView, which is inside a GtkScrolledWindow
+	if s.ctype == c_logview {
+		C.gtk_widget_set_direction(gLogViewTextView(s.widget), dir)
+	}
+}
@@ -0,0 +1,228 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"image"
+	"sync"
+)
+
+// A Toolbar is a row of ToolbarItems along the top of a Window, above its controls (and below its menu bar, if any); see Window.SetToolbar.
+// Each ToolbarItem is a button, a toggle button, or a separator, with some text, an icon, and a tooltip.
+//
+// On Mac OS X, the Toolbar is the Window's toolbar, in the title bar area, which the user can hide with the View menu of programs that have one.
+type Toolbar struct {
+	lock       sync.Mutex
+	used       bool
+	items      []*ToolbarItem
+	sysToolbar // see toolbar_windows.go, toolbar_unix.go, and toolbar_darwin.go
+}
+
+// NewToolbar creates a new Toolbar with the given ToolbarItems, in order.
+// It panics if one of the ToolbarItems is already in a Toolbar.
+func NewToolbar(items ...*ToolbarItem) *Toolbar {
+	t := &Toolbar{
+		items: items,
+	}
+	for _, i := range items {
+		i.lock.Lock()
+		if i.toolbar != nil {
+			i.lock.Unlock()
+			panic(fmt.Errorf("ToolbarItem %q passed to NewToolbar() is already in a Toolbar", i.text))
+		}
+		i.toolbar = t
+		i.lock.Unlock()
+	}
+	return t
+}
+
+// SetToolbar gives the Window the Toolbar t, replacing the one given before, if any; pass nil to have none.
+// This function cannot be called after the Window has been created, and panics if t is already in a Window.
+func (w *Window) SetToolbar(t *Toolbar) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.created {
+		panic("Window.SetToolbar() called after the Window has been created")
+	}
+	if t != nil {
+		t.lock.Lock()
+		if t.used {
+			t.lock.Unlock()
+			panic("Toolbar passed to Window.SetToolbar() is already used")
+		}
+		t.used = true
+		t.lock.Unlock()
+	}
+	if w.initToolbar != nil {
+		w.initToolbar.lock.Lock()
+		w.initToolbar.used = false
+		w.initToolbar.lock.Unlock()
+	}
+	w.initToolbar = t
+}
+
+// called by Window.Create() with the Window locked
+func (s *sysData) makeToolbar(t *Toolbar) {
+	for _, i := range t.items {
+		i.lock.Lock()
+		i.created = true
+		i.lock.Unlock()
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		s.toolbar = t
+		s.sysMakeToolbar(t)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+type toolbarItemKind int
+
+const (
+	toolbarButton toolbarItemKind = iota
+	toolbarToggle
+	toolbarSeparator
+)
+
+// A ToolbarItem is an item of a Toolbar.
+// Once the Window with its Toolbar is created, a ToolbarItem is created along with it; until then, its methods just remember what to show.
+type ToolbarItem struct {
+	// Clicked is signaled when the user clicks the ToolbarItem; for a toggle button, that's after it's been checked or unchecked.
+	// It is never signaled for separators.
+	Clicked chan struct{}
+
+	lock    sync.Mutex
+	created bool
+	toolbar *Toolbar
+	kind    toolbarItemKind
+	text    string
+	icon    *image.NRGBA // nil for none
+	tooltip string
+
+	// only touched on the UI thread once created
+	checked  bool
+	disabled bool
+	sysToolbarItem
+}
+
+func newToolbarItem(kind toolbarItemKind, text string, icon image.Image, tooltip string) *ToolbarItem {
+	i := &ToolbarItem{
+		Clicked: newEvent(),
+		kind:    kind,
+		text:    text,
+		tooltip: tooltip,
+	}
+	if icon != nil {
+		i.icon = toClipboardImage(icon) // which makes an *image.NRGBA at (0,0), or nil for an empty image
+	}
+	return i
+}
+
+// NewToolbarButton creates a new ToolbarItem that the user can click, with the given text, icon, and tooltip.
+// The icon can be nil, for none; otherwise, it is scaled to the size the system uses for toolbar icons (16x16 on Windows, 24x24 on GTK+, and 16x16 points on Mac OS X), so it should be square, and at least that big.
+// The tooltip can be empty, for none.
+func NewToolbarButton(text string, icon image.Image, tooltip string) *ToolbarItem {
+	return newToolbarItem(toolbarButton, text, icon, tooltip)
+}
+
+// NewToolbarToggle creates a new ToolbarItem that stays pushed in (checked) when clicked, until it's clicked again, starting checked if checked is true.
+// The text, icon, and tooltip are as for NewToolbarButton.
+func NewToolbarToggle(text string, icon image.Image, tooltip string, checked bool) *ToolbarItem {
+	i := newToolbarItem(toolbarToggle, text, icon, tooltip)
+	i.checked = checked
+	return i
+}
+
+// NewToolbarSeparator creates a new ToolbarItem that is a gap between the ToolbarItems around it.
+func NewToolbarSeparator() *ToolbarItem {
+	return newToolbarItem(toolbarSeparator, "", nil, "")
+}
+
+// Checked returns whether a ToolbarItem made with NewToolbarToggle is checked; it returns false for every other ToolbarItem.
+func (i *ToolbarItem) Checked() bool {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if !i.created {
+		return i.checked
+	}
+	ret := make(chan bool)
+	defer close(ret)
+	uitask(func() {
+		ret <- i.checked
+	})
+	return <-ret
+}
+
+// SetChecked checks or unchecks a ToolbarItem made with NewToolbarToggle, without signaling Clicked.
+// It does nothing for every other ToolbarItem.
+func (i *ToolbarItem) SetChecked(checked bool) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if i.kind != toolbarToggle {
+		return
+	}
+	if !i.created {
+		i.checked = checked
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		i.checked = checked
+		i.sysSetChecked()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// Enabled returns whether the ToolbarItem is enabled; ToolbarItems start enabled.
+func (i *ToolbarItem) Enabled() bool {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if !i.created {
+		return !i.disabled
+	}
+	ret := make(chan bool)
+	defer close(ret)
+	uitask(func() {
+		ret <- !i.disabled
+	})
+	return <-ret
+}
+
+// SetEnabled enables or disables the ToolbarItem; the user can't click a disabled ToolbarItem.
+func (i *ToolbarItem) SetEnabled(enabled bool) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if !i.created {
+		i.disabled = !enabled
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		i.disabled = !enabled
+		i.sysSetEnabled()
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// called by the platform code on the UI thread when the user clicks the ToolbarItem; a toggle button has already checked or unchecked itself by then
+func (i *ToolbarItem) clicked() {
+	switch i.kind {
+	case toolbarToggle:
+		i.checked = !i.checked
+	case toolbarSeparator:
+		return
+	}
+	sendEvent(i.Clicked)
+}
@@ -0,0 +1,52 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "objc_darwin.h"
+import "C"
+
+// see toolbar_darwin.m
+
+type sysToolbar struct {
+	id C.id
+}
+
+type sysToolbarItem struct {
+	button C.id // nil for separators
+}
+
+// runs on the UI thread
+func (s *sysData) sysMakeToolbar(t *Toolbar) {
+	t.id = C.makeToolbar()
+	for n, i := range t.items {
+		var text, tooltip C.id
+		var png unsafe.Pointer
+		var b []byte
+
+		text = toNSString(i.text)
+		if i.tooltip != "" {
+			tooltip = toNSString(i.tooltip)
+		}
+		if i.icon != nil {
+			b = toClipboardPNG(i.icon)
+			if len(b) != 0 {
+				png = unsafe.Pointer(&b[0])
+			}
+		}
+		i.button = C.toolbarAppend(t.id, C.intptr_t(i.kind), text, png, C.intptr_t(len(b)), tooltip,
+			toBOOL(i.checked), toBOOL(!i.disabled), C.intptr_t(n), appDelegate)
+	}
+	C.windowSetToolbar(s.id, t.id)
+}
+
+func (i *ToolbarItem) sysSetChecked() {
+	C.toolbarButtonSetChecked(i.button, toBOOL(i.checked))
+}
+
+func (i *ToolbarItem) sysSetEnabled() {
+	C.toolbarButtonSetEnabled(i.button, toBOOL(!i.disabled))
+}
@@ -0,0 +1,146 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSToolbar.h>
+#import <AppKit/NSToolbarItem.h>
+#import <AppKit/NSButton.h>
+#import <AppKit/NSButtonCell.h>
+#import <AppKit/NSImage.h>
+#import <AppKit/NSWindow.h>
+#import <Foundation/NSArray.h>
+#import <Foundation/NSDictionary.h>
+#import <Foundation/NSData.h>
+
+/*
+A Toolbar is the NSToolbar of the Window; NSToolbar asks its delegate for its items, so goToolbarDelegate hands out the ones we make up front.
+Each button is an NSButton in an NSToolbarItem, rather than a plain NSToolbarItem, because plain ones can't stay pushed in the way toggle buttons do; its tag is its index in the Toolbar, which the app delegate's toolbarItemClicked: passes on.
+The NSButton shows the icon, and the NSToolbarItem shows the text under it; without an icon, the NSButton shows the text instead.
+NSToolbar doesn't retain its delegate, so the delegate lives as long as the program; there's one per Window with a Toolbar.
+*/
+
+#define toNSToolbar(x) ((NSToolbar *) (x))
+#define toNSButton(x) ((NSButton *) (x))
+
+@interface goToolbarDelegate : NSObject <NSToolbarDelegate> {
+@public
+	NSMutableArray *identifiers;
+	NSMutableDictionary *items;
+}
+@end
+
+@implementation goToolbarDelegate
+
+- (NSToolbarItem *)toolbar:(NSToolbar *)toolbar itemForItemIdentifier:(NSString *)identifier willBeInsertedIntoToolbar:(BOOL)flag
+{
+	return [items objectForKey:identifier];
+}
+
+- (NSArray *)toolbarAllowedItemIdentifiers:(NSToolbar *)toolbar
+{
+	return identifiers;
+}
+
+- (NSArray *)toolbarDefaultItemIdentifiers:(NSToolbar *)toolbar
+{
+	return identifiers;
+}
+
+@end
+
+// the kinds of toolbarItemKind in toolbar.go
+enum {
+	toolbarButton,
+	toolbarToggle,
+	toolbarSeparator,
+};
+
+id makeToolbar(void)
+{
+	static uintptr_t n = 0;
+	NSToolbar *toolbar;
+	goToolbarDelegate *d;
+
+	// the identifier is shared by all toolbars with the same items in the same program; ours are all different
+	toolbar = [[NSToolbar alloc] initWithIdentifier:[NSString stringWithFormat:@"goToolbar%lu", (unsigned long) n++]];
+	[toolbar setAllowsUserCustomization:NO];
+	[toolbar setAutosavesConfiguration:NO];
+	[toolbar setDisplayMode:NSToolbarDisplayModeIconAndLabel];
+	d = [goToolbarDelegate new];
+	d->identifiers = [NSMutableArray new];
+	d->items = [NSMutableDictionary new];
+	[toolbar setDelegate:d];
+	return toolbar;
+}
+
+// returns the NSButton, or nil for a separator
+id toolbarAppend(id toolbar, intptr_t kind, id text, void *png, intptr_t pngLen, id tooltip, BOOL checked, BOOL enabled, intptr_t tag, id target)
+{
+	goToolbarDelegate *d;
+	NSString *identifier;
+	NSToolbarItem *item;
+	NSButton *button;
+	NSImage *image = nil;
+
+	d = (goToolbarDelegate *) [toNSToolbar(toolbar) delegate];
+	if (kind == toolbarSeparator) {
+		[d->identifiers addObject:NSToolbarSeparatorItemIdentifier];
+		return nil;
+	}
+	identifier = [NSString stringWithFormat:@"%ld", (long) tag];
+	item = [[NSToolbarItem alloc] initWithItemIdentifier:identifier];
+	button = [[NSButton alloc] initWithFrame:NSZeroRect];
+	[button setBezelStyle:NSTexturedRoundedBezelStyle];
+	if (kind == toolbarToggle) {
+		[button setButtonType:NSPushOnPushOffButton];
+		if (checked)
+			[button setState:NSOnState];
+	}
+	if (png != NULL)
+		image = [[NSImage alloc] initWithData:[NSData dataWithBytes:png length:(NSUInteger) pngLen]];
+	if (image != nil) {
+		[image setSize:NSMakeSize(16, 16)];
+		[button setImage:image];
+		[button setImagePosition:NSImageOnly];
+		[image release];		// the button has it now
+		[item setLabel:((NSString *) text)];
+	} else
+		[button setTitle:((NSString *) text)];
+	[button sizeToFit];
+	[button setTarget:target];
+	[button setAction:@selector(toolbarItemClicked:)];
+	[button setTag:(NSInteger) tag];
+	[button setEnabled:enabled];
+	if (tooltip != nil) {
+		[button setToolTip:((NSString *) tooltip)];
+		[item setToolTip:((NSString *) tooltip)];
+	}
+	[item setPaletteLabel:((NSString *) text)];
+	[item setView:button];
+	[item setMinSize:[button frame].size];
+	[item setMaxSize:[button frame].size];
+	[button release];		// the item has it now
+	[d->items setObject:item forKey:identifier];
+	[item release];		// the dictionary has it now
+	[d->identifiers addObject:identifier];
+	return button;
+}
+
+// once all the items are in
+void windowSetToolbar(id window, id toolbar)
+{
+	[((NSWindow *) window) setToolbar:toNSToolbar(toolbar)];
+	[toNSToolbar(toolbar) release];		// the window has it now
+}
+
+void toolbarButtonSetChecked(id button, BOOL checked)
+{
+	if (checked)
+		[toNSButton(button) setState:NSOnState];
+	else
+		[toNSButton(button) setState:NSOffState];
+}
+
+void toolbarButtonSetEnabled(id button, BOOL enabled)
+{
+	[toNSButton(button) setEnabled:enabled];
+}
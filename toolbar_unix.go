@@ -0,0 +1,102 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern void our_toolbar_item_clicked_callback(GtkToolButton *, gpointer);
+// static inline void gtkToolbarItemConnect(GtkToolItem *item, gpointer data)
+// {
+// 	g_signal_connect(item, "clicked", G_CALLBACK(our_toolbar_item_clicked_callback), data);
+// }
+import "C"
+
+/*
+A Toolbar is a GtkToolbar, packed above the layout of the Window in its box, like the menu bar (see menu_unix.go); it is made before the menu bar, which goes above it.
+Buttons are GtkToolButtons and toggle buttons are GtkToggleToolButtons, which check and uncheck themselves before "clicked"; both show their text under their icon.
+*/
+
+type sysToolbar struct {
+	widget *C.GtkWidget
+}
+
+type sysToolbarItem struct {
+	item *C.GtkToolItem
+}
+
+// runs on the UI thread
+func (s *sysData) sysMakeToolbar(t *Toolbar) {
+	t.widget = C.gtk_toolbar_new()
+	toolbar := (*C.GtkToolbar)(unsafe.Pointer(t.widget))
+	C.gtk_toolbar_set_style(toolbar, C.GTK_TOOLBAR_BOTH)
+	for _, i := range t.items {
+		i.make()
+		C.gtk_toolbar_insert(toolbar, i.item, -1)
+	}
+	box := C.gtk_bin_get_child((*C.GtkBin)(unsafe.Pointer(s.widget)))
+	C.gtk_box_pack_start((*C.GtkBox)(unsafe.Pointer(box)), t.widget, C.FALSE, C.FALSE, 0)
+	C.gtk_box_reorder_child((*C.GtkBox)(unsafe.Pointer(box)), t.widget, 0)
+	C.gtk_widget_show_all(t.widget)
+}
+
+func (i *ToolbarItem) make() {
+	if i.kind == toolbarSeparator {
+		i.item = C.gtk_separator_tool_item_new()
+		return
+	}
+	if i.kind == toolbarToggle {
+		i.item = C.gtk_toggle_tool_button_new()
+		i.sysSetChecked()
+	} else {
+		i.item = C.gtk_tool_button_new(nil, nil)
+	}
+	button := (*C.GtkToolButton)(unsafe.Pointer(i.item))
+	ctext := C.CString(i.text)
+	defer C.free(unsafe.Pointer(ctext))
+	C.gtk_tool_button_set_label(button, togstr(ctext))
+	if i.icon != nil {
+		if pixbuf := toPixbuf(i.icon); pixbuf != nil {
+			var width, height C.gint
+
+			C.gtk_icon_size_lookup(C.GTK_ICON_SIZE_LARGE_TOOLBAR, &width, &height)
+			scaled := C.gdk_pixbuf_scale_simple(pixbuf, width, height, C.GDK_INTERP_BILINEAR)
+			C.g_object_unref(C.gpointer(unsafe.Pointer(pixbuf)))
+			C.gtk_tool_button_set_icon_widget(button, C.gtk_image_new_from_pixbuf(scaled))
+			C.g_object_unref(C.gpointer(unsafe.Pointer(scaled))) // the GtkImage has it now
+		}
+	}
+	if i.tooltip != "" {
+		ctooltip := C.CString(i.tooltip)
+		defer C.free(unsafe.Pointer(ctooltip))
+		C.gtk_tool_item_set_tooltip_text(i.item, togstr(ctooltip))
+	}
+	if i.disabled {
+		i.sysSetEnabled()
+	}
+	C.gtkToolbarItemConnect(i.item, C.gpointer(unsafe.Pointer(i)))
+}
+
+func (i *ToolbarItem) sysSetChecked() {
+	// this emits "clicked" if the button changes; i.checked already says the same by then, which our_toolbar_item_clicked_callback() takes to mean it wasn't the user
+	C.gtk_toggle_tool_button_set_active((*C.GtkToggleToolButton)(unsafe.Pointer(i.item)), togbool(i.checked))
+}
+
+func (i *ToolbarItem) sysSetEnabled() {
+	C.gtk_widget_set_sensitive((*C.GtkWidget)(unsafe.Pointer(i.item)), togbool(!i.disabled))
+}
+
+//export our_toolbar_item_clicked_callback
+func our_toolbar_item_clicked_callback(button *C.GtkToolButton, data C.gpointer) {
+	defer recoverUIPanic()
+	i := (*ToolbarItem)(unsafe.Pointer(data))
+	if i.kind == toolbarToggle && fromgbool(C.gtk_toggle_tool_button_get_active((*C.GtkToggleToolButton)(unsafe.Pointer(button)))) == i.checked {
+		// we changed it ourselves with SetChecked()
+		return
+	}
+	i.clicked()
+}
@@ -0,0 +1,304 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"image"
+	"syscall"
+	"unsafe"
+)
+
+/*
+A Toolbar is a toolbar control along the top of the Window's client area; the controls of the Window are laid out in what's left below it (see sysData.toolbarHeight()).
+The command ID of each button is its index in the Toolbar plus one; the toolbar sends WM_COMMAND with that and its own window handle to the Window (see stdWndProc()).
+Toggle buttons are BTNS_CHECK buttons, which check and uncheck themselves before WM_COMMAND.
+The icons go in an image list made for the toolbar, at the size of small icons; tooltips come from TBN_GETINFOTIP, as the button text is shown under the icon.
+*/
+
+type sysToolbar struct {
+	hwnd      _HWND
+	imagelist uintptr
+	texts     [][]uint16 // the toolbar keeps pointers to these
+}
+
+type sysToolbarItem struct{}
+
+type _TBBUTTON struct {
+	iBitmap   int32
+	idCommand int32
+	fsState   byte
+	fsStyle   byte
+	bReserved [2]byte // padded to the size of a pointer, as the C struct is
+	dwData    uintptr
+	iString   uintptr
+}
+
+type _NMTBGETINFOTIP struct {
+	hdr        _NMHDR
+	pszText    uintptr
+	cchTextMax int32
+	iItem      int32
+	lParam     uintptr
+}
+
+func (l _LPARAM) NMTBGETINFOTIP() *_NMTBGETINFOTIP {
+	return (*_NMTBGETINFOTIP)(unsafe.Pointer(l))
+}
+
+var (
+	_createDIBSection = gdi32.NewProc("CreateDIBSection")
+)
+
+// runs on the UI thread
+func (s *sysData) sysMakeToolbar(t *Toolbar) {
+	r1, _, err := _createWindowEx.Call(
+		uintptr(0),
+		utf16ToArg(toUTF16(x_TOOLBARCLASSNAME)),
+		blankString,
+		uintptr(_WS_CHILD|_WS_VISIBLE|_WS_CLIPSIBLINGS|_TBSTYLE_FLAT|_TBSTYLE_TOOLTIPS|_CCS_NODIVIDER),
+		uintptr(0),
+		uintptr(0),
+		uintptr(0),
+		uintptr(0),
+		uintptr(s.hwnd),
+		uintptr(0),
+		uintptr(hInstance),
+		uintptr(0))
+	if r1 == 0 { // failure
+		panic(s.newError("creating toolbar", "CreateWindowEx()", err))
+	}
+	t.hwnd = _HWND(r1)
+	_sendMessage.Call(
+		uintptr(t.hwnd),
+		uintptr(_TB_BUTTONSTRUCTSIZE),
+		uintptr(unsafe.Sizeof(_TBBUTTON{})),
+		uintptr(0))
+	t.makeImageList()
+	buttons := make([]_TBBUTTON, len(t.items))
+	images := 0
+	for n, i := range t.items {
+		b := &buttons[n]
+		if i.kind == toolbarSeparator {
+			b.fsStyle = _BTNS_SEP
+			continue
+		}
+		b.idCommand = int32(n + 1)
+		b.fsStyle = _BTNS_BUTTON | _BTNS_AUTOSIZE
+		if i.kind == toolbarToggle {
+			b.fsStyle = _BTNS_CHECK | _BTNS_AUTOSIZE
+		}
+		if !i.disabled {
+			b.fsState |= _TBSTATE_ENABLED
+		}
+		if i.checked {
+			b.fsState |= _TBSTATE_CHECKED
+		}
+		b.iBitmap = _I_IMAGENONE
+		if i.icon != nil {
+			b.iBitmap = int32(images)
+			images++
+		}
+		text := syscall.StringToUTF16(i.text)
+		t.texts = append(t.texts, text)
+		b.iString = uintptr(unsafe.Pointer(&text[0]))
+	}
+	r1, _, err = _sendMessage.Call(
+		uintptr(t.hwnd),
+		uintptr(_TB_ADDBUTTONSW),
+		uintptr(len(buttons)),
+		uintptr(unsafe.Pointer(&buttons[0])))
+	if r1 == 0 { // failure
+		panic(s.newError("adding buttons to toolbar", "TB_ADDBUTTONS", err))
+	}
+	_sendMessage.Call(
+		uintptr(t.hwnd),
+		uintptr(_TB_AUTOSIZE),
+		uintptr(0),
+		uintptr(0))
+}
+
+// the image list holds the icons in the order of the ToolbarItems that have them
+func (t *Toolbar) makeImageList() {
+	n := 0
+	for _, i := range t.items {
+		if i.icon != nil {
+			n++
+		}
+	}
+	if n == 0 { // no image list at all, so the buttons are just text
+		return
+	}
+	size, _, _ := _getSystemMetrics.Call(uintptr(_SM_CXSMICON))
+	r1, _, err := _imageListCreate.Call(
+		size,
+		size,
+		uintptr(_ILC_COLOR32),
+		uintptr(n),
+		uintptr(0))
+	if r1 == 0 { // failure
+		panic(newError(nil, "creating image list for toolbar icons", "ImageList_Create()", err))
+	}
+	t.imagelist = r1
+	for _, i := range t.items {
+		if i.icon == nil {
+			continue
+		}
+		bitmap := iconBitmap(scaleIcon(i.icon, int(size)))
+		r1, _, err := _imageListAdd.Call(
+			t.imagelist,
+			uintptr(bitmap),
+			uintptr(0))
+		_deleteObject.Call(uintptr(bitmap)) // the image list has a copy
+		if r1 == negConst(-1) {
+			panic(newError(nil, "adding icon to toolbar image list", "ImageList_Add()", err))
+		}
+	}
+	_sendMessage.Call(
+		uintptr(t.hwnd),
+		uintptr(_TB_SETIMAGELIST),
+		uintptr(0),
+		t.imagelist)
+}
+
+// a 32-bit image list wants premultiplied alpha, as AlphaBlend() does (see area_windows.go)
+func iconBitmap(img *image.RGBA) _HANDLE {
+	var bits uintptr
+
+	bi := _BITMAPINFO{}
+	bi.bmiHeader.biSize = uint32(unsafe.Sizeof(bi.bmiHeader))
+	bi.bmiHeader.biWidth = int32(img.Rect.Dx())
+	bi.bmiHeader.biHeight = -int32(img.Rect.Dy()) // top-down
+	bi.bmiHeader.biPlanes = 1
+	bi.bmiHeader.biBitCount = 32
+	bi.bmiHeader.biCompression = _BI_RGB
+	bi.bmiHeader.biSizeImage = uint32(img.Rect.Dx() * img.Rect.Dy() * 4)
+	r1, _, err := _createDIBSection.Call(
+		uintptr(_NULL),
+		uintptr(unsafe.Pointer(&bi)),
+		uintptr(_DIB_RGB_COLORS),
+		uintptr(unsafe.Pointer(&bits)),
+		uintptr(0),
+		uintptr(0))
+	if r1 == 0 { // failure
+		panic(newError(nil, "creating HBITMAP for toolbar icon", "CreateDIBSection()", err))
+	}
+	toARGB(img, bits, img.Rect.Dx()*4)
+	return _HANDLE(r1)
+}
+
+// scales img to size by size, averaging the pixels of img that make up each pixel of the result (or repeating them, when scaling up)
+func scaleIcon(img *image.NRGBA, size int) *image.RGBA {
+	out := image.NewRGBA(image.Rect(0, 0, size, size))
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	for y := 0; y < size; y++ {
+		y0, y1 := y*height/size, (y+1)*height/size
+		if y1 == y0 {
+			y1++
+		}
+		for x := 0; x < size; x++ {
+			var r, g, b, a, n uint32
+
+			x0, x1 := x*width/size, (x+1)*width/size
+			if x1 == x0 {
+				x1++
+			}
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA() // premultiplied
+					r, g, b, a = r+cr, g+cg, b+cb, a+ca
+					n++
+				}
+			}
+			p := out.PixOffset(x, y)
+			out.Pix[p+0] = uint8(r / n >> 8)
+			out.Pix[p+1] = uint8(g / n >> 8)
+			out.Pix[p+2] = uint8(b / n >> 8)
+			out.Pix[p+3] = uint8(a / n >> 8)
+		}
+	}
+	return out
+}
+
+// runs on the UI thread; the controls of the Window go below this
+func (s *sysData) toolbarHeight() int {
+	var r _RECT
+
+	if s.toolbar == nil {
+		return 0
+	}
+	r1, _, err := _getWindowRect.Call(
+		uintptr(s.toolbar.hwnd),
+		uintptr(unsafe.Pointer(&r)))
+	if r1 == 0 { // failure
+		panic(s.newError("getting toolbar height", "GetWindowRect()", err))
+	}
+	return int(r.bottom - r.top)
+}
+
+// runs on the UI thread; toolbars don't resize themselves when their parent does
+func (s *sysData) toolbarAutosize() {
+	if s.toolbar == nil {
+		return
+	}
+	_sendMessage.Call(
+		uintptr(s.toolbar.hwnd),
+		uintptr(_TB_AUTOSIZE),
+		uintptr(0),
+		uintptr(0))
+}
+
+// runs on the UI thread; the toolbar itself goes with the Window, but the image list doesn't
+func (s *sysData) toolbarDestroy() {
+	if s.toolbar == nil || s.toolbar.imagelist == 0 {
+		return
+	}
+	_imageListDestroy.Call(s.toolbar.imagelist)
+	s.toolbar.imagelist = 0
+}
+
+// runs on the UI thread; for TBN_GETINFOTIP
+func (s *sysData) toolbarTooltip(nm *_NMTBGETINFOTIP) {
+	n := int(nm.iItem) - 1
+	if n < 0 || n >= len(s.toolbar.items) || nm.cchTextMax <= 0 {
+		return
+	}
+	tooltip := syscall.StringToUTF16(s.toolbar.items[n].tooltip)
+	if len(tooltip) > int(nm.cchTextMax) {
+		tooltip = tooltip[:nm.cchTextMax]
+		tooltip[len(tooltip)-1] = 0
+	}
+	copy((*[1 << 20]uint16)(unsafe.Pointer(nm.pszText))[:len(tooltip)], tooltip)
+}
+
+func (i *ToolbarItem) sysSetChecked() {
+	check := uintptr(_FALSE)
+	if i.checked {
+		check = _TRUE
+	}
+	_sendMessage.Call(
+		uintptr(i.toolbar.hwnd),
+		uintptr(_TB_CHECKBUTTON),
+		uintptr(i.commandID()),
+		check)
+}
+
+func (i *ToolbarItem) sysSetEnabled() {
+	enable := uintptr(_TRUE)
+	if i.disabled {
+		enable = _FALSE
+	}
+	_sendMessage.Call(
+		uintptr(i.toolbar.hwnd),
+		uintptr(_TB_ENABLEBUTTON),
+		uintptr(i.commandID()),
+		enable)
+}
+
+func (i *ToolbarItem) commandID() int {
+	for n, j := range i.toolbar.items {
+		if j == i {
+			return n + 1
+		}
+	}
+	panic("ToolbarItem not found in its own Toolbar")
+}
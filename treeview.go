@@ -0,0 +1,295 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A TreeView is a list of items that can have items under them, like the folders in a file manager: each item (a TreeNode) can be expanded to show the items under it, indented below it, or collapsed to hide them.
+// Items are added one at a time with Add, each under an item already in the TreeView or at the top level.
+// At most one item is selected at a time; on creation, no item is selected (unless Select was called beforehand).
+// For information on scrollbars, see "Scrollbars" in the Overview.
+type TreeView struct {
+	// SelectionChanged is signaled when the user selects another item.
+	// Whether changing the selection in code (with Select or Remove) also signals SelectionChanged is implementation-defined.
+	SelectionChanged chan struct{}
+
+	lock         sync.Mutex
+	created      bool
+	sysData      *sysData
+	top          []*TreeNode
+	nodes        map[uintptr]*TreeNode // by id, so the platform code only has to hand us back a number; see Selected()
+	nextID       uintptr
+	initSelected *TreeNode
+}
+
+// A TreeNode is an item in a TreeView, returned by TreeView.Add.
+type TreeNode struct {
+	tree     *TreeView // nil once removed
+	id       uintptr
+	text     string
+	parent   *TreeNode
+	children []*TreeNode
+	expanded bool     // only used until the TreeView is created
+	item     treeItem // the platform's handle for the item; see treeview_*.go
+}
+
+// NewTreeView creates a new TreeView with no items.
+func NewTreeView() *TreeView {
+	return &TreeView{
+		SelectionChanged: newEvent(),
+		sysData:          mksysdata(c_treeview),
+		nodes:            map[uintptr]*TreeNode{},
+	}
+}
+
+// Text returns the text of the TreeNode.
+func (n *TreeNode) Text() string {
+	return n.text
+}
+
+// Parent returns the TreeNode the TreeNode is under, or nil if it is at the top level of its TreeView.
+func (n *TreeNode) Parent() *TreeNode {
+	return n.parent
+}
+
+// checks that n is in t; call with t.lock held
+func (t *TreeView) check(n *TreeNode, what string) {
+	if n.tree != t {
+		panic(fmt.Errorf("TreeNode %q given to TreeView.%s() is not in the TreeView", n.text, what))
+	}
+}
+
+// Add adds an item with the given text after the last item under parent, or after the last item at the top level if parent is nil, and returns it.
+// Adding an item doesn't expand its parent.
+// It panics if parent is not in the TreeView.
+func (t *TreeView) Add(parent *TreeNode, text string) *TreeNode {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if parent != nil {
+		t.check(parent, "Add")
+	}
+	t.nextID++
+	n := &TreeNode{
+		tree:   t,
+		id:     t.nextID,
+		text:   text,
+		parent: parent,
+	}
+	t.nodes[n.id] = n
+	if parent == nil {
+		t.top = append(t.top, n)
+	} else {
+		parent.children = append(parent.children, n)
+	}
+	if t.created {
+		ret := make(chan struct{})
+		defer close(ret)
+		uitask(func() {
+			t.sysData.treeInsert(n)
+			ret <- struct{}{}
+		})
+		<-ret
+	}
+	return n
+}
+
+// Remove removes the given item, and all the items under it, from the TreeView.
+// The TreeNodes removed can't be used with the TreeView anymore, though their Text and Parent methods still work.
+// It panics if n is not in the TreeView.
+func (t *TreeView) Remove(n *TreeNode) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.check(n, "Remove")
+	if t.created {
+		ret := make(chan struct{})
+		defer close(ret)
+		uitask(func() {
+			t.sysData.treeDelete(n)
+			ret <- struct{}{}
+		})
+		<-ret
+	}
+	siblings := &t.top
+	if n.parent != nil {
+		siblings = &n.parent.children
+	}
+	for i, c := range *siblings {
+		if c == n {
+			*siblings = append((*siblings)[:i], (*siblings)[i+1:]...)
+			break
+		}
+	}
+	t.forget(n)
+}
+
+// call with t.lock held
+func (t *TreeView) forget(n *TreeNode) {
+	for _, c := range n.children {
+		t.forget(c)
+	}
+	delete(t.nodes, n.id)
+	n.tree = nil
+	if t.initSelected == n {
+		t.initSelected = nil
+	}
+}
+
+// Expand shows the items under n, as if the user had clicked n's expander, first expanding the items n is under so they show too; it does not expand the items under n's items.
+// It panics if n is not in the TreeView.
+func (t *TreeView) Expand(n *TreeNode) {
+	t.expand(n, true, "Expand")
+}
+
+// Collapse hides the items under n.
+// Whether the items under those stay expanded, so they show the same way when n is expanded again, is implementation-defined.
+// It panics if n is not in the TreeView.
+func (t *TreeView) Collapse(n *TreeNode) {
+	t.expand(n, false, "Collapse")
+}
+
+func (t *TreeView) expand(n *TreeNode, expand bool, what string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.check(n, what)
+	if !t.created {
+		n.expanded = expand
+		for p := n.parent; expand && p != nil; p = p.parent {
+			p.expanded = true
+		}
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		if expand {
+			t.expandTo(n)
+		} else {
+			t.sysData.treeExpand(n, false)
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// Selected returns the selected item, or nil if no item is selected.
+func (t *TreeView) Selected() *TreeNode {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !t.created {
+		return t.initSelected
+	}
+	ret := make(chan uintptr)
+	defer close(ret)
+	uitask(func() {
+		ret <- t.sysData.treeSelected()
+	})
+	return t.nodes[<-ret] // 0 (nothing selected) is never an id
+}
+
+// Select selects n, expanding the items it is under so it can be seen, and scrolls the TreeView to it; pass nil to select nothing.
+// It panics if n is not in the TreeView.
+func (t *TreeView) Select(n *TreeNode) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if n != nil {
+		t.check(n, "Select")
+	}
+	if !t.created {
+		t.initSelected = n
+		return
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		t.selectNode(n)
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+func (t *TreeView) make(window *sysData) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.sysData.control = t
+	t.sysData.event = t.SelectionChanged
+	err := t.sysData.make(window)
+	if err != nil {
+		return err
+	}
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		for _, n := range t.top {
+			t.insertAll(n)
+		}
+		// only now that all the items are there, as none of the systems let us expand an item that has no items under it
+		for _, n := range t.top {
+			t.expandAll(n)
+		}
+		if t.initSelected != nil {
+			t.selectNode(t.initSelected)
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+	t.created = true
+	return nil
+}
+
+// these run on the UI thread
+func (t *TreeView) insertAll(n *TreeNode) {
+	t.sysData.treeInsert(n)
+	for _, c := range n.children {
+		t.insertAll(c)
+	}
+}
+
+// not all systems can expand an item whose parent is collapsed, so parents go first, and items under collapsed ones stay collapsed, as they would if the user had collapsed the parent
+func (t *TreeView) expandAll(n *TreeNode) {
+	if !n.expanded {
+		return
+	}
+	t.sysData.treeExpand(n, true)
+	for _, c := range n.children {
+		t.expandAll(c)
+	}
+}
+
+func (t *TreeView) expandTo(n *TreeNode) {
+	if n.parent != nil {
+		t.expandTo(n.parent)
+	}
+	t.sysData.treeExpand(n, true)
+}
+
+func (t *TreeView) selectNode(n *TreeNode) {
+	if n != nil && n.parent != nil {
+		t.expandTo(n.parent)
+	}
+	t.sysData.treeSelect(n)
+}
+
+func (t *TreeView) allocate(x int, y int, width int, height int, d *sysSizeData) []*allocation {
+	return t.sysData.singleAllocation(x, y, width, height, t)
+}
+
+func (t *TreeView) preferredSize(d *sysSizeData) (width int, height int) {
+	return t.sysData.preferredSize(d)
+}
+
+func (t *TreeView) commitResize(a *allocation, d *sysSizeData) {
+	t.sysData.commitResize(a, d)
+}
+
+func (t *TreeView) getAuxResizeInfo(d *sysSizeData) {
+	t.sysData.getAuxResizeInfo(d)
+}
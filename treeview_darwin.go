@@ -0,0 +1,57 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+/*
+On Mac OS X, a TreeView is an NSOutlineView in a scroll view, like a Listbox; but instead of bindings, it has a data source of our own, which holds the items (see treeview_darwin.m).
+Each TreeNode holds its item, which knows the TreeNode's id; that's how we find the TreeNode of the selected row.
+The app delegate is the NSOutlineView's delegate, so it's told when the selection changes; see appDelegate_treeViewSelectionChanged(). NSOutlineView also tells it when we change the selection, and when the selected item is removed.
+*/
+
+type treeItem C.id
+
+func makeTreeView(parentWindow C.id, alternate bool, s *sysData) C.id {
+	treeview := C.makeTreeView(appDelegate)
+	treeview = makeListboxScrollView(treeview)
+	addControl(parentWindow, treeview)
+	return treeview
+}
+
+func (s *sysData) outlineView() C.id {
+	return getScrollViewContent(s.id)
+}
+
+func (s *sysData) treeInsert(n *TreeNode) {
+	var parent C.id // nil for the top level
+
+	if n.parent != nil {
+		parent = C.id(n.parent.item)
+	}
+	n.item = treeItem(C.treeViewInsert(s.outlineView(), parent, toNSString(n.text), C.uintptr_t(n.id)))
+}
+
+// this removes the items under n too
+func (s *sysData) treeDelete(n *TreeNode) {
+	C.treeViewDelete(s.outlineView(), C.id(n.item))
+}
+
+func (s *sysData) treeExpand(n *TreeNode, expand bool) {
+	C.treeViewExpand(s.outlineView(), C.id(n.item), toBOOL(expand))
+}
+
+func (s *sysData) treeSelect(n *TreeNode) {
+	var item C.id // nil selects nothing
+
+	if n != nil {
+		item = C.id(n.item)
+	}
+	C.treeViewSelect(s.outlineView(), item)
+}
+
+// returns the id of the selected TreeNode, or 0 if none is selected
+func (s *sysData) treeSelected() uintptr {
+	return uintptr(C.treeViewSelected(s.outlineView()))
+}
@@ -0,0 +1,187 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <Foundation/NSArray.h>
+#import <Foundation/NSIndexSet.h>
+#import <Foundation/NSString.h>
+#import <AppKit/NSOutlineView.h>
+#import <AppKit/NSTableColumn.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSOutlineView(x) to(NSOutlineView, (x))
+
+extern NSRect dummyRect;
+
+// an item of a TreeView; see treeview_darwin.go
+@interface goTreeItem : NSObject {
+@public
+	NSString *text;
+	uintptr_t nodeID;
+	goTreeItem *parent;		// not retained, as the parent retains us; nil at the top level
+	NSMutableArray *children;
+}
+@end
+
+@implementation goTreeItem
+
+- (void)dealloc
+{
+	[text release];
+	[children release];
+	[super dealloc];
+}
+
+@end
+
+#define toGoTreeItem(x) to(goTreeItem, (x))
+
+// NSOutlineView doesn't hold its items; it asks its data source for them, by the item they're under (nil for the top level)
+@interface goTreeDataSource : NSObject <NSOutlineViewDataSource> {
+@public
+	NSMutableArray *top;
+}
+@end
+
+@implementation goTreeDataSource
+
+- (void)dealloc
+{
+	[top release];
+	[super dealloc];
+}
+
+- (NSMutableArray *)childrenOf:(id)item
+{
+	if (item == nil)
+		return top;
+	return toGoTreeItem(item)->children;
+}
+
+- (NSInteger)outlineView:(NSOutlineView *)ov numberOfChildrenOfItem:(id)item
+{
+	return (NSInteger) [[self childrenOf:item] count];
+}
+
+- (id)outlineView:(NSOutlineView *)ov child:(NSInteger)index ofItem:(id)item
+{
+	return [[self childrenOf:item] objectAtIndex:((NSUInteger) index)];
+}
+
+- (BOOL)outlineView:(NSOutlineView *)ov isItemExpandable:(id)item
+{
+	return [[self childrenOf:item] count] != 0;
+}
+
+- (id)outlineView:(NSOutlineView *)ov objectValueForTableColumn:(NSTableColumn *)column byItem:(id)item
+{
+	return toGoTreeItem(item)->text;
+}
+
+@end
+
+static char dataSourceKey;		/* only its address is used */
+
+id makeTreeView(id delegate)
+{
+	NSOutlineView *ov;
+	NSTableColumn *column;
+	NSCell *dataCell;
+	goTreeDataSource *ds;
+
+	ov = [[NSOutlineView alloc]
+		initWithFrame:dummyRect];
+	column = [[NSTableColumn alloc] initWithIdentifier:@""];
+	[column setEditable:NO];
+	// as with Listbox, the font goes on the cell template
+	dataCell = [column dataCell];
+	applyStandardControlFont(dataCell);
+	[column setDataCell:dataCell];
+	[ov addTableColumn:column];
+	[ov setOutlineTableColumn:column];
+	[column release];
+	[ov setAllowsMultipleSelection:NO];
+	[ov setAllowsEmptySelection:YES];
+	[ov setHeaderView:nil];
+	ds = [goTreeDataSource new];
+	ds->top = [NSMutableArray new];
+	[ov setDataSource:ds];
+	// the outline view doesn't retain its data source, so make it live as long as the outline view does
+	objc_setAssociatedObject(ov, &dataSourceKey, ds, OBJC_ASSOCIATION_RETAIN);
+	[ds release];
+	// for TreeView.SelectionChanged (outlineViewSelectionDidChange:)
+	[ov setDelegate:delegate];
+	return ov;
+}
+
+// returns the new item, which belongs to its parent's list of children
+id treeViewInsert(id treeview, id parent, id text, uintptr_t nodeID)
+{
+	NSOutlineView *ov;
+	goTreeDataSource *ds;
+	goTreeItem *item;
+
+	ov = toNSOutlineView(treeview);
+	ds = (goTreeDataSource *) [ov dataSource];
+	item = [goTreeItem new];
+	item->text = [((NSString *) text) copy];
+	item->nodeID = nodeID;
+	item->parent = toGoTreeItem(parent);
+	item->children = [NSMutableArray new];
+	[[ds childrenOf:parent] addObject:item];
+	[item release];
+	// reloading nil reloads everything
+	[ov reloadItem:parent reloadChildren:YES];
+	return item;
+}
+
+// this removes the items under item too
+void treeViewDelete(id treeview, id item)
+{
+	NSOutlineView *ov;
+	goTreeDataSource *ds;
+	goTreeItem *parent;
+
+	ov = toNSOutlineView(treeview);
+	ds = (goTreeDataSource *) [ov dataSource];
+	parent = toGoTreeItem(item)->parent;
+	// this releases item, which also releases the items under it
+	[[ds childrenOf:parent] removeObjectIdenticalTo:item];
+	[ov reloadItem:parent reloadChildren:YES];
+}
+
+void treeViewExpand(id treeview, id item, BOOL expand)
+{
+	if (expand)
+		[toNSOutlineView(treeview) expandItem:item];		// not the items under those
+	else
+		[toNSOutlineView(treeview) collapseItem:item];
+}
+
+// item is nil to select nothing
+void treeViewSelect(id treeview, id item)
+{
+	NSOutlineView *ov;
+	NSInteger row;
+
+	ov = toNSOutlineView(treeview);
+	if (item == nil) {
+		[ov deselectAll:ov];
+		return;
+	}
+	row = [ov rowForItem:item];
+	[ov selectRowIndexes:[NSIndexSet indexSetWithIndex:((NSUInteger) row)] byExtendingSelection:NO];
+	[ov scrollRowToVisible:row];
+}
+
+// returns 0 if nothing is selected
+uintptr_t treeViewSelected(id treeview)
+{
+	NSOutlineView *ov;
+	NSInteger row;
+
+	ov = toNSOutlineView(treeview);
+	row = [ov selectedRow];
+	if (row == -1)
+		return 0;
+	return toGoTreeItem([ov itemAtRow:row])->nodeID;
+}
@@ -0,0 +1,117 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// /* as in listbox_unix.go, because cgo chokes on ... */
+// static GtkTreeStore *gtkTreeStoreNew(void)
+// {
+// 	/* the text of the item and the id of its TreeNode */
+// 	return gtk_tree_store_new(2, G_TYPE_STRING, G_TYPE_UINT64);
+// }
+// static void gtkTreeStoreSet(GtkTreeStore *ts, GtkTreeIter *iter, char *text, guint64 id)
+// {
+// 	gtk_tree_store_set(ts, iter, 0, (gchar *) text, 1, id, -1);
+// }
+// static guint64 gtkTreeModelGetID(GtkTreeModel *model, GtkTreeIter *iter)
+// {
+// 	guint64 id;
+//
+// 	gtk_tree_model_get(model, iter, 1, &id, -1);
+// 	return id;
+// }
+// static GtkTreeViewColumn *gtkTreeViewTextColumn(GtkCellRenderer *renderer)
+// {
+// 	return gtk_tree_view_column_new_with_attributes("", renderer, "text", 0, NULL);
+// }
+import "C"
+
+/*
+On GTK+, a TreeView is a GtkTreeView with a GtkTreeStore, in a GtkScrolledWindow like a Listbox; each TreeNode holds the GtkTreeIter of its row, which stays valid for as long as the row exists because GtkTreeStore's iterators persist.
+The second column of the store, which isn't shown, is the TreeNode's id, which is how we find the TreeNode of the selected row.
+The selection tells us when it changes, as with Listbox (see the classData for TreeViews); it also does so when we change it, and when the selected row is removed.
+*/
+
+type treeItem C.GtkTreeIter
+
+func gTreeViewNew() *C.GtkWidget {
+	store := C.gtkTreeStoreNew()
+	widget := C.gtk_tree_view_new_with_model((*C.GtkTreeModel)(unsafe.Pointer(store)))
+	C.g_object_unref(C.gpointer(unsafe.Pointer(store))) // the GtkTreeView has its own reference
+	tv := togtktreeview(widget)
+	C.gtk_tree_view_append_column(tv, C.gtkTreeViewTextColumn(C.gtk_cell_renderer_text_new()))
+	C.gtk_tree_view_set_headers_visible(tv, C.FALSE)
+	// GTK_SELECTION_SINGLE is the default
+	scrollarea := C.gtk_scrolled_window_new((*C.GtkAdjustment)(nil), (*C.GtkAdjustment)(nil))
+	C.gtk_scrolled_window_set_shadow_type((*C.GtkScrolledWindow)(unsafe.Pointer(scrollarea)), C.GTK_SHADOW_IN)
+	C.gtk_container_add((*C.GtkContainer)(unsafe.Pointer(scrollarea)), widget)
+	return scrollarea
+}
+
+func (n *TreeNode) iter() *C.GtkTreeIter {
+	return (*C.GtkTreeIter)(&n.item)
+}
+
+func (s *sysData) treeStore() *C.GtkTreeStore {
+	return (*C.GtkTreeStore)(unsafe.Pointer(C.gtk_tree_view_get_model(getTreeViewFrom(s.widget))))
+}
+
+func (s *sysData) treeInsert(n *TreeNode) {
+	var parent *C.GtkTreeIter // NULL for the top level
+
+	store := s.treeStore()
+	if n.parent != nil {
+		parent = n.parent.iter()
+	}
+	C.gtk_tree_store_append(store, n.iter(), parent)
+	ctext := C.CString(n.text)
+	defer C.free(unsafe.Pointer(ctext))
+	C.gtkTreeStoreSet(store, n.iter(), ctext, C.guint64(n.id))
+}
+
+// this removes the rows under n too
+func (s *sysData) treeDelete(n *TreeNode) {
+	C.gtk_tree_store_remove(s.treeStore(), n.iter())
+}
+
+func (s *sysData) treeExpand(n *TreeNode, expand bool) {
+	tv := getTreeViewFrom(s.widget)
+	path := C.gtk_tree_model_get_path(C.gtk_tree_view_get_model(tv), n.iter())
+	defer C.gtk_tree_path_free(path)
+	if expand {
+		C.gtk_tree_view_expand_row(tv, path, C.FALSE) // not the rows under those
+	} else {
+		C.gtk_tree_view_collapse_row(tv, path)
+	}
+}
+
+func (s *sysData) treeSelect(n *TreeNode) {
+	tv := getTreeViewFrom(s.widget)
+	sel := C.gtk_tree_view_get_selection(tv)
+	if n == nil {
+		C.gtk_tree_selection_unselect_all(sel)
+		return
+	}
+	C.gtk_tree_selection_select_iter(sel, n.iter())
+	path := C.gtk_tree_model_get_path(C.gtk_tree_view_get_model(tv), n.iter())
+	defer C.gtk_tree_path_free(path)
+	C.gtk_tree_view_scroll_to_cell(tv, path, nil, C.FALSE, 0, 0)
+}
+
+// returns the id of the selected TreeNode, or 0 if none is selected
+func (s *sysData) treeSelected() uintptr {
+	var model *C.GtkTreeModel
+	var iter C.GtkTreeIter
+
+	sel := C.gtk_tree_view_get_selection(getTreeViewFrom(s.widget))
+	if C.gtk_tree_selection_get_selected(sel, &model, &iter) == C.FALSE {
+		return 0
+	}
+	return uintptr(C.gtkTreeModelGetID(model, &iter))
+}
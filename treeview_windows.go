@@ -0,0 +1,118 @@
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+/*
+On Windows, a TreeView is a tree view control; each TreeNode holds the HTREEITEM of its item, and the item's lParam is the TreeNode's id, which is how we find the TreeNode of the selected item.
+The tree view tells the window it's in that the selection changed with a TVN_SELCHANGED notification; see stdWndProc(). It sends this for TVM_SELECTITEM and for deleting the selected item too.
+*/
+
+type treeItem uintptr // HTREEITEM
+
+type _TVITEM struct {
+	mask           uint32
+	hItem          treeItem
+	state          uint32
+	stateMask      uint32
+	pszText        *uint16
+	cchTextMax     int32
+	iImage         int32
+	iSelectedImage int32
+	cChildren      int32
+	lParam         _LPARAM
+}
+
+type _TVINSERTSTRUCT struct {
+	hParent      treeItem
+	hInsertAfter treeItem
+	item         _TVITEM // really a union with TVITEMEX, whose extra fields aren't read without their TVIF_* flags
+}
+
+func (s *sysData) treeInsert(n *TreeNode) {
+	var is _TVINSERTSTRUCT
+
+	is.hParent = treeItem(negConst(_TVI_ROOT))
+	if n.parent != nil {
+		is.hParent = n.parent.item
+	}
+	is.hInsertAfter = treeItem(negConst(_TVI_LAST))
+	is.item.mask = _TVIF_TEXT | _TVIF_PARAM
+	is.item.pszText = toUTF16(n.text)
+	is.item.lParam = _LPARAM(n.id)
+	r1, _, err := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TVM_INSERTITEM),
+		uintptr(0),
+		uintptr(unsafe.Pointer(&is)))
+	if r1 == 0 { // failure
+		panic(s.newError("adding item to TreeView", "TVM_INSERTITEM", err))
+	}
+	n.item = treeItem(r1)
+}
+
+// this deletes the items under n too
+func (s *sysData) treeDelete(n *TreeNode) {
+	r1, _, err := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TVM_DELETEITEM),
+		uintptr(0),
+		uintptr(n.item))
+	if r1 == 0 { // failure
+		panic(s.newError("removing item from TreeView", "TVM_DELETEITEM", err))
+	}
+}
+
+func (s *sysData) treeExpand(n *TreeNode, expand bool) {
+	action := uintptr(_TVE_COLLAPSE)
+	if expand {
+		action = uintptr(_TVE_EXPAND)
+	}
+	// the return value is whether anything changed, not an error
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TVM_EXPAND),
+		action,
+		uintptr(n.item))
+}
+
+// TVGN_CARET also scrolls the item into view; a NULL item selects nothing
+func (s *sysData) treeSelect(n *TreeNode) {
+	item := treeItem(0)
+	if n != nil {
+		item = n.item
+	}
+	_sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TVM_SELECTITEM),
+		uintptr(_TVGN_CARET),
+		uintptr(item))
+}
+
+// returns the id of the selected TreeNode, or 0 if none is selected
+func (s *sysData) treeSelected() uintptr {
+	var item _TVITEM
+
+	r1, _, _ := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TVM_GETNEXTITEM),
+		uintptr(_TVGN_CARET),
+		uintptr(0))
+	if r1 == 0 { // nothing selected
+		return 0
+	}
+	item.mask = _TVIF_PARAM
+	item.hItem = treeItem(r1)
+	r1, _, err := _sendMessage.Call(
+		uintptr(s.hwnd),
+		uintptr(_TVM_GETITEM),
+		uintptr(0),
+		uintptr(unsafe.Pointer(&item)))
+	if r1 == 0 { // failure
+		panic(s.newError("getting selected item of TreeView", "TVM_GETITEM", err))
+	}
+	return uintptr(item.lParam)
+}
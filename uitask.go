@@ -0,0 +1,162 @@
+// 3 july 2014
+
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"unsafe"
+)
+
+/*
+All calls into the system go through uitask(), which runs the given function on the UI thread (the thread that called ui()).
+
+This used to be a channel read by a dispatcher goroutine that handed each function to the system one at a time (with an idle source on GTK+, a posted message on Windows, and -[NSObject performSelectorOnMainThread:withObject:waitUntilDone:] on Mac OS X) and waited for it to finish before handing over the next one. That meant every call paid for a goroutine switch and a new system event on top of the actual work, which adds up if a program makes thousands of small calls a second.
+
+Instead, we keep a single queue of functions. Only the first function queued after the queue runs dry (or while a queued function is running; see below) wakes up the UI thread (with uiwake(), which each backend provides); the UI thread then runs everything in the queue (with runuitasks()) before going back to sleep. Functions run in the order they were queued.
+
+We take one function off the queue at a time, not the whole queue at once, because a function can run a nested event loop (a modal dialog box on Windows, for instance), and that nested loop will call runuitasks() too; this way nothing runs out of order.
+
+The queue is lock-free, so goroutines queueing functions never wait for each other or for the UI thread: it's the intrusive multiple-producer, single-consumer queue of Dmitry Vyukov (http://www.1024cores.net/home/lock-free-algorithms/queues/intrusive-mpsc-node-based-queue). Goroutines add to the head with one atomic swap; only the UI thread takes from the tail. A function that is being queued while the UI thread looks at the queue can be missed for the moment, but the goroutine queueing it only decides whether to wake the UI thread after it's in the queue, and the UI thread clears woken before it looks for the last time, so such a function always gets a uiwake() of its own.
+*/
+
+type uinode struct {
+	f    func()
+	next unsafe.Pointer // *uinode; use sync/atomic
+}
+
+var uiqueue struct {
+	head  unsafe.Pointer // *uinode; the most recently queued, or stub; use sync/atomic
+	tail  *uinode        // the next one to run, or stub; only touched on the UI thread
+	stub  uinode         // keeps the queue from ever being empty, so head and tail are never nil
+	woken int32          // 1 if there is a uiwake() that will get to the end of the queue; use sync/atomic
+	ready int32          // 0 until the system has been initialized; see Go(); use sync/atomic
+}
+
+func init() {
+	uiqueue.head = unsafe.Pointer(&uiqueue.stub)
+	uiqueue.tail = &uiqueue.stub
+}
+
+// the first uitask() before the system has been initialized sends here instead of calling uiwake(); see Go()
+var uineeded = make(chan struct{}, 1)
+
+func uitask(f func()) {
+	// the caller is going to wait for f to run, and it can't if the caller is the UI thread, so don't deadlock silently
+	if onUIThread() {
+		panic("package ui function or method called on the UI thread (for instance, from an AreaHandler method); this would deadlock")
+	}
+	uipush(&uinode{f: f})
+	if atomic.CompareAndSwapInt32(&uiqueue.woken, 0, 1) {
+		if atomic.LoadInt32(&uiqueue.ready) == 0 {
+			uineeded <- struct{}{}
+			return
+		}
+		uiwake()
+	}
+}
+
+func uipush(n *uinode) {
+	atomic.StorePointer(&n.next, nil)
+	prev := (*uinode)(atomic.SwapPointer(&uiqueue.head, unsafe.Pointer(n)))
+	// between these two lines, n is in the queue but the UI thread can't get to it yet; see above
+	atomic.StorePointer(&prev.next, unsafe.Pointer(n))
+}
+
+// runs on the UI thread; returns nil if the queue is empty (or if the next function is still being queued)
+func uipop() func() {
+	tail := uiqueue.tail
+	next := (*uinode)(atomic.LoadPointer(&tail.next))
+	if tail == &uiqueue.stub {
+		if next == nil {
+			return nil
+		}
+		uiqueue.tail = next
+		tail = next
+		next = (*uinode)(atomic.LoadPointer(&tail.next))
+	}
+	if next == nil {
+		if tail != (*uinode)(atomic.LoadPointer(&uiqueue.head)) {
+			return nil // the next one is still being queued
+		}
+		// tail is the last one; put the stub back behind it so we can take it
+		uipush(&uiqueue.stub)
+		next = (*uinode)(atomic.LoadPointer(&tail.next))
+		if next == nil {
+			return nil
+		}
+	}
+	uiqueue.tail = next
+	f := tail.f
+	tail.f = nil // so f can be collected once it finishes
+	return f
+}
+
+// called by Go() on the UI thread once the system has been initialized, to run whatever was queued while it was
+func uiinitialized() {
+	atomic.StoreInt32(&uiqueue.ready, 1)
+	if atomic.LoadInt32(&uiqueue.woken) == 1 {
+		uiwake()
+	}
+}
+
+// runs on the UI thread
+func runuitasks() {
+	for {
+		f := uipop()
+		if f == nil {
+			// anything queued from now on needs a new uiwake(); look one last time for what was queued before
+			atomic.StoreInt32(&uiqueue.woken, 0)
+			f = uipop()
+			if f == nil {
+				return
+			}
+		}
+		// if f runs a nested event loop, anything queued while it runs needs a new uiwake() to get to that loop
+		atomic.StoreInt32(&uiqueue.woken, 0)
+		runuitask(f)
+	}
+}
+
+// separate function so a panic in f doesn't stop us from running the rest of the queue; see SetPanicHandler()
+func runuitask(f func()) {
+	defer recoverUIPanic()
+	f()
+}
+
+// the OS thread Go() locked the UI goroutine to; as nothing else runs on that thread, being on it means being the UI goroutine
+// asking for the current thread is one system call (see the uitask_*.go files), unlike curgoroutine() below, so this is cheap enough for every uitask()
+var uithread uintptr
+
+func onUIThread() bool {
+	return uithread != 0 && curthread() == uithread
+}
+
+// Go doesn't give us a way to tell which goroutine we're on, but the first line of a stack trace is "goroutine N [status]:", so we can read it from there.
+// cgo and Windows callbacks run on the goroutine that called into the system, so every callback from the event loop runs on the goroutine that called ui(), which is the one that called Go().
+var uigoroutine uint64
+
+func curgoroutine() uint64 {
+	var buf [64]byte
+
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if n := bytes.IndexByte(b, ' '); n != -1 {
+		b = b[:n]
+	}
+	id, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		panic(fmt.Errorf("error getting current goroutine ID from stack trace: %v", err))
+	}
+	return id
+}
+
+// in uidebug builds, this panics if it isn't called on the UI thread; call it from the things that must only run there
+func checkUIThread(what string) {
+	if uidebug && curgoroutine() != uigoroutine {
+		panic(fmt.Errorf("%s called outside the UI thread; it must only be called from a uitask function or a system callback", what))
+	}
+}
@@ -4,44 +4,33 @@ package ui
 
 import (
 	"fmt"
-	"runtime"
-	"unsafe"
 )
 
 // #cgo CFLAGS: -mmacosx-version-min=10.7 -DMACOSX_DEPLOYMENT_TARGET=10.7
-// #cgo LDFLAGS: -mmacosx-version-min=10.7 -lobjc -framework Foundation -framework AppKit
+// #cgo LDFLAGS: -mmacosx-version-min=10.7 -lobjc -framework Foundation -framework AppKit -framework Carbon -framework ApplicationServices
 // /* application compatibilty stuff via https://developer.apple.com/library/mac/documentation/DeveloperTools/Conceptual/cross_development/Configuring/configuring.html, http://www.cocoawithlove.com/2009/09/building-for-earlier-os-versions-in.html, http://opensource.apple.com/source/xnu/xnu-2422.1.72/EXTERNAL_HEADERS/AvailabilityMacros.h (via http://stackoverflow.com/questions/20485797/what-macro-to-use-to-identify-mavericks-osx-10-9-in-c-c-code), and Beelsebob and LookyLuke_ICBM on irc.freenode.net/#macdev */
 // #include "objc_darwin.h"
+// #include <pthread.h>
+// static inline uintptr_t curthread(void) { return (uintptr_t) pthread_self(); }
 import "C"
 
-var uitask chan func()
-
-func ui(main func()) error {
-	runtime.LockOSThread()
-
-	uitask = make(chan func())
-
-	err := initCocoa()
-	if err != nil {
-		return err
-	}
-
-	// Cocoa must run on the first thread created by the program, so we run our dispatcher on another thread instead
-	go func() {
-		for f := range uitask {
-			C.douitask(appDelegate, unsafe.Pointer(&f))
-		}
-	}()
+// see onUIThread()
+func curthread() uintptr {
+	return uintptr(C.curthread())
+}
 
-	go func() {
-		main()
-		uitask <- func() {
-			C.breakMainLoop()
-		}
-	}()
+func uiinit() error {
+	return initCocoa()
+}
 
+func uimsgloop() {
 	C.cocoaMainLoop()
-	return nil
+}
+
+func uistop() {
+	uitask(func() {
+		C.breakMainLoop()
+	})
 }
 
 func initCocoa() (err error) {
@@ -49,11 +38,20 @@ func initCocoa() (err error) {
 	if C.initCocoa(appDelegate) != C.YES {
 		return fmt.Errorf("error setting NSApplication activation policy (basically identifies our program as a separate program; needed for several things, such as Dock icon, application menu, window resizing, etc.) (unknown reason)")
 	}
+	C.clipboardWatch()
+	C.initMainMenu()
+	C.highContrastWatch(appDelegate)
+	highContrastUpdate()
+	animationsUpdate()
 	return nil
 }
 
+// Cocoa must run on the first thread created by the program, so uitask functions are run there by -[appDelegate uitask:]; see uitask.go
+func uiwake() {
+	C.douitask(appDelegate)
+}
+
 //export appDelegate_uitask
-func appDelegate_uitask(p unsafe.Pointer) {
-	f := (*func())(unsafe.Pointer(p))
-	(*f)()
+func appDelegate_uitask() {
+	runuitasks()
 }
@@ -4,45 +4,40 @@
 
 package ui
 
-import (
-	"fmt"
-	"runtime"
-)
-
 // #cgo pkg-config: gtk+-3.0
 // #include "gtk_unix.h"
+// #include <pthread.h>
+// static inline uintptr_t curthread(void) { return (uintptr_t) pthread_self(); }
 import "C"
 
-var uitask chan func()
-
-func ui(main func()) error {
-	runtime.LockOSThread()
+// see onUIThread()
+func curthread() uintptr {
+	return uintptr(C.curthread())
+}
 
-	uitask = make(chan func())
+func uiinit() error {
 	err := gtk_init()
 	if err != nil {
-		return fmt.Errorf("gtk_init() failed: %v", err)
+		return err
 	}
+	clipboardWatch()
+	highContrastWatch()
+	highContrastUpdate()
+	animationsWatch()
+	animationsUpdate()
+	return nil
+}
 
-	// thanks to tristan and Daniel_S in irc.gimp.net/#gtk
-	// see our_idle_callback in callbacks_unix.go for details
-	go func() {
-		for f := range uitask {
-			done := make(chan struct{})
-			gdk_threads_add_idle(&gtkIdleOp{
-				what: f,
-				done: done,
-			})
-			<-done
-			close(done)
-		}
-	}()
-
-	go func() {
-		main()
-		uitask <- gtk_main_quit
-	}()
-
+func uimsgloop() {
 	C.gtk_main()
-	return nil
+}
+
+func uistop() {
+	uitask(gtk_main_quit)
+}
+
+// thanks to tristan and Daniel_S in irc.gimp.net/#gtk
+// see our_idle_callback in callbacks_unix.go for details
+func uiwake() {
+	gdk_threads_add_idle()
 }
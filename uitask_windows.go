@@ -4,7 +4,6 @@ package ui
 
 import (
 	"fmt"
-	"runtime"
 	"syscall"
 	"unsafe"
 )
@@ -22,18 +21,8 @@ the only recourse, and the one both Microsoft (http://support.microsoft.com/kb/1
 yay.
 */
 
-var uitask chan interface{}
-
-type uimsg struct {
-	call *syscall.LazyProc
-	p    []uintptr
-	ret  chan uiret
-}
-
-type uiret struct {
-	ret uintptr
-	err error
-}
+// the invisible window; see uiwake()
+var msghwnd _HWND
 
 const (
 	msgRequested = _WM_APP + iota + 1 // + 1 just to be safe
@@ -46,47 +35,58 @@ var (
 	_postMessage = user32.NewProc("PostMessageW")
 )
 
-func ui(main func()) error {
-	runtime.LockOSThread()
-
-	uitask = make(chan interface{})
-	err := doWindowsInit()
+func uiinit() (err error) {
+	err = doWindowsInit()
 	if err != nil {
 		return fmt.Errorf("error doing general Windows initialization: %v", err)
 	}
 
-	hwnd, err := makeMessageHandler()
+	msghwnd, err = makeMessageHandler()
 	if err != nil {
 		return fmt.Errorf("error making invisible window for handling events: %v", err)
 	}
+	err = clipboardWatch(msghwnd)
+	if err != nil {
+		return fmt.Errorf("error watching the clipboard for ClipboardChanged: %v", err)
+	}
+	highContrastUpdate()
+	animationsUpdate()
+	return nil
+}
 
-	go func() {
-		for m := range uitask {
-			r1, _, err := _postMessage.Call(
-				uintptr(hwnd),
-				msgRequested,
-				uintptr(0),
-				uintptr(unsafe.Pointer(&m)))
-			if r1 == 0 { // failure
-				panic("error sending message to message loop to call function: " + err.Error())
-			}
-		}
-	}()
+func uimsgloop() {
+	msgloop()
+}
 
-	go func() {
-		main()
-		r1, _, err := _postMessage.Call(
-			uintptr(hwnd),
-			msgQuit,
-			uintptr(0),
-			uintptr(0))
-		if r1 == 0 { // failure
-			panic("error sending quit message to message loop: " + err.Error())
-		}
-	}()
+func uistop() {
+	r1, _, err := _postMessage.Call(
+		uintptr(msghwnd),
+		msgQuit,
+		uintptr(0),
+		uintptr(0))
+	if r1 == 0 { // failure
+		panic("error sending quit message to message loop: " + err.Error())
+	}
+}
 
-	msgloop()
-	return nil
+// one msgRequested runs every function queued so far; see uitask.go
+func uiwake() {
+	r1, _, err := _postMessage.Call(
+		uintptr(msghwnd),
+		msgRequested,
+		uintptr(0),
+		uintptr(0))
+	if r1 == 0 { // failure
+		panic("error sending message to message loop to call function: " + err.Error())
+	}
+}
+
+var _getCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
+
+// see onUIThread()
+func curthread() uintptr {
+	r1, _, _ := _getCurrentThreadId.Call()
+	return r1
 }
 
 var (
@@ -178,22 +178,16 @@ func makeMessageHandler() (hwnd _HWND, err error) {
 func messageHandlerWndProc(hwnd _HWND, uMsg uint32, wParam _WPARAM, lParam _LPARAM) _LRESULT {
 	switch uMsg {
 	case msgRequested:
-		mt := (*interface{})(unsafe.Pointer(lParam))
-		switch m := (*mt).(type) {
-		case *uimsg:
-			r1, _, err := m.call.Call(m.p...)
-			m.ret <- uiret{
-				ret: r1,
-				err: err,
-			}
-		case func():
-			m()
-		}
+		runuitasks()
 		return 0
 	case msgQuit:
 		// does not return a value according to MSDN
 		_postQuitMessage.Call(0)
 		return 0
+	case _WM_CLIPBOARDUPDATE:
+		// see clipboard_windows.go
+		sendEvent(ClipboardChanged)
+		return 0
 	}
 	return defWindowProc(hwnd, uMsg, wParam, lParam)
 }
@@ -0,0 +1,18 @@
+// +build uidebug
+
+// 10 july 2014
+
+package ui
+
+// DebugUitask runs f on the UI thread the way every package ui function and method does, and returns once f has run.
+// It is only available in uidebug builds (see debug.go), for measuring how many calls a second can get to the UI thread; see test/uitaskbench.
+// f runs on the UI thread, so it cannot call any other package ui function or method.
+func DebugUitask(f func()) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		f()
+		ret <- struct{}{}
+	})
+	<-ret
+}
@@ -5,6 +5,7 @@ package ui
 import (
 	"fmt"
 	"sync"
+	"time"
 )
 
 // Window represents an on-screen window.
@@ -22,6 +23,15 @@ type Window struct {
 	initHeight int
 	shownOnce  bool
 	spaced	bool
+	frozen     int
+	busy       bool
+	blocked    bool
+	overlay    Control
+	overlaysMade map[Control]bool // see SetInputBlocked()
+	destroyed  bool
+	initMenuBar *Menu
+	initToolbar *Toolbar
+	initStatusBar *StatusBar
 }
 
 // NewWindow allocates a new Window with the given title and size. The window is not created until a call to Create() or Open().
@@ -93,6 +103,7 @@ func (w *Window) Create(control Control) {
 	if w.created {
 		panic("window already open")
 	}
+	defer setStartupTime(&startup.t.FirstWindow, time.Now())
 	w.sysData.spaced = w.spaced
 	w.sysData.event = w.Closing
 	err := w.sysData.make(nil)
@@ -105,6 +116,21 @@ func (w *Window) Create(control Control) {
 		if err != nil {
 			panic(fmt.Errorf("error adding window's control: %v", err))
 		}
+		w.sysData.controlsMade(control)
+	}
+	// before the size, so the size includes the menu bar, toolbar, and status bar on systems that put them in the window
+	// the toolbar goes first so that on GTK+ the menu bar can go above it (see menu_unix.go)
+	if w.initToolbar != nil {
+		w.sysData.makeToolbar(w.initToolbar)
+		w.initToolbar = nil
+	}
+	if w.initStatusBar != nil {
+		w.sysData.makeStatusBar(w.initStatusBar)
+		w.initStatusBar = nil
+	}
+	if w.initMenuBar != nil {
+		w.sysData.changeMenuBar(w.initMenuBar)
+		w.initMenuBar = nil
 	}
 	err = w.sysData.setWindowSize(w.initWidth, w.initHeight)
 	if err != nil {
@@ -112,6 +138,22 @@ func (w *Window) Create(control Control) {
 	}
 	w.sysData.setText(w.initTitle)
 	w.created = true
+	addLiveWindow(w)
+}
+
+// controlsMade() does what has to wait until all of c's controls are made, for the Window s; Tab.makePage() calls it again for the Controls of each page it makes later.
+func (s *sysData) controlsMade(c Control) {
+	s.windowLock.Lock()
+	labels := s.labels
+	s.labels = nil
+	settings := s.settings
+	s.windowLock.Unlock()
+	for _, l := range labels {
+		l.linkBuddy()
+	}
+	addLiveSettings(settings)
+	s.updateForms()
+	checkKeyboard(c)
 }
 
 // Show shows the window.
@@ -125,9 +167,11 @@ func (w *Window) Show() {
 		if err != nil {
 			panic(fmt.Errorf("error showing window for the first time: %v", err))
 		}
+		w.sysData.setWindowListed(true)
 		return
 	}
 	w.sysData.show()
+	w.sysData.setWindowListed(true)
 }
 
 // Hide hides the window.
@@ -136,6 +180,7 @@ func (w *Window) Hide() {
 	defer w.lock.Unlock()
 
 	w.sysData.hide()
+	w.sysData.setWindowListed(false)
 }
 
 // Center centers the Window on-screen.
@@ -150,3 +195,119 @@ func (w *Window) Center() {
 	}
 	w.sysData.center()
 }
+
+// Freeze stops the Window from laying out its controls and from redrawing itself until a matching call to Thaw.
+// Use it to make many changes at once (for instance, changing the text of dozens of controls) without the Window visibly flickering in between.
+// Calls to Freeze nest; the Window is only thawed when each call to Freeze has been matched by a call to Thaw.
+// Resizing a frozen Window is allowed; the new size will take effect when the Window is thawed.
+// It presently panics if the Window has not been created.
+func (w *Window) Freeze() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if !w.created {
+		panic("attempt to freeze Window before it has been created")
+	}
+	if w.frozen == 0 {
+		w.sysData.freeze()
+	}
+	w.frozen++
+}
+
+// Thaw undoes a previous call to Freeze.
+// When the last outstanding Freeze is undone, the Window's controls are laid out once for the Window's current size and the whole Window is redrawn.
+// It panics if the Window is not frozen.
+func (w *Window) Thaw() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if !w.created {
+		panic("attempt to thaw Window before it has been created")
+	}
+	if w.frozen == 0 {
+		panic("attempt to thaw Window that is not frozen")
+	}
+	w.frozen--
+	if w.frozen == 0 {
+		w.sysData.thaw()
+	}
+}
+
+// Relayout lays out the Window's controls again at the Window's current size.
+// Package ui only lays out a Window when it is resized (or thawed; see Thaw), so changing something that changes the preferred size of a control, such as the text of a Label or Button, doesn't move anything else to make room for it (or take up the room it no longer needs) until the user resizes the Window; call Relayout after such changes instead.
+// To change several things at once, Freeze the Window, make the changes, and Thaw it, which lays it out only once; Relayout does nothing while the Window is frozen.
+// It panics if the Window has not been created.
+func (w *Window) Relayout() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if !w.created {
+		panic("attempt to relayout Window before it has been created")
+	}
+	if w.frozen != 0 {
+		return
+	}
+	w.sysData.relayout()
+}
+
+// SetBusy sets whether the Window is busy, for instance while it waits for data to be loaded in the background.
+// While a Window is busy, its controls are dimmed and ignore the mouse and keyboard, and a spinning busy indicator is shown in its middle.
+// The user can still move, resize, and close the Window (Closing still gets messages), and can still use the Window's banner, if any (see ShowBanner); so a banner is a good place for a way to cancel whatever the Window is waiting for.
+// How the controls are dimmed depends on the system: on Windows and with GTK+ they are disabled and drawn the way disabled controls are, and on Mac OS X they are covered with a translucent layer.
+// Windows has no spinning busy indicator, so it shows a marquee progress bar, the way its own busy dialogs do.
+// If animations are off (see AnimationsEnabled), the spinner stands still on Mac OS X and with GTK+; a marquee progress bar that stands still shows nothing, so on Windows it keeps moving.
+// Once the Window is no longer busy, its controls are enabled again, except those disabled with a ControlGroup (see ControlGroup.DisableAll).
+// Only a whole Window can be busy; Stack and Grid have no native counterpart to cover (see the Overview).
+// It panics if the Window has not been created.
+func (w *Window) SetBusy(busy bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if !w.created {
+		panic("attempt to set busy state of Window before it has been created")
+	}
+	if busy == w.busy {
+		return
+	}
+	w.sysData.setBusy(busy)
+	w.busy = busy
+}
+
+// Destroy destroys the Window and every Control in it, freeing everything the system allocated for them.
+// Once Destroy returns, neither the Window nor any of its Controls can be used again; Closing will also no longer be pulsed.
+// To destroy a Control without its Window, use DestroyControl. The Windows that are left when the function given to Go returns are destroyed for you.
+// If the Window is showing a banner, its channel receives -1; see ShowBanner.
+// The settings of the Window's Controls bound with BindSetting are saved first.
+// Destroy panics if the Window has not been created or has already been destroyed.
+func (w *Window) Destroy() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if !w.created {
+		panic("attempt to destroy Window before it has been created")
+	}
+	if w.destroyed {
+		panic("attempt to destroy Window that has already been destroyed")
+	}
+	w.destroy()
+}
+
+// the rest of Destroy(), for teardown() as well; w.lock must be held
+func (w *Window) destroy() {
+	w.sysData.windowLock.Lock()
+	settings := w.sysData.settings
+	stoppers := w.sysData.stoppers
+	w.sysData.windowLock.Unlock()
+	for _, s := range settings {
+		s.save(true)
+	}
+	for _, stop := range stoppers {
+		stop()
+	}
+	w.sysData.hideBanner()
+	w.sysData.changeMenuBar(nil)
+	w.sysData.setWindowListed(false)
+	w.sysData.destroy()
+	w.destroyed = true
+	removeLiveWindow(w)
+}
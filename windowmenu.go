@@ -0,0 +1,85 @@
+// 9 july 2014
+
+package ui
+
+// NewWindowMenu creates a new MenuItem with the Window menu of programs that have more than one Window open at a time: Minimize, which minimizes the Window whose menu bar the Window menu is in; Bring All to Front, which brings all the Windows of the program in front of those of other programs; and a list of every Window that is shown, most recently active first, with the one whose menu bar this is checked.
+// Picking a Window from the list brings it to the front (restoring it if it was minimized) and makes it active.
+// The list is made each time the menu opens, so your program doesn't have to do anything with it; as with any other Menu, a Window menu can only be in one menu bar, so make one for each Window.
+// On Mac OS X, Minimize has the usual keyboard shortcut, Command-M.
+func NewWindowMenu() *MenuItem {
+	menu := NewMenu()
+	item := NewSubmenu("Window", menu)
+	menu.SetItems(windowMenuItems(item)...)
+	menu.SetOpening(func() {
+		items := windowMenuItems(item)
+		owner := item.window()
+		if len(windowList) != 0 {
+			items = append(items, NewMenuSeparator())
+		}
+		for _, s := range windowList {
+			s := s
+			i := NewCheckMenuItem(s.windowTitle(), s == owner) // see windowmenu_*.go
+			i.action = func() {
+				s.activateWindow()
+			}
+			items = append(items, i)
+		}
+		menu.SetItems(items...)
+	})
+	return item
+}
+
+// the items that are always there; item is the Window menu itself, for finding the Window it's in
+func windowMenuItems(item *MenuItem) []*MenuItem {
+	minimize := NewMenuItem("Minimize")
+	minimize.shortcut = minimizeShortcut // see windowmenu_*.go
+	minimize.action = func() {
+		if s := item.window(); s != nil {
+			s.minimizeWindow()
+		}
+	}
+	front := NewMenuItem("Bring All to Front")
+	front.action = func() {
+		// from least to most recently active, so they end up in the same order, in front of everything else; activating changes windowList, so go through a copy
+		list := append([]*sysData(nil), windowList...)
+		for i := len(list) - 1; i >= 0; i-- {
+			list[i].activateWindow()
+		}
+	}
+	return []*MenuItem{minimize, NewMenuSeparator(), front}
+}
+
+// the Windows that are shown, most recently active first, for NewWindowMenu(); only touched on the UI thread
+var windowList []*sysData
+
+// called by Window.Show(), Window.Hide(), and Window.Destroy() with the Window locked
+func (s *sysData) setWindowListed(shown bool) {
+	ret := make(chan struct{})
+	defer close(ret)
+	uitask(func() {
+		windowListRemove(s)
+		if shown { // a Window that was just shown is usually active too
+			windowList = append([]*sysData{s}, windowList...)
+		}
+		ret <- struct{}{}
+	})
+	<-ret
+}
+
+// runs on the UI thread
+func windowListRemove(s *sysData) bool {
+	for i, w := range windowList {
+		if w == s {
+			windowList = append(windowList[:i], windowList[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// called by the platform code on the UI thread when the Window becomes the active one
+func (s *sysData) windowActivated() {
+	if windowListRemove(s) { // only if it's shown
+		windowList = append([]*sysData{s}, windowList...)
+	}
+}
@@ -0,0 +1,24 @@
+// 9 july 2014
+
+package ui
+
+// #include "objc_darwin.h"
+import "C"
+
+// see windowmenu_darwin.m
+
+var minimizeShortcut = menuShortcut{'M', false}
+
+// these run on the UI thread
+
+func (s *sysData) windowTitle() string {
+	return fromNSString(C.windowTitle(s.id))
+}
+
+func (s *sysData) activateWindow() {
+	C.windowShow(s.id)
+}
+
+func (s *sysData) minimizeWindow() {
+	C.windowMinimize(s.id)
+}
@@ -0,0 +1,17 @@
+// 9 july 2014
+
+#include "objc_darwin.h"
+#import <AppKit/NSWindow.h>
+
+#define to(T, x) ((T *) (x))
+#define toNSWindow(x) to(NSWindow, (x))
+
+/*
+NSApplication can keep a Window menu itself ([NSApp setWindowsMenu:]), but it only knows about the menu of [NSApp mainMenu], which changes with the key window (see menu_darwin.m), so NewWindowMenu() makes the list on its own like on the other systems.
+Picking a window uses windowShow(), as makeKeyAndOrderFront: deminiaturizes too.
+*/
+
+void windowMinimize(id window)
+{
+	[toNSWindow(window) miniaturize:window];
+}
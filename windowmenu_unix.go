@@ -0,0 +1,45 @@
+// +build !windows,!darwin,!plan9
+
+// 9 july 2014
+
+package ui
+
+import (
+	"unsafe"
+)
+
+// #include "gtk_unix.h"
+// extern gboolean our_window_focus_in_event_callback(GtkWidget *, GdkEvent *, gpointer);
+import "C"
+
+/*
+GTK+ sends "focus-in-event" to a GtkWindow when it becomes the active window (see the classData for Windows), which is how NewWindowMenu() knows the most recently active one.
+The GNOME HIG has no Window menu, so the text and items are those of Mac OS X; Minimize gets no shortcut, as only Mac OS X acts on them (see menuShortcut).
+*/
+
+var minimizeShortcut = menuShortcut{}
+
+//export our_window_focus_in_event_callback
+func our_window_focus_in_event_callback(widget *C.GtkWidget, event *C.GdkEvent, what C.gpointer) C.gboolean {
+	defer recoverUIPanic()
+	s := (*sysData)(unsafe.Pointer(what))
+	s.windowActivated()
+	return C.FALSE // continue the event chain; GtkWindow needs it to pass the focus on to the focused control
+}
+
+var window_focus_in_event_callback = C.GCallback(C.our_window_focus_in_event_callback)
+
+// these run on the UI thread
+
+func (s *sysData) windowTitle() string {
+	return gtk_window_get_title(s.widget)
+}
+
+func (s *sysData) activateWindow() {
+	// this deiconifies too
+	C.gtk_window_present(togtkwindow(s.widget))
+}
+
+func (s *sysData) minimizeWindow() {
+	C.gtk_window_iconify(togtkwindow(s.widget))
+}
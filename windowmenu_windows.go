@@ -0,0 +1,34 @@
+// 9 july 2014
+
+package ui
+
+// Windows has no standard shortcut for Minimize (the system menu has no shortcuts besides Alt+F4 for Close)
+var minimizeShortcut = menuShortcut{}
+
+var (
+	_isIconic            = user32.NewProc("IsIconic")
+	_setForegroundWindow = user32.NewProc("SetForegroundWindow")
+)
+
+// these run on the UI thread
+
+func (s *sysData) windowTitle() string {
+	return s.doText()
+}
+
+func (s *sysData) activateWindow() {
+	r1, _, _ := _isIconic.Call(uintptr(s.hwnd))
+	if r1 != 0 {
+		_showWindow.Call(
+			uintptr(s.hwnd),
+			uintptr(_SW_RESTORE))
+	}
+	// we're in the foreground already when the user picks from our menu, so this won't be refused; ignore the error
+	_setForegroundWindow.Call(uintptr(s.hwnd))
+}
+
+func (s *sysData) minimizeWindow() {
+	_showWindow.Call(
+		uintptr(s.hwnd),
+		uintptr(_SW_MINIMIZE))
+}
@@ -4,15 +4,26 @@ package ui
 const _ACTCTX_FLAG_SET_PROCESS_DEFAULT = 16
 const _AC_SRC_ALPHA = 1
 const _AC_SRC_OVER = 0
+const _AW_SLIDE = 262144
+const _AW_VER_POSITIVE = 4
 const _BCM_GETIDEALSIZE = 5633
+const _BI_BITFIELDS = 3
 const _BI_RGB = 0
 const _BM_GETCHECK = 240
 const _BM_SETCHECK = 241
 const _BN_CLICKED = 0
 const _BST_CHECKED = 1
 const _BST_UNCHECKED = 0
+const _BS_AUTORADIOBUTTON = 9
 const _BS_CHECKBOX = 2
+const _BS_GROUPBOX = 7
 const _BS_PUSHBUTTON = 0
+const _BTNS_AUTOSIZE = 16
+const _BTNS_BUTTON = 0
+const _BTNS_CHECK = 2
+const _BTNS_SEP = 1
+const _CBN_EDITCHANGE = 5
+const _CBN_SELCHANGE = 1
 const _CBS_AUTOHSCROLL = 64
 const _CBS_DROPDOWN = 2
 const _CBS_DROPDOWNLIST = 3
@@ -22,19 +33,97 @@ const _CB_ERR = -1
 const _CB_ERRSPACE = -2
 const _CB_GETCOUNT = 326
 const _CB_GETCURSEL = 327
+const _CB_GETITEMDATA = 336
 const _CB_INSERTSTRING = 330
+const _CB_SETCURSEL = 334
+const _CB_SETITEMDATA = 337
+const _CCS_NODIVIDER = 64
+const _CC_ANYCOLOR = 256
+const _CC_ENABLEHOOK = 16
+const _CC_FULLOPEN = 2
+const _CC_RGBINIT = 1
+const _CDDS_POSTPAINT = 2
+const _CDDS_PREPAINT = 1
+const _CDIS_DISABLED = 4
+const _CDRF_DODEFAULT = 0
+const _CDRF_NOTIFYPOSTPAINT = 16
+const _CF_DIB = 8
+const _CF_FORCEFONTEXIST = 65536
+const _CF_HDROP = 15
+const _CF_INITTOLOGFONTSTRUCT = 64
+const _CF_NOVERTFONTS = 16777216
+const _CF_SCREENFONTS = 1
+const _CF_UNICODETEXT = 13
 const _COLOR_BTNFACE = 15
+const _COLOR_BTNTEXT = 18
+const _COLOR_GRAYTEXT = 17
+const _COLOR_HIGHLIGHT = 13
+const _COLOR_HIGHLIGHTTEXT = 14
+const _COLOR_INFOBK = 24
+const _COLOR_INFOTEXT = 23
+const _COLOR_WINDOW = 5
+const _COLOR_WINDOWTEXT = 8
 const _CS_HREDRAW = 2
 const _CS_VREDRAW = 1
 const _CW_USEDEFAULT = -2147483648
+const _DATE_SHORTDATE = 1
+const _DEFAULT_CHARSET = 1
 const _DIB_RGB_COLORS = 0
+const _DLGC_WANTALLKEYS = 4
+const _DTM_GETIDEALSIZE = 4111
+const _DTM_GETSYSTEMTIME = 4097
+const _DTM_SETFORMAT = 4146
+const _DTM_SETSYSTEMTIME = 4098
+const _DTN_DATETIMECHANGE = -759
+const _DTS_SHORTDATEFORMAT = 0
+const _DT_CENTER = 1
+const _DT_END_ELLIPSIS = 32768
+const _DT_NOPREFIX = 2048
+const _DT_SINGLELINE = 32
+const _DT_VCENTER = 4
+const _EM_CANUNDO = 198
+const _EM_GETFIRSTVISIBLELINE = 206
+const _EM_GETMARGINS = 212
+const _EM_GETSEL = 176
+const _EM_HIDEBALLOONTIP = 5380
+const _EM_LINESCROLL = 182
+const _EM_REPLACESEL = 194
+const _EM_SCROLLCARET = 183
+const _EM_SETLIMITTEXT = 197
+const _EM_SETSEL = 177
+const _EM_SHOWBALLOONTIP = 5379
+const _EN_CHANGE = 768
+const _EN_KILLFOCUS = 512
+const _EN_SETFOCUS = 256
 const _ERROR = 0
 const _ES_AUTOHSCROLL = 128
+const _ES_AUTOVSCROLL = 64
+const _ES_MULTILINE = 4
+const _ES_NOHIDESEL = 256
 const _ES_PASSWORD = 32
+const _ES_READONLY = 2048
 const _FALSE = 0
+const _FF_MODERN = 48
+const _FIXED_PITCH = 1
+const _GA_ROOT = 2
+const _GDT_VALID = 0
+const _GMEM_MOVEABLE = 2
+const _GWLP_ID = -12
 const _GWLP_USERDATA = -21
 const _GWL_STYLE = -16
+const _GW_HWNDFIRST = 0
+const _GW_HWNDNEXT = 2
+const _HCF_HIGHCONTRASTON = 1
+const _HWND_TOP = 0
+const _ICC_BAR_CLASSES = 4
+const _ICC_DATE_CLASSES = 256
 const _ICC_PROGRESS_CLASS = 32
+const _ICC_TAB_CLASSES = 8
+const _ICC_TREEVIEW_CLASSES = 2
+const _ILC_COLOR32 = 32
+const _I_IMAGENONE = -2
+const _LBN_DBLCLK = 2
+const _LBN_SELCHANGE = 1
 const _LBS_EXTENDEDSEL = 2048
 const _LBS_NOINTEGRALHEIGHT = 256
 const _LBS_NOTIFY = 1
@@ -44,27 +133,60 @@ const _LB_ERR = -1
 const _LB_ERRSPACE = -2
 const _LB_GETCOUNT = 395
 const _LB_GETCURSEL = 392
+const _LB_GETITEMHEIGHT = 417
 const _LB_GETSELCOUNT = 400
 const _LB_GETSELITEMS = 401
 const _LB_GETTEXT = 393
 const _LB_GETTEXTLEN = 394
+const _LB_GETTOPINDEX = 398
 const _LB_INSERTSTRING = 385
+const _LB_SETCURSEL = 390
+const _LB_SETSEL = 389
 const _LF_FACESIZE = 32
+const _LOCALE_SDECIMAL = 14
+const _LOCALE_SGROUPING = 16
+const _LOCALE_SLANGUAGE = 2
+const _LOCALE_SSHORTDATE = 31
+const _LOCALE_STHOUSAND = 15
+const _LOCALE_STIMEFORMAT = 4099
+const _LOCALE_USER_DEFAULT = 1024
+const _LOGPIXELSY = 90
 const _MA_ACTIVATE = 1
 const _MB_APPLMODAL = 0
 const _MB_ICONERROR = 16
 const _MB_OK = 0
 const _MB_TASKMODAL = 8192
+const _MFT_RADIOCHECK = 512
+const _MFT_STRING = 0
+const _MF_BYCOMMAND = 0
+const _MF_BYPOSITION = 1024
+const _MF_CHECKED = 8
+const _MF_ENABLED = 0
+const _MF_GRAYED = 1
+const _MF_POPUP = 16
+const _MF_SEPARATOR = 2048
+const _MF_STRING = 0
+const _MF_UNCHECKED = 0
+const _MIIM_FTYPE = 256
+const _MIIM_STRING = 64
 const _MK_LBUTTON = 1
 const _MK_MBUTTON = 16
 const _MK_RBUTTON = 2
 const _MK_XBUTTON1 = 32
 const _MK_XBUTTON2 = 64
+const _NM_CUSTOMDRAW = -12
+const _NULL_PEN = 8
 const _PBM_SETMARQUEE = 1034
 const _PBM_SETPOS = 1026
 const _PBM_SETRANGE32 = 1030
 const _PBS_MARQUEE = 8
 const _PBS_SMOOTH = 1
+const _RDW_ALLCHILDREN = 128
+const _RDW_ERASE = 4
+const _RDW_FRAME = 1024
+const _RDW_INVALIDATE = 1
+const _SBARS_SIZEGRIP = 256
+const _SB_GETRECT = 1034
 const _SB_HORZ = 0
 const _SB_LEFT = 6
 const _SB_LINELEFT = 0
@@ -72,6 +194,8 @@ const _SB_LINERIGHT = 1
 const _SB_PAGELEFT = 2
 const _SB_PAGERIGHT = 3
 const _SB_RIGHT = 7
+const _SB_SETPARTS = 1028
+const _SB_SETTEXTW = 1035
 const _SB_THUMBPOSITION = 4
 const _SB_THUMBTRACK = 5
 const _SB_VERT = 1
@@ -80,21 +204,86 @@ const _SIF_POS = 4
 const _SIF_RANGE = 1
 const _SIF_TRACKPOS = 16
 const _SM_CXDOUBLECLK = 36
+const _SM_CXEDGE = 45
 const _SM_CXFULLSCREEN = 16
+const _SM_CXSMICON = 49
+const _SM_CXVSCROLL = 2
 const _SM_CYDOUBLECLK = 37
 const _SM_CYFULLSCREEN = 17
+const _SPI_GETCLIENTAREAANIMATION = 4162
+const _SPI_GETHIGHCONTRAST = 66
 const _SPI_GETNONCLIENTMETRICS = 41
 const _SRCCOPY = 13369376
+const _SS_ENDELLIPSIS = 16384
 const _SS_LEFTNOWORDWRAP = 12
 const _SS_NOPREFIX = 128
+const _SS_NOTIFY = 256
+const _SS_PATHELLIPSIS = 32768
 const _STARTF_USESHOWWINDOW = 1
+const _STN_CLICKED = 0
+const _SWP_NOACTIVATE = 16
+const _SWP_NOMOVE = 2
+const _SWP_NOSIZE = 1
 const _SW_ERASE = 4
 const _SW_HIDE = 0
 const _SW_INVALIDATE = 2
+const _SW_MINIMIZE = 6
+const _SW_RESTORE = 9
 const _SW_SHOW = 5
 const _SW_SHOWDEFAULT = 10
+const _TBM_GETPOS = 1024
+const _TBM_GETRANGEMAX = 1026
+const _TBM_SETPAGESIZE = 1045
+const _TBM_SETPOS = 1029
+const _TBM_SETRANGEMAX = 1032
+const _TBM_SETRANGEMIN = 1031
+const _TBN_GETINFOTIPW = -719
+const _TBSTATE_CHECKED = 1
+const _TBSTATE_ENABLED = 4
+const _TBSTYLE_FLAT = 2048
+const _TBSTYLE_TOOLTIPS = 256
+const _TBS_HORZ = 0
+const _TBS_NOTICKS = 16
+const _TBS_VERT = 2
+const _TB_ADDBUTTONSW = 1092
+const _TB_AUTOSIZE = 1057
+const _TB_BUTTONSTRUCTSIZE = 1054
+const _TB_CHECKBUTTON = 1026
+const _TB_ENABLEBUTTON = 1025
+const _TB_SETIMAGELIST = 1072
+const _TCIF_TEXT = 1
+const _TCM_ADJUSTRECT = 4904
+const _TCM_GETCURSEL = 4875
+const _TCM_GETITEMCOUNT = 4868
+const _TCM_GETITEMRECT = 4874
+const _TCM_INSERTITEM = 4926
+const _TCM_SETCURSEL = 4876
+const _TCN_SELCHANGE = -551
+const _TPM_RIGHTBUTTON = 2
+const _TRANSPARENT = 1
 const _TRUE = 1
+const _TTI_ERROR = 3
+const _TVE_COLLAPSE = 1
+const _TVE_EXPAND = 2
+const _TVGN_CARET = 9
+const _TVIF_PARAM = 4
+const _TVIF_TEXT = 1
+const _TVI_LAST = -65535
+const _TVI_ROOT = -65536
+const _TVM_DELETEITEM = 4353
+const _TVM_EXPAND = 4354
+const _TVM_GETITEM = 4414
+const _TVM_GETNEXTITEM = 4362
+const _TVM_INSERTITEM = 4402
+const _TVM_SELECTITEM = 4363
+const _TVN_SELCHANGED = -451
+const _TVS_HASBUTTONS = 1
+const _TVS_HASLINES = 2
+const _TVS_LINESATROOT = 4
+const _TVS_SHOWSELALWAYS = 32
+const _VAR_DATEVALUEONLY = 2
 const _VK_ADD = 107
+const _VK_CAPITAL = 20
 const _VK_CLEAR = 12
 const _VK_CONTROL = 17
 const _VK_DELETE = 46
@@ -137,34 +326,60 @@ const _VK_UP = 38
 const _WA_INACTIVE = 0
 const _WM_ACTIVATE = 6
 const _WM_APP = 32768
+const _WM_CHAR = 258
+const _WM_CLIPBOARDUPDATE = 797
 const _WM_CLOSE = 16
 const _WM_COMMAND = 273
+const _WM_CONTEXTMENU = 123
+const _WM_COPY = 769
+const _WM_CTLCOLORBTN = 309
+const _WM_CUT = 768
+const _WM_DESTROY = 2
 const _WM_ERASEBKGND = 20
+const _WM_GETDLGCODE = 135
 const _WM_GETMINMAXINFO = 36
 const _WM_GETTEXT = 13
 const _WM_GETTEXTLENGTH = 14
 const _WM_HSCROLL = 276
+const _WM_INITDIALOG = 272
+const _WM_INITMENUPOPUP = 279
 const _WM_KEYDOWN = 256
 const _WM_KEYUP = 257
+const _WM_KILLFOCUS = 8
 const _WM_LBUTTONDOWN = 513
 const _WM_LBUTTONUP = 514
 const _WM_MBUTTONDOWN = 519
 const _WM_MBUTTONUP = 520
 const _WM_MOUSEACTIVATE = 33
 const _WM_MOUSEMOVE = 512
+const _WM_MOUSEWHEEL = 522
 const _WM_NCCREATE = 129
+const _WM_NCDESTROY = 130
+const _WM_NOTIFY = 78
 const _WM_PAINT = 15
+const _WM_PASTE = 770
+const _WM_PRINTCLIENT = 792
 const _WM_RBUTTONDOWN = 516
 const _WM_RBUTTONUP = 517
+const _WM_SETFOCUS = 7
 const _WM_SETFONT = 48
+const _WM_SETREDRAW = 11
+const _WM_SETTINGCHANGE = 26
 const _WM_SIZE = 5
+const _WM_SYSCOLORCHANGE = 21
 const _WM_SYSKEYDOWN = 260
 const _WM_SYSKEYUP = 261
+const _WM_UNDO = 772
 const _WM_VSCROLL = 277
 const _WM_XBUTTONDOWN = 523
 const _WM_XBUTTONUP = 524
 const _WS_CHILD = 1073741824
+const _WS_CLIPCHILDREN = 33554432
+const _WS_CLIPSIBLINGS = 67108864
 const _WS_EX_CLIENTEDGE = 512
+const _WS_EX_CONTROLPARENT = 65536
+const _WS_EX_LAYOUTRTL = 4194304
+const _WS_GROUP = 131072
 const _WS_HSCROLL = 1048576
 const _WS_OVERLAPPEDWINDOW = 13565952
 const _WS_TABSTOP = 65536